@@ -66,6 +66,19 @@ type ExecutionResult struct {
 	WithdrawalsRoot      *common.Hash          `json:"withdrawalsRoot,omitempty"`
 	CurrentExcessBlobGas *math.HexOrDecimal64  `json:"currentExcessBlobGas,omitempty"`
 	CurrentBlobGasUsed   *math.HexOrDecimal64  `json:"blobGasUsed,omitempty"`
+	Rip7560Gas           []*rip7560Gas         `json:"rip7560Gas,omitempty"`
+}
+
+// rip7560Gas is the gas used by a RIP-7560 transaction, split by phase. The
+// gas of the validation phase is further split by frame, the intrinsic gas
+// included in the validation phase is reported separately.
+type rip7560Gas struct {
+	Index         int                            `json:"index"`
+	TxHash        common.Hash                    `json:"transactionHash"`
+	IntrinsicGas  math.HexOrDecimal64            `json:"intrinsicGas"`
+	ValidationGas math.HexOrDecimal64            `json:"validationGasUsed"`
+	ExecutionGas  math.HexOrDecimal64            `json:"executionGasUsed"`
+	Frames        map[string]math.HexOrDecimal64 `json:"validationFrames"`
 }
 
 type ommer struct {
@@ -149,6 +162,8 @@ func (pre *Prestate) Apply(vmConfig vm.Config, chainConfig *params.ChainConfig,
 		blobGasUsed = uint64(0)
 		receipts    = make(types.Receipts, 0)
 		txIndex     = 0
+
+		rip7560Gases []*rip7560Gas
 	)
 	gaspool.AddGas(pre.Env.GasLimit)
 	vmContext := vm.BlockContext{
@@ -192,9 +207,21 @@ func (pre *Prestate) Apply(vmConfig vm.Config, chainConfig *params.ChainConfig,
 		chainConfig.DAOForkBlock.Cmp(new(big.Int).SetUint64(pre.Env.Number)) == 0 {
 		misc.ApplyDAOHardFork(statedb)
 	}
+	// The header is only needed by the RIP-7560 transactions, which read the
+	// block from it rather than from the EVM.
+	header := &types.Header{
+		Coinbase:   pre.Env.Coinbase,
+		Difficulty: vmContext.Difficulty,
+		Number:     vmContext.BlockNumber,
+		GasLimit:   pre.Env.GasLimit,
+		Time:       pre.Env.Timestamp,
+		BaseFee:    vmContext.BaseFee,
+	}
 	if beaconRoot := pre.Env.ParentBeaconBlockRoot; beaconRoot != nil {
 		evm := vm.NewEVM(vmContext, vm.TxContext{}, statedb, chainConfig, vmConfig)
-		core.ProcessBeaconBlockRoot(*beaconRoot, evm, statedb)
+		if err := core.ProcessBeaconBlockRoot(*beaconRoot, evm, statedb); err != nil {
+			return nil, nil, nil, NewError(ErrorEVM, err)
+		}
 	}
 
 	for i := 0; txIt.Next(); i++ {
@@ -210,6 +237,46 @@ func (pre *Prestate) Apply(vmConfig vm.Config, chainConfig *params.ChainConfig,
 			rejectedTxs = append(rejectedTxs, &rejectedTx{i, errMsg})
 			continue
 		}
+		if tx.Type() == types.Rip7560Type {
+			// RIP-7560 transactions have no signature to derive a message
+			// from, both of their phases are run by the core instead
+			tracer, traceOutput, err := getTracerFn(txIndex, tx.Hash())
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if tracer != nil {
+				vmConfig.Tracer = tracer.Hooks
+			}
+			var (
+				snapshot = statedb.Snapshot()
+				prevGas  = gaspool.Gas()
+				evm      = vm.NewEVM(vmContext, vm.TxContext{}, statedb, chainConfig, vmConfig)
+			)
+			receipt, frameGas, err := applyRip7560Tx(evm, gaspool, statedb, header, blockHash, tx, txIndex, &gasUsed)
+			if tracer != nil {
+				if tracer.OnTxEnd != nil {
+					tracer.OnTxEnd(receipt, err)
+				}
+				if err := writeTraceResult(tracer, traceOutput); err != nil {
+					log.Warn("Error writing tracer output", "err", err)
+				}
+			}
+			if err != nil {
+				statedb.RevertToSnapshot(snapshot)
+				log.Info("rejected tx", "index", i, "hash", tx.Hash(), "error", err)
+				rejectedTxs = append(rejectedTxs, &rejectedTx{i, err.Error()})
+				gaspool.SetGas(prevGas)
+				continue
+			}
+			if hashError != nil {
+				return nil, nil, nil, NewError(ErrorMissingBlockhash, hashError)
+			}
+			includedTxs = append(includedTxs, tx)
+			receipts = append(receipts, receipt)
+			rip7560Gases = append(rip7560Gases, frameGas)
+			txIndex++
+			continue
+		}
 		msg, err := core.TransactionToMessage(tx, signer, pre.Env.BaseFee)
 		if err != nil {
 			log.Warn("rejected tx", "index", i, "hash", tx.Hash(), "error", err)
@@ -359,6 +426,7 @@ func (pre *Prestate) Apply(vmConfig vm.Config, chainConfig *params.ChainConfig,
 		Difficulty:  (*math.HexOrDecimal256)(vmContext.Difficulty),
 		GasUsed:     (math.HexOrDecimal64)(gasUsed),
 		BaseFee:     (*math.HexOrDecimal256)(vmContext.BaseFee),
+		Rip7560Gas:  rip7560Gases,
 	}
 	if pre.Env.Withdrawals != nil {
 		h := types.DeriveSha(types.Withdrawals(pre.Env.Withdrawals), trie.NewStackTrie(nil))
@@ -378,6 +446,44 @@ func (pre *Prestate) Apply(vmConfig vm.Config, chainConfig *params.ChainConfig,
 	return statedb, execRs, body, nil
 }
 
+// applyRip7560Tx runs the validation and the execution phases of a RIP-7560
+// transaction, returning its receipt along with the gas used by its phases.
+func applyRip7560Tx(evm *vm.EVM, gp *core.GasPool, statedb *state.StateDB, header *types.Header, blockHash common.Hash, tx *types.Transaction, txIndex int, usedGas *uint64) (*types.Receipt, *rip7560Gas, error) {
+	statedb.SetTxContext(tx.Hash(), txIndex)
+	vpr, err := core.ApplyRip7560ValidationPhasesWithEVM(evm, gp, statedb, header, tx)
+	if err != nil {
+		return nil, nil, err
+	}
+	vpr.TxIndex = txIndex
+	receipt, err := core.ApplyRip7560ExecutionPhaseWithEVM(evm, vpr, gp, statedb, header, usedGas)
+	if err != nil {
+		return nil, nil, err
+	}
+	statedb.Finalise(true)
+	receipt.Logs = statedb.GetLogs(tx.Hash(), header.Number.Uint64(), blockHash)
+
+	frameGas := map[string]uint64{
+		core.FrameNonceManager: vpr.NonceManagerUsedGas,
+		core.FrameDeployer:     vpr.DeploymentUsedGas,
+		core.FrameAccount:      vpr.ValidationUsedGas,
+		core.FramePaymaster:    vpr.PmValidationUsedGas,
+	}
+	gas := &rip7560Gas{
+		Index:         txIndex,
+		TxHash:        tx.Hash(),
+		IntrinsicGas:  math.HexOrDecimal64(vpr.PreTransactionGasCost),
+		ValidationGas: math.HexOrDecimal64(receipt.ValidationGasUsed),
+		ExecutionGas:  math.HexOrDecimal64(receipt.GasUsed - receipt.ValidationGasUsed),
+		Frames:        make(map[string]math.HexOrDecimal64),
+	}
+	for _, frame := range receipt.FrameStatuses {
+		if used, ok := frameGas[frame.Frame]; ok {
+			gas.Frames[frame.Frame] = math.HexOrDecimal64(used)
+		}
+	}
+	return receipt, gas, nil
+}
+
 func MakePreState(db ethdb.Database, accounts types.GenesisAlloc) *state.StateDB {
 	sdb := state.NewDatabaseWithConfig(db, &triedb.Config{Preimages: true})
 	statedb, _ := state.New(types.EmptyRootHash, sdb, nil)
@@ -93,7 +93,9 @@ func signUnsignedTransactions(txs []*txWithKey, signer types.Signer) (types.Tran
 			signed  *types.Transaction
 			err     error
 		)
-		if tx.key == nil || v.BitLen()+r.BitLen()+s.BitLen() != 0 {
+		// RIP-7560 transactions are authorized by the account validation
+		// frame, they are never signed here.
+		if tx.key == nil || v.BitLen()+r.BitLen()+s.BitLen() != 0 || tx.tx.Type() == types.Rip7560Type {
 			// Already signed
 			signedTxs = append(signedTxs, tx.tx)
 			continue
@@ -0,0 +1,153 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/urfave/cli/v2"
+)
+
+var aaCommand = &cli.Command{
+	Name:  "aa",
+	Usage: "A set of RIP-7560 account abstraction commands",
+	Subcommands: []*cli.Command{
+		{
+			Name:      "decode",
+			Usage:     "Decode and print a raw RIP-7560 transaction",
+			ArgsUsage: "<hex>",
+			Action:    decodeAATx,
+			Description: `
+geth aa decode <hex>
+This command decodes a raw RIP-7560 transaction, as passed to eth_sendRawTransaction,
+checks that its encoding is canonical and prints its fields, the hash it is signed by
+and a summary of its size and gas limits.
+ `,
+		},
+		aaFuzzCommand,
+	},
+}
+
+func decodeAATx(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		return errors.New("need the raw transaction as single argument")
+	}
+	input := strings.TrimSpace(ctx.Args().First())
+	if !strings.HasPrefix(input, "0x") {
+		input = "0x" + input
+	}
+	raw, err := hexutil.Decode(input)
+	if err != nil {
+		return fmt.Errorf("invalid hex: %v", err)
+	}
+	tx, err := decodeRawAATx(raw)
+	if err != nil {
+		return err
+	}
+	return printAATx(os.Stdout, tx)
+}
+
+// decodeRawAATx decodes a RIP-7560 transaction from its binary encoding, which
+// must be canonical.
+func decodeRawAATx(raw []byte) (*types.Transaction, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("invalid transaction encoding: %v", err)
+	}
+	if tx.Type() != types.Rip7560Type {
+		return nil, fmt.Errorf("not a RIP-7560 transaction: type %d", tx.Type())
+	}
+	if enc, err := tx.MarshalBinary(); err != nil || !bytes.Equal(enc, raw) {
+		return nil, errors.New("non-canonical transaction encoding")
+	}
+	return tx, nil
+}
+
+// printAATx writes the fields and the derived values of a RIP-7560 transaction.
+func printAATx(w io.Writer, tx *types.Transaction) error {
+	aatx := tx.Rip7560TransactionData()
+
+	field := func(name string, value interface{}) {
+		fmt.Fprintf(w, "%-30s %v\n", name+":", value)
+	}
+	address := func(addr *common.Address) string {
+		if addr == nil {
+			return "none"
+		}
+		return addr.Hex()
+	}
+	number := func(n *big.Int) string {
+		if n == nil {
+			return "0"
+		}
+		return n.String()
+	}
+	data := func(data []byte) string {
+		if len(data) == 0 {
+			return "none"
+		}
+		return fmt.Sprintf("%d bytes %s", len(data), hexutil.Encode(data))
+	}
+	field("Hash", tx.Hash().Hex())
+	field("Signing hash", types.NewRIP7560Signer(aatx.ChainID).Hash(tx).Hex())
+	field("Chain ID", number(aatx.ChainID))
+	field("Sender", address(aatx.Sender))
+	field("Nonce", aatx.Nonce)
+	field("Nonce key", number(aatx.NonceKey))
+	field("RIP-7712 nonce", aatx.IsRip7712Nonce())
+	field("Paymaster", address(aatx.Paymaster))
+	field("Paymaster data", data(aatx.PaymasterData))
+	field("Deployer", address(aatx.Deployer))
+	field("Deployer data", data(aatx.DeployerData))
+	field("Authorization data", data(aatx.AuthorizationData))
+	for i, call := range aatx.ExecutionFrames() {
+		field(fmt.Sprintf("Execution data #%d", i), data(call))
+	}
+	field("Access list entries", len(aatx.AccessList))
+	field("Max priority fee per gas", number(aatx.GasTipCap))
+	field("Max fee per gas", number(aatx.GasFeeCap))
+	field("Builder fee", number(aatx.BuilderFee))
+
+	fmt.Fprintln(w)
+	field("Size", fmt.Sprintf("%d bytes", tx.Size()))
+	field("Call gas limit", aatx.Gas)
+	field("Validation gas limit", aatx.ValidationGasLimit)
+	field("Paymaster validation gas", aatx.PaymasterValidationGasLimit)
+	field("PostOp gas limit", aatx.PostOpGas)
+	preTxGas, err := aatx.PreTransactionGasCost()
+	if err != nil {
+		return err
+	}
+	field("Pre-transaction gas cost", preTxGas)
+	total, err := aatx.TotalGasLimit()
+	if err != nil {
+		return err
+	}
+	field("Total gas limit", total)
+	field("Max cost (wei)", tx.Cost())
+	field("Gas payer", address(aatx.GasPayer()))
+	return nil
+}
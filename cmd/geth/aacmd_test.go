@@ -0,0 +1,71 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestDecodeAATx(t *testing.T) {
+	t.Parallel()
+
+	paymaster := common.Address{0xbb}
+	tx := types.NewTx(&types.Rip7560AccountAbstractionTx{
+		ChainID:       big.NewInt(1337),
+		NonceKey:      new(big.Int),
+		GasTipCap:     big.NewInt(1),
+		GasFeeCap:     big.NewInt(2),
+		Gas:           100_000,
+		Sender:        &common.Address{0xaa},
+		Paymaster:     &paymaster,
+		PaymasterData: []byte{0x01},
+		BuilderFee:    new(big.Int),
+
+		ValidationGasLimit:          50_000,
+		PaymasterValidationGasLimit: 30_000,
+	})
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := decodeRawAATx(raw)
+	if err != nil {
+		t.Fatalf("failed to decode transaction: %v", err)
+	}
+	var out bytes.Buffer
+	if err := printAATx(&out, decoded); err != nil {
+		t.Fatalf("failed to print transaction: %v", err)
+	}
+	for _, want := range []string{tx.Hash().Hex(), "Gas payer:", paymaster.Hex(), "1 bytes 0x01"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output misses %q:\n%s", want, out.String())
+		}
+	}
+	if _, err := decodeRawAATx(append(raw, 0x00)); err == nil {
+		t.Error("trailing bytes accepted")
+	}
+	legacy, _ := types.NewTransaction(0, common.Address{}, nil, 21000, big.NewInt(1), nil).MarshalBinary()
+	if _, err := decodeRawAATx(legacy); err == nil {
+		t.Error("legacy transaction accepted")
+	}
+}
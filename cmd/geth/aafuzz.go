@@ -0,0 +1,386 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	aaFuzzSeedFlag = &cli.Int64Flag{
+		Name:  "seed",
+		Usage: "Seed of the generated blocks (0 = random)",
+	}
+	aaFuzzBlocksFlag = &cli.IntFlag{
+		Name:  "blocks",
+		Usage: "Number of blocks to generate",
+		Value: 16,
+	}
+	aaFuzzTxsFlag = &cli.IntFlag{
+		Name:  "txs",
+		Usage: "Maximum number of transactions per generated block",
+		Value: 16,
+	}
+	aaFuzzEngineFlag = &cli.StringFlag{
+		Name:  "engine",
+		Usage: "Engine API endpoint of the client to compare against",
+		Value: "http://127.0.0.1:8551",
+	}
+	aaFuzzJWTSecretFlag = &cli.StringFlag{
+		Name:  "jwtsecret",
+		Usage: "Path to the JWT secret of the engine API endpoint",
+	}
+
+	aaFuzzCommand = &cli.Command{
+		Name:  "fuzz",
+		Usage: "Differentially fuzz the RIP-7560 block processing against another client",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "genesis",
+				Usage:     "Write the genesis of the generated blocks",
+				ArgsUsage: "<file>",
+				Action:    aaFuzzGenesis,
+				Description: `
+geth aa fuzz genesis <file>
+This command writes the genesis the fuzzed blocks are built on, which the client to
+compare against has to be initialized with.
+ `,
+			},
+			{
+				Name:   "run",
+				Usage:  "Feed generated RIP-7560 blocks to the local processor and to another client",
+				Action: aaFuzzRun,
+				Flags:  []cli.Flag{aaFuzzSeedFlag, aaFuzzBlocksFlag, aaFuzzTxsFlag, aaFuzzEngineFlag, aaFuzzJWTSecretFlag},
+				Description: `
+geth aa fuzz run --engine <url> --jwtsecret <file>
+This command generates random blocks of RIP-7560 and regular transactions on top of
+the fuzzing genesis, imports them into an in-memory chain and submits them to the
+other client over the engine API. Every block has to be accepted by both clients
+with the same state root and the same receipts. The first divergence is reported
+along with the seed reproducing it.
+ `,
+			},
+		},
+	}
+)
+
+// The accounts of the fuzzing genesis.
+var (
+	aaFuzzKey, _    = crypto.HexToECDSA("45a915e4d060149eb4365960e6a7a45f334393093061116b197e3240065ff2d8")
+	aaFuzzEOA       = crypto.PubkeyToAddress(aaFuzzKey.PublicKey)
+	aaFuzzPaymaster = common.HexToAddress("0x00000000000000000000000000000000000aa0ff")
+	aaFuzzAccounts  = []common.Address{
+		common.HexToAddress("0x00000000000000000000000000000000000aa001"),
+		common.HexToAddress("0x00000000000000000000000000000000000aa002"),
+		common.HexToAddress("0x00000000000000000000000000000000000aa003"),
+		common.HexToAddress("0x00000000000000000000000000000000000aa004"),
+	}
+)
+
+// aaFuzzGenesisBlock returns the genesis of the fuzzed blocks: the developer
+// chain with a funded externally owned account, smart accounts and a paymaster.
+func aaFuzzGenesisBlock() *core.Genesis {
+	genesis := core.DeveloperGenesisBlock(30_000_000, &aaFuzzEOA)
+	genesis.Timestamp = 1
+	for _, account := range aaFuzzAccounts {
+		genesis.Alloc[account] = types.Account{Code: aaFuzzAccountCode(), Balance: big.NewInt(params.Ether)}
+	}
+	genesis.Alloc[aaFuzzPaymaster] = types.Account{Code: aaFuzzPaymasterCode(), Balance: big.NewInt(params.Ether)}
+	return genesis
+}
+
+// aaFuzzAccountCode returns the code of a smart account accepting all
+// transactions. Every call stores its second calldata word in the slot of the
+// first one, and logs its calldata. A single byte calldata, which only the
+// execution frame can have, reverts.
+func aaFuzzAccountCode() []byte {
+	code := []byte{
+		byte(vm.CALLDATASIZE), byte(vm.PUSH1), 1, byte(vm.EQ), byte(vm.PUSH1), 0, byte(vm.JUMPI), // patched below
+		byte(vm.PUSH1), 32, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.SSTORE),
+		byte(vm.CALLDATASIZE), byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.CALLDATACOPY),
+		byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.CALLDATASIZE), byte(vm.PUSH1), 0, byte(vm.LOG1),
+	}
+	code = append(code, aaFuzzReturnWords(core.PackValidationData(core.AcceptAccountMethodSig, 0, 0))...)
+	code[5] = byte(len(code))
+	return append(code, byte(vm.JUMPDEST), byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.REVERT))
+}
+
+// aaFuzzPaymasterCode returns the code of a paymaster sponsoring all
+// transactions, with an empty context.
+func aaFuzzPaymasterCode() []byte {
+	ret := core.PackValidationData(core.AcceptPaymasterMethodSig, 0, 0)
+	ret = append(ret, common.LeftPadBytes([]byte{64}, 32)...)
+	return aaFuzzReturnWords(append(ret, make([]byte, 32)...))
+}
+
+// aaFuzzReturnWords returns the code returning the given data, a multiple of
+// words.
+func aaFuzzReturnWords(data []byte) []byte {
+	var code []byte
+	for i := 0; i < len(data); i += 32 {
+		code = append(code, byte(vm.PUSH32))
+		code = append(code, data[i:i+32]...)
+		code = append(code, byte(vm.PUSH1), byte(i), byte(vm.MSTORE))
+	}
+	return append(code, byte(vm.PUSH1), byte(len(data)), byte(vm.PUSH1), 0, byte(vm.RETURN))
+}
+
+// aaFuzzBlocks generates the given number of blocks on top of the genesis, with
+// up to the given number of random transactions each.
+func aaFuzzBlocks(genesis *core.Genesis, seed int64, n int, maxTxs int) []*types.Block {
+	var (
+		rng    = rand.New(rand.NewSource(seed))
+		signer = types.LatestSigner(genesis.Config)
+		nonces = make(map[common.Address]map[uint64]uint64)
+	)
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, beacon.New(ethash.NewFaker()), n, func(i int, b *core.BlockGen) {
+		var root common.Hash
+		rng.Read(root[:])
+
+		b.SetPoS()
+		b.SetCoinbase(common.Address{0xc0, byte(i)})
+		b.SetParentBeaconRoot(root)
+		for j := rng.Intn(maxTxs + 1); j > 0; j-- {
+			var (
+				tip    = big.NewInt(rng.Int63n(params.GWei))
+				feeCap = new(big.Int).Add(new(big.Int).Mul(b.BaseFee(), big.NewInt(2)), tip)
+			)
+			if rng.Intn(4) == 0 {
+				b.AddTx(types.MustSignNewTx(aaFuzzKey, signer, &types.DynamicFeeTx{
+					ChainID:   genesis.Config.ChainID,
+					Nonce:     b.TxNonce(aaFuzzEOA),
+					GasTipCap: tip,
+					GasFeeCap: feeCap,
+					Gas:       100_000,
+					To:        &aaFuzzAccounts[rng.Intn(len(aaFuzzAccounts))],
+					Value:     big.NewInt(rng.Int63n(params.GWei)),
+				}))
+				continue
+			}
+			var (
+				sender = aaFuzzAccounts[rng.Intn(len(aaFuzzAccounts))]
+				key    = uint64(rng.Intn(3))
+			)
+			if nonces[sender] == nil {
+				nonces[sender] = make(map[uint64]uint64)
+			}
+			aatx := &types.Rip7560AccountAbstractionTx{
+				ChainID:            genesis.Config.ChainID,
+				NonceKey:           new(big.Int).SetUint64(key),
+				Nonce:              nonces[sender][key],
+				GasTipCap:          tip,
+				GasFeeCap:          feeCap,
+				Gas:                uint64(rng.Intn(100_000)),
+				Sender:             &sender,
+				ValidationGasLimit: 200_000,
+				BuilderFee:         new(big.Int),
+			}
+			if key == 0 {
+				// The legacy nonce is validated against the state, which is
+				// already advanced by the preceding transactions
+				aatx.Nonce = b.TxNonce(sender)
+			}
+			switch rng.Intn(3) {
+			case 1:
+				aatx.ExecutionData = []byte{0x01}
+			case 2:
+				aatx.ExecutionData = make([]byte, 64)
+				rng.Read(aatx.ExecutionData)
+			}
+			if rng.Intn(3) == 0 {
+				aatx.Paymaster = &aaFuzzPaymaster
+				aatx.PaymasterValidationGasLimit = 100_000
+			}
+			b.AddTx(types.NewTx(aatx))
+			if key != 0 {
+				nonces[sender][key]++
+			}
+		}
+	})
+	return blocks
+}
+
+// aaFuzzProcess imports the blocks into an in-memory chain, processing them
+// like the node does, and returns their receipts.
+func aaFuzzProcess(genesis *core.Genesis, blocks []*types.Block) ([]types.Receipts, error) {
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, genesis, nil, beacon.New(ethash.NewFaker()), vm.Config{}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer chain.Stop()
+
+	receipts := make([]types.Receipts, len(blocks))
+	for i, block := range blocks {
+		if _, err := chain.InsertChain(blocks[i : i+1]); err != nil {
+			return nil, fmt.Errorf("block #%d rejected locally: %v", block.NumberU64(), err)
+		}
+		receipts[i] = chain.GetReceiptsByHash(block.Hash())
+	}
+	return receipts, nil
+}
+
+// aaFuzzCompare submits the blocks to the other client over the engine API, and
+// compares its verdict and receipts with the local ones. The first divergence
+// is returned as an error.
+func aaFuzzCompare(ctx context.Context, client *rpc.Client, genesis *core.Genesis, blocks []*types.Block, receipts []types.Receipts) error {
+	var remoteGenesis struct {
+		Hash common.Hash `json:"hash"`
+	}
+	if err := client.CallContext(ctx, &remoteGenesis, "eth_getBlockByNumber", "0x0", false); err != nil {
+		return fmt.Errorf("failed to retrieve remote genesis: %v", err)
+	}
+	if local := genesis.ToBlock().Hash(); remoteGenesis.Hash != local {
+		return fmt.Errorf("genesis mismatch: remote %x, local %x", remoteGenesis.Hash, local)
+	}
+	for i, block := range blocks {
+		var (
+			payload = engine.BlockToExecutableData(block, nil, nil).ExecutionPayload
+			status  engine.PayloadStatusV1
+		)
+		if err := client.CallContext(ctx, &status, "engine_newPayloadV3", payload, []common.Hash{}, block.BeaconRoot()); err != nil {
+			return fmt.Errorf("block #%d: failed to submit payload: %v", block.NumberU64(), err)
+		}
+		if status.Status != engine.VALID {
+			var reason string
+			if status.ValidationError != nil {
+				reason = *status.ValidationError
+			}
+			return fmt.Errorf("block #%d: remote status %s (local %s): %s", block.NumberU64(), status.Status, engine.VALID, reason)
+		}
+		var (
+			update = engine.ForkchoiceStateV1{HeadBlockHash: block.Hash(), SafeBlockHash: block.Hash(), FinalizedBlockHash: block.Hash()}
+			resp   engine.ForkChoiceResponse
+		)
+		if err := client.CallContext(ctx, &resp, "engine_forkchoiceUpdatedV3", update, nil); err != nil {
+			return fmt.Errorf("block #%d: failed to update forkchoice: %v", block.NumberU64(), err)
+		}
+		var remote []*types.Receipt
+		if err := client.CallContext(ctx, &remote, "eth_getBlockReceipts", block.Hash()); err != nil {
+			return fmt.Errorf("block #%d: failed to retrieve remote receipts: %v", block.NumberU64(), err)
+		}
+		if err := aaFuzzDiffReceipts(receipts[i], remote); err != nil {
+			return fmt.Errorf("block #%d: %v", block.NumberU64(), err)
+		}
+		log.Info("Block matched", "number", block.NumberU64(), "hash", block.Hash(), "txs", len(block.Transactions()))
+	}
+	return nil
+}
+
+// aaFuzzDiffReceipts compares the consensus fields of the local and the remote
+// receipts of a block, and the gas used by their transactions.
+func aaFuzzDiffReceipts(local, remote []*types.Receipt) error {
+	if len(local) != len(remote) {
+		return fmt.Errorf("receipt count mismatch: remote %d, local %d", len(remote), len(local))
+	}
+	for i := range local {
+		l, r := local[i], remote[i]
+		switch {
+		case l.TxHash != r.TxHash:
+			return fmt.Errorf("receipt %d: transaction mismatch: remote %x, local %x", i, r.TxHash, l.TxHash)
+		case l.Type != r.Type:
+			return fmt.Errorf("receipt %d: type mismatch: remote %d, local %d", i, r.Type, l.Type)
+		case l.Status != r.Status:
+			return fmt.Errorf("receipt %d: status mismatch: remote %d, local %d", i, r.Status, l.Status)
+		case l.CumulativeGasUsed != r.CumulativeGasUsed:
+			return fmt.Errorf("receipt %d: cumulative gas mismatch: remote %d, local %d", i, r.CumulativeGasUsed, l.CumulativeGasUsed)
+		case l.GasUsed != r.GasUsed:
+			return fmt.Errorf("receipt %d: gas used mismatch: remote %d, local %d", i, r.GasUsed, l.GasUsed)
+		case len(l.Logs) != len(r.Logs):
+			return fmt.Errorf("receipt %d: log count mismatch: remote %d, local %d", i, len(r.Logs), len(l.Logs))
+		}
+		for j := range l.Logs {
+			ll, rl := l.Logs[j], r.Logs[j]
+			lenc, _ := json.Marshal([]any{ll.Address, ll.Topics, ll.Data})
+			renc, _ := json.Marshal([]any{rl.Address, rl.Topics, rl.Data})
+			if !bytes.Equal(lenc, renc) {
+				return fmt.Errorf("receipt %d: log %d mismatch: remote %s, local %s", i, j, renc, lenc)
+			}
+		}
+	}
+	return nil
+}
+
+func aaFuzzGenesis(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		return errors.New("need the genesis file as single argument")
+	}
+	out, err := json.MarshalIndent(aaFuzzGenesisBlock(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(ctx.Args().First(), out, 0644)
+}
+
+func aaFuzzRun(ctx *cli.Context) error {
+	if !ctx.IsSet(aaFuzzJWTSecretFlag.Name) {
+		return errors.New("missing --" + aaFuzzJWTSecretFlag.Name)
+	}
+	secret, err := node.ObtainJWTSecret(ctx.String(aaFuzzJWTSecretFlag.Name))
+	if err != nil {
+		return fmt.Errorf("failed to load JWT secret: %v", err)
+	}
+	var jwt [32]byte
+	copy(jwt[:], secret)
+	client, err := rpc.DialOptions(ctx.Context, ctx.String(aaFuzzEngineFlag.Name), rpc.WithHTTPAuth(node.NewJWTAuth(jwt)))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	seed := ctx.Int64(aaFuzzSeedFlag.Name)
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	var (
+		genesis = aaFuzzGenesisBlock()
+		blocks  = aaFuzzBlocks(genesis, seed, ctx.Int(aaFuzzBlocksFlag.Name), ctx.Int(aaFuzzTxsFlag.Name))
+	)
+	log.Info("Generated blocks", "seed", seed, "blocks", len(blocks))
+
+	receipts, err := aaFuzzProcess(genesis, blocks)
+	if err != nil {
+		return fmt.Errorf("seed %d: %v", seed, err)
+	}
+	if err := aaFuzzCompare(ctx.Context, client, genesis, blocks, receipts); err != nil {
+		return fmt.Errorf("seed %d: %v", seed, err)
+	}
+	log.Info("No divergence found", "seed", seed, "blocks", len(blocks))
+	return nil
+}
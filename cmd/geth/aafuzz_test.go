@@ -0,0 +1,93 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/catalyst"
+	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+)
+
+// Tests that the fuzzed blocks are processed identically by two geth instances,
+// and that receipt divergences are reported.
+func TestAAFuzzCompare(t *testing.T) {
+	genesis := aaFuzzGenesisBlock()
+	blocks := aaFuzzBlocks(genesis, 1, 8, 16)
+
+	var aatxs, failed int
+	receipts, err := aaFuzzProcess(genesis, blocks)
+	if err != nil {
+		t.Fatalf("failed to process blocks: %v", err)
+	}
+	for _, block := range receipts {
+		for _, receipt := range block {
+			if receipt.Type == types.Rip7560Type {
+				aatxs++
+			}
+			if receipt.Status == types.ReceiptStatusFailed {
+				failed++
+			}
+		}
+	}
+	if aatxs == 0 || failed == 0 {
+		t.Fatalf("fuzzed blocks lack coverage: %d RIP-7560 transactions, %d failed", aatxs, failed)
+	}
+	// Compare against a second node importing the blocks over the engine API
+	stack, err := node.New(&node.Config{P2P: p2p.Config{NoDiscovery: true}})
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	defer stack.Close()
+
+	config := ethconfig.Defaults
+	config.Genesis = aaFuzzGenesisBlock()
+	config.SyncMode = downloader.FullSync
+	backend, err := eth.New(stack, &config)
+	if err != nil {
+		t.Fatalf("failed to create backend: %v", err)
+	}
+	if err := catalyst.Register(stack, backend); err != nil {
+		t.Fatalf("failed to register engine API: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	client := stack.Attach()
+	defer client.Close()
+
+	if err := aaFuzzCompare(context.Background(), client, genesis, blocks, receipts); err != nil {
+		t.Fatalf("divergence found: %v", err)
+	}
+	// Tampered receipts are reported
+	var tampered []*types.Receipt
+	for _, receipt := range receipts[len(receipts)-1] {
+		cpy := *receipt
+		tampered = append(tampered, &cpy)
+	}
+	tampered[0].CumulativeGasUsed++
+	if err := aaFuzzDiffReceipts(receipts[len(receipts)-1], tampered); err == nil || !strings.Contains(err.Error(), "cumulative gas mismatch") {
+		t.Errorf("tampered receipt error mismatch: %v", err)
+	}
+}
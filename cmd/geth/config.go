@@ -181,6 +181,15 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 
 	backend, eth := utils.RegisterEthService(stack, &cfg.Eth)
 
+	// Allow operators to tune the RIP-7560 pool policy without a restart
+	if eth != nil && cfg.Eth.Rip7560Pool.Enabled {
+		eth.SetRip7560PolicyLoader(rip7560PolicyLoader(ctx))
+		stack.RegisterLifecycle(newRip7560PolicyReloader(eth))
+	}
+	if eth != nil && cfg.Eth.Rip7560Bundler.Enabled {
+		utils.RegisterRip7560Bundler(stack, eth, cfg.Eth.Rip7560Bundler)
+	}
+
 	// Create gauge with geth system and build information
 	if eth != nil { // The 'eth' backend may be nil in light mode
 		var protos []string
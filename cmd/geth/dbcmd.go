@@ -83,6 +83,7 @@ Remove blockchain and state databases`,
 			dbMetadataCmd,
 			dbCheckStateContentCmd,
 			dbInspectHistoryCmd,
+			dbMigrateAACmd,
 		},
 	}
 	dbInspectCmd = &cli.Command{
@@ -229,6 +230,21 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		}, utils.NetworkFlags, utils.DatabaseFlags),
 		Description: "This command queries the history of the account or storage slot within the specified block range",
 	}
+	dbMigrateAACmd = &cli.Command{
+		Action: migrateAA,
+		Name:   "migrate-aa",
+		Usage:  "Upgrade the stored receipts of RIP-7560 transactions to the current schema",
+		Flags: flags.Merge([]cli.Flag{
+			utils.SyncModeFlag,
+			utils.GCModeFlag,
+			utils.CacheFlag,
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command re-executes the canonical blocks containing RIP-7560 transactions
+whose receipts were stored without the validation gas, the frame statuses and
+the frames emitting their logs, and rewrites their receipts. The progress is
+persisted, so an interrupted migration resumes where it stopped. Blocks whose
+parent state was pruned or which were already moved to the freezer are skipped.`,
+	}
 )
 
 func removeDB(ctx *cli.Context) error {
@@ -933,3 +949,19 @@ func inspectHistory(ctx *cli.Context) error {
 	}
 	return inspectStorage(triedb, start, end, address, slot, ctx.Bool("raw"))
 }
+
+func migrateAA(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, db := utils.MakeChain(ctx, stack, false)
+	defer db.Close()
+	defer chain.Stop()
+
+	result, err := chain.MigrateRip7560Receipts()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Migrated %d blocks, skipped %d blocks\n", result.Migrated, result.Skipped)
+	return nil
+}
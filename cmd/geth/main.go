@@ -81,11 +81,30 @@ var (
 		utils.BlobPoolDataDirFlag,
 		utils.BlobPoolDataCapFlag,
 		utils.BlobPoolPriceBumpFlag,
+		utils.AAPoolFlag,
+		utils.AAPoolPriceBumpFlag,
+		utils.AAPoolKeySlotsFlag,
+		utils.AAPoolKeyQueueFlag,
+		utils.AAPoolGlobalQueueFlag,
+		utils.AAValidationTimeoutFlag,
+		utils.AABannedOpcodesFlag,
+		utils.AAPaymasterAllowlistFlag,
+		utils.AAPaymasterBanlistFlag,
+		utils.AAMaxCallDepthFlag,
+		utils.AAMaxReentrancyFlag,
+		utils.AATrustedDelegatesFlag,
+		utils.AAReputationFileFlag,
+		utils.AAValidationWorkersFlag,
+		utils.AAMetricsFlag,
+		utils.AABundlerFlag,
+		utils.AABundlerBuilderFlag,
+		utils.AABundlerRecommitFlag,
 		utils.SyncModeFlag,
 		utils.SyncTargetFlag,
 		utils.ExitWhenSyncedFlag,
 		utils.GCModeFlag,
 		utils.SnapshotFlag,
+		utils.NoUnclesFlag,
 		utils.TxLookupLimitFlag, // deprecated
 		utils.TransactionHistoryFlag,
 		utils.StateHistoryFlag,
@@ -117,6 +136,7 @@ var (
 		utils.MaxPendingPeersFlag,
 		utils.MiningEnabledFlag, // deprecated
 		utils.MinerGasLimitFlag,
+		utils.MinerRip7560GasShareFlag,
 		utils.MinerGasPriceFlag,
 		utils.MinerEtherbaseFlag, // deprecated
 		utils.MinerExtraDataFlag,
@@ -138,6 +158,7 @@ var (
 		utils.VMEnableDebugFlag,
 		utils.VMTraceFlag,
 		utils.VMTraceJsonConfigFlag,
+		utils.VMStrictTxTypesFlag,
 		utils.NetworkIdFlag,
 		utils.EthStatsURLFlag,
 		utils.NoCompactionFlag,
@@ -181,6 +202,8 @@ var (
 		utils.WSPathPrefixFlag,
 		utils.IPCDisabledFlag,
 		utils.IPCPathFlag,
+		utils.IPCApiFlag,
+		utils.IPCSocketsFlag,
 		utils.InsecureUnlockAllowedFlag,
 		utils.RPCGlobalGasCapFlag,
 		utils.RPCGlobalEVMTimeoutFlag,
@@ -245,6 +268,8 @@ func init() {
 		snapshotCommand,
 		// See verkle.go
 		verkleCommand,
+		// See aacmd.go
+		aaCommand,
 	}
 	if logTestCommand != nil {
 		app.Commands = append(app.Commands, logTestCommand)
@@ -0,0 +1,90 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/core/txpool/rip7560pool"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+)
+
+// rip7560PolicyLoader returns a function re-reading the RIP-7560 pool policy
+// from the configuration file and the command line flags, the same way it's
+// assembled on startup.
+func rip7560PolicyLoader(ctx *cli.Context) func() (rip7560pool.Config, error) {
+	return func() (rip7560pool.Config, error) {
+		cfg := gethConfig{Eth: ethconfig.Defaults}
+		if file := ctx.String(configFileFlag.Name); file != "" {
+			if err := loadConfig(file, &cfg); err != nil {
+				return rip7560pool.Config{}, err
+			}
+		}
+		utils.SetRip7560PoolConfig(ctx, &cfg.Eth.Rip7560Pool)
+		return cfg.Eth.Rip7560Pool, nil
+	}
+}
+
+// rip7560PolicyReloader is a node lifecycle reloading the RIP-7560 pool policy
+// whenever the process receives a SIGHUP.
+type rip7560PolicyReloader struct {
+	eth  *eth.Ethereum
+	sigs chan os.Signal
+	done chan struct{}
+}
+
+func newRip7560PolicyReloader(backend *eth.Ethereum) *rip7560PolicyReloader {
+	return &rip7560PolicyReloader{
+		eth:  backend,
+		sigs: make(chan os.Signal, 1),
+		done: make(chan struct{}),
+	}
+}
+
+// Start implements node.Lifecycle, subscribing to SIGHUP.
+func (r *rip7560PolicyReloader) Start() error {
+	signal.Notify(r.sigs, syscall.SIGHUP)
+	go r.loop()
+	return nil
+}
+
+// Stop implements node.Lifecycle, unsubscribing from SIGHUP.
+func (r *rip7560PolicyReloader) Stop() error {
+	signal.Stop(r.sigs)
+	close(r.done)
+	return nil
+}
+
+func (r *rip7560PolicyReloader) loop() {
+	for {
+		select {
+		case <-r.sigs:
+			log.Info("Got SIGHUP, reloading RIP-7560 pool policy")
+			if err := r.eth.ReloadRip7560Policy(); err != nil {
+				log.Error("Failed to reload RIP-7560 pool policy", "err", err)
+			}
+		case <-r.done:
+			return
+		}
+	}
+}
@@ -75,7 +75,8 @@ WARNING: it's only supported in hash mode(--state.scheme=hash)".
 geth snapshot verify-state <state-root>
 will traverse the whole accounts and storages set based on the specified
 snapshot and recalculate the root hash of state for verification.
-In other words, this command does the snapshot to trie conversion.
+In other words, this command does the snapshot to trie conversion. The storage
+of the accounts is verified in parallel, using one account range per CPU.
 `,
 			},
 			{
@@ -43,6 +43,7 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
+	"github.com/ethereum/go-ethereum/core/txpool/rip7560pool"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/crypto/kzg4844"
@@ -52,6 +53,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/eth/filters"
 	"github.com/ethereum/go-ethereum/eth/gasprice"
+	"github.com/ethereum/go-ethereum/eth/rip7560bundler"
 	"github.com/ethereum/go-ethereum/eth/tracers"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/ethdb/remotedb"
@@ -228,6 +230,11 @@ var (
 		Value:    true,
 		Category: flags.EthCategory,
 	}
+	NoUnclesFlag = &cli.BoolFlag{
+		Name:     "nouncles",
+		Usage:    "Post-merge only processing: reject blocks with uncles and skip uncle handling",
+		Category: flags.EthCategory,
+	}
 	LightKDFFlag = &cli.BoolFlag{
 		Name:     "lightkdf",
 		Usage:    "Reduce key-derivation RAM & CPU usage at some expense of KDF strength",
@@ -419,6 +426,112 @@ var (
 		Value:    ethconfig.Defaults.BlobPool.PriceBump,
 		Category: flags.BlobPoolCategory,
 	}
+	// Account abstraction settings
+	AAPoolFlag = &cli.BoolFlag{
+		Name:     "aa.pool",
+		Usage:    "Enable the RIP-7560 transaction pool",
+		Value:    ethconfig.Defaults.Rip7560Pool.Enabled,
+		Category: flags.AACategory,
+	}
+	AAPoolPriceBumpFlag = &cli.Uint64Flag{
+		Name:     "aa.pool.pricebump",
+		Usage:    "Price bump percentage to replace an already existing RIP-7560 transaction",
+		Value:    ethconfig.Defaults.Rip7560Pool.Queue.PriceBump,
+		Category: flags.AACategory,
+	}
+	AAPoolKeySlotsFlag = &cli.Uint64Flag{
+		Name:     "aa.pool.keyslots",
+		Usage:    "Executable RIP-7560 transaction slots guaranteed per nonce sequence",
+		Value:    ethconfig.Defaults.Rip7560Pool.Queue.KeySlots,
+		Category: flags.AACategory,
+	}
+	AAPoolKeyQueueFlag = &cli.Uint64Flag{
+		Name:     "aa.pool.keyqueue",
+		Usage:    "Maximum number of non-executable RIP-7560 transaction slots permitted per nonce sequence",
+		Value:    ethconfig.Defaults.Rip7560Pool.Queue.KeyQueue,
+		Category: flags.AACategory,
+	}
+	AAPoolGlobalQueueFlag = &cli.Uint64Flag{
+		Name:     "aa.pool.globalqueue",
+		Usage:    "Maximum number of non-executable RIP-7560 transaction slots for all nonce sequences",
+		Value:    ethconfig.Defaults.Rip7560Pool.Queue.GlobalQueue,
+		Category: flags.AACategory,
+	}
+	AAValidationTimeoutFlag = &cli.DurationFlag{
+		Name:     "aa.validationtimeout",
+		Usage:    "Maximum time allowed to simulate the validation phase of a RIP-7560 transaction (0 = unlimited)",
+		Value:    ethconfig.Defaults.Rip7560Pool.ValidationTimeout,
+		Category: flags.AACategory,
+	}
+	AABannedOpcodesFlag = &cli.StringFlag{
+		Name:     "aa.bannedopcodes",
+		Usage:    "Handling of RIP-7560 validation frames using banned opcodes (enforce, warn, off)",
+		Value:    ethconfig.Defaults.Rip7560Pool.BannedOpcodes,
+		Category: flags.AACategory,
+	}
+	AAPaymasterAllowlistFlag = &cli.StringFlag{
+		Name:     "aa.paymasterallowlist",
+		Usage:    "File listing the only paymasters accepted by the RIP-7560 pool, one address per line",
+		Category: flags.AACategory,
+	}
+	AAPaymasterBanlistFlag = &cli.StringFlag{
+		Name:     "aa.paymasterbanlist",
+		Usage:    "File listing the paymasters rejected by the RIP-7560 pool, one address per line",
+		Category: flags.AACategory,
+	}
+	AAMaxCallDepthFlag = &cli.Uint64Flag{
+		Name:     "aa.maxcalldepth",
+		Usage:    "Maximum depth of the inner calls of RIP-7560 validation frames accepted by the pool (0 = unlimited)",
+		Value:    ethconfig.Defaults.Rip7560Pool.MaxCallDepth,
+		Category: flags.AACategory,
+	}
+	AAMaxReentrancyFlag = &cli.Uint64Flag{
+		Name:     "aa.maxreentrancy",
+		Usage:    "Maximum number of times an address may be on the call stack of a RIP-7560 validation frame (0 = unlimited)",
+		Value:    ethconfig.Defaults.Rip7560Pool.MaxReentrancy,
+		Category: flags.AACategory,
+	}
+	AATrustedDelegatesFlag = &cli.StringFlag{
+		Name:     "aa.trusteddelegates",
+		Usage:    "File listing the only DELEGATECALL targets of RIP-7560 validation frames accepted by the pool, one address per line",
+		Category: flags.AACategory,
+	}
+	AAReputationFileFlag = &cli.StringFlag{
+		Name:     "aa.reputationfile",
+		Usage:    "File persisting the reputation of RIP-7560 paymasters and deployers across restarts (empty to keep it in memory)",
+		Value:    ethconfig.Defaults.Rip7560Pool.ReputationFile,
+		Category: flags.AACategory,
+	}
+	AAValidationWorkersFlag = &cli.IntFlag{
+		Name:     "aa.validationworkers",
+		Usage:    "Number of RIP-7560 validation phases run concurrently when processing blocks (0 = serial)",
+		Value:    ethconfig.Defaults.Rip7560.ValidationWorkers,
+		Category: flags.AACategory,
+	}
+	AAMetricsFlag = &cli.BoolFlag{
+		Name:     "aa.metrics",
+		Usage:    "Report RIP-7560 transaction pool metrics",
+		Value:    ethconfig.Defaults.Rip7560Pool.Metrics,
+		Category: flags.AACategory,
+	}
+	AABundlerFlag = &cli.BoolFlag{
+		Name:     "aa.bundler",
+		Usage:    "Enable the in-process bundler, batching the RIP-7560 pool transactions into bundles",
+		Value:    ethconfig.Defaults.Rip7560Bundler.Enabled,
+		Category: flags.AACategory,
+	}
+	AABundlerBuilderFlag = &cli.StringFlag{
+		Name:     "aa.bundler.builder",
+		Usage:    "URL of the block builder receiving the bundles of the in-process bundler (empty to hand them to the local miner)",
+		Value:    ethconfig.Defaults.Rip7560Bundler.Builder,
+		Category: flags.AACategory,
+	}
+	AABundlerRecommitFlag = &cli.DurationFlag{
+		Name:     "aa.bundler.recommit",
+		Usage:    "Time interval for the in-process bundler to rebuild the bundle of the next block",
+		Value:    ethconfig.Defaults.Rip7560Bundler.Recommit,
+		Category: flags.AACategory,
+	}
 	// Performance tuning settings
 	CacheFlag = &cli.IntFlag{
 		Name:     "cache",
@@ -485,6 +598,12 @@ var (
 		Value:    ethconfig.Defaults.Miner.GasCeil,
 		Category: flags.MinerCategory,
 	}
+	MinerRip7560GasShareFlag = &cli.Uint64Flag{
+		Name:     "miner.rip7560gasshare",
+		Usage:    "Percentage of the block gas limit reserved for RIP-7560 bundles",
+		Value:    ethconfig.Defaults.Miner.Rip7560GasShare,
+		Category: flags.MinerCategory,
+	}
 	MinerGasPriceFlag = &flags.BigFlag{
 		Name:     "miner.gasprice",
 		Usage:    "Minimum gas price for mining a transaction",
@@ -549,6 +668,11 @@ var (
 		Usage:    "Tracer configuration (JSON)",
 		Category: flags.VMCategory,
 	}
+	VMStrictTxTypesFlag = &cli.BoolFlag{
+		Name:     "vm.stricttxtypes",
+		Usage:    "Strict processing: reject blocks with transaction types not active at the block",
+		Category: flags.VMCategory,
+	}
 	// API options.
 	RPCGlobalGasCapFlag = &cli.Uint64Flag{
 		Name:     "rpc.gascap",
@@ -624,6 +748,16 @@ var (
 		Usage:    "Filename for IPC socket/pipe within the datadir (explicit paths escape it)",
 		Category: flags.APICategory,
 	}
+	IPCApiFlag = &cli.StringFlag{
+		Name:     "ipc.api",
+		Usage:    "API's offered over the IPC interface (all of them if not set)",
+		Category: flags.APICategory,
+	}
+	IPCSocketsFlag = &cli.StringFlag{
+		Name:     "ipc.sockets",
+		Usage:    "Additional IPC sockets with the API's they offer, e.g. \"bundler.ipc=aa,debug;other.ipc=eth\"",
+		Category: flags.APICategory,
+	}
 	HTTPEnabledFlag = &cli.BoolFlag{
 		Name:     "http",
 		Usage:    "Enable the HTTP-RPC server",
@@ -1236,6 +1370,37 @@ func setIPC(ctx *cli.Context, cfg *node.Config) {
 	case ctx.IsSet(IPCPathFlag.Name):
 		cfg.IPCPath = ctx.String(IPCPathFlag.Name)
 	}
+	if ctx.IsSet(IPCApiFlag.Name) {
+		cfg.IPCModules = SplitAndTrim(ctx.String(IPCApiFlag.Name))
+	}
+	if ctx.IsSet(IPCSocketsFlag.Name) {
+		sockets, err := parseIPCSockets(ctx.String(IPCSocketsFlag.Name))
+		if err != nil {
+			Fatalf("Invalid --%s value: %v", IPCSocketsFlag.Name, err)
+		}
+		cfg.IPCSockets = sockets
+	}
+}
+
+// parseIPCSockets parses semicolon separated IPC socket specifications of the
+// form path=module,module.
+func parseIPCSockets(input string) ([]node.IPCSocket, error) {
+	var sockets []node.IPCSocket
+	for _, spec := range strings.Split(input, ";") {
+		if spec = strings.TrimSpace(spec); spec == "" {
+			continue
+		}
+		path, modules, ok := strings.Cut(spec, "=")
+		if !ok || strings.TrimSpace(path) == "" {
+			return nil, fmt.Errorf("socket %q not in the form path=module,module", spec)
+		}
+		socket := node.IPCSocket{Path: strings.TrimSpace(path), Modules: SplitAndTrim(modules)}
+		if len(socket.Modules) == 0 {
+			return nil, fmt.Errorf("no modules for socket %q", socket.Path)
+		}
+		sockets = append(sockets, socket)
+	}
+	return sockets, nil
 }
 
 // setLes shows the deprecation warnings for LES flags.
@@ -1545,6 +1710,65 @@ func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
 	}
 }
 
+// SetRip7560PoolConfig applies the RIP-7560 pool related command line flags
+// to the config. The result is not validated.
+func SetRip7560PoolConfig(ctx *cli.Context, cfg *rip7560pool.Config) {
+	if ctx.IsSet(AAPoolFlag.Name) {
+		cfg.Enabled = ctx.Bool(AAPoolFlag.Name)
+	}
+	if ctx.IsSet(AAPoolPriceBumpFlag.Name) {
+		cfg.Queue.PriceBump = ctx.Uint64(AAPoolPriceBumpFlag.Name)
+	}
+	if ctx.IsSet(AAPoolKeySlotsFlag.Name) {
+		cfg.Queue.KeySlots = ctx.Uint64(AAPoolKeySlotsFlag.Name)
+	}
+	if ctx.IsSet(AAPoolKeyQueueFlag.Name) {
+		cfg.Queue.KeyQueue = ctx.Uint64(AAPoolKeyQueueFlag.Name)
+	}
+	if ctx.IsSet(AAPoolGlobalQueueFlag.Name) {
+		cfg.Queue.GlobalQueue = ctx.Uint64(AAPoolGlobalQueueFlag.Name)
+	}
+	if ctx.IsSet(AAValidationTimeoutFlag.Name) {
+		cfg.ValidationTimeout = ctx.Duration(AAValidationTimeoutFlag.Name)
+	}
+	if ctx.IsSet(AABannedOpcodesFlag.Name) {
+		cfg.BannedOpcodes = ctx.String(AABannedOpcodesFlag.Name)
+	}
+	if ctx.IsSet(AAPaymasterAllowlistFlag.Name) {
+		cfg.PaymasterAllowlist = ctx.String(AAPaymasterAllowlistFlag.Name)
+	}
+	if ctx.IsSet(AAPaymasterBanlistFlag.Name) {
+		cfg.PaymasterBanlist = ctx.String(AAPaymasterBanlistFlag.Name)
+	}
+	if ctx.IsSet(AAMaxCallDepthFlag.Name) {
+		cfg.MaxCallDepth = ctx.Uint64(AAMaxCallDepthFlag.Name)
+	}
+	if ctx.IsSet(AAMaxReentrancyFlag.Name) {
+		cfg.MaxReentrancy = ctx.Uint64(AAMaxReentrancyFlag.Name)
+	}
+	if ctx.IsSet(AATrustedDelegatesFlag.Name) {
+		cfg.TrustedDelegates = ctx.String(AATrustedDelegatesFlag.Name)
+	}
+	if ctx.IsSet(AAReputationFileFlag.Name) {
+		cfg.ReputationFile = ctx.String(AAReputationFileFlag.Name)
+	}
+	if ctx.IsSet(AAMetricsFlag.Name) {
+		cfg.Metrics = ctx.Bool(AAMetricsFlag.Name)
+	}
+}
+
+func setRip7560Bundler(ctx *cli.Context, cfg *rip7560bundler.Config) {
+	if ctx.IsSet(AABundlerFlag.Name) {
+		cfg.Enabled = ctx.Bool(AABundlerFlag.Name)
+	}
+	if ctx.IsSet(AABundlerBuilderFlag.Name) {
+		cfg.Builder = ctx.String(AABundlerBuilderFlag.Name)
+	}
+	if ctx.IsSet(AABundlerRecommitFlag.Name) {
+		cfg.Recommit = ctx.Duration(AABundlerRecommitFlag.Name)
+	}
+}
+
 func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.Bool(MiningEnabledFlag.Name) {
 		log.Warn("The flag --mine is deprecated and will be removed")
@@ -1555,6 +1779,12 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.IsSet(MinerGasLimitFlag.Name) {
 		cfg.GasCeil = ctx.Uint64(MinerGasLimitFlag.Name)
 	}
+	if ctx.IsSet(MinerRip7560GasShareFlag.Name) {
+		cfg.Rip7560GasShare = ctx.Uint64(MinerRip7560GasShareFlag.Name)
+		if cfg.Rip7560GasShare > 100 {
+			Fatalf("Invalid --%s value %d, must be at most 100", MinerRip7560GasShareFlag.Name, cfg.Rip7560GasShare)
+		}
+	}
 	if ctx.IsSet(MinerGasPriceFlag.Name) {
 		cfg.GasPrice = flags.GlobalBig(ctx, MinerGasPriceFlag.Name)
 	}
@@ -1646,6 +1876,11 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	setEtherbase(ctx, cfg)
 	setGPO(ctx, &cfg.GPO)
 	setTxPool(ctx, &cfg.TxPool)
+	SetRip7560PoolConfig(ctx, &cfg.Rip7560Pool)
+	if err := cfg.Rip7560Pool.Validate(); err != nil {
+		Fatalf("Invalid RIP-7560 pool configuration: %v", err)
+	}
+	setRip7560Bundler(ctx, &cfg.Rip7560Bundler)
 	setMiner(ctx, &cfg.Miner)
 	setRequiredBlocks(ctx, cfg)
 	setLes(ctx, cfg)
@@ -1760,6 +1995,15 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		// TODO(fjl): force-enable this in --dev mode
 		cfg.EnablePreimageRecording = ctx.Bool(VMEnableDebugFlag.Name)
 	}
+	if ctx.IsSet(NoUnclesFlag.Name) {
+		cfg.NoUncles = ctx.Bool(NoUnclesFlag.Name)
+	}
+	if ctx.IsSet(VMStrictTxTypesFlag.Name) {
+		cfg.StrictTxTypes = ctx.Bool(VMStrictTxTypesFlag.Name)
+	}
+	if ctx.IsSet(AAValidationWorkersFlag.Name) {
+		cfg.Rip7560.ValidationWorkers = ctx.Int(AAValidationWorkersFlag.Name)
+	}
 
 	if ctx.IsSet(RPCGlobalGasCapFlag.Name) {
 		cfg.RPCGasCap = ctx.Uint64(RPCGlobalGasCapFlag.Name)
@@ -1966,6 +2210,16 @@ func RegisterFilterAPI(stack *node.Node, backend ethapi.Backend, ethcfg *ethconf
 	return filterSystem
 }
 
+// RegisterRip7560Bundler adds the in-process RIP-7560 bundler to the node,
+// drawing the transactions from the RIP-7560 pool of the given backend.
+func RegisterRip7560Bundler(stack *node.Node, eth *eth.Ethereum, cfg rip7560bundler.Config) {
+	pool := eth.Rip7560Pool()
+	if pool == nil {
+		Fatalf("The RIP-7560 bundler requires the RIP-7560 pool (--%s)", AAPoolFlag.Name)
+	}
+	stack.RegisterLifecycle(rip7560bundler.New(cfg, eth.BlockChain(), pool))
+}
+
 // RegisterFullSyncTester adds the full-sync tester service into node.
 func RegisterFullSyncTester(stack *node.Node, eth *eth.Ethereum, target common.Hash) {
 	catalyst.RegisterFullSyncTester(stack, eth, target)
@@ -2171,6 +2425,10 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readonly bool) (*core.BlockCh
 		Preimages:           ctx.Bool(CachePreimagesFlag.Name),
 		StateScheme:         scheme,
 		StateHistory:        ctx.Uint64(StateHistoryFlag.Name),
+
+		NoUncles:                 ctx.Bool(NoUnclesFlag.Name),
+		StrictTxTypes:            ctx.Bool(VMStrictTxTypesFlag.Name),
+		Rip7560ValidationWorkers: ctx.Int(AAValidationWorkersFlag.Name),
 	}
 	if cache.TrieDirtyDisabled && !cache.Preimages {
 		cache.Preimages = true
@@ -2190,7 +2448,9 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readonly bool) (*core.BlockCh
 	if ctx.IsSet(CacheFlag.Name) || ctx.IsSet(CacheGCFlag.Name) {
 		cache.TrieDirtyLimit = ctx.Int(CacheFlag.Name) * ctx.Int(CacheGCFlag.Name) / 100
 	}
-	vmcfg := vm.Config{EnablePreimageRecording: ctx.Bool(VMEnableDebugFlag.Name)}
+	vmcfg := vm.Config{
+		EnablePreimageRecording: ctx.Bool(VMEnableDebugFlag.Name),
+	}
 	if ctx.IsSet(VMTraceFlag.Name) {
 		if name := ctx.String(VMTraceFlag.Name); name != "" {
 			var config json.RawMessage
@@ -20,6 +20,8 @@ package utils
 import (
 	"reflect"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/node"
 )
 
 func Test_SplitTagsFlag(t *testing.T) {
@@ -65,3 +67,33 @@ func Test_SplitTagsFlag(t *testing.T) {
 		})
 	}
 }
+
+func Test_parseIPCSockets(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		input string
+		want  []node.IPCSocket
+		fail  bool
+	}{
+		{"empty case", "", nil, false},
+		{"1 socket case", "bundler.ipc=aa,debug", []node.IPCSocket{{Path: "bundler.ipc", Modules: []string{"aa", "debug"}}}, false},
+		{"2 sockets case", " a.ipc = eth ; /tmp/b.ipc=aa;", []node.IPCSocket{{Path: "a.ipc", Modules: []string{"eth"}}, {Path: "/tmp/b.ipc", Modules: []string{"aa"}}}, false},
+		{"no modules", "bundler.ipc=", nil, true},
+		{"no path", "=aa", nil, true},
+		{"garbage", "bundler.ipc", nil, true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := parseIPCSockets(tt.input)
+			if (err != nil) != tt.fail {
+				t.Fatalf("parseIPCSockets() error = %v, want failure %v", err, tt.fail)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseIPCSockets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
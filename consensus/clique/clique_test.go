@@ -123,3 +123,80 @@ func TestSealHash(t *testing.T) {
 		t.Errorf("have %x, want %x", have, want)
 	}
 }
+
+// Tests that RIP-7560 account abstraction transactions can be included in and
+// imported from Clique sealed blocks, so PoA devnets can run without a beacon
+// client.
+func TestRip7560Import(t *testing.T) {
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		engine = New(params.AllCliqueProtocolChanges.Clique, rawdb.NewMemoryDatabase())
+		config = *params.AllCliqueProtocolChanges
+
+		account  = common.HexToAddress("0xaa")
+		selector = core.Rip7560Abi.Methods["acceptAccount"].ID
+	)
+	config.RIP7560Block = big.NewInt(0)
+
+	// The account accepts any transaction by calling acceptAccount(0, 0) on
+	// the entry point.
+	code := []byte{byte(vm.PUSH4)}
+	code = append(code, selector...)
+	code = append(code,
+		byte(vm.PUSH1), 0xe0, byte(vm.SHL), byte(vm.PUSH1), 0, byte(vm.MSTORE),
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0x44, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0,
+		byte(vm.PUSH2), 0x75, 0x60, byte(vm.GAS), byte(vm.CALL), byte(vm.STOP),
+	)
+	genspec := &core.Genesis{
+		Config:    &config,
+		ExtraData: make([]byte, extraVanity+common.AddressLength+extraSeal),
+		Alloc: map[common.Address]types.Account{
+			addr:    {Balance: big.NewInt(10000000000000000)},
+			account: {Balance: big.NewInt(params.Ether), Code: code},
+		},
+		BaseFee: big.NewInt(params.InitialBaseFee),
+	}
+	copy(genspec.ExtraData[extraVanity:], addr[:])
+
+	_, blocks, _ := core.GenerateChainWithGenesis(genspec, engine, 2, func(i int, block *core.BlockGen) {
+		block.SetDifficulty(diffInTurn)
+		block.AddTx(types.NewTx(&types.Rip7560AccountAbstractionTx{
+			ChainID:            config.ChainID,
+			Nonce:              uint64(i),
+			NonceKey:           new(big.Int),
+			Sender:             &account,
+			GasTipCap:          new(big.Int),
+			GasFeeCap:          new(big.Int).Mul(block.BaseFee(), common.Big2),
+			Gas:                100_000,
+			ValidationGasLimit: 100_000,
+			BuilderFee:         new(big.Int),
+		}))
+	})
+	for i, block := range blocks {
+		header := block.Header()
+		if i > 0 {
+			header.ParentHash = blocks[i-1].Hash()
+		}
+		header.Extra = make([]byte, extraVanity+extraSeal)
+		header.Difficulty = diffInTurn
+
+		sig, _ := crypto.Sign(SealHash(header).Bytes(), key)
+		copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+		blocks[i] = block.WithSeal(header)
+	}
+	chain, _ := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, genspec, nil, engine, vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	state, _ := chain.State()
+	if nonce := state.GetNonce(account); nonce != 2 {
+		t.Fatalf("account nonce mismatch: have %d, want %d", nonce, 2)
+	}
+	receipts := chain.GetReceiptsByHash(blocks[1].Hash())
+	if len(receipts) != 1 || receipts[0].Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("unexpected receipts: %v", receipts)
+	}
+}
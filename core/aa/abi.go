@@ -0,0 +1,141 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package aa defines the interfaces RIP-7560 accounts and paymasters implement
+// for the frames the protocol calls them in, with one revision per ABI version.
+//
+// The state processor encodes the frame calls through the revision selected by
+// the chain config, so a new revision of the interfaces only needs to be added
+// here. Deployers are not part of the versioned interfaces, the deployer frame
+// calls the deployer with the deployer data of the transaction as is.
+package aa
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/internal/abienc"
+	"github.com/holiman/uint256"
+)
+
+// Versions of the account and paymaster interfaces.
+const (
+	Version0 uint64 = 0 // validateTransaction, validatePaymasterTransaction and postPaymasterTransaction
+
+	LatestVersion = Version0
+)
+
+// version0JSON is the ABI of the account and paymaster interfaces of Version0.
+const version0JSON = `[
+	{
+		"type": "function",
+		"name": "validateTransaction",
+		"inputs": [
+			{"name": "version", "type": "uint256"},
+			{"name": "txHash", "type": "bytes32"},
+			{"name": "transaction", "type": "bytes"}
+		]
+	},
+	{
+		"type": "function",
+		"name": "validatePaymasterTransaction",
+		"inputs": [
+			{"name": "version", "type": "uint256"},
+			{"name": "txHash", "type": "bytes32"},
+			{"name": "transaction", "type": "bytes"}
+		]
+	},
+	{
+		"type": "function",
+		"name": "postPaymasterTransaction",
+		"inputs": [
+			{"name": "success", "type": "bool"},
+			{"name": "actualGasCost", "type": "uint256"},
+			{"name": "context", "type": "bytes"}
+		]
+	}
+]`
+
+// FrameABI is a revision of the interfaces accounts and paymasters implement,
+// encoding the calldata of the frames they are called in. The encoders append
+// to the given buffer like the ones of the internal/abienc package.
+type FrameABI struct {
+	Version uint64  // Version passed to the validation frames
+	ABI     abi.ABI // Interfaces of the accounts and paymasters
+
+	validateTransaction          func(dst []byte, version uint64, txHash common.Hash, transaction []byte) []byte
+	validatePaymasterTransaction func(dst []byte, version uint64, txHash common.Hash, transaction []byte) []byte
+	postPaymasterTransaction     func(dst []byte, success bool, actualGasCost *uint256.Int, context []byte) []byte
+}
+
+// frameABIs are the supported revisions of the interfaces, by version.
+var frameABIs = map[uint64]*FrameABI{
+	Version0: {
+		Version:                      Version0,
+		ABI:                          mustParse(version0JSON),
+		validateTransaction:          abienc.AppendValidateTransaction,
+		validatePaymasterTransaction: abienc.AppendValidatePaymasterTransaction,
+		postPaymasterTransaction:     abienc.AppendPostPaymasterTransaction,
+	},
+}
+
+func mustParse(json string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(json))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// Lookup returns the revision of the interfaces with the given version.
+func Lookup(version uint64) (*FrameABI, error) {
+	frameABI, ok := frameABIs[version]
+	if !ok {
+		return nil, fmt.Errorf("unsupported RIP-7560 ABI version %d", version)
+	}
+	return frameABI, nil
+}
+
+// Versions returns the supported versions of the interfaces in ascending order.
+func Versions() []uint64 {
+	versions := make([]uint64, 0, len(frameABIs))
+	for version := range frameABIs {
+		versions = append(versions, version)
+	}
+	slices.Sort(versions)
+	return versions
+}
+
+// AppendValidateTransaction appends the calldata of the account validation frame
+// of a transaction with the given signing hash and ABI encoding.
+func (f *FrameABI) AppendValidateTransaction(dst []byte, txHash common.Hash, transaction []byte) []byte {
+	return f.validateTransaction(dst, f.Version, txHash, transaction)
+}
+
+// AppendValidatePaymasterTransaction appends the calldata of the paymaster
+// validation frame of a transaction with the given signing hash and ABI encoding.
+func (f *FrameABI) AppendValidatePaymasterTransaction(dst []byte, txHash common.Hash, transaction []byte) []byte {
+	return f.validatePaymasterTransaction(dst, f.Version, txHash, transaction)
+}
+
+// AppendPostPaymasterTransaction appends the calldata of the paymaster postOp
+// frame with the outcome of the execution, its cost and the paymaster context.
+func (f *FrameABI) AppendPostPaymasterTransaction(dst []byte, success bool, actualGasCost *uint256.Int, context []byte) []byte {
+	return f.postPaymasterTransaction(dst, success, actualGasCost, context)
+}
@@ -0,0 +1,64 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package aa
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holiman/uint256"
+)
+
+// Tests that every revision encodes the frame calls as described by its ABI.
+func TestFrameABIs(t *testing.T) {
+	var (
+		hash = common.HexToHash("0xdeadbeef")
+		data = bytes.Repeat([]byte{0xab}, 33)
+	)
+	for _, version := range Versions() {
+		frameABI, err := Lookup(version)
+		if err != nil {
+			t.Fatalf("version %d: lookup failed: %v", version, err)
+		}
+		want, err := frameABI.ABI.Pack("validateTransaction", new(big.Int).SetUint64(version), hash, data)
+		if err != nil {
+			t.Fatalf("version %d: failed to pack validateTransaction: %v", version, err)
+		}
+		if have := frameABI.AppendValidateTransaction(nil, hash, data); !bytes.Equal(have, want) {
+			t.Errorf("version %d: validateTransaction mismatch: have %x, want %x", version, have, want)
+		}
+		want, err = frameABI.ABI.Pack("validatePaymasterTransaction", new(big.Int).SetUint64(version), hash, data)
+		if err != nil {
+			t.Fatalf("version %d: failed to pack validatePaymasterTransaction: %v", version, err)
+		}
+		if have := frameABI.AppendValidatePaymasterTransaction(nil, hash, data); !bytes.Equal(have, want) {
+			t.Errorf("version %d: validatePaymasterTransaction mismatch: have %x, want %x", version, have, want)
+		}
+		want, err = frameABI.ABI.Pack("postPaymasterTransaction", true, big.NewInt(123456), data)
+		if err != nil {
+			t.Fatalf("version %d: failed to pack postPaymasterTransaction: %v", version, err)
+		}
+		if have := frameABI.AppendPostPaymasterTransaction(nil, true, uint256.NewInt(123456), data); !bytes.Equal(have, want) {
+			t.Errorf("version %d: postPaymasterTransaction mismatch: have %x, want %x", version, have, want)
+		}
+	}
+	if _, err := Lookup(LatestVersion + 1); err == nil {
+		t.Error("unknown version accepted")
+	}
+}
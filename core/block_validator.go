@@ -19,6 +19,7 @@ package core
 import (
 	"errors"
 	"fmt"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core/state"
@@ -59,11 +60,19 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 	// Header validity is known at this point. Here we verify that uncles, transactions
 	// and withdrawals given in the block body match the header.
 	header := block.Header()
-	if err := v.engine.VerifyUncles(v.bc, block); err != nil {
-		return err
-	}
-	if hash := types.CalcUncleHash(block.Uncles()); hash != header.UncleHash {
-		return fmt.Errorf("uncle root hash mismatch (header value %x, calculated %x)", header.UncleHash, hash)
+	if v.bc.cacheConfig.NoUncles {
+		// Post-merge only mode: uncles are never valid, so there is no need
+		// to run them through the consensus engine or hash them.
+		if err := verifyNoUncles(block); err != nil {
+			return err
+		}
+	} else {
+		if err := v.engine.VerifyUncles(v.bc, block); err != nil {
+			return err
+		}
+		if hash := types.CalcUncleHash(block.Uncles()); hash != header.UncleHash {
+			return fmt.Errorf("uncle root hash mismatch (header value %x, calculated %x)", header.UncleHash, hash)
+		}
 	}
 	if hash := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); hash != header.TxHash {
 		return fmt.Errorf("transaction root hash mismatch (header value %x, calculated %x)", header.TxHash, hash)
@@ -84,11 +93,25 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 	}
 
 	// Blob transactions may be present after the Cancun fork.
-	var blobs int
+	var (
+		blobs int
+		aaTxs uint64
+	)
 	for i, tx := range block.Transactions() {
 		// Count the number of blobs to validate against the header's blobGasUsed
 		blobs += len(tx.BlobHashes())
 
+		// RIP-7560 transactions may only be present after its activation.
+		if tx.Type() == types.Rip7560Type {
+			if !v.config.IsRIP7560(block.Number(), block.Time()) {
+				return fmt.Errorf("%w: rip7560 transaction at index %d before activation", ErrTxTypeNotSupported, i)
+			}
+			if tx.Rip7560LegacyType() && !v.config.IsRIP7560LegacyType(block.Number()) {
+				return fmt.Errorf("%w: rip7560 transaction at index %d with legacy type", ErrTxTypeNotSupported, i)
+			}
+			aaTxs++
+		}
+
 		// If the tx is a blob tx, it must NOT have a sidecar attached to be valid in a block.
 		if tx.BlobTxSidecar() != nil {
 			return fmt.Errorf("unexpected blob sidecar in transaction at index %d", i)
@@ -109,6 +132,17 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 		}
 	}
 
+	// Check the optional cap on the number of RIP-7560 transactions.
+	if limit := v.config.Rip7560MaxTxsPerBlock(); limit > 0 && aaTxs > limit {
+		return fmt.Errorf("%w: have %d, limit %d", ErrRip7560TxCountExceeded, aaTxs, limit)
+	}
+	// Check the optional ordering of the RIP-7560 bundles.
+	if v.config.Rip7560OrderedBundles() {
+		if err := VerifyRip7560BundleOrder(block.Transactions()); err != nil {
+			return err
+		}
+	}
+
 	// Ancestor block must be known.
 	if !v.bc.HasBlockAndState(block.ParentHash(), block.NumberU64()-1) {
 		if !v.bc.HasBlock(block.ParentHash(), block.NumberU64()-1) {
@@ -119,6 +153,62 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 	return nil
 }
 
+// verifyTxTypes checks that all transactions of the block are of a type active
+// at the block, as required for strict processing.
+func verifyTxTypes(config *params.ChainConfig, block *types.Block) error {
+	for i, tx := range block.Transactions() {
+		if !txTypeSupported(config, block.Number(), block.Time(), tx.Type()) {
+			return fmt.Errorf("%w: type %d transaction at index %d in block #%d", ErrTxTypeNotSupported, tx.Type(), i, block.NumberU64())
+		}
+	}
+	return nil
+}
+
+// SupportedTxTypes returns the transaction types which are fully implemented and
+// active at the given block, in ascending order.
+func SupportedTxTypes(config *params.ChainConfig, num *big.Int, time uint64) []uint8 {
+	var supported []uint8
+	for _, txType := range []uint8{types.LegacyTxType, types.AccessListTxType, types.DynamicFeeTxType, types.BlobTxType, types.SetCodeTxType, types.Rip7560Type} {
+		if txTypeSupported(config, num, time, txType) {
+			supported = append(supported, txType)
+		}
+	}
+	return supported
+}
+
+// txTypeSupported reports whether transactions of the given type are fully
+// implemented and active at the given block.
+func txTypeSupported(config *params.ChainConfig, num *big.Int, time uint64, txType uint8) bool {
+	switch txType {
+	case types.LegacyTxType:
+		return true
+	case types.AccessListTxType:
+		return config.IsBerlin(num)
+	case types.DynamicFeeTxType:
+		return config.IsLondon(num)
+	case types.BlobTxType:
+		return config.IsCancun(num, time)
+	case types.Rip7560Type:
+		return config.IsRIP7560(num, time)
+	case types.SetCodeTxType:
+		return config.IsPrague(num, time)
+	default:
+		return false
+	}
+}
+
+// verifyNoUncles checks that the block neither carries uncles nor commits to
+// any in its header, as required for post-merge blocks.
+func verifyNoUncles(block *types.Block) error {
+	if len(block.Uncles()) > 0 {
+		return fmt.Errorf("%w: block #%d has %d uncles", ErrUnclesNotAllowed, block.NumberU64(), len(block.Uncles()))
+	}
+	if hash := block.UncleHash(); hash != types.EmptyUncleHash {
+		return fmt.Errorf("%w: block #%d has uncle hash %x", ErrUnclesNotAllowed, block.NumberU64(), hash)
+	}
+	return nil
+}
+
 // ValidateState validates the various changes that happen after a state transition,
 // such as amount of used gas, the receipt roots and the state root itself.
 func (v *BlockValidator) ValidateState(block *types.Block, statedb *state.StateDB, receipts types.Receipts, usedGas uint64) error {
@@ -126,6 +216,18 @@ func (v *BlockValidator) ValidateState(block *types.Block, statedb *state.StateD
 	if block.GasUsed() != usedGas {
 		return fmt.Errorf("invalid gas used (remote: %d local: %d)", block.GasUsed(), usedGas)
 	}
+	// Check the optional cap on the gas used by RIP-7560 transactions.
+	if limit := v.config.Rip7560MaxGasPerBlock(); limit > 0 {
+		var aaGas uint64
+		for _, receipt := range receipts {
+			if receipt.Type == types.Rip7560Type {
+				aaGas += receipt.GasUsed
+			}
+		}
+		if aaGas > limit {
+			return fmt.Errorf("%w: have %d, limit %d", ErrRip7560GasExceeded, aaGas, limit)
+		}
+	}
 	// Validate the received block's bloom with the one derived from the generated receipts.
 	// For valid blocks this should always validate to true.
 	rbloom := types.CreateBloom(receipts)
@@ -17,6 +17,7 @@
 package core
 
 import (
+	"errors"
 	"math/big"
 	"testing"
 	"time"
@@ -31,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
 )
 
 // Tests that simple header verification works, for both good and bad blocks.
@@ -84,6 +86,227 @@ func testHeaderVerification(t *testing.T, scheme string) {
 	}
 }
 
+// Tests that the post-merge only mode rejects blocks carrying uncles, while
+// still importing uncle-free chains.
+func TestBodyValidationNoUncles(t *testing.T) {
+	var (
+		gspec        = &Genesis{Config: params.TestChainConfig}
+		_, blocks, _ = GenerateChainWithGenesis(gspec, ethash.NewFaker(), 4, nil)
+		_, uncled, _ = GenerateChainWithGenesis(gspec, ethash.NewFaker(), 4, func(i int, gen *BlockGen) {
+			if i == 2 {
+				uncle := gen.PrevBlock(i - 1).Header()
+				uncle.Extra = []byte("foo")
+				gen.AddUncle(uncle)
+			}
+		})
+	)
+	cacheConfig := DefaultCacheConfigWithScheme(rawdb.HashScheme)
+	cacheConfig.NoUncles = true
+	chain, _ := NewBlockChain(rawdb.NewMemoryDatabase(), cacheConfig, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import uncle-free chain: %v", err)
+	}
+	chain.SetHead(0)
+	if n, err := chain.InsertChain(uncled); !errors.Is(err, ErrUnclesNotAllowed) {
+		t.Fatalf("block %d: error mismatch: have %v, want %v", n, err, ErrUnclesNotAllowed)
+	}
+}
+
+// Tests that blocks exceeding the configured RIP-7560 transaction count cap are
+// rejected during body validation.
+func TestBodyValidationRip7560TxCap(t *testing.T) {
+	config := *params.TestChainConfig
+	config.RIP7560Block = big.NewInt(0)
+	config.Rip7560 = &params.Rip7560Config{MaxTxsPerBlock: 1}
+
+	var (
+		gspec        = &Genesis{Config: &config}
+		_, blocks, _ = GenerateChainWithGenesis(gspec, ethash.NewFaker(), 1, nil)
+	)
+	chain, _ := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	newBlock := func(n int) *types.Block {
+		txs := make([]*types.Transaction, n)
+		for i := range txs {
+			txs[i] = types.NewTx(&types.Rip7560AccountAbstractionTx{
+				ChainID:   config.ChainID,
+				Nonce:     uint64(i),
+				Sender:    &common.Address{0xaa},
+				GasFeeCap: big.NewInt(1),
+				GasTipCap: big.NewInt(1),
+			})
+		}
+		header := &types.Header{
+			ParentHash: blocks[0].Hash(),
+			Number:     big.NewInt(2),
+			UncleHash:  types.EmptyUncleHash,
+			Difficulty: big.NewInt(1),
+		}
+		return types.NewBlock(header, &types.Body{Transactions: txs}, nil, trie.NewStackTrie(nil))
+	}
+	if err := chain.Validator().ValidateBody(newBlock(1)); err != nil {
+		t.Fatalf("block within cap rejected: %v", err)
+	}
+	if err := chain.Validator().ValidateBody(newBlock(2)); !errors.Is(err, ErrRip7560TxCountExceeded) {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrRip7560TxCountExceeded)
+	}
+}
+
+// Tests that blocks carrying RIP-7560 transactions before its activation are
+// rejected during body validation.
+func TestBodyValidationRip7560Activation(t *testing.T) {
+	config := *params.TestChainConfig
+	config.RIP7560Block = big.NewInt(2)
+
+	var (
+		gspec        = &Genesis{Config: &config}
+		_, blocks, _ = GenerateChainWithGenesis(gspec, ethash.NewFaker(), 1, nil)
+	)
+	chain, _ := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	newBlock := func(number int64) *types.Block {
+		tx := types.NewTx(&types.Rip7560AccountAbstractionTx{
+			ChainID:   config.ChainID,
+			Sender:    &common.Address{0xaa},
+			GasFeeCap: big.NewInt(1),
+			GasTipCap: big.NewInt(1),
+		})
+		header := &types.Header{
+			ParentHash: blocks[0].Hash(),
+			Number:     big.NewInt(number),
+			UncleHash:  types.EmptyUncleHash,
+			Difficulty: big.NewInt(1),
+		}
+		return types.NewBlock(header, &types.Body{Transactions: []*types.Transaction{tx}}, nil, trie.NewStackTrie(nil))
+	}
+	if err := chain.Validator().ValidateBody(newBlock(1)); !errors.Is(err, ErrTxTypeNotSupported) {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+	if err := chain.Validator().ValidateBody(newBlock(2)); err != nil {
+		t.Fatalf("block after activation rejected: %v", err)
+	}
+}
+
+// Tests that RIP-7560 transactions using the legacy type byte of the early
+// devnets are only accepted before the type switch block of the chain.
+func TestBodyValidationRip7560LegacyType(t *testing.T) {
+	config := *params.TestChainConfig
+	config.RIP7560Block = big.NewInt(0)
+	config.RIP7560TypeBlock = big.NewInt(3)
+
+	var (
+		gspec        = &Genesis{Config: &config}
+		_, blocks, _ = GenerateChainWithGenesis(gspec, ethash.NewFaker(), 2, nil)
+	)
+	chain, _ := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	enc, err := types.NewTx(&types.Rip7560AccountAbstractionTx{
+		ChainID:   config.ChainID,
+		Sender:    &common.Address{0xaa},
+		GasFeeCap: big.NewInt(1),
+		GasTipCap: big.NewInt(1),
+	}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc[0] = types.Rip7560LegacyType
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(enc); err != nil {
+		t.Fatalf("failed to decode legacy type: %v", err)
+	}
+	newBlock := func(number int64) *types.Block {
+		header := &types.Header{
+			ParentHash: blocks[number-2].Hash(),
+			Number:     big.NewInt(number),
+			UncleHash:  types.EmptyUncleHash,
+			Difficulty: big.NewInt(1),
+		}
+		return types.NewBlock(header, &types.Body{Transactions: []*types.Transaction{tx}}, nil, trie.NewStackTrie(nil))
+	}
+	if err := chain.Validator().ValidateBody(newBlock(2)); err != nil {
+		t.Fatalf("legacy type before the switch rejected: %v", err)
+	}
+	if err := chain.Validator().ValidateBody(newBlock(3)); !errors.Is(err, ErrTxTypeNotSupported) {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+}
+
+// Tests that on chains requiring ordered bundles, blocks with RIP-7560 bundles
+// not sorted by sender, nonce key and nonce are rejected during body validation.
+func TestBodyValidationRip7560BundleOrder(t *testing.T) {
+	config := *params.TestChainConfig
+	config.RIP7560Block = big.NewInt(0)
+	config.Rip7560 = &params.Rip7560Config{OrderedBundles: true}
+
+	var (
+		gspec        = &Genesis{Config: &config}
+		_, blocks, _ = GenerateChainWithGenesis(gspec, ethash.NewFaker(), 1, nil)
+	)
+	chain, _ := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	aaTx := func(sender byte, key int64, nonce uint64) *types.Transaction {
+		return types.NewTx(&types.Rip7560AccountAbstractionTx{
+			ChainID:   config.ChainID,
+			NonceKey:  big.NewInt(key),
+			Nonce:     nonce,
+			Sender:    &common.Address{sender},
+			GasFeeCap: big.NewInt(1),
+			GasTipCap: big.NewInt(1),
+		})
+	}
+	newBlock := func(txs ...*types.Transaction) *types.Block {
+		header := &types.Header{
+			ParentHash: blocks[0].Hash(),
+			Number:     big.NewInt(2),
+			UncleHash:  types.EmptyUncleHash,
+			Difficulty: big.NewInt(1),
+		}
+		return types.NewBlock(header, &types.Body{Transactions: txs}, nil, trie.NewStackTrie(nil))
+	}
+	plain := types.NewTransaction(0, common.Address{0xbb}, big.NewInt(1), params.TxGas, big.NewInt(1), nil)
+
+	tests := []struct {
+		name string
+		txs  []*types.Transaction
+		err  error
+	}{
+		{"sorted", []*types.Transaction{aaTx(0xaa, 0, 0), aaTx(0xaa, 0, 1), aaTx(0xaa, 1, 0), aaTx(0xbb, 0, 0)}, nil},
+		{"separate bundles", []*types.Transaction{aaTx(0xbb, 0, 0), plain, aaTx(0xaa, 0, 0)}, nil},
+		{"unsorted senders", []*types.Transaction{aaTx(0xbb, 0, 0), aaTx(0xaa, 0, 0)}, ErrRip7560BundleOrder},
+		{"unsorted nonce keys", []*types.Transaction{aaTx(0xaa, 1, 0), aaTx(0xaa, 0, 0)}, ErrRip7560BundleOrder},
+		{"unsorted nonces", []*types.Transaction{aaTx(0xaa, 0, 1), aaTx(0xaa, 0, 0)}, ErrRip7560BundleOrder},
+		{"duplicate", []*types.Transaction{aaTx(0xaa, 0, 0), aaTx(0xaa, 0, 0)}, ErrRip7560BundleOrder},
+	}
+	for _, tt := range tests {
+		if err := chain.Validator().ValidateBody(newBlock(tt.txs...)); !errors.Is(err, tt.err) {
+			t.Errorf("%s: error mismatch: have %v, want %v", tt.name, err, tt.err)
+		}
+	}
+	// Without the requirement, the order is up to the builder
+	config.Rip7560 = nil
+	if err := chain.Validator().ValidateBody(newBlock(aaTx(0xbb, 0, 0), aaTx(0xaa, 0, 0))); err != nil {
+		t.Errorf("unordered bundle rejected: %v", err)
+	}
+}
+
 func TestHeaderVerificationForMergingClique(t *testing.T) { testHeaderVerificationForMerging(t, true) }
 func TestHeaderVerificationForMergingEthash(t *testing.T) { testHeaderVerificationForMerging(t, false) }
 
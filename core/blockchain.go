@@ -100,6 +100,7 @@ const (
 	blockCacheLimit    = 256
 	receiptsCacheLimit = 32
 	txLookupCacheLimit = 1024
+	rip7560StatsLimit  = 256
 
 	// BlockChainVersion ensures that an incompatible database forces a resync from scratch.
 	//
@@ -142,6 +143,14 @@ type CacheConfig struct {
 
 	SnapshotNoBuild bool // Whether the background generation is allowed
 	SnapshotWait    bool // Wait for snapshot construction on startup. TODO(karalabe): This is a dirty hack for testing, nuke it
+
+	NoUncles      bool // Post-merge only: reject blocks carrying uncles and skip all uncle handling
+	StrictTxTypes bool // Reject blocks carrying transaction types not active at the block
+
+	// Rip7560ValidationWorkers is the number of RIP-7560 validation phases run
+	// concurrently ahead of their turn when processing blocks, zero or one
+	// validating the transactions one after the other.
+	Rip7560ValidationWorkers int
 }
 
 // triedbConfig derives the configures for trie database.
@@ -242,6 +251,7 @@ type BlockChain struct {
 	bodyRLPCache  *lru.Cache[common.Hash, rlp.RawValue]
 	receiptsCache *lru.Cache[common.Hash, []*types.Receipt]
 	blockCache    *lru.Cache[common.Hash, *types.Block]
+	rip7560Stats  *lru.Cache[common.Hash, Rip7560BlockStats] // Stats of recently processed blocks
 
 	txLookupLock  sync.RWMutex
 	txLookupCache *lru.Cache[common.Hash, txLookup]
@@ -300,6 +310,7 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *Genesis
 		bodyRLPCache:  lru.NewCache[common.Hash, rlp.RawValue](bodyCacheLimit),
 		receiptsCache: lru.NewCache[common.Hash, []*types.Receipt](receiptsCacheLimit),
 		blockCache:    lru.NewCache[common.Hash, *types.Block](blockCacheLimit),
+		rip7560Stats:  lru.NewCache[common.Hash, Rip7560BlockStats](rip7560StatsLimit),
 		txLookupCache: lru.NewCache[common.Hash, txLookup](txLookupCacheLimit),
 		engine:        engine,
 		vmConfig:      vmConfig,
@@ -466,6 +477,11 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *Genesis
 	if txLookupLimit != nil {
 		bc.txIndexer = newTxIndexer(*txLookupLimit, bc)
 	}
+	// Drop the RIP-7560 side table entries left behind by an unclean shutdown.
+	if bc.chainConfig.RIP7560Block != nil || bc.chainConfig.RIP7560Time != nil {
+		bc.wg.Add(1)
+		go bc.repairRip7560SideTables()
+	}
 	return bc, nil
 }
 
@@ -578,7 +594,7 @@ func (bc *BlockChain) SetHead(head uint64) error {
 		log.Error("Current block not found in database", "block", header.Number, "hash", header.Hash())
 		return fmt.Errorf("current block missing: #%d [%x..]", header.Number, header.Hash().Bytes()[:4])
 	}
-	bc.chainHeadFeed.Send(ChainHeadEvent{Block: block})
+	bc.chainHeadFeed.Send(ChainHeadEvent{Block: block, Rip7560: bc.rip7560BlockStats(block)})
 	return nil
 }
 
@@ -600,7 +616,7 @@ func (bc *BlockChain) SetHeadWithTimestamp(timestamp uint64) error {
 		log.Error("Current block not found in database", "block", header.Number, "hash", header.Hash())
 		return fmt.Errorf("current block missing: #%d [%x..]", header.Number, header.Hash().Bytes()[:4])
 	}
-	bc.chainHeadFeed.Send(ChainHeadEvent{Block: block})
+	bc.chainHeadFeed.Send(ChainHeadEvent{Block: block, Rip7560: bc.rip7560BlockStats(block)})
 	return nil
 }
 
@@ -918,6 +934,7 @@ func (bc *BlockChain) setHeadBeyondRoot(head uint64, time uint64, root common.Ha
 	bc.bodyCache.Purge()
 	bc.bodyRLPCache.Purge()
 	bc.receiptsCache.Purge()
+	bc.rip7560Stats.Purge()
 	bc.blockCache.Purge()
 	bc.txLookupCache.Purge()
 
@@ -1471,10 +1488,19 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	rawdb.WriteTd(blockBatch, block.Hash(), block.NumberU64(), externTd)
 	rawdb.WriteBlock(blockBatch, block)
 	rawdb.WriteReceipts(blockBatch, block.Hash(), block.NumberU64(), receipts)
+	for _, receipt := range receipts {
+		if len(receipt.LogFrames) > 0 {
+			rawdb.WriteRip7560LogFrames(blockBatch, block.Hash(), receipt.TxHash, receipt.LogFrames)
+		}
+	}
 	rawdb.WritePreimages(blockBatch, statedb.Preimages())
 	if err := blockBatch.Write(); err != nil {
 		log.Crit("Failed to write block into disk", "err", err)
 	}
+	// Remember the RIP-7560 stats while the validation gas is still known
+	if stats := NewRip7560BlockStats(block, receipts); stats.Transactions > 0 {
+		bc.rip7560Stats.Add(block.Hash(), stats)
+	}
 	// Commit all cached state changes into underlying memory database.
 	root, err := statedb.Commit(block.NumberU64(), bc.chainConfig.IsEIP158(block.Number()))
 	if err != nil {
@@ -1577,7 +1603,7 @@ func (bc *BlockChain) writeBlockAndSetHead(block *types.Block, receipts []*types
 		// we will fire an accumulated ChainHeadEvent and disable fire
 		// event here.
 		if emitHeadEvent {
-			bc.chainHeadFeed.Send(ChainHeadEvent{Block: block})
+			bc.chainHeadFeed.Send(ChainHeadEvent{Block: block, Rip7560: bc.rip7560BlockStats(block)})
 		}
 	} else {
 		bc.chainSideFeed.Send(ChainSideEvent{Block: block})
@@ -1644,7 +1670,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks, setHead bool) (int, error)
 	// Fire a single chain head event if we've progressed the chain
 	defer func() {
 		if lastCanon != nil && bc.CurrentBlock().Hash() == lastCanon.Hash() {
-			bc.chainHeadFeed.Send(ChainHeadEvent{lastCanon})
+			bc.chainHeadFeed.Send(ChainHeadEvent{Block: lastCanon, Rip7560: bc.rip7560BlockStats(lastCanon)})
 		}
 	}()
 	// Start the parallel header verifier
@@ -2412,7 +2438,7 @@ func (bc *BlockChain) SetCanonical(head *types.Block) (common.Hash, error) {
 	if len(logs) > 0 {
 		bc.logsFeed.Send(logs)
 	}
-	bc.chainHeadFeed.Send(ChainHeadEvent{Block: head})
+	bc.chainHeadFeed.Send(ChainHeadEvent{Block: head, Rip7560: bc.rip7560BlockStats(head)})
 
 	context := []interface{}{
 		"number", head.Number(),
@@ -2561,3 +2587,18 @@ func (bc *BlockChain) SetRip7560TransactionDebugInfo(infos []*types.Rip7560Trans
 	}
 	bc.rip7560TransactionDebugInfos = append(bc.rip7560TransactionDebugInfos, infos...)
 }
+
+// rip7560BlockStats returns the summary of the RIP-7560 transactions in a block,
+// preferring the one collected when the block was processed, since the stored
+// receipts lack the validation gas.
+func (bc *BlockChain) rip7560BlockStats(block *types.Block) Rip7560BlockStats {
+	if stats, ok := bc.rip7560Stats.Get(block.Hash()); ok {
+		return stats
+	}
+	for _, tx := range block.Transactions() {
+		if tx.Type() == types.Rip7560Type {
+			return NewRip7560BlockStats(block, bc.GetReceiptsByHash(block.Hash()))
+		}
+	}
+	return Rip7560BlockStats{}
+}
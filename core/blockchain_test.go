@@ -4221,3 +4221,113 @@ func TestEIP3651(t *testing.T) {
 		t.Fatalf("sender balance incorrect: expected %d, got %d", expected, actual)
 	}
 }
+
+// Tests that the RIP-7560 block summary only accounts AA transactions and
+// attributes the gas of sponsored ones to their paymasters.
+func TestRip7560BlockStats(t *testing.T) {
+	newAATx := func(paymaster *common.Address) *types.Transaction {
+		return types.NewTx(&types.Rip7560AccountAbstractionTx{
+			ChainID:   big.NewInt(1),
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(1),
+			Sender:    &common.Address{0xaa},
+			Paymaster: paymaster,
+		})
+	}
+	var (
+		legacy = types.NewTransaction(0, common.Address{}, nil, params.TxGas, big.NewInt(1), nil)
+		txs    = types.Transactions{legacy, newAATx(nil), newAATx(&common.Address{0xbb})}
+		block  = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)}).WithBody(types.Body{Transactions: txs})
+	)
+	receipts := types.Receipts{
+		{GasUsed: params.TxGas},
+		{GasUsed: 100_000, ValidationGasUsed: 40_000},
+		{GasUsed: 70_000, ValidationGasUsed: 30_000},
+	}
+	want := Rip7560BlockStats{Transactions: 2, ValidationGas: 70_000, SponsoredGas: 70_000}
+	if have := NewRip7560BlockStats(block, receipts); have != want {
+		t.Fatalf("stats mismatch: have %+v, want %+v", have, want)
+	}
+	// Stored receipts lack the validation gas, but everything else is derivable
+	for _, receipt := range receipts {
+		receipt.ValidationGasUsed = 0
+	}
+	want.ValidationGas = 0
+	if have := NewRip7560BlockStats(block, receipts); have != want {
+		t.Fatalf("stats mismatch: have %+v, want %+v", have, want)
+	}
+}
+
+// Tests that RIP-7560 transactions are processed at their position in the block,
+// interleaved with the other transactions.
+func TestRip7560InterleavedTransactions(t *testing.T) {
+	config := *params.TestChainConfig
+	config.RIP7560Block = big.NewInt(0)
+
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		sender = common.Address{0xaa}
+		signer = types.LatestSigner(&config)
+		gspec  = &Genesis{
+			Config: &config,
+			Alloc: types.GenesisAlloc{
+				addr:   {Balance: big.NewInt(params.Ether)},
+				sender: {Balance: big.NewInt(params.Ether), Code: rip7560AccountCode()},
+			},
+		}
+	)
+	_, blocks, receipts := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 1, func(i int, b *BlockGen) {
+		for nonce := uint64(0); nonce < 2; nonce++ {
+			tx, _ := types.SignTx(types.NewTransaction(nonce, common.Address{0xbb}, big.NewInt(1), params.TxGas, b.BaseFee(), nil), signer, key)
+			b.AddTx(tx)
+			if nonce == 0 {
+				b.AddTx(types.NewTx(&types.Rip7560AccountAbstractionTx{
+					ChainID:   config.ChainID,
+					NonceKey:  new(big.Int),
+					GasTipCap: big.NewInt(1),
+					GasFeeCap: b.BaseFee(),
+					Gas:       100_000,
+					Sender:    &sender,
+
+					ValidationGasLimit: 100_000,
+				}))
+			}
+		}
+	})
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	statedb, _ := chain.StateAt(chain.Genesis().Root())
+	processed, _, _, err := chain.Processor().Process(blocks[0], statedb, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to process block: %v", err)
+	}
+	for _, have := range []types.Receipts{receipts[0], processed} {
+		if len(have) != 3 || have[1].Type != types.Rip7560Type {
+			t.Fatalf("unexpected receipts: %v", have)
+		}
+		for i, receipt := range have {
+			if receipt.TransactionIndex != uint(i) {
+				t.Errorf("receipt %d: index mismatch: have %d", i, receipt.TransactionIndex)
+			}
+			for _, log := range receipt.Logs {
+				if log.TxIndex != uint(i) {
+					t.Errorf("receipt %d: log index mismatch: have %d", i, log.TxIndex)
+				}
+			}
+			if i > 0 && receipt.CumulativeGasUsed != have[i-1].CumulativeGasUsed+receipt.GasUsed {
+				t.Errorf("receipt %d: cumulative gas mismatch: have %d", i, receipt.CumulativeGasUsed)
+			}
+		}
+		if len(have[1].Logs) == 0 {
+			t.Fatal("missing RIP-7560 logs")
+		}
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+}
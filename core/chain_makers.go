@@ -102,7 +102,9 @@ func (b *BlockGen) SetParentBeaconRoot(root common.Hash) {
 		blockContext = NewEVMBlockContext(b.header, b.cm, &b.header.Coinbase)
 		vmenv        = vm.NewEVM(blockContext, vm.TxContext{}, b.statedb, b.cm.config, vm.Config{})
 	)
-	ProcessBeaconBlockRoot(root, vmenv, b.statedb)
+	if err := ProcessBeaconBlockRoot(root, vmenv, b.statedb); err != nil {
+		panic(err)
+	}
 }
 
 // addTx adds a transaction to the generated block. If no coinbase has
@@ -116,8 +118,12 @@ func (b *BlockGen) addTx(bc *BlockChain, vmConfig vm.Config, tx *types.Transacti
 	if b.gasPool == nil {
 		b.SetCoinbase(common.Address{})
 	}
+	if tx.Type() == types.Rip7560Type {
+		b.addRip7560Tx(bc, vmConfig, tx)
+		return
+	}
 	b.statedb.SetTxContext(tx.Hash(), len(b.txs))
-	receipt, err := ApplyTransaction(b.cm.config, bc, &b.header.Coinbase, b.gasPool, b.statedb, b.header, tx, &b.header.GasUsed, vmConfig)
+	receipt, _, err := ApplyTransaction(b.cm.config, bc, &b.header.Coinbase, b.gasPool, b.statedb, b.header, tx, &b.header.GasUsed, vmConfig)
 	if err != nil {
 		panic(err)
 	}
@@ -128,6 +134,21 @@ func (b *BlockGen) addTx(bc *BlockChain, vmConfig vm.Config, tx *types.Transacti
 	}
 }
 
+// addRip7560Tx runs both the validation and execution phases of an RIP-7560
+// transaction on top of the generated block, mirroring the block processor.
+func (b *BlockGen) addRip7560Tx(bc *BlockChain, vmConfig vm.Config, tx *types.Transaction) {
+	var chain ChainContext = b.cm
+	if bc != nil {
+		chain = bc
+	}
+	receipt, err := ApplyRip7560Transaction(b.cm.config, chain, &b.header.Coinbase, b.gasPool, b.statedb, b.header, common.Hash{}, tx, len(b.txs), &b.header.GasUsed, vmConfig)
+	if err != nil {
+		panic(err)
+	}
+	b.txs = append(b.txs, tx)
+	b.receipts = append(b.receipts, receipt)
+}
+
 // AddTx adds a transaction to the generated block. If no coinbase has
 // been set, the block's coinbase is set to the zero address.
 //
@@ -29,6 +29,72 @@ var (
 	// ErrNoGenesis is returned when there is no Genesis Block.
 	ErrNoGenesis = errors.New("genesis not found in chain")
 
+	// ErrUnclesNotAllowed is returned when a block carries uncles while the
+	// processor runs in post-merge only mode.
+	ErrUnclesNotAllowed = errors.New("uncles not allowed in post-merge mode")
+
+	// ErrBeaconRootCallFailed is returned if the EIP-4788 system call storing
+	// the parent beacon block root fails.
+	ErrBeaconRootCallFailed = errors.New("beacon root system call failed")
+
+	// ErrRip7560TxCountExceeded is returned when a block contains more RIP-7560
+	// transactions than allowed by the chain configuration.
+	ErrRip7560TxCountExceeded = errors.New("too many rip-7560 transactions in block")
+
+	// ErrRip7560GasExceeded is returned when the RIP-7560 transactions of a block
+	// use more gas than allowed by the chain configuration.
+	ErrRip7560GasExceeded = errors.New("rip-7560 transactions exceed block gas cap")
+
+	// ErrRip7560BundleOrder is returned when the consecutive RIP-7560
+	// transactions of a block are not sorted as required by the chain
+	// configuration.
+	ErrRip7560BundleOrder = errors.New("rip-7560 bundle out of order")
+
+	// ErrPaymasterContextTooLarge is returned if a paymaster returns a larger
+	// context than allowed by the chain configuration.
+	ErrPaymasterContextTooLarge = errors.New("paymaster context too large")
+
+	// ErrSenderNotDeployed is returned if the deployer frame of a RIP-7560
+	// transaction doesn't deploy the account of the sender.
+	ErrSenderNotDeployed = errors.New("sender not deployed by the deployer")
+
+	// ErrAANonceMismatch is returned if the nonce of a RIP-7560 transaction is
+	// rejected, either by the state or by the RIP-7712 NonceManager.
+	ErrAANonceMismatch = errors.New("nonce mismatch")
+
+	// ErrAADeploymentFailed is returned if the deployer frame of a RIP-7560
+	// transaction fails or doesn't deploy the sender.
+	ErrAADeploymentFailed = errors.New("deployment failed")
+
+	// ErrAAValidationReverted is returned if the account or the paymaster
+	// validation frame of a RIP-7560 transaction reverts or fails.
+	ErrAAValidationReverted = errors.New("validation frame reverted")
+
+	// ErrAAValidationRejected is returned if the account or the paymaster
+	// validation frame of a RIP-7560 transaction completes without accepting
+	// the transaction.
+	ErrAAValidationRejected = errors.New("transaction not accepted")
+
+	// ErrAAValidityRange is returned if a RIP-7560 transaction is accepted
+	// for a time range not including the block time.
+	ErrAAValidityRange = errors.New("outside validity time range")
+
+	// ErrAAPaymasterPostOpReverted is returned by simulations of RIP-7560
+	// transactions whose paymaster postOp frame reverts or fails.
+	ErrAAPaymasterPostOpReverted = errors.New("paymaster postOp reverted")
+
+	// ErrValidationRulesViolation is returned if the simulated validation of a
+	// RIP-7560 transaction breaks the ERC-7562 validation rules.
+	ErrValidationRulesViolation = types.ErrValidationRulesViolation
+
+	// ErrValidationOutOfGas is returned if a validation frame of a RIP-7560
+	// transaction runs out of the gas limit the transaction assigned to it.
+	ErrValidationOutOfGas = errors.New("validation frame out of gas")
+
+	// ErrValidationTimeout is returned if the simulated validation of a RIP-7560
+	// transaction takes longer than permitted by the caller.
+	ErrValidationTimeout = errors.New("validation timeout")
+
 	errSideChainReceipts = errors.New("side blocks can't be accepted as ancient chain data")
 )
 
@@ -112,4 +178,20 @@ var (
 
 	// ErrBlobTxCreate is returned if a blob transaction has no explicit to field.
 	ErrBlobTxCreate = errors.New("blob transaction of type create")
+
+	// ErrEmptyAuthList is returned if a set code transaction has an empty auth list.
+	ErrEmptyAuthList = errors.New("EIP-7702 transaction with empty auth list")
+
+	// ErrSetCodeTxCreate is returned if a set code transaction has no explicit to field.
+	ErrSetCodeTxCreate = errors.New("EIP-7702 transaction cannot be used to create contract")
+)
+
+// EIP-7702 state transition errors.
+// Note these are just informational, and do not cause tx execution abort.
+var (
+	ErrAuthorizationWrongChainID       = errors.New("EIP-7702 authorization chain ID mismatch")
+	ErrAuthorizationNonceOverflow      = errors.New("EIP-7702 authorization nonce > 64 bit")
+	ErrAuthorizationInvalidSignature   = errors.New("EIP-7702 authorization has invalid signature")
+	ErrAuthorizationDestinationHasCode = errors.New("EIP-7702 authorization destination is a contract")
+	ErrAuthorizationNonceMismatch      = errors.New("EIP-7702 authorization nonce does not match current account nonce")
 )
@@ -24,6 +24,14 @@ import (
 // NewTxsEvent is posted when a batch of transactions enter the transaction pool.
 type NewTxsEvent struct{ Txs []*types.Transaction }
 
+// Rip7560TxDroppedEvent is posted when an individually submitted RIP-7560
+// transaction is removed from the pool. Transactions removed because their
+// nonce was used on chain may have been included.
+type Rip7560TxDroppedEvent struct {
+	Tx     *types.Transaction
+	Reason string
+}
+
 // NewMinedBlockEvent is posted when a block has been imported.
 type NewMinedBlockEvent struct{ Block *types.Block }
 
@@ -40,4 +48,35 @@ type ChainSideEvent struct {
 	Block *types.Block
 }
 
-type ChainHeadEvent struct{ Block *types.Block }
+type ChainHeadEvent struct {
+	Block   *types.Block
+	Rip7560 Rip7560BlockStats // Summary of the RIP-7560 transactions in the block
+}
+
+// Rip7560BlockStats summarizes the RIP-7560 transactions included in a block.
+type Rip7560BlockStats struct {
+	Transactions  int    // Number of RIP-7560 transactions
+	ValidationGas uint64 // Gas used by their validation phases, if known
+	SponsoredGas  uint64 // Gas used by the transactions paid for by a paymaster
+}
+
+// NewRip7560BlockStats summarizes the RIP-7560 transactions of a block using its
+// receipts. The validation gas is only accounted if the receipts come straight
+// from processing the block, as it is not persisted.
+func NewRip7560BlockStats(block *types.Block, receipts types.Receipts) Rip7560BlockStats {
+	var stats Rip7560BlockStats
+	for i, tx := range block.Transactions() {
+		if tx.Type() != types.Rip7560Type {
+			continue
+		}
+		stats.Transactions++
+		if i >= len(receipts) {
+			continue
+		}
+		stats.ValidationGas += receipts[i].ValidationGasUsed
+		if payer, _ := tx.PayerCost(); payer != nil {
+			stats.SponsoredGas += receipts[i].GasUsed
+		}
+	}
+	return stats
+}
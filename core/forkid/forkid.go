@@ -97,6 +97,23 @@ func NewID(config *params.ChainConfig, genesis *types.Block, head, time uint64)
 	return ID{Hash: checksumToBytes(hash), Next: 0}
 }
 
+// Passed reports whether a remotely advertised fork ID was calculated at or past
+// the given head and time, i.e. whether the remote node went through all the
+// forks scheduled until then.
+func Passed(config *params.ChainConfig, genesis *types.Block, id ID, head, time uint64) bool {
+	var (
+		hash            = crc32.ChecksumIEEE(genesis.Hash().Bytes())
+		sums            = [][4]byte{checksumToBytes(hash)}
+		want            = NewID(config, genesis, head, time).Hash
+		byBlock, byTime = gatherForks(config, genesis.Time())
+	)
+	for _, fork := range append(byBlock, byTime...) {
+		hash = checksumUpdate(hash, fork)
+		sums = append(sums, checksumToBytes(hash))
+	}
+	return slices.Contains(sums[slices.Index(sums, want):], id.Hash)
+}
+
 // NewIDWithChain calculates the Ethereum fork ID from an existing chain instance.
 func NewIDWithChain(chain Blockchain) ID {
 	head := chain.CurrentHeader()
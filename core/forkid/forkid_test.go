@@ -439,3 +439,54 @@ func TestTimeBasedForkInGenesis(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the RIP-7560 fork is announced in the fork ID, so that peers with
+// a different activation are rejected.
+func TestRip7560ForkID(t *testing.T) {
+	var (
+		genesis    = types.NewBlockWithHeader(&types.Header{})
+		forkidHash = checksumToBytes(crc32.ChecksumIEEE(genesis.Hash().Bytes()))
+		forkTime   = uint64(1000)
+	)
+	tests := []struct {
+		config *params.ChainConfig
+		want   ID
+	}{
+		{&params.ChainConfig{RIP7560Block: big.NewInt(5)}, ID{Hash: forkidHash, Next: 5}},
+		{&params.ChainConfig{RIP7560Time: &forkTime}, ID{Hash: forkidHash, Next: forkTime}},
+	}
+	for i, tt := range tests {
+		if have := NewID(tt.config, genesis, 0, 0); have != tt.want {
+			t.Errorf("test %d: fork ID mismatch: have %x, want %x", i, have, tt.want)
+		}
+	}
+}
+
+// Tests that only the fork IDs calculated at or past a fork are reported to have
+// passed it.
+func TestPassed(t *testing.T) {
+	var (
+		genesis  = types.NewBlockWithHeader(&types.Header{})
+		forkTime = uint64(1000)
+		config   = &params.ChainConfig{HomesteadBlock: big.NewInt(2), RIP7560Block: big.NewInt(5), ShanghaiTime: &forkTime}
+	)
+	tests := []struct {
+		head, time uint64
+		passed     bool
+	}{
+		{0, 0, false},
+		{4, 0, false},
+		{5, 0, true},
+		{10, 0, true},
+		{10, forkTime, true},
+	}
+	for i, tt := range tests {
+		id := NewID(config, genesis, tt.head, tt.time)
+		if have := Passed(config, genesis, id, 5, 0); have != tt.passed {
+			t.Errorf("test %d: passed mismatch: have %v, want %v", i, have, tt.passed)
+		}
+	}
+	if Passed(config, genesis, ID{Hash: [4]byte{0xde, 0xad, 0xbe, 0xef}}, 5, 0) {
+		t.Error("unknown fork ID passed")
+	}
+}
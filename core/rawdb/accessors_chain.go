@@ -676,6 +676,7 @@ type storedReceiptRLP struct {
 	PostStateOrStatus []byte
 	CumulativeGasUsed uint64
 	Logs              []*types.Log
+	Rip7560           rlp.RawValue `rlp:"optional"` // Fields of RIP-7560 receipts, skipped here
 }
 
 // ReceiptLogs is a barebone version of ReceiptForStorage which only keeps
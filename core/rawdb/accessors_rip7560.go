@@ -0,0 +1,166 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// ReadRip7560LogFrames retrieves the names of the frames which emitted the logs
+// of a RIP-7560 transaction included in the given block, in log order.
+func ReadRip7560LogFrames(db ethdb.KeyValueReader, blockHash, txHash common.Hash) []string {
+	data, _ := db.Get(rip7560LogFramesKey(blockHash, txHash))
+	if len(data) == 0 {
+		return nil
+	}
+	var frames []string
+	if err := rlp.DecodeBytes(data, &frames); err != nil {
+		log.Error("Invalid RIP-7560 log frames RLP", "block", blockHash, "tx", txHash, "err", err)
+		return nil
+	}
+	return frames
+}
+
+// WriteRip7560LogFrames stores the names of the frames which emitted the logs
+// of a RIP-7560 transaction included in the given block.
+func WriteRip7560LogFrames(db ethdb.KeyValueWriter, blockHash, txHash common.Hash, frames []string) {
+	data, err := rlp.EncodeToBytes(frames)
+	if err != nil {
+		log.Crit("Failed to RLP encode RIP-7560 log frames", "err", err)
+	}
+	if err := db.Put(rip7560LogFramesKey(blockHash, txHash), data); err != nil {
+		log.Crit("Failed to store RIP-7560 log frames", "err", err)
+	}
+}
+
+// DeleteRip7560LogFrames removes the names of the frames which emitted the logs
+// of a RIP-7560 transaction included in the given block.
+func DeleteRip7560LogFrames(db ethdb.KeyValueWriter, blockHash, txHash common.Hash) {
+	if err := db.Delete(rip7560LogFramesKey(blockHash, txHash)); err != nil {
+		log.Crit("Failed to delete RIP-7560 log frames", "err", err)
+	}
+}
+
+// DeleteOrphanedRip7560LogFrames removes the RIP-7560 log frames of the blocks
+// which are not canonical. The blocks dropped by a rewind, either explicit or
+// after an unclean shutdown, don't take their log frames with them, nor do the
+// side chain blocks left behind by a reorg.
+//
+// There is a passed channel, the whole procedure will be interrupted if any
+// signal received. The number of checked and deleted entries is returned.
+func DeleteOrphanedRip7560LogFrames(db ethdb.Database, interrupt chan struct{}) (checked int, deleted int) {
+	var (
+		it     = db.NewIterator(rip7560LogFramesPrefix, nil)
+		batch  = db.NewBatch()
+		start  = time.Now()
+		logged = time.Now()
+		known  = make(map[common.Hash]bool)
+	)
+	defer it.Release()
+
+	for it.Next() {
+		select {
+		case <-interrupt:
+			log.Debug("RIP-7560 log frames check interrupted", "checked", checked, "deleted", deleted)
+			return checked, deleted
+		default:
+		}
+		key := it.Key()
+		if len(key) != len(rip7560LogFramesPrefix)+2*common.HashLength || !bytes.HasPrefix(key, rip7560LogFramesPrefix) {
+			continue
+		}
+		checked++
+
+		blockHash := common.BytesToHash(key[len(rip7560LogFramesPrefix) : len(rip7560LogFramesPrefix)+common.HashLength])
+		canonical, ok := known[blockHash]
+		if !ok {
+			number := ReadHeaderNumber(db, blockHash)
+			canonical = number != nil && ReadCanonicalHash(db, *number) == blockHash
+			known[blockHash] = canonical
+		}
+		if !canonical {
+			if err := batch.Delete(key); err != nil {
+				log.Crit("Failed to delete RIP-7560 log frames", "err", err)
+			}
+			deleted++
+		}
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to delete RIP-7560 log frames", "err", err)
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Checking RIP-7560 log frames", "checked", checked, "deleted", deleted, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to delete RIP-7560 log frames", "err", err)
+	}
+	return checked, deleted
+}
+
+// ReadRip7560ReceiptMigration retrieves the number of the next block whose
+// receipts are to be upgraded to the RIP-7560 receipt schema.
+func ReadRip7560ReceiptMigration(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(rip7560ReceiptMigrationKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteRip7560ReceiptMigration stores the number of the next block whose
+// receipts are to be upgraded to the RIP-7560 receipt schema.
+func WriteRip7560ReceiptMigration(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(rip7560ReceiptMigrationKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store the RIP-7560 receipt migration progress", "err", err)
+	}
+}
+
+// HasLegacyRip7560Receipts reports whether any receipt of a RIP-7560 transaction
+// of the given block was stored without the RIP-7560 receipt fields.
+func HasLegacyRip7560Receipts(db ethdb.Reader, hash common.Hash, number uint64, txs types.Transactions) bool {
+	data := ReadReceiptsRLP(db, hash, number)
+	if len(data) == 0 {
+		return false
+	}
+	var receipts []storedReceiptRLP
+	if err := rlp.DecodeBytes(data, &receipts); err != nil {
+		log.Error("Invalid receipt array RLP", "hash", hash, "err", err)
+		return false
+	}
+	if len(receipts) != len(txs) {
+		return false
+	}
+	for i, tx := range txs {
+		if tx.Type() == types.Rip7560Type && len(receipts[i].Rip7560) == 0 {
+			return true
+		}
+	}
+	return false
+}
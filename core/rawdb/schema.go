@@ -96,6 +96,10 @@ var (
 	// snapSyncStatusFlagKey flags that status of snap sync.
 	snapSyncStatusFlagKey = []byte("SnapSyncStatus")
 
+	// rip7560ReceiptMigrationKey tracks the next block to upgrade to the RIP-7560
+	// receipt schema.
+	rip7560ReceiptMigrationKey = []byte("Rip7560ReceiptMigration")
+
 	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`, used for indexes).
 	headerPrefix       = []byte("h") // headerPrefix + num (uint64 big endian) + hash -> header
 	headerTDSuffix     = []byte("t") // headerPrefix + num (uint64 big endian) + hash + headerTDSuffix -> td
@@ -134,6 +138,8 @@ var (
 
 	CliqueSnapshotPrefix = []byte("clique-")
 
+	rip7560LogFramesPrefix = []byte("aa-log-frames-") // rip7560LogFramesPrefix + block hash + tx hash -> RLP(frame names)
+
 	BestUpdateKey         = []byte("update-")    // bigEndian64(syncPeriod) -> RLP(types.LightClientUpdate)  (nextCommittee only referenced by root hash)
 	FixedCommitteeRootKey = []byte("fixedRoot-") // bigEndian64(syncPeriod) -> committee root hash
 	SyncCommitteeKey      = []byte("committee-") // bigEndian64(syncPeriod) -> serialized committee
@@ -197,6 +203,11 @@ func txLookupKey(hash common.Hash) []byte {
 	return append(txLookupPrefix, hash.Bytes()...)
 }
 
+// rip7560LogFramesKey = rip7560LogFramesPrefix + block hash + tx hash
+func rip7560LogFramesKey(blockHash, txHash common.Hash) []byte {
+	return append(append(rip7560LogFramesPrefix, blockHash.Bytes()...), txHash.Bytes()...)
+}
+
 // accountSnapshotKey = SnapshotAccountPrefix + hash
 func accountSnapshotKey(hash common.Hash) []byte {
 	return append(SnapshotAccountPrefix, hash.Bytes()...)
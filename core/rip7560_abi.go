@@ -1,17 +1,41 @@
 package core
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/aa"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
 	"math/big"
 	"strings"
 )
 
 var Rip7560Abi, _ = abi.JSON(strings.NewReader(Rip7560AbiJson))
 
+// Magic values returned by the validation frames accepting a transaction without
+// calling the EntryPoint, the selectors of the matching EntryPoint callbacks.
+var (
+	AcceptAccountMethodSig    = methodSig("acceptAccount")
+	AcceptPaymasterMethodSig  = methodSig("acceptPaymaster")
+	SigFailAccountMethodSig   = methodSig("sigFailAccount")
+	SigFailPaymasterMethodSig = methodSig("sigFailPaymaster")
+)
+
+// paymasterReturnArgs are the values returned by a paymaster validation frame:
+// the packed validation data followed by the paymaster context.
+var paymasterReturnArgs = abi.Arguments{
+	{Type: abi.Type{T: abi.FixedBytesTy, Size: 32}},
+	{Type: abi.Type{T: abi.BytesTy}},
+}
+
+func methodSig(name string) uint64 {
+	return uint64(binary.BigEndian.Uint32(Rip7560Abi.Methods[name].ID))
+}
+
 type AcceptAccountData struct {
 	ValidAfter *big.Int
 	ValidUntil *big.Int
@@ -23,32 +47,40 @@ type AcceptPaymasterData struct {
 	Context    []byte
 }
 
-func abiEncodeValidateTransaction(tx *types.Rip7560AccountAbstractionTx, signingHash common.Hash) ([]byte, error) {
+// rip7560FrameABI returns the revision of the account and paymaster interfaces
+// selected by the chain config.
+func rip7560FrameABI(config *params.ChainConfig) (*aa.FrameABI, error) {
+	return aa.Lookup(config.Rip7560AbiVersion())
+}
+
+// checkRip7560Config checks that the RIP-7560 parameters of the chain config
+// are valid and select a supported version of the frame interfaces.
+func checkRip7560Config(config *params.ChainConfig) error {
+	if err := config.CheckRip7560Config(); err != nil {
+		return err
+	}
+	_, err := rip7560FrameABI(config)
+	return err
+}
 
+func abiEncodeValidateTransaction(frameABI *aa.FrameABI, tx *types.Rip7560AccountAbstractionTx, signingHash common.Hash) ([]byte, error) {
 	txAbiEncoding, err := tx.AbiEncode()
 	if err != nil {
 		return nil, err
 	}
-	validateTransactionData, err := Rip7560Abi.Pack("validateTransaction", big.NewInt(Rip7560AbiVersion), signingHash, txAbiEncoding)
-	return validateTransactionData, err
+	return frameABI.AppendValidateTransaction(nil, signingHash, txAbiEncoding), nil
 }
 
-func abiEncodeValidatePaymasterTransaction(tx *types.Rip7560AccountAbstractionTx, signingHash common.Hash) ([]byte, error) {
+func abiEncodeValidatePaymasterTransaction(frameABI *aa.FrameABI, tx *types.Rip7560AccountAbstractionTx, signingHash common.Hash) ([]byte, error) {
 	txAbiEncoding, err := tx.AbiEncode()
 	if err != nil {
 		return nil, err
 	}
-	data, err := Rip7560Abi.Pack("validatePaymasterTransaction", big.NewInt(Rip7560AbiVersion), signingHash, txAbiEncoding)
-	return data, err
+	return frameABI.AppendValidatePaymasterTransaction(nil, signingHash, txAbiEncoding), nil
 }
 
-func abiEncodePostPaymasterTransaction(success bool, actualGasCost uint64, context []byte) []byte {
-	// TODO: pass actual gas cost parameter here!
-	postOpData, err := Rip7560Abi.Pack("postPaymasterTransaction", success, big.NewInt(int64(actualGasCost)), context)
-	if err != nil {
-		panic("unable to encode postPaymasterTransaction")
-	}
-	return postOpData
+func abiEncodePostPaymasterTransaction(frameABI *aa.FrameABI, success bool, actualGasCost *uint256.Int, context []byte) []byte {
+	return frameABI.AppendPostPaymasterTransaction(nil, success, actualGasCost, context)
 }
 
 func decodeMethodParamsToInterface(output interface{}, methodName string, input []byte) error {
@@ -91,12 +123,94 @@ func abiDecodeAcceptPaymaster(input []byte, allowSigFail bool) (*AcceptPaymaster
 	if err != nil {
 		return nil, err
 	}
-	if len(acceptPaymasterData.Context) > PaymasterMaxContextSize {
-		return nil, errors.New("paymaster return data: context too large")
-	}
 	return acceptPaymasterData, err
 }
 
+// PackValidationData packs the magic value and the validity range returned by a
+// validation frame into a word of validAfter (6 bytes), validUntil (6 bytes) and
+// magic (20 bytes).
+func PackValidationData(magic uint64, validUntil uint64, validAfter uint64) []byte {
+	packed := make([]byte, 32)
+	putUint48(packed[0:6], validAfter)
+	putUint48(packed[6:12], validUntil)
+	binary.BigEndian.PutUint64(packed[24:], magic)
+	return packed
+}
+
+// UnpackValidationData splits a word packed by PackValidationData. A magic
+// value not fitting into 64 bits is returned as zero, which is never valid.
+func UnpackValidationData(packed []byte) (magic uint64, validUntil uint64, validAfter uint64) {
+	var word [32]byte
+	copy(word[32-min(len(packed), 32):], packed)
+
+	if common.BytesToHash(word[12:24]) == (common.Hash{}) {
+		magic = binary.BigEndian.Uint64(word[24:])
+	}
+	return magic, getUint48(word[6:12]), getUint48(word[0:6])
+}
+
+func putUint48(b []byte, v uint64) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	copy(b, buf[2:])
+}
+
+func getUint48(b []byte) uint64 {
+	var buf [8]byte
+	copy(buf[2:], b)
+	return binary.BigEndian.Uint64(buf[:])
+}
+
+// abiDecodeAccountReturnData decodes the validation data returned by an account
+// accepting the transaction without calling the EntryPoint.
+func abiDecodeAccountReturnData(ret []byte) (*AcceptAccountData, error) {
+	if len(ret) < 32 {
+		return nil, errors.New("account return data: too short")
+	}
+	magic, validUntil, validAfter := UnpackValidationData(ret[:32])
+	switch magic {
+	case AcceptAccountMethodSig:
+	case SigFailAccountMethodSig:
+		return nil, errors.New("account signature error")
+	default:
+		return nil, errors.New("account did not return correct MAGIC_VALUE")
+	}
+	return &AcceptAccountData{
+		ValidAfter: new(big.Int).SetUint64(validAfter),
+		ValidUntil: new(big.Int).SetUint64(validUntil),
+	}, nil
+}
+
+// abiDecodePaymasterReturnData decodes the validation data and the context
+// returned by a paymaster accepting the transaction without calling the
+// EntryPoint. The context may be omitted.
+func abiDecodePaymasterReturnData(ret []byte) (*AcceptPaymasterData, error) {
+	if len(ret) < 32 {
+		return nil, errors.New("paymaster return data: too short")
+	}
+	var context []byte
+	if len(ret) > 32 {
+		values, err := paymasterReturnArgs.Unpack(ret)
+		if err != nil {
+			return nil, fmt.Errorf("paymaster return data: %w", err)
+		}
+		context = values[1].([]byte)
+	}
+	magic, validUntil, validAfter := UnpackValidationData(ret[:32])
+	switch magic {
+	case AcceptPaymasterMethodSig:
+	case SigFailPaymasterMethodSig:
+		return nil, errors.New("paymaster signature error")
+	default:
+		return nil, errors.New("paymaster did not return correct MAGIC_VALUE")
+	}
+	return &AcceptPaymasterData{
+		ValidAfter: new(big.Int).SetUint64(validAfter),
+		ValidUntil: new(big.Int).SetUint64(validUntil),
+		Context:    context,
+	}, nil
+}
+
 func abiEncodeRIP7560TransactionEvent(
 	aatx *types.Rip7560AccountAbstractionTx,
 	executionStatus uint64,
@@ -1,45 +1,23 @@
 package core
 
-import "github.com/ethereum/go-ethereum/common"
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
 
-const PaymasterMaxContextSize = 65536
-const Rip7560AbiVersion = 0
+// Rip7560ValidationRulesVersion identifies the set of validation and execution
+// rules enforced for RIP-7560 transactions. It must be bumped whenever the
+// enforced rules change in a way observable by bundlers or wallets.
+const Rip7560ValidationRulesVersion = 2
 
-var AA_ENTRY_POINT = common.HexToAddress("0x0000000000000000000000000000000000007560")
+var AA_ENTRY_POINT = params.Rip7560EntryPointAddress
 var AA_SENDER_CREATOR = common.HexToAddress("0x00000000000000000000000000000000ffff7560")
 
-// AA_GAS_PENALTY_PCT is always applied to unused execution and postOp gas limits
-const AA_GAS_PENALTY_PCT = 10
-
+// Rip7560AbiJson is the ABI of the EntryPoint callbacks and the events of
+// RIP-7560 transactions. The interfaces of the accounts and paymasters are
+// versioned by the core/aa package.
 const Rip7560AbiJson = `
 [
-	{
-		"type":"function",
-		"name":"validateTransaction",
-		"inputs": [
-			{"name": "version","type": "uint256"},
-			{"name": "txHash","type": "bytes32"},
-			{"name": "transaction","type": "bytes"}
-		]
-	},
-	{
-		"type":"function",
-		"name":"validatePaymasterTransaction",
-		"inputs": [
-			{"name": "version","type": "uint256"},
-			{"name": "txHash","type": "bytes32"},
-			{"name": "transaction","type": "bytes"}
-		]
-	},
-	{
-		"type":"function",
-		"name":"postPaymasterTransaction",
-		"inputs": [
-			{"name": "success","type": "bool"},
-			{"name": "actualGasCost","type": "uint256"},
-			{"name": "context","type": "bytes"}
-		]
-	},
 	{
 		"type":"function",
 		"name":"acceptAccount",
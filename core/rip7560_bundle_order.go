@@ -0,0 +1,88 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// A RIP-7560 bundle is a run of consecutive RIP-7560 transactions in a block.
+// On chains requiring ordered bundles, the transactions of every bundle must be
+// strictly sorted by sender, nonce key and nonce, so that the validity of a
+// block doesn't depend on the order a builder happened to pick. The order keeps
+// the transactions of a sender in nonce order, as their validation requires.
+
+// CompareRip7560Order compares two RIP-7560 transactions by their bundle order,
+// returning a negative number when a comes before b, a positive one when b
+// comes before a and zero when they have the same position.
+func CompareRip7560Order(a, b *types.Transaction) int {
+	atx, btx := a.Rip7560TransactionData(), b.Rip7560TransactionData()
+	if c := bytes.Compare(orderSender(atx).Bytes(), orderSender(btx).Bytes()); c != 0 {
+		return c
+	}
+	if c := orderNonceKey(atx).Cmp(orderNonceKey(btx)); c != 0 {
+		return c
+	}
+	return cmp.Compare(atx.Nonce, btx.Nonce)
+}
+
+// SortRip7560Bundle sorts the transactions of a bundle in their bundle order.
+func SortRip7560Bundle(txs types.Transactions) {
+	slices.SortStableFunc(txs, CompareRip7560Order)
+}
+
+// VerifyRip7560BundleOrder checks that the transactions of every RIP-7560
+// bundle in the list are strictly sorted in their bundle order.
+func VerifyRip7560BundleOrder(txs types.Transactions) error {
+	var prev *types.Transaction
+	for i, tx := range txs {
+		if tx.Type() != types.Rip7560Type {
+			prev = nil
+			continue
+		}
+		if prev != nil && CompareRip7560Order(prev, tx) >= 0 {
+			return fmt.Errorf("%w: transaction %d [%v] not after %v", ErrRip7560BundleOrder, i, tx.Hash().Hex(), prev.Hash().Hex())
+		}
+		prev = tx
+	}
+	return nil
+}
+
+// orderSender returns the sender of a transaction for ordering, tolerating the
+// malformed transactions of a block not validated yet.
+func orderSender(aatx *types.Rip7560AccountAbstractionTx) common.Address {
+	if aatx.Sender == nil {
+		return common.Address{}
+	}
+	return *aatx.Sender
+}
+
+// orderNonceKey returns the nonce key of a transaction for ordering, the
+// missing key being the zero one.
+func orderNonceKey(aatx *types.Rip7560AccountAbstractionTx) *big.Int {
+	if aatx.NonceKey == nil {
+		return new(big.Int)
+	}
+	return aatx.NonceKey
+}
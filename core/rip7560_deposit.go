@@ -0,0 +1,76 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// AA_DEPOSIT_MANAGER is the address of the RIP-7560 DepositManager system
+// contract holding the gas deposits of paymasters.
+var AA_DEPOSIT_MANAGER = params.Rip7560DepositManagerAddress
+
+// rip7560DepositSlot returns the DepositManager storage slot holding the
+// deposit of the given address.
+func rip7560DepositSlot(addr common.Address) common.Hash {
+	return common.BytesToHash(addr.Bytes())
+}
+
+// GetRip7560Deposit returns the gas deposit of the given paymaster held by the
+// DepositManager.
+func GetRip7560Deposit(state vm.StateDB, paymaster common.Address) *uint256.Int {
+	slot := state.GetState(AA_DEPOSIT_MANAGER, rip7560DepositSlot(paymaster))
+	return new(uint256.Int).SetBytes(slot[:])
+}
+
+// chargesRip7560Deposit reports whether the gas of the transaction is charged
+// to the deposit of its paymaster rather than to the balance of its gas payer.
+func chargesRip7560Deposit(config *params.ChainConfig, aatx *types.Rip7560AccountAbstractionTx) bool {
+	return aatx.IsSponsored() && config.Rip7560PaymasterDeposits()
+}
+
+// Rip7560GasFunds returns the funds the gas of the transaction is charged to:
+// the deposit of the paymaster if the chain funds paymasters by deposits, and
+// the balance of the gas payer otherwise.
+func Rip7560GasFunds(config *params.ChainConfig, state vm.StateDB, aatx *types.Rip7560AccountAbstractionTx) *uint256.Int {
+	if chargesRip7560Deposit(config, aatx) {
+		return GetRip7560Deposit(state, *aatx.Paymaster)
+	}
+	return state.GetBalance(*aatx.GasPayer())
+}
+
+// subRip7560Deposit charges the given amount to the deposit of the paymaster,
+// taking it out of the balance of the DepositManager backing the deposits.
+// The deposit is assumed to cover the amount.
+func subRip7560Deposit(state vm.StateDB, paymaster common.Address, amount *uint256.Int) {
+	deposit := GetRip7560Deposit(state, paymaster)
+	state.SetState(AA_DEPOSIT_MANAGER, rip7560DepositSlot(paymaster), deposit.Sub(deposit, amount).Bytes32())
+	state.SubBalance(AA_DEPOSIT_MANAGER, amount, tracing.BalanceDecreaseGasBuy)
+}
+
+// addRip7560Deposit credits the given amount to the deposit of the paymaster,
+// adding it to the balance of the DepositManager backing the deposits.
+func addRip7560Deposit(state vm.StateDB, paymaster common.Address, amount *uint256.Int) {
+	deposit := GetRip7560Deposit(state, paymaster)
+	state.SetState(AA_DEPOSIT_MANAGER, rip7560DepositSlot(paymaster), deposit.Add(deposit, amount).Bytes32())
+	state.AddBalance(AA_DEPOSIT_MANAGER, amount, tracing.BalanceIncreaseGasReturn)
+}
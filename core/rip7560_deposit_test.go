@@ -0,0 +1,205 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// Tests that the DepositManager credits, reports and withdraws deposits.
+func TestRip7560DepositManager(t *testing.T) {
+	var (
+		paymaster = common.Address{0xbb}
+		funder    = common.Address{0xf0}
+		recipient = common.Address{0xee}
+		header    = &types.Header{Number: new(big.Int), Difficulty: new(big.Int), GasLimit: 30_000_000, BaseFee: new(big.Int)}
+	)
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetCode(AA_DEPOSIT_MANAGER, params.Rip7560DepositManagerCode)
+	statedb.SetBalance(paymaster, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+	statedb.SetBalance(funder, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+
+	evm := vm.NewEVM(NewEVMBlockContext(header, nil, &common.Address{}), vm.TxContext{}, statedb, params.AllDevChainProtocolChanges, vm.Config{})
+	call := func(from common.Address, input []byte, value uint64) ([]byte, error) {
+		ret, _, err := evm.Call(vm.AccountRef(from), AA_DEPOSIT_MANAGER, input, 100_000, uint256.NewInt(value))
+		return ret, err
+	}
+	// Deposits are credited to the caller, or to the address in calldata
+	if _, err := call(paymaster, nil, 100); err != nil {
+		t.Fatalf("failed to deposit: %v", err)
+	}
+	if _, err := call(funder, paymaster.Bytes(), 50); err != nil {
+		t.Fatalf("failed to deposit for paymaster: %v", err)
+	}
+	if _, err := call(funder, []byte{0x01}, 50); err == nil {
+		t.Fatal("deposit with malformed beneficiary accepted")
+	}
+	if deposit := GetRip7560Deposit(statedb, paymaster); deposit.Uint64() != 150 {
+		t.Fatalf("deposit mismatch: have %v, want 150", deposit)
+	}
+	ret, err := call(funder, paymaster.Bytes(), 0)
+	if err != nil {
+		t.Fatalf("failed to query deposit: %v", err)
+	}
+	if deposit := new(big.Int).SetBytes(ret); deposit.Uint64() != 150 {
+		t.Fatalf("queried deposit mismatch: have %v, want 150", deposit)
+	}
+	// Withdrawals are taken from the deposit of the caller
+	withdraw := func(from common.Address, amount uint64) error {
+		_, err := call(from, append(append([]byte{}, recipient.Bytes()...), common.LeftPadBytes(new(big.Int).SetUint64(amount).Bytes(), 32)...), 0)
+		return err
+	}
+	if err := withdraw(funder, 1); err == nil {
+		t.Fatal("withdrawal without deposit accepted")
+	}
+	if err := withdraw(paymaster, 151); err == nil {
+		t.Fatal("withdrawal above deposit accepted")
+	}
+	if err := withdraw(paymaster, 120); err != nil {
+		t.Fatalf("failed to withdraw: %v", err)
+	}
+	if deposit := GetRip7560Deposit(statedb, paymaster); deposit.Uint64() != 30 {
+		t.Errorf("deposit after withdrawal mismatch: have %v, want 30", deposit)
+	}
+	if balance := statedb.GetBalance(recipient); balance.Uint64() != 120 {
+		t.Errorf("recipient balance mismatch: have %v, want 120", balance)
+	}
+	if balance := statedb.GetBalance(AA_DEPOSIT_MANAGER); balance.Uint64() != 30 {
+		t.Errorf("DepositManager balance mismatch: have %v, want 30", balance)
+	}
+}
+
+// Tests that paymasters are charged from their deposit instead of their balance
+// if the chain funds them by deposits.
+func TestRip7560PaymasterDeposit(t *testing.T) {
+	var (
+		sender    = common.Address{0xaa}
+		paymaster = common.Address{0xbb}
+		coinbase  = common.Address{0xcc}
+		header    = &types.Header{Number: new(big.Int), Difficulty: new(big.Int), GasLimit: 30_000_000, BaseFee: big.NewInt(10)}
+		config    = *params.AllDevChainProtocolChanges
+	)
+	config.Rip7560 = &params.Rip7560Config{PaymasterDeposits: true}
+
+	tx := types.NewTx(&types.Rip7560AccountAbstractionTx{
+		ChainID:                     config.ChainID,
+		NonceKey:                    new(big.Int),
+		GasTipCap:                   big.NewInt(3),
+		GasFeeCap:                   big.NewInt(20),
+		Gas:                         100_000,
+		Sender:                      &sender,
+		ValidationGasLimit:          100_000,
+		Paymaster:                   &paymaster,
+		PaymasterValidationGasLimit: 100_000,
+		PostOpGas:                   50_000,
+	})
+	total, _ := tx.Rip7560TransactionData().TotalGasLimit()
+
+	apply := func(deposit uint64) (*state.StateDB, *types.Receipt, error) {
+		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.SetCode(sender, rip7560AccountCode())
+		statedb.SetCode(paymaster, rip7560PaymasterCodeWithPostOp(byte(vm.STOP)))
+		statedb.SetBalance(paymaster, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+		statedb.SetCode(AA_DEPOSIT_MANAGER, params.Rip7560DepositManagerCode)
+		addRip7560Deposit(statedb, paymaster, uint256.NewInt(deposit))
+
+		var usedGas uint64
+		receipt, err := ApplyRip7560Transaction(&config, nil, &coinbase, new(GasPool).AddGas(header.GasLimit), statedb, header, common.Hash{}, tx, 0, &usedGas, vm.Config{})
+		return statedb, receipt, err
+	}
+	// The deposit has to cover the maximum fee, whatever the balance
+	if _, _, err := apply(20*total - 1); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrInsufficientFunds)
+	}
+	statedb, receipt, err := apply(20 * total)
+	if err != nil {
+		t.Fatalf("failed to apply transaction: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("transaction failed")
+	}
+	want := uint256.NewInt(20*total - 13*receipt.GasUsed)
+	if have := GetRip7560Deposit(statedb, paymaster); have.Cmp(want) != 0 {
+		t.Errorf("deposit mismatch: have %v, want %v", have, want)
+	}
+	if have := statedb.GetBalance(AA_DEPOSIT_MANAGER); have.Cmp(want) != 0 {
+		t.Errorf("DepositManager balance mismatch: have %v, want %v", have, want)
+	}
+	if have := statedb.GetBalance(paymaster); have.Uint64() != params.Ether {
+		t.Errorf("paymaster balance charged: have %v, want %d", have, uint64(params.Ether))
+	}
+	if have := statedb.GetBalance(coinbase); have.Uint64() != 3*receipt.GasUsed {
+		t.Errorf("coinbase balance mismatch: have %v, want %d", have, 3*receipt.GasUsed)
+	}
+}
+
+// Tests that a zero paymaster address, standing for no paymaster, doesn't charge
+// the gas to the deposit of the zero address on chains funding paymasters by
+// deposits, but to the balance of the sender.
+func TestRip7560ZeroPaymasterDeposit(t *testing.T) {
+	var (
+		sender   = common.Address{0xaa}
+		coinbase = common.Address{0xcc}
+		header   = &types.Header{Number: new(big.Int), Difficulty: new(big.Int), GasLimit: 30_000_000, BaseFee: big.NewInt(10)}
+		config   = *params.AllDevChainProtocolChanges
+	)
+	config.Rip7560 = &params.Rip7560Config{PaymasterDeposits: true}
+
+	tx := types.NewTx(&types.Rip7560AccountAbstractionTx{
+		ChainID:            config.ChainID,
+		NonceKey:           new(big.Int),
+		GasTipCap:          big.NewInt(3),
+		GasFeeCap:          big.NewInt(20),
+		Gas:                100_000,
+		Sender:             &sender,
+		ValidationGasLimit: 100_000,
+		Paymaster:          &common.Address{},
+	})
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetCode(sender, rip7560AccountCode())
+	statedb.SetBalance(sender, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+	statedb.SetCode(AA_DEPOSIT_MANAGER, params.Rip7560DepositManagerCode)
+
+	var usedGas uint64
+	receipt, err := ApplyRip7560Transaction(&config, nil, &coinbase, new(GasPool).AddGas(header.GasLimit), statedb, header, common.Hash{}, tx, 0, &usedGas, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to apply transaction: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("transaction failed")
+	}
+	if have, want := statedb.GetBalance(sender), uint256.NewInt(params.Ether-13*receipt.GasUsed); have.Cmp(want) != 0 {
+		t.Errorf("sender balance mismatch: have %v, want %v", have, want)
+	}
+	if have := GetRip7560Deposit(statedb, common.Address{}); !have.IsZero() {
+		t.Errorf("zero address deposit charged: have %v", have)
+	}
+	if have := statedb.GetBalance(AA_DEPOSIT_MANAGER); !have.IsZero() {
+		t.Errorf("DepositManager balance charged: have %v", have)
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import "github.com/ethereum/go-ethereum/core/state"
+
+// Names of the RIP-7560 frames which may emit logs, other than the execution
+// frames named by ExecutionFrameName.
+const (
+	FrameNonceManager = "nonceManager"
+	FrameDeployer     = "deployer"
+	FrameAccount      = "account"
+	FramePaymaster    = "paymaster"
+	FramePostOp       = "postOp"
+
+	// FrameEntryPoint marks the events injected by the protocol itself.
+	FrameEntryPoint = "entryPoint"
+)
+
+// logFrames tracks which frame emitted each log of a RIP-7560 transaction.
+type logFrames []string
+
+// mark attributes the logs emitted by the current transaction since the
+// previous mark to the given frame. Logs dropped by a revert since then are
+// forgotten as well.
+func (f *logFrames) mark(statedb *state.StateDB, frame string) {
+	n := statedb.TxLogCount()
+	if n < len(*f) {
+		*f = (*f)[:n]
+	}
+	for len(*f) < n {
+		*f = append(*f, frame)
+	}
+}
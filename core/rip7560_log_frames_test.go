@@ -0,0 +1,53 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Tests that logs are attributed to the frame which emitted them, and that
+// reverted logs are forgotten.
+func TestRip7560LogFrames(t *testing.T) {
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetTxContext(common.Hash{0x01}, 0)
+
+	var frames logFrames
+	statedb.AddLog(&types.Log{})
+	frames.mark(statedb, FrameAccount)
+	frames.mark(statedb, FramePaymaster)
+
+	snapshot := statedb.Snapshot()
+	statedb.AddLog(&types.Log{})
+	statedb.AddLog(&types.Log{})
+	frames.mark(statedb, ExecutionFrameName(0, 2))
+	statedb.RevertToSnapshot(snapshot)
+	frames.mark(statedb, "")
+
+	statedb.AddLog(&types.Log{})
+	frames.mark(statedb, FrameEntryPoint)
+
+	if want := []string{FrameAccount, FrameEntryPoint}; !slices.Equal(frames, want) {
+		t.Fatalf("frame mismatch: have %v, want %v", frames, want)
+	}
+}
@@ -0,0 +1,316 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+var (
+	rip7560SpeculationHitMeter      = metrics.NewRegisteredMeter("chain/rip7560/speculation/hits", nil)
+	rip7560SpeculationConflictMeter = metrics.NewRegisteredMeter("chain/rip7560/speculation/conflicts", nil)
+)
+
+// rip7560Speculation is the validation phase of a RIP-7560 transaction run ahead
+// of its turn, on a copy of the state the RIP-7560 transactions of the block
+// start from. It holds as long as the state it read is unchanged when the turn
+// of the transaction comes, in which case its changes are merged instead of
+// validating the transaction again.
+type rip7560Speculation struct {
+	vpr        *ValidationPhaseResult
+	gas        uint64                                     // Block gas bought by the transaction
+	accounts   map[common.Address]*rip7560SpeculatedState // State read by the validation, before and after it
+	accessList types.AccessList                           // Warm addresses and slots left for the execution phase
+	logs       []*types.Log                               // Logs emitted by the validation frames
+}
+
+// rip7560SpeculatedState is an account read by a speculated validation, with the
+// storage slots read or written, as it was before and after the validation.
+type rip7560SpeculatedState struct {
+	pre, post rip7560AccountState
+}
+
+type rip7560AccountState struct {
+	exists   bool
+	empty    bool
+	balance  *uint256.Int
+	nonce    uint64
+	codeHash common.Hash
+	code     []byte
+	storage  map[common.Hash]common.Hash
+}
+
+func readRip7560AccountState(statedb *state.StateDB, addr common.Address, slots []common.Hash) rip7560AccountState {
+	account := rip7560AccountState{
+		exists:   statedb.Exist(addr),
+		empty:    statedb.Empty(addr),
+		balance:  statedb.GetBalance(addr).Clone(),
+		nonce:    statedb.GetNonce(addr),
+		codeHash: statedb.GetCodeHash(addr),
+		storage:  make(map[common.Hash]common.Hash, len(slots)),
+	}
+	for _, slot := range slots {
+		account.storage[slot] = statedb.GetState(addr, slot)
+	}
+	return account
+}
+
+// speculateRip7560Validations runs the validation phases of the leading RIP-7560
+// transactions concurrently, each on its own copy of the state. Transactions whose
+// validation failed, or can't be merged back into the state, have no speculation
+// and are validated in turn.
+func speculateRip7560Validations(
+	workers int,
+	transactions []*types.Transaction,
+	statedb *state.StateDB,
+	coinbase *common.Address,
+	header *types.Header,
+	gp *GasPool,
+	chainConfig *params.ChainConfig,
+	bc ChainContext,
+	cfg vm.Config,
+) []*rip7560Speculation {
+	var n int
+	for n < len(transactions) && transactions[n].Type() == types.Rip7560Type {
+		n++
+	}
+	if n < 2 {
+		return nil
+	}
+	// The state is copied up front, as it is mutated by the copies of itself
+	copies := make([]*state.StateDB, n)
+	for i := range copies {
+		copies[i] = statedb.Copy()
+	}
+	var (
+		specs = make([]*rip7560Speculation, n)
+		tasks = make(chan int, n)
+		gas   = gp.Gas()
+		wg    sync.WaitGroup
+	)
+	for i := 0; i < n; i++ {
+		tasks <- i
+	}
+	close(tasks)
+	for w := 0; w < min(workers, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range tasks {
+				specs[i] = speculateRip7560Validation(transactions[i], copies[i], coinbase, header, gas, chainConfig, bc, cfg)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The state the speculations started from is still the one of the block,
+	// so the state they read is compared against it
+	for i, spec := range specs {
+		if spec == nil {
+			continue
+		}
+		for addr, account := range spec.accounts {
+			slots := make([]common.Hash, 0, len(account.post.storage))
+			for slot := range account.post.storage {
+				slots = append(slots, slot)
+			}
+			account.pre = readRip7560AccountState(statedb, addr, slots)
+
+			// Deleted accounts can't be merged by setting their fields
+			if account.pre.exists && !account.post.exists {
+				specs[i] = nil
+				break
+			}
+			if account.post.codeHash != account.pre.codeHash {
+				account.post.code = copies[i].GetCode(addr)
+			}
+		}
+	}
+	return specs
+}
+
+// speculateRip7560Validation runs the validation phase of a transaction on the
+// given state copy, returning nil if it failed or can't be merged. The state of
+// the accounts read is only filled in after the validation.
+func speculateRip7560Validation(
+	tx *types.Transaction,
+	statedb *state.StateDB,
+	coinbase *common.Address,
+	header *types.Header,
+	gas uint64,
+	chainConfig *params.ChainConfig,
+	bc ChainContext,
+	cfg vm.Config,
+) *rip7560Speculation {
+	beneficiary := header.Coinbase
+	if coinbase != nil {
+		beneficiary = *coinbase
+	}
+	// The coinbase changes with every transaction, so only the validations not
+	// looking at it can be merged: neither its balance nor its code, nor if it
+	// is empty, which is priced in by calls. Neither can the ones using
+	// transient storage, which isn't carried over to the execution phase.
+	var mergeable = true
+	cfg.Tracer = &tracing.Hooks{
+		OnOpcode: func(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+			if scope.Address() == beneficiary {
+				mergeable = false
+				return
+			}
+			var (
+				stack = scope.StackData()
+				arg   = -1
+			)
+			switch vm.OpCode(op) {
+			case vm.TSTORE:
+				mergeable = false
+			case vm.BALANCE, vm.EXTCODESIZE, vm.EXTCODECOPY, vm.EXTCODEHASH, vm.SELFDESTRUCT:
+				arg = len(stack) - 1
+			case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+				arg = len(stack) - 2
+			}
+			if arg >= 0 && common.Address(stack[arg].Bytes20()) == beneficiary {
+				mergeable = false
+			}
+		},
+	}
+	statedb.SetTxContext(tx.Hash(), 0)
+
+	pool := new(GasPool).AddGas(gas)
+	vpr, err := ApplyRip7560ValidationPhases(chainConfig, bc, coinbase, pool, statedb, header, tx, cfg)
+	if err != nil || !mergeable {
+		return nil
+	}
+	// The accounts the protocol reads directly are not necessarily warm. Their
+	// balances are read too, so the coinbase can't be one of them.
+	aatx := tx.Rip7560TransactionData()
+	for _, addr := range []*common.Address{aatx.Sender, aatx.Paymaster, aatx.Deployer} {
+		if addr != nil && *addr == beneficiary {
+			return nil
+		}
+	}
+	read := map[common.Address][]common.Hash{
+		*aatx.Sender:      nil,
+		*aatx.GasPayer():  nil,
+		AA_ENTRY_POINT:    nil,
+		AA_SENDER_CREATOR: nil,
+		AA_NONCE_MANAGER:  nil,
+	}
+	if aatx.IsSponsored() {
+		read[*aatx.Paymaster] = nil
+	}
+	if aatx.Deployer != nil {
+		read[*aatx.Deployer] = nil
+	}
+	if chargesRip7560Deposit(chainConfig, aatx) {
+		read[AA_DEPOSIT_MANAGER] = []common.Hash{rip7560DepositSlot(*aatx.Paymaster)}
+	}
+	accessList := statedb.AccessList()
+	for _, tuple := range accessList {
+		read[tuple.Address] = append(read[tuple.Address], tuple.StorageKeys...)
+	}
+	spec := &rip7560Speculation{
+		vpr:        vpr,
+		gas:        gas - pool.Gas(),
+		accounts:   make(map[common.Address]*rip7560SpeculatedState, len(read)),
+		accessList: accessList,
+		logs:       statedb.GetLogs(tx.Hash(), header.Number.Uint64(), common.Hash{}),
+	}
+	delete(read, beneficiary)
+	for addr, slots := range read {
+		spec.accounts[addr] = &rip7560SpeculatedState{post: readRip7560AccountState(statedb, addr, slots)}
+	}
+	return spec
+}
+
+// holds reports whether the state read by the speculated validation is still
+// the same, so that validating the transaction again would have the same
+// outcome.
+func (s *rip7560Speculation) holds(statedb *state.StateDB, gp *GasPool) bool {
+	if gp.Gas() < s.gas {
+		return false
+	}
+	for addr, account := range s.accounts {
+		pre := &account.pre
+		if statedb.Exist(addr) != pre.exists || statedb.Empty(addr) != pre.empty ||
+			statedb.GetNonce(addr) != pre.nonce || statedb.GetCodeHash(addr) != pre.codeHash {
+			return false
+		}
+		if statedb.GetBalance(addr).Cmp(pre.balance) != 0 {
+			return false
+		}
+		for slot, value := range pre.storage {
+			if statedb.GetState(addr, slot) != value {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// apply merges the changes of the speculated validation into the state, leaving
+// it as validating the transaction in turn would have.
+func (s *rip7560Speculation) apply(statedb *state.StateDB, rules params.Rules, coinbase common.Address, gp *GasPool) *ValidationPhaseResult {
+	gp.SubGas(s.gas)
+	for addr, account := range s.accounts {
+		pre, post := &account.pre, &account.post
+		if !pre.exists && post.exists {
+			statedb.CreateAccount(addr)
+		}
+		switch post.balance.Cmp(pre.balance) {
+		case 1:
+			statedb.AddBalance(addr, new(uint256.Int).Sub(post.balance, pre.balance), tracing.BalanceChangeUnspecified)
+		case -1:
+			statedb.SubBalance(addr, new(uint256.Int).Sub(pre.balance, post.balance), tracing.BalanceChangeUnspecified)
+		}
+		if post.nonce != pre.nonce {
+			statedb.SetNonce(addr, post.nonce)
+		}
+		if post.codeHash != pre.codeHash {
+			statedb.SetCode(addr, post.code)
+		}
+		for slot, value := range post.storage {
+			if value != pre.storage[slot] {
+				statedb.SetState(addr, slot, value)
+			}
+		}
+	}
+	for _, log := range s.logs {
+		statedb.AddLog(&types.Log{Address: log.Address, Topics: log.Topics, Data: log.Data})
+	}
+	// The execution phase runs with the addresses and slots warmed up by the
+	// validation phase
+	aatx := s.vpr.Tx.Rip7560TransactionData()
+	statedb.Prepare(rules, *aatx.Sender, coinbase, &AA_ENTRY_POINT, vm.ActivePrecompiles(rules), s.vpr.Tx.AccessList())
+	for _, tuple := range s.accessList {
+		statedb.AddAddressToAccessList(tuple.Address)
+		for _, slot := range tuple.StorageKeys {
+			statedb.AddSlotToAccessList(tuple.Address, slot)
+		}
+	}
+	statedb.Finalise(true)
+	return s.vpr
+}
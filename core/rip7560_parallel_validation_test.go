@@ -0,0 +1,215 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// rip7560SpeculationTest is a block of RIP-7560 transactions whose validations
+// are independent of each other, except for the ones of the same sender and the
+// ones sponsored by the same paymaster.
+type rip7560SpeculationTest struct {
+	header    *types.Header
+	coinbase  common.Address
+	txs       []*types.Transaction
+	conflicts []bool // Whether the validation of each transaction depends on the ones before
+}
+
+func newRip7560SpeculationTest() (*rip7560SpeculationTest, *state.StateDB) {
+	var (
+		paymaster = common.Address{0xbb}
+		test      = &rip7560SpeculationTest{
+			header:   &types.Header{Number: new(big.Int), Difficulty: new(big.Int), GasLimit: 30_000_000, BaseFee: big.NewInt(1)},
+			coinbase: common.Address{0xcc},
+		}
+	)
+	// The accounts count their validations in their first slot, and log them
+	account := rip7560AccountCode(
+		byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.PUSH1), 1, byte(vm.ADD), byte(vm.PUSH1), 0, byte(vm.SSTORE),
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.LOG0),
+	)
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetCode(paymaster, rip7560PaymasterCode())
+	statedb.SetBalance(paymaster, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+
+	add := func(sender common.Address, nonce uint64, sponsored bool, conflict bool) {
+		aatx := &types.Rip7560AccountAbstractionTx{
+			ChainID:            params.AllDevChainProtocolChanges.ChainID,
+			NonceKey:           new(big.Int),
+			Nonce:              nonce,
+			GasTipCap:          big.NewInt(2),
+			GasFeeCap:          big.NewInt(3),
+			Gas:                100_000,
+			Sender:             &sender,
+			ExecutionData:      []byte{0x01},
+			ValidationGasLimit: 100_000,
+		}
+		if sponsored {
+			aatx.Paymaster = &paymaster
+			aatx.PaymasterValidationGasLimit = 100_000
+			aatx.PostOpGas = 100_000
+		}
+		if nonce == 0 {
+			statedb.SetCode(sender, account)
+			statedb.SetBalance(sender, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+		}
+		test.txs = append(test.txs, types.NewTx(aatx))
+		test.conflicts = append(test.conflicts, conflict)
+	}
+	add(common.Address{0x01}, 0, false, false)
+	add(common.Address{0x02}, 0, false, false)
+	add(common.Address{0x02}, 1, false, true)
+	add(common.Address{0x03}, 0, true, false)
+	add(common.Address{0x04}, 0, true, true)
+	add(common.Address{0x05}, 0, false, false)
+
+	statedb.Finalise(true)
+	return test, statedb
+}
+
+// Tests that the validations run ahead of their turn are merged as long as the
+// state they read is unchanged, and dropped otherwise.
+func TestRip7560SpeculatedValidations(t *testing.T) {
+	test, statedb := newRip7560SpeculationTest()
+	gp := new(GasPool).AddGas(test.header.GasLimit)
+
+	specs := speculateRip7560Validations(4, test.txs, statedb, &test.coinbase, test.header, gp, params.AllDevChainProtocolChanges, nil, vm.Config{})
+	if len(specs) != len(test.txs) {
+		t.Fatalf("speculation count mismatch: have %d, want %d", len(specs), len(test.txs))
+	}
+	for i, tx := range test.txs {
+		statedb.SetTxContext(tx.Hash(), i)
+		// Transactions depending on the ones before may fail validation
+		// on the state of the block
+		if specs[i] == nil && !test.conflicts[i] {
+			t.Fatalf("tx %d: validation not speculated", i)
+		}
+		if holds := specs[i] != nil && specs[i].holds(statedb, gp); holds == test.conflicts[i] {
+			t.Errorf("tx %d: speculation holding mismatch: have %v, want %v", i, holds, !test.conflicts[i])
+		}
+		vpr, err := ApplyRip7560ValidationPhases(params.AllDevChainProtocolChanges, nil, &test.coinbase, gp, statedb, test.header, tx, vm.Config{})
+		if err != nil {
+			t.Fatalf("tx %d: validation failed: %v", i, err)
+		}
+		var usedGas uint64
+		if _, err := ApplyRip7560ExecutionPhase(params.AllDevChainProtocolChanges, vpr, nil, &test.coinbase, gp, statedb, test.header, vm.Config{}, &usedGas); err != nil {
+			t.Fatalf("tx %d: execution failed: %v", i, err)
+		}
+		statedb.Finalise(true)
+	}
+}
+
+// Tests that validating the transactions of a block concurrently leaves the same
+// state and receipts as validating them one after the other.
+func TestRip7560ParallelValidation(t *testing.T) {
+	process := func(workers int) (common.Hash, types.Receipts, uint64) {
+		test, statedb := newRip7560SpeculationTest()
+
+		var (
+			gp      = new(GasPool).AddGas(test.header.GasLimit)
+			usedGas uint64
+		)
+		txs, receipts, _, _, err := handleRip7560Transactions(test.txs, 0, statedb, &test.coinbase, test.header, gp, params.AllDevChainProtocolChanges, nil, vm.Config{}, workers, false, &usedGas)
+		if err != nil {
+			t.Fatalf("workers %d: failed to process transactions: %v", workers, err)
+		}
+		if len(txs) != len(test.txs) {
+			t.Fatalf("workers %d: included transaction count mismatch: have %d, want %d", workers, len(txs), len(test.txs))
+		}
+		return statedb.IntermediateRoot(true), receipts, usedGas
+	}
+	wantRoot, wantReceipts, wantGas := process(0)
+	for _, workers := range []int{2, 4, 16} {
+		root, receipts, usedGas := process(workers)
+		if root != wantRoot {
+			t.Errorf("workers %d: state root mismatch: have %x, want %x", workers, root, wantRoot)
+		}
+		if usedGas != wantGas {
+			t.Errorf("workers %d: used gas mismatch: have %d, want %d", workers, usedGas, wantGas)
+		}
+		for i := range receipts {
+			if !reflect.DeepEqual(receipts[i], wantReceipts[i]) {
+				t.Errorf("workers %d, tx %d: receipt mismatch:\nhave %+v\nwant %+v", workers, i, receipts[i], wantReceipts[i])
+			}
+		}
+	}
+}
+
+// Tests that blocks carrying consecutive RIP-7560 transactions are imported with
+// their validation phases run ahead concurrently if enabled, and end up with the
+// same state as validating them one after the other.
+func TestRip7560ParallelValidationImport(t *testing.T) {
+	config := *params.TestChainConfig
+	config.RIP7560Block = big.NewInt(0)
+
+	var (
+		senders = []common.Address{{0x01}, {0x02}, {0x03}}
+		alloc   = make(types.GenesisAlloc)
+	)
+	for _, sender := range senders {
+		alloc[sender] = types.Account{Balance: big.NewInt(params.Ether), Code: rip7560AccountCode()}
+	}
+	gspec := &Genesis{Config: &config, Alloc: alloc}
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 2, func(i int, b *BlockGen) {
+		for _, sender := range senders {
+			b.AddTx(types.NewTx(newRip7560TestTx(sender, func(aatx *types.Rip7560AccountAbstractionTx) {
+				aatx.ChainID, aatx.Nonce, aatx.GasFeeCap = config.ChainID, uint64(i), b.BaseFee()
+			})))
+		}
+	})
+	// Count the merged speculations, the meters are inert unless metrics are on
+	enabled, hits := metrics.Enabled, rip7560SpeculationHitMeter
+	metrics.Enabled = true
+	rip7560SpeculationHitMeter = metrics.NewInactiveMeter()
+	defer func() { metrics.Enabled, rip7560SpeculationHitMeter = enabled, hits }()
+
+	for _, workers := range []int{0, 4} {
+		cacheConfig := DefaultCacheConfigWithScheme(rawdb.HashScheme)
+		cacheConfig.Rip7560ValidationWorkers = workers
+
+		chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), cacheConfig, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+		if err != nil {
+			t.Fatalf("workers %d: failed to create tester chain: %v", workers, err)
+		}
+		if n, err := chain.InsertChain(blocks); err != nil {
+			t.Fatalf("workers %d: failed to import block %d: %v", workers, n, err)
+		}
+		block := blocks[len(blocks)-1]
+		receipts := chain.GetReceiptsByHash(block.Hash())
+		if len(receipts) != len(senders) || receipts[0].BlockHash != block.Hash() {
+			t.Fatalf("workers %d: unexpected receipts: %v", workers, receipts)
+		}
+		chain.Stop()
+	}
+	if have, want := rip7560SpeculationHitMeter.Snapshot().Count(), int64(len(senders)*len(blocks)); have != want {
+		t.Fatalf("merged speculation count mismatch: have %d, want %d", have, want)
+	}
+}
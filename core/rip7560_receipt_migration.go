@@ -0,0 +1,119 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// Rip7560ReceiptMigration summarizes a run of MigrateRip7560Receipts.
+type Rip7560ReceiptMigration struct {
+	Migrated int // Blocks whose receipts were upgraded
+	Skipped  int // Blocks which couldn't be upgraded, see MigrateRip7560Receipts
+}
+
+// MigrateRip7560Receipts upgrades the receipts of the canonical RIP-7560
+// transactions which were stored before the validation gas and the frame
+// statuses were part of the receipt, along with the frames emitting their logs.
+// Since neither can be derived from the stored data, the affected blocks are
+// re-executed on top of their parent state.
+//
+// The progress is persisted as the blocks are upgraded, so an interrupted
+// migration resumes where it stopped. Blocks whose parent state was pruned or
+// whose receipts were already moved to the freezer can't be upgraded, they are
+// skipped with a warning.
+func (bc *BlockChain) MigrateRip7560Receipts() (*Rip7560ReceiptMigration, error) {
+	result := new(Rip7560ReceiptMigration)
+	if bc.chainConfig.RIP7560Block == nil {
+		return result, nil
+	}
+	var (
+		head   = bc.CurrentBlock().Number.Uint64()
+		number = bc.chainConfig.RIP7560Block.Uint64()
+	)
+	if next := rawdb.ReadRip7560ReceiptMigration(bc.db); next != nil {
+		number = *next
+	}
+	if number == 0 {
+		number = 1 // Genesis has no transactions
+	}
+	var (
+		frozen, _ = bc.db.Ancients()
+		start     = time.Now()
+		logged    = time.Now()
+	)
+	for ; number <= head; number++ {
+		block := bc.GetBlockByNumber(number)
+		if block == nil {
+			return result, fmt.Errorf("missing block %d", number)
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Migrating RIP-7560 receipts", "number", number, "head", head, "migrated", result.Migrated, "skipped", result.Skipped, "elapsed", common.PrettyDuration(time.Since(start)))
+			rawdb.WriteRip7560ReceiptMigration(bc.db, number)
+			logged = time.Now()
+		}
+		if !rawdb.HasLegacyRip7560Receipts(bc.db, block.Hash(), number, block.Transactions()) {
+			continue
+		}
+		if number < frozen {
+			log.Warn("Skipping RIP-7560 receipts in the freezer", "number", number, "hash", block.Hash())
+			result.Skipped++
+			continue
+		}
+		parent := bc.GetHeader(block.ParentHash(), number-1)
+		if parent == nil {
+			return result, fmt.Errorf("missing parent of block %d", number)
+		}
+		statedb, err := bc.StateAt(parent.Root)
+		if err != nil {
+			log.Warn("Skipping RIP-7560 receipts without parent state", "number", number, "hash", block.Hash(), "err", err)
+			result.Skipped++
+			continue
+		}
+		receipts, _, _, err := bc.processor.Process(block, statedb, bc.vmConfig)
+		if err != nil {
+			return result, fmt.Errorf("failed to re-execute block %d: %w", number, err)
+		}
+		// Never overwrite the receipts with ones not matching the block
+		if hash := types.DeriveSha(receipts, trie.NewStackTrie(nil)); hash != block.ReceiptHash() {
+			return result, fmt.Errorf("re-executed block %d receipt root mismatch: have %x, want %x", number, hash, block.ReceiptHash())
+		}
+		batch := bc.db.NewBatch()
+		rawdb.WriteReceipts(batch, block.Hash(), number, receipts)
+		for _, receipt := range receipts {
+			if len(receipt.LogFrames) > 0 {
+				rawdb.WriteRip7560LogFrames(batch, block.Hash(), receipt.TxHash, receipt.LogFrames)
+			}
+		}
+		rawdb.WriteRip7560ReceiptMigration(batch, number+1)
+		if err := batch.Write(); err != nil {
+			return result, err
+		}
+		result.Migrated++
+	}
+	rawdb.WriteRip7560ReceiptMigration(bc.db, number)
+	bc.receiptsCache.Purge()
+	log.Info("Migrated RIP-7560 receipts", "head", head, "migrated", result.Migrated, "skipped", result.Skipped, "elapsed", common.PrettyDuration(time.Since(start)))
+	return result, nil
+}
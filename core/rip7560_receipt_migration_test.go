@@ -0,0 +1,100 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that receipts stored without the RIP-7560 fields are upgraded by
+// re-executing their blocks, and that the migration resumes after them.
+func TestMigrateRip7560Receipts(t *testing.T) {
+	config := *params.TestChainConfig
+	config.RIP7560Block = big.NewInt(0)
+
+	var (
+		sender = common.Address{0xaa}
+		gspec  = &Genesis{
+			Config: &config,
+			Alloc: types.GenesisAlloc{
+				sender: {Balance: big.NewInt(params.Ether), Code: rip7560AccountCode()},
+			},
+		}
+	)
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 2, func(i int, b *BlockGen) {
+		b.AddTx(types.NewTx(&types.Rip7560AccountAbstractionTx{
+			ChainID:   config.ChainID,
+			NonceKey:  new(big.Int),
+			Nonce:     uint64(i),
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: b.BaseFee(),
+			Gas:       100_000,
+			Sender:    &sender,
+
+			ValidationGasLimit: 100_000,
+		}))
+	})
+	db := rawdb.NewMemoryDatabase()
+	chain, err := NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	// Store the receipts of the first block the way they used to be
+	block := blocks[0]
+	want := rawdb.ReadReceipts(db, block.Hash(), block.NumberU64(), block.Time(), &config)
+	if len(want) != 1 || want[0].ValidationGasUsed == 0 || len(want[0].FrameStatuses) == 0 {
+		t.Fatalf("unexpected receipts: %v", want)
+	}
+	legacy := *want[0]
+	legacy.Type = types.LegacyTxType
+	rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), types.Receipts{&legacy})
+	if !rawdb.HasLegacyRip7560Receipts(db, block.Hash(), block.NumberU64(), block.Transactions()) {
+		t.Fatal("legacy receipts not detected")
+	}
+	result, err := chain.MigrateRip7560Receipts()
+	if err != nil {
+		t.Fatalf("failed to migrate receipts: %v", err)
+	}
+	if *result != (Rip7560ReceiptMigration{Migrated: 1}) {
+		t.Fatalf("migration mismatch: have %+v", result)
+	}
+	have := rawdb.ReadReceipts(db, block.Hash(), block.NumberU64(), block.Time(), &config)
+	if len(have) != 1 || have[0].ValidationGasUsed != want[0].ValidationGasUsed || !reflect.DeepEqual(have[0].FrameStatuses, want[0].FrameStatuses) {
+		t.Fatalf("receipts mismatch: have %v, want %v", have, want)
+	}
+	if next := rawdb.ReadRip7560ReceiptMigration(db); next == nil || *next != 3 {
+		t.Fatalf("progress mismatch: have %v, want 3", next)
+	}
+	// Blocks before the progress marker are not visited again
+	rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), types.Receipts{&legacy})
+	if result, err := chain.MigrateRip7560Receipts(); err != nil || result.Migrated != 0 {
+		t.Fatalf("unexpected second migration: %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"math"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// rip7560Rules are the parameters of a chain deciding whether RIP-7560
+// transactions, and the tooling producing them, are compatible with it.
+type rip7560Rules struct {
+	AbiVersion    uint64
+	RulesVersion  uint64
+	EntryPoint    common.Address
+	SenderCreator common.Address
+	NonceManager  common.Address
+	Forks         []uint64 // RIP-7560 and RIP-7712 activation block and time
+
+	MaxTxsPerBlock          uint64
+	MaxGasPerBlock          uint64
+	MaxPaymasterContextSize uint64
+	OrderedBundles          bool
+	BannedOpcodes           []string
+
+	// PaymasterDeposits and GasPenaltyPercent are optional, leaving the hash
+	// of the chains charging the balance of paymasters and the default penalty
+	// unchanged
+	PaymasterDeposits bool    `rlp:"optional"`
+	GasPenaltyPercent *uint64 `rlp:"optional"`
+}
+
+// Rip7560RulesHash returns a fork identifier like checksum of the RIP-7560
+// rules of the chain: the ABI and validation rules versions, the system
+// contract addresses, the activation of the AA forks, the AA limits, the
+// bundle ordering, the paymaster funding and the unused gas penalty. Nodes and
+// devnets with different hashes don't accept the same transactions.
+func Rip7560RulesHash(config *params.ChainConfig) [4]byte {
+	rules := rip7560Rules{
+		AbiVersion:    config.Rip7560AbiVersion(),
+		RulesVersion:  Rip7560ValidationRulesVersion,
+		EntryPoint:    AA_ENTRY_POINT,
+		SenderCreator: AA_SENDER_CREATOR,
+		NonceManager:  AA_NONCE_MANAGER,
+		Forks: []uint64{
+			forkBlock(config.RIP7560Block), forkTime(config.RIP7560Time),
+			forkBlock(config.RIP7712Block), forkTime(config.RIP7712Time),
+		},
+		MaxTxsPerBlock:          config.Rip7560MaxTxsPerBlock(),
+		MaxGasPerBlock:          config.Rip7560MaxGasPerBlock(),
+		MaxPaymasterContextSize: config.Rip7560MaxPaymasterContextSize(),
+		OrderedBundles:          config.Rip7560OrderedBundles(),
+		PaymasterDeposits:       config.Rip7560PaymasterDeposits(),
+	}
+	if penalty := config.Rip7560GasPenaltyPercent(); penalty != params.Rip7560GasPenaltyPercent {
+		rules.GasPenaltyPercent = &penalty
+	}
+	for op := range config.Rip7560BannedOpcodes() {
+		rules.BannedOpcodes = append(rules.BannedOpcodes, op)
+	}
+	sort.Strings(rules.BannedOpcodes)
+
+	blob, err := rlp.EncodeToBytes(&rules)
+	if err != nil {
+		panic(err) // can't happen, all fields are encodable
+	}
+	var hash [4]byte
+	binary.BigEndian.PutUint32(hash[:], crc32.ChecksumIEEE(blob))
+	return hash
+}
+
+// forkBlock returns the activation block of a fork, or the maximum one if the
+// fork is not scheduled.
+func forkBlock(block *big.Int) uint64 {
+	if block == nil || !block.IsUint64() {
+		return math.MaxUint64
+	}
+	return block.Uint64()
+}
+
+// forkTime returns the activation time of a fork, or the maximum one if the
+// fork is not scheduled.
+func forkTime(time *uint64) uint64 {
+	if time == nil {
+		return math.MaxUint64
+	}
+	return *time
+}
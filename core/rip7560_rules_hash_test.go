@@ -0,0 +1,68 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the rules hash changes with the AA parameters of the chain only.
+func TestRip7560RulesHash(t *testing.T) {
+	base := *params.TestChainConfig
+	base.RIP7560Block = big.NewInt(0)
+	hash := Rip7560RulesHash(&base)
+
+	same := base
+	same.RIP7560Block = big.NewInt(0)
+	same.LondonBlock = big.NewInt(10)
+	if have := Rip7560RulesHash(&same); have != hash {
+		t.Errorf("hash changed by non-AA parameter: have %x, want %x", have, hash)
+	}
+	changes := map[string]func(c *params.ChainConfig){
+		"rip7560 block": func(c *params.ChainConfig) { c.RIP7560Block = big.NewInt(1) },
+		"rip7560 time":  func(c *params.ChainConfig) { c.RIP7560Block, c.RIP7560Time = nil, new(uint64) },
+		"rip7712 block": func(c *params.ChainConfig) { c.RIP7712Block = big.NewInt(0) },
+		"max txs":       func(c *params.ChainConfig) { c.Rip7560 = &params.Rip7560Config{MaxTxsPerBlock: 10} },
+		"context size":  func(c *params.ChainConfig) { c.Rip7560 = &params.Rip7560Config{MaxPaymasterContextSize: 10} },
+		"ordering":      func(c *params.ChainConfig) { c.Rip7560 = &params.Rip7560Config{OrderedBundles: true} },
+		"deposits":      func(c *params.ChainConfig) { c.Rip7560 = &params.Rip7560Config{PaymasterDeposits: true} },
+		"abi version":   func(c *params.ChainConfig) { c.Rip7560 = &params.Rip7560Config{AbiVersion: 1} },
+		"gas penalty": func(c *params.ChainConfig) {
+			c.Rip7560 = &params.Rip7560Config{GasPenaltyPercent: new(uint64)}
+		},
+		"banned opcodes": func(c *params.ChainConfig) {
+			c.Rip7560 = &params.Rip7560Config{BannedOpcodeExemptions: []string{"GAS"}}
+		},
+	}
+	for name, change := range changes {
+		config := base
+		change(&config)
+		if have := Rip7560RulesHash(&config); have == hash {
+			t.Errorf("%s: hash not changed", name)
+		}
+	}
+	// The default limits are equivalent to the explicit ones
+	explicit := base
+	penalty := params.Rip7560GasPenaltyPercent
+	explicit.Rip7560 = &params.Rip7560Config{MaxPaymasterContextSize: params.Rip7560MaxPaymasterContextSize, GasPenaltyPercent: &penalty}
+	if have := Rip7560RulesHash(&explicit); have != hash {
+		t.Errorf("hash changed by explicit default: have %x, want %x", have, hash)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	rip7560SideTableCheckedMeter = metrics.NewRegisteredMeter("chain/rip7560/sidetable/checked", nil)
+	rip7560SideTableOrphanMeter  = metrics.NewRegisteredMeter("chain/rip7560/sidetable/orphans", nil)
+)
+
+// repairRip7560SideTables brings the RIP-7560 side tables back in line with
+// the chain after an unclean shutdown, a rewind or a reorg: the log frames of
+// the blocks no longer canonical are dropped, and the receipt migration progress is
+// moved back to the head if it ran past it. It's meant to run in the background
+// on startup and stops when the chain is closed.
+func (bc *BlockChain) repairRip7560SideTables() {
+	defer bc.wg.Done()
+
+	start := time.Now()
+	if next := rawdb.ReadRip7560ReceiptMigration(bc.db); next != nil {
+		if head := bc.CurrentBlock().Number.Uint64(); *next > head+1 {
+			log.Warn("Rewinding RIP-7560 receipt migration past the head", "next", *next, "head", head)
+			rawdb.WriteRip7560ReceiptMigration(bc.db, head+1)
+			rip7560SideTableOrphanMeter.Mark(1)
+		}
+	}
+	checked, deleted := rawdb.DeleteOrphanedRip7560LogFrames(bc.db, bc.quit)
+	rip7560SideTableCheckedMeter.Mark(int64(checked))
+	rip7560SideTableOrphanMeter.Mark(int64(deleted))
+
+	if deleted > 0 {
+		log.Warn("Deleted orphaned RIP-7560 log frames", "checked", checked, "deleted", deleted, "elapsed", common.PrettyDuration(time.Since(start)))
+	} else {
+		log.Debug("Checked RIP-7560 log frames", "checked", checked, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+}
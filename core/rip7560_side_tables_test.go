@@ -0,0 +1,72 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the RIP-7560 side table entries of blocks no longer in the
+// database or not canonical are dropped on startup, and the others are kept.
+func TestRepairRip7560SideTables(t *testing.T) {
+	config := *params.TestChainConfig
+	config.RIP7560Block = big.NewInt(0)
+
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		gspec  = &Genesis{Config: &config}
+		orphan = common.Hash{0xde, 0xad}
+		tx     = common.Hash{0x01}
+	)
+	chain, err := NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	genesis := chain.Genesis()
+	side := &types.Header{ParentHash: genesis.Hash(), Number: big.NewInt(1), Difficulty: big.NewInt(1)}
+	rawdb.WriteHeader(db, side)
+	rawdb.WriteRip7560LogFrames(db, genesis.Hash(), tx, []string{"execution"})
+	rawdb.WriteRip7560LogFrames(db, orphan, tx, []string{"execution"})
+	rawdb.WriteRip7560LogFrames(db, side.Hash(), tx, []string{"execution"})
+	rawdb.WriteRip7560ReceiptMigration(db, 10)
+
+	chain.wg.Add(1)
+	chain.repairRip7560SideTables()
+
+	if frames := rawdb.ReadRip7560LogFrames(db, genesis.Hash(), tx); len(frames) != 1 {
+		t.Errorf("canonical log frames dropped: %v", frames)
+	}
+	if frames := rawdb.ReadRip7560LogFrames(db, orphan, tx); frames != nil {
+		t.Errorf("orphaned log frames kept: %v", frames)
+	}
+	if frames := rawdb.ReadRip7560LogFrames(db, side.Hash(), tx); frames != nil {
+		t.Errorf("side chain log frames kept: %v", frames)
+	}
+	if next := rawdb.ReadRip7560ReceiptMigration(db); next == nil || *next != 1 {
+		t.Errorf("receipt migration progress mismatch: have %v, want 1", next)
+	}
+}
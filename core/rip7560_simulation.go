@@ -0,0 +1,144 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// CallRip7560Transaction runs both phases of a RIP-7560 transaction like
+// SimulateRip7560Transaction, additionally reporting every frame run and the
+// output of the execution. A failed validation is returned as an error, as the
+// transaction could not be included.
+func CallRip7560Transaction(config *params.ChainConfig, bc ChainContext, header *types.Header, statedb *state.StateDB, tx *types.Transaction, cfg vm.Config) (*types.Rip7560CallResult, error) {
+	result := &types.Rip7560CallResult{Frames: []*types.Rip7560CallFrame{}}
+	cfg.Tracer = tracing.NewMuxHooks(cfg.Tracer, &tracing.Hooks{
+		OnAAFrameStart: func(frame string, to common.Address, input []byte, gasLimit uint64) {
+			result.Frames = append(result.Frames, &types.Rip7560CallFrame{Name: frame, Target: to, GasLimit: hexutil.Uint64(gasLimit)})
+		},
+		OnAAFrameEnd: func(frame string, output []byte, gasUsed uint64, err error) {
+			last := result.Frames[len(result.Frames)-1]
+			last.GasUsed = hexutil.Uint64(gasUsed)
+			if err != nil {
+				last.Error = err.Error()
+			}
+			if strings.HasPrefix(frame, "execution") {
+				result.ReturnData = common.CopyBytes(output)
+			}
+		},
+	})
+	gas, receipt, err := SimulateRip7560Transaction(config, bc, header, statedb, tx, cfg)
+	if gas == nil {
+		return nil, err
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	result.Status = hexutil.Uint64(receipt.Status)
+	result.Logs = receipt.Logs
+	result.GasUsed = hexutil.Uint64(receipt.GasUsed)
+	result.FrameGas = gas
+	if result.Logs == nil {
+		result.Logs = []*types.Log{}
+	}
+	return result, nil
+}
+
+// SimulateRip7560Transaction runs both phases of a RIP-7560 transaction in the
+// context of the given header, returning the gas used by its frames and its
+// receipt. The block gas limit is not enforced. The state is modified by the
+// simulation, callers should pass a copy. A reverted postOp frame is reported
+// as an ErrAAPaymasterPostOpReverted error, along with the gas and the receipt.
+func SimulateRip7560Transaction(config *params.ChainConfig, bc ChainContext, header *types.Header, statedb *state.StateDB, tx *types.Transaction, cfg vm.Config) (*types.Rip7560FrameGas, *types.Receipt, error) {
+	if tx.Type() != types.Rip7560Type {
+		return nil, nil, errors.New("not a RIP-7560 transaction")
+	}
+	gp := new(GasPool).AddGas(math.MaxUint64)
+	statedb.SetTxContext(tx.Hash(), 0)
+	vpr, err := ApplyRip7560ValidationPhases(config, bc, &header.Coinbase, gp, statedb, header, tx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	validationGas, err := vpr.validationPhaseUsedGas()
+	if err != nil {
+		return nil, nil, err
+	}
+	gas := &types.Rip7560FrameGas{
+		ValidationGas:          hexutil.Uint64(validationGas - vpr.PmValidationUsedGas),
+		PaymasterValidationGas: hexutil.Uint64(vpr.PmValidationUsedGas),
+	}
+	// The execution frames are the top level calls of the sender, while the
+	// postOp frame is the one of the paymaster
+	var (
+		aatx   = tx.Rip7560TransactionData()
+		target common.Address
+		postOp *ExecutionResult
+	)
+	cfg.Tracer = tracing.NewMuxHooks(cfg.Tracer, &tracing.Hooks{
+		OnEnter: func(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+			if depth == 0 {
+				target = to
+			}
+		},
+		OnExit: func(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+			if depth != 0 {
+				return
+			}
+			switch {
+			case target == *aatx.Sender:
+				gas.CallGas += hexutil.Uint64(gasUsed)
+			case aatx.Paymaster != nil && target == *aatx.Paymaster:
+				gas.PostOpGas += hexutil.Uint64(gasUsed)
+				if err != nil {
+					postOp = &ExecutionResult{Err: err, ReturnData: common.CopyBytes(output)}
+				}
+			}
+		},
+	})
+	var usedGas uint64
+	receipt, err := ApplyRip7560ExecutionPhase(config, vpr, bc, &header.Coinbase, gp, statedb, header, cfg, &usedGas)
+	if err != nil {
+		return nil, nil, err
+	}
+	if postOp != nil {
+		err := fmt.Errorf("%w: %v", ErrAAPaymasterPostOpReverted, postOp.Err)
+		if reason, errUnpack := abi.UnpackRevert(postOp.ReturnData); errUnpack == nil {
+			err = fmt.Errorf("%w: %v", err, reason)
+		}
+		return gas, receipt, &ValidationPhaseError{
+			error:            err,
+			kind:             ErrAAPaymasterPostOpReverted,
+			reason:           hexutil.Encode(postOp.ReturnData),
+			revertEntityName: ptr(FramePostOp),
+			frameReverted:    true,
+		}
+	}
+	return gas, receipt, nil
+}
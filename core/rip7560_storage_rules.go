@@ -0,0 +1,103 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// maxAssociatedSlotOffset is the largest offset from the hash of a key made of
+// the sender address to a slot still associated with the sender, covering the
+// members of the structs stored in mappings keyed by the sender.
+const maxAssociatedSlotOffset = 128
+
+// storageAccessTracker decides which storage the validation frames of a
+// transaction may access according to the ERC-7562 storage rules. Frames may
+// access the storage of the sender [STO-010], the slots associated with the
+// sender in any contract [STO-021] and the storage of the entities of the
+// transaction. Staking is not part of RIP-7560, so the paymaster and the
+// deployer are treated as staked entities with access to their own storage
+// [STO-031].
+type storageAccessTracker struct {
+	sender     common.Address
+	entities   map[common.Address]struct{}
+	associated []*uint256.Int // Hashes of the keys starting with the sender
+}
+
+func newStorageAccessTracker(aatx *types.Rip7560AccountAbstractionTx) *storageAccessTracker {
+	t := &storageAccessTracker{
+		sender: *aatx.Sender,
+		entities: map[common.Address]struct{}{
+			AA_ENTRY_POINT:     {},
+			AA_NONCE_MANAGER:   {},
+			AA_DEPOSIT_MANAGER: {},
+		},
+	}
+	if aatx.Paymaster != nil {
+		t.entities[*aatx.Paymaster] = struct{}{}
+	}
+	if aatx.Deployer != nil {
+		t.entities[*aatx.Deployer] = struct{}{}
+	}
+	return t
+}
+
+// onKeccak records the hash of a KECCAK256 input starting with the sender
+// address as a 32 bytes word, the way Solidity derives the slots of mapping
+// entries keyed by the sender.
+func (t *storageAccessTracker) onKeccak(input []byte) {
+	if len(input) < 32 || !bytes.Equal(input[:32], common.LeftPadBytes(t.sender.Bytes(), 32)) {
+		return
+	}
+	t.associated = append(t.associated, new(uint256.Int).SetBytes(crypto.Keccak256(input)))
+}
+
+// allowed reports whether the validation frames may access the given slot of
+// the given account.
+func (t *storageAccessTracker) allowed(addr common.Address, slot common.Hash) bool {
+	if addr == t.sender {
+		return true
+	}
+	if _, ok := t.entities[addr]; ok {
+		return true
+	}
+	return t.isAssociated(slot)
+}
+
+// isAssociated reports whether a slot is associated with the sender: the slot
+// is either the sender address itself, or at a small offset from a hash of a
+// key starting with the sender address.
+func (t *storageAccessTracker) isAssociated(slot common.Hash) bool {
+	if slot == common.BytesToHash(t.sender.Bytes()) {
+		return true
+	}
+	value := new(uint256.Int).SetBytes(slot.Bytes())
+	for _, base := range t.associated {
+		if value.Lt(base) {
+			continue
+		}
+		if offset := new(uint256.Int).Sub(value, base); offset.IsUint64() && offset.Uint64() <= maxAssociatedSlotOffset {
+			return true
+		}
+	}
+	return false
+}
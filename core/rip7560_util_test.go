@@ -0,0 +1,181 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/aa"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// This file holds the builders shared by the RIP-7560 tests: the code of the
+// accounts and paymasters accepting all transactions, the funded test state
+// and the transactions using them.
+
+// rip7560AccountCode returns the code of an account accepting all transactions,
+// which executes the given code before calling the EntryPoint.
+func rip7560AccountCode(prefix ...byte) []byte {
+	sel := Rip7560Abi.Methods["acceptAccount"].ID
+	code := append(prefix, byte(vm.PUSH4), sel[0], sel[1], sel[2], sel[3], byte(vm.PUSH1), 0xe0, byte(vm.SHL), byte(vm.PUSH1), 0, byte(vm.MSTORE),
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0x44, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH20))
+	code = append(code, AA_ENTRY_POINT.Bytes()...)
+	return append(code, byte(vm.GAS), byte(vm.CALL), byte(vm.STOP))
+}
+
+// rip7560TestFrameABI is the revision of the account and paymaster interfaces
+// the test chains call the frames with.
+var rip7560TestFrameABI, _ = aa.Lookup(aa.LatestVersion)
+
+// rip7560PaymasterCode returns the code of a paymaster sponsoring all
+// transactions with a one byte context, whose postOp frame writes its storage
+// and reverts.
+func rip7560PaymasterCode() []byte {
+	return rip7560PaymasterCodeWithPostOp(byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.REVERT))
+}
+
+// rip7560PaymasterCodeWithPostOp returns the code of a paymaster sponsoring all
+// transactions with the one byte context 0xff, whose postOp frame runs the given
+// code.
+func rip7560PaymasterCodeWithPostOp(postOpCode ...byte) []byte {
+	var (
+		postOp = rip7560TestFrameABI.ABI.Methods["postPaymasterTransaction"].ID
+		accept = Rip7560Abi.Methods["acceptPaymaster"].ID
+	)
+	validate := []byte{
+		byte(vm.PUSH4), accept[0], accept[1], accept[2], accept[3], byte(vm.PUSH1), 0xe0, byte(vm.SHL), byte(vm.PUSH1), 0, byte(vm.MSTORE),
+		byte(vm.PUSH1), 0x60, byte(vm.PUSH1), 0x44, byte(vm.MSTORE),
+		byte(vm.PUSH1), 1, byte(vm.PUSH1), 0x64, byte(vm.MSTORE),
+		byte(vm.PUSH1), 0xff, byte(vm.PUSH1), 0xf8, byte(vm.SHL), byte(vm.PUSH1), 0x84, byte(vm.MSTORE),
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0xa4, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH20),
+	}
+	validate = append(validate, AA_ENTRY_POINT.Bytes()...)
+	validate = append(validate, byte(vm.GAS), byte(vm.CALL), byte(vm.STOP))
+
+	code := []byte{
+		byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 0xe0, byte(vm.SHR),
+		byte(vm.PUSH4), postOp[0], postOp[1], postOp[2], postOp[3], byte(vm.EQ), byte(vm.PUSH1), byte(15 + len(validate)), byte(vm.JUMPI),
+	}
+	code = append(code, validate...)
+	code = append(code, byte(vm.JUMPDEST))
+	return append(code, postOpCode...)
+}
+
+// rip7560TestHeader returns the header of the block the test transactions are
+// applied in, with a zero base fee.
+func rip7560TestHeader() *types.Header {
+	return &types.Header{Number: new(big.Int), Difficulty: new(big.Int), GasLimit: 30_000_000, BaseFee: new(big.Int)}
+}
+
+// newRip7560TestState returns an empty in-memory state.
+func newRip7560TestState() *state.StateDB {
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	return statedb
+}
+
+// setRip7560TestSender deploys an account accepting all transactions after
+// running the given code at the address, and funds it with an ether.
+func setRip7560TestSender(statedb *state.StateDB, sender common.Address, prefix ...byte) {
+	statedb.SetCode(sender, rip7560AccountCode(prefix...))
+	statedb.SetBalance(sender, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+}
+
+// setRip7560TestPaymaster deploys the paymaster of rip7560PaymasterCodeWithPostOp
+// at the address, and funds it with an ether.
+func setRip7560TestPaymaster(statedb *state.StateDB, paymaster common.Address, postOpCode ...byte) {
+	statedb.SetCode(paymaster, rip7560PaymasterCodeWithPostOp(postOpCode...))
+	statedb.SetBalance(paymaster, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+}
+
+// newRip7560TestTx returns a transaction of the sender on the dev chain paying
+// one wei per gas, with limits fitting the test accounts and a single byte of
+// execution data. The options are applied on top of these defaults.
+func newRip7560TestTx(sender common.Address, options ...func(*types.Rip7560AccountAbstractionTx)) *types.Rip7560AccountAbstractionTx {
+	aatx := &types.Rip7560AccountAbstractionTx{
+		ChainID:       params.AllDevChainProtocolChanges.ChainID,
+		NonceKey:      new(big.Int),
+		GasTipCap:     big.NewInt(1),
+		GasFeeCap:     big.NewInt(1),
+		Gas:           100_000,
+		Sender:        &sender,
+		ExecutionData: []byte{0x01},
+
+		ValidationGasLimit: 100_000,
+	}
+	for _, option := range options {
+		option(aatx)
+	}
+	return aatx
+}
+
+// withRip7560TestPaymaster sponsors a test transaction by the paymaster, with
+// limits fitting the test paymasters.
+func withRip7560TestPaymaster(paymaster common.Address) func(*types.Rip7560AccountAbstractionTx) {
+	return func(aatx *types.Rip7560AccountAbstractionTx) {
+		aatx.Paymaster, aatx.PaymasterValidationGasLimit, aatx.PostOpGas = &paymaster, 100_000, 100_000
+	}
+}
+
+// signRip7560TestTx signs the signing hash of a transaction with the key, and
+// returns the transaction carrying the signature in its authorization data.
+func signRip7560TestTx(aatx *types.Rip7560AccountAbstractionTx, key *ecdsa.PrivateKey) (*types.Transaction, error) {
+	signer := types.NewRIP7560Signer(aatx.ChainID)
+	sig, err := crypto.Sign(signer.Hash(types.NewTx(aatx)).Bytes(), key)
+	if err != nil {
+		return nil, err
+	}
+	signed := *aatx
+	signed.AuthorizationData = sig
+	return types.NewTx(&signed), nil
+}
+
+// rip7560TestValidationCalldata returns the calldata the account validation
+// frame of a transaction is expected to be called with.
+func rip7560TestValidationCalldata(t *testing.T, tx *types.Transaction) []byte {
+	t.Helper()
+
+	encoded, err := tx.Rip7560TransactionData().AbiEncode()
+	if err != nil {
+		t.Fatalf("failed to encode transaction: %v", err)
+	}
+	signingHash := types.NewRIP7560Signer(tx.ChainId()).Hash(tx)
+	calldata, err := rip7560TestFrameABI.ABI.Pack("validateTransaction", new(big.Int).SetUint64(aa.LatestVersion), signingHash, encoded)
+	if err != nil {
+		t.Fatalf("failed to pack calldata: %v", err)
+	}
+	return calldata
+}
+
+// applyRip7560TestTx applies a transaction on the dev chain to the state, in the
+// block of the header.
+func applyRip7560TestTx(statedb *state.StateDB, header *types.Header, tx *types.Transaction, cfg vm.Config) (*types.Receipt, error) {
+	var (
+		coinbase = common.Address{0xcc}
+		usedGas  uint64
+	)
+	return ApplyRip7560Transaction(params.AllDevChainProtocolChanges, nil, &coinbase, new(GasPool).AddGas(header.GasLimit), statedb, header, common.Hash{}, tx, 0, &usedGas, cfg)
+}
@@ -0,0 +1,471 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// DefaultRip7560BannedAddresses are the system contracts validation frames must
+// not interact with, as their storage is written by the protocol outside of any
+// transaction and may change between validation and inclusion [ERC-7562].
+var DefaultRip7560BannedAddresses = []common.Address{
+	params.BeaconRootsAddress,
+	params.SystemAddress,
+}
+
+// ValidationPolicy holds local limits on the structure of the validation frames
+// of RIP-7560 transactions, enforced on top of the ERC-7562 rules. They aren't
+// consensus rules, but let permissioned deployments restrict what the frames of
+// the transactions accepted by their pool may do. Breaking them is reported as
+// a violation of the POL-001 (call depth), POL-002 (reentrancy), POL-003
+// (untrusted delegate) and POL-004 (banned address) rules.
+type ValidationPolicy struct {
+	MaxCallDepth     uint64                      // Maximum depth of the inner calls of a frame (zero if unlimited)
+	MaxReentrancy    uint64                      // Maximum number of times an address may be on the call stack of a frame (zero if unlimited)
+	TrustedDelegates map[common.Address]struct{} // Only targets allowed for DELEGATECALL and CALLCODE (nil if all are allowed)
+	BannedAddresses  map[common.Address]struct{} // Addresses the frames may not access (nil for DefaultRip7560BannedAddresses)
+}
+
+// SimulateRip7560Validation runs the validation phase of a RIP-7560 transaction
+// in the context of the given header and reports its outcome. The state is
+// modified by the simulation, callers should pass a copy.
+func SimulateRip7560Validation(config *params.ChainConfig, bc ChainContext, header *types.Header, statedb *state.StateDB, tx *types.Transaction) *types.ValidationReport {
+	return SimulateRip7560ValidationWithTimeout(config, bc, header, statedb, tx, 0)
+}
+
+// SimulateRip7560ValidationWithTimeout is like SimulateRip7560Validation, but
+// aborts the validation frames and fails the transaction with
+// ErrValidationTimeout if they run longer than the given timeout. A zero
+// timeout disables the limit.
+func SimulateRip7560ValidationWithTimeout(config *params.ChainConfig, bc ChainContext, header *types.Header, statedb *state.StateDB, tx *types.Transaction, timeout time.Duration) *types.ValidationReport {
+	return SimulateRip7560ValidationWithPolicy(config, bc, header, statedb, tx, timeout, nil)
+}
+
+// SimulateRip7560ValidationWithPolicy is like SimulateRip7560ValidationWithTimeout,
+// additionally reporting the breaches of the given local policy, if any.
+func SimulateRip7560ValidationWithPolicy(config *params.ChainConfig, bc ChainContext, header *types.Header, statedb *state.StateDB, tx *types.Transaction, timeout time.Duration, policy *ValidationPolicy) *types.ValidationReport {
+	c := newValidationCollector(config, tx)
+	c.policy = policy
+	if policy != nil && policy.BannedAddresses != nil {
+		c.bannedAddrs = policy.BannedAddresses
+	}
+	if tx.Type() != types.Rip7560Type {
+		return c.report(nil, errors.New("not a RIP-7560 transaction"))
+	}
+	// The protocol reads the accounts of the entities before the frames are run
+	for _, addr := range []*common.Address{c.aatx.Sender, c.aatx.Paymaster, c.aatx.Deployer} {
+		if addr != nil {
+			c.witnessAccount(statedb, *addr)
+		}
+	}
+	var (
+		gp          = new(GasPool).AddGas(header.GasLimit)
+		gasPrice, _ = rip7560GasPrices(c.aatx, header.BaseFee, false)
+		evm         = vm.NewEVM(NewEVMBlockContext(header, bc, &header.Coinbase), vm.TxContext{GasPrice: gasPrice}, statedb, config, vm.Config{Tracer: c.hooks()})
+	)
+	if timeout > 0 {
+		timer := time.AfterFunc(timeout, evm.Cancel)
+		defer timer.Stop()
+	}
+	vpr, err := ApplyRip7560ValidationPhasesWithEVM(evm, gp, statedb, header, tx)
+	if evm.Cancelled() {
+		vpr, err = nil, fmt.Errorf("%w: exceeded %v", ErrValidationTimeout, timeout)
+	}
+	return c.report(vpr, err)
+}
+
+// validationCollector gathers the details of the validation frames of a
+// transaction for its report.
+type validationCollector struct {
+	aatx   *types.Rip7560AccountAbstractionTx
+	txHash common.Hash
+	banned map[string]struct{}
+	policy *ValidationPolicy // Local limits on the frames, if any
+
+	bannedAddrs map[common.Address]struct{} // Addresses the frames may not access
+
+	env         *tracing.VMContext
+	precompiles map[common.Address]struct{}
+	storage     *storageAccessTracker
+
+	frames         []*types.ValidationFrame
+	reads          map[common.Address]map[common.Hash]struct{}
+	writes         map[common.Address]map[common.Hash]struct{}
+	witness        map[common.Address]*types.WitnessAccount // State read by the frames, recorded on first access
+	violations     []string
+	violationRules []string
+	rules          []string
+	lastOp         string
+	create2        int              // Number of CREATE2 executed by the validation frames
+	callStack      []common.Address // Addresses entered by the current frame, outermost first
+}
+
+func newValidationCollector(config *params.ChainConfig, tx *types.Transaction) *validationCollector {
+	c := &validationCollector{
+		txHash:      tx.Hash(),
+		banned:      config.Rip7560BannedOpcodes(),
+		bannedAddrs: make(map[common.Address]struct{}, len(DefaultRip7560BannedAddresses)),
+		reads:       make(map[common.Address]map[common.Hash]struct{}),
+		writes:      make(map[common.Address]map[common.Hash]struct{}),
+		witness:     make(map[common.Address]*types.WitnessAccount),
+	}
+	for _, addr := range DefaultRip7560BannedAddresses {
+		c.bannedAddrs[addr] = struct{}{}
+	}
+	if tx.Type() == types.Rip7560Type {
+		c.aatx = tx.Rip7560TransactionData()
+	}
+	return c
+}
+
+func (c *validationCollector) hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnTxStart: c.onTxStart,
+		OnEnter:   c.onEnter,
+		OnExit:    c.onExit,
+		OnOpcode:  c.onOpcode,
+	}
+}
+
+func (c *validationCollector) onTxStart(env *tracing.VMContext, tx *types.Transaction, from common.Address) {
+	c.env = env
+	c.storage = newStorageAccessTracker(c.aatx)
+	rules := env.ChainConfig.Rules(env.BlockNumber, env.Random != nil, env.Time)
+	c.precompiles = make(map[common.Address]struct{})
+	for _, addr := range vm.ActivePrecompiles(rules) {
+		c.precompiles[addr] = struct{}{}
+	}
+}
+
+// frameName names a top level validation call by its caller and target.
+func (c *validationCollector) frameName(from, to common.Address) string {
+	switch {
+	case to == AA_NONCE_MANAGER:
+		return FrameNonceManager
+	case from == AA_SENDER_CREATOR:
+		return FrameDeployer
+	case c.aatx.Paymaster != nil && to == *c.aatx.Paymaster:
+		return FramePaymaster
+	case to == *c.aatx.Sender:
+		return FrameAccount
+	}
+	return "unknown"
+}
+
+func (c *validationCollector) onEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	if c.env != nil {
+		c.witnessAccount(c.env.StateDB, to)
+	}
+	if depth != 0 {
+		// Value may only be transferred to the EntryPoint [OP-061]
+		if vm.OpCode(typ) == vm.CALL && value != nil && value.Sign() > 0 && to != AA_ENTRY_POINT {
+			c.violation("OP-061", "%s frame calls %v with value", c.frames[len(c.frames)-1].Name, to)
+		}
+		if c.policy != nil {
+			c.checkPolicy(depth, vm.OpCode(typ), to)
+		}
+		c.checkBannedAccess(vm.OpCode(typ), to)
+		c.callStack = append(c.callStack, to)
+		return
+	}
+	c.frames = append(c.frames, &types.ValidationFrame{
+		Name:     c.frameName(from, to),
+		Target:   to,
+		GasLimit: hexutil.Uint64(gas),
+	})
+	c.lastOp = ""
+	c.callStack = append(c.callStack[:0], to)
+}
+
+// checkPolicy records the breaches of the local policy by an inner call of a
+// validation frame.
+func (c *validationCollector) checkPolicy(depth int, typ vm.OpCode, to common.Address) {
+	frame := c.frames[len(c.frames)-1].Name
+	if limit := c.policy.MaxCallDepth; limit > 0 && uint64(depth) > limit {
+		c.violation("POL-001", "%s frame exceeds call depth %d", frame, limit)
+	}
+	if limit := c.policy.MaxReentrancy; limit > 0 {
+		entered := uint64(1)
+		for _, addr := range c.callStack {
+			if addr == to {
+				entered++
+			}
+		}
+		if entered > limit {
+			c.violation("POL-002", "%s frame enters %v more than %d times", frame, to, limit)
+		}
+	}
+	if typ == vm.DELEGATECALL || typ == vm.CALLCODE {
+		if _, ok := c.policy.TrustedDelegates[to]; c.policy.TrustedDelegates != nil && !ok {
+			c.violation("POL-003", "%s frame uses %s on untrusted %v", frame, typ, to)
+		}
+	}
+}
+
+func (c *validationCollector) onExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	if len(c.frames) == 0 {
+		return
+	}
+	if depth != 0 {
+		// Inner calls must not run out of gas [OP-020]
+		if errors.Is(err, vm.ErrOutOfGas) {
+			c.violation("OP-020", "%s frame runs out of gas in an inner call", c.frames[len(c.frames)-1].Name)
+		}
+		if len(c.callStack) > 0 {
+			c.callStack = c.callStack[:len(c.callStack)-1]
+		}
+		return
+	}
+	frame := c.frames[len(c.frames)-1]
+	frame.GasUsed = hexutil.Uint64(gasUsed)
+	frame.Reverted = reverted
+}
+
+func (c *validationCollector) onOpcode(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+	if len(c.frames) == 0 {
+		return
+	}
+	opcode := vm.OpCode(op)
+
+	// GAS is only allowed right before one of the CALL opcodes [OP-012]
+	if c.lastOp == "GAS" && opcode != vm.CALL && opcode != vm.CALLCODE && opcode != vm.DELEGATECALL && opcode != vm.STATICCALL {
+		c.bannedOpcode("OP-012", "GAS")
+	}
+	if name := opcode.String(); name != "GAS" {
+		c.bannedOpcode("OP-011", name)
+	}
+	c.lastOp = opcode.String()
+
+	switch opcode {
+	case vm.CREATE2:
+		// CREATE2 may only deploy the sender, once, in the deployer frame [OP-031]
+		if frame := c.frames[len(c.frames)-1].Name; frame != FrameDeployer {
+			c.violation("OP-031", "%s frame uses CREATE2 outside of the deployer frame", frame)
+		} else if c.create2 > 0 {
+			c.violation("OP-031", "%s frame uses CREATE2 more than once", frame)
+		}
+		c.create2++
+
+	case vm.EXTCODESIZE, vm.EXTCODEHASH, vm.EXTCODECOPY:
+		if stack := scope.StackData(); len(stack) > 0 {
+			c.checkBannedAccess(opcode, common.Address(stack[len(stack)-1].Bytes20()))
+			c.checkCodeAccess(opcode, common.Address(stack[len(stack)-1].Bytes20()))
+			c.witnessAccount(c.env.StateDB, common.Address(stack[len(stack)-1].Bytes20()))
+		}
+
+	case vm.BALANCE, vm.SELFDESTRUCT:
+		if stack := scope.StackData(); len(stack) > 0 {
+			c.checkBannedAccess(opcode, common.Address(stack[len(stack)-1].Bytes20()))
+			c.witnessAccount(c.env.StateDB, common.Address(stack[len(stack)-1].Bytes20()))
+		}
+
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		if stack := scope.StackData(); len(stack) > 1 {
+			c.checkCodeAccess(opcode, common.Address(stack[len(stack)-2].Bytes20()))
+			c.witnessAccount(c.env.StateDB, common.Address(stack[len(stack)-2].Bytes20()))
+		}
+
+	case vm.KECCAK256:
+		stack := scope.StackData()
+		if c.storage == nil || len(stack) < 2 {
+			return
+		}
+		offset, size := stack[len(stack)-1], stack[len(stack)-2]
+		// The memory is expanded by the opcode, inputs out of it are zeroes
+		mem := uint64(len(scope.MemoryData()))
+		if offset.IsUint64() && size.IsUint64() && size.Uint64() <= mem && offset.Uint64() <= mem-size.Uint64() {
+			c.storage.onKeccak(scope.MemoryData()[offset.Uint64() : offset.Uint64()+size.Uint64()])
+		}
+
+	case vm.SLOAD, vm.SSTORE:
+		stack := scope.StackData()
+		if len(stack) == 0 {
+			return
+		}
+		set := c.reads
+		if opcode == vm.SSTORE {
+			set = c.writes
+		}
+		addr, slot := scope.Address(), common.Hash(stack[len(stack)-1].Bytes32())
+		if set[addr] == nil {
+			set[addr] = make(map[common.Hash]struct{})
+		}
+		set[addr][slot] = struct{}{}
+		c.witnessSlot(addr, slot)
+
+		// Only the storage of the sender, of the entities and the slots
+		// associated with the sender may be accessed [STO-032, STO-033]
+		if c.storage != nil && !c.storage.allowed(addr, slot) {
+			if opcode == vm.SSTORE {
+				c.violation("STO-032", "%s frame writes unassociated slot %v of %v", c.frames[len(c.frames)-1].Name, slot, addr)
+			} else {
+				c.violation("STO-033", "%s frame reads unassociated slot %v of %v", c.frames[len(c.frames)-1].Name, slot, addr)
+			}
+		}
+	}
+}
+
+// checkBannedAccess records the access of an opcode to one of the addresses the
+// validation frames may not interact with [POL-004].
+func (c *validationCollector) checkBannedAccess(opcode vm.OpCode, addr common.Address) {
+	if _, ok := c.bannedAddrs[addr]; ok {
+		c.violation("POL-004", "%s frame uses %s on banned %v", c.frames[len(c.frames)-1].Name, opcode, addr)
+	}
+}
+
+// checkCodeAccess records the access of an opcode to an address without code,
+// except for the sender, which may not be deployed yet, the precompiles and the
+// AA system contracts [OP-041].
+func (c *validationCollector) checkCodeAccess(opcode vm.OpCode, addr common.Address) {
+	if c.env == nil || addr == *c.aatx.Sender {
+		return
+	}
+	switch addr {
+	case AA_ENTRY_POINT, AA_SENDER_CREATOR, AA_NONCE_MANAGER, AA_DEPOSIT_MANAGER:
+		return
+	}
+	if _, ok := c.precompiles[addr]; ok {
+		return
+	}
+	if len(c.env.StateDB.GetCode(addr)) == 0 {
+		c.violation("OP-041", "%s frame uses %s on %v without code", c.frames[len(c.frames)-1].Name, opcode, addr)
+	}
+}
+
+// witnessAccount records the state of an account on its first access, returning
+// its witness.
+func (c *validationCollector) witnessAccount(db tracing.StateDB, addr common.Address) *types.WitnessAccount {
+	if account, ok := c.witness[addr]; ok {
+		return account
+	}
+	account := &types.WitnessAccount{
+		Exists:  db.Exist(addr),
+		Balance: (*hexutil.Big)(db.GetBalance(addr).ToBig()),
+		Nonce:   hexutil.Uint64(db.GetNonce(addr)),
+		Storage: make(map[common.Hash]common.Hash),
+	}
+	if account.Exists {
+		account.CodeHash = crypto.Keccak256Hash(db.GetCode(addr))
+	}
+	c.witness[addr] = account
+	return account
+}
+
+// witnessSlot records the value of a storage slot on its first access, before
+// it is written by the validation.
+func (c *validationCollector) witnessSlot(addr common.Address, slot common.Hash) {
+	account := c.witnessAccount(c.env.StateDB, addr)
+	if _, ok := account.Storage[slot]; !ok {
+		account.Storage[slot] = c.env.StateDB.GetState(addr, slot)
+	}
+}
+
+// bannedOpcode records the use of an opcode if it's banned on this chain,
+// breaking the given rule.
+func (c *validationCollector) bannedOpcode(rule string, opcode string) {
+	if _, ok := c.banned[opcode]; !ok {
+		return
+	}
+	c.violation(rule, "%s frame uses banned opcode %s", c.frames[len(c.frames)-1].Name, opcode)
+}
+
+// violation records a breach of the validation rule with the given ERC-7562
+// identifier, once.
+func (c *validationCollector) violation(rule string, format string, args ...any) {
+	violation := fmt.Sprintf(format, args...)
+	if !slices.Contains(c.violations, violation) {
+		c.violations = append(c.violations, violation)
+		c.violationRules = append(c.violationRules, rule)
+	}
+	if !slices.Contains(c.rules, rule) {
+		c.rules = append(c.rules, rule)
+	}
+}
+
+// report assembles the validation report from the collected details and the
+// result of the validation phase.
+func (c *validationCollector) report(vpr *ValidationPhaseResult, err error) *types.ValidationReport {
+	report := &types.ValidationReport{
+		TxHash:         c.txHash,
+		Frames:         c.frames,
+		Reads:          sortedSlots(c.reads),
+		Writes:         sortedSlots(c.writes),
+		Witness:        c.witness,
+		Violations:     c.violations,
+		ViolationRules: c.violationRules,
+		Rules:          c.rules,
+	}
+	if report.Frames == nil {
+		report.Frames = []*types.ValidationFrame{}
+	}
+	if report.Violations == nil {
+		report.Violations = []string{}
+	}
+	if report.Rules == nil {
+		report.Rules = []string{}
+	}
+	if err != nil {
+		report.SetErr(err)
+		return report
+	}
+	if gas, err := vpr.validationPhaseUsedGas(); err == nil {
+		report.GasUsed = hexutil.Uint64(gas)
+		if c.aatx.Deployer != nil {
+			deployment := vpr.DeploymentUsedGas + c.aatx.DeployerDataGasCost()
+			report.Deployment = &types.DeploymentEstimate{
+				DeploymentGas: hexutil.Uint64(deployment),
+				NextTxGas:     hexutil.Uint64(gas - deployment),
+			}
+		}
+	}
+	// The transaction is valid in the intersection of the ranges returned by
+	// the account and the paymaster, zero meaning unbounded
+	report.ValidAfter = hexutil.Uint64(max(vpr.SenderValidAfter, vpr.PmValidAfter))
+	for _, until := range []uint64{vpr.SenderValidUntil, vpr.PmValidUntil} {
+		if until != 0 && (report.ValidUntil == 0 || until < uint64(report.ValidUntil)) {
+			report.ValidUntil = hexutil.Uint64(until)
+		}
+	}
+	return report
+}
+
+func sortedSlots(set map[common.Address]map[common.Hash]struct{}) map[common.Address][]common.Hash {
+	slots := make(map[common.Address][]common.Hash, len(set))
+	for addr, keys := range set {
+		list := make([]common.Hash, 0, len(keys))
+		for key := range keys {
+			list = append(list, key)
+		}
+		slices.SortFunc(list, func(a, b common.Hash) int { return a.Cmp(b) })
+		slots[addr] = list
+	}
+	return slots
+}
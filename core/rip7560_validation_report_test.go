@@ -0,0 +1,446 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// Tests that the validation report of a transaction deploying its account
+// splits the gas between the deployment and the recurring validation.
+func TestValidationReportDeployment(t *testing.T) {
+	var (
+		deployer = common.Address{0xdd}
+		header   = &types.Header{Number: new(big.Int), Difficulty: new(big.Int), GasLimit: 30_000_000, BaseFee: new(big.Int)}
+	)
+	// The account is deployed with CREATE2 by the deployer copying its init
+	// code into memory
+	account := rip7560AccountCode()
+	initcode := append([]byte{byte(vm.PUSH1), byte(len(account)), byte(vm.DUP1), byte(vm.PUSH1), 11, byte(vm.PUSH1), 0, byte(vm.CODECOPY),
+		byte(vm.PUSH1), 0, byte(vm.RETURN)}, account...)
+	deployerCode := append([]byte{byte(vm.PUSH1), byte(len(initcode)), byte(vm.PUSH1), 18, byte(vm.PUSH1), 0, byte(vm.CODECOPY),
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), byte(len(initcode)), byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.CREATE2), byte(vm.POP), byte(vm.STOP)}, initcode...)
+	sender := crypto.CreateAddress2(deployer, [32]byte{}, crypto.Keccak256(initcode))
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetBalance(sender, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+	statedb.SetCode(deployer, deployerCode)
+
+	tx := types.NewTx(&types.Rip7560AccountAbstractionTx{
+		ChainID:      params.AllDevChainProtocolChanges.ChainID,
+		NonceKey:     new(big.Int),
+		GasTipCap:    big.NewInt(1),
+		GasFeeCap:    big.NewInt(1),
+		Gas:          100_000,
+		Sender:       &sender,
+		Deployer:     &deployer,
+		DeployerData: []byte{0x01, 0x00},
+
+		ValidationGasLimit: 100_000,
+	})
+	report := SimulateRip7560Validation(params.AllDevChainProtocolChanges, nil, header, statedb, tx)
+	if err := report.Err(); err != nil {
+		t.Fatalf("validation failed: %v", err)
+	}
+	if report.Deployment == nil {
+		t.Fatal("missing deployment estimate")
+	}
+	var deployerGas uint64
+	for _, frame := range report.Frames {
+		if frame.Name == FrameDeployer {
+			deployerGas = uint64(frame.GasUsed)
+		}
+	}
+	want := deployerGas + params.TxDataNonZeroGasEIP2028 + params.TxDataZeroGas
+	if have := uint64(report.Deployment.DeploymentGas); have != want {
+		t.Fatalf("deployment gas mismatch: have %d, want %d", have, want)
+	}
+	if have := report.Deployment.DeploymentGas + report.Deployment.NextTxGas; have != report.GasUsed {
+		t.Fatalf("gas split mismatch: have %d, want %d", have, report.GasUsed)
+	}
+}
+
+// Tests that the validation report flags the breaches of the ERC-7562 opcode
+// rules which are not about banned opcodes.
+func TestValidationReportRules(t *testing.T) {
+	var (
+		sender = common.Address{0xaa}
+		callee = common.Address{0xcc}
+		looper = common.Address{0xdd}
+		empty  = common.Address{0xee}
+		header = &types.Header{Number: new(big.Int), Difficulty: new(big.Int), GasLimit: 30_000_000, BaseFee: new(big.Int)}
+	)
+	// call returns the code calling a contract with the given gas and value
+	call := func(to common.Address, gas, value byte) []byte {
+		code := []byte{byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), value, byte(vm.PUSH20)}
+		code = append(code, to.Bytes()...)
+		return append(code, byte(vm.PUSH1), gas, byte(vm.CALL), byte(vm.POP))
+	}
+	// extcodesize returns the code reading the code size of an account
+	extcodesize := func(addr common.Address) []byte {
+		return append(append([]byte{byte(vm.PUSH20)}, addr.Bytes()...), byte(vm.EXTCODESIZE), byte(vm.POP))
+	}
+	tests := []struct {
+		prefix    []byte
+		violation string
+		rule      string
+	}{
+		{nil, "", ""},
+		{call(callee, 0xff, 0), "", ""},
+		{extcodesize(callee), "", ""},
+		{extcodesize(sender), "", ""},
+		{extcodesize(common.BytesToAddress([]byte{1})), "", ""},
+		{extcodesize(empty), fmt.Sprintf("account frame uses EXTCODESIZE on %v without code", empty), "OP-041"},
+		{call(empty, 0xff, 0), fmt.Sprintf("account frame uses CALL on %v without code", empty), "OP-041"},
+		{call(callee, 0xff, 1), fmt.Sprintf("account frame calls %v with value", callee), "OP-061"},
+		{call(looper, 0xff, 0), "account frame runs out of gas in an inner call", "OP-020"},
+		{[]byte{byte(vm.PUSH1), 0, byte(vm.DUP1), byte(vm.DUP1), byte(vm.DUP1), byte(vm.CREATE2), byte(vm.POP)}, "account frame uses CREATE2 outside of the deployer frame", "OP-031"},
+	}
+	for i, tt := range tests {
+		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.SetBalance(sender, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+		statedb.SetCode(sender, rip7560AccountCode(tt.prefix...))
+		statedb.SetCode(callee, []byte{byte(vm.STOP)})
+		statedb.SetCode(looper, []byte{byte(vm.JUMPDEST), byte(vm.PUSH1), 0, byte(vm.JUMP)})
+
+		tx := types.NewTx(&types.Rip7560AccountAbstractionTx{
+			ChainID:   params.AllDevChainProtocolChanges.ChainID,
+			NonceKey:  new(big.Int),
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(1),
+			Gas:       100_000,
+			Sender:    &sender,
+
+			ValidationGasLimit: 100_000,
+		})
+		report := SimulateRip7560Validation(params.AllDevChainProtocolChanges, nil, header, statedb, tx)
+		if report.Error != "" {
+			t.Fatalf("test %d: validation failed: %s", i, report.Error)
+		}
+		if tt.violation == "" && len(report.Violations) != 0 {
+			t.Errorf("test %d: unexpected violations: %v", i, report.Violations)
+		}
+		if tt.violation != "" && !slices.Contains(report.Violations, tt.violation) {
+			t.Errorf("test %d: missing violation %q: have %v", i, tt.violation, report.Violations)
+		}
+		if tt.rule != "" && !slices.Contains(report.Rules, tt.rule) {
+			t.Errorf("test %d: missing rule %s: have %v", i, tt.rule, report.Rules)
+		}
+		// Rejections name the broken rule
+		if tt.rule != "" && !strings.Contains(report.Err().Error(), fmt.Sprintf("[%s] %s", tt.rule, tt.violation)) {
+			t.Errorf("test %d: error without the broken rule: %v", i, report.Err())
+		}
+	}
+}
+
+// Tests that the validation report flags the accesses of the validation frames
+// to storage which is neither of the sender, of an entity nor associated with
+// the sender.
+func TestValidationReportStorageRules(t *testing.T) {
+	var (
+		sender    = common.Address{0xaa}
+		paymaster = common.Address{0xbb}
+		contract  = common.Address{0xcc}
+		header    = &types.Header{Number: new(big.Int), Difficulty: new(big.Int), GasLimit: 30_000_000, BaseFee: new(big.Int)}
+	)
+	// call returns the code calling the contract with all the gas left
+	call := func() []byte {
+		code := []byte{byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH20)}
+		code = append(code, contract.Bytes()...)
+		return append(code, byte(vm.GAS), byte(vm.CALL), byte(vm.POP))
+	}
+	// mapping returns the code writing the slot at the given offset from the
+	// entry of the caller in the mapping at slot 0
+	mapping := func(offset byte) []byte {
+		return []byte{byte(vm.CALLER), byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 0, byte(vm.PUSH1), 32, byte(vm.MSTORE),
+			byte(vm.PUSH1), 1, byte(vm.PUSH1), offset, byte(vm.PUSH1), 64, byte(vm.PUSH1), 0, byte(vm.KECCAK256), byte(vm.ADD), byte(vm.SSTORE)}
+	}
+	tests := []struct {
+		account  []byte // Code run by the account before accepting
+		contract []byte // Code of the contract called by the account
+		rule     string
+	}{
+		{[]byte{byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE)}, nil, ""},
+		{call(), []byte{byte(vm.CALLER), byte(vm.SLOAD), byte(vm.POP)}, ""},
+		{call(), mapping(0), ""},
+		{call(), mapping(maxAssociatedSlotOffset), ""},
+		{call(), mapping(maxAssociatedSlotOffset + 1), "STO-032"},
+		{call(), []byte{byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE)}, "STO-032"},
+		{call(), []byte{byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.POP)}, "STO-033"},
+	}
+	for i, tt := range tests {
+		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.SetBalance(sender, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+		statedb.SetCode(sender, rip7560AccountCode(tt.account...))
+		statedb.SetCode(contract, append(tt.contract, byte(vm.STOP)))
+
+		tx := types.NewTx(&types.Rip7560AccountAbstractionTx{
+			ChainID:   params.AllDevChainProtocolChanges.ChainID,
+			NonceKey:  new(big.Int),
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(1),
+			Gas:       100_000,
+			Sender:    &sender,
+
+			ValidationGasLimit: 200_000,
+		})
+		report := SimulateRip7560Validation(params.AllDevChainProtocolChanges, nil, header, statedb, tx)
+		if report.Error != "" {
+			t.Fatalf("test %d: validation failed: %s", i, report.Error)
+		}
+		if tt.rule == "" && len(report.Violations) != 0 {
+			t.Errorf("test %d: unexpected violations: %v", i, report.Violations)
+		}
+		if tt.rule != "" && !slices.Contains(report.Rules, tt.rule) {
+			t.Errorf("test %d: missing rule %s: have %v", i, tt.rule, report.Rules)
+		}
+	}
+	// The entities may access their own storage
+	tracker := newStorageAccessTracker(&types.Rip7560AccountAbstractionTx{Sender: &sender, Paymaster: &paymaster})
+	if !tracker.allowed(paymaster, common.Hash{}) || tracker.allowed(contract, common.Hash{}) {
+		t.Error("entity storage access mismatch")
+	}
+}
+
+// Tests that the breaches of the local validation policy are reported as
+// violations, and only if the policy is set.
+func TestValidationReportPolicy(t *testing.T) {
+	var (
+		sender   = common.Address{0xaa}
+		contract = common.Address{0xcc}
+		inner    = common.Address{0xdd}
+		header   = &types.Header{Number: new(big.Int), Difficulty: new(big.Int), GasLimit: 30_000_000, BaseFee: new(big.Int)}
+	)
+	// call returns the code calling the target with all the gas left
+	call := func(op vm.OpCode, target common.Address) []byte {
+		code := []byte{byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0}
+		if op == vm.CALL {
+			code = append(code, byte(vm.PUSH1), 0)
+		}
+		code = append(append(code, byte(vm.PUSH20)), target.Bytes()...)
+		return append(code, byte(vm.GAS), byte(op), byte(vm.POP))
+	}
+	// reenter is the code of the contract calling itself once
+	reenter := append([]byte{byte(vm.CALLER), byte(vm.PUSH20)}, contract.Bytes()...)
+	reenter = append(reenter, byte(vm.EQ), byte(vm.PUSH1), byte(len(reenter)+4+len(call(vm.CALL, contract))), byte(vm.JUMPI))
+	reenter = append(append(reenter, call(vm.CALL, contract)...), byte(vm.JUMPDEST))
+
+	tests := []struct {
+		policy   *ValidationPolicy
+		account  []byte // Code run by the account before accepting
+		contract []byte // Code of the contract called by the account
+		rule     string
+	}{
+		{nil, call(vm.CALL, contract), call(vm.CALL, inner), ""},
+		{&ValidationPolicy{MaxCallDepth: 2}, call(vm.CALL, contract), call(vm.CALL, inner), ""},
+		{&ValidationPolicy{MaxCallDepth: 1}, call(vm.CALL, contract), call(vm.CALL, inner), "POL-001"},
+		{nil, call(vm.CALL, contract), reenter, ""},
+		{&ValidationPolicy{MaxReentrancy: 2}, call(vm.CALL, contract), reenter, ""},
+		{&ValidationPolicy{MaxReentrancy: 1}, call(vm.CALL, contract), reenter, "POL-002"},
+		{&ValidationPolicy{}, call(vm.DELEGATECALL, contract), nil, ""},
+		{&ValidationPolicy{TrustedDelegates: map[common.Address]struct{}{contract: {}}}, call(vm.DELEGATECALL, contract), nil, ""},
+		{&ValidationPolicy{TrustedDelegates: map[common.Address]struct{}{}}, call(vm.DELEGATECALL, contract), nil, "POL-003"},
+	}
+	for i, tt := range tests {
+		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.SetBalance(sender, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+		statedb.SetCode(sender, rip7560AccountCode(tt.account...))
+		statedb.SetCode(contract, append(tt.contract, byte(vm.STOP)))
+		statedb.SetCode(inner, []byte{byte(vm.STOP)})
+
+		tx := types.NewTx(&types.Rip7560AccountAbstractionTx{
+			ChainID:   params.AllDevChainProtocolChanges.ChainID,
+			NonceKey:  new(big.Int),
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(1),
+			Gas:       100_000,
+			Sender:    &sender,
+
+			ValidationGasLimit: 200_000,
+		})
+		report := SimulateRip7560ValidationWithPolicy(params.AllDevChainProtocolChanges, nil, header, statedb, tx, 0, tt.policy)
+		if report.Error != "" {
+			t.Fatalf("test %d: validation failed: %s", i, report.Error)
+		}
+		if tt.rule == "" && len(report.Violations) != 0 {
+			t.Errorf("test %d: unexpected violations: %v", i, report.Violations)
+		}
+		if tt.rule != "" && !slices.Contains(report.Rules, tt.rule) {
+			t.Errorf("test %d: missing rule %s: have %v", i, tt.rule, report.Rules)
+		}
+	}
+}
+
+// Tests that validation frames running longer than the timeout are aborted and
+// fail the transaction.
+func TestValidationReportTimeout(t *testing.T) {
+	var (
+		sender = common.Address{0xaa}
+		header = &types.Header{Number: new(big.Int), Difficulty: new(big.Int), GasLimit: 1_000_000_000, BaseFee: new(big.Int)}
+	)
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetBalance(sender, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+	statedb.SetCode(sender, []byte{byte(vm.JUMPDEST), byte(vm.PUSH1), 0, byte(vm.JUMP)})
+
+	tx := types.NewTx(&types.Rip7560AccountAbstractionTx{
+		ChainID:   params.AllDevChainProtocolChanges.ChainID,
+		NonceKey:  new(big.Int),
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(1),
+		Gas:       100_000,
+		Sender:    &sender,
+
+		ValidationGasLimit: 900_000_000,
+	})
+	start := time.Now()
+	report := SimulateRip7560ValidationWithTimeout(params.AllDevChainProtocolChanges, nil, header, statedb, tx, 10*time.Millisecond)
+	if err := report.Err(); !errors.Is(err, ErrValidationTimeout) {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrValidationTimeout)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("validation not aborted, took %v", elapsed)
+	}
+}
+
+// Tests that the accesses of the validation frames to the banned system
+// contracts are reported as violations failing the validation, and that the
+// banned set can be replaced by the policy.
+func TestValidationReportBannedAddress(t *testing.T) {
+	var (
+		sender   = common.Address{0xaa}
+		contract = common.Address{0xcc}
+		header   = &types.Header{Number: new(big.Int), Difficulty: new(big.Int), GasLimit: 30_000_000, BaseFee: new(big.Int)}
+	)
+	// call returns the account code calling the target with all the gas left
+	call := func(target common.Address) []byte {
+		code := []byte{byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH20)}
+		code = append(code, target.Bytes()...)
+		return append(code, byte(vm.GAS), byte(vm.STATICCALL), byte(vm.POP))
+	}
+	tests := []struct {
+		policy *ValidationPolicy
+		target common.Address
+		banned bool
+	}{
+		{nil, params.BeaconRootsAddress, true},
+		{nil, contract, false},
+		{&ValidationPolicy{}, params.BeaconRootsAddress, true},
+		{&ValidationPolicy{BannedAddresses: map[common.Address]struct{}{}}, params.BeaconRootsAddress, false},
+		{&ValidationPolicy{BannedAddresses: map[common.Address]struct{}{contract: {}}}, contract, true},
+	}
+	for i, tt := range tests {
+		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.SetBalance(sender, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+		statedb.SetCode(sender, rip7560AccountCode(call(tt.target)...))
+		statedb.SetCode(contract, []byte{byte(vm.STOP)})
+		statedb.SetCode(params.BeaconRootsAddress, params.BeaconRootsCode)
+
+		tx := types.NewTx(&types.Rip7560AccountAbstractionTx{
+			ChainID:   params.AllDevChainProtocolChanges.ChainID,
+			NonceKey:  new(big.Int),
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(1),
+			Gas:       100_000,
+			Sender:    &sender,
+
+			ValidationGasLimit: 200_000,
+		})
+		report := SimulateRip7560ValidationWithPolicy(params.AllDevChainProtocolChanges, nil, header, statedb, tx, 0, tt.policy)
+		if report.Error != "" {
+			t.Fatalf("test %d: validation failed: %s", i, report.Error)
+		}
+		err := report.Err()
+		if tt.banned && (!errors.Is(err, ErrValidationRulesViolation) || !slices.Contains(report.Rules, "POL-004")) {
+			t.Errorf("test %d: banned access not rejected: %v", i, err)
+		}
+		if !tt.banned && err != nil {
+			t.Errorf("test %d: unexpected error: %v", i, err)
+		}
+	}
+}
+
+// Tests that the witness of the validation report holds the state read by the
+// validation as it was before it, including the slots overwritten and the
+// accounts only read by the protocol.
+func TestValidationReportWitness(t *testing.T) {
+	var (
+		sender  = common.Address{0xaa}
+		queried = common.Address{0xcc}
+		header  = &types.Header{Number: new(big.Int), Difficulty: new(big.Int), GasLimit: 30_000_000, BaseFee: new(big.Int)}
+	)
+	// The account increments its first slot and reads the code hash of another
+	// account
+	prefix := []byte{
+		byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.PUSH1), 1, byte(vm.ADD), byte(vm.PUSH1), 0, byte(vm.SSTORE),
+		byte(vm.PUSH20),
+	}
+	prefix = append(append(prefix, queried.Bytes()...), byte(vm.EXTCODEHASH), byte(vm.POP))
+	code := rip7560AccountCode(prefix...)
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetCode(sender, code)
+	statedb.SetNonce(sender, 3)
+	statedb.SetState(sender, common.Hash{}, common.Hash{0x05})
+	statedb.SetBalance(sender, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+	statedb.SetCode(queried, []byte{byte(vm.STOP)})
+	statedb.SetBalance(queried, uint256.NewInt(7), tracing.BalanceChangeUnspecified)
+
+	tx := types.NewTx(&types.Rip7560AccountAbstractionTx{
+		ChainID:   params.AllDevChainProtocolChanges.ChainID,
+		NonceKey:  new(big.Int),
+		Nonce:     3,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(1),
+		Gas:       100_000,
+		Sender:    &sender,
+
+		ValidationGasLimit: 100_000,
+	})
+	report := SimulateRip7560Validation(params.AllDevChainProtocolChanges, nil, header, statedb, tx)
+	if err := report.Err(); err != nil {
+		t.Fatalf("validation failed: %v", err)
+	}
+	account := report.Witness[sender]
+	if account == nil {
+		t.Fatal("missing sender witness")
+	}
+	if !account.Exists || account.Nonce != 3 || account.Balance.ToInt().Cmp(big.NewInt(params.Ether)) != 0 || account.CodeHash != crypto.Keccak256Hash(code) {
+		t.Errorf("sender witness mismatch: %+v", account)
+	}
+	if have := account.Storage[common.Hash{}]; have != (common.Hash{0x05}) {
+		t.Errorf("slot witness mismatch: have %x, want %x", have, common.Hash{0x05})
+	}
+	if account := report.Witness[queried]; account == nil || account.Balance.ToInt().Int64() != 7 || account.CodeHash != crypto.Keccak256Hash([]byte{byte(vm.STOP)}) {
+		t.Errorf("queried account witness mismatch: %+v", account)
+	}
+}
@@ -1,21 +1,53 @@
 package core
 
 import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/core/types"
-	"math/big"
-	"slices"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
 )
 
-// TODO: accept address as configuration parameter
-var AA_NONCE_MANAGER = common.HexToAddress("0x63f63e798f5F6A934Acf0a3FD1C01f3Fac851fF0")
+// AA_NONCE_MANAGER is the address of the RIP-7712 NonceManager system contract.
+var AA_NONCE_MANAGER = params.Rip7712NonceManagerAddress
 
-func prepareNonceManagerMessage(tx *types.Rip7560AccountAbstractionTx) []byte {
+// rip7712NonceGetGas is the gas allowance of a NonceManager nonce lookup.
+const rip7712NonceGetGas = 100_000
 
-	return slices.Concat(
+// prepareNonceManagerMessage encodes the calldata of the NonceManager frame,
+// validating and incrementing the nonce of the transaction.
+func prepareNonceManagerMessage(tx *types.Rip7560AccountAbstractionTx) []byte {
+	return bytes.Join([][]byte{
 		tx.Sender.Bytes(),
 		math.PaddedBigBytes(tx.NonceKey, 24),
 		math.PaddedBigBytes(big.NewInt(int64(tx.Nonce)), 8),
-	)
+	}, nil)
+}
+
+// GetRip7712Nonce returns the next nonce of the given sender and nonce key as
+// tracked by the RIP-7712 NonceManager in the state of the given EVM.
+func GetRip7712Nonce(evm *vm.EVM, sender common.Address, key *big.Int) (uint64, error) {
+	if key == nil {
+		key = new(big.Int)
+	}
+	input := append(append([]byte{}, sender.Bytes()...), math.PaddedBigBytes(key, 24)...)
+	ret, _, err := evm.StaticCall(vm.AccountRef(sender), AA_NONCE_MANAGER, input, rip7712NonceGetGas)
+	if err != nil {
+		return 0, err
+	}
+	if len(ret) < 32 {
+		// The NonceManager is not deployed, no nonce was used yet
+		return 0, nil
+	}
+	// The sequence number is held in the lowest 64 bits, the key may be
+	// returned in the upper bits
+	if upper := new(big.Int).SetBytes(ret[:24]); upper.Sign() != 0 && upper.Cmp(key) != 0 {
+		return 0, fmt.Errorf("NonceManager returned the nonce of key %#x instead of %#x", upper, key)
+	}
+	return binary.BigEndian.Uint64(ret[24:32]), nil
 }
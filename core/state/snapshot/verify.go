@@ -0,0 +1,166 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// errVerifyAborted is returned by the state verification workers stopped due
+// to the failure of another one.
+var errVerifyAborted = errors.New("verification aborted")
+
+// Verify iterates the whole state(all the accounts as well as the corresponding storages)
+// with the specific root and compares the re-computed hash with the original one.
+//
+// The account trie is regenerated in a single pass, while the storage tries are
+// verified concurrently, split into account ranges walked by separate iterators.
+func (t *Tree) Verify(root common.Hash) error {
+	return t.verify(root, runtime.NumCPU())
+}
+
+// verify checks the state with the given root, verifying the storage tries
+// in the given number of account ranges.
+func (t *Tree) verify(root common.Hash, threads int) error {
+	if threads < 1 {
+		threads = 1
+	}
+	var (
+		stats  = newGenerateStats()
+		abort  = make(chan struct{})
+		errc   = make(chan error, threads+1)
+		starts = splitAccountRange(threads)
+	)
+	// Regenerate the account trie, with the storage roots taken from the accounts
+	go func() {
+		acctIt, err := t.AccountIterator(root, common.Hash{})
+		if err != nil {
+			errc <- err
+			return
+		}
+		it := &abortableAccountIterator{AccountIterator: acctIt, abort: abort}
+		defer it.Release()
+
+		got, err := generateTrieRoot(nil, "", it, common.Hash{}, stackTrieGenerate, nil, stats, true)
+		if err == nil && it.aborted {
+			err = errVerifyAborted
+		}
+		if err == nil && got != root {
+			err = fmt.Errorf("state root hash mismatch: got %x, want %x", got, root)
+		}
+		errc <- err
+	}()
+	// Check the storage roots of the accounts range by range
+	for i, start := range starts {
+		var end *common.Hash
+		if i+1 < len(starts) {
+			end = &starts[i+1]
+		}
+		go func(start common.Hash, end *common.Hash) {
+			errc <- t.verifyStorageRange(root, start, end, stats, abort)
+		}(start, end)
+	}
+	// Wait for all the workers, aborting all of them on the first failure
+	var fail error
+	for i := 0; i < threads+1; i++ {
+		if err := <-errc; err != nil && fail == nil {
+			fail = err
+			close(abort)
+		}
+	}
+	return fail
+}
+
+// verifyStorageRange regenerates the storage tries of the accounts in the range
+// [start, end) and checks them against the storage roots of the accounts. A nil
+// end denotes the end of the account space.
+func (t *Tree) verifyStorageRange(root common.Hash, start common.Hash, end *common.Hash, stats *generateStats, abort chan struct{}) error {
+	acctIt, err := t.AccountIterator(root, start)
+	if err != nil {
+		return err
+	}
+	defer acctIt.Release()
+
+	for acctIt.Next() {
+		hash := acctIt.Hash()
+		if end != nil && bytes.Compare(hash[:], end[:]) >= 0 {
+			break
+		}
+		select {
+		case <-abort:
+			return errVerifyAborted
+		default:
+		}
+		account, err := types.FullAccount(acctIt.Account())
+		if err != nil {
+			return err
+		}
+		storageIt, err := t.StorageIterator(root, hash, common.Hash{})
+		if err != nil {
+			return err
+		}
+		subroot, err := generateTrieRoot(nil, "", storageIt, hash, stackTrieGenerate, nil, stats, false)
+		storageIt.Release()
+		if err != nil {
+			return err
+		}
+		if account.Root != subroot {
+			return fmt.Errorf("invalid subroot(path %x), want %x, have %x", hash, account.Root, subroot)
+		}
+	}
+	return acctIt.Error()
+}
+
+// splitAccountRange splits the account hash space into the given number of
+// equally sized ranges, returning the start of each of them.
+func splitAccountRange(n int) []common.Hash {
+	var (
+		starts = make([]common.Hash, n)
+		step   = math.MaxUint64/uint64(n) + 1
+	)
+	for i := 1; i < n; i++ {
+		binary.BigEndian.PutUint64(starts[i][:8], uint64(i)*step)
+	}
+	return starts
+}
+
+// abortableAccountIterator is an account iterator which stops iterating once
+// the abort channel is closed.
+type abortableAccountIterator struct {
+	AccountIterator
+	abort   chan struct{}
+	aborted bool
+}
+
+// Next steps the iterator forward one element, unless aborted.
+func (it *abortableAccountIterator) Next() bool {
+	select {
+	case <-it.abort:
+		it.aborted = true
+		return false
+	default:
+		return it.AccountIterator.Next()
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
+)
+
+// Tests that the account ranges cover the whole account space in order.
+func TestSplitAccountRange(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 16, 17} {
+		starts := splitAccountRange(n)
+		if len(starts) != n {
+			t.Fatalf("%d ranges: have %d starts", n, len(starts))
+		}
+		if starts[0] != (common.Hash{}) {
+			t.Errorf("%d ranges: first range starts at %x", n, starts[0])
+		}
+		for i := 1; i < n; i++ {
+			if starts[i].Big().Cmp(starts[i-1].Big()) <= 0 {
+				t.Errorf("%d ranges: range %d starts at %x, before %x", n, i, starts[i], starts[i-1])
+			}
+		}
+	}
+}
+
+// Tests that the state verification walking the accounts in parallel ranges
+// accepts a consistent snapshot and detects corrupted accounts and storage.
+func TestVerify(t *testing.T) {
+	testVerify(t, rawdb.HashScheme)
+	testVerify(t, rawdb.PathScheme)
+}
+
+func testVerify(t *testing.T, scheme string) {
+	var (
+		helper = newHelper(scheme)
+		keys   = []string{"key-1", "key-2", "key-3"}
+		vals   = []string{"val-1", "val-2", "val-3"}
+	)
+	for i := 0; i < 64; i++ {
+		acc := fmt.Sprintf("acc-%d", i)
+		root := types.EmptyRootHash
+		if i%2 == 0 {
+			root = helper.makeStorageTrie(hashData([]byte(acc)), keys, vals, true)
+			helper.addSnapStorage(acc, keys, vals)
+		}
+		helper.addAccount(acc, &types.StateAccount{Balance: uint256.NewInt(uint64(i)), Root: root, CodeHash: types.EmptyCodeHash.Bytes()})
+	}
+	root, snap := helper.CommitAndGenerate()
+	select {
+	case <-snap.genPending:
+	case <-time.After(3 * time.Second):
+		t.Fatalf("Snapshot generation failed")
+	}
+	defer func() {
+		stop := make(chan *generatorStats)
+		snap.genAbort <- stop
+		<-stop
+	}()
+	snaps := &Tree{layers: map[common.Hash]snapshot{root: snap}}
+
+	for _, threads := range []int{1, 4, 16} {
+		if err := snaps.verify(root, threads); err != nil {
+			t.Errorf("%d threads: failed to verify state: %v", threads, err)
+		}
+	}
+	// Corrupt a storage slot, which only the storage verification detects
+	rawdb.WriteStorageSnapshot(helper.diskdb, hashData([]byte("acc-10")), hashData([]byte("key-2")), []byte("val-x"))
+	if err := snaps.verify(root, 4); err == nil {
+		t.Errorf("corrupted storage not detected")
+	}
+	rawdb.WriteStorageSnapshot(helper.diskdb, hashData([]byte("acc-10")), hashData([]byte("key-2")), []byte("val-2"))
+
+	// Corrupt an account, which only the account trie regeneration detects
+	rawdb.WriteAccountSnapshot(helper.diskdb, hashData([]byte("acc-11")), types.SlimAccountRLP(types.StateAccount{
+		Balance: uint256.NewInt(1000), Root: types.EmptyRootHash, CodeHash: types.EmptyCodeHash.Bytes(),
+	}))
+	if err := snaps.verify(root, 4); err == nil {
+		t.Errorf("corrupted account not detected")
+	}
+}
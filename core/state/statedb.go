@@ -270,6 +270,11 @@ func (s *StateDB) GetLogs(hash common.Hash, blockNumber uint64, blockHash common
 	return logs
 }
 
+// TxLogCount returns the number of logs emitted so far by the current transaction.
+func (s *StateDB) TxLogCount() int {
+	return len(s.logs[s.thash])
+}
+
 func (s *StateDB) Logs() []*types.Log {
 	var logs []*types.Log
 	for _, lgs := range s.logs {
@@ -1385,6 +1390,12 @@ func (s *StateDB) SlotInAccessList(addr common.Address, slot common.Hash) (addre
 	return s.accessList.Contains(addr, slot)
 }
 
+// AccessList returns the addresses and slots in the access list, in no
+// particular order.
+func (s *StateDB) AccessList() types.AccessList {
+	return s.accessList.List()
+}
+
 // markDelete is invoked when an account is deleted but the deletion is
 // not yet committed. The pending mutation is cached and will be applied
 // all together
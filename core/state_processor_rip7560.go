@@ -1,6 +1,7 @@
 package core
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -18,10 +19,9 @@ import (
 )
 
 type EntryPointCall struct {
-	OnEnterSuper tracing.EnterHook
-	Input        []byte
-	From         common.Address
-	err          error
+	Input []byte
+	From  common.Address
+	err   error
 }
 
 type ValidationPhaseResult struct {
@@ -42,6 +42,8 @@ type ValidationPhaseResult struct {
 	SenderValidUntil      uint64
 	PmValidAfter          uint64
 	PmValidUntil          uint64
+
+	logFrames logFrames
 }
 
 func (vpr *ValidationPhaseResult) validationPhaseUsedGas() (uint64, error) {
@@ -65,6 +67,7 @@ const (
 // code and a binary data blob.
 type ValidationPhaseError struct {
 	error
+	kind   error  // class of the failure, one of the ErrAA errors if known
 	reason string // revert reason hex encoded
 
 	revertEntityName *string
@@ -75,6 +78,55 @@ func (v *ValidationPhaseError) ErrorData() interface{} {
 	return v.reason
 }
 
+// ErrorCode returns the JSON-RPC error code of the failure, following the error
+// codes of the ERC-4337 bundler API.
+func (v *ValidationPhaseError) ErrorCode() int {
+	switch {
+	case errors.Is(v, ErrValidationRulesViolation):
+		return -32502
+	case errors.Is(v, ErrAAValidityRange):
+		return -32503
+	case v.Frame() == FramePaymaster || v.Frame() == FramePostOp:
+		return -32501
+	default:
+		return -32500
+	}
+}
+
+func (v *ValidationPhaseError) Unwrap() error {
+	return v.error
+}
+
+// Is reports whether the failure is of the given class, e.g. ErrAANonceMismatch.
+func (v *ValidationPhaseError) Is(target error) bool {
+	return v.kind != nil && target == v.kind
+}
+
+// Frame returns the name of the frame the failure occurred in, if known.
+func (v *ValidationPhaseError) Frame() string {
+	if v.revertEntityName == nil {
+		return ""
+	}
+	return *v.revertEntityName
+}
+
+// RevertData returns the data returned by the failing frame.
+func (v *ValidationPhaseError) RevertData() []byte {
+	return common.FromHex(v.reason)
+}
+
+// classify sets the class of the failure and the frame it occurred in, unless
+// already known.
+func (v *ValidationPhaseError) classify(kind error, frame string) *ValidationPhaseError {
+	if v.kind == nil {
+		v.kind = kind
+	}
+	if v.revertEntityName == nil {
+		v.revertEntityName = &frame
+	}
+	return v
+}
+
 // wrapError creates a revertError instance for validation errors not caused by an on-chain revert
 func wrapError(
 	innerErr error,
@@ -94,22 +146,16 @@ func newValidationPhaseError(
 	if errors.As(innerErr, &vpeCast) {
 		return vpeCast
 	}
-	var errorMessage string
 	contractSubst := ""
 	if revertEntityName != nil {
 		contractSubst = fmt.Sprintf(" in contract %s", *revertEntityName)
 	}
+	var err error
 	if innerErr != nil {
-		errorMessage = fmt.Sprintf(
-			"validation phase failed%s with exception: %s",
-			contractSubst,
-			innerErr.Error(),
-		)
+		err = fmt.Errorf("validation phase failed%s with exception: %w", contractSubst, innerErr)
 	} else {
-		errorMessage = fmt.Sprintf("validation phase failed%s", contractSubst)
+		err = fmt.Errorf("validation phase failed%s", contractSubst)
 	}
-	// TODO: use "vm.ErrorX" for RIP-7560 specific errors as well!
-	err := errors.New(errorMessage)
 
 	reason, errUnpack := abi.UnpackRevert(revertReason)
 	if errUnpack == nil {
@@ -124,8 +170,89 @@ func newValidationPhaseError(
 	}
 }
 
+// newFrameError creates the error of a failed validation frame, reporting the
+// frames exhausting their gas limit with ErrValidationOutOfGas.
+func newFrameError(result *ExecutionResult, frame string, gasLimit uint64) *ValidationPhaseError {
+	kind := ErrAAValidationReverted
+	switch frame {
+	case FrameNonceManager:
+		kind = ErrAANonceMismatch
+	case FrameDeployer:
+		kind = ErrAADeploymentFailed
+	}
+	if !errors.Is(result.Err, vm.ErrOutOfGas) {
+		return newValidationPhaseError(result.Err, result.ReturnData, &frame, true).classify(kind, frame)
+	}
+	return &ValidationPhaseError{
+		error:            fmt.Errorf("%w: frame %s, gas limit %d", ErrValidationOutOfGas, frame, gasLimit),
+		kind:             kind,
+		reason:           hexutil.Encode(result.ReturnData),
+		frameReverted:    true,
+		revertEntityName: &frame,
+	}
+}
+
+// newNonceError creates the error of a nonce rejected by the state or the
+// NonceManager, keeping the message of the given error.
+func newNonceError(err error) *ValidationPhaseError {
+	return &ValidationPhaseError{error: err, kind: ErrAANonceMismatch, reason: "0x", revertEntityName: ptr(FrameNonceManager)}
+}
+
+// ApplyRip7560Transaction applies both phases of a RIP-7560 transaction at the
+// given index of a block, like ApplyTransaction does for other transactions. A
+// failed validation phase is returned as an error, invalidating the block.
+func ApplyRip7560Transaction(
+	chainConfig *params.ChainConfig,
+	bc ChainContext,
+	coinbase *common.Address,
+	gp *GasPool,
+	statedb *state.StateDB,
+	header *types.Header,
+	blockHash common.Hash,
+	tx *types.Transaction,
+	txIndex int,
+	usedGas *uint64,
+	cfg vm.Config,
+) (*types.Receipt, error) {
+	gasPrice, _ := rip7560GasPrices(tx.Rip7560TransactionData(), header.BaseFee, cfg.NoBaseFee)
+	evm := vm.NewEVM(NewEVMBlockContext(header, bc, coinbase), vm.TxContext{GasPrice: gasPrice}, statedb, chainConfig, cfg)
+	return ApplyRip7560TransactionWithEVM(evm, gp, statedb, header, blockHash, tx, txIndex, usedGas)
+}
+
+// ApplyRip7560TransactionWithEVM is like ApplyRip7560Transaction, running both
+// phases on an EVM provided by the caller, with its hooks already attached. The
+// EVM is reused, so the caller can trace or cancel the transaction through it.
+func ApplyRip7560TransactionWithEVM(
+	evm *vm.EVM,
+	gp *GasPool,
+	statedb *state.StateDB,
+	header *types.Header,
+	blockHash common.Hash,
+	tx *types.Transaction,
+	txIndex int,
+	usedGas *uint64,
+) (*types.Receipt, error) {
+	statedb.SetTxContext(tx.Hash(), txIndex)
+	vpr, err := ApplyRip7560ValidationPhasesWithEVM(evm, gp, statedb, header, tx)
+	if err != nil {
+		return nil, err
+	}
+	vpr.TxIndex = txIndex
+	receipt, err := ApplyRip7560ExecutionPhaseWithEVM(evm, vpr, gp, statedb, header, usedGas)
+	if err != nil {
+		return nil, err
+	}
+	statedb.Finalise(true)
+
+	receipt.Logs = statedb.GetLogs(tx.Hash(), header.Number.Uint64(), blockHash)
+	receipt.BlockHash = blockHash
+	receipt.BlockNumber = header.Number
+	return receipt, nil
+}
+
 // HandleRip7560Transactions apply state changes of all sequential RIP-7560 transactions.
 // During block building the 'skipInvalid' flag is set to False, and invalid transactions are silently ignored.
+// The index is the position in the block of the first included transaction.
 // Returns an array of included transactions.
 func HandleRip7560Transactions(
 	transactions []*types.Transaction,
@@ -145,7 +272,7 @@ func HandleRip7560Transactions(
 	allLogs := make([]*types.Log, 0)
 
 	iTransactions, iReceipts, validationFailureReceipts, iLogs, err := handleRip7560Transactions(
-		transactions, index, statedb, coinbase, header, gp, chainConfig, bc, cfg, skipInvalid, usedGas,
+		transactions, index, statedb, coinbase, header, gp, chainConfig, bc, cfg, 0, skipInvalid, usedGas,
 	)
 	if err != nil {
 		return nil, nil, nil, nil, err
@@ -156,6 +283,8 @@ func HandleRip7560Transactions(
 	return validatedTransactions, receipts, validationFailureReceipts, allLogs, nil
 }
 
+// handleRip7560Transactions applies the leading RIP-7560 transactions. If more
+// than one worker is given, their validation phases are run ahead concurrently.
 func handleRip7560Transactions(
 	transactions []*types.Transaction,
 	index int,
@@ -166,6 +295,7 @@ func handleRip7560Transactions(
 	chainConfig *params.ChainConfig,
 	bc ChainContext,
 	cfg vm.Config,
+	workers int,
 	skipInvalid bool,
 	usedGas *uint64,
 ) ([]*types.Transaction, types.Receipts, []*types.Rip7560TransactionDebugInfo, []*types.Log, error) {
@@ -174,17 +304,46 @@ func handleRip7560Transactions(
 	validationFailureInfos := make([]*types.Rip7560TransactionDebugInfo, 0)
 	receipts := make([]*types.Receipt, 0)
 	allLogs := make([]*types.Log, 0)
-	for i, tx := range transactions[index:] {
+
+	// The validation phases are run ahead concurrently if enabled, and merged
+	// in turn unless invalidated by the transactions before
+	var (
+		specs       []*rip7560Speculation
+		beneficiary = header.Coinbase
+		rules       = chainConfig.Rules(header.Number, header.Difficulty.Sign() == 0, header.Time)
+	)
+	if coinbase != nil {
+		beneficiary = *coinbase
+	}
+	if workers > 1 && cfg.Tracer == nil && !cfg.EnablePreimageRecording {
+		specs = speculateRip7560Validations(workers, transactions, statedb, coinbase, header, gp, chainConfig, bc, cfg)
+	}
+	for i, tx := range transactions {
 		if tx.Type() != types.Rip7560Type {
 			break
 		}
+		// Skipped transactions don't take a position in the block
+		txIndex := index + len(validatedTransactions)
 
-		statedb.SetTxContext(tx.Hash(), index+i)
+		statedb.SetTxContext(tx.Hash(), txIndex)
 		beforeValidationSnapshotId := statedb.Snapshot()
-		vpr, vpe := ApplyRip7560ValidationPhases(chainConfig, bc, coinbase, gp, statedb, header, tx, cfg)
+
+		var (
+			vpr *ValidationPhaseResult
+			vpe error
+		)
+		if i < len(specs) && specs[i] != nil && specs[i].holds(statedb, gp) {
+			rip7560SpeculationHitMeter.Mark(1)
+			vpr = specs[i].apply(statedb, rules, beneficiary, gp)
+		} else {
+			if i < len(specs) && specs[i] != nil {
+				rip7560SpeculationConflictMeter.Mark(1)
+			}
+			vpr, vpe = ApplyRip7560ValidationPhases(chainConfig, bc, coinbase, gp, statedb, header, tx, cfg)
+		}
 		if vpe != nil {
 			if skipInvalid {
-				log.Error("Validation failed during block building, should not happen, skipping transaction", "error", vpe)
+				log.Warn("Validation failed during block building, skipping transaction", "hash", tx.Hash(), "err", vpe)
 				debugInfo := &types.Rip7560TransactionDebugInfo{
 					TxHash:           tx.Hash(),
 					RevertData:       vpe.Error(),
@@ -206,6 +365,7 @@ func handleRip7560Transactions(
 			}
 			return nil, nil, nil, nil, vpe
 		}
+		vpr.TxIndex = txIndex
 		validationPhaseResults = append(validationPhaseResults, vpr)
 		validatedTransactions = append(validatedTransactions, tx)
 
@@ -229,42 +389,94 @@ func handleRip7560Transactions(
 	return validatedTransactions, receipts, validationFailureInfos, allLogs, nil
 }
 
+// checkRip7560Fees checks the fee fields of a RIP-7560 transaction against the
+// base fee of the block, mirroring the checks of the other transaction types.
+// The checks are skipped if the fee fields are zero and the base fee was
+// explicitly disabled, as done by simulations.
+func checkRip7560Fees(config *params.ChainConfig, header *types.Header, aatx *types.Rip7560AccountAbstractionTx, noBaseFee bool) error {
+	if !config.IsLondon(header.Number) {
+		return nil
+	}
+	if noBaseFee && aatx.GasFeeCap.BitLen() == 0 && aatx.GasTipCap.BitLen() == 0 {
+		return nil
+	}
+	if l := aatx.GasFeeCap.BitLen(); l > 256 {
+		return fmt.Errorf("%w: address %v, maxFeePerGas bit length: %d", ErrFeeCapVeryHigh,
+			aatx.Sender.Hex(), l)
+	}
+	if l := aatx.GasTipCap.BitLen(); l > 256 {
+		return fmt.Errorf("%w: address %v, maxPriorityFeePerGas bit length: %d", ErrTipVeryHigh,
+			aatx.Sender.Hex(), l)
+	}
+	if aatx.GasFeeCap.Cmp(aatx.GasTipCap) < 0 {
+		return fmt.Errorf("%w: address %v, maxPriorityFeePerGas: %s, maxFeePerGas: %s", ErrTipAboveFeeCap,
+			aatx.Sender.Hex(), aatx.GasTipCap, aatx.GasFeeCap)
+	}
+	if header.BaseFee != nil && aatx.GasFeeCap.Cmp(header.BaseFee) < 0 {
+		return fmt.Errorf("%w: address %v, maxFeePerGas: %s, baseFee: %s", ErrFeeCapTooLow,
+			aatx.Sender.Hex(), aatx.GasFeeCap, header.BaseFee)
+	}
+	return nil
+}
+
+// BuyGasRip7560Transaction reserves the total gas limit of the transaction in
+// the block gas pool and pre-charges the gas payer, the paymaster if there is
+// one and the sender otherwise, for all of it at the given maximum gas price.
+// Paymasters are charged from their DepositManager deposit instead of their
+// balance if the chain funds them by deposits. The part of the pre-charge not
+// covering the gas used at the effective gas price is refunded after the
+// execution phase. It returns the total gas limit and the pre-charged amount.
 func BuyGasRip7560Transaction(
+	config *params.ChainConfig,
 	st *types.Rip7560AccountAbstractionTx,
 	state vm.StateDB,
-	gasPrice *uint256.Int,
+	maxGasPrice *uint256.Int,
 	gp *GasPool,
 ) (uint64, *uint256.Int, error) {
 	gasLimit, err := st.TotalGasLimit()
 	if err != nil {
 		return 0, nil, err
 	}
-
-	//TODO: check gasLimit against block gasPool
-	preCharge := new(uint256.Int).SetUint64(gasLimit)
-	preCharge = preCharge.Mul(preCharge, gasPrice)
-
 	chargeFrom := st.GasPayer()
 
-	if have, want := state.GetBalance(*chargeFrom), preCharge; have.Cmp(want) < 0 {
+	preCharge, overflow := new(uint256.Int).MulOverflow(new(uint256.Int).SetUint64(gasLimit), maxGasPrice)
+	if overflow {
+		return 0, nil, fmt.Errorf("%w: RIP-7560 address %v required balance exceeds 256 bits", ErrInsufficientFunds, chargeFrom.Hex())
+	}
+	deposit := chargesRip7560Deposit(config, st)
+	if have, want := Rip7560GasFunds(config, state, st), preCharge; have.Cmp(want) < 0 {
+		if deposit {
+			return 0, nil, fmt.Errorf("%w: RIP-7560 paymaster %v deposit have %v want %v", ErrInsufficientFunds, chargeFrom.Hex(), have, want)
+		}
 		return 0, nil, fmt.Errorf("%w: RIP-7560 address %v have %v want %v", ErrInsufficientFunds, chargeFrom.Hex(), have, want)
 	}
-
-	state.SubBalance(*chargeFrom, preCharge, 0)
 	if err := gp.SubGas(gasLimit); err != nil {
 		return 0, nil, newValidationPhaseError(err, nil, ptr("block gas limit"), false)
 	}
+	if deposit {
+		subRip7560Deposit(state, *chargeFrom, preCharge)
+	} else {
+		state.SubBalance(*chargeFrom, preCharge, tracing.BalanceDecreaseGasBuy)
+	}
 	return gasLimit, preCharge, nil
 }
 
-// refund the transaction payer (either account or paymaster) with the excess gas cost
-func refundPayer(vpr *ValidationPhaseResult, state vm.StateDB, gasUsed uint64) {
-	var chargeFrom = vpr.Tx.Rip7560TransactionData().GasPayer()
-
+// refundPayer refunds the transaction payer (either account or paymaster) with
+// the part of the pre-charge not covering the gas used at the effective price,
+// returning it to wherever it was charged from.
+func refundPayer(config *params.ChainConfig, vpr *ValidationPhaseResult, state vm.StateDB, gasUsed uint64) {
+	var (
+		aatx       = vpr.Tx.Rip7560TransactionData()
+		chargeFrom = aatx.GasPayer()
+	)
 	actualGasCost := new(uint256.Int).Mul(vpr.EffectiveGasPrice, new(uint256.Int).SetUint64(gasUsed))
 
 	refund := new(uint256.Int).Sub(vpr.PreCharge, actualGasCost)
 
+	if chargesRip7560Deposit(config, aatx) {
+		addRip7560Deposit(state, *chargeFrom, refund)
+		return
+	}
 	state.AddBalance(*chargeFrom, refund, tracing.BalanceIncreaseGasReturn)
 }
 
@@ -277,31 +489,50 @@ func CheckNonceRip7560(st *StateTransition, tx *types.Rip7560AccountAbstractionT
 	}
 	stNonce := st.state.GetNonce(*tx.Sender)
 	if msgNonce := tx.Nonce; stNonce < msgNonce {
-		return 0, fmt.Errorf("%w: address %v, tx: %d state: %d", ErrNonceTooHigh,
-			tx.Sender.Hex(), msgNonce, stNonce)
+		return 0, newNonceError(fmt.Errorf("%w: address %v, tx: %d state: %d", ErrNonceTooHigh,
+			tx.Sender.Hex(), msgNonce, stNonce))
 	} else if stNonce > msgNonce {
-		return 0, fmt.Errorf("%w: address %v, tx: %d state: %d", ErrNonceTooLow,
-			tx.Sender.Hex(), msgNonce, stNonce)
+		return 0, newNonceError(fmt.Errorf("%w: address %v, tx: %d state: %d", ErrNonceTooLow,
+			tx.Sender.Hex(), msgNonce, stNonce))
 	} else if stNonce+1 < stNonce {
-		return 0, fmt.Errorf("%w: address %v, nonce: %d", ErrNonceMax,
-			tx.Sender.Hex(), stNonce)
+		return 0, newNonceError(fmt.Errorf("%w: address %v, nonce: %d", ErrNonceMax,
+			tx.Sender.Hex(), stNonce))
 	}
 	return 0, nil
 }
 
 func performNonceCheckFrameRip7712(st *StateTransition, tx *types.Rip7560AccountAbstractionTx) (uint64, error) {
-	if !st.evm.ChainConfig().IsRIP7712(st.evm.Context.BlockNumber) {
+	if !st.evm.ChainConfig().IsRIP7712(st.evm.Context.BlockNumber, st.evm.Context.Time) {
 		return 0, wrapError(fmt.Errorf("RIP-7712 nonce is disabled"))
 	}
+	if st.state.GetCodeSize(AA_NONCE_MANAGER) == 0 {
+		return 0, wrapError(errors.New("RIP-7712 NonceManager is not deployed"))
+	}
+	// Look the nonce up first for a meaningful error, without leaving the
+	// lookup in the access list or showing it to the tracer
+	snapshot := st.state.Snapshot()
+	lookup := vm.NewEVM(st.evm.Context, st.evm.TxContext, st.state, st.evm.ChainConfig(), vm.Config{})
+	next, err := GetRip7712Nonce(lookup, *tx.Sender, tx.NonceKey)
+	st.state.RevertToSnapshot(snapshot)
+	switch {
+	case err != nil:
+		return 0, wrapError(fmt.Errorf("RIP-7712 nonce lookup failed: %w", err))
+	case next < tx.Nonce:
+		return 0, newNonceError(fmt.Errorf("%w: address %v, key %#x, tx: %d state: %d", ErrNonceTooHigh,
+			tx.Sender.Hex(), tx.NonceKey, tx.Nonce, next))
+	case next > tx.Nonce:
+		return 0, newNonceError(fmt.Errorf("%w: address %v, key %#x, tx: %d state: %d", ErrNonceTooLow,
+			tx.Sender.Hex(), tx.NonceKey, tx.Nonce, next))
+	}
 	nonceManagerMessageData := prepareNonceManagerMessage(tx)
-	resultNonceManager := CallFrame(st, &AA_ENTRY_POINT, &AA_NONCE_MANAGER, nonceManagerMessageData, st.gasRemaining)
+	resultNonceManager := callFrame(st, FrameNonceManager, &AA_ENTRY_POINT, &AA_NONCE_MANAGER, nonceManagerMessageData, st.gasRemaining)
 	if resultNonceManager.Failed() {
 		return 0, newValidationPhaseError(
 			fmt.Errorf("RIP-7712 nonce validation failed: %w", resultNonceManager.Err),
 			resultNonceManager.ReturnData,
-			ptr("NonceManager"),
+			ptr(FrameNonceManager),
 			true,
-		)
+		).classify(ErrAANonceMismatch, FrameNonceManager)
 	}
 	return resultNonceManager.UsedGas, nil
 }
@@ -320,8 +551,34 @@ func CallFrame(st *StateTransition, from *common.Address, to *common.Address, da
 	}
 }
 
+// callFrame calls the named frame of a RIP-7560 transaction, reporting it to
+// the tracer of the state transition.
+func callFrame(st *StateTransition, frame string, from *common.Address, to *common.Address, data []byte, gasLimit uint64) *ExecutionResult {
+	tracer := st.evm.Config.Tracer
+	if tracer != nil && tracer.OnAAFrameStart != nil {
+		tracer.OnAAFrameStart(frame, *to, data, gasLimit)
+	}
+	result := CallFrame(st, from, to, data, gasLimit)
+	if tracer != nil && tracer.OnAAFrameEnd != nil {
+		tracer.OnAAFrameEnd(frame, result.ReturnData, result.UsedGas, result.Err)
+	}
+	return result
+}
+
+// rip7560GasPrices returns the effective gas price of a RIP-7560 transaction,
+// and the maximum one its gas is pre-charged at. Simulations without fees are
+// neither charged nor paid for.
+func rip7560GasPrices(aatx *types.Rip7560AccountAbstractionTx, baseFee *big.Int, noBaseFee bool) (*big.Int, *big.Int) {
+	if noBaseFee && aatx.GasFeeCap.BitLen() == 0 && aatx.GasTipCap.BitLen() == 0 {
+		return new(big.Int), new(big.Int)
+	}
+	return aatx.EffectiveGasPrice(baseFee), aatx.GasFeeCap
+}
+
 func ptr(s string) *string { return &s }
 
+// ApplyRip7560ValidationPhases runs the validation frames of a RIP-7560
+// transaction, reporting the validation phase to the tracer of the config.
 func ApplyRip7560ValidationPhases(
 	chainConfig *params.ChainConfig,
 	bc ChainContext,
@@ -332,43 +589,91 @@ func ApplyRip7560ValidationPhases(
 	tx *types.Transaction,
 	cfg vm.Config,
 ) (*ValidationPhaseResult, error) {
+	gasPrice, _ := rip7560GasPrices(tx.Rip7560TransactionData(), header.BaseFee, cfg.NoBaseFee)
+	evm := vm.NewEVM(NewEVMBlockContext(header, bc, coinbase), vm.TxContext{GasPrice: gasPrice}, statedb, chainConfig, cfg)
+	return ApplyRip7560ValidationPhasesWithEVM(evm, gp, statedb, header, tx)
+}
+
+// ApplyRip7560ValidationPhasesWithEVM runs the validation frames of a RIP-7560
+// transaction on the given EVM, whose transaction context is reset. The phase
+// is reported to the tracer of the EVM config.
+func ApplyRip7560ValidationPhasesWithEVM(
+	evm *vm.EVM,
+	gp *GasPool,
+	statedb *state.StateDB,
+	header *types.Header,
+	tx *types.Transaction,
+) (*ValidationPhaseResult, error) {
+	tracer := evm.Config.Tracer
+	if tracer != nil && tracer.OnAAValidationStart != nil {
+		tracer.OnAAValidationStart(tx)
+	}
+	vpr, err := applyRip7560ValidationPhases(evm, gp, statedb, header, tx)
+	if tracer != nil && tracer.OnAAValidationEnd != nil {
+		tracer.OnAAValidationEnd(tx, err)
+	}
+	return vpr, err
+}
+
+func applyRip7560ValidationPhases(
+	evm *vm.EVM,
+	gp *GasPool,
+	statedb *state.StateDB,
+	header *types.Header,
+	tx *types.Transaction,
+) (*ValidationPhaseResult, error) {
+	chainConfig := evm.ChainConfig()
+	if !chainConfig.IsRIP7560(header.Number, header.Time) {
+		return nil, wrapError(fmt.Errorf("%w: rip7560 not active", ErrTxTypeNotSupported))
+	}
 	aatx := tx.Rip7560TransactionData()
 	err := performStaticValidation(aatx, statedb)
 	if err != nil {
 		return nil, wrapError(err)
 	}
 
-	gasPrice := aatx.EffectiveGasPrice(header.BaseFee)
+	if err := checkRip7560Fees(chainConfig, header, aatx, evm.Config.NoBaseFee); err != nil {
+		return nil, wrapError(err)
+	}
+	gasPrice, maxGasPrice := rip7560GasPrices(aatx, header.BaseFee, evm.Config.NoBaseFee)
 	effectiveGasPrice := uint256.MustFromBig(gasPrice)
-	gasLimit, preCharge, err := BuyGasRip7560Transaction(aatx, statedb, effectiveGasPrice, gp)
+	gasLimit, preCharge, err := BuyGasRip7560Transaction(chainConfig, aatx, statedb, uint256.MustFromBig(maxGasPrice), gp)
 	if err != nil {
 		return nil, wrapError(err)
 	}
 
-	blockContext := NewEVMBlockContext(header, bc, coinbase)
 	sender := aatx.Sender
-	txContext := vm.TxContext{
-		Origin:   *aatx.Sender,
-		GasPrice: gasPrice,
-	}
-	evm := vm.NewEVM(blockContext, txContext, statedb, chainConfig, cfg)
+	evm.Reset(vm.TxContext{Origin: *sender, GasPrice: gasPrice}, statedb)
 	rules := evm.ChainConfig().Rules(evm.Context.BlockNumber, evm.Context.Random != nil, evm.Context.Time)
 
 	statedb.Prepare(rules, *sender, evm.Context.Coinbase, &AA_ENTRY_POINT, vm.ActivePrecompiles(rules), tx.AccessList())
+	// The paymaster and the deployer are called directly by the protocol, so
+	// they are warm like the sender
+	if aatx.IsSponsored() {
+		statedb.AddAddressToAccessList(*aatx.Paymaster)
+	}
+	if aatx.Deployer != nil {
+		statedb.AddAddressToAccessList(*aatx.Deployer)
+	}
+	// The deposit of a paymaster funded by the DepositManager is accessed by
+	// the protocol, so it is warm for the paymaster to check
+	if chargesRip7560Deposit(chainConfig, aatx) {
+		statedb.AddSlotToAccessList(AA_DEPOSIT_MANAGER, rip7560DepositSlot(*aatx.Paymaster))
+	}
+	// An EIP-7702 delegated sender runs the code of its delegation target in
+	// the validation and execution frames, which is warmed like the sender
+	if rules.IsPrague {
+		if target, ok := types.ParseDelegation(statedb.GetCode(*sender)); ok {
+			statedb.AddAddressToAccessList(target)
+		}
+	}
 
 	epc := &EntryPointCall{}
 
-	if evm.Config.Tracer == nil {
-		evm.Config.Tracer = &tracing.Hooks{
-			OnEnter: epc.OnEnter,
-		}
-	} else {
-		// keep the original tracer's OnEnter hook
-		epc.OnEnterSuper = evm.Config.Tracer.OnEnter
-		newTracer := *evm.Config.Tracer
-		newTracer.OnEnter = epc.OnEnter
-		evm.Config.Tracer = &newTracer
-	}
+	// The EntryPoint calls are captured alongside the tracer of the caller,
+	// whose EVM is handed back with its own hooks
+	defer func(tracer *tracing.Hooks) { evm.Config.Tracer = tracer }(evm.Config.Tracer)
+	evm.Config.Tracer = tracing.NewMuxHooks(evm.Config.Tracer, &tracing.Hooks{OnEnter: epc.OnEnter})
 
 	if evm.Config.Tracer.OnTxStart != nil {
 		evm.Config.Tracer.OnTxStart(evm.GetVMContext(), tx, common.Address{})
@@ -384,32 +689,30 @@ func ApplyRip7560ValidationPhases(
 	}
 
 	/*** Nonce Manager Frame ***/
+	var frames logFrames
 	nonceManagerUsedGas, err := CheckNonceRip7560(st, aatx)
 	if err != nil {
 		return nil, err
 	}
+	frames.mark(statedb, FrameNonceManager)
 
 	/*** Deployer Frame ***/
 	var deploymentUsedGas uint64
 	if aatx.Deployer != nil {
 		deployerGasLimit := aatx.ValidationGasLimit - preTransactionGasCost
-		resultDeployer := CallFrame(st, &AA_SENDER_CREATOR, aatx.Deployer, aatx.DeployerData, deployerGasLimit)
+		resultDeployer := callFrame(st, FrameDeployer, &AA_SENDER_CREATOR, aatx.Deployer, aatx.DeployerData, deployerGasLimit)
 		if resultDeployer.Failed() {
-			return nil, newValidationPhaseError(
-				resultDeployer.Err,
-				resultDeployer.ReturnData,
-				ptr("deployer"),
-				true,
-			)
+			return nil, newFrameError(resultDeployer, FrameDeployer, deployerGasLimit)
 		}
 		if statedb.GetCodeSize(*sender) == 0 {
 			return nil, wrapError(
 				fmt.Errorf(
-					"sender not deployed by the deployer, sender:%s deployer:%s",
-					sender.String(), aatx.Deployer.String(),
-				))
+					"%w, sender:%s deployer:%s",
+					ErrSenderNotDeployed, sender.String(), aatx.Deployer.String(),
+				)).classify(ErrAADeploymentFailed, FrameDeployer)
 		}
 		deploymentUsedGas = resultDeployer.UsedGas
+		frames.mark(statedb, FrameDeployer)
 	} else {
 		if !aatx.IsRip7712Nonce() {
 			statedb.SetNonce(*sender, statedb.GetNonce(*sender)+1)
@@ -419,39 +722,36 @@ func ApplyRip7560ValidationPhases(
 	/*** Account Validation Frame ***/
 	signer := types.MakeSigner(chainConfig, header.Number, header.Time)
 	signingHash := signer.Hash(tx)
-	accountValidationMsg, err := prepareAccountValidationMessage(aatx, signingHash)
+	accountValidationMsg, err := prepareAccountValidationMessage(chainConfig, aatx, signingHash)
 	if err != nil {
 		return nil, wrapError(err)
 	}
 	accountGasLimit := aatx.ValidationGasLimit - preTransactionGasCost - deploymentUsedGas
-	resultAccountValidation := CallFrame(st, &AA_ENTRY_POINT, aatx.Sender, accountValidationMsg, accountGasLimit)
+	resultAccountValidation := callFrame(st, FrameAccount, &AA_ENTRY_POINT, aatx.Sender, accountValidationMsg, accountGasLimit)
 	if resultAccountValidation.Failed() {
-		return nil, newValidationPhaseError(
-			resultAccountValidation.Err,
-			resultAccountValidation.ReturnData,
-			ptr("account"),
-			true,
-		)
+		return nil, newFrameError(resultAccountValidation, FrameAccount, accountGasLimit)
 	}
-	aad, err := validateAccountEntryPointCall(epc, aatx.Sender)
+	aad, err := validateAccountEntryPointCall(epc, aatx.Sender, resultAccountValidation.ReturnData)
 	if err != nil {
-		return nil, wrapError(err)
+		return nil, wrapError(err).classify(ErrAAValidationRejected, FrameAccount)
 	}
+	frames.mark(statedb, FrameAccount)
 
 	// clear the EntryPoint calls array after parsing
 	epc.err = nil
 	epc.Input = nil
 	epc.From = common.Address{}
 
-	err = validateValidityTimeRange(header.Time, aad.ValidAfter.Uint64(), aad.ValidUntil.Uint64())
+	err = validateValidityTimeRange(header.Time, validityBound(aad.ValidAfter), validityBound(aad.ValidUntil))
 	if err != nil {
-		return nil, wrapError(err)
+		return nil, wrapError(err).classify(ErrAAValidationRejected, FrameAccount)
 	}
 
 	paymasterContext, pmValidationUsedGas, pmValidAfter, pmValidUntil, err := applyPaymasterValidationFrame(st, epc, tx, signingHash, header)
 	if err != nil {
 		return nil, err
 	}
+	frames.mark(statedb, FramePaymaster)
 
 	gasRefund := st.state.GetRefund()
 
@@ -467,10 +767,11 @@ func ApplyRip7560ValidationPhases(
 		NonceManagerUsedGas:   nonceManagerUsedGas,
 		ValidationUsedGas:     resultAccountValidation.UsedGas,
 		PmValidationUsedGas:   pmValidationUsedGas,
-		SenderValidAfter:      aad.ValidAfter.Uint64(),
-		SenderValidUntil:      aad.ValidUntil.Uint64(),
+		SenderValidAfter:      validityBound(aad.ValidAfter),
+		SenderValidUntil:      validityBound(aad.ValidUntil),
 		PmValidAfter:          pmValidAfter,
 		PmValidUntil:          pmValidUntil,
+		logFrames:             frames,
 	}
 	statedb.Finalise(true)
 
@@ -481,13 +782,21 @@ func performStaticValidation(
 	aatx *types.Rip7560AccountAbstractionTx,
 	statedb *state.StateDB,
 ) error {
-	hasPaymaster := aatx.Paymaster != nil
+	hasPaymaster := aatx.IsSponsored()
 	hasPaymasterData := aatx.PaymasterData != nil && len(aatx.PaymasterData) != 0
 	hasPaymasterGasLimit := aatx.PaymasterValidationGasLimit != 0
 	hasDeployer := aatx.Deployer != nil
 	hasDeployerData := aatx.DeployerData != nil && len(aatx.DeployerData) != 0
 	hasCodeSender := statedb.GetCodeSize(*aatx.Sender) != 0
 
+	if len(aatx.ExecutionCalls) != 0 && len(aatx.ExecutionData) != 0 {
+		return wrapError(
+			fmt.Errorf(
+				"execution data of size %d is provided together with %d execution calls",
+				len(aatx.ExecutionData), len(aatx.ExecutionCalls),
+			),
+		)
+	}
 	if !hasDeployer && hasDeployerData {
 		return wrapError(
 			fmt.Errorf(
@@ -571,31 +880,26 @@ func applyPaymasterValidationFrame(st *StateTransition, epc *EntryPointCall, tx
 	/*** Paymaster Validation Frame ***/
 	aatx := tx.Rip7560TransactionData()
 	var pmValidationUsedGas uint64
-	paymasterMsg, err := preparePaymasterValidationMessage(aatx, signingHash)
+	paymasterMsg, err := preparePaymasterValidationMessage(st.evm.ChainConfig(), aatx, signingHash)
 	if err != nil {
 		return nil, 0, 0, 0, wrapError(err)
 	}
 	if paymasterMsg == nil {
 		return nil, 0, 0, 0, nil
 	}
-	resultPm := CallFrame(st, &AA_ENTRY_POINT, aatx.Paymaster, paymasterMsg, aatx.PaymasterValidationGasLimit)
+	resultPm := callFrame(st, FramePaymaster, &AA_ENTRY_POINT, aatx.Paymaster, paymasterMsg, aatx.PaymasterValidationGasLimit)
 
 	if resultPm.Failed() {
-		return nil, 0, 0, 0, newValidationPhaseError(
-			resultPm.Err,
-			resultPm.ReturnData,
-			ptr("paymaster"),
-			true,
-		)
+		return nil, 0, 0, 0, newFrameError(resultPm, FramePaymaster, aatx.PaymasterValidationGasLimit)
 	}
 	pmValidationUsedGas = resultPm.UsedGas
-	apd, err := validatePaymasterEntryPointCall(epc, aatx.Paymaster)
+	apd, err := validatePaymasterEntryPointCall(epc, aatx.Paymaster, resultPm.ReturnData)
 	if err != nil {
-		return nil, 0, 0, 0, wrapError(err)
+		return nil, 0, 0, 0, wrapError(err).classify(ErrAAValidationRejected, FramePaymaster)
 	}
-	err = validateValidityTimeRange(header.Time, apd.ValidAfter.Uint64(), apd.ValidUntil.Uint64())
+	err = validateValidityTimeRange(header.Time, validityBound(apd.ValidAfter), validityBound(apd.ValidUntil))
 	if err != nil {
-		return nil, 0, 0, 0, wrapError(err)
+		return nil, 0, 0, 0, wrapError(err).classify(ErrAAValidationRejected, FramePaymaster)
 	}
 	if len(apd.Context) > 0 && aatx.PostOpGas == 0 {
 		return nil, 0, 0, 0, wrapError(
@@ -605,13 +909,42 @@ func applyPaymasterValidationFrame(st *StateTransition, epc *EntryPointCall, tx
 			),
 		)
 	}
-	return apd.Context, pmValidationUsedGas, apd.ValidAfter.Uint64(), apd.ValidUntil.Uint64(), nil
+	// The context is carried over into the postOp calldata, bound its size and,
+	// once the chain activates it, charge for it as if the paymaster had
+	// submitted it as calldata.
+	if maxSize := st.evm.ChainConfig().Rip7560MaxPaymasterContextSize(); uint64(len(apd.Context)) > maxSize {
+		return nil, 0, 0, 0, wrapError(fmt.Errorf("%w: size %d, max %d", ErrPaymasterContextTooLarge, len(apd.Context), maxSize))
+	}
+	if !st.evm.ChainConfig().IsRIP7560ContextGas(st.evm.Context.BlockNumber) {
+		return apd.Context, pmValidationUsedGas, apd.ValidAfter.Uint64(), apd.ValidUntil.Uint64(), nil
+	}
+	pmValidationUsedGas += paymasterContextGas(apd.Context)
+	if pmValidationUsedGas > aatx.PaymasterValidationGasLimit {
+		return nil, 0, 0, 0, wrapError(
+			fmt.Errorf(
+				"%w: paymaster validation used %d gas including its context, exceeding the limit of %d",
+				ErrValidationOutOfGas, pmValidationUsedGas, aatx.PaymasterValidationGasLimit,
+			),
+		)
+	}
+	return apd.Context, pmValidationUsedGas, validityBound(apd.ValidAfter), validityBound(apd.ValidUntil), nil
+}
+
+// paymasterContextGas returns the gas charged for the context of a paymaster,
+// priced the same as transaction calldata.
+func paymasterContextGas(context []byte) uint64 {
+	zero := uint64(bytes.Count(context, []byte{0}))
+	nonZero := uint64(len(context)) - zero
+	return zero*params.TxDataZeroGas + nonZero*params.TxDataNonZeroGasEIP2028
 }
 
+// applyPaymasterPostOpFrame calls the postPaymasterTransaction function of the
+// paymaster with the outcome of the execution, the cost of the gas used so far
+// and the context returned by the paymaster validation.
 func applyPaymasterPostOpFrame(st *StateTransition, aatx *types.Rip7560AccountAbstractionTx, vpr *ValidationPhaseResult, success bool, gasUsed uint64) *ExecutionResult {
 	var paymasterPostOpResult *ExecutionResult
-	paymasterPostOpMsg := preparePostOpMessage(vpr, success, gasUsed)
-	paymasterPostOpResult = CallFrame(st, &AA_ENTRY_POINT, aatx.Paymaster, paymasterPostOpMsg, aatx.PostOpGas)
+	paymasterPostOpMsg := preparePostOpMessage(st.evm.ChainConfig(), vpr, success, gasUsed)
+	paymasterPostOpResult = callFrame(st, FramePostOp, &AA_ENTRY_POINT, aatx.Paymaster, paymasterPostOpMsg, aatx.PostOpGas)
 	return paymasterPostOpResult
 }
 
@@ -623,6 +956,14 @@ func capRefund(getRefund uint64, gasUsed uint64) uint64 {
 	return refund
 }
 
+// rip7560GasPenalty returns the penalty charged for the unused part of the gas
+// limit of an execution or postOp frame, discouraging over-reservation.
+func rip7560GasPenalty(config *params.ChainConfig, gasLimit uint64, gasUsed uint64) uint64 {
+	return (gasLimit - gasUsed) * config.Rip7560GasPenaltyPercent() / 100
+}
+
+// ApplyRip7560ExecutionPhase runs the execution and postOp frames of a
+// validated RIP-7560 transaction and returns its receipt.
 func ApplyRip7560ExecutionPhase(
 	config *params.ChainConfig,
 	vpr *ValidationPhaseResult,
@@ -634,60 +975,102 @@ func ApplyRip7560ExecutionPhase(
 	cfg vm.Config,
 	usedGas *uint64,
 ) (*types.Receipt, error) {
+	txContext := vm.TxContext{GasPrice: vpr.EffectiveGasPrice.ToBig()}
+	evm := vm.NewEVM(NewEVMBlockContext(header, bc, author), txContext, statedb, config, cfg)
+	return ApplyRip7560ExecutionPhaseWithEVM(evm, vpr, gp, statedb, header, usedGas)
+}
 
-	blockContext := NewEVMBlockContext(header, bc, author)
+// ApplyRip7560ExecutionPhaseWithEVM is like ApplyRip7560ExecutionPhase, running
+// the frames on the given EVM, whose transaction context is reset.
+func ApplyRip7560ExecutionPhaseWithEVM(
+	evm *vm.EVM,
+	vpr *ValidationPhaseResult,
+	gp *GasPool,
+	statedb *state.StateDB,
+	header *types.Header,
+	usedGas *uint64,
+) (*types.Receipt, error) {
 	aatx := vpr.Tx.Rip7560TransactionData()
-	sender := aatx.Sender
-	txContext := vm.TxContext{
-		Origin:   *sender,
-		GasPrice: vpr.EffectiveGasPrice.ToBig(),
-	}
-	txContext.Origin = *aatx.Sender
-	evm := vm.NewEVM(blockContext, txContext, statedb, config, cfg)
+	evm.Reset(vm.TxContext{Origin: *aatx.Sender, GasPrice: vpr.EffectiveGasPrice.ToBig()}, statedb)
 	st := NewStateTransition(evm, nil, gp)
 	st.initialGas = math.MaxUint64
 	st.gasRemaining = math.MaxUint64
 
-	accountExecutionMsg := prepareAccountExecutionMessage(vpr.Tx)
-	beforeExecSnapshotId := statedb.Snapshot()
-	executionResult := CallFrame(st, &AA_ENTRY_POINT, sender, accountExecutionMsg, aatx.Gas)
+	// The changes of the validation phase are final at this point. A failed
+	// execution reverts its own changes only, while a failed postOp reverts
+	// the changes of both the execution and the postOp frames.
+	frames := vpr.logFrames
+	frameStatuses := vpr.validationFrameStatuses()
+	executionSnapshot := statedb.Snapshot()
+	executionResult := applyAccountExecutionFrames(st, aatx, func(frame string, result *ExecutionResult) {
+		frames.mark(statedb, frame)
+		frameStatuses = append(frameStatuses, newReceiptFrameStatus(frame, result))
+	})
+	if executionResult.Failed() {
+		statedb.RevertToSnapshot(executionSnapshot)
+		frames.mark(statedb, "")
+	}
 	receiptStatus := types.ReceiptStatusSuccessful
 	executionStatus := ExecutionStatusSuccess
-	execRefund := capRefund(st.state.GetRefund(), executionResult.UsedGas)
+
+	// Refunds are earned and capped separately by the validation, the execution
+	// and the postOp frames, so that clearing storage in one of them can't pay
+	// for the gas used by another. The refund counter starts from zero in the
+	// execution frames, as the validation phase is finalised.
+	execRefundCounter := st.state.GetRefund()
+	execRefund := capRefund(execRefundCounter, executionResult.UsedGas)
 	if executionResult.Failed() {
 		receiptStatus = types.ReceiptStatusFailed
 		executionStatus = ExecutionStatusExecutionFailure
 	}
-	executionGasPenalty := (aatx.Gas - executionResult.UsedGas) * AA_GAS_PENALTY_PCT / 100
+	executionGasPenalty := rip7560GasPenalty(evm.ChainConfig(), aatx.Gas, executionResult.UsedGas)
 
 	validationPhaseUsedGas, _ := vpr.validationPhaseUsedGas()
 	gasUsed := validationPhaseUsedGas +
 		executionResult.UsedGas +
 		executionGasPenalty
 
-	gasRefund := capRefund(execRefund+vpr.ValidationRefund, gasUsed)
+	validationRefund := capRefund(vpr.ValidationRefund, validationPhaseUsedGas)
 
-	var postOpGasUsed uint64
+	var postOpGasUsed, postOpRefund uint64
 	var paymasterPostOpResult *ExecutionResult
 	if len(vpr.PaymasterContext) != 0 {
-		paymasterPostOpResult = applyPaymasterPostOpFrame(st, aatx, vpr, !executionResult.Failed(), gasUsed-gasRefund)
+		// Reverting to a snapshot discards it, so a failed execution needs a
+		// new one to revert the postOp frame to
+		postOpSnapshot := executionSnapshot
+		if executionResult.Failed() {
+			postOpSnapshot = statedb.Snapshot()
+		}
+		paymasterPostOpResult = applyPaymasterPostOpFrame(st, aatx, vpr, !executionResult.Failed(), gasUsed-validationRefund-execRefund)
 		postOpGasUsed = paymasterPostOpResult.UsedGas
-		gasRefund += capRefund(paymasterPostOpResult.RefundedGas, postOpGasUsed)
+		frames.mark(statedb, FramePostOp)
+		frameStatuses = append(frameStatuses, newReceiptFrameStatus(FramePostOp, paymasterPostOpResult))
+
+		// The postOp frame may undo the storage clearing of the execution,
+		// taking away the refunds of the execution instead of earning its own
+		if counter := st.state.GetRefund(); counter < execRefundCounter {
+			execRefund = capRefund(counter, executionResult.UsedGas)
+		} else {
+			postOpRefund = capRefund(counter-execRefundCounter, postOpGasUsed)
+		}
 		// PostOp failed, reverting execution changes
 		if paymasterPostOpResult.Failed() {
-			statedb.RevertToSnapshot(beforeExecSnapshotId)
+			statedb.RevertToSnapshot(postOpSnapshot)
+			frames.mark(statedb, "")
+			execRefund, postOpRefund = 0, 0
 			receiptStatus = types.ReceiptStatusFailed
 			if executionStatus == ExecutionStatusExecutionFailure {
 				executionStatus = ExecutionStatusExecutionAndPostOpFailure
+			} else {
+				executionStatus = ExecutionStatusPostOpFailure
 			}
-			executionStatus = ExecutionStatusPostOpFailure
 		}
-		postOpGasPenalty := (aatx.PostOpGas - postOpGasUsed) * AA_GAS_PENALTY_PCT / 100
+		postOpGasPenalty := rip7560GasPenalty(evm.ChainConfig(), aatx.PostOpGas, postOpGasUsed)
 		postOpGasUsed += postOpGasPenalty
 		gasUsed += postOpGasUsed
 	}
-	gasUsed -= gasRefund
-	refundPayer(vpr, statedb, gasUsed)
+	gasUsed -= validationRefund + execRefund + postOpRefund
+	refundPayer(evm.ChainConfig(), vpr, statedb, gasUsed)
 	payCoinbase(st, aatx, gasUsed)
 
 	// Also return remaining gas to the block gas counter so it is
@@ -722,10 +1105,13 @@ func ApplyRip7560ExecutionPhase(
 		}
 	}
 
+	frames.mark(statedb, FrameEntryPoint)
+
 	// TODO: naming convention hell!!! 'usedGas' is 'CumulativeGasUsed' in block processing
 	*usedGas += gasUsed
 
-	receipt := &types.Receipt{Type: vpr.Tx.Type(), TxHash: vpr.Tx.Hash(), GasUsed: gasUsed, CumulativeGasUsed: *usedGas}
+	receipt := &types.Receipt{TxHash: vpr.Tx.Hash(), GasUsed: gasUsed, CumulativeGasUsed: *usedGas, ValidationGasUsed: validationPhaseUsedGas, FrameStatuses: frameStatuses, LogFrames: frames}
+	receipt.SetTxType(vpr.Tx)
 
 	receipt.Status = receiptStatus
 
@@ -733,6 +1119,7 @@ func ApplyRip7560ExecutionPhase(
 	blockNumber := header.Number
 	receipt.Logs = statedb.GetLogs(vpr.TxHash, blockNumber.Uint64(), common.Hash{})
 	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+	receipt.BlockNumber = blockNumber
 	receipt.TransactionIndex = uint(vpr.TxIndex)
 	// other fields are filled in DeriveFields (all tx, block fields, and updating CumulativeGasUsed
 	return receipt, nil
@@ -844,32 +1231,115 @@ func payCoinbase(st *StateTransition, msg *types.Rip7560AccountAbstractionTx, ga
 	}
 }
 
-func prepareAccountValidationMessage(tx *types.Rip7560AccountAbstractionTx, signingHash common.Hash) ([]byte, error) {
-	return abiEncodeValidateTransaction(tx, signingHash)
+func prepareAccountValidationMessage(config *params.ChainConfig, tx *types.Rip7560AccountAbstractionTx, signingHash common.Hash) ([]byte, error) {
+	frameABI, err := rip7560FrameABI(config)
+	if err != nil {
+		return nil, err
+	}
+	return abiEncodeValidateTransaction(frameABI, tx, signingHash)
 }
 
-func preparePaymasterValidationMessage(tx *types.Rip7560AccountAbstractionTx, signingHash common.Hash) ([]byte, error) {
-	if tx.Paymaster == nil || tx.Paymaster.Cmp(common.Address{}) == 0 {
+func preparePaymasterValidationMessage(config *params.ChainConfig, tx *types.Rip7560AccountAbstractionTx, signingHash common.Hash) ([]byte, error) {
+	if !tx.IsSponsored() {
 		return nil, nil
 	}
-	return abiEncodeValidatePaymasterTransaction(tx, signingHash)
+	frameABI, err := rip7560FrameABI(config)
+	if err != nil {
+		return nil, err
+	}
+	return abiEncodeValidatePaymasterTransaction(frameABI, tx, signingHash)
 }
 
-func prepareAccountExecutionMessage(baseTx *types.Transaction) []byte {
-	tx := baseTx.Rip7560TransactionData()
-	return tx.ExecutionData
+// applyAccountExecutionFrames calls the sender once for every execution frame
+// of the transaction, in order. The frames share the call gas limit of the
+// transaction and the first failing frame aborts the remaining ones, so the
+// caller is expected to revert all of them. The returned result carries the
+// total gas used by the frames and the return data of the last one. The done
+// callback is invoked with the name and the result of every frame once it
+// returns.
+func applyAccountExecutionFrames(st *StateTransition, aatx *types.Rip7560AccountAbstractionTx, done func(frame string, result *ExecutionResult)) *ExecutionResult {
+	var (
+		frames = aatx.ExecutionFrames()
+		result = &ExecutionResult{}
+	)
+	for i, data := range frames {
+		name := ExecutionFrameName(i, len(frames))
+		frame := callFrame(st, name, &AA_ENTRY_POINT, aatx.Sender, data, aatx.Gas-result.UsedGas)
+		done(name, frame)
+		result.UsedGas += frame.UsedGas
+		result.ReturnData = frame.ReturnData
+		if frame.Failed() {
+			log.Debug("RIP-7560 execution frame failed", "sender", aatx.Sender, "frame", name, "err", frame.Err)
+			result.Err = frame.Err
+			break
+		}
+	}
+	return result
 }
 
-func preparePostOpMessage(vpr *ValidationPhaseResult, success bool, gasUsed uint64) []byte {
-	return abiEncodePostPaymasterTransaction(success, gasUsed, vpr.PaymasterContext)
+// validationFrameStatuses lists the validation frames run by the transaction in
+// order, all of which succeeded for it to pass validation.
+func (vpr *ValidationPhaseResult) validationFrameStatuses() []types.ReceiptFrameStatus {
+	var (
+		aatx   = vpr.Tx.Rip7560TransactionData()
+		frames []string
+	)
+	if aatx.IsRip7712Nonce() {
+		frames = append(frames, FrameNonceManager)
+	}
+	if aatx.Deployer != nil {
+		frames = append(frames, FrameDeployer)
+	}
+	frames = append(frames, FrameAccount)
+	if aatx.IsSponsored() {
+		frames = append(frames, FramePaymaster)
+	}
+	statuses := make([]types.ReceiptFrameStatus, len(frames))
+	for i, frame := range frames {
+		statuses[i] = types.ReceiptFrameStatus{Frame: frame, Status: types.ReceiptStatusSuccessful}
+	}
+	return statuses
+}
+
+// newReceiptFrameStatus reports the outcome of a frame in the receipt.
+func newReceiptFrameStatus(frame string, result *ExecutionResult) types.ReceiptFrameStatus {
+	status := types.ReceiptFrameStatus{Frame: frame, Status: types.ReceiptStatusSuccessful}
+	if result.Failed() {
+		status.Status = types.ReceiptStatusFailed
+	}
+	return status
+}
+
+// ExecutionFrameName labels the execution frame at the given index of a
+// transaction with the given number of execution frames.
+func ExecutionFrameName(index int, frames int) string {
+	if frames == 1 {
+		return "execution"
+	}
+	return fmt.Sprintf("execution[%d]", index)
 }
 
-func validateAccountEntryPointCall(epc *EntryPointCall, sender *common.Address) (*AcceptAccountData, error) {
+// preparePostOpMessage returns the calldata of the postOp frame, charging the
+// gas used at the effective gas price of the transaction. The version of the
+// interfaces was checked when encoding the validation frames.
+func preparePostOpMessage(config *params.ChainConfig, vpr *ValidationPhaseResult, success bool, gasUsed uint64) []byte {
+	frameABI, err := rip7560FrameABI(config)
+	if err != nil {
+		panic(err) // can't happen, the paymaster validation frame was encoded
+	}
+	actualGasCost := new(uint256.Int).Mul(uint256.NewInt(gasUsed), vpr.EffectiveGasPrice)
+	return abiEncodePostPaymasterTransaction(frameABI, success, actualGasCost, vpr.PaymasterContext)
+}
+
+// validateAccountEntryPointCall returns the acceptance of the account, either
+// passed to the EntryPoint 'acceptAccount' callback or, if the account didn't
+// call it, returned by the validation frame.
+func validateAccountEntryPointCall(epc *EntryPointCall, sender *common.Address, returnData []byte) (*AcceptAccountData, error) {
 	if epc.err != nil {
 		return nil, epc.err
 	}
 	if epc.Input == nil {
-		return nil, errors.New("account validation did not call the EntryPoint 'acceptAccount' callback")
+		return abiDecodeAccountReturnData(returnData)
 	}
 	if epc.From.Cmp(*sender) != 0 {
 		return nil, errors.New("invalid call to EntryPoint contract from a wrong account address")
@@ -877,12 +1347,15 @@ func validateAccountEntryPointCall(epc *EntryPointCall, sender *common.Address)
 	return abiDecodeAcceptAccount(epc.Input, false)
 }
 
-func validatePaymasterEntryPointCall(epc *EntryPointCall, paymaster *common.Address) (*AcceptPaymasterData, error) {
+// validatePaymasterEntryPointCall returns the acceptance of the paymaster, either
+// passed to the EntryPoint 'acceptPaymaster' callback or, if the paymaster didn't
+// call it, returned by the validation frame.
+func validatePaymasterEntryPointCall(epc *EntryPointCall, paymaster *common.Address, returnData []byte) (*AcceptPaymasterData, error) {
 	if epc.err != nil {
 		return nil, epc.err
 	}
 	if epc.Input == nil {
-		return nil, errors.New("paymaster validation did not call the EntryPoint 'acceptPaymaster' callback")
+		return abiDecodePaymasterReturnData(returnData)
 	}
 
 	if epc.From.Cmp(*paymaster) != 0 {
@@ -895,26 +1368,31 @@ func validatePaymasterEntryPointCall(epc *EntryPointCall, paymaster *common.Addr
 	return apd, nil
 }
 
+// validateValidityTimeRange checks that validAfter <= time < validUntil, with a
+// zero validUntil meaning the transaction doesn't expire.
 func validateValidityTimeRange(time uint64, validAfter uint64, validUntil uint64) error {
-	if validUntil == 0 && validAfter == 0 {
-		return nil
-	}
-	if validUntil < validAfter {
-		return errors.New("RIP-7560 transaction validity range invalid")
-	}
-	if time > validUntil {
-		return errors.New("RIP-7560 transaction validity expired")
+	if validUntil != 0 && validUntil <= validAfter {
+		return fmt.Errorf("%w: RIP-7560 transaction validity range invalid", ErrAAValidityRange)
 	}
 	if time < validAfter {
-		return errors.New("RIP-7560 transaction validity not reached yet")
+		return fmt.Errorf("%w: RIP-7560 transaction validity not reached yet", ErrAAValidityRange)
+	}
+	if validUntil != 0 && time >= validUntil {
+		return fmt.Errorf("%w: RIP-7560 transaction validity expired", ErrAAValidityRange)
 	}
 	return nil
 }
 
-func (epc *EntryPointCall) OnEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
-	if epc.OnEnterSuper != nil {
-		epc.OnEnterSuper(depth, typ, from, to, input, gas, value)
+// validityBound returns a bound of the validity range accepted by a validation
+// frame, saturating the bounds beyond any representable block time.
+func validityBound(bound *big.Int) uint64 {
+	if !bound.IsUint64() {
+		return math.MaxUint64
 	}
+	return bound.Uint64()
+}
+
+func (epc *EntryPointCall) OnEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
 	isRip7560EntryPoint := to.Cmp(AA_ENTRY_POINT) == 0
 	if !isRip7560EntryPoint {
 		return
@@ -0,0 +1,993 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/aa"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/holiman/uint256"
+)
+
+// Tests that the postOp frame is called with the outcome of the execution, the
+// cost of the gas used before it and the context of the paymaster.
+func TestRip7560PostOpCalldata(t *testing.T) {
+	var (
+		sender    = common.Address{0xaa}
+		paymaster = common.Address{0xbb}
+		header    = rip7560TestHeader()
+	)
+	header.BaseFee = big.NewInt(1)
+
+	// The postOp frame stores the success flag, the gas cost and the first word
+	// of the context in its first three slots
+	postOp := []byte{
+		byte(vm.PUSH1), 4, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 0, byte(vm.SSTORE),
+		byte(vm.PUSH1), 36, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 1, byte(vm.SSTORE),
+		byte(vm.PUSH1), 132, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 2, byte(vm.SSTORE),
+	}
+	tests := []struct {
+		name    string
+		gas     uint64
+		success bool
+	}{
+		{"execution success", 100_000, true},
+		{"execution failure", 0, false},
+	}
+	for _, tt := range tests {
+		statedb := newRip7560TestState()
+		setRip7560TestSender(statedb, sender)
+		setRip7560TestPaymaster(statedb, paymaster, postOp...)
+
+		tx := types.NewTx(newRip7560TestTx(sender, withRip7560TestPaymaster(paymaster), func(aatx *types.Rip7560AccountAbstractionTx) {
+			aatx.GasTipCap, aatx.GasFeeCap, aatx.Gas = big.NewInt(2), big.NewInt(3), tt.gas
+		}))
+		receipt, err := applyRip7560TestTx(statedb, header, tx, vm.Config{})
+		if err != nil {
+			t.Fatalf("%s: failed to apply transaction: %v", tt.name, err)
+		}
+		if success := statedb.GetState(paymaster, common.Hash{}).Big().Sign() != 0; success != tt.success {
+			t.Errorf("%s: success flag mismatch: have %v, want %v", tt.name, success, tt.success)
+		}
+		// The cost is charged at the effective gas price of 3 wei, for the gas
+		// used before the postOp frame
+		cost := statedb.GetState(paymaster, common.BigToHash(big.NewInt(1))).Big()
+		if cost.Sign() == 0 || new(big.Int).Mod(cost, big.NewInt(3)).Sign() != 0 || cost.Uint64() >= 3*receipt.GasUsed {
+			t.Errorf("%s: gas cost mismatch: have %v, total %d gas", tt.name, cost, receipt.GasUsed)
+		}
+		if context := statedb.GetState(paymaster, common.BigToHash(big.NewInt(2))); context != (common.Hash{0xff}) {
+			t.Errorf("%s: context mismatch: have %x", tt.name, context)
+		}
+	}
+}
+
+// Tests that the gas refunds earned by the validation, the execution and the
+// postOp frames are capped by the gas used by the same frames, so that clearing
+// storage in one of them can't pay for another.
+func TestRip7560RefundSeparation(t *testing.T) {
+	var (
+		sender    = common.Address{0xaa}
+		paymaster = common.Address{0xbb}
+	)
+	// store returns the code writing the value to the given storage slots
+	store := func(value byte, from, to byte) []byte {
+		var code []byte
+		for slot := from; slot < to; slot++ {
+			code = append(code, byte(vm.PUSH1), value, byte(vm.PUSH1), slot, byte(vm.SSTORE))
+		}
+		return code
+	}
+	// account returns the code prefix of an account running the given code in
+	// the execution frame, which is the only one called with a single byte, and
+	// in the validation frame
+	account := func(execution, validation []byte) []byte {
+		code := []byte{byte(vm.CALLDATASIZE), byte(vm.PUSH1), 1, byte(vm.EQ), byte(vm.ISZERO), byte(vm.PUSH1), byte(len(execution) + 9), byte(vm.JUMPI)}
+		code = append(code, execution...)
+		code = append(code, byte(vm.STOP), byte(vm.JUMPDEST))
+		return append(code, validation...)
+	}
+	var (
+		clear  = store(0, 0, 10)    // Clears the ten slots set up front, earning 48000 gas
+		burn   = store(1, 100, 105) // Writes five new slots, earning nothing
+		revert = []byte{byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.REVERT)}
+	)
+	tests := []struct {
+		name       string
+		account    []byte
+		postOp     []byte // Code of the postOp frame, no paymaster if nil
+		refundFrom string // Frame whose gas caps the refund, none if empty
+	}{
+		{"validation", account(burn, clear), nil, FrameAccount},
+		{"execution", account(clear, nil), burn, "execution"},
+		{"postOp", account(burn, nil), clear, FramePostOp},
+		{"reverted execution", account(clear, nil), append(burn, revert...), ""},
+	}
+	for _, tt := range tests {
+		statedb := newRip7560TestState()
+		setRip7560TestSender(statedb, sender, tt.account...)
+		for slot := int64(0); slot < 10; slot++ {
+			statedb.SetState(sender, common.BigToHash(big.NewInt(slot)), common.Hash{31: 1})
+			statedb.SetState(paymaster, common.BigToHash(big.NewInt(slot)), common.Hash{31: 1})
+		}
+		aatx := newRip7560TestTx(sender, func(aatx *types.Rip7560AccountAbstractionTx) {
+			aatx.Gas, aatx.ValidationGasLimit = 500_000, 500_000
+		})
+		if tt.postOp != nil {
+			setRip7560TestPaymaster(statedb, paymaster, tt.postOp...)
+			aatx.Paymaster, aatx.PaymasterValidationGasLimit, aatx.PostOpGas = &paymaster, 500_000, 500_000
+		}
+		// Collect the gas used by every frame to compute the expected charge
+		used := make(map[string]uint64)
+		hooks := &tracing.Hooks{
+			OnAAFrameEnd: func(frame string, output []byte, gasUsed uint64, err error) {
+				used[frame] = gasUsed
+			},
+		}
+		receipt, err := applyRip7560TestTx(statedb, rip7560TestHeader(), types.NewTx(aatx), vm.Config{Tracer: hooks})
+		if err != nil {
+			t.Fatalf("%s: failed to apply transaction: %v", tt.name, err)
+		}
+		preTransactionGas, _ := aatx.PreTransactionGasCost()
+		validationUsed := preTransactionGas + used[FrameAccount]
+		if tt.postOp != nil {
+			validationUsed += used[FramePaymaster] + paymasterContextGas([]byte{0xff})
+		}
+		frameUsed := map[string]uint64{FrameAccount: validationUsed, "execution": used["execution"], FramePostOp: used[FramePostOp]}
+
+		want := validationUsed + used["execution"] + (aatx.Gas-used["execution"])*params.Rip7560GasPenaltyPercent/100
+		if tt.postOp != nil {
+			want += used[FramePostOp] + (aatx.PostOpGas-used[FramePostOp])*params.Rip7560GasPenaltyPercent/100
+		}
+		if tt.refundFrom != "" {
+			want -= min(10*params.SstoreClearsScheduleRefundEIP3529, frameUsed[tt.refundFrom]/params.RefundQuotientEIP3529)
+		}
+		if receipt.GasUsed != want {
+			t.Errorf("%s: gas used mismatch: have %d, want %d", tt.name, receipt.GasUsed, want)
+		}
+	}
+}
+
+// Tests that a failed postOp frame reverts the changes of the execution and the
+// postOp frames, but retains the ones of the validation phase.
+func TestRip7560PostOpRevert(t *testing.T) {
+	var (
+		sender    = common.Address{0xaa}
+		paymaster = common.Address{0xbb}
+		header    = rip7560TestHeader()
+	)
+	// The account writes its storage if called with a single byte of data,
+	// which is only the case in the execution frame
+	account := []byte{byte(vm.CALLDATASIZE), byte(vm.PUSH1), 1, byte(vm.EQ), byte(vm.ISZERO), byte(vm.PUSH1), 14, byte(vm.JUMPI),
+		byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP), byte(vm.JUMPDEST)}
+
+	tests := []struct {
+		name      string
+		gas       uint64
+		status    uint64
+		execution uint64
+	}{
+		{"execution success", 100_000, ExecutionStatusPostOpFailure, types.ReceiptStatusSuccessful},
+		{"execution failure", 10_000, ExecutionStatusExecutionAndPostOpFailure, types.ReceiptStatusFailed},
+	}
+	for _, tt := range tests {
+		statedb := newRip7560TestState()
+		setRip7560TestSender(statedb, sender, account...)
+		statedb.SetCode(paymaster, rip7560PaymasterCode())
+		statedb.SetBalance(paymaster, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+
+		tx := types.NewTx(newRip7560TestTx(sender, withRip7560TestPaymaster(paymaster), func(aatx *types.Rip7560AccountAbstractionTx) {
+			aatx.Gas = tt.gas
+		}))
+		receipt, err := applyRip7560TestTx(statedb, header, tx, vm.Config{})
+		if err != nil {
+			t.Fatalf("%s: failed to apply transaction: %v", tt.name, err)
+		}
+		if receipt.Status != types.ReceiptStatusFailed {
+			t.Errorf("%s: receipt status mismatch: have %d, want failed", tt.name, receipt.Status)
+		}
+		if status := new(big.Int).SetBytes(receipt.Logs[0].Data[64:]).Uint64(); status != tt.status {
+			t.Errorf("%s: execution status mismatch: have %d, want %d", tt.name, status, tt.status)
+		}
+		frames := []types.ReceiptFrameStatus{
+			{Frame: FrameAccount, Status: types.ReceiptStatusSuccessful},
+			{Frame: FramePaymaster, Status: types.ReceiptStatusSuccessful},
+			{Frame: "execution", Status: tt.execution},
+			{Frame: FramePostOp, Status: types.ReceiptStatusFailed},
+		}
+		if !reflect.DeepEqual(receipt.FrameStatuses, frames) {
+			t.Errorf("%s: frame status mismatch: have %v, want %v", tt.name, receipt.FrameStatuses, frames)
+		}
+		if value := statedb.GetState(sender, common.Hash{}); value != (common.Hash{}) {
+			t.Errorf("%s: execution changes not reverted", tt.name)
+		}
+		if value := statedb.GetState(paymaster, common.Hash{}); value != (common.Hash{}) {
+			t.Errorf("%s: postOp changes not reverted", tt.name)
+		}
+		if nonce := statedb.GetNonce(sender); nonce != 1 {
+			t.Errorf("%s: validation changes reverted, nonce %d", tt.name, nonce)
+		}
+		if balance := statedb.GetBalance(paymaster); balance.Cmp(uint256.NewInt(params.Ether-receipt.GasUsed)) != 0 {
+			t.Errorf("%s: paymaster balance mismatch: have %v, want %d", tt.name, balance, params.Ether-receipt.GasUsed)
+		}
+	}
+}
+
+// Tests that the simulation of a transaction attributes the gas used by the
+// execution and the postOp frames to their respective limits, and reports the
+// revert of the postOp frame.
+func TestSimulateRip7560Transaction(t *testing.T) {
+	var (
+		sender    = common.Address{0xaa}
+		paymaster = common.Address{0xbb}
+		statedb   = newRip7560TestState()
+	)
+	setRip7560TestSender(statedb, sender, byte(vm.CALLDATASIZE), byte(vm.PUSH1), 1, byte(vm.EQ), byte(vm.ISZERO), byte(vm.PUSH1), 14, byte(vm.JUMPI),
+		byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP), byte(vm.JUMPDEST))
+	statedb.SetCode(paymaster, rip7560PaymasterCode())
+	statedb.SetBalance(paymaster, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+
+	tx := types.NewTx(newRip7560TestTx(sender, withRip7560TestPaymaster(paymaster)))
+	gas, receipt, err := SimulateRip7560Transaction(params.AllDevChainProtocolChanges, nil, rip7560TestHeader(), statedb, tx, vm.Config{})
+	if !errors.Is(err, ErrAAPaymasterPostOpReverted) {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrAAPaymasterPostOpReverted)
+	}
+	var vpe *ValidationPhaseError
+	if !errors.As(err, &vpe) || vpe.Frame() != FramePostOp || vpe.ErrorCode() != -32501 {
+		t.Fatalf("postOp error mismatch: %v", err)
+	}
+	if uint64(gas.ValidationGas) < params.Rip7560TxGas {
+		t.Errorf("validation gas %d below intrinsic gas", gas.ValidationGas)
+	}
+	if gas.PaymasterValidationGas == 0 {
+		t.Error("paymaster validation gas not accounted")
+	}
+	if uint64(gas.CallGas) < params.SstoreSetGas {
+		t.Errorf("call gas %d below storage write cost", gas.CallGas)
+	}
+	if uint64(gas.PostOpGas) < params.SstoreSetGas {
+		t.Errorf("postOp gas %d below storage write cost", gas.PostOpGas)
+	}
+	if total := uint64(gas.ValidationGas + gas.PaymasterValidationGas + gas.CallGas + gas.PostOpGas); total > receipt.GasUsed {
+		t.Errorf("frame gas %d above receipt gas %d", total, receipt.GasUsed)
+	}
+}
+
+// Tests that the dry run of a transaction reports every frame, the output and
+// the logs of the execution.
+func TestCallRip7560Transaction(t *testing.T) {
+	var (
+		sender  = common.Address{0xaa}
+		statedb = newRip7560TestState()
+	)
+	// The account logs and returns its calldata when executed, which is longer
+	// than the one of the validation
+	setRip7560TestSender(statedb, sender, byte(vm.CALLDATASIZE), byte(vm.PUSH1), 1, byte(vm.EQ), byte(vm.ISZERO), byte(vm.PUSH1), 22, byte(vm.JUMPI),
+		byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.DUP1), byte(vm.CALLDATACOPY), byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.DUP2), byte(vm.DUP2), byte(vm.LOG0), byte(vm.RETURN),
+		byte(vm.JUMPDEST))
+
+	tx := types.NewTx(newRip7560TestTx(sender, func(aatx *types.Rip7560AccountAbstractionTx) {
+		aatx.GasTipCap, aatx.GasFeeCap, aatx.ExecutionData = new(big.Int), new(big.Int), []byte{0x2a}
+	}))
+	result, err := CallRip7560Transaction(params.AllDevChainProtocolChanges, nil, rip7560TestHeader(), statedb, tx, vm.Config{NoBaseFee: true})
+	if err != nil {
+		t.Fatalf("failed to call transaction: %v", err)
+	}
+	if result.Status != hexutil.Uint64(types.ReceiptStatusSuccessful) || result.Error != "" {
+		t.Fatalf("call failed: status %d, error %q", result.Status, result.Error)
+	}
+	if !bytes.Equal(result.ReturnData, []byte{0x2a}) {
+		t.Errorf("return data mismatch: have %x, want 2a", result.ReturnData)
+	}
+	// The execution log is followed by the event of the transaction
+	if len(result.Logs) == 0 || result.Logs[0].Address != sender || !bytes.Equal(result.Logs[0].Data, []byte{0x2a}) {
+		t.Errorf("execution log missing: %v", result.Logs)
+	}
+	var names []string
+	for _, frame := range result.Frames {
+		names = append(names, frame.Name)
+	}
+	if want := []string{FrameAccount, "execution"}; !slices.Equal(names, want) {
+		t.Errorf("frames mismatch: have %v, want %v", names, want)
+	}
+	if result.Frames[1].GasUsed != result.FrameGas.CallGas {
+		t.Errorf("execution gas mismatch: have %d, want %d", result.Frames[1].GasUsed, result.FrameGas.CallGas)
+	}
+}
+
+// Tests that RIP-7712 nonces are validated and incremented by the NonceManager
+// system contract, which has to be deployed.
+func TestRip7712NonceManager(t *testing.T) {
+	var (
+		config   = params.AllDevChainProtocolChanges
+		sender   = common.Address{0xaa}
+		coinbase = common.Address{0xcc}
+		key      = big.NewInt(5)
+		header   = rip7560TestHeader()
+		statedb  = newRip7560TestState()
+	)
+	setRip7560TestSender(statedb, sender)
+
+	apply := func(nonce uint64) error {
+		tx := types.NewTx(newRip7560TestTx(sender, func(aatx *types.Rip7560AccountAbstractionTx) {
+			aatx.Nonce, aatx.NonceKey, aatx.ExecutionData, aatx.ValidationGasLimit = nonce, key, nil, 200_000
+		}))
+		_, err := applyRip7560TestTx(statedb, header, tx, vm.Config{})
+		return err
+	}
+	if err := apply(0); err == nil {
+		t.Fatal("transaction accepted without NonceManager")
+	}
+	statedb.SetCode(params.Rip7712NonceManagerAddress, params.Rip7712NonceManagerCode)
+	if err := apply(0); err != nil {
+		t.Fatalf("failed to apply transaction: %v", err)
+	}
+	evm := vm.NewEVM(NewEVMBlockContext(header, nil, &coinbase), vm.TxContext{}, statedb, config, vm.Config{})
+	if nonce, err := GetRip7712Nonce(evm, sender, key); err != nil || nonce != 1 {
+		t.Fatalf("nonce mismatch: have %d (%v), want 1", nonce, err)
+	}
+	if nonce, err := GetRip7712Nonce(evm, sender, big.NewInt(6)); err != nil || nonce != 0 {
+		t.Fatalf("nonce of other key mismatch: have %d (%v), want 0", nonce, err)
+	}
+	// The nonces are stored in a Solidity mapping(address => mapping(uint192 => uint256))
+	inner := crypto.Keccak256(common.LeftPadBytes(sender.Bytes(), 32), make([]byte, 32))
+	slot := common.BytesToHash(crypto.Keccak256(common.LeftPadBytes(key.Bytes(), 32), inner))
+	if value := statedb.GetState(params.Rip7712NonceManagerAddress, slot); value != common.BigToHash(common.Big1) {
+		t.Fatalf("nonce storage mismatch: have %x, want 1", value)
+	}
+	if err := apply(0); !errors.Is(err, ErrNonceTooLow) {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrNonceTooLow)
+	}
+	if err := apply(2); !errors.Is(err, ErrNonceTooHigh) {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrNonceTooHigh)
+	}
+	if err := apply(1); err != nil {
+		t.Fatalf("failed to apply next transaction: %v", err)
+	}
+}
+
+// Tests that the deployer frame has to deploy the sender, whatever the deployer
+// returns.
+func TestRip7560DeployerFrame(t *testing.T) {
+	var (
+		deployer = common.Address{0xdd}
+		other    = common.Address{0xee}
+	)
+	account := rip7560AccountCode()
+	initcode := append([]byte{byte(vm.PUSH1), byte(len(account)), byte(vm.DUP1), byte(vm.PUSH1), 11, byte(vm.PUSH1), 0, byte(vm.CODECOPY),
+		byte(vm.PUSH1), 0, byte(vm.RETURN)}, account...)
+	sender := crypto.CreateAddress2(deployer, [32]byte{}, crypto.Keccak256(initcode))
+
+	// deployerCode returns the code deploying the account with CREATE2 and
+	// executing the given code with the deployed address on the stack
+	deployerCode := func(tail ...byte) []byte {
+		create := []byte{byte(vm.PUSH1), 0, byte(vm.PUSH1), byte(len(initcode)), byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.CREATE2)}
+		code := []byte{byte(vm.PUSH1), byte(len(initcode)), byte(vm.PUSH1), byte(7 + len(create) + len(tail)), byte(vm.PUSH1), 0, byte(vm.CODECOPY)}
+		code = append(append(code, create...), tail...)
+		return append(code, initcode...)
+	}
+	returnTop := []byte{byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 0x20, byte(vm.PUSH1), 0, byte(vm.RETURN)}
+	returnOther := append(append([]byte{byte(vm.POP), byte(vm.PUSH20)}, other.Bytes()...), returnTop...)
+
+	tests := []struct {
+		name string
+		code []byte
+		err  error
+	}{
+		{"no deployment", []byte{byte(vm.STOP)}, ErrSenderNotDeployed},
+		{"deployment", deployerCode(byte(vm.STOP)), nil},
+		{"deployment returning sender", deployerCode(returnTop...), nil},
+		{"deployment returning other", deployerCode(returnOther...), nil},
+	}
+	for _, tt := range tests {
+		statedb := newRip7560TestState()
+		statedb.SetBalance(sender, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+		statedb.SetCode(deployer, tt.code)
+
+		tx := types.NewTx(newRip7560TestTx(sender, func(aatx *types.Rip7560AccountAbstractionTx) {
+			aatx.Deployer, aatx.ExecutionData, aatx.ValidationGasLimit = &deployer, nil, 1_000_000
+		}))
+		_, err := applyRip7560TestTx(statedb, rip7560TestHeader(), tx, vm.Config{})
+		if tt.err == nil && err != nil {
+			t.Errorf("%s: failed to apply transaction: %v", tt.name, err)
+		}
+		if tt.err != nil && !errors.Is(err, tt.err) {
+			t.Errorf("%s: error mismatch: have %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
+
+// Tests that validation frames can accept a transaction by returning the packed
+// validation data instead of calling the EntryPoint.
+func TestRip7560ValidationReturnData(t *testing.T) {
+	var (
+		sender    = common.Address{0x5e}
+		paymaster = common.Address{0x9a}
+		header    = rip7560TestHeader()
+	)
+	header.Time = 1000
+
+	// returnCode returns the code returning the given word
+	returnCode := func(word []byte) []byte {
+		code := append([]byte{byte(vm.PUSH32)}, word...)
+		return append(code, byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN))
+	}
+	tests := []struct {
+		name      string
+		account   []byte
+		paymaster []byte
+		err       string
+	}{
+		{"accepted", returnCode(PackValidationData(AcceptAccountMethodSig, 0, 0)), nil, ""},
+		{"accepted in range", returnCode(PackValidationData(AcceptAccountMethodSig, 1001, 1000)), nil, ""},
+		{"not reached yet", returnCode(PackValidationData(AcceptAccountMethodSig, 2000, 1001)), nil, "validity not reached yet"},
+		{"expired", returnCode(PackValidationData(AcceptAccountMethodSig, 1000, 0)), nil, "validity expired"},
+		{"signature failure", returnCode(PackValidationData(SigFailAccountMethodSig, 0, 0)), nil, "account signature error"},
+		{"wrong magic", returnCode(PackValidationData(AcceptPaymasterMethodSig, 0, 0)), nil, "account did not return correct MAGIC_VALUE"},
+		{"no return data", []byte{byte(vm.STOP)}, nil, "account return data: too short"},
+		{"paymaster accepted", rip7560AccountCode(), returnCode(PackValidationData(AcceptPaymasterMethodSig, 0, 0)), ""},
+		{"paymaster expired", rip7560AccountCode(), returnCode(PackValidationData(AcceptPaymasterMethodSig, 999, 0)), "validity expired"},
+		{"paymaster wrong magic", rip7560AccountCode(), returnCode(PackValidationData(AcceptAccountMethodSig, 0, 0)), "paymaster did not return correct MAGIC_VALUE"},
+	}
+	for _, tt := range tests {
+		statedb := newRip7560TestState()
+		statedb.SetBalance(sender, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+		statedb.SetCode(sender, tt.account)
+
+		aatx := newRip7560TestTx(sender, func(aatx *types.Rip7560AccountAbstractionTx) { aatx.ExecutionData = nil })
+		if tt.paymaster != nil {
+			statedb.SetBalance(paymaster, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+			statedb.SetCode(paymaster, tt.paymaster)
+			aatx.Paymaster = &paymaster
+			aatx.PaymasterValidationGasLimit = 100_000
+		}
+		_, err := applyRip7560TestTx(statedb, header, types.NewTx(aatx), vm.Config{})
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: failed to apply transaction: %v", tt.name, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s: error mismatch: have %v, want %q", tt.name, err, tt.err)
+		}
+	}
+}
+
+// Tests that the account validation frame is called with the validateTransaction
+// calldata: the ABI version, the signing hash and the ABI encoded transaction,
+// letting the account verify the signature in its authorization data.
+func TestRip7560AccountValidationCalldata(t *testing.T) {
+	var (
+		sender   = common.Address{0x5e}
+		coinbase = common.Address{0xcc}
+		header   = rip7560TestHeader()
+	)
+	// The account stores the version, the signing hash and the hash of the whole
+	// calldata in its first three slots before accepting the transaction
+	account := rip7560AccountCode(
+		byte(vm.PUSH1), 4, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 0, byte(vm.SSTORE),
+		byte(vm.PUSH1), 36, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 1, byte(vm.SSTORE),
+		byte(vm.CALLDATASIZE), byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.CALLDATACOPY),
+		byte(vm.CALLDATASIZE), byte(vm.PUSH1), 0, byte(vm.KECCAK256), byte(vm.PUSH1), 2, byte(vm.SSTORE),
+	)
+	statedb := newRip7560TestState()
+	statedb.SetBalance(sender, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+	statedb.SetCode(sender, account)
+
+	key, _ := crypto.GenerateKey()
+	tx, err := signRip7560TestTx(newRip7560TestTx(sender, func(aatx *types.Rip7560AccountAbstractionTx) {
+		aatx.ExecutionData, aatx.ValidationGasLimit = []byte{0x01, 0x02}, 200_000
+	}), key)
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+
+	// Only the validation phase runs, as the execution frame calls the same code
+	if _, err := ApplyRip7560ValidationPhases(params.AllDevChainProtocolChanges, nil, &coinbase, new(GasPool).AddGas(header.GasLimit), statedb, header, tx, vm.Config{}); err != nil {
+		t.Fatalf("failed to validate transaction: %v", err)
+	}
+	signingHash := types.MakeSigner(params.AllDevChainProtocolChanges, header.Number, header.Time).Hash(tx)
+	calldata := rip7560TestValidationCalldata(t, tx)
+	if version := statedb.GetState(sender, common.Hash{}).Big(); version.Uint64() != aa.LatestVersion {
+		t.Errorf("version mismatch: have %v, want %d", version, aa.LatestVersion)
+	}
+	if hash := statedb.GetState(sender, common.BigToHash(big.NewInt(1))); hash != signingHash {
+		t.Errorf("signing hash mismatch: have %x, want %x", hash, signingHash)
+	}
+	if hash := statedb.GetState(sender, common.BigToHash(big.NewInt(2))); hash != crypto.Keccak256Hash(calldata) {
+		t.Errorf("calldata hash mismatch: have %x, want %x", hash, crypto.Keccak256Hash(calldata))
+	}
+}
+
+// Tests that paymasters returning the validation data can return a context.
+func TestRip7560PaymasterReturnData(t *testing.T) {
+	word := PackValidationData(AcceptPaymasterMethodSig, 20, 10)
+	ret, err := paymasterReturnArgs.Pack([32]byte(word), []byte{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	apd, err := abiDecodePaymasterReturnData(ret)
+	if err != nil {
+		t.Fatalf("failed to decode return data: %v", err)
+	}
+	if apd.ValidAfter.Uint64() != 10 || apd.ValidUntil.Uint64() != 20 || !bytes.Equal(apd.Context, []byte{1, 2, 3}) {
+		t.Errorf("return data mismatch: have after %v until %v context %x", apd.ValidAfter, apd.ValidUntil, apd.Context)
+	}
+	if _, err := abiDecodePaymasterReturnData(ret[:48]); err == nil {
+		t.Errorf("truncated return data accepted")
+	}
+}
+
+// Tests that the failures of the validation phase are classified by their kind
+// and the frame they occurred in.
+func TestRip7560ErrorKinds(t *testing.T) {
+	var (
+		sender    = common.Address{0x5e}
+		paymaster = common.Address{0x9a}
+		deployer  = common.Address{0xdd}
+		header    = rip7560TestHeader()
+	)
+	header.Time = 1000
+
+	revert := []byte{byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.REVERT)}
+	expired := append(append([]byte{byte(vm.PUSH32)}, PackValidationData(AcceptAccountMethodSig, 999, 0)...),
+		byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN))
+
+	tests := []struct {
+		name      string
+		nonce     uint64
+		account   []byte
+		paymaster []byte
+		deployer  []byte
+		kind      error
+		frame     string
+		code      int
+	}{
+		{"nonce", 1, rip7560AccountCode(), nil, nil, ErrAANonceMismatch, FrameNonceManager, -32500},
+		{"deployment", 0, nil, nil, []byte{byte(vm.STOP)}, ErrAADeploymentFailed, FrameDeployer, -32500},
+		{"account revert", 0, revert, nil, nil, ErrAAValidationReverted, FrameAccount, -32500},
+		{"account rejection", 0, []byte{byte(vm.STOP)}, nil, nil, ErrAAValidationRejected, FrameAccount, -32500},
+		{"account expired", 0, expired, nil, nil, ErrAAValidityRange, FrameAccount, -32503},
+		{"paymaster revert", 0, rip7560AccountCode(), revert, nil, ErrAAValidationReverted, FramePaymaster, -32501},
+	}
+	for _, tt := range tests {
+		statedb := newRip7560TestState()
+		statedb.SetBalance(sender, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+		statedb.SetCode(sender, tt.account)
+
+		aatx := newRip7560TestTx(sender, func(aatx *types.Rip7560AccountAbstractionTx) {
+			aatx.Nonce, aatx.ExecutionData = tt.nonce, nil
+		})
+		if tt.paymaster != nil {
+			statedb.SetBalance(paymaster, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+			statedb.SetCode(paymaster, tt.paymaster)
+			aatx.Paymaster = &paymaster
+			aatx.PaymasterValidationGasLimit = 100_000
+		}
+		if tt.deployer != nil {
+			statedb.SetCode(deployer, tt.deployer)
+			aatx.Deployer = &deployer
+		}
+		_, err := applyRip7560TestTx(statedb, header, types.NewTx(aatx), vm.Config{})
+		if !errors.Is(err, tt.kind) {
+			t.Errorf("%s: error mismatch: have %v, want %v", tt.name, err, tt.kind)
+			continue
+		}
+		var vpe *ValidationPhaseError
+		if !errors.As(err, &vpe) {
+			t.Errorf("%s: not a validation phase error: %v", tt.name, err)
+			continue
+		}
+		if vpe.Frame() != tt.frame {
+			t.Errorf("%s: frame mismatch: have %q, want %q", tt.name, vpe.Frame(), tt.frame)
+		}
+		if vpe.ErrorCode() != tt.code {
+			t.Errorf("%s: error code mismatch: have %d, want %d", tt.name, vpe.ErrorCode(), tt.code)
+		}
+	}
+}
+
+// Tests that tracers observe the validation phase and every frame of a RIP-7560
+// transaction.
+func TestRip7560TracingHooks(t *testing.T) {
+	var (
+		sender    = common.Address{0xaa}
+		paymaster = common.Address{0xbb}
+		statedb   = newRip7560TestState()
+	)
+	statedb.SetCode(sender, rip7560AccountCode())
+	statedb.SetCode(paymaster, rip7560PaymasterCode())
+	statedb.SetBalance(paymaster, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+
+	tx := types.NewTx(newRip7560TestTx(sender, withRip7560TestPaymaster(paymaster)))
+	var events []string
+	tracer := &tracing.Hooks{
+		OnAAValidationStart: func(tx *types.Transaction) {
+			events = append(events, "validationStart")
+		},
+		OnAAValidationEnd: func(tx *types.Transaction, err error) {
+			events = append(events, "validationEnd")
+		},
+		OnAAFrameStart: func(frame string, to common.Address, input []byte, gasLimit uint64) {
+			events = append(events, frame)
+		},
+		OnAAFrameEnd: func(frame string, output []byte, gasUsed uint64, err error) {
+			if err != nil {
+				events = append(events, frame+" failed")
+			}
+		},
+	}
+	if _, err := applyRip7560TestTx(statedb, rip7560TestHeader(), tx, vm.Config{Tracer: tracer}); err != nil {
+		t.Fatalf("failed to apply transaction: %v", err)
+	}
+	want := []string{"validationStart", FrameAccount, FramePaymaster, "validationEnd", "execution", FramePostOp, FramePostOp + " failed"}
+	if !slices.Equal(events, want) {
+		t.Errorf("events mismatch:\nhave %v\nwant %v", events, want)
+	}
+}
+
+// Tests that the gas payer is pre-charged the maximum fee of the total gas limit
+// and refunded the unused part, while the coinbase receives the effective tip.
+func TestRip7560GasPayment(t *testing.T) {
+	var (
+		sender   = common.Address{0xaa}
+		coinbase = common.Address{0xcc} // Coinbase of applyRip7560TestTx
+		header   = rip7560TestHeader()
+	)
+	header.BaseFee = big.NewInt(10)
+
+	newTx := func(feeCap, tipCap int64) *types.Transaction {
+		return types.NewTx(newRip7560TestTx(sender, func(aatx *types.Rip7560AccountAbstractionTx) {
+			aatx.GasFeeCap, aatx.GasTipCap, aatx.ExecutionData = big.NewInt(feeCap), big.NewInt(tipCap), nil
+		}))
+	}
+	apply := func(tx *types.Transaction, balance uint64) (*state.StateDB, *types.Receipt, error) {
+		statedb := newRip7560TestState()
+		statedb.SetCode(sender, rip7560AccountCode())
+		statedb.SetBalance(sender, uint256.NewInt(balance), tracing.BalanceChangeUnspecified)
+
+		receipt, err := applyRip7560TestTx(statedb, header, tx, vm.Config{})
+		return statedb, receipt, err
+	}
+	if _, _, err := apply(newTx(5, 1), params.Ether); !errors.Is(err, ErrFeeCapTooLow) {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrFeeCapTooLow)
+	}
+	if _, _, err := apply(newTx(20, 30), params.Ether); !errors.Is(err, ErrTipAboveFeeCap) {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrTipAboveFeeCap)
+	}
+	// The balance has to cover the maximum fee, not the effective one
+	tx := newTx(20, 3)
+	total, _ := tx.Rip7560TransactionData().TotalGasLimit()
+	if _, _, err := apply(tx, 20*total-1); !errors.Is(err, ErrInsufficientFunds) {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrInsufficientFunds)
+	}
+	statedb, receipt, err := apply(tx, 20*total)
+	if err != nil {
+		t.Fatalf("failed to apply transaction: %v", err)
+	}
+	if have, want := statedb.GetBalance(sender), uint256.NewInt(20*total-13*receipt.GasUsed); have.Cmp(want) != 0 {
+		t.Errorf("sender balance mismatch: have %v, want %v", have, want)
+	}
+	if have, want := statedb.GetBalance(coinbase), uint256.NewInt(3*receipt.GasUsed); have.Cmp(want) != 0 {
+		t.Errorf("coinbase balance mismatch: have %v, want %v", have, want)
+	}
+}
+
+// Tests that the penalty charged for the unused execution gas follows the
+// percentage configured by the chain.
+func TestRip7560GasPenalty(t *testing.T) {
+	var (
+		sender   = common.Address{0xaa}
+		coinbase = common.Address{0xcc}
+		header   = rip7560TestHeader()
+	)
+	aatx := newRip7560TestTx(sender, func(aatx *types.Rip7560AccountAbstractionTx) {
+		aatx.GasFeeCap, aatx.GasTipCap = new(big.Int), new(big.Int)
+	})
+	// apply returns the gas charged to the transaction and used by its
+	// execution frame with the given penalty
+	apply := func(penalty *uint64) (uint64, uint64) {
+		config := *params.AllDevChainProtocolChanges
+		config.Rip7560 = &params.Rip7560Config{GasPenaltyPercent: penalty}
+
+		statedb := newRip7560TestState()
+		statedb.SetCode(sender, rip7560AccountCode())
+
+		var executionUsed, usedGas uint64
+		hooks := &tracing.Hooks{
+			OnAAFrameEnd: func(frame string, output []byte, gasUsed uint64, err error) {
+				if frame == "execution" {
+					executionUsed = gasUsed
+				}
+			},
+		}
+		receipt, err := ApplyRip7560Transaction(&config, nil, &coinbase, new(GasPool).AddGas(header.GasLimit), statedb, header, common.Hash{}, types.NewTx(aatx), 0, &usedGas, vm.Config{Tracer: hooks})
+		if err != nil {
+			t.Fatalf("failed to apply transaction: %v", err)
+		}
+		return receipt.GasUsed, executionUsed
+	}
+	unpenalized, executionUsed := apply(new(uint64))
+	for _, percent := range []uint64{params.Rip7560GasPenaltyPercent, 50, 100} {
+		penalty := percent
+		gasUsed, _ := apply(&penalty)
+		if want := unpenalized + (aatx.Gas-executionUsed)*percent/100; gasUsed != want {
+			t.Errorf("%d%%: gas used mismatch: have %d, want %d", percent, gasUsed, want)
+		}
+	}
+	if gasUsed, _ := apply(nil); gasUsed != unpenalized+(aatx.Gas-executionUsed)*params.Rip7560GasPenaltyPercent/100 {
+		t.Errorf("default penalty not charged: have %d gas used", gasUsed)
+	}
+}
+
+// Tests that paymasters are only charged for their context from the context gas
+// block of the chain on.
+func TestRip7560PaymasterContextGasFork(t *testing.T) {
+	var (
+		sender    = common.Address{0xaa}
+		paymaster = common.Address{0xbb}
+		coinbase  = common.Address{0xcc}
+		header    = rip7560TestHeader()
+	)
+	aatx := newRip7560TestTx(sender, withRip7560TestPaymaster(paymaster))
+
+	// apply returns the gas charged to the transaction with the given block
+	// starting the context charge
+	apply := func(fork *big.Int) uint64 {
+		config := *params.AllDevChainProtocolChanges
+		config.RIP7560ContextGasBlock = fork
+
+		statedb := newRip7560TestState()
+		setRip7560TestSender(statedb, sender)
+		setRip7560TestPaymaster(statedb, paymaster)
+
+		var usedGas uint64
+		receipt, err := ApplyRip7560Transaction(&config, nil, &coinbase, new(GasPool).AddGas(header.GasLimit), statedb, header, common.Hash{}, types.NewTx(aatx), 0, &usedGas, vm.Config{})
+		if err != nil {
+			t.Fatalf("failed to apply transaction: %v", err)
+		}
+		return receipt.GasUsed
+	}
+	uncharged := apply(nil)
+	if gasUsed := apply(big.NewInt(1)); gasUsed != uncharged {
+		t.Errorf("context charged before the fork: have %d gas used, want %d", gasUsed, uncharged)
+	}
+	if gasUsed, want := apply(big.NewInt(0)), uncharged+paymasterContextGas([]byte{0xff}); gasUsed != want {
+		t.Errorf("context not charged after the fork: have %d gas used, want %d", gasUsed, want)
+	}
+}
+
+// Tests that the execution frames of a batched transaction are reverted all
+// together if any of them fails, and that their gas use, refunds and penalty
+// are accounted for as a single execution frame.
+func TestRip7560BatchedExecution(t *testing.T) {
+	sender := common.Address{0xaa}
+
+	// The execution frames, the only ones called with two bytes, write the
+	// second byte to the slot given by the first one, reverting on slot 0xff
+	exec := []byte{
+		byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.DUP1),
+		byte(vm.PUSH1), 8, byte(vm.SHL), byte(vm.PUSH1), 248, byte(vm.SHR),
+		byte(vm.SWAP1), byte(vm.PUSH1), 248, byte(vm.SHR),
+		byte(vm.DUP1), byte(vm.PUSH1), 0xff, byte(vm.EQ), byte(vm.PUSH1), 0, byte(vm.JUMPI),
+		byte(vm.SSTORE), byte(vm.STOP),
+		byte(vm.JUMPDEST), byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.REVERT),
+	}
+	const dispatch = 8 // Length of the code jumping over the execution frames
+	exec[19] = byte(dispatch + len(exec) - 6)
+	prefix := append([]byte{byte(vm.CALLDATASIZE), byte(vm.PUSH1), 2, byte(vm.EQ), byte(vm.ISZERO), byte(vm.PUSH1), byte(dispatch + len(exec)), byte(vm.JUMPI)}, exec...)
+	prefix = append(prefix, byte(vm.JUMPDEST))
+
+	tests := []struct {
+		name     string
+		calls    [][]byte
+		failed   int    // Index of the failing frame, -1 if none
+		slots    []byte // Slots set to one after the transaction
+		refunded bool   // Whether the frames clear the slots set up front
+	}{
+		{"batch", [][]byte{{1, 1}, {2, 1}, {3, 1}}, -1, []byte{1, 2, 3, 10}, false},
+		{"reverted batch", [][]byte{{1, 1}, {0xff, 0}, {3, 1}}, 1, []byte{10}, false},
+		{"refunded batch", [][]byte{{10, 0}, {1, 1}, {2, 1}}, -1, []byte{1, 2}, true},
+	}
+	for _, tt := range tests {
+		statedb := newRip7560TestState()
+		setRip7560TestSender(statedb, sender, prefix...)
+		statedb.SetState(sender, common.Hash{31: 10}, common.Hash{31: 1})
+		statedb.Finalise(true)
+
+		aatx := newRip7560TestTx(sender, func(aatx *types.Rip7560AccountAbstractionTx) {
+			aatx.ExecutionData, aatx.ExecutionCalls = nil, tt.calls
+		})
+		used := make(map[string]uint64)
+		hooks := &tracing.Hooks{
+			OnAAFrameEnd: func(frame string, output []byte, gasUsed uint64, err error) {
+				used[frame] = gasUsed
+			},
+		}
+		receipt, err := applyRip7560TestTx(statedb, rip7560TestHeader(), types.NewTx(aatx), vm.Config{Tracer: hooks})
+		if err != nil {
+			t.Fatalf("%s: failed to apply transaction: %v", tt.name, err)
+		}
+		// All the frames are run up to the failing one, and reverted with it
+		wantStatus := types.ReceiptStatusSuccessful
+		if tt.failed >= 0 {
+			wantStatus = types.ReceiptStatusFailed
+		}
+		if receipt.Status != wantStatus {
+			t.Errorf("%s: status mismatch: have %d, want %d", tt.name, receipt.Status, wantStatus)
+		}
+		var wantFrames []types.ReceiptFrameStatus
+		for i := range tt.calls {
+			status := types.ReceiptFrameStatus{Frame: ExecutionFrameName(i, len(tt.calls)), Status: types.ReceiptStatusSuccessful}
+			if i == tt.failed {
+				status.Status = types.ReceiptStatusFailed
+			}
+			wantFrames = append(wantFrames, status)
+			if i == tt.failed {
+				break
+			}
+		}
+		if have := receipt.FrameStatuses[1:]; !reflect.DeepEqual(have, wantFrames) {
+			t.Errorf("%s: execution frame statuses mismatch: have %v, want %v", tt.name, have, wantFrames)
+		}
+		for slot := byte(0); slot < 16; slot++ {
+			want := common.Hash{}
+			if slices.Contains(tt.slots, slot) {
+				want = common.Hash{31: 1}
+			}
+			if have := statedb.GetState(sender, common.Hash{31: slot}); have != want {
+				t.Errorf("%s: slot %d mismatch: have %x, want %x", tt.name, slot, have, want)
+			}
+		}
+		// The frames share the gas limit, the refund cap and the penalty
+		var executionUsed uint64
+		for i := range tt.calls {
+			executionUsed += used[ExecutionFrameName(i, len(tt.calls))]
+		}
+		preTransactionGas, _ := aatx.PreTransactionGasCost()
+		want := preTransactionGas + used[FrameAccount] + executionUsed + (aatx.Gas-executionUsed)*params.Rip7560GasPenaltyPercent/100
+		if tt.refunded {
+			want -= min(params.SstoreClearsScheduleRefundEIP3529, executionUsed/params.RefundQuotientEIP3529)
+		}
+		if receipt.GasUsed != want {
+			t.Errorf("%s: gas used mismatch: have %d, want %d", tt.name, receipt.GasUsed, want)
+		}
+	}
+}
+
+// Tests that chains selecting an unsupported version of the frame interfaces
+// are rejected at genesis, and their transactions fail validation.
+func TestRip7560UnsupportedAbiVersion(t *testing.T) {
+	var (
+		sender   = common.Address{0xaa}
+		coinbase = common.Address{0xcc}
+		header   = rip7560TestHeader()
+		config   = *params.AllDevChainProtocolChanges
+	)
+	config.Rip7560 = &params.Rip7560Config{AbiVersion: aa.LatestVersion + 1}
+
+	db := rawdb.NewMemoryDatabase()
+	gspec := &Genesis{Config: &config, BaseFee: big.NewInt(params.InitialBaseFee)}
+	if _, err := gspec.Commit(db, triedb.NewDatabase(db, nil)); err == nil {
+		t.Error("genesis with unsupported ABI version committed")
+	}
+	statedb := newRip7560TestState()
+	statedb.SetCode(sender, rip7560AccountCode())
+	tx := types.NewTx(newRip7560TestTx(sender, func(aatx *types.Rip7560AccountAbstractionTx) {
+		aatx.GasFeeCap, aatx.GasTipCap, aatx.ExecutionData = new(big.Int), new(big.Int), nil
+	}))
+	var usedGas uint64
+	if _, err := ApplyRip7560Transaction(&config, nil, &coinbase, new(GasPool).AddGas(header.GasLimit), statedb, header, common.Hash{}, tx, 0, &usedGas, vm.Config{}); err == nil {
+		t.Error("transaction with unsupported ABI version applied")
+	}
+}
+
+// Tests that a transaction applied on an EVM of the caller is traced by its
+// hooks and gives the same receipt, handing the EVM back with its own hooks.
+func TestApplyRip7560TransactionWithEVM(t *testing.T) {
+	var (
+		sender   = common.Address{0xaa}
+		coinbase = common.Address{0xcc}
+		header   = rip7560TestHeader()
+		statedb  = newRip7560TestState()
+	)
+	header.Coinbase = coinbase
+	setRip7560TestSender(statedb, sender)
+
+	tx := types.NewTx(newRip7560TestTx(sender))
+	want, err := applyRip7560TestTx(statedb.Copy(), header, tx, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to apply transaction: %v", err)
+	}
+	var (
+		frames []string
+		calls  int
+	)
+	tracer := &tracing.Hooks{
+		OnEnter: func(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+			calls++
+		},
+		OnAAFrameStart: func(frame string, to common.Address, input []byte, gasLimit uint64) {
+			frames = append(frames, frame)
+		},
+	}
+	evm := vm.NewEVM(NewEVMBlockContext(header, nil, &coinbase), vm.TxContext{}, statedb, params.AllDevChainProtocolChanges, vm.Config{Tracer: tracer})
+
+	var usedGas uint64
+	have, err := ApplyRip7560TransactionWithEVM(evm, new(GasPool).AddGas(header.GasLimit), statedb, header, common.Hash{}, tx, 0, &usedGas)
+	if err != nil {
+		t.Fatalf("failed to apply transaction: %v", err)
+	}
+	if have.Status != want.Status || have.GasUsed != want.GasUsed || usedGas != want.GasUsed {
+		t.Errorf("receipt mismatch: have status %d gas %d, want status %d gas %d", have.Status, have.GasUsed, want.Status, want.GasUsed)
+	}
+	if wantFrames := []string{FrameAccount, "execution"}; !slices.Equal(frames, wantFrames) {
+		t.Errorf("frames mismatch: have %v, want %v", frames, wantFrames)
+	}
+	if calls == 0 {
+		t.Error("no calls traced on the EVM of the caller")
+	}
+	if evm.Config.Tracer != tracer {
+		t.Error("EVM not handed back with its own hooks")
+	}
+}
+
+// Tests that the validation and execution frames of an EIP-7702 delegated
+// sender run the code of its delegation target.
+func TestRip7560DelegatedSender(t *testing.T) {
+	var (
+		config   = *params.AllDevChainProtocolChanges
+		sender   = common.Address{0xaa}
+		target   = common.Address{0xab}
+		coinbase = common.Address{0xcc}
+		header   = rip7560TestHeader()
+	)
+	config.PragueTime = new(uint64)
+
+	statedb := newRip7560TestState()
+	statedb.SetCode(sender, types.AddressToDelegation(target))
+	statedb.SetBalance(sender, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+	// The target accepts the transaction and stores 1 in the first slot on execution
+	statedb.SetCode(target, rip7560AccountCode(byte(vm.CALLDATASIZE), byte(vm.PUSH1), 1, byte(vm.EQ), byte(vm.ISZERO), byte(vm.PUSH1), 14, byte(vm.JUMPI),
+		byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP), byte(vm.JUMPDEST)))
+
+	tx := types.NewTx(newRip7560TestTx(sender))
+	var usedGas uint64
+	receipt, err := ApplyRip7560Transaction(&config, nil, &coinbase, new(GasPool).AddGas(header.GasLimit), statedb, header, common.Hash{}, tx, 0, &usedGas, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to apply transaction: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("execution failed")
+	}
+	if val := statedb.GetState(sender, common.Hash{}); val != common.BigToHash(big.NewInt(1)) {
+		t.Errorf("delegated code not executed at sender: slot 0 is %x", val)
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// Names of the default block processing steps, in execution order.
+const (
+	StepNoUncles     = "no-uncles"
+	StepTxTypes      = "tx-types"
+	StepDAOFork      = "dao-fork"
+	StepBeaconRoot   = "beacon-root"
+	StepTransactions = "transactions"
+	StepWithdrawals  = "withdrawals"
+	StepFinalize     = "finalize"
+)
+
+// ProcessContext holds the state shared by the steps processing a single block.
+// Steps communicate by reading and updating its fields.
+type ProcessContext struct {
+	Processor *StateProcessor
+	Block     *types.Block
+	Header    *types.Header
+	StateDB   *state.StateDB
+	VMConfig  vm.Config
+	EVM       *vm.EVM
+	Signer    types.Signer
+	GasPool   *GasPool
+	UsedGas   *uint64
+
+	Receipts types.Receipts
+	Logs     []*types.Log
+}
+
+// ProcessStep is a named stage of the block processing pipeline.
+type ProcessStep struct {
+	Name string
+	Run  func(ctx *ProcessContext) error
+}
+
+var errUnknownStep = errors.New("unknown process step")
+
+// DefaultProcessSteps returns the block processing pipeline implementing the
+// Ethereum rules. RIP-7560 transactions are validated and executed as part of
+// the transactions step, as they may be interleaved with regular ones.
+func DefaultProcessSteps() []ProcessStep {
+	return []ProcessStep{
+		{Name: StepNoUncles, Run: processNoUncles},
+		{Name: StepTxTypes, Run: processTxTypes},
+		{Name: StepDAOFork, Run: processDAOFork},
+		{Name: StepBeaconRoot, Run: processBeaconRoot},
+		{Name: StepTransactions, Run: processTransactions},
+		{Name: StepWithdrawals, Run: processWithdrawals},
+		{Name: StepFinalize, Run: processFinalize},
+	}
+}
+
+// Steps returns the names of the configured processing steps in execution order.
+func (p *StateProcessor) Steps() []string {
+	names := make([]string, len(p.steps))
+	for i, step := range p.steps {
+		names[i] = step.Name
+	}
+	return names
+}
+
+// InsertStepBefore inserts a processing step right before the named one.
+//
+// The pipeline must only be modified before the processor is used.
+func (p *StateProcessor) InsertStepBefore(name string, step ProcessStep) error {
+	i, err := p.stepIndex(name)
+	if err != nil {
+		return err
+	}
+	p.steps = slices.Insert(slices.Clone(p.steps), i, step)
+	return nil
+}
+
+// InsertStepAfter inserts a processing step right after the named one.
+//
+// The pipeline must only be modified before the processor is used.
+func (p *StateProcessor) InsertStepAfter(name string, step ProcessStep) error {
+	i, err := p.stepIndex(name)
+	if err != nil {
+		return err
+	}
+	p.steps = slices.Insert(slices.Clone(p.steps), i+1, step)
+	return nil
+}
+
+// ReplaceStep replaces the named processing step.
+//
+// The pipeline must only be modified before the processor is used.
+func (p *StateProcessor) ReplaceStep(name string, step ProcessStep) error {
+	i, err := p.stepIndex(name)
+	if err != nil {
+		return err
+	}
+	steps := slices.Clone(p.steps)
+	steps[i] = step
+	p.steps = steps
+	return nil
+}
+
+// RemoveStep removes the named processing step.
+//
+// The pipeline must only be modified before the processor is used.
+func (p *StateProcessor) RemoveStep(name string) error {
+	i, err := p.stepIndex(name)
+	if err != nil {
+		return err
+	}
+	p.steps = slices.Delete(slices.Clone(p.steps), i, i+1)
+	return nil
+}
+
+func (p *StateProcessor) stepIndex(name string) (int, error) {
+	for i, step := range p.steps {
+		if step.Name == name {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s", errUnknownStep, name)
+}
+
+// processNoUncles rejects uncles up front in post-merge only mode, so that
+// nothing below (including the consensus engine's finalization) ever has to
+// handle them.
+func processNoUncles(ctx *ProcessContext) error {
+	if bc := ctx.Processor.bc; bc != nil && bc.cacheConfig.NoUncles {
+		return verifyNoUncles(ctx.Block)
+	}
+	return nil
+}
+
+// processTxTypes rejects blocks carrying transaction types the node doesn't
+// implement at the block in strict mode, before any of them is applied.
+func processTxTypes(ctx *ProcessContext) error {
+	if bc := ctx.Processor.bc; bc != nil && bc.cacheConfig.StrictTxTypes {
+		return verifyTxTypes(ctx.Processor.config, ctx.Block)
+	}
+	return nil
+}
+
+// processDAOFork mutates the state according to the DAO hard-fork spec.
+func processDAOFork(ctx *ProcessContext) error {
+	config := ctx.Processor.config
+	if config.DAOForkSupport && config.DAOForkBlock != nil && config.DAOForkBlock.Cmp(ctx.Header.Number) == 0 {
+		misc.ApplyDAOHardFork(ctx.StateDB)
+	}
+	return nil
+}
+
+// processBeaconRoot stores the parent beacon block root as per EIP-4788.
+func processBeaconRoot(ctx *ProcessContext) error {
+	if beaconRoot := ctx.Block.BeaconRoot(); beaconRoot != nil {
+		return ProcessBeaconBlockRoot(*beaconRoot, ctx.EVM, ctx.StateDB)
+	}
+	return nil
+}
+
+// processTransactions iterates over and applies the individual transactions.
+func processTransactions(ctx *ProcessContext) error {
+	var (
+		p           = ctx.Processor
+		header      = ctx.Header
+		blockHash   = ctx.Block.Hash()
+		blockNumber = ctx.Block.Number()
+		coinbase    = ctx.EVM.Context.Coinbase
+	)
+	txs := ctx.Block.Transactions()
+	for i := 0; i < len(txs); i++ {
+		tx := txs[i]
+
+		// RIP-7560 transactions may appear anywhere in the block, interleaved
+		// with the others on the same state, gas pool and cumulative gas. The
+		// validation phases of consecutive ones are run ahead concurrently if
+		// enabled.
+		if workers := p.rip7560ValidationWorkers(); workers > 1 && tx.Type() == types.Rip7560Type && i+1 < len(txs) && txs[i+1].Type() == types.Rip7560Type {
+			_, receipts, _, _, err := handleRip7560Transactions(txs[i:], i, ctx.StateDB, &coinbase, header, ctx.GasPool, p.config, p.bc, ctx.VMConfig, workers, false, ctx.UsedGas)
+			if err != nil {
+				return fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
+			for _, receipt := range receipts {
+				receipt.Logs = ctx.StateDB.GetLogs(receipt.TxHash, blockNumber.Uint64(), blockHash)
+				receipt.BlockHash = blockHash
+				receipt.BlockNumber = blockNumber
+				ctx.Receipts = append(ctx.Receipts, receipt)
+				ctx.Logs = append(ctx.Logs, receipt.Logs...)
+			}
+			i += len(receipts) - 1
+			continue
+		}
+		if tx.Type() == types.Rip7560Type {
+			receipt, err := ApplyRip7560Transaction(p.config, p.bc, &coinbase, ctx.GasPool, ctx.StateDB, header, blockHash, tx, i, ctx.UsedGas, ctx.VMConfig)
+			if err != nil {
+				return fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
+			ctx.Receipts = append(ctx.Receipts, receipt)
+			ctx.Logs = append(ctx.Logs, receipt.Logs...)
+			continue
+		}
+		msg, err := TransactionToMessage(tx, ctx.Signer, header.BaseFee)
+		if err != nil {
+			return fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+		}
+		ctx.StateDB.SetTxContext(tx.Hash(), i)
+
+		receipt, _, err := ApplyTransactionWithEVM(msg, p.config, ctx.GasPool, ctx.StateDB, blockNumber, blockHash, tx, ctx.UsedGas, ctx.EVM)
+		if err != nil {
+			return fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+		}
+		ctx.Receipts = append(ctx.Receipts, receipt)
+		ctx.Logs = append(ctx.Logs, receipt.Logs...)
+	}
+	return nil
+}
+
+// rip7560ValidationWorkers returns the number of RIP-7560 validation phases to
+// run concurrently, as configured for the chain.
+func (p *StateProcessor) rip7560ValidationWorkers() int {
+	if p.bc == nil {
+		return 0
+	}
+	return p.bc.cacheConfig.Rip7560ValidationWorkers
+}
+
+// processWithdrawals fails if Shanghai is not enabled and the block carries
+// withdrawals. The withdrawals themselves are applied by the consensus engine.
+func processWithdrawals(ctx *ProcessContext) error {
+	if len(ctx.Block.Withdrawals()) > 0 && !ctx.Processor.config.IsShanghai(ctx.Block.Number(), ctx.Block.Time()) {
+		return errors.New("withdrawals before shanghai")
+	}
+	return nil
+}
+
+// processFinalize applies any consensus engine specific extras (e.g. block rewards).
+func processFinalize(ctx *ProcessContext) error {
+	p := ctx.Processor
+	p.engine.Finalize(p.bc, ctx.Header, ctx.StateDB, ctx.Block.Body())
+	return nil
+}
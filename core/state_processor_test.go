@@ -18,7 +18,9 @@ package core
 
 import (
 	"crypto/ecdsa"
+	"errors"
 	"math/big"
+	"slices"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -29,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
 	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -426,12 +429,12 @@ func GenerateBadBlock(parent *types.Block, engine consensus.Engine, txs types.Tr
 
 var (
 	code                            = common.FromHex(`6060604052600a8060106000396000f360606040526008565b00`)
-	intrinsicContractCreationGas, _ = IntrinsicGas(code, nil, true, true, true, true)
+	intrinsicContractCreationGas, _ = IntrinsicGas(code, nil, nil, true, true, true, true)
 	// A contract creation that calls EXTCODECOPY in the constructor. Used to ensure that the witness
 	// will not contain that copied data.
 	// Source: https://gist.github.com/gballet/a23db1e1cb4ed105616b5920feb75985
 	codeWithExtCodeCopy                = common.FromHex(`0x60806040526040516100109061017b565b604051809103906000f08015801561002c573d6000803e3d6000fd5b506000806101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff16021790555034801561007857600080fd5b5060008067ffffffffffffffff8111156100955761009461024a565b5b6040519080825280601f01601f1916602001820160405280156100c75781602001600182028036833780820191505090505b50905060008060009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1690506020600083833c81610101906101e3565b60405161010d90610187565b61011791906101a3565b604051809103906000f080158015610133573d6000803e3d6000fd5b50600160006101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff160217905550505061029b565b60d58061046783390190565b6102068061053c83390190565b61019d816101d9565b82525050565b60006020820190506101b86000830184610194565b92915050565b6000819050602082019050919050565b600081519050919050565b6000819050919050565b60006101ee826101ce565b826101f8846101be565b905061020381610279565b925060208210156102435761023e7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff8360200360080261028e565b831692505b5050919050565b7f4e487b7100000000000000000000000000000000000000000000000000000000600052604160045260246000fd5b600061028582516101d9565b80915050919050565b600082821b905092915050565b6101bd806102aa6000396000f3fe608060405234801561001057600080fd5b506004361061002b5760003560e01c8063f566852414610030575b600080fd5b61003861004e565b6040516100459190610146565b60405180910390f35b6000600160009054906101000a900473ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff166381ca91d36040518163ffffffff1660e01b815260040160206040518083038186803b1580156100b857600080fd5b505afa1580156100cc573d6000803e3d6000fd5b505050506040513d601f19601f820116820180604052508101906100f0919061010a565b905090565b60008151905061010481610170565b92915050565b6000602082840312156101205761011f61016b565b5b600061012e848285016100f5565b91505092915050565b61014081610161565b82525050565b600060208201905061015b6000830184610137565b92915050565b6000819050919050565b600080fd5b61017981610161565b811461018457600080fd5b5056fea2646970667358221220a6a0e11af79f176f9c421b7b12f441356b25f6489b83d38cc828a701720b41f164736f6c63430008070033608060405234801561001057600080fd5b5060b68061001f6000396000f3fe6080604052348015600f57600080fd5b506004361060285760003560e01c8063ab5ed15014602d575b600080fd5b60336047565b604051603e9190605d565b60405180910390f35b60006001905090565b6057816076565b82525050565b6000602082019050607060008301846050565b92915050565b600081905091905056fea26469706673582212203a14eb0d5cd07c277d3e24912f110ddda3e553245a99afc4eeefb2fbae5327aa64736f6c63430008070033608060405234801561001057600080fd5b5060405161020638038061020683398181016040528101906100329190610063565b60018160001c6100429190610090565b60008190555050610145565b60008151905061005d8161012e565b92915050565b60006020828403121561007957610078610129565b5b60006100878482850161004e565b91505092915050565b600061009b826100f0565b91506100a6836100f0565b9250827fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff038211156100db576100da6100fa565b5b828201905092915050565b6000819050919050565b6000819050919050565b7f4e487b7100000000000000000000000000000000000000000000000000000000600052601160045260246000fd5b600080fd5b610137816100e6565b811461014257600080fd5b50565b60b3806101536000396000f3fe6080604052348015600f57600080fd5b506004361060285760003560e01c806381ca91d314602d575b600080fd5b60336047565b604051603e9190605a565b60405180910390f35b60005481565b6054816073565b82525050565b6000602082019050606d6000830184604d565b92915050565b600081905091905056fea26469706673582212209bff7098a2f526de1ad499866f27d6d0d6f17b74a413036d6063ca6a0998ca4264736f6c63430008070033`)
-	intrinsicCodeWithExtCodeCopyGas, _ = IntrinsicGas(codeWithExtCodeCopy, nil, true, true, true, true)
+	intrinsicCodeWithExtCodeCopyGas, _ = IntrinsicGas(codeWithExtCodeCopy, nil, nil, true, true, true, true)
 )
 
 func TestProcessVerkle(t *testing.T) {
@@ -528,3 +531,307 @@ func TestProcessVerkle(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the beacon root system call honours the chain config overrides and
+// that failures of the call are reported.
+func TestProcessBeaconBlockRoot(t *testing.T) {
+	var (
+		custom   = common.HexToAddress("0xbeac0")
+		reverter = common.HexToAddress("0xdead")
+		config   = *params.MergedTestChainConfig
+	)
+	config.BeaconRoots = &params.BeaconRootsConfig{Address: custom}
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetCode(custom, params.BeaconRootsCode)
+	statedb.SetCode(reverter, []byte{byte(vm.PUSH0), byte(vm.PUSH0), byte(vm.REVERT)})
+
+	header := &types.Header{Number: big.NewInt(1), Time: 12, Difficulty: new(big.Int), BaseFee: new(big.Int)}
+	vmenv := vm.NewEVM(NewEVMBlockContext(header, nil, &common.Address{}), vm.TxContext{}, statedb, &config, vm.Config{})
+
+	root := common.Hash{0x01}
+	if err := ProcessBeaconBlockRoot(root, vmenv, statedb); err != nil {
+		t.Fatalf("failed to process beacon root: %v", err)
+	}
+	// The contract stores the root at slot (timestamp % 8191) + 8191.
+	slot := common.BigToHash(big.NewInt(12%8191 + 8191))
+	if have := statedb.GetState(custom, slot); have != root {
+		t.Fatalf("beacon root mismatch: have %x, want %x", have, root)
+	}
+	config.BeaconRoots.Address = reverter
+	if err := ProcessBeaconBlockRoot(root, vmenv, statedb); !errors.Is(err, ErrBeaconRootCallFailed) {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrBeaconRootCallFailed)
+	}
+}
+
+// Tests that derived chains can hook into the block processing pipeline.
+func TestProcessStepPipeline(t *testing.T) {
+	var (
+		gspec        = &Genesis{Config: params.TestChainConfig}
+		_, blocks, _ = GenerateChainWithGenesis(gspec, ethash.NewFaker(), 3, nil)
+	)
+	chain, _ := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	processor := chain.Processor().(*StateProcessor)
+	want := []string{StepNoUncles, StepTxTypes, StepDAOFork, StepBeaconRoot, StepTransactions, StepWithdrawals, StepFinalize}
+	if have := processor.Steps(); !slices.Equal(have, want) {
+		t.Fatalf("default steps mismatch: have %v, want %v", have, want)
+	}
+	var order []string
+	record := func(name string) ProcessStep {
+		return ProcessStep{Name: name, Run: func(ctx *ProcessContext) error {
+			order = append(order, name)
+			return nil
+		}}
+	}
+	if err := processor.InsertStepBefore(StepTransactions, record("pre-txs")); err != nil {
+		t.Fatal(err)
+	}
+	if err := processor.InsertStepAfter(StepTransactions, record("post-txs")); err != nil {
+		t.Fatal(err)
+	}
+	if err := processor.ReplaceStep(StepDAOFork, record("dao")); err != nil {
+		t.Fatal(err)
+	}
+	if err := processor.RemoveStep("missing"); !errors.Is(err, errUnknownStep) {
+		t.Fatalf("error mismatch: have %v, want %v", err, errUnknownStep)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if len(order) != 3*len(blocks) || order[0] != "dao" || order[1] != "pre-txs" || order[2] != "post-txs" {
+		t.Fatalf("unexpected step execution order: %v", order)
+	}
+}
+
+// Tests that modifying the processing pipeline never writes through to the
+// steps it was built from, and that Process runs the modified pipeline.
+func TestProcessStepPipelineCopy(t *testing.T) {
+	var (
+		gspec        = &Genesis{Config: params.TestChainConfig}
+		_, blocks, _ = GenerateChainWithGenesis(gspec, ethash.NewFaker(), 1, nil)
+	)
+	chain, _ := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer chain.Stop()
+
+	var order []string
+	record := func(name string) ProcessStep {
+		return ProcessStep{Name: name, Run: func(ctx *ProcessContext) error {
+			order = append(order, name)
+			return nil
+		}}
+	}
+	// Leave spare capacity behind the pipeline, which appending in place would reuse
+	processor := NewStateProcessor(gspec.Config, chain, chain.engine)
+	if err := processor.RemoveStep(StepWithdrawals); err != nil {
+		t.Fatal(err)
+	}
+	steps := processor.steps
+	names := processor.Steps()
+
+	if err := processor.InsertStepBefore(StepTransactions, record("pre-txs")); err != nil {
+		t.Fatal(err)
+	}
+	if err := processor.InsertStepAfter(StepFinalize, record("post-finalize")); err != nil {
+		t.Fatal(err)
+	}
+	if err := processor.ReplaceStep(StepNoUncles, record("no-uncles")); err != nil {
+		t.Fatal(err)
+	}
+	if err := processor.RemoveStep(StepDAOFork); err != nil {
+		t.Fatal(err)
+	}
+	for i, step := range steps {
+		if step.Name != names[i] {
+			t.Fatalf("step %d overwritten: have %s, want %s", i, step.Name, names[i])
+		}
+	}
+	want := []string{"no-uncles", StepTxTypes, StepBeaconRoot, "pre-txs", StepTransactions, StepFinalize, "post-finalize"}
+	if have := processor.Steps(); !slices.Equal(have, want) {
+		t.Fatalf("steps mismatch: have %v, want %v", have, want)
+	}
+	statedb, err := chain.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := processor.Process(blocks[0], statedb, vm.Config{}); err != nil {
+		t.Fatalf("failed to process block: %v", err)
+	}
+	if want := []string{"no-uncles", "pre-txs", "post-finalize"}; !slices.Equal(order, want) {
+		t.Fatalf("step execution order mismatch: have %v, want %v", order, want)
+	}
+}
+
+func TestPaymasterContextGas(t *testing.T) {
+	context := []byte{0x00, 0x01, 0x00, 0xff}
+	want := 2*params.TxDataZeroGas + 2*params.TxDataNonZeroGasEIP2028
+	if have := paymasterContextGas(context); have != want {
+		t.Fatalf("context gas mismatch: have %d, want %d", have, want)
+	}
+	if have := paymasterContextGas(nil); have != 0 {
+		t.Fatalf("empty context gas mismatch: have %d, want 0", have)
+	}
+}
+
+// Tests that ApplyTransaction returns the execution result of the transaction
+// alongside its receipt.
+func TestApplyTransactionResult(t *testing.T) {
+	var (
+		config   = params.TestChainConfig
+		signer   = types.LatestSigner(config)
+		key, _   = crypto.GenerateKey()
+		from     = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.Address{0xc0}
+		header   = &types.Header{Number: big.NewInt(1), Difficulty: new(big.Int), GasLimit: 30_000_000, BaseFee: big.NewInt(1)}
+	)
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetBalance(from, uint256.NewInt(params.Ether), 0)
+	// The contract reverts with the word 0x2a
+	statedb.SetCode(contract, []byte{byte(vm.PUSH1), 0x2a, byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.REVERT)})
+
+	tx := types.MustSignNewTx(key, signer, &types.DynamicFeeTx{
+		ChainID:   config.ChainID,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(2),
+		Gas:       100_000,
+		To:        &contract,
+	})
+	var usedGas uint64
+	receipt, result, err := ApplyTransaction(config, nil, &common.Address{}, new(GasPool).AddGas(header.GasLimit), statedb, header, tx, &usedGas, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to apply transaction: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusFailed || !errors.Is(result.Err, vm.ErrExecutionReverted) {
+		t.Fatalf("execution mismatch: status %d, err %v", receipt.Status, result.Err)
+	}
+	if result.UsedGas != receipt.GasUsed {
+		t.Errorf("gas mismatch: result %d, receipt %d", result.UsedGas, receipt.GasUsed)
+	}
+	if want := common.LeftPadBytes([]byte{0x2a}, 32); !slices.Equal(result.Revert(), want) {
+		t.Errorf("revert data mismatch: have %x, want %x", result.Revert(), want)
+	}
+}
+
+// Tests that strict processing rejects blocks carrying transaction types not
+// active at the block before applying any of their transactions.
+func TestProcessStrictTxTypes(t *testing.T) {
+	var (
+		key, _ = crypto.GenerateKey()
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		gspec  = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  types.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+		}
+	)
+	var (
+		parent   = gspec.ToBlock().Header()
+		transfer = types.MustSignNewTx(key, types.LatestSigner(gspec.Config), &types.LegacyTx{
+			To:       &common.Address{0x01},
+			Gas:      params.TxGas,
+			GasPrice: big.NewInt(params.GWei),
+		})
+		aatx = types.NewTx(&types.Rip7560AccountAbstractionTx{
+			ChainID:   gspec.Config.ChainID,
+			NonceKey:  new(big.Int),
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(1),
+			Sender:    &addr,
+		})
+		header = &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     big.NewInt(1),
+			Time:       parent.Time + 10,
+			GasLimit:   parent.GasLimit,
+			Difficulty: big.NewInt(1),
+			BaseFee:    eip1559.CalcBaseFee(gspec.Config, parent),
+		}
+		block = types.NewBlock(header, &types.Body{Transactions: []*types.Transaction{transfer, aatx}}, nil, trie.NewStackTrie(nil))
+	)
+	for _, strict := range []bool{false, true} {
+		cacheConfig := DefaultCacheConfigWithScheme(rawdb.HashScheme)
+		cacheConfig.StrictTxTypes = strict
+		chain, _ := NewBlockChain(rawdb.NewMemoryDatabase(), cacheConfig, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+		defer chain.Stop()
+
+		statedb, _ := chain.State()
+		_, _, _, err := chain.Processor().Process(block, statedb, vm.Config{})
+		if !errors.Is(err, ErrTxTypeNotSupported) {
+			t.Fatalf("strict %v: error mismatch: have %v, want %v", strict, err, ErrTxTypeNotSupported)
+		}
+		// Only the lenient processing applies the transactions preceding the
+		// unsupported one
+		want := uint64(1)
+		if strict {
+			want = 0
+		}
+		if nonce := statedb.GetNonce(addr); nonce != want {
+			t.Errorf("strict %v: sender nonce mismatch: have %d, want %d", strict, nonce, want)
+		}
+	}
+}
+
+// Tests that set code transactions install the delegations of their valid
+// authorizations, skip the invalid ones, and run the delegated code in the
+// context of the authority.
+func TestEIP7702(t *testing.T) {
+	var (
+		config    = *params.AllDevChainProtocolChanges
+		key, _    = crypto.GenerateKey()
+		keyA, _   = crypto.GenerateKey()
+		keyB, _   = crypto.GenerateKey()
+		from      = crypto.PubkeyToAddress(key.PublicKey)
+		addrA     = crypto.PubkeyToAddress(keyA.PublicKey)
+		addrB     = crypto.PubkeyToAddress(keyB.PublicKey)
+		delegate  = common.Address{0xde}
+		chainID   = uint256.MustFromBig(config.ChainID)
+		header    = &types.Header{Number: big.NewInt(1), Difficulty: new(big.Int), GasLimit: 30_000_000, BaseFee: big.NewInt(1)}
+		pragueCfg = config
+	)
+	pragueCfg.PragueTime = new(uint64)
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetBalance(from, uint256.NewInt(params.Ether), 0)
+	// The delegate stores 0x2a in its first slot
+	statedb.SetCode(delegate, []byte{byte(vm.PUSH1), 0x2a, byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP)})
+
+	authA, _ := types.SignSetCode(keyA, types.SetCodeAuthorization{ChainID: *chainID, Address: delegate, Nonce: 0})
+	authB, _ := types.SignSetCode(keyB, types.SetCodeAuthorization{ChainID: *chainID, Address: delegate, Nonce: 5})
+	tx := types.MustSignNewTx(key, types.NewPragueSigner(config.ChainID), &types.SetCodeTx{
+		ChainID:   chainID,
+		GasTipCap: uint256.NewInt(1),
+		GasFeeCap: uint256.NewInt(2),
+		Gas:       200_000,
+		To:        addrA,
+		Value:     new(uint256.Int),
+		AuthList:  []types.SetCodeAuthorization{authA, authB},
+	})
+	// Set code transactions are rejected before Prague
+	var usedGas uint64
+	if _, _, err := ApplyTransaction(&config, nil, &common.Address{}, new(GasPool).AddGas(header.GasLimit), statedb.Copy(), header, tx, &usedGas, vm.Config{}); !errors.Is(err, ErrTxTypeNotSupported) {
+		t.Fatalf("pre-prague error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+	receipt, _, err := ApplyTransaction(&pragueCfg, nil, &common.Address{}, new(GasPool).AddGas(header.GasLimit), statedb, header, tx, &usedGas, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to apply transaction: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("transaction failed")
+	}
+	if code, want := statedb.GetCode(addrA), types.AddressToDelegation(delegate); !slices.Equal(code, want) {
+		t.Errorf("authority code mismatch: have %x, want %x", code, want)
+	}
+	if nonce := statedb.GetNonce(addrA); nonce != 1 {
+		t.Errorf("authority nonce mismatch: have %d, want 1", nonce)
+	}
+	if val := statedb.GetState(addrA, common.Hash{}); val != common.BigToHash(big.NewInt(0x2a)) {
+		t.Errorf("delegated code not run at authority: slot 0 is %x", val)
+	}
+	// The authorization with the mismatching nonce is skipped
+	if code := statedb.GetCode(addrB); len(code) != 0 {
+		t.Errorf("invalid authorization applied: code %x", code)
+	}
+	if nonce := statedb.GetNonce(addrB); nonce != 0 {
+		t.Errorf("invalid authorization bumped nonce to %d", nonce)
+	}
+}
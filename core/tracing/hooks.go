@@ -149,6 +149,26 @@ type (
 	// beacon block root.
 	OnSystemCallEndHook = func()
 
+	/*
+		- RIP-7560 events -
+	*/
+
+	// AAValidationStartHook is called before the validation phase of a RIP-7560
+	// transaction starts.
+	AAValidationStartHook = func(tx *types.Transaction)
+
+	// AAValidationEndHook is called after the validation phase of a RIP-7560
+	// transaction ends. The error is non-nil if the transaction was rejected.
+	AAValidationEndHook = func(tx *types.Transaction, err error)
+
+	// AAFrameStartHook is called before a frame of a RIP-7560 transaction, such
+	// as "account" or "postOp", calls its target. The frame is followed by normal
+	// EVM execution events.
+	AAFrameStartHook = func(frame string, to common.Address, input []byte, gasLimit uint64)
+
+	// AAFrameEndHook is called after a frame of a RIP-7560 transaction returns.
+	AAFrameEndHook = func(frame string, output []byte, gasUsed uint64, err error)
+
 	/*
 		- State events -
 	*/
@@ -187,6 +207,11 @@ type Hooks struct {
 	OnGenesisBlock    GenesisBlockHook
 	OnSystemCallStart OnSystemCallStartHook
 	OnSystemCallEnd   OnSystemCallEndHook
+	// RIP-7560 events
+	OnAAValidationStart AAValidationStartHook
+	OnAAValidationEnd   AAValidationEndHook
+	OnAAFrameStart      AAFrameStartHook
+	OnAAFrameEnd        AAFrameEndHook
 	// State events
 	OnBalanceChange BalanceChangeHook
 	OnNonceChange   NonceChangeHook
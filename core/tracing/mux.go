@@ -0,0 +1,330 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracing
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// NewMuxHooks combines multiple sets of hooks into one, so that several tracers
+// observe the same execution. Each event is delivered to the hooks in the order
+// they were given. Nil sets are skipped, and an event is only hooked if at least
+// one of the sets hooks it, so that the EVM keeps skipping the unused ones.
+//
+// If a single non-nil set is given, it's returned as is.
+func NewMuxHooks(hooks ...*Hooks) *Hooks {
+	var sets []*Hooks
+	for _, h := range hooks {
+		if h != nil {
+			sets = append(sets, h)
+		}
+	}
+	switch len(sets) {
+	case 0:
+		return nil
+	case 1:
+		return sets[0]
+	}
+	var (
+		m   = &muxHooks{sets: sets}
+		mux = new(Hooks)
+	)
+	if m.has(func(h *Hooks) bool { return h.OnTxStart != nil }) {
+		mux.OnTxStart = m.onTxStart
+	}
+	if m.has(func(h *Hooks) bool { return h.OnTxEnd != nil }) {
+		mux.OnTxEnd = m.onTxEnd
+	}
+	if m.has(func(h *Hooks) bool { return h.OnEnter != nil }) {
+		mux.OnEnter = m.onEnter
+	}
+	if m.has(func(h *Hooks) bool { return h.OnExit != nil }) {
+		mux.OnExit = m.onExit
+	}
+	if m.has(func(h *Hooks) bool { return h.OnOpcode != nil }) {
+		mux.OnOpcode = m.onOpcode
+	}
+	if m.has(func(h *Hooks) bool { return h.OnFault != nil }) {
+		mux.OnFault = m.onFault
+	}
+	if m.has(func(h *Hooks) bool { return h.OnGasChange != nil }) {
+		mux.OnGasChange = m.onGasChange
+	}
+	if m.has(func(h *Hooks) bool { return h.OnBlockchainInit != nil }) {
+		mux.OnBlockchainInit = m.onBlockchainInit
+	}
+	if m.has(func(h *Hooks) bool { return h.OnClose != nil }) {
+		mux.OnClose = m.onClose
+	}
+	if m.has(func(h *Hooks) bool { return h.OnBlockStart != nil }) {
+		mux.OnBlockStart = m.onBlockStart
+	}
+	if m.has(func(h *Hooks) bool { return h.OnBlockEnd != nil }) {
+		mux.OnBlockEnd = m.onBlockEnd
+	}
+	if m.has(func(h *Hooks) bool { return h.OnSkippedBlock != nil }) {
+		mux.OnSkippedBlock = m.onSkippedBlock
+	}
+	if m.has(func(h *Hooks) bool { return h.OnGenesisBlock != nil }) {
+		mux.OnGenesisBlock = m.onGenesisBlock
+	}
+	if m.has(func(h *Hooks) bool { return h.OnSystemCallStart != nil }) {
+		mux.OnSystemCallStart = m.onSystemCallStart
+	}
+	if m.has(func(h *Hooks) bool { return h.OnSystemCallEnd != nil }) {
+		mux.OnSystemCallEnd = m.onSystemCallEnd
+	}
+	if m.has(func(h *Hooks) bool { return h.OnAAValidationStart != nil }) {
+		mux.OnAAValidationStart = m.onAAValidationStart
+	}
+	if m.has(func(h *Hooks) bool { return h.OnAAValidationEnd != nil }) {
+		mux.OnAAValidationEnd = m.onAAValidationEnd
+	}
+	if m.has(func(h *Hooks) bool { return h.OnAAFrameStart != nil }) {
+		mux.OnAAFrameStart = m.onAAFrameStart
+	}
+	if m.has(func(h *Hooks) bool { return h.OnAAFrameEnd != nil }) {
+		mux.OnAAFrameEnd = m.onAAFrameEnd
+	}
+	if m.has(func(h *Hooks) bool { return h.OnBalanceChange != nil }) {
+		mux.OnBalanceChange = m.onBalanceChange
+	}
+	if m.has(func(h *Hooks) bool { return h.OnNonceChange != nil }) {
+		mux.OnNonceChange = m.onNonceChange
+	}
+	if m.has(func(h *Hooks) bool { return h.OnCodeChange != nil }) {
+		mux.OnCodeChange = m.onCodeChange
+	}
+	if m.has(func(h *Hooks) bool { return h.OnStorageChange != nil }) {
+		mux.OnStorageChange = m.onStorageChange
+	}
+	if m.has(func(h *Hooks) bool { return h.OnLog != nil }) {
+		mux.OnLog = m.onLog
+	}
+	return mux
+}
+
+// muxHooks delivers each event to all the hook sets handling it.
+type muxHooks struct {
+	sets []*Hooks
+}
+
+// has reports whether any of the hook sets handles an event.
+func (m *muxHooks) has(hooked func(h *Hooks) bool) bool {
+	for _, h := range m.sets {
+		if hooked(h) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *muxHooks) onTxStart(vm *VMContext, tx *types.Transaction, from common.Address) {
+	for _, h := range m.sets {
+		if h.OnTxStart != nil {
+			h.OnTxStart(vm, tx, from)
+		}
+	}
+}
+
+func (m *muxHooks) onTxEnd(receipt *types.Receipt, err error) {
+	for _, h := range m.sets {
+		if h.OnTxEnd != nil {
+			h.OnTxEnd(receipt, err)
+		}
+	}
+}
+
+func (m *muxHooks) onEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	for _, h := range m.sets {
+		if h.OnEnter != nil {
+			h.OnEnter(depth, typ, from, to, input, gas, value)
+		}
+	}
+}
+
+func (m *muxHooks) onExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	for _, h := range m.sets {
+		if h.OnExit != nil {
+			h.OnExit(depth, output, gasUsed, err, reverted)
+		}
+	}
+}
+
+func (m *muxHooks) onOpcode(pc uint64, op byte, gas, cost uint64, scope OpContext, rData []byte, depth int, err error) {
+	for _, h := range m.sets {
+		if h.OnOpcode != nil {
+			h.OnOpcode(pc, op, gas, cost, scope, rData, depth, err)
+		}
+	}
+}
+
+func (m *muxHooks) onFault(pc uint64, op byte, gas, cost uint64, scope OpContext, depth int, err error) {
+	for _, h := range m.sets {
+		if h.OnFault != nil {
+			h.OnFault(pc, op, gas, cost, scope, depth, err)
+		}
+	}
+}
+
+func (m *muxHooks) onGasChange(old, new uint64, reason GasChangeReason) {
+	for _, h := range m.sets {
+		if h.OnGasChange != nil {
+			h.OnGasChange(old, new, reason)
+		}
+	}
+}
+
+func (m *muxHooks) onBlockchainInit(chainConfig *params.ChainConfig) {
+	for _, h := range m.sets {
+		if h.OnBlockchainInit != nil {
+			h.OnBlockchainInit(chainConfig)
+		}
+	}
+}
+
+func (m *muxHooks) onClose() {
+	for _, h := range m.sets {
+		if h.OnClose != nil {
+			h.OnClose()
+		}
+	}
+}
+
+func (m *muxHooks) onBlockStart(event BlockEvent) {
+	for _, h := range m.sets {
+		if h.OnBlockStart != nil {
+			h.OnBlockStart(event)
+		}
+	}
+}
+
+func (m *muxHooks) onBlockEnd(err error) {
+	for _, h := range m.sets {
+		if h.OnBlockEnd != nil {
+			h.OnBlockEnd(err)
+		}
+	}
+}
+
+func (m *muxHooks) onSkippedBlock(event BlockEvent) {
+	for _, h := range m.sets {
+		if h.OnSkippedBlock != nil {
+			h.OnSkippedBlock(event)
+		}
+	}
+}
+
+func (m *muxHooks) onGenesisBlock(genesis *types.Block, alloc types.GenesisAlloc) {
+	for _, h := range m.sets {
+		if h.OnGenesisBlock != nil {
+			h.OnGenesisBlock(genesis, alloc)
+		}
+	}
+}
+
+func (m *muxHooks) onSystemCallStart() {
+	for _, h := range m.sets {
+		if h.OnSystemCallStart != nil {
+			h.OnSystemCallStart()
+		}
+	}
+}
+
+func (m *muxHooks) onSystemCallEnd() {
+	for _, h := range m.sets {
+		if h.OnSystemCallEnd != nil {
+			h.OnSystemCallEnd()
+		}
+	}
+}
+
+func (m *muxHooks) onAAValidationStart(tx *types.Transaction) {
+	for _, h := range m.sets {
+		if h.OnAAValidationStart != nil {
+			h.OnAAValidationStart(tx)
+		}
+	}
+}
+
+func (m *muxHooks) onAAValidationEnd(tx *types.Transaction, err error) {
+	for _, h := range m.sets {
+		if h.OnAAValidationEnd != nil {
+			h.OnAAValidationEnd(tx, err)
+		}
+	}
+}
+
+func (m *muxHooks) onAAFrameStart(frame string, to common.Address, input []byte, gasLimit uint64) {
+	for _, h := range m.sets {
+		if h.OnAAFrameStart != nil {
+			h.OnAAFrameStart(frame, to, input, gasLimit)
+		}
+	}
+}
+
+func (m *muxHooks) onAAFrameEnd(frame string, output []byte, gasUsed uint64, err error) {
+	for _, h := range m.sets {
+		if h.OnAAFrameEnd != nil {
+			h.OnAAFrameEnd(frame, output, gasUsed, err)
+		}
+	}
+}
+
+func (m *muxHooks) onBalanceChange(addr common.Address, prev, new *big.Int, reason BalanceChangeReason) {
+	for _, h := range m.sets {
+		if h.OnBalanceChange != nil {
+			h.OnBalanceChange(addr, prev, new, reason)
+		}
+	}
+}
+
+func (m *muxHooks) onNonceChange(addr common.Address, prev, new uint64) {
+	for _, h := range m.sets {
+		if h.OnNonceChange != nil {
+			h.OnNonceChange(addr, prev, new)
+		}
+	}
+}
+
+func (m *muxHooks) onCodeChange(addr common.Address, prevCodeHash common.Hash, prevCode []byte, codeHash common.Hash, code []byte) {
+	for _, h := range m.sets {
+		if h.OnCodeChange != nil {
+			h.OnCodeChange(addr, prevCodeHash, prevCode, codeHash, code)
+		}
+	}
+}
+
+func (m *muxHooks) onStorageChange(addr common.Address, slot common.Hash, prev, new common.Hash) {
+	for _, h := range m.sets {
+		if h.OnStorageChange != nil {
+			h.OnStorageChange(addr, slot, prev, new)
+		}
+	}
+}
+
+func (m *muxHooks) onLog(log *types.Log) {
+	for _, h := range m.sets {
+		if h.OnLog != nil {
+			h.OnLog(log)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracing
+
+import (
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestMuxHooks(t *testing.T) {
+	var calls []string
+	first := &Hooks{
+		OnEnter: func(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+			calls = append(calls, "first")
+		},
+	}
+	second := &Hooks{
+		OnEnter: func(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+			calls = append(calls, "second")
+		},
+	}
+	if NewMuxHooks() != nil || NewMuxHooks(nil, nil) != nil {
+		t.Fatal("empty mux not nil")
+	}
+	if NewMuxHooks(nil, first) != first {
+		t.Fatal("single hook set wrapped")
+	}
+	mux := NewMuxHooks(first, nil, second)
+	if mux.OnOpcode != nil || mux.OnLog != nil {
+		t.Fatal("unused hooks set")
+	}
+	mux.OnEnter(0, 0, common.Address{}, common.Address{}, nil, 0, nil)
+	if !slices.Equal(calls, []string{"first", "second"}) {
+		t.Fatalf("calls mismatch: have %v, want [first second]", calls)
+	}
+}
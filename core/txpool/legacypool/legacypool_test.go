@@ -21,6 +21,7 @@ import (
 	crand "crypto/rand"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
 	"math/rand"
 	"os"
@@ -98,6 +99,10 @@ func (bc *testBlockChain) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent)
 	return bc.chainHeadFeed.Subscribe(ch)
 }
 
+func (bc *testBlockChain) GetReceiptsByHash(hash common.Hash) types.Receipts {
+	return nil
+}
+
 func transaction(nonce uint64, gaslimit uint64, key *ecdsa.PrivateKey) *types.Transaction {
 	return pricedTransaction(nonce, gaslimit, big.NewInt(1), key)
 }
@@ -440,6 +445,74 @@ func TestTipAboveFeeCap(t *testing.T) {
 	}
 }
 
+// Tests that set code transactions are only accepted from Prague, and that
+// their authorizations are checked against the chain and the authorities.
+func TestSetCodeTransactions(t *testing.T) {
+	t.Parallel()
+
+	config := *params.MergedTestChainConfig
+	config.PragueTime = new(uint64)
+
+	authKey, _ := crypto.GenerateKey()
+	authority := crypto.PubkeyToAddress(authKey.PublicKey)
+
+	setCodeTx := func(nonce uint64, key *ecdsa.PrivateKey, auths ...types.SetCodeAuthorization) *types.Transaction {
+		return types.MustSignNewTx(key, types.LatestSignerForChainID(config.ChainID), &types.SetCodeTx{
+			ChainID:   uint256.MustFromBig(config.ChainID),
+			Nonce:     nonce,
+			GasTipCap: uint256.NewInt(1),
+			GasFeeCap: uint256.NewInt(1),
+			Gas:       100000,
+			To:        common.Address{},
+			Value:     new(uint256.Int),
+			AuthList:  auths,
+		})
+	}
+	auth := func(chainID uint64, nonce uint64) types.SetCodeAuthorization {
+		auth, _ := types.SignSetCode(authKey, types.SetCodeAuthorization{
+			ChainID: *uint256.NewInt(chainID),
+			Address: common.Address{0xaa},
+			Nonce:   nonce,
+		})
+		return auth
+	}
+	// Set code transactions are rejected before Prague
+	pool, key := setupPoolWithConfig(eip1559Config)
+	defer pool.Close()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(params.Ether))
+	if err := pool.addRemote(setCodeTx(0, key, auth(0, 0))); !errors.Is(err, core.ErrTxTypeNotSupported) {
+		t.Errorf("pre-Prague error mismatch: have %v, want %v", err, core.ErrTxTypeNotSupported)
+	}
+	// Authorizations are validated from Prague
+	pool, key = setupPoolWithConfig(&config)
+	defer pool.Close()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(params.Ether))
+	testSetNonce(pool, authority, 1)
+
+	for i, tt := range []struct {
+		auths []types.SetCodeAuthorization
+		want  error
+	}{
+		{auths: nil, want: core.ErrEmptyAuthList},
+		{auths: []types.SetCodeAuthorization{auth(config.ChainID.Uint64()+1, 1)}, want: core.ErrAuthorizationWrongChainID},
+		{auths: []types.SetCodeAuthorization{auth(0, math.MaxUint64)}, want: core.ErrAuthorizationNonceOverflow},
+		{auths: []types.SetCodeAuthorization{auth(0, 1), auth(0, 0)}, want: core.ErrAuthorizationNonceMismatch},
+	} {
+		err := pool.addRemote(setCodeTx(0, key, tt.auths...))
+		if !errors.Is(err, tt.want) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.want)
+		}
+	}
+	if err := pool.addRemoteSync(setCodeTx(0, key, auth(0, 1), auth(config.ChainID.Uint64(), 2))); err != nil {
+		t.Fatalf("failed to add valid set code transaction: %v", err)
+	}
+	if pending, _ := pool.Stats(); pending != 1 {
+		t.Errorf("pending transactions mismatch: have %d, want 1", pending)
+	}
+}
+
 func TestVeryHighValues(t *testing.T) {
 	t.Parallel()
 
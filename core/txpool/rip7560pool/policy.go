@@ -0,0 +1,292 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rip7560pool
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	// ErrPaymasterNotAllowed is returned if a transaction is sponsored by a
+	// paymaster missing from the configured allowlist.
+	ErrPaymasterNotAllowed = errors.New("paymaster not allowed")
+
+	// ErrPaymasterBanned is returned if a transaction is sponsored by a
+	// paymaster listed in the configured banlist.
+	ErrPaymasterBanned = errors.New("paymaster banned")
+)
+
+var (
+	// Metrics reported when enabled in the pool configuration
+	pendingGauge  = metrics.NewRegisteredGauge("rip7560pool/pending", nil)
+	queuedGauge   = metrics.NewRegisteredGauge("rip7560pool/queued", nil)
+	acceptedMeter = metrics.NewRegisteredMeter("rip7560pool/accepted", nil)
+	rejectedMeter = metrics.NewRegisteredMeter("rip7560pool/rejected", nil)
+	timeoutMeter  = metrics.NewRegisteredMeter("rip7560pool/timeout", nil)
+	violatedMeter = metrics.NewRegisteredMeter("rip7560pool/violation", nil)
+)
+
+// DefaultReputationConfig contains the ERC-7562 default reputation thresholds.
+var DefaultReputationConfig = ReputationConfig{
+	MinInclusionDenominator: 10,
+	ThrottlingSlack:         10,
+	BanSlack:                50,
+	ThrottlingFailures:      2,
+	BanFailures:             10,
+}
+
+// ReputationConfig are the thresholds deciding when ERC-7562 entities (senders,
+// paymasters and deployers) are throttled or banned, based on the number of
+// their transactions seen by the pool versus included in blocks, and on the
+// number of validations they failed when their transactions were included.
+type ReputationConfig struct {
+	MinInclusionDenominator uint64 // Transactions seen per expected inclusion
+	ThrottlingSlack         uint64 // Missing inclusions tolerated before throttling an entity
+	BanSlack                uint64 // Missing inclusions tolerated before banning an entity
+	ThrottlingFailures      uint64 // Validation failures on inclusion tolerated before throttling an entity (0 = unlimited)
+	BanFailures             uint64 // Validation failures on inclusion tolerated before banning an entity (0 = unlimited)
+}
+
+// loadAddressList reads a file listing one address per line. Empty lines and
+// lines starting with '#' are ignored.
+func loadAddressList(path string) (map[common.Address]struct{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var (
+		addresses = make(map[common.Address]struct{})
+		scanner   = bufio.NewScanner(file)
+	)
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		if !common.IsHexAddress(entry) {
+			return nil, fmt.Errorf("invalid address %q on line %d of %s", entry, line, path)
+		}
+		addresses[common.HexToAddress(entry)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+// loadPolicy reads the paymaster and delegate lists of the given configuration
+// and installs them together with the rest of the policy. Nothing is changed on
+// failure. The pool lock must be held, unless the pool is not yet shared.
+func (pool *Rip7560BundlerPool) loadPolicy(config Config) error {
+	var (
+		allowed, banned, delegates map[common.Address]struct{}
+		err                        error
+	)
+	if config.PaymasterAllowlist != "" {
+		if allowed, err = loadAddressList(config.PaymasterAllowlist); err != nil {
+			return err
+		}
+	}
+	if config.PaymasterBanlist != "" {
+		if banned, err = loadAddressList(config.PaymasterBanlist); err != nil {
+			return err
+		}
+	}
+	if config.TrustedDelegates != "" {
+		if delegates, err = loadAddressList(config.TrustedDelegates); err != nil {
+			return err
+		}
+	}
+	pool.allowed, pool.banned = allowed, banned
+	pool.policy = core.ValidationPolicy{
+		MaxCallDepth:     config.MaxCallDepth,
+		MaxReentrancy:    config.MaxReentrancy,
+		TrustedDelegates: delegates,
+	}
+	pool.config.BannedOpcodes = config.BannedOpcodes
+	pool.config.PaymasterAllowlist = config.PaymasterAllowlist
+	pool.config.PaymasterBanlist = config.PaymasterBanlist
+	pool.config.MaxCallDepth = config.MaxCallDepth
+	pool.config.MaxReentrancy = config.MaxReentrancy
+	pool.config.TrustedDelegates = config.TrustedDelegates
+	pool.config.Reputation = config.Reputation
+	return nil
+}
+
+// Policy returns the operator policy currently applied by the pool.
+func (pool *Rip7560BundlerPool) Policy() Config {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	return pool.config
+}
+
+// AllowedPaymasters returns the paymasters of the allowlist, or nil if the pool
+// accepts all paymasters.
+func (pool *Rip7560BundlerPool) AllowedPaymasters() []common.Address {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if pool.allowed == nil {
+		return nil
+	}
+	paymasters := make([]common.Address, 0, len(pool.allowed))
+	for paymaster := range pool.allowed {
+		paymasters = append(paymasters, paymaster)
+	}
+	return paymasters
+}
+
+// SetPolicy replaces the operator policy of the pool at runtime: the banned
+// opcode mode, the paymaster allow and ban lists (re-read from their files),
+// the limits on the validation frames and the reputation thresholds. The rest
+// of the configuration is ignored. Transactions sponsored by paymasters no
+// longer accepted are dropped.
+func (pool *Rip7560BundlerPool) SetPolicy(config Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	pool.mu.Lock()
+	if err := pool.loadPolicy(config); err != nil {
+		pool.mu.Unlock()
+		return err
+	}
+	var dropped int
+	for _, tx := range pool.all {
+		if pool.checkPaymaster(tx) != nil {
+			pool.removeTx(tx, DropPaymasterPolicy)
+			dropped++
+		}
+	}
+	pool.recountQueued()
+	pool.reportSize()
+
+	log.Info("Updated RIP-7560 pool policy", "bannedopcodes", config.BannedOpcodes,
+		"allowed", len(pool.allowed), "banned", len(pool.banned), "dropped", dropped)
+	drops := pool.takeDrops()
+	pool.mu.Unlock()
+
+	pool.sendDrops(drops)
+	return nil
+}
+
+// checkPaymaster rejects transactions sponsored by paymasters missing from the
+// allowlist or listed in the banlist, if any was configured.
+func (pool *Rip7560BundlerPool) checkPaymaster(tx *types.Transaction) error {
+	paymaster := tx.Rip7560TransactionData().Paymaster
+	if paymaster == nil {
+		return nil
+	}
+	if _, ok := pool.banned[*paymaster]; ok {
+		return fmt.Errorf("%w: %v", ErrPaymasterBanned, paymaster)
+	}
+	if pool.allowed == nil {
+		return nil
+	}
+	if _, ok := pool.allowed[*paymaster]; !ok {
+		return fmt.Errorf("%w: %v", ErrPaymasterNotAllowed, paymaster)
+	}
+	return nil
+}
+
+// checkReport decides whether the simulated validation of a transaction lets
+// it into the pool, applying the configured handling of rule violations.
+func (pool *Rip7560BundlerPool) checkReport(tx *types.Transaction, report *types.ValidationReport) error {
+	err := report.Err()
+	if !errors.Is(err, core.ErrValidationRulesViolation) {
+		return err
+	}
+	pool.markViolation(report)
+	switch pool.config.BannedOpcodes {
+	case BannedOpcodesWarn:
+		log.Warn("Accepting RIP-7560 transaction violating validation rules", "hash", tx.Hash(), "violations", report.Violations)
+		return nil
+	case BannedOpcodesOff:
+		return nil
+	}
+	return err
+}
+
+// markViolation counts a transaction breaking the validation rules, once in
+// total and once for each of the broken rules. The pool lock must be held.
+func (pool *Rip7560BundlerPool) markViolation(report *types.ValidationReport) {
+	for _, rule := range report.Rules {
+		pool.violations[rule]++
+	}
+	if !pool.config.Metrics {
+		return
+	}
+	violatedMeter.Mark(1)
+	for _, rule := range report.Rules {
+		metrics.GetOrRegisterCounter("rip7560pool/violation/"+rule, nil).Inc(1)
+	}
+}
+
+// ViolationStats returns the number of transactions submitted to the pool that
+// broke each of the ERC-7562 validation rules, by rule identifier, whether they
+// were rejected or not.
+func (pool *Rip7560BundlerPool) ViolationStats() map[string]uint64 {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	stats := make(map[string]uint64, len(pool.violations))
+	for rule, count := range pool.violations {
+		stats[rule] = count
+	}
+	return stats
+}
+
+// reportAdded updates the metrics after a batch of transactions was added to
+// the pool. The pool lock must be held.
+func (pool *Rip7560BundlerPool) reportAdded(errs []error) {
+	if !pool.config.Metrics {
+		return
+	}
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			acceptedMeter.Mark(1)
+		case errors.Is(err, core.ErrValidationTimeout):
+			timeoutMeter.Mark(1)
+			rejectedMeter.Mark(1)
+		default:
+			rejectedMeter.Mark(1)
+		}
+	}
+	pool.reportSize()
+}
+
+// reportSize updates the pool size gauges. The pool lock must be held.
+func (pool *Rip7560BundlerPool) reportSize() {
+	if !pool.config.Metrics {
+		return
+	}
+	pendingGauge.Update(int64(len(pool.all) - pool.queued))
+	queuedGauge.Update(int64(pool.queued))
+}
@@ -0,0 +1,143 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rip7560pool
+
+import (
+	"cmp"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// txPayer returns the account paying for the gas of a RIP-7560 transaction,
+// which is the paymaster of sponsored transactions and the sender otherwise,
+// together with the maximum amount charged to it.
+func txPayer(tx *types.Transaction) (common.Address, *big.Int) {
+	if payer, cost := tx.PayerCost(); payer != nil {
+		return *payer, cost
+	}
+	return *tx.Rip7560TransactionData().Sender, tx.SenderCost()
+}
+
+// checkPrefund verifies that the payer of a transaction can cover the maximum
+// cost of all of its transactions in the pool and in the pending bundles,
+// including the new one, from the funds it is charged from. If the transaction
+// replaces an old one, the cost of the latter is not accounted for.
+func (pool *Rip7560BundlerPool) checkPrefund(tx *types.Transaction, old *types.Transaction) error {
+	payer, cost := txPayer(tx)
+
+	liability := new(big.Int).Set(cost)
+	if pending := pool.liabilities[payer]; pending != nil {
+		liability.Add(liability, pending)
+	}
+	liability.Add(liability, pool.bundledLiability(payer, tx))
+	if old != nil {
+		if oldPayer, oldCost := txPayer(old); oldPayer == payer {
+			liability.Sub(liability, oldCost)
+		}
+	}
+	if funds := core.Rip7560GasFunds(pool.chain.Config(), pool.state, tx.Rip7560TransactionData()).ToBig(); funds.Cmp(liability) < 0 {
+		return fmt.Errorf("%w: payer %v have %v want %v", core.ErrInsufficientFunds, payer, funds, liability)
+	}
+	return nil
+}
+
+// bundledLiability returns the maximum cost of the transactions of a payer in
+// the pending bundles, leaving out the given transaction and the ones already
+// accounted for as individually submitted. Transactions in several bundles are
+// only counted once.
+func (pool *Rip7560BundlerPool) bundledLiability(payer common.Address, tx *types.Transaction) *big.Int {
+	var (
+		liability = new(big.Int)
+		seen      = map[common.Hash]struct{}{tx.Hash(): {}}
+	)
+	for _, bundle := range pool.pendingBundles {
+		for _, btx := range bundle.Transactions {
+			if btx.Type() != types.Rip7560Type {
+				continue
+			}
+			if _, ok := seen[btx.Hash()]; ok {
+				continue
+			}
+			seen[btx.Hash()] = struct{}{}
+			if _, ok := pool.all[btx.Hash()]; ok {
+				continue
+			}
+			if p, cost := txPayer(btx); p == payer {
+				liability.Add(liability, cost)
+			}
+		}
+	}
+	return liability
+}
+
+// addLiability accounts the maximum cost of a transaction to its payer.
+func (pool *Rip7560BundlerPool) addLiability(tx *types.Transaction) {
+	payer, cost := txPayer(tx)
+	if pool.liabilities[payer] == nil {
+		pool.liabilities[payer] = new(big.Int)
+	}
+	pool.liabilities[payer].Add(pool.liabilities[payer], cost)
+}
+
+// subLiability removes the maximum cost of a transaction from its payer.
+func (pool *Rip7560BundlerPool) subLiability(tx *types.Transaction) {
+	payer, cost := txPayer(tx)
+	if liability := pool.liabilities[payer]; liability != nil {
+		if liability.Sub(liability, cost).Sign() <= 0 {
+			delete(pool.liabilities, payer)
+		}
+	}
+}
+
+// evictUnderfunded drops transactions of all payers whose balance in the given
+// state no longer covers the maximum cost of their transactions in the pool.
+// The cheapest transactions are evicted first, the ones with the highest nonces
+// among equally priced ones.
+func (pool *Rip7560BundlerPool) evictUnderfunded(statedb *state.StateDB) {
+	for payer, liability := range pool.liabilities {
+		balance := statedb.GetBalance(payer).ToBig()
+		if balance.Cmp(liability) >= 0 {
+			continue
+		}
+		var txs []*types.Transaction
+		for _, tx := range pool.all {
+			if p, _ := txPayer(tx); p == payer {
+				txs = append(txs, tx)
+			}
+		}
+		slices.SortFunc(txs, func(a, b *types.Transaction) int {
+			if c := a.GasFeeCapCmp(b); c != 0 {
+				return c
+			}
+			return cmp.Compare(b.Nonce(), a.Nonce())
+		})
+		for _, tx := range txs {
+			if balance.Cmp(liability) >= 0 {
+				break
+			}
+			log.Trace("Evicting underfunded RIP-7560 transaction", "hash", tx.Hash(), "payer", payer)
+			pool.removeTx(tx, DropUnderfunded)
+		}
+	}
+}
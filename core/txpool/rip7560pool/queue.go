@@ -0,0 +1,632 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rip7560pool
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ErrQueueFull is returned if a transaction would need to be queued but the
+// pool already holds the maximum number of future transactions.
+var ErrQueueFull = errors.New("rip7560 transaction queue full")
+
+// ErrTxNotFound is returned if a transaction is not among the individually
+// submitted transactions of the pool.
+var ErrTxNotFound = errors.New("transaction not found in rip7560 pool")
+
+// Reasons of removing individually submitted transactions from the pool, as
+// reported by the dropped transaction events.
+const (
+	DropReplaced        = "replaced"
+	DropNonceUsed       = "nonce used"
+	DropUnderfunded     = "payer underfunded"
+	DropPaymasterPolicy = "paymaster not allowed"
+	DropInvalid         = "validation failed"
+)
+
+// DefaultQueueConfig contains the default limits of individually submitted
+// RIP-7560 transactions.
+var DefaultQueueConfig = QueueConfig{
+	PriceBump:   10,
+	KeySlots:    16,
+	KeyQueue:    64,
+	GlobalQueue: 1024,
+}
+
+// QueueConfig are the limits of individually submitted RIP-7560 transactions.
+//
+// Transactions are tracked per nonce sequence, which is the sender for legacy
+// nonces and the (sender, nonce key) pair for RIP-7712 nonces. A transaction
+// is pending if all of the lower nonces of its sequence are either mined or
+// pending, and queued otherwise.
+type QueueConfig struct {
+	PriceBump   uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
+	KeySlots    uint64 // Number of executable transaction slots guaranteed per nonce sequence
+	KeyQueue    uint64 // Maximum number of non-executable transaction slots permitted per nonce sequence
+	GlobalQueue uint64 // Maximum number of non-executable transaction slots for all nonce sequences
+}
+
+// sanitize checks the provided user configurations and changes anything that's
+// unreasonable or unworkable.
+func (config *QueueConfig) sanitize() QueueConfig {
+	conf := *config
+	if conf.PriceBump < 1 {
+		log.Warn("Sanitizing invalid rip7560 pool price bump", "provided", conf.PriceBump, "updated", DefaultQueueConfig.PriceBump)
+		conf.PriceBump = DefaultQueueConfig.PriceBump
+	}
+	if conf.KeySlots < 1 {
+		log.Warn("Sanitizing invalid rip7560 pool key slots", "provided", conf.KeySlots, "updated", DefaultQueueConfig.KeySlots)
+		conf.KeySlots = DefaultQueueConfig.KeySlots
+	}
+	if conf.KeyQueue < 1 {
+		log.Warn("Sanitizing invalid rip7560 pool key queue", "provided", conf.KeyQueue, "updated", DefaultQueueConfig.KeyQueue)
+		conf.KeyQueue = DefaultQueueConfig.KeyQueue
+	}
+	if conf.GlobalQueue < 1 {
+		log.Warn("Sanitizing invalid rip7560 pool global queue", "provided", conf.GlobalQueue, "updated", DefaultQueueConfig.GlobalQueue)
+		conf.GlobalQueue = DefaultQueueConfig.GlobalQueue
+	}
+	return conf
+}
+
+// sequenceID identifies a nonce sequence of a sender.
+type sequenceID struct {
+	sender common.Address
+	key    [24]byte
+}
+
+func newSequenceID(tx *types.Rip7560AccountAbstractionTx) sequenceID {
+	id := sequenceID{sender: *tx.Sender}
+	if tx.NonceKey != nil {
+		copy(id.key[:], math.PaddedBigBytes(tx.NonceKey, 24))
+	}
+	return id
+}
+
+// nonceSequence holds the transactions of a single nonce sequence. The pending
+// transactions are the ones with nonces [next, next+pending), all the other
+// ones are queued.
+type nonceSequence struct {
+	key     *big.Int                      // RIP-7712 nonce key, zero for legacy nonces
+	next    uint64                        // Next nonce to be executed on chain
+	pending uint64                        // Number of executable transactions
+	txs     map[uint64]*types.Transaction // All transactions of the sequence by nonce
+}
+
+func (seq *nonceSequence) queued() int {
+	return len(seq.txs) - int(seq.pending)
+}
+
+// isPending returns whether the transaction with the given nonce is executable.
+func (seq *nonceSequence) isPending(nonce uint64) bool {
+	return nonce >= seq.next && nonce < seq.next+seq.pending
+}
+
+// promote moves queued transactions that became executable to the pending set,
+// returning them. Promotion stops at the first transaction not ready to be
+// executed, as reported by the given callback.
+func (seq *nonceSequence) promote(slots uint64, ready func(*types.Transaction) bool) []*types.Transaction {
+	var promoted []*types.Transaction
+	for seq.pending < slots {
+		tx, ok := seq.txs[seq.next+seq.pending]
+		if !ok || !ready(tx) {
+			break
+		}
+		promoted = append(promoted, tx)
+		seq.pending++
+	}
+	return promoted
+}
+
+// forward discards all transactions below the new next nonce and recomputes
+// the pending set, returning the dropped and the newly promoted transactions.
+// Only the transactions not pending before are checked to be ready.
+func (seq *nonceSequence) forward(next uint64, slots uint64, ready func(*types.Transaction) bool) (dropped, promoted []*types.Transaction) {
+	end := seq.next + seq.pending
+	for nonce, tx := range seq.txs {
+		if nonce < next {
+			dropped = append(dropped, tx)
+			delete(seq.txs, nonce)
+		}
+	}
+	seq.next, seq.pending = next, 0
+	promotable := func(tx *types.Transaction) bool {
+		return tx.Nonce() < end || ready(tx)
+	}
+	for _, tx := range seq.promote(slots, promotable) {
+		if tx.Nonce() >= end {
+			promoted = append(promoted, tx)
+		}
+	}
+	return dropped, promoted
+}
+
+// sorted returns the pending and the queued transactions of the sequence,
+// ordered by nonce.
+func (seq *nonceSequence) sorted() (pending, queued []*types.Transaction) {
+	nonces := make([]uint64, 0, len(seq.txs))
+	for nonce := range seq.txs {
+		nonces = append(nonces, nonce)
+	}
+	slices.Sort(nonces)
+	for _, nonce := range nonces {
+		if seq.isPending(nonce) {
+			pending = append(pending, seq.txs[nonce])
+		} else {
+			queued = append(queued, seq.txs[nonce])
+		}
+	}
+	return pending, queued
+}
+
+// nonceAt returns the next nonce of the given nonce sequence in the given state.
+func (pool *Rip7560BundlerPool) nonceAt(statedb *state.StateDB, head *types.Header, sender common.Address, key *big.Int) (uint64, error) {
+	if key.Sign() == 0 {
+		return statedb.GetNonce(sender), nil
+	}
+	blockCtx := vm.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		GetHash:     func(uint64) common.Hash { return common.Hash{} },
+		Coinbase:    pool.coinbase,
+		BlockNumber: new(big.Int).Add(head.Number, common.Big1),
+		Time:        head.Time,
+		Difficulty:  new(big.Int),
+		Random:      &common.Hash{},
+		BaseFee:     new(big.Int),
+		BlobBaseFee: new(big.Int),
+		GasLimit:    head.GasLimit,
+	}
+	evm := vm.NewEVM(blockCtx, vm.TxContext{GasPrice: new(big.Int)}, statedb.Copy(), pool.chain.Config(), vm.Config{})
+	return core.GetRip7712Nonce(evm, sender, key)
+}
+
+// simulatable returns whether the validation phase of a transaction can be
+// simulated on top of the current head state. RIP-7712 transactions ahead of
+// the on-chain nonce can't be simulated before their predecessors are mined.
+func (seq *nonceSequence) simulatable(tx *types.Transaction) bool {
+	return seq.key.Sign() == 0 || tx.Nonce() == seq.next
+}
+
+// simulate runs the validation phase of a transaction on top of the current
+// head state. Legacy nonces are advanced to the one of the transaction, as if
+// all of its predecessors had been executed. The transaction has to be
+// simulatable in its sequence.
+func (pool *Rip7560BundlerPool) simulate(tx *types.Transaction, seq *nonceSequence) *types.ValidationReport {
+	statedb := pool.state.Copy()
+	if seq.key.Sign() == 0 {
+		statedb.SetNonce(*tx.Rip7560TransactionData().Sender, tx.Nonce())
+	}
+	var (
+		head   = pool.currentHead.Load()
+		config = pool.chain.Config()
+		header = &types.Header{
+			ParentHash: head.Hash(),
+			Coinbase:   pool.coinbase,
+			Number:     new(big.Int).Add(head.Number, common.Big1),
+			GasLimit:   head.GasLimit,
+			Time:       head.Time + 1,
+			Difficulty: new(big.Int),
+		}
+	)
+	if config.IsLondon(header.Number) && head.BaseFee != nil {
+		header.BaseFee = eip1559.CalcBaseFee(config, head)
+	}
+	policy := pool.policy
+	return core.SimulateRip7560ValidationWithPolicy(config, pool.chain, header, statedb, tx, pool.config.ValidationTimeout, &policy)
+}
+
+// checkBounds runs the stateless sanity checks of a transaction, which are
+// otherwise covered by simulating its validation phase.
+func (pool *Rip7560BundlerPool) checkBounds(tx *types.Transaction) error {
+	aatx := tx.Rip7560TransactionData()
+	if tx.GasFeeCapIntCmp(aatx.GasTipCap) < 0 {
+		return core.ErrTipAboveFeeCap
+	}
+	gas, err := aatx.TotalGasLimit()
+	if err != nil {
+		return err
+	}
+	if gas > pool.currentHead.Load().GasLimit {
+		return txpool.ErrGasLimit
+	}
+	preTransactionGas, err := aatx.PreTransactionGasCost()
+	if err != nil {
+		return err
+	}
+	if preTransactionGas > aatx.ValidationGasLimit {
+		return fmt.Errorf("%w: have %d, want %d", core.ErrIntrinsicGas, aatx.ValidationGasLimit, preTransactionGas)
+	}
+	return nil
+}
+
+// simulated returns whether the validation phase of a transaction about to
+// become executable was simulated successfully, simulating it now if it was
+// deferred on admission. Deferred transactions that can't be simulated yet or
+// fail their simulation are kept queued. The pool lock must be held.
+func (pool *Rip7560BundlerPool) simulated(seq *nonceSequence, tx *types.Transaction) bool {
+	if _, ok := pool.deferred[tx.Hash()]; !ok {
+		return true
+	}
+	if !seq.simulatable(tx) {
+		return false
+	}
+	if err := pool.checkReport(tx, pool.simulate(tx, seq)); err != nil {
+		log.Trace("Deferred RIP-7560 transaction failed validation", "hash", tx.Hash(), "err", err)
+		return false
+	}
+	delete(pool.deferred, tx.Hash())
+	return true
+}
+
+// addTx validates and inserts an individually submitted RIP-7560 transaction,
+// returning the transactions that became executable. Transactions relayed by
+// trusted peers and RIP-7712 transactions that can't be simulated yet are only
+// checked for their bounds, their simulation being deferred until they are
+// about to become executable. The pool lock must be held.
+func (pool *Rip7560BundlerPool) addTx(tx *types.Transaction, trusted bool) ([]*types.Transaction, error) {
+	if tx.Type() != types.Rip7560Type {
+		return nil, fmt.Errorf("%w: tx type %v not supported by this pool", core.ErrTxTypeNotSupported, tx.Type())
+	}
+	if pool.all[tx.Hash()] != nil {
+		return nil, txpool.ErrAlreadyKnown
+	}
+	var (
+		aatx = tx.Rip7560TransactionData()
+		head = pool.currentHead.Load()
+		next = new(big.Int).Add(head.Number, common.Big1)
+		cfg  = pool.chain.Config()
+	)
+	if aatx.Sender == nil {
+		return nil, txpool.ErrInvalidSender
+	}
+	if !cfg.IsRIP7560(next, head.Time) {
+		return nil, fmt.Errorf("%w: rip7560 not active", core.ErrTxTypeNotSupported)
+	}
+	if tx.Rip7560LegacyType() && !cfg.IsRIP7560LegacyType(next) {
+		return nil, fmt.Errorf("%w: rip7560 legacy type", core.ErrTxTypeNotSupported)
+	}
+	if tx.ChainId().Cmp(cfg.ChainID) != 0 {
+		return nil, fmt.Errorf("%w: have %v, want %v", types.ErrInvalidChainId, tx.ChainId(), cfg.ChainID)
+	}
+	if aatx.IsRip7712Nonce() && !cfg.IsRIP7712(next, head.Time) {
+		return nil, errors.New("RIP-7712 nonce is disabled")
+	}
+	if err := pool.checkPaymaster(tx); err != nil {
+		return nil, err
+	}
+	if err := pool.checkReputation(tx); err != nil {
+		return nil, err
+	}
+	id := newSequenceID(aatx)
+	seq := pool.sequences[id]
+	if seq == nil {
+		key := new(big.Int)
+		if aatx.NonceKey != nil {
+			key.Set(aatx.NonceKey)
+		}
+		nonce, err := pool.nonceAt(pool.state, head, *aatx.Sender, key)
+		if err != nil {
+			return nil, err
+		}
+		seq = &nonceSequence{key: key, next: nonce, txs: make(map[uint64]*types.Transaction)}
+	}
+	nonce := tx.Nonce()
+	if nonce < seq.next {
+		return nil, fmt.Errorf("%w: next nonce %v, tx nonce %v", core.ErrNonceTooLow, seq.next, nonce)
+	}
+	if limit := seq.next + pool.queueConfig.KeySlots + pool.queueConfig.KeyQueue; nonce >= limit {
+		return nil, fmt.Errorf("%w: next nonce %v, tx nonce %v", core.ErrNonceTooHigh, seq.next, nonce)
+	}
+	old := seq.txs[nonce]
+	if old != nil {
+		feeCap, tipCap := pool.bumpedFees(old)
+		if tx.GasFeeCapIntCmp(feeCap) < 0 || tx.GasTipCapIntCmp(tipCap) < 0 {
+			return nil, txpool.ErrReplaceUnderpriced
+		}
+	}
+	if err := pool.checkPrefund(tx, old); err != nil {
+		return nil, err
+	}
+	// Replacements of executable transactions have to be simulated right away
+	deferred := (trusted || !seq.simulatable(tx)) && !(old != nil && seq.isPending(nonce))
+	validate := func(seq *nonceSequence) error {
+		if deferred {
+			return pool.checkBounds(tx)
+		}
+		return pool.checkReport(tx, pool.simulate(tx, seq))
+	}
+	if old != nil {
+		if err := validate(seq); err != nil {
+			return nil, err
+		}
+		pool.subLiability(old)
+		delete(pool.all, old.Hash())
+		delete(pool.deferred, old.Hash())
+		pool.recordDrop(old, DropReplaced)
+		seq.txs[nonce] = tx
+		pool.all[tx.Hash()] = tx
+		if deferred {
+			pool.deferred[tx.Hash()] = struct{}{}
+		}
+		pool.addLiability(tx)
+		pool.markSeen(tx)
+		pool.sequences[id] = seq
+		if seq.isPending(nonce) {
+			return []*types.Transaction{tx}, nil
+		}
+		return nil, nil
+	}
+	// New nonce, make sure it fits into the queue if it's not executable
+	queued := seq.queued()
+	if deferred || nonce != seq.next+seq.pending || seq.pending >= pool.queueConfig.KeySlots {
+		if uint64(seq.queued()) >= pool.queueConfig.KeyQueue {
+			return nil, txpool.ErrAccountLimitExceeded
+		}
+		if uint64(pool.queued) >= pool.queueConfig.GlobalQueue {
+			return nil, ErrQueueFull
+		}
+	}
+	if err := validate(seq); err != nil {
+		return nil, err
+	}
+	seq.txs[nonce] = tx
+	pool.all[tx.Hash()] = tx
+	if deferred {
+		pool.deferred[tx.Hash()] = struct{}{}
+	}
+	pool.addLiability(tx)
+	pool.markSeen(tx)
+	pool.sequences[id] = seq
+
+	promoted := seq.promote(pool.queueConfig.KeySlots, func(tx *types.Transaction) bool {
+		return pool.simulated(seq, tx)
+	})
+	pool.queued += seq.queued() - queued
+	return promoted, nil
+}
+
+// bumpedFees returns the minimal fee and tip caps of a transaction replacing
+// the given one, as replacements require bumping both of its fees.
+func (pool *Rip7560BundlerPool) bumpedFees(old *types.Transaction) (feeCap, tipCap *big.Int) {
+	bump := new(big.Int).SetUint64(100 + pool.queueConfig.PriceBump)
+	feeCap = new(big.Int).Div(new(big.Int).Mul(old.GasFeeCap(), bump), big.NewInt(100))
+	tipCap = new(big.Int).Div(new(big.Int).Mul(old.GasTipCap(), bump), big.NewInt(100))
+	return feeCap, tipCap
+}
+
+// ReplacementFee returns the minimal fee and tip caps of a transaction replacing
+// the given individually submitted one. Besides the price bump, the fee cap is
+// raised to afford the tip on top of the base fee of the next block, so that
+// the replacement of a stuck transaction is includable right away.
+func (pool *Rip7560BundlerPool) ReplacementFee(hash common.Hash) (feeCap, tipCap *big.Int, err error) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	old := pool.all[hash]
+	if old == nil {
+		return nil, nil, ErrTxNotFound
+	}
+	feeCap, tipCap = pool.bumpedFees(old)
+
+	head := pool.currentHead.Load()
+	if head.BaseFee != nil {
+		baseFee := eip1559.CalcBaseFee(pool.chain.Config(), head)
+		if minFeeCap := new(big.Int).Add(baseFee, tipCap); feeCap.Cmp(minFeeCap) < 0 {
+			feeCap = minFeeCap
+		}
+	}
+	return feeCap, tipCap, nil
+}
+
+// recountQueued recalculates the total number of queued transactions.
+func (pool *Rip7560BundlerPool) recountQueued() {
+	pool.queued = 0
+	for _, seq := range pool.sequences {
+		pool.queued += seq.queued()
+	}
+}
+
+// resetSequences advances all tracked nonce sequences to the given state,
+// dropping the transactions that were included (or invalidated) and promoting
+// the ones that became executable. The transactions to promote are simulated
+// again on the new state, dropping the ones failing their validation. The pool
+// lock must be held.
+func (pool *Rip7560BundlerPool) resetSequences(statedb *state.StateDB, head *types.Header) []*types.Transaction {
+	var promoted, invalid []*types.Transaction
+	for id, seq := range pool.sequences {
+		next, err := pool.nonceAt(statedb, head, id.sender, seq.key)
+		if err != nil {
+			log.Warn("Failed to retrieve RIP-7560 sender nonce", "sender", id.sender, "key", seq.key, "err", err)
+			continue
+		}
+		dropped, added := seq.forward(next, pool.queueConfig.KeySlots, func(tx *types.Transaction) bool {
+			if !seq.simulatable(tx) {
+				return false
+			}
+			if err := pool.checkReport(tx, pool.simulate(tx, seq)); err != nil {
+				log.Trace("Promoted RIP-7560 transaction failed validation", "hash", tx.Hash(), "err", err)
+				invalid = append(invalid, tx)
+				return false
+			}
+			delete(pool.deferred, tx.Hash())
+			return true
+		})
+		for _, tx := range dropped {
+			delete(pool.all, tx.Hash())
+			delete(pool.deferred, tx.Hash())
+			pool.subLiability(tx)
+			pool.recordDrop(tx, DropNonceUsed)
+		}
+		promoted = append(promoted, added...)
+		if len(seq.txs) == 0 {
+			delete(pool.sequences, id)
+		}
+	}
+	for _, tx := range invalid {
+		pool.removeTx(tx, DropInvalid)
+	}
+	pool.evictUnderfunded(statedb)
+	pool.recountQueued()
+
+	// Evictions may have demoted some of the promoted transactions
+	promoted = slices.DeleteFunc(promoted, func(tx *types.Transaction) bool {
+		return pool.all[tx.Hash()] == nil
+	})
+	return promoted
+}
+
+// removeTx removes a transaction from its nonce sequence for the given reason,
+// demoting all the transactions with higher nonces. The queued counter is not
+// maintained and has to be recalculated by the caller.
+func (pool *Rip7560BundlerPool) removeTx(tx *types.Transaction, reason string) {
+	id := newSequenceID(tx.Rip7560TransactionData())
+	seq := pool.sequences[id]
+	if seq == nil {
+		return
+	}
+	nonce := tx.Nonce()
+	if seq.isPending(nonce) {
+		seq.pending = nonce - seq.next
+	}
+	delete(seq.txs, nonce)
+	delete(pool.all, tx.Hash())
+	delete(pool.deferred, tx.Hash())
+	pool.subLiability(tx)
+	pool.recordDrop(tx, reason)
+	if len(seq.txs) == 0 {
+		delete(pool.sequences, id)
+	}
+}
+
+// pendingHeads is a heap of the pending transactions of the nonce sequences,
+// ordered by the effective tip of their lowest nonce transaction.
+type pendingHeads struct {
+	txs  [][]*types.Transaction // Pending transactions of every sequence, by nonce
+	tips []*big.Int             // Effective tips of the heads of the sequences
+}
+
+func (h *pendingHeads) Len() int { return len(h.txs) }
+
+// Less orders by descending tip, breaking ties by hash for a deterministic order.
+func (h *pendingHeads) Less(i, j int) bool {
+	if c := h.tips[i].Cmp(h.tips[j]); c != 0 {
+		return c > 0
+	}
+	return h.txs[i][0].Hash().Cmp(h.txs[j][0].Hash()) < 0
+}
+
+func (h *pendingHeads) Swap(i, j int) {
+	h.txs[i], h.txs[j] = h.txs[j], h.txs[i]
+	h.tips[i], h.tips[j] = h.tips[j], h.tips[i]
+}
+
+// Push is never called, sequences are only removed from the heap.
+func (h *pendingHeads) Push(x any) {}
+
+func (h *pendingHeads) Pop() any {
+	n := len(h.txs) - 1
+	h.txs, h.tips = h.txs[:n], h.tips[:n]
+	return nil
+}
+
+// pendingBundle returns the executable individually submitted transactions as
+// a bundle for the next block. Transactions are ordered by their effective tip
+// at the given base fee, keeping the nonce order within every nonce sequence.
+// A transaction not affording the base fee is left out, along with the rest of
+// its sequence. The pool lock must be held.
+func (pool *Rip7560BundlerPool) pendingBundle(number *big.Int, baseFee *big.Int) *types.ExternallyReceivedBundle {
+	heads := new(pendingHeads)
+	for _, id := range pool.sortedSequences(nil) {
+		pending, _ := pool.sequences[id].sorted()
+		if len(pending) == 0 {
+			continue
+		}
+		tip, err := pending[0].EffectiveGasTip(baseFee)
+		if err != nil {
+			continue
+		}
+		heads.txs = append(heads.txs, pending)
+		heads.tips = append(heads.tips, tip)
+	}
+	heap.Init(heads)
+
+	var txs types.Transactions
+	for heads.Len() > 0 {
+		if pool.config.MaxBundleSize != nil && uint64(len(txs)) >= *pool.config.MaxBundleSize {
+			break
+		}
+		txs = append(txs, heads.txs[0][0])
+		if heads.txs[0] = heads.txs[0][1:]; len(heads.txs[0]) == 0 {
+			heap.Pop(heads)
+			continue
+		}
+		tip, err := heads.txs[0][0].EffectiveGasTip(baseFee)
+		if err != nil {
+			heap.Pop(heads)
+			continue
+		}
+		heads.tips[0] = tip
+		heap.Fix(heads, 0)
+	}
+	if len(txs) == 0 {
+		return nil
+	}
+	return &types.ExternallyReceivedBundle{
+		BundlerId:     "local",
+		BundleHash:    ethapi.CalculateBundleHash(txs),
+		ValidForBlock: number,
+		Transactions:  txs,
+	}
+}
+
+// recordDrop queues the announcement of a removed transaction. Events are only
+// sent once the pool lock is released, so that slow subscribers cannot stall
+// the pool.
+func (pool *Rip7560BundlerPool) recordDrop(tx *types.Transaction, reason string) {
+	pool.drops = append(pool.drops, core.Rip7560TxDroppedEvent{Tx: tx, Reason: reason})
+}
+
+// takeDrops returns and clears the drop events recorded under the pool lock.
+func (pool *Rip7560BundlerPool) takeDrops() []core.Rip7560TxDroppedEvent {
+	drops := pool.drops
+	pool.drops = nil
+	return drops
+}
+
+// sendDrops announces the given drop events. It must be called without holding
+// the pool lock.
+func (pool *Rip7560BundlerPool) sendDrops(drops []core.Rip7560TxDroppedEvent) {
+	for _, ev := range drops {
+		pool.dropFeed.Send(ev)
+	}
+}
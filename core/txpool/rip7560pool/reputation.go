@@ -0,0 +1,362 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rip7560pool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Reputation statuses of the ERC-7562 entities.
+const (
+	ReputationOK        = "ok"
+	ReputationThrottled = "throttled"
+	ReputationBanned    = "banned"
+)
+
+const (
+	// reputationDecayInterval is the period after which the counters of the
+	// entities decay, making old misbehaviour fade away.
+	reputationDecayInterval = time.Hour
+
+	// maxReputationDecays caps the number of decays applied at once, after which
+	// all counters are long gone anyway.
+	maxReputationDecays = 24 * 30
+
+	// throttledEntityLimit is the number of pooled transactions allowed to use
+	// a throttled entity.
+	throttledEntityLimit = 4
+)
+
+var (
+	// ErrEntityBanned is returned if a transaction uses a paymaster or deployer
+	// banned for its reputation.
+	ErrEntityBanned = errors.New("entity banned")
+
+	// ErrEntityThrottled is returned if a transaction uses a paymaster or
+	// deployer throttled for its reputation, which already has the maximum
+	// number of pooled transactions.
+	ErrEntityThrottled = errors.New("entity throttled")
+)
+
+// ReputationEntry is the reputation of a paymaster or deployer: the number of
+// transactions using it seen by the pool and included in blocks, the number of
+// them failing their validation in its frame when included, and the status
+// derived from them, unless set manually.
+type ReputationEntry struct {
+	Address            common.Address `json:"address"`
+	OpsSeen            uint64         `json:"opsSeen"`
+	OpsIncluded        uint64         `json:"opsIncluded"`
+	ValidationFailures uint64         `json:"validationFailures"`
+	Status             string         `json:"status"`
+	Pinned             bool           `json:"pinned,omitempty"` // Whether the status is set manually
+}
+
+// reputationStore is the on-disk format of the reputation.
+type reputationStore struct {
+	LastDecay uint64             `json:"lastDecay"`
+	Entries   []*ReputationEntry `json:"entries"`
+}
+
+// reputation tracks the ERC-7562 reputation of the paymasters and deployers of
+// the transactions of the pool, optionally persisted to a file so that it isn't
+// lost on restarts. The counters decay by a 24th every hour, including the time
+// the node was down.
+type reputation struct {
+	path      string                              // File the reputation is persisted to (empty if kept in memory)
+	entries   map[common.Address]*ReputationEntry // Reputation of the entities by address
+	lastDecay time.Time                           // Time the counters last decayed
+}
+
+// newReputation creates the reputation tracker, loading the entries persisted to
+// the given file if any. A missing or corrupt file starts from scratch.
+func newReputation(path string, now time.Time) *reputation {
+	r := &reputation{
+		path:      path,
+		entries:   make(map[common.Address]*ReputationEntry),
+		lastDecay: now,
+	}
+	if path == "" {
+		return r
+	}
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warn("Failed to read RIP-7560 reputation", "path", path, "err", err)
+		}
+		return r
+	}
+	var store reputationStore
+	if err := json.Unmarshal(blob, &store); err != nil {
+		log.Warn("Failed to decode RIP-7560 reputation, starting afresh", "path", path, "err", err)
+		return r
+	}
+	for _, entry := range store.Entries {
+		r.entries[entry.Address] = entry
+	}
+	if last := time.Unix(int64(store.LastDecay), 0); last.Before(now) {
+		r.lastDecay = last
+	}
+	r.decay(now)
+	return r
+}
+
+// save persists the reputation to its file, if any, replacing the file at once.
+func (r *reputation) save() error {
+	if r.path == "" {
+		return nil
+	}
+	store := reputationStore{LastDecay: uint64(r.lastDecay.Unix()), Entries: r.sorted()}
+	blob, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, blob, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.path)
+}
+
+// sorted returns the entries ordered by address.
+func (r *reputation) sorted() []*ReputationEntry {
+	entries := make([]*ReputationEntry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		entries = append(entries, entry)
+	}
+	slices.SortFunc(entries, func(a, b *ReputationEntry) int { return a.Address.Cmp(b.Address) })
+	return entries
+}
+
+// entry returns the reputation of an entity, creating it if missing.
+func (r *reputation) entry(addr common.Address) *ReputationEntry {
+	entry := r.entries[addr]
+	if entry == nil {
+		entry = &ReputationEntry{Address: addr}
+		r.entries[addr] = entry
+	}
+	return entry
+}
+
+// status returns the reputation status of an entity under the given thresholds.
+// Entities are throttled and then banned once the transactions seen using them
+// exceed the ones included by more than the tolerated slack, or once they fail
+// more validations on inclusion than tolerated. Without thresholds, only the
+// manually set statuses apply.
+func (r *reputation) status(config ReputationConfig, addr common.Address) string {
+	entry := r.entries[addr]
+	if entry == nil {
+		return ReputationOK
+	}
+	if entry.Pinned {
+		return entry.Status
+	}
+	var throttled, banned bool
+	if config.MinInclusionDenominator != 0 {
+		maxSeen := entry.OpsSeen / config.MinInclusionDenominator
+		throttled = maxSeen > entry.OpsIncluded+config.ThrottlingSlack
+		banned = maxSeen > entry.OpsIncluded+config.BanSlack
+	}
+	if config.ThrottlingFailures != 0 && entry.ValidationFailures > config.ThrottlingFailures {
+		throttled = true
+	}
+	if config.BanFailures != 0 && entry.ValidationFailures > config.BanFailures {
+		banned = true
+	}
+	switch {
+	case banned:
+		return ReputationBanned
+	case throttled:
+		return ReputationThrottled
+	default:
+		return ReputationOK
+	}
+}
+
+// decay reduces all counters by a 24th for every interval elapsed since the
+// last decay, dropping the entries left empty. It returns whether anything
+// decayed.
+func (r *reputation) decay(now time.Time) bool {
+	elapsed := now.Sub(r.lastDecay)
+	if elapsed < reputationDecayInterval {
+		return false
+	}
+	n := elapsed / reputationDecayInterval
+	r.lastDecay = r.lastDecay.Add(n * reputationDecayInterval)
+	for i := 0; i < min(int(n), maxReputationDecays); i++ {
+		for addr, entry := range r.entries {
+			entry.OpsSeen = entry.OpsSeen * 23 / 24
+			entry.OpsIncluded = entry.OpsIncluded * 23 / 24
+			entry.ValidationFailures = entry.ValidationFailures * 23 / 24
+			if entry.OpsSeen == 0 && entry.OpsIncluded == 0 && entry.ValidationFailures == 0 && !entry.Pinned {
+				delete(r.entries, addr)
+			}
+		}
+	}
+	return true
+}
+
+// set overrides the reputation of the given entities. Entries with a status
+// pin it, while entries without one let it be derived from the counters again.
+func (r *reputation) set(entries []ReputationEntry) error {
+	for _, entry := range entries {
+		switch entry.Status {
+		case "", ReputationOK, ReputationThrottled, ReputationBanned:
+		default:
+			return fmt.Errorf("invalid reputation status %q, must be one of %s, %s, %s", entry.Status, ReputationOK, ReputationThrottled, ReputationBanned)
+		}
+	}
+	for _, entry := range entries {
+		r.entries[entry.Address] = &ReputationEntry{
+			Address:            entry.Address,
+			OpsSeen:            entry.OpsSeen,
+			OpsIncluded:        entry.OpsIncluded,
+			ValidationFailures: entry.ValidationFailures,
+			Status:             entry.Status,
+			Pinned:             entry.Status != "",
+		}
+	}
+	return nil
+}
+
+// txEntities returns the paymaster and the deployer of a transaction, if any.
+func txEntities(tx *types.Transaction) []common.Address {
+	var (
+		aatx     = tx.Rip7560TransactionData()
+		entities []common.Address
+	)
+	if aatx.Paymaster != nil {
+		entities = append(entities, *aatx.Paymaster)
+	}
+	if aatx.Deployer != nil {
+		entities = append(entities, *aatx.Deployer)
+	}
+	return entities
+}
+
+// checkReputation rejects transactions using banned entities, or throttled ones
+// already used by the maximum number of pooled transactions. The pool lock must
+// be held.
+func (pool *Rip7560BundlerPool) checkReputation(tx *types.Transaction) error {
+	for _, entity := range txEntities(tx) {
+		switch pool.reputation.status(pool.config.Reputation, entity) {
+		case ReputationBanned:
+			return fmt.Errorf("%w: %v", ErrEntityBanned, entity)
+		case ReputationThrottled:
+			var pooled int
+			for _, ptx := range pool.all {
+				if slices.Contains(txEntities(ptx), entity) {
+					pooled++
+				}
+			}
+			if pooled >= throttledEntityLimit {
+				return fmt.Errorf("%w: %v", ErrEntityThrottled, entity)
+			}
+		}
+	}
+	return nil
+}
+
+// markSeen counts a transaction accepted by the pool towards the reputation of
+// its entities. The pool lock must be held.
+func (pool *Rip7560BundlerPool) markSeen(tx *types.Transaction) {
+	for _, entity := range txEntities(tx) {
+		pool.reputation.entry(entity).OpsSeen++
+	}
+}
+
+// markIncluded counts the RIP-7560 transactions of a new block towards the
+// reputation of their entities, and decays the reputation when due. The pool
+// lock must be held.
+func (pool *Rip7560BundlerPool) markIncluded(head *types.Header) {
+	if block := pool.chain.GetBlock(head.Hash(), head.Number.Uint64()); block != nil {
+		for _, tx := range block.Transactions() {
+			if tx.Type() != types.Rip7560Type {
+				continue
+			}
+			for _, entity := range txEntities(tx) {
+				pool.reputation.entry(entity).OpsIncluded++
+			}
+		}
+	}
+	if pool.reputation.decay(time.Now()) {
+		if err := pool.reputation.save(); err != nil {
+			log.Warn("Failed to persist RIP-7560 reputation", "err", err)
+		}
+	}
+}
+
+// ReportRip7560Failure counts a transaction failing its validation when included
+// in a block towards the reputation of the entity of the failing frame, i.e. its
+// paymaster or deployer. Failures in the other frames are not attributable to
+// an entity.
+func (pool *Rip7560BundlerPool) ReportRip7560Failure(tx *types.Transaction, frame string) {
+	var (
+		aatx   = tx.Rip7560TransactionData()
+		entity *common.Address
+	)
+	switch frame {
+	case core.FramePaymaster:
+		entity = aatx.Paymaster
+	case core.FrameDeployer:
+		entity = aatx.Deployer
+	}
+	if entity == nil {
+		return
+	}
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.reputation.entry(*entity).ValidationFailures++
+}
+
+// Reputation returns the reputation of all the paymasters and deployers known
+// to the pool, ordered by address.
+func (pool *Rip7560BundlerPool) Reputation() []ReputationEntry {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	entries := make([]ReputationEntry, 0, len(pool.reputation.entries))
+	for _, entry := range pool.reputation.sorted() {
+		report := *entry
+		report.Status = pool.reputation.status(pool.config.Reputation, entry.Address)
+		entries = append(entries, report)
+	}
+	return entries
+}
+
+// SetReputation overrides the reputation of the given paymasters and deployers,
+// persisting the result. Entries with a status pin it until overridden again,
+// while entries without one derive it from their counters.
+func (pool *Rip7560BundlerPool) SetReputation(entries []ReputationEntry) error {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if err := pool.reputation.set(entries); err != nil {
+		return err
+	}
+	return pool.reputation.save()
+}
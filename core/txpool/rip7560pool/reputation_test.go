@@ -0,0 +1,179 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rip7560pool
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// Tests that the reputation status follows the ERC-7562 thresholds, and that
+// the counters decay over time, including across restarts.
+func TestReputationDecay(t *testing.T) {
+	var (
+		entity = common.Address{0xbb}
+		path   = filepath.Join(t.TempDir(), "reputation.json")
+		start  = time.Unix(1_700_000_000, 0)
+	)
+	r := newReputation(path, start)
+	tests := []struct {
+		seen, included uint64
+		status         string
+	}{
+		{100, 0, ReputationOK},
+		{109, 0, ReputationOK},
+		{120, 0, ReputationThrottled},
+		{600, 10, ReputationThrottled},
+		{610, 0, ReputationBanned},
+	}
+	for i, tt := range tests {
+		r.entry(entity).OpsSeen, r.entry(entity).OpsIncluded = tt.seen, tt.included
+		if status := r.status(DefaultReputationConfig, entity); status != tt.status {
+			t.Errorf("test %d: status mismatch: have %s, want %s", i, status, tt.status)
+		}
+	}
+	if r.decay(start.Add(time.Minute)) {
+		t.Fatal("reputation decayed before the interval")
+	}
+	if !r.decay(start.Add(time.Hour)) {
+		t.Fatal("reputation not decayed after the interval")
+	}
+	if entry := r.entries[entity]; entry.OpsSeen != 584 {
+		t.Fatalf("decayed counter mismatch: have %d, want 584", entry.OpsSeen)
+	}
+	if err := r.save(); err != nil {
+		t.Fatalf("failed to save reputation: %v", err)
+	}
+	// The time the node was down counts towards the decay
+	r = newReputation(path, start.Add(2*time.Hour))
+	if entry := r.entries[entity]; entry == nil || entry.OpsSeen != 559 {
+		t.Fatalf("restored counter mismatch: have %v, want 559", entry)
+	}
+	r = newReputation(path, start.Add(365*24*time.Hour))
+	if len(r.entries) != 0 {
+		t.Fatalf("entries not expired: %v", r.entries)
+	}
+}
+
+// Tests that banned and throttled entities are applied on admission, and that
+// the reputation overridden through the pool survives restarts.
+func TestReputationAdmission(t *testing.T) {
+	pool, chain := newTestPoolWithConfig(t, DefaultConfig)
+
+	var (
+		banned    = common.Address{0xbb}
+		throttled = common.Address{0xcc}
+	)
+	for _, paymaster := range []common.Address{banned, throttled} {
+		chain.statedb.SetCode(paymaster, paymasterCode())
+		chain.statedb.SetBalance(paymaster, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+	}
+	for i := 0; i <= throttledEntityLimit; i++ {
+		chain.statedb.SetCode(common.Address{byte(i + 1)}, accountCode(nil))
+	}
+	if err := pool.SetReputation([]ReputationEntry{{Address: banned, Status: "unknown"}}); err == nil {
+		t.Fatal("invalid reputation status accepted")
+	}
+	err := pool.SetReputation([]ReputationEntry{
+		{Address: banned, Status: ReputationBanned},
+		{Address: throttled, Status: ReputationThrottled},
+	})
+	if err != nil {
+		t.Fatalf("failed to set reputation: %v", err)
+	}
+	if err := pool.Add([]*types.Transaction{sponsoredTx(common.Address{1}, banned, 1)}, false, false)[0]; !errors.Is(err, ErrEntityBanned) {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrEntityBanned)
+	}
+	for i := 0; i <= throttledEntityLimit; i++ {
+		err := pool.Add([]*types.Transaction{sponsoredTx(common.Address{byte(i + 1)}, throttled, 1)}, false, false)[0]
+		if i < throttledEntityLimit && err != nil {
+			t.Fatalf("tx %d: throttled entity rejected below its limit: %v", i, err)
+		}
+		if i == throttledEntityLimit && !errors.Is(err, ErrEntityThrottled) {
+			t.Fatalf("tx %d: error mismatch: have %v, want %v", i, err, ErrEntityThrottled)
+		}
+	}
+	if entries := pool.Reputation(); len(entries) != 2 || entries[1].OpsSeen != throttledEntityLimit || !entries[1].Pinned {
+		t.Fatalf("reputation mismatch: %+v", entries)
+	}
+	// Unpinning the status derives it from the counters again
+	if err := pool.SetReputation([]ReputationEntry{{Address: banned}}); err != nil {
+		t.Fatalf("failed to unpin reputation: %v", err)
+	}
+	if err := pool.Close(); err != nil {
+		t.Fatalf("failed to close pool: %v", err)
+	}
+	if _, err := os.Stat(pool.config.ReputationFile); err != nil {
+		t.Fatalf("reputation not persisted: %v", err)
+	}
+	restarted := New(pool.config, chain, common.Address{})
+	entries := restarted.Reputation()
+	if len(entries) != 2 || entries[0].Status != ReputationOK || entries[1].Status != ReputationThrottled {
+		t.Fatalf("restored reputation mismatch: %+v", entries)
+	}
+}
+
+// Tests that the validation failures reported on inclusion are charged to the
+// entity of the failing frame, throttling and then banning it.
+func TestReputationFailures(t *testing.T) {
+	pool, chain := newTestPoolWithConfig(t, DefaultConfig)
+
+	paymaster := common.Address{0xbb}
+	chain.statedb.SetCode(paymaster, paymasterCode())
+	chain.statedb.SetBalance(paymaster, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+	chain.statedb.SetCode(common.Address{1}, accountCode(nil))
+
+	tx := sponsoredTx(common.Address{1}, paymaster, 1)
+	pool.ReportRip7560Failure(tx, core.FrameAccount)
+	pool.ReportRip7560Failure(tx, core.FrameDeployer)
+	if entries := pool.Reputation(); len(entries) != 0 {
+		t.Fatalf("failures outside the paymaster frames charged: %+v", entries)
+	}
+	status := func() string {
+		entries := pool.Reputation()
+		if len(entries) != 1 || entries[0].Address != paymaster {
+			t.Fatalf("reputation mismatch: %+v", entries)
+		}
+		return entries[0].Status
+	}
+	for i := uint64(0); i < DefaultReputationConfig.ThrottlingFailures; i++ {
+		pool.ReportRip7560Failure(tx, core.FramePaymaster)
+	}
+	if status := status(); status != ReputationOK {
+		t.Fatalf("status mismatch at the throttling threshold: have %s, want %s", status, ReputationOK)
+	}
+	pool.ReportRip7560Failure(tx, core.FramePaymaster)
+	if status := status(); status != ReputationThrottled {
+		t.Fatalf("status mismatch past the throttling threshold: have %s, want %s", status, ReputationThrottled)
+	}
+	for i := DefaultReputationConfig.ThrottlingFailures + 1; i <= DefaultReputationConfig.BanFailures; i++ {
+		pool.ReportRip7560Failure(tx, core.FramePaymaster)
+	}
+	if err := pool.Add([]*types.Transaction{tx}, false, false)[0]; !errors.Is(err, ErrEntityBanned) {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrEntityBanned)
+	}
+}
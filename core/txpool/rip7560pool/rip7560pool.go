@@ -1,12 +1,16 @@
 package rip7560pool
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -16,28 +20,108 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 	"math/big"
 	"net/http"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
 )
 
+// BlockChain defines the minimal set of methods needed to back an RIP-7560 pool
+// with a blockchain.
+type BlockChain interface {
+	legacypool.BlockChain
+
+	// Engine retrieves the consensus engine, used to resolve block authors.
+	Engine() consensus.Engine
+
+	// GetHeader retrieves a block header, used by the simulated validation frames.
+	GetHeader(hash common.Hash, number uint64) *types.Header
+}
+
+// Banned opcode modes, deciding what happens to transactions whose simulated
+// validation breaks the ERC-7562 validation rules.
+const (
+	BannedOpcodesEnforce = "enforce" // Reject the transaction
+	BannedOpcodesWarn    = "warn"    // Log the violation and accept the transaction
+	BannedOpcodesOff     = "off"     // Ignore the violation
+)
+
+// Config are the configuration parameters of the RIP-7560 transaction pool.
 type Config struct {
-	MaxBundleSize *uint64
-	MaxBundleGas  *uint64
-	PullUrls      []string
+	MaxBundleSize *uint64  `toml:"-"`
+	MaxBundleGas  *uint64  `toml:"-"`
+	PullUrls      []string `toml:"-"`
+
+	Enabled bool // Whether the pool accepts RIP-7560 transactions at all
+
+	// Queue holds the limits of individually submitted transactions
+	Queue QueueConfig
+
+	ValidationTimeout  time.Duration // Maximum time allowed to simulate the validation phase (zero if unlimited)
+	BannedOpcodes      string        // Handling of validation rules violations (enforce, warn or off)
+	PaymasterAllowlist string        // File listing the only paymasters accepted by the pool (empty to accept all)
+	PaymasterBanlist   string        // File listing the paymasters rejected by the pool (empty to reject none)
+	MaxCallDepth       uint64        // Maximum depth of the inner calls of validation frames (zero if unlimited)
+	MaxReentrancy      uint64        // Maximum number of times an address may be on the call stack of a validation frame (zero if unlimited)
+	TrustedDelegates   string        // File listing the only DELEGATECALL targets of validation frames (empty to allow all)
+	Metrics            bool          // Whether to report pool metrics
+
+	// Reputation holds the thresholds of the ERC-7562 entity reputation
+	Reputation     ReputationConfig
+	ReputationFile string // File persisting the entity reputation across restarts (empty to keep it in memory)
+}
+
+// DefaultConfig contains the default configurations for the RIP-7560 pool.
+var DefaultConfig = Config{
+	Enabled:        true,
+	Queue:          DefaultQueueConfig,
+	BannedOpcodes:  BannedOpcodesEnforce,
+	Reputation:     DefaultReputationConfig,
+	ReputationFile: "rip7560reputation.json",
+}
+
+// Validate checks the provided user configurations for values the pool can
+// not operate with.
+func (config *Config) Validate() error {
+	switch config.BannedOpcodes {
+	case BannedOpcodesEnforce, BannedOpcodesWarn, BannedOpcodesOff:
+	default:
+		return fmt.Errorf("invalid banned opcodes mode %q, must be one of %s, %s, %s", config.BannedOpcodes, BannedOpcodesEnforce, BannedOpcodesWarn, BannedOpcodesOff)
+	}
+	if config.Reputation.MinInclusionDenominator == 0 {
+		return errors.New("reputation inclusion denominator must be positive")
+	}
+	return nil
 }
 
 // Rip7560BundlerPool is the transaction pool dedicated to RIP-7560 AA transactions.
 // This implementation relies on an external bundler process to perform most of the hard work.
 type Rip7560BundlerPool struct {
 	config      Config
-	chain       legacypool.BlockChain
+	chain       BlockChain
 	txFeed      event.Feed
+	dropFeed    event.Feed
+	drops       []core.Rip7560TxDroppedEvent // Drop events to send once the lock is released
 	currentHead atomic.Pointer[types.Header] // Current head of the blockchain
 
 	pendingBundles  []*types.ExternallyReceivedBundle
 	includedBundles map[common.Hash]*types.BundleReceipt
 
+	queueConfig QueueConfig                        // Sanitized limits of individually submitted transactions
+	state       *state.StateDB                     // Current state in the blockchain head
+	sequences   map[sequenceID]*nonceSequence      // Individually submitted transactions by nonce sequence
+	all         map[common.Hash]*types.Transaction // All individually submitted transactions
+	deferred    map[common.Hash]struct{}           // Transactions admitted without simulating their validation phase
+	queued      int                                // Number of queued transactions in all sequences
+	liabilities map[common.Address]*big.Int        // Maximum cost of all transactions by gas payer
+
+	allowed map[common.Address]struct{} // Allowed paymasters (nil if all are allowed)
+	banned  map[common.Address]struct{} // Banned paymasters (nil if none are banned)
+	policy  core.ValidationPolicy       // Local limits on the validation frames
+
+	violations map[string]uint64 // Number of transactions breaking each ERC-7562 rule
+	reputation *reputation       // Reputation of the paymasters and deployers
+
 	mu sync.Mutex
 
 	coinbase common.Address
@@ -46,18 +130,43 @@ type Rip7560BundlerPool struct {
 func (pool *Rip7560BundlerPool) Init(_ uint64, head *types.Header, _ txpool.AddressReserver) error {
 	pool.pendingBundles = make([]*types.ExternallyReceivedBundle, 0)
 	pool.includedBundles = make(map[common.Hash]*types.BundleReceipt)
+	pool.sequences = make(map[sequenceID]*nonceSequence)
+	pool.all = make(map[common.Hash]*types.Transaction)
+	pool.deferred = make(map[common.Hash]struct{})
+	pool.liabilities = make(map[common.Address]*big.Int)
+	pool.violations = make(map[string]uint64)
+
+	statedb, err := pool.chain.StateAt(head.Root)
+	if err != nil {
+		return err
+	}
+	pool.state = statedb
 	pool.currentHead.Store(head)
 	return nil
 }
 
 func (pool *Rip7560BundlerPool) Close() error {
-	return nil
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	return pool.reputation.save()
 }
 
 func (pool *Rip7560BundlerPool) Reset(oldHead, newHead *types.Header) {
 	pool.mu.Lock()
-	defer pool.mu.Unlock()
+	promoted := pool.reset(newHead)
+	drops := pool.takeDrops()
+	pool.mu.Unlock()
 
+	pool.sendDrops(drops)
+	if len(promoted) > 0 {
+		pool.txFeed.Send(core.NewTxsEvent{Txs: promoted})
+	}
+}
+
+// reset moves the pool to the new head, returning the transactions that became
+// executable. It must be called with the pool lock held.
+func (pool *Rip7560BundlerPool) reset(newHead *types.Header) []*types.Transaction {
 	newIncludedBundles := pool.gatherIncludedBundlesStats(newHead)
 	for _, included := range newIncludedBundles {
 		pool.includedBundles[included.BundleHash] = included
@@ -72,20 +181,37 @@ func (pool *Rip7560BundlerPool) Reset(oldHead, newHead *types.Header) {
 	}
 	pool.pendingBundles = pendingBundles
 	pool.currentHead.Store(newHead)
+
+	statedb, err := pool.chain.StateAt(newHead.Root)
+	if err != nil {
+		log.Error("Failed to reset RIP-7560 pool state", "err", err)
+		return nil
+	}
+	pool.state = statedb
+	pool.markIncluded(newHead)
+	promoted := pool.resetSequences(statedb, newHead)
+	pool.reportSize()
+	return promoted
 }
 
 // For simplicity, this function assumes 'Reset' called for each new block sequentially.
 func (pool *Rip7560BundlerPool) gatherIncludedBundlesStats(newHead *types.Header) map[common.Hash]*types.BundleReceipt {
 	// 1. Is there a bundle included in the block?
 
-	// note that in 'clique' mode Coinbase is always set to 0x000...000
-	if newHead.Coinbase.Cmp(pool.coinbase) != 0 && newHead.Coinbase.Cmp(common.Address{}) != 0 {
+	// note that in 'clique' mode the header Coinbase is either zero or a vote
+	// candidate, so the actual block author has to be resolved by the engine.
+	// The simulated beacon of '--dev' mode builds blocks with a zero fee recipient.
+	author, err := pool.chain.Engine().Author(newHead)
+	if err != nil || (author.Cmp(pool.coinbase) != 0 && author.Cmp(common.Address{}) != 0) {
 		// not our block
 		return nil
 	}
 
 	// get all transaction hashes in block
 	add := pool.chain.GetBlock(newHead.Hash(), newHead.Number.Uint64())
+	if add == nil {
+		return nil
+	}
 	block := add.Transactions()
 
 	receipts := pool.chain.GetReceiptsByHash(add.Hash())
@@ -164,17 +290,16 @@ OuterLoop:
 func (pool *Rip7560BundlerPool) SetGasTip(_ *big.Int) {}
 
 func (pool *Rip7560BundlerPool) Has(hash common.Hash) bool {
-	pool.mu.Lock()
-	defer pool.mu.Unlock()
-
-	tx := pool.Get(hash)
-	return tx != nil
+	return pool.Get(hash) != nil
 }
 
 func (pool *Rip7560BundlerPool) Get(hash common.Hash) *types.Transaction {
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
+	if tx := pool.all[hash]; tx != nil {
+		return tx
+	}
 	for _, bundle := range pool.pendingBundles {
 		for _, tx := range bundle.Transactions {
 			if tx.Hash().Cmp(hash) == 0 {
@@ -185,14 +310,50 @@ func (pool *Rip7560BundlerPool) Get(hash common.Hash) *types.Transaction {
 	return nil
 }
 
-func (pool *Rip7560BundlerPool) Add(_ []*types.Transaction, _ bool, _ bool) []error {
-	return nil
+// Add enqueues individually submitted RIP-7560 transactions into their nonce
+// sequences. Transactions with a nonce gap are queued until the gap is filled
+// or the lower nonces are mined, and announced once they become executable.
+func (pool *Rip7560BundlerPool) Add(txs []*types.Transaction, _ bool, _ bool) []error {
+	return pool.add(txs, false)
+}
+
+// AddTrusted enqueues RIP-7560 transactions relayed by a trusted peer. They
+// are admitted after checking their bounds, nonces and the balance of their
+// gas payer, deferring the simulation of their validation phase until they are
+// about to become executable.
+func (pool *Rip7560BundlerPool) AddTrusted(txs []*types.Transaction) []error {
+	return pool.add(txs, true)
+}
+
+func (pool *Rip7560BundlerPool) add(txs []*types.Transaction, trusted bool) []error {
+	var (
+		errs     = make([]error, len(txs))
+		promoted []*types.Transaction
+	)
+	pool.mu.Lock()
+	for i, tx := range txs {
+		var added []*types.Transaction
+		added, errs[i] = pool.addTx(tx, trusted)
+		promoted = append(promoted, added...)
+	}
+	pool.reportAdded(errs)
+	drops := pool.takeDrops()
+	pool.mu.Unlock()
+
+	pool.sendDrops(drops)
+	if len(promoted) > 0 {
+		pool.txFeed.Send(core.NewTxsEvent{Txs: promoted})
+	}
+	return errs
 }
 
 func (pool *Rip7560BundlerPool) Pending(_ txpool.PendingFilter) map[common.Address][]*txpool.LazyTransaction {
 	return nil
 }
 
+// PendingRip7560Bundle returns the bundle to include in the next block. Bundles
+// submitted externally take precedence over the ones pulled from the bundlers,
+// which take precedence over the individually submitted transactions.
 func (pool *Rip7560BundlerPool) PendingRip7560Bundle() (*types.ExternallyReceivedBundle, error) {
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
@@ -201,7 +362,34 @@ func (pool *Rip7560BundlerPool) PendingRip7560Bundle() (*types.ExternallyReceive
 	if bundle != nil {
 		return bundle, nil
 	}
-	return pool.fetchBundleFromBundler()
+	bundle, err := pool.fetchBundleFromBundler()
+	if err != nil || (bundle != nil && len(bundle.Transactions) > 0) {
+		return bundle, err
+	}
+	return pool.pendingBundle(pool.nextBlock()), nil
+}
+
+// LocalBundle returns the executable individually submitted transactions as a
+// bundle for the next block, disregarding the externally submitted bundles.
+func (pool *Rip7560BundlerPool) LocalBundle() *types.ExternallyReceivedBundle {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	return pool.pendingBundle(pool.nextBlock())
+}
+
+// nextBlock returns the number and the base fee of the block following the
+// current head.
+func (pool *Rip7560BundlerPool) nextBlock() (*big.Int, *big.Int) {
+	var (
+		head    = pool.currentHead.Load()
+		number  = new(big.Int).Add(head.Number, common.Big1)
+		baseFee *big.Int
+	)
+	if config := pool.chain.Config(); config.IsLondon(number) && head.BaseFee != nil {
+		baseFee = eip1559.CalcBaseFee(config, head)
+	}
+	return number, baseFee
 }
 
 // SubscribeTransactions is not needed for the External Bundler AA sub pool and 'ch' will never be sent anything.
@@ -209,24 +397,88 @@ func (pool *Rip7560BundlerPool) SubscribeTransactions(ch chan<- core.NewTxsEvent
 	return pool.txFeed.Subscribe(ch)
 }
 
+// SubscribeDropped subscribes to the removals of individually submitted
+// transactions from the pool.
+func (pool *Rip7560BundlerPool) SubscribeDropped(ch chan<- core.Rip7560TxDroppedEvent) event.Subscription {
+	return pool.dropFeed.Subscribe(ch)
+}
+
 // Nonce is only used from 'GetPoolNonce' which is not relevant for AA transactions.
 func (pool *Rip7560BundlerPool) Nonce(_ common.Address) uint64 {
 	return 0
 }
 
-// Stats function not implemented for the External Bundler AA sub pool.
+// Stats retrieves the number of individually submitted pending and queued
+// transactions. Externally received bundles are not accounted for.
 func (pool *Rip7560BundlerPool) Stats() (int, int) {
-	return 0, 0
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	return len(pool.all) - pool.queued, pool.queued
 }
 
-// Content function not implemented for the External Bundler AA sub pool.
+// ValidationBacklog retrieves the number of individually submitted transactions
+// admitted without simulating their validation phase, which are kept queued
+// until they are simulated. These are the transactions relayed by trusted peers
+// and the RIP-7712 ones ahead of the on-chain nonce of their sequence.
+func (pool *Rip7560BundlerPool) ValidationBacklog() int {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	return len(pool.deferred)
+}
+
+// Content retrieves the individually submitted pending and queued transactions,
+// grouped by sender and sorted by nonce key and nonce.
 func (pool *Rip7560BundlerPool) Content() (map[common.Address][]*types.Transaction, map[common.Address][]*types.Transaction) {
-	return nil, nil
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pending := make(map[common.Address][]*types.Transaction)
+	queued := make(map[common.Address][]*types.Transaction)
+	for _, id := range pool.sortedSequences(nil) {
+		p, q := pool.sequences[id].sorted()
+		if len(p) > 0 {
+			pending[id.sender] = append(pending[id.sender], p...)
+		}
+		if len(q) > 0 {
+			queued[id.sender] = append(queued[id.sender], q...)
+		}
+	}
+	return pending, queued
 }
 
-// ContentFrom function not implemented for the External Bundler AA sub pool.
-func (pool *Rip7560BundlerPool) ContentFrom(_ common.Address) ([]*types.Transaction, []*types.Transaction) {
-	return nil, nil
+// ContentFrom retrieves the individually submitted pending and queued
+// transactions of a sender, sorted by nonce key and nonce.
+func (pool *Rip7560BundlerPool) ContentFrom(addr common.Address) ([]*types.Transaction, []*types.Transaction) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	var pending, queued []*types.Transaction
+	for _, id := range pool.sortedSequences(&addr) {
+		p, q := pool.sequences[id].sorted()
+		pending = append(pending, p...)
+		queued = append(queued, q...)
+	}
+	return pending, queued
+}
+
+// sortedSequences returns the identifiers of the tracked nonce sequences, optionally
+// filtered by sender, ordered by sender and nonce key.
+func (pool *Rip7560BundlerPool) sortedSequences(sender *common.Address) []sequenceID {
+	ids := make([]sequenceID, 0, len(pool.sequences))
+	for id := range pool.sequences {
+		if sender == nil || id.sender == *sender {
+			ids = append(ids, id)
+		}
+	}
+	slices.SortFunc(ids, func(a, b sequenceID) int {
+		if c := a.sender.Cmp(b.sender); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.key[:], b.key[:])
+	})
+	return ids
 }
 
 // Locals are not necessary for AA Pool
@@ -234,22 +486,47 @@ func (pool *Rip7560BundlerPool) Locals() []common.Address {
 	return []common.Address{}
 }
 
-func (pool *Rip7560BundlerPool) Status(_ common.Hash) txpool.TxStatus {
-	panic("implement me")
+// Status returns the known status of an individually submitted transaction.
+func (pool *Rip7560BundlerPool) Status(hash common.Hash) txpool.TxStatus {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	tx := pool.all[hash]
+	if tx == nil {
+		return txpool.TxStatusUnknown
+	}
+	if pool.sequences[newSequenceID(tx.Rip7560TransactionData())].isPending(tx.Nonce()) {
+		return txpool.TxStatusPending
+	}
+	return txpool.TxStatusQueued
 }
 
 // New creates a new RIP-7560 Account Abstraction Bundler transaction pool.
-func New(config Config, chain legacypool.BlockChain, coinbase common.Address) *Rip7560BundlerPool {
-	return &Rip7560BundlerPool{
-		config:   config,
-		chain:    chain,
-		coinbase: coinbase,
+func New(config Config, chain BlockChain, coinbase common.Address) *Rip7560BundlerPool {
+	pool := &Rip7560BundlerPool{
+		config:      config,
+		chain:       chain,
+		coinbase:    coinbase,
+		queueConfig: config.Queue.sanitize(),
+		reputation:  newReputation(config.ReputationFile, time.Now()),
 	}
+	if err := pool.loadPolicy(config); err != nil {
+		// Failing open would accept paymasters the operator meant to exclude
+		log.Error("Failed to load RIP-7560 pool policy, rejecting all paymasters and delegates", "err", err)
+		pool.allowed, pool.banned = make(map[common.Address]struct{}), nil
+		pool.policy = core.ValidationPolicy{
+			MaxCallDepth:     config.MaxCallDepth,
+			MaxReentrancy:    config.MaxReentrancy,
+			TrustedDelegates: make(map[common.Address]struct{}),
+		}
+	}
+	return pool
 }
 
-// Filter rejects all individual transactions for External Bundler AA sub pool.
-func (pool *Rip7560BundlerPool) Filter(_ *types.Transaction) bool {
-	return false
+// Filter returns whether the given transaction can be consumed by the RIP-7560
+// pool, specifically, whether it is a RIP-7560 transaction.
+func (pool *Rip7560BundlerPool) Filter(tx *types.Transaction) bool {
+	return tx.Type() == types.Rip7560Type
 }
 
 func (pool *Rip7560BundlerPool) SubmitRip7560Bundle(bundle *types.ExternallyReceivedBundle) error {
@@ -258,7 +535,7 @@ func (pool *Rip7560BundlerPool) SubmitRip7560Bundle(bundle *types.ExternallyRece
 
 	currentBlock := pool.currentHead.Load().Number
 	nextBlock := big.NewInt(0).Add(currentBlock, big.NewInt(1))
-	log.Error("RIP-7560 bundle submitted", "validForBlock", bundle.ValidForBlock.String(), "nextBlock", nextBlock.String())
+	log.Debug("RIP-7560 bundle submitted", "validForBlock", bundle.ValidForBlock, "nextBlock", nextBlock)
 	pool.pendingBundles = append(pool.pendingBundles, bundle)
 	if nextBlock.Cmp(bundle.ValidForBlock) == 0 {
 		pool.txFeed.Send(core.NewTxsEvent{Txs: bundle.Transactions})
@@ -266,6 +543,20 @@ func (pool *Rip7560BundlerPool) SubmitRip7560Bundle(bundle *types.ExternallyRece
 	return nil
 }
 
+// ReplaceRip7560Bundle inserts the bundle like SubmitRip7560Bundle, replacing
+// the pending bundle previously submitted by the same bundler for the same
+// block. The transactions are expected to be known to the pool already, so
+// they are not announced again.
+func (pool *Rip7560BundlerPool) ReplaceRip7560Bundle(bundle *types.ExternallyReceivedBundle) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.pendingBundles = slices.DeleteFunc(pool.pendingBundles, func(pending *types.ExternallyReceivedBundle) bool {
+		return pending.BundlerId == bundle.BundlerId && pending.ValidForBlock.Cmp(bundle.ValidForBlock) == 0
+	})
+	pool.pendingBundles = append(pool.pendingBundles, bundle)
+}
+
 func (pool *Rip7560BundlerPool) GetRip7560BundleStatus(hash common.Hash) (*types.BundleReceipt, error) {
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
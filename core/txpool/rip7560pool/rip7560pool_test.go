@@ -0,0 +1,786 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rip7560pool
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/holiman/uint256"
+)
+
+type testBlockChain struct {
+	statedb *state.StateDB
+	head    *types.Header
+}
+
+func (bc *testBlockChain) Config() *params.ChainConfig { return params.AllDevChainProtocolChanges }
+func (bc *testBlockChain) CurrentBlock() *types.Header { return bc.head }
+func (bc *testBlockChain) Engine() consensus.Engine    { return ethash.NewFaker() }
+
+func (bc *testBlockChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	return bc.head
+}
+
+func (bc *testBlockChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	return types.NewBlock(bc.head, nil, nil, trie.NewStackTrie(nil))
+}
+
+func (bc *testBlockChain) StateAt(common.Hash) (*state.StateDB, error) {
+	return bc.statedb, nil
+}
+
+func (bc *testBlockChain) GetReceiptsByHash(hash common.Hash) types.Receipts {
+	return nil
+}
+
+func newTestPool(t *testing.T, config QueueConfig) (*Rip7560BundlerPool, *testBlockChain) {
+	t.Helper()
+	return newTestPoolWithConfig(t, Config{Queue: config})
+}
+
+func newTestPoolWithConfig(t *testing.T, config Config) (*Rip7560BundlerPool, *testBlockChain) {
+	t.Helper()
+
+	if config.ReputationFile != "" {
+		config.ReputationFile = filepath.Join(t.TempDir(), config.ReputationFile)
+	}
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetBalance(common.Address{0xaa}, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+	statedb.SetCode(common.Address{0xaa}, accountCode(nil))
+	statedb.SetCode(params.Rip7712NonceManagerAddress, params.Rip7712NonceManagerCode)
+	chain := &testBlockChain{
+		statedb: statedb,
+		head:    &types.Header{Number: new(big.Int), GasLimit: 30_000_000, BaseFee: new(big.Int)},
+	}
+	pool := New(config, chain, common.Address{})
+	if err := pool.Init(0, chain.head, nil); err != nil {
+		t.Fatalf("failed to init pool: %v", err)
+	}
+	return pool, chain
+}
+
+// accountCode returns the code of an account accepting all transactions, which
+// executes the given code before calling the EntryPoint.
+func accountCode(prefix []byte) []byte {
+	sel := core.Rip7560Abi.Methods["acceptAccount"].ID
+	code := append(prefix, byte(vm.PUSH4), sel[0], sel[1], sel[2], sel[3], byte(vm.PUSH1), 0xe0, byte(vm.SHL), byte(vm.PUSH1), 0, byte(vm.MSTORE))
+	return append(code, entryPointCall(0x44)...)
+}
+
+// paymasterCode returns the code of a paymaster sponsoring all transactions
+// with an empty context.
+func paymasterCode() []byte {
+	sel := core.Rip7560Abi.Methods["acceptPaymaster"].ID
+	code := []byte{
+		byte(vm.PUSH4), sel[0], sel[1], sel[2], sel[3], byte(vm.PUSH1), 0xe0, byte(vm.SHL), byte(vm.PUSH1), 0, byte(vm.MSTORE),
+		byte(vm.PUSH1), 0x60, byte(vm.PUSH1), 0x44, byte(vm.MSTORE),
+	}
+	return append(code, entryPointCall(0x84)...)
+}
+
+// entryPointCall returns the code calling the EntryPoint with the given amount
+// of memory as input.
+func entryPointCall(size byte) []byte {
+	code := []byte{byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), size, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH20)}
+	code = append(code, core.AA_ENTRY_POINT.Bytes()...)
+	return append(code, byte(vm.GAS), byte(vm.CALL), byte(vm.STOP))
+}
+
+func aaTx(sender common.Address, key int64, nonce uint64, feeCap int64) *types.Transaction {
+	return types.NewTx(&types.Rip7560AccountAbstractionTx{
+		ChainID:   params.AllDevChainProtocolChanges.ChainID,
+		Nonce:     nonce,
+		NonceKey:  big.NewInt(key),
+		GasTipCap: big.NewInt(feeCap),
+		GasFeeCap: big.NewInt(feeCap),
+		Gas:       100_000,
+		Sender:    &sender,
+
+		ValidationGasLimit: 100_000,
+	})
+}
+
+func sponsoredTx(sender common.Address, paymaster common.Address, feeCap int64) *types.Transaction {
+	return types.NewTx(&types.Rip7560AccountAbstractionTx{
+		ChainID:   params.AllDevChainProtocolChanges.ChainID,
+		NonceKey:  new(big.Int),
+		GasTipCap: big.NewInt(feeCap),
+		GasFeeCap: big.NewInt(feeCap),
+		Gas:       100_000,
+		Sender:    &sender,
+		Paymaster: &paymaster,
+
+		ValidationGasLimit:          100_000,
+		PaymasterValidationGasLimit: 100_000,
+	})
+}
+
+// Tests that transactions with nonce gaps are queued per nonce sequence and
+// promoted once the gap is filled.
+func TestNonceGapQueueing(t *testing.T) {
+	pool, _ := newTestPool(t, DefaultQueueConfig)
+	sender := common.Address{0xaa}
+
+	events := make(chan core.NewTxsEvent, 10)
+	sub := pool.SubscribeTransactions(events, false)
+	defer sub.Unsubscribe()
+
+	for _, err := range pool.Add([]*types.Transaction{aaTx(sender, 0, 0, 1), aaTx(sender, 0, 2, 1), aaTx(sender, 0, 3, 1), aaTx(sender, 1, 0, 1)}, false, false) {
+		if err != nil {
+			t.Fatalf("failed to add transaction: %v", err)
+		}
+	}
+	if pending, queued := pool.Stats(); pending != 2 || queued != 2 {
+		t.Fatalf("stats mismatch: have %d/%d, want 2/2", pending, queued)
+	}
+	if status := pool.Status(aaTx(sender, 0, 2, 1).Hash()); status != txpool.TxStatusQueued {
+		t.Fatalf("status mismatch: have %v, want queued", status)
+	}
+	if ev := <-events; len(ev.Txs) != 2 {
+		t.Fatalf("announced transactions mismatch: have %d, want 2", len(ev.Txs))
+	}
+	// Fill the gap and make sure the queued transactions are promoted
+	if err := pool.Add([]*types.Transaction{aaTx(sender, 0, 1, 1)}, false, false)[0]; err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	if pending, queued := pool.Stats(); pending != 5 || queued != 0 {
+		t.Fatalf("stats mismatch: have %d/%d, want 5/0", pending, queued)
+	}
+	if ev := <-events; len(ev.Txs) != 3 {
+		t.Fatalf("announced transactions mismatch: have %d, want 3", len(ev.Txs))
+	}
+	pending, _ := pool.ContentFrom(sender)
+	for i, tx := range pending[:4] {
+		if tx.Nonce() != uint64(i) {
+			t.Fatalf("pending tx %d nonce mismatch: have %d", i, tx.Nonce())
+		}
+	}
+}
+
+// Tests that mined nonces drop the included transactions and that transactions
+// below the on-chain nonce are rejected.
+func TestNonceSequenceReset(t *testing.T) {
+	pool, chain := newTestPool(t, DefaultQueueConfig)
+	sender := common.Address{0xaa}
+
+	pool.Add([]*types.Transaction{aaTx(sender, 0, 0, 1), aaTx(sender, 0, 1, 1), aaTx(sender, 0, 3, 1)}, false, false)
+
+	chain.statedb.SetNonce(sender, 2)
+	head := &types.Header{Number: big.NewInt(1), GasLimit: 30_000_000}
+	pool.Reset(chain.head, head)
+	chain.head = head
+
+	if pending, queued := pool.Stats(); pending != 0 || queued != 1 {
+		t.Fatalf("stats mismatch: have %d/%d, want 0/1", pending, queued)
+	}
+	if err := pool.Add([]*types.Transaction{aaTx(sender, 0, 1, 2)}, false, false)[0]; !errors.Is(err, core.ErrNonceTooLow) {
+		t.Fatalf("error mismatch: have %v, want %v", err, core.ErrNonceTooLow)
+	}
+	if err := pool.Add([]*types.Transaction{aaTx(sender, 0, 2, 1)}, false, false)[0]; err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	if pending, queued := pool.Stats(); pending != 2 || queued != 0 {
+		t.Fatalf("stats mismatch: have %d/%d, want 2/0", pending, queued)
+	}
+}
+
+// Tests that the pending individually submitted transactions are bundled for
+// the next block by effective tip, keeping the nonce order of every sequence.
+func TestPendingBundle(t *testing.T) {
+	pool, chain := newTestPool(t, DefaultQueueConfig)
+	var (
+		a = common.Address{0xaa}
+		b = common.Address{0xbb}
+	)
+	chain.statedb.SetBalance(b, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+	chain.statedb.SetCode(b, accountCode(nil))
+
+	if bundle, err := pool.PendingRip7560Bundle(); err != nil || bundle != nil {
+		t.Fatalf("empty pool bundle mismatch: have %v, %v", bundle, err)
+	}
+	txs := []*types.Transaction{aaTx(a, 0, 0, 1), aaTx(a, 0, 1, 3), aaTx(a, 0, 3, 9), aaTx(a, 1, 0, 5), aaTx(b, 0, 0, 2)}
+	for _, err := range pool.Add(txs, false, false) {
+		if err != nil {
+			t.Fatalf("failed to add transaction: %v", err)
+		}
+	}
+	bundle, err := pool.PendingRip7560Bundle()
+	if err != nil {
+		t.Fatalf("failed to retrieve bundle: %v", err)
+	}
+	want := []*types.Transaction{txs[3], txs[4], txs[0], txs[1]}
+	if len(bundle.Transactions) != len(want) {
+		t.Fatalf("bundle size mismatch: have %d, want %d", len(bundle.Transactions), len(want))
+	}
+	for i, tx := range bundle.Transactions {
+		if tx.Hash() != want[i].Hash() {
+			t.Errorf("bundle tx %d mismatch: have nonce key %v nonce %d, want nonce key %v nonce %d", i,
+				tx.Rip7560TransactionData().NonceKey, tx.Nonce(), want[i].Rip7560TransactionData().NonceKey, want[i].Nonce())
+		}
+	}
+	if bundle.ValidForBlock.Uint64() != 1 {
+		t.Errorf("bundle block mismatch: have %v, want 1", bundle.ValidForBlock)
+	}
+	// The bundle size is capped if configured
+	size := uint64(2)
+	pool.config.MaxBundleSize = &size
+	if bundle, _ := pool.PendingRip7560Bundle(); len(bundle.Transactions) != 2 {
+		t.Errorf("capped bundle size mismatch: have %d, want 2", len(bundle.Transactions))
+	}
+}
+
+// Tests that a bundle replaces the pending one of the same bundler for the same
+// block, and takes precedence over the individually submitted transactions.
+func TestReplaceBundle(t *testing.T) {
+	pool, _ := newTestPool(t, DefaultQueueConfig)
+	tx := aaTx(common.Address{0xaa}, 0, 0, 1)
+	if errs := pool.Add([]*types.Transaction{tx}, false, false); errs[0] != nil {
+		t.Fatalf("failed to add transaction: %v", errs[0])
+	}
+	local := pool.LocalBundle()
+	if local == nil || len(local.Transactions) != 1 {
+		t.Fatalf("local bundle mismatch: have %v", local)
+	}
+	var (
+		first  = &types.ExternallyReceivedBundle{BundlerId: "test", BundleHash: common.Hash{0x01}, ValidForBlock: big.NewInt(1), Transactions: local.Transactions}
+		second = &types.ExternallyReceivedBundle{BundlerId: "test", BundleHash: common.Hash{0x02}, ValidForBlock: big.NewInt(1), Transactions: local.Transactions}
+	)
+	pool.ReplaceRip7560Bundle(first)
+	pool.ReplaceRip7560Bundle(second)
+	if len(pool.pendingBundles) != 1 {
+		t.Fatalf("pending bundles mismatch: have %d, want 1", len(pool.pendingBundles))
+	}
+	if bundle, _ := pool.PendingRip7560Bundle(); bundle.BundleHash != second.BundleHash {
+		t.Errorf("pending bundle mismatch: have %x, want %x", bundle.BundleHash, second.BundleHash)
+	}
+	// The local bundle ignores the submitted ones
+	if bundle := pool.LocalBundle(); bundle.BundlerId != local.BundlerId {
+		t.Errorf("local bundle replaced by bundler %q", bundle.BundlerId)
+	}
+}
+
+// Tests the per nonce sequence limits and the replacement rules.
+func TestNonceSequenceLimits(t *testing.T) {
+	pool, _ := newTestPool(t, QueueConfig{PriceBump: 10, KeySlots: 2, KeyQueue: 2, GlobalQueue: 3})
+	sender := common.Address{0xaa}
+
+	if err := pool.Add([]*types.Transaction{aaTx(sender, 0, 4, 1)}, false, false)[0]; !errors.Is(err, core.ErrNonceTooHigh) {
+		t.Fatalf("error mismatch: have %v, want %v", err, core.ErrNonceTooHigh)
+	}
+	pool.Add([]*types.Transaction{aaTx(sender, 0, 2, 1), aaTx(sender, 0, 3, 1)}, false, false)
+	if err := pool.Add([]*types.Transaction{aaTx(sender, 0, 1, 1)}, false, false)[0]; !errors.Is(err, txpool.ErrAccountLimitExceeded) {
+		t.Fatalf("error mismatch: have %v, want %v", err, txpool.ErrAccountLimitExceeded)
+	}
+	// Other nonce keys have their own limits, but share the global queue
+	if err := pool.Add([]*types.Transaction{aaTx(sender, 1, 1, 1)}, false, false)[0]; err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	if err := pool.Add([]*types.Transaction{aaTx(sender, 2, 1, 1)}, false, false)[0]; !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrQueueFull)
+	}
+	// Replacements need to bump the fees
+	if err := pool.Add([]*types.Transaction{aaTx(sender, 0, 2, 1)}, false, false)[0]; !errors.Is(err, txpool.ErrAlreadyKnown) {
+		t.Fatalf("error mismatch: have %v, want %v", err, txpool.ErrAlreadyKnown)
+	}
+	if err := pool.Add([]*types.Transaction{aaTx(sender, 0, 2, 2)}, false, false)[0]; err != nil {
+		t.Fatalf("failed to replace transaction: %v", err)
+	}
+	if pending, queued := pool.Stats(); pending != 0 || queued != 3 {
+		t.Fatalf("stats mismatch: have %d/%d, want 0/3", pending, queued)
+	}
+}
+
+// Tests that the replacement fees of a transaction are accepted by the pool, and
+// that the fee cap covers the tip on top of the next base fee.
+func TestReplacementFee(t *testing.T) {
+	pool, chain := newTestPool(t, DefaultQueueConfig)
+	sender := common.Address{0xaa}
+
+	if _, _, err := pool.ReplacementFee(common.Hash{0x01}); !errors.Is(err, ErrTxNotFound) {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrTxNotFound)
+	}
+	tx := aaTx(sender, 0, 0, 100)
+	if err := pool.Add([]*types.Transaction{tx}, false, false)[0]; err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	feeCap, tipCap, err := pool.ReplacementFee(tx.Hash())
+	if err != nil {
+		t.Fatalf("failed to retrieve replacement fee: %v", err)
+	}
+	if feeCap.Int64() != 110 || tipCap.Int64() != 110 {
+		t.Fatalf("replacement fee mismatch: have %v/%v, want 110/110", feeCap, tipCap)
+	}
+	if err := pool.Add([]*types.Transaction{aaTx(sender, 0, 0, 109)}, false, false)[0]; !errors.Is(err, txpool.ErrReplaceUnderpriced) {
+		t.Fatalf("error mismatch: have %v, want %v", err, txpool.ErrReplaceUnderpriced)
+	}
+	replacement := aaTx(sender, 0, 0, feeCap.Int64())
+	if err := pool.Add([]*types.Transaction{replacement}, false, false)[0]; err != nil {
+		t.Fatalf("failed to replace transaction: %v", err)
+	}
+	if _, _, err := pool.ReplacementFee(tx.Hash()); !errors.Is(err, ErrTxNotFound) {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrTxNotFound)
+	}
+	// A base fee above the bumped fee cap raises it
+	head := &types.Header{Number: big.NewInt(1), GasLimit: 30_000_000, GasUsed: 15_000_000, BaseFee: big.NewInt(1000)}
+	pool.Reset(chain.head, head)
+	chain.head = head
+
+	feeCap, tipCap, err = pool.ReplacementFee(replacement.Hash())
+	if err != nil {
+		t.Fatalf("failed to retrieve replacement fee: %v", err)
+	}
+	if feeCap.Int64() != 1121 || tipCap.Int64() != 121 {
+		t.Fatalf("replacement fee mismatch: have %v/%v, want 1121/121", feeCap, tipCap)
+	}
+}
+
+// Tests that the removals of transactions from the pool are announced with
+// their reason.
+func TestDroppedEvents(t *testing.T) {
+	pool, chain := newTestPool(t, DefaultQueueConfig)
+	sender := common.Address{0xaa}
+
+	drops := make(chan core.Rip7560TxDroppedEvent, 10)
+	sub := pool.SubscribeDropped(drops)
+	defer sub.Unsubscribe()
+
+	replaced, replacement := aaTx(sender, 0, 0, 1), aaTx(sender, 0, 0, 2)
+	pool.Add([]*types.Transaction{replaced, aaTx(sender, 0, 1, 1)}, false, false)
+	if err := pool.Add([]*types.Transaction{replacement}, false, false)[0]; err != nil {
+		t.Fatalf("failed to replace transaction: %v", err)
+	}
+	if ev := <-drops; ev.Tx.Hash() != replaced.Hash() || ev.Reason != DropReplaced {
+		t.Fatalf("drop event mismatch: have %x %q, want %x %q", ev.Tx.Hash(), ev.Reason, replaced.Hash(), DropReplaced)
+	}
+	chain.statedb.SetNonce(sender, 1)
+	head := &types.Header{Number: big.NewInt(1), GasLimit: 30_000_000}
+	pool.Reset(chain.head, head)
+	chain.head = head
+
+	if ev := <-drops; ev.Tx.Hash() != replacement.Hash() || ev.Reason != DropNonceUsed {
+		t.Fatalf("drop event mismatch: have %x %q, want %x %q", ev.Tx.Hash(), ev.Reason, replacement.Hash(), DropNonceUsed)
+	}
+	select {
+	case ev := <-drops:
+		t.Fatalf("unexpected drop event: %x %q", ev.Tx.Hash(), ev.Reason)
+	default:
+	}
+}
+
+// Tests that drop events are sent without holding the pool lock, so that
+// subscribers may query the pool while handling them.
+func TestDroppedEventsUnlocked(t *testing.T) {
+	pool, _ := newTestPool(t, DefaultQueueConfig)
+	sender := common.Address{0xaa}
+
+	drops := make(chan core.Rip7560TxDroppedEvent)
+	sub := pool.SubscribeDropped(drops)
+	defer sub.Unsubscribe()
+
+	handled := make(chan struct{})
+	go func() {
+		<-drops
+		pool.Stats()
+		close(handled)
+	}()
+	pool.Add([]*types.Transaction{aaTx(sender, 0, 0, 1)}, false, false)
+	pool.Add([]*types.Transaction{aaTx(sender, 0, 0, 2)}, false, false)
+
+	select {
+	case <-handled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("drop event handler blocked on the pool lock")
+	}
+}
+
+// Tests that the transactions promoted on a reset are simulated again on the
+// new state, and dropped if their validation fails.
+func TestResetResimulation(t *testing.T) {
+	pool, chain := newTestPool(t, DefaultQueueConfig)
+	sender := common.Address{0xaa}
+
+	drops := make(chan core.Rip7560TxDroppedEvent, 10)
+	sub := pool.SubscribeDropped(drops)
+	defer sub.Unsubscribe()
+
+	invalid := aaTx(sender, 0, 2, 1)
+	for _, err := range pool.Add([]*types.Transaction{aaTx(sender, 0, 0, 1), invalid, aaTx(sender, 0, 3, 1)}, false, false) {
+		if err != nil {
+			t.Fatalf("failed to add transaction: %v", err)
+		}
+	}
+	// Mine up to the gap, while the account stops accepting transactions
+	chain.statedb.SetNonce(sender, 2)
+	chain.statedb.SetCode(sender, nil)
+	head := &types.Header{Number: big.NewInt(1), GasLimit: 30_000_000}
+	pool.Reset(chain.head, head)
+	chain.head = head
+
+	if pending, queued := pool.Stats(); pending != 0 || queued != 1 {
+		t.Fatalf("stats mismatch: have %d/%d, want 0/1", pending, queued)
+	}
+	<-drops // Mined transaction
+	if ev := <-drops; ev.Tx.Hash() != invalid.Hash() || ev.Reason != DropInvalid {
+		t.Fatalf("drop event mismatch: have %x %q, want %x %q", ev.Tx.Hash(), ev.Reason, invalid.Hash(), DropInvalid)
+	}
+}
+
+// Tests that the cumulative cost of all transactions sponsored by a paymaster,
+// in the pool and in the pending bundles, is checked against its balance, and
+// that transactions are evicted if the balance drops.
+func TestPayerPrefund(t *testing.T) {
+	pool, chain := newTestPool(t, DefaultQueueConfig)
+	paymaster := common.Address{0xbb}
+
+	cost := sponsoredTx(common.Address{0x01}, paymaster, 1).Cost()
+	chain.statedb.SetCode(paymaster, paymasterCode())
+	for _, sender := range []common.Address{{0x01}, {0x02}, {0x03}} {
+		chain.statedb.SetCode(sender, accountCode(nil))
+	}
+	chain.statedb.SetBalance(paymaster, uint256.MustFromBig(new(big.Int).Mul(cost, big.NewInt(2))), tracing.BalanceChangeUnspecified)
+
+	errs := pool.Add([]*types.Transaction{
+		sponsoredTx(common.Address{0x01}, paymaster, 1),
+		sponsoredTx(common.Address{0x02}, paymaster, 1),
+		sponsoredTx(common.Address{0x03}, paymaster, 1),
+	}, false, false)
+	if errs[0] != nil || errs[1] != nil {
+		t.Fatalf("failed to add transactions: %v", errs)
+	}
+	if !errors.Is(errs[2], core.ErrInsufficientFunds) {
+		t.Fatalf("error mismatch: have %v, want %v", errs[2], core.ErrInsufficientFunds)
+	}
+	// Replacements are only accepted if the payer covers the cost difference
+	if err := pool.Add([]*types.Transaction{sponsoredTx(common.Address{0x02}, paymaster, 2)}, false, false)[0]; !errors.Is(err, core.ErrInsufficientFunds) {
+		t.Fatalf("error mismatch: have %v, want %v", err, core.ErrInsufficientFunds)
+	}
+	// Drop the paymaster balance and make sure the pool evicts the excess
+	chain.statedb.SetBalance(paymaster, uint256.MustFromBig(cost), tracing.BalanceChangeUnspecified)
+	head := &types.Header{Number: big.NewInt(1), GasLimit: 30_000_000}
+	pool.Reset(chain.head, head)
+	chain.head = head
+
+	if pending, queued := pool.Stats(); pending != 1 || queued != 0 {
+		t.Fatalf("stats mismatch: have %d/%d, want 1/0", pending, queued)
+	}
+	// Transactions sponsored in a pending bundle are owed by the paymaster too
+	chain.statedb.SetBalance(paymaster, uint256.MustFromBig(new(big.Int).Mul(cost, big.NewInt(3))), tracing.BalanceChangeUnspecified)
+	for _, sender := range []common.Address{{0x05}, {0x06}} {
+		chain.statedb.SetCode(sender, accountCode(nil))
+	}
+	bundled := sponsoredTx(common.Address{0x04}, paymaster, 1)
+	pool.SubmitRip7560Bundle(&types.ExternallyReceivedBundle{ValidForBlock: big.NewInt(2), Transactions: []*types.Transaction{bundled}})
+
+	if err := pool.Add([]*types.Transaction{sponsoredTx(common.Address{0x05}, paymaster, 1)}, false, false)[0]; err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	if err := pool.Add([]*types.Transaction{sponsoredTx(common.Address{0x06}, paymaster, 1)}, false, false)[0]; !errors.Is(err, core.ErrInsufficientFunds) {
+		t.Fatalf("error mismatch: have %v, want %v", err, core.ErrInsufficientFunds)
+	}
+}
+
+// Tests that transactions are simulated on admission and rejected if their
+// validation fails or breaks the validation rules.
+func TestAdmissionSimulation(t *testing.T) {
+	pool, chain := newTestPool(t, DefaultQueueConfig)
+
+	var (
+		noCode    = common.Address{0x01}
+		timestamp = common.Address{0x02}
+	)
+	chain.statedb.SetBalance(noCode, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+	chain.statedb.SetBalance(timestamp, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+	chain.statedb.SetCode(timestamp, accountCode([]byte{byte(vm.TIMESTAMP), byte(vm.POP)}))
+
+	if err := pool.Add([]*types.Transaction{aaTx(noCode, 0, 0, 1)}, false, false)[0]; err == nil {
+		t.Fatal("transaction without account code accepted")
+	}
+	if err := pool.Add([]*types.Transaction{aaTx(timestamp, 0, 0, 1)}, false, false)[0]; !errors.Is(err, core.ErrValidationRulesViolation) {
+		t.Fatalf("error mismatch: have %v, want %v", err, core.ErrValidationRulesViolation)
+	}
+	if pending, queued := pool.Stats(); pending != 0 || queued != 0 {
+		t.Fatalf("stats mismatch: have %d/%d, want 0/0", pending, queued)
+	}
+	// The banned TIMESTAMP opcode breaks a single rule
+	if stats := pool.ViolationStats(); len(stats) != 1 || stats["OP-011"] != 1 {
+		t.Fatalf("violation stats mismatch: have %v, want OP-011 once", stats)
+	}
+}
+
+// Tests that validation frames exhausting the gas limit assigned to them by the
+// transaction are rejected with a dedicated error.
+func TestAdmissionOutOfGas(t *testing.T) {
+	pool, chain := newTestPool(t, DefaultQueueConfig)
+
+	var (
+		account   = common.Address{0x01}
+		paymaster = common.Address{0x02}
+		loop      = []byte{byte(vm.JUMPDEST), byte(vm.PUSH1), 0, byte(vm.JUMP)}
+	)
+	chain.statedb.SetBalance(account, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+	chain.statedb.SetCode(account, loop)
+	chain.statedb.SetBalance(paymaster, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+	chain.statedb.SetCode(paymaster, loop)
+
+	errs := pool.Add([]*types.Transaction{
+		aaTx(account, 0, 0, 1),
+		sponsoredTx(common.Address{0xaa}, paymaster, 1),
+	}, false, false)
+	for i, err := range errs {
+		if !errors.Is(err, core.ErrValidationOutOfGas) {
+			t.Fatalf("tx %d: error mismatch: have %v, want %v", i, err, core.ErrValidationOutOfGas)
+		}
+	}
+}
+
+// Tests that transactions relayed by trusted peers are admitted without the
+// simulation of their validation phase, but are still checked for their bounds
+// and only become executable once their simulation passes.
+func TestAddTrusted(t *testing.T) {
+	pool, chain := newTestPool(t, DefaultQueueConfig)
+
+	noCode := common.Address{0x01}
+	chain.statedb.SetBalance(noCode, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+
+	if err := pool.AddTrusted([]*types.Transaction{aaTx(noCode, 0, 0, 1)})[0]; err != nil {
+		t.Fatalf("trusted transaction rejected: %v", err)
+	}
+	if err := pool.AddTrusted([]*types.Transaction{aaTx(common.Address{0xaa}, 0, 0, 1)})[0]; err != nil {
+		t.Fatalf("trusted transaction rejected: %v", err)
+	}
+	tx := aaTx(noCode, 0, 1, 1)
+	tx.Rip7560TransactionData().ValidationGasLimit = 0
+	if err := pool.AddTrusted([]*types.Transaction{tx})[0]; !errors.Is(err, core.ErrIntrinsicGas) {
+		t.Fatalf("error mismatch: have %v, want %v", err, core.ErrIntrinsicGas)
+	}
+	tx = aaTx(noCode, 0, 1, 1)
+	tx.Rip7560TransactionData().Gas = chain.head.GasLimit
+	if err := pool.AddTrusted([]*types.Transaction{tx})[0]; !errors.Is(err, txpool.ErrGasLimit) {
+		t.Fatalf("error mismatch: have %v, want %v", err, txpool.ErrGasLimit)
+	}
+	// The transaction without account code fails its simulation and is queued
+	if pending, queued := pool.Stats(); pending != 1 || queued != 1 {
+		t.Fatalf("stats mismatch: have %d/%d, want 1/1", pending, queued)
+	}
+	if status := pool.Status(aaTx(noCode, 0, 0, 1).Hash()); status != txpool.TxStatusQueued {
+		t.Fatalf("status mismatch: have %v, want queued", status)
+	}
+}
+
+// Tests that RIP-7712 transactions ahead of the on-chain nonce of their sequence
+// are kept queued until their predecessors are mined and they pass simulation.
+func TestDeferredRip7712Promotion(t *testing.T) {
+	pool, chain := newTestPool(t, DefaultQueueConfig)
+	sender := common.Address{0xaa}
+
+	for _, err := range pool.Add([]*types.Transaction{aaTx(sender, 1, 0, 1), aaTx(sender, 1, 1, 1)}, false, false) {
+		if err != nil {
+			t.Fatalf("failed to add transaction: %v", err)
+		}
+	}
+	if pending, queued := pool.Stats(); pending != 1 || queued != 1 {
+		t.Fatalf("stats mismatch: have %d/%d, want 1/1", pending, queued)
+	}
+	// Mine the first transaction, simulating and promoting the second one. The
+	// nonces are stored in a Solidity mapping(address => mapping(uint192 => uint256))
+	inner := crypto.Keccak256(common.LeftPadBytes(sender.Bytes(), 32), make([]byte, 32))
+	slot := common.BytesToHash(crypto.Keccak256(common.LeftPadBytes([]byte{1}, 32), inner))
+	chain.statedb.SetState(params.Rip7712NonceManagerAddress, slot, common.BigToHash(common.Big1))
+
+	head := &types.Header{Number: big.NewInt(1), GasLimit: 30_000_000}
+	pool.Reset(chain.head, head)
+	chain.head = head
+
+	if pending, queued := pool.Stats(); pending != 1 || queued != 0 {
+		t.Fatalf("stats mismatch after reset: have %d/%d, want 1/0", pending, queued)
+	}
+	if backlog := pool.ValidationBacklog(); backlog != 0 {
+		t.Fatalf("backlog mismatch after reset: have %d, want 0", backlog)
+	}
+}
+
+// Tests that the transactions admitted without simulating their validation are
+// counted in the validation backlog until they leave the pool.
+func TestValidationBacklog(t *testing.T) {
+	pool, chain := newTestPool(t, DefaultQueueConfig)
+	sender, noCode := common.Address{0xaa}, common.Address{0x01}
+	chain.statedb.SetBalance(noCode, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+
+	if err := pool.AddTrusted([]*types.Transaction{aaTx(noCode, 0, 0, 1)})[0]; err != nil {
+		t.Fatalf("trusted transaction rejected: %v", err)
+	}
+	// Only the RIP-7712 transaction ahead of the on-chain nonce isn't simulated
+	for _, err := range pool.Add([]*types.Transaction{aaTx(sender, 0, 1, 1), aaTx(sender, 1, 0, 1), aaTx(sender, 1, 1, 1)}, false, false) {
+		if err != nil {
+			t.Fatalf("failed to add transaction: %v", err)
+		}
+	}
+	if backlog := pool.ValidationBacklog(); backlog != 2 {
+		t.Fatalf("backlog mismatch: have %d, want 2", backlog)
+	}
+	chain.statedb.SetNonce(noCode, 1)
+	head := &types.Header{Number: big.NewInt(1), GasLimit: 30_000_000}
+	pool.Reset(chain.head, head)
+	chain.head = head
+
+	if backlog := pool.ValidationBacklog(); backlog != 1 {
+		t.Fatalf("backlog mismatch after reset: have %d, want 1", backlog)
+	}
+}
+
+// Tests that the paymaster allowlist and the banned opcode mode of the pool
+// configuration are applied on admission.
+func TestAdmissionPolicy(t *testing.T) {
+	var (
+		allowed   = common.Address{0xbb}
+		denied    = common.Address{0xcc}
+		timestamp = common.Address{0x02}
+		allowlist = filepath.Join(t.TempDir(), "paymasters.txt")
+	)
+	if err := os.WriteFile(allowlist, []byte("# sponsors\n\n"+allowed.Hex()+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config := DefaultConfig
+	config.BannedOpcodes = BannedOpcodesWarn
+	config.PaymasterAllowlist = allowlist
+	pool, chain := newTestPoolWithConfig(t, config)
+
+	for _, paymaster := range []common.Address{allowed, denied} {
+		chain.statedb.SetCode(paymaster, paymasterCode())
+		chain.statedb.SetBalance(paymaster, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+	}
+	chain.statedb.SetCode(common.Address{0x01}, accountCode(nil))
+	chain.statedb.SetCode(common.Address{0x03}, accountCode(nil))
+	chain.statedb.SetBalance(timestamp, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+	chain.statedb.SetCode(timestamp, accountCode([]byte{byte(vm.TIMESTAMP), byte(vm.POP)}))
+
+	errs := pool.Add([]*types.Transaction{
+		sponsoredTx(common.Address{0x01}, allowed, 1),
+		sponsoredTx(common.Address{0x03}, denied, 1),
+		aaTx(timestamp, 0, 0, 1),
+	}, false, false)
+	if errs[0] != nil {
+		t.Fatalf("allowed paymaster rejected: %v", errs[0])
+	}
+	if !errors.Is(errs[1], ErrPaymasterNotAllowed) {
+		t.Fatalf("error mismatch: have %v, want %v", errs[1], ErrPaymasterNotAllowed)
+	}
+	if errs[2] != nil {
+		t.Fatalf("violating transaction rejected in warn mode: %v", errs[2])
+	}
+	if pending, queued := pool.Stats(); pending != 2 || queued != 0 {
+		t.Fatalf("stats mismatch: have %d/%d, want 2/0", pending, queued)
+	}
+}
+
+// Tests that the limits on the structure of the validation frames configured for
+// the pool are applied on admission.
+func TestAdmissionFramePolicy(t *testing.T) {
+	var (
+		trusted   = common.Address{0xbb}
+		untrusted = common.Address{0xcc}
+		delegates = filepath.Join(t.TempDir(), "delegates.txt")
+	)
+	if err := os.WriteFile(delegates, []byte(trusted.Hex()+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config := DefaultConfig
+	config.TrustedDelegates = delegates
+	pool, chain := newTestPoolWithConfig(t, config)
+
+	// delegate returns the account code delegating to the target before accepting
+	delegate := func(target common.Address) []byte {
+		code := []byte{byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH20)}
+		code = append(code, target.Bytes()...)
+		return accountCode(append(code, byte(vm.GAS), byte(vm.DELEGATECALL), byte(vm.POP)))
+	}
+	for i, target := range []common.Address{trusted, untrusted} {
+		sender := common.Address{byte(i + 1)}
+		chain.statedb.SetCode(target, []byte{byte(vm.STOP)})
+		chain.statedb.SetCode(sender, delegate(target))
+		chain.statedb.SetBalance(sender, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+	}
+	errs := pool.Add([]*types.Transaction{aaTx(common.Address{1}, 0, 0, 1), aaTx(common.Address{2}, 0, 0, 1)}, false, false)
+	if errs[0] != nil {
+		t.Fatalf("trusted delegate rejected: %v", errs[0])
+	}
+	if !errors.Is(errs[1], core.ErrValidationRulesViolation) {
+		t.Fatalf("error mismatch: have %v, want %v", errs[1], core.ErrValidationRulesViolation)
+	}
+	if stats := pool.ViolationStats(); stats["POL-003"] != 1 {
+		t.Fatalf("violation stats mismatch: have %v, want POL-003 once", stats)
+	}
+}
+
+// Tests that the pool policy can be replaced at runtime, dropping the pooled
+// transactions of paymasters that got banned.
+func TestSetPolicy(t *testing.T) {
+	pool, chain := newTestPoolWithConfig(t, DefaultConfig)
+	paymaster := common.Address{0xbb}
+
+	chain.statedb.SetCode(paymaster, paymasterCode())
+	chain.statedb.SetBalance(paymaster, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+	chain.statedb.SetCode(common.Address{0x01}, accountCode(nil))
+
+	if err := pool.Add([]*types.Transaction{sponsoredTx(common.Address{0x01}, paymaster, 1)}, false, false)[0]; err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	config := DefaultConfig
+	config.BannedOpcodes = "ignore"
+	if err := pool.SetPolicy(config); err == nil {
+		t.Fatal("invalid policy accepted")
+	}
+	config.BannedOpcodes = BannedOpcodesEnforce
+	config.PaymasterBanlist = filepath.Join(t.TempDir(), "banned.txt")
+	if err := pool.SetPolicy(config); err == nil {
+		t.Fatal("policy with missing banlist accepted")
+	}
+	if err := os.WriteFile(config.PaymasterBanlist, []byte(paymaster.Hex()+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.SetPolicy(config); err != nil {
+		t.Fatalf("failed to set policy: %v", err)
+	}
+	if pending, queued := pool.Stats(); pending != 0 || queued != 0 {
+		t.Fatalf("stats mismatch: have %d/%d, want 0/0", pending, queued)
+	}
+	if err := pool.Add([]*types.Transaction{sponsoredTx(common.Address{0x01}, paymaster, 1)}, false, false)[0]; !errors.Is(err, ErrPaymasterBanned) {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrPaymasterBanned)
+	}
+	if policy := pool.Policy(); policy.PaymasterBanlist != config.PaymasterBanlist {
+		t.Fatalf("banlist mismatch: have %q, want %q", policy.PaymasterBanlist, config.PaymasterBanlist)
+	}
+}
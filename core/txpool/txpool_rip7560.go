@@ -44,3 +44,19 @@ func (p *TxPool) PendingRip7560Bundle() (*types.ExternallyReceivedBundle, error)
 	}
 	return nil, nil
 }
+
+// rip7560FailureReporter is implemented by the subpools tracking the reputation
+// of the entities of RIP-7560 transactions.
+type rip7560FailureReporter interface {
+	ReportRip7560Failure(tx *types.Transaction, frame string)
+}
+
+// ReportRip7560Failure reports a RIP-7560 transaction failing its validation in
+// the given frame when included in a block, holding its entities accountable.
+func (p *TxPool) ReportRip7560Failure(tx *types.Transaction, frame string) {
+	for _, subpool := range p.subpools {
+		if reporter, ok := subpool.(rip7560FailureReporter); ok {
+			reporter.ReportRip7560Failure(tx, frame)
+		}
+	}
+}
@@ -73,6 +73,9 @@ func ValidateTransaction(tx *types.Transaction, head *types.Header, signer types
 	if !opts.Config.IsCancun(head.Number, head.Time) && tx.Type() == types.BlobTxType {
 		return fmt.Errorf("%w: type %d rejected, pool not yet in Cancun", core.ErrTxTypeNotSupported, tx.Type())
 	}
+	if !opts.Config.IsPrague(head.Number, head.Time) && tx.Type() == types.SetCodeTxType {
+		return fmt.Errorf("%w: type %d rejected, pool not yet in Prague", core.ErrTxTypeNotSupported, tx.Type())
+	}
 	// Check whether the init code size has been exceeded
 	if opts.Config.IsShanghai(head.Number, head.Time) && tx.To() == nil && len(tx.Data()) > params.MaxInitCodeSize {
 		return fmt.Errorf("%w: code size %v, limit %v", core.ErrMaxInitCodeSizeExceeded, len(tx.Data()), params.MaxInitCodeSize)
@@ -103,7 +106,7 @@ func ValidateTransaction(tx *types.Transaction, head *types.Header, signer types
 	}
 	// Ensure the transaction has more gas than the bare minimum needed to cover
 	// the transaction metadata
-	intrGas, err := core.IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, true, opts.Config.IsIstanbul(head.Number), opts.Config.IsShanghai(head.Number, head.Time))
+	intrGas, err := core.IntrinsicGas(tx.Data(), tx.AccessList(), tx.SetCodeAuthorizations(), tx.To() == nil, true, opts.Config.IsIstanbul(head.Number), opts.Config.IsShanghai(head.Number, head.Time))
 	if err != nil {
 		return err
 	}
@@ -137,6 +140,29 @@ func ValidateTransaction(tx *types.Transaction, head *types.Header, signer types
 			return err
 		}
 	}
+	if tx.Type() == types.SetCodeTxType {
+		if err := validateAuthorizations(tx.SetCodeAuthorizations(), opts.Config.ChainID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateAuthorizations checks the EIP-7702 authorizations of a set code
+// transaction against the chain, rejecting the tuples which can never be
+// applied on it.
+func validateAuthorizations(auths []types.SetCodeAuthorization, chainID *big.Int) error {
+	if len(auths) == 0 {
+		return core.ErrEmptyAuthList
+	}
+	for i, auth := range auths {
+		if !auth.ChainID.IsZero() && auth.ChainID.CmpBig(chainID) != 0 {
+			return fmt.Errorf("%w: authorization %d, chain ID %v, want %v", core.ErrAuthorizationWrongChainID, i, &auth.ChainID, chainID)
+		}
+		if auth.Nonce+1 < auth.Nonce {
+			return fmt.Errorf("%w: authorization %d", core.ErrAuthorizationNonceOverflow, i)
+		}
+	}
 	return nil
 }
 
@@ -217,14 +243,31 @@ func ValidateTransactionWithState(tx *types.Transaction, signer types.Signer, op
 			return fmt.Errorf("%w: tx nonce %v, gapped nonce %v", core.ErrNonceTooHigh, tx.Nonce(), gap)
 		}
 	}
+	// Ensure the authorizations aren't already invalidated by the nonces of
+	// their authorities
+	for i, auth := range tx.SetCodeAuthorizations() {
+		authority, err := auth.Authority()
+		if err != nil {
+			return fmt.Errorf("%w: authorization %d: %v", core.ErrAuthorizationInvalidSignature, i, err)
+		}
+		if next := opts.State.GetNonce(authority); next > auth.Nonce {
+			return fmt.Errorf("%w: authorization %d, authority %v, next nonce %v, authorization nonce %v", core.ErrAuthorizationNonceMismatch, i, authority, next, auth.Nonce)
+		}
+	}
 	// Ensure the transactor has enough funds to cover the transaction costs
 	var (
 		balance = opts.State.GetBalance(from).ToBig()
-		cost    = tx.Cost()
+		cost    = tx.SenderCost()
 	)
 	if balance.Cmp(cost) < 0 {
 		return fmt.Errorf("%w: balance %v, tx cost %v, overshot %v", core.ErrInsufficientFunds, balance, cost, new(big.Int).Sub(cost, balance))
 	}
+	// Ensure the paymaster of a sponsored transaction can cover the gas costs
+	if payer, payerCost := tx.PayerCost(); payer != nil {
+		if payerBalance := opts.State.GetBalance(*payer).ToBig(); payerBalance.Cmp(payerCost) < 0 {
+			return fmt.Errorf("%w: paymaster %v balance %v, tx cost %v, overshot %v", core.ErrInsufficientFunds, *payer, payerBalance, payerCost, new(big.Int).Sub(payerCost, payerBalance))
+		}
+	}
 	// Ensure the transactor has enough funds to cover for replacements or nonce
 	// expansions without overdrafts
 	spent := opts.ExistingExpenditure(from)
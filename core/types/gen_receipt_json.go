@@ -16,21 +16,25 @@ var _ = (*receiptMarshaling)(nil)
 // MarshalJSON marshals as JSON.
 func (r Receipt) MarshalJSON() ([]byte, error) {
 	type Receipt struct {
-		Type              hexutil.Uint64 `json:"type,omitempty"`
-		PostState         hexutil.Bytes  `json:"root"`
-		Status            hexutil.Uint64 `json:"status"`
-		CumulativeGasUsed hexutil.Uint64 `json:"cumulativeGasUsed" gencodec:"required"`
-		Bloom             Bloom          `json:"logsBloom"         gencodec:"required"`
-		Logs              []*Log         `json:"logs"              gencodec:"required"`
-		TxHash            common.Hash    `json:"transactionHash" gencodec:"required"`
-		ContractAddress   common.Address `json:"contractAddress"`
-		GasUsed           hexutil.Uint64 `json:"gasUsed" gencodec:"required"`
-		EffectiveGasPrice *hexutil.Big   `json:"effectiveGasPrice"`
-		BlobGasUsed       hexutil.Uint64 `json:"blobGasUsed,omitempty"`
-		BlobGasPrice      *hexutil.Big   `json:"blobGasPrice,omitempty"`
-		BlockHash         common.Hash    `json:"blockHash,omitempty"`
-		BlockNumber       *hexutil.Big   `json:"blockNumber,omitempty"`
-		TransactionIndex  hexutil.Uint   `json:"transactionIndex"`
+		Type                    hexutil.Uint64       `json:"type,omitempty"`
+		PostState               hexutil.Bytes        `json:"root"`
+		Status                  hexutil.Uint64       `json:"status"`
+		CumulativeGasUsed       hexutil.Uint64       `json:"cumulativeGasUsed" gencodec:"required"`
+		Bloom                   Bloom                `json:"logsBloom"         gencodec:"required"`
+		Logs                    []*Log               `json:"logs"              gencodec:"required"`
+		TxHash                  common.Hash          `json:"transactionHash" gencodec:"required"`
+		ContractAddress         common.Address       `json:"contractAddress"`
+		GasUsed                 hexutil.Uint64       `json:"gasUsed" gencodec:"required"`
+		EffectiveGasPrice       *hexutil.Big         `json:"effectiveGasPrice"`
+		BlobGasUsed             hexutil.Uint64       `json:"blobGasUsed,omitempty"`
+		BlobGasPrice            *hexutil.Big         `json:"blobGasPrice,omitempty"`
+		ValidationGasUsed       hexutil.Uint64       `json:"validationGasUsed,omitempty"`
+		FrameStatuses           []ReceiptFrameStatus `json:"frameStatuses,omitempty"`
+		PaymasterAddress        *common.Address      `json:"paymasterAddress,omitempty"`
+		DeployedContractAddress *common.Address      `json:"deployedContractAddress,omitempty"`
+		BlockHash               common.Hash          `json:"blockHash,omitempty"`
+		BlockNumber             *hexutil.Big         `json:"blockNumber,omitempty"`
+		TransactionIndex        hexutil.Uint         `json:"transactionIndex"`
 	}
 	var enc Receipt
 	enc.Type = hexutil.Uint64(r.Type)
@@ -45,6 +49,10 @@ func (r Receipt) MarshalJSON() ([]byte, error) {
 	enc.EffectiveGasPrice = (*hexutil.Big)(r.EffectiveGasPrice)
 	enc.BlobGasUsed = hexutil.Uint64(r.BlobGasUsed)
 	enc.BlobGasPrice = (*hexutil.Big)(r.BlobGasPrice)
+	enc.ValidationGasUsed = hexutil.Uint64(r.ValidationGasUsed)
+	enc.FrameStatuses = r.FrameStatuses
+	enc.PaymasterAddress = r.PaymasterAddress
+	enc.DeployedContractAddress = r.DeployedContractAddress
 	enc.BlockHash = r.BlockHash
 	enc.BlockNumber = (*hexutil.Big)(r.BlockNumber)
 	enc.TransactionIndex = hexutil.Uint(r.TransactionIndex)
@@ -54,21 +62,25 @@ func (r Receipt) MarshalJSON() ([]byte, error) {
 // UnmarshalJSON unmarshals from JSON.
 func (r *Receipt) UnmarshalJSON(input []byte) error {
 	type Receipt struct {
-		Type              *hexutil.Uint64 `json:"type,omitempty"`
-		PostState         *hexutil.Bytes  `json:"root"`
-		Status            *hexutil.Uint64 `json:"status"`
-		CumulativeGasUsed *hexutil.Uint64 `json:"cumulativeGasUsed" gencodec:"required"`
-		Bloom             *Bloom          `json:"logsBloom"         gencodec:"required"`
-		Logs              []*Log          `json:"logs"              gencodec:"required"`
-		TxHash            *common.Hash    `json:"transactionHash" gencodec:"required"`
-		ContractAddress   *common.Address `json:"contractAddress"`
-		GasUsed           *hexutil.Uint64 `json:"gasUsed" gencodec:"required"`
-		EffectiveGasPrice *hexutil.Big    `json:"effectiveGasPrice"`
-		BlobGasUsed       *hexutil.Uint64 `json:"blobGasUsed,omitempty"`
-		BlobGasPrice      *hexutil.Big    `json:"blobGasPrice,omitempty"`
-		BlockHash         *common.Hash    `json:"blockHash,omitempty"`
-		BlockNumber       *hexutil.Big    `json:"blockNumber,omitempty"`
-		TransactionIndex  *hexutil.Uint   `json:"transactionIndex"`
+		Type                    *hexutil.Uint64      `json:"type,omitempty"`
+		PostState               *hexutil.Bytes       `json:"root"`
+		Status                  *hexutil.Uint64      `json:"status"`
+		CumulativeGasUsed       *hexutil.Uint64      `json:"cumulativeGasUsed" gencodec:"required"`
+		Bloom                   *Bloom               `json:"logsBloom"         gencodec:"required"`
+		Logs                    []*Log               `json:"logs"              gencodec:"required"`
+		TxHash                  *common.Hash         `json:"transactionHash" gencodec:"required"`
+		ContractAddress         *common.Address      `json:"contractAddress"`
+		GasUsed                 *hexutil.Uint64      `json:"gasUsed" gencodec:"required"`
+		EffectiveGasPrice       *hexutil.Big         `json:"effectiveGasPrice"`
+		BlobGasUsed             *hexutil.Uint64      `json:"blobGasUsed,omitempty"`
+		BlobGasPrice            *hexutil.Big         `json:"blobGasPrice,omitempty"`
+		ValidationGasUsed       *hexutil.Uint64      `json:"validationGasUsed,omitempty"`
+		FrameStatuses           []ReceiptFrameStatus `json:"frameStatuses,omitempty"`
+		PaymasterAddress        *common.Address      `json:"paymasterAddress,omitempty"`
+		DeployedContractAddress *common.Address      `json:"deployedContractAddress,omitempty"`
+		BlockHash               *common.Hash         `json:"blockHash,omitempty"`
+		BlockNumber             *hexutil.Big         `json:"blockNumber,omitempty"`
+		TransactionIndex        *hexutil.Uint        `json:"transactionIndex"`
 	}
 	var dec Receipt
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -115,6 +127,18 @@ func (r *Receipt) UnmarshalJSON(input []byte) error {
 	if dec.BlobGasPrice != nil {
 		r.BlobGasPrice = (*big.Int)(dec.BlobGasPrice)
 	}
+	if dec.ValidationGasUsed != nil {
+		r.ValidationGasUsed = uint64(*dec.ValidationGasUsed)
+	}
+	if dec.FrameStatuses != nil {
+		r.FrameStatuses = dec.FrameStatuses
+	}
+	if dec.PaymasterAddress != nil {
+		r.PaymasterAddress = dec.PaymasterAddress
+	}
+	if dec.DeployedContractAddress != nil {
+		r.DeployedContractAddress = dec.DeployedContractAddress
+	}
 	if dec.BlockHash != nil {
 		r.BlockHash = *dec.BlockHash
 	}
@@ -32,6 +32,7 @@ import (
 )
 
 //go:generate go run github.com/fjl/gencodec -type Receipt -field-override receiptMarshaling -out gen_receipt_json.go
+//go:generate go run github.com/fjl/gencodec -type ReceiptFrameStatus -field-override receiptFrameStatusMarshaling -out gen_receipt_frame_status_json.go
 
 var (
 	receiptStatusFailedRLP     = []byte{}
@@ -66,6 +67,20 @@ type Receipt struct {
 	BlobGasUsed       uint64         `json:"blobGasUsed,omitempty"`
 	BlobGasPrice      *big.Int       `json:"blobGasPrice,omitempty"`
 
+	// RIP-7560 fields: The validation gas and the status of every frame run are
+	// stored along with the receipt, while the paymaster and the deployed account
+	// are derived from the transaction.
+	ValidationGasUsed       uint64               `json:"validationGasUsed,omitempty"`
+	FrameStatuses           []ReceiptFrameStatus `json:"frameStatuses,omitempty"`
+	PaymasterAddress        *common.Address      `json:"paymasterAddress,omitempty"`
+	DeployedContractAddress *common.Address      `json:"deployedContractAddress,omitempty"`
+
+	// LogFrames names the frame of a RIP-7560 transaction which emitted each of
+	// its logs. It is node-local metadata, stored separately from the receipt.
+	LogFrames []string `json:"-"`
+
+	legacyType bool // Receipt of a transaction using Rip7560LegacyType, encoded with it
+
 	// Inclusion information: These fields provide information about the inclusion of the
 	// transaction corresponding to this receipt.
 	BlockHash        common.Hash `json:"blockHash,omitempty"`
@@ -82,6 +97,7 @@ type receiptMarshaling struct {
 	EffectiveGasPrice *hexutil.Big
 	BlobGasUsed       hexutil.Uint64
 	BlobGasPrice      *hexutil.Big
+	ValidationGasUsed hexutil.Uint64
 	BlockNumber       *hexutil.Big
 	TransactionIndex  hexutil.Uint
 }
@@ -99,6 +115,25 @@ type storedReceiptRLP struct {
 	PostStateOrStatus []byte
 	CumulativeGasUsed uint64
 	Logs              []*Log
+	Rip7560           *storedRip7560ReceiptRLP `rlp:"optional"`
+}
+
+// storedRip7560ReceiptRLP is the storage encoding of the fields of a RIP-7560
+// receipt which can't be derived from the transaction.
+type storedRip7560ReceiptRLP struct {
+	ValidationGasUsed uint64
+	FrameStatuses     []ReceiptFrameStatus
+}
+
+// ReceiptFrameStatus is the outcome of a frame of a RIP-7560 transaction. It
+// tells whether the transaction failed in its execution or its postOp frame.
+type ReceiptFrameStatus struct {
+	Frame  string `json:"frame"`
+	Status uint64 `json:"status"`
+}
+
+type receiptFrameStatusMarshaling struct {
+	Status hexutil.Uint64
 }
 
 // NewReceipt creates a barebone transaction receipt, copying the init fields.
@@ -135,10 +170,25 @@ func (r *Receipt) EncodeRLP(w io.Writer) error {
 
 // encodeTyped writes the canonical encoding of a typed receipt to w.
 func (r *Receipt) encodeTyped(data *receiptRLP, w *bytes.Buffer) error {
-	w.WriteByte(r.Type)
+	w.WriteByte(r.envelopeType())
 	return rlp.Encode(w, data)
 }
 
+// SetTxType sets the type of the receipt to the one of its transaction, along
+// with the type byte the receipt is encoded with.
+func (r *Receipt) SetTxType(tx *Transaction) {
+	r.Type, r.legacyType = tx.Type(), tx.Rip7560LegacyType()
+}
+
+// envelopeType returns the type byte the receipt is encoded with, which is
+// Rip7560LegacyType for the receipts of transactions using it.
+func (r *Receipt) envelopeType() uint8 {
+	if r.legacyType {
+		return Rip7560LegacyType
+	}
+	return r.Type
+}
+
 // MarshalBinary returns the consensus encoding of the receipt.
 func (r *Receipt) MarshalBinary() ([]byte, error) {
 	if r.Type == LegacyTxType {
@@ -204,7 +254,7 @@ func (r *Receipt) decodeTyped(b []byte) error {
 		return errShortTypedReceipt
 	}
 	switch b[0] {
-	case DynamicFeeTxType, AccessListTxType, BlobTxType:
+	case DynamicFeeTxType, AccessListTxType, BlobTxType, Rip7560Type, SetCodeTxType:
 		var data receiptRLP
 		err := rlp.DecodeBytes(b[1:], &data)
 		if err != nil {
@@ -275,6 +325,19 @@ func (r *ReceiptForStorage) EncodeRLP(_w io.Writer) error {
 		}
 	}
 	w.ListEnd(logList)
+	if r.Type == Rip7560Type {
+		rip7560List := w.List()
+		w.WriteUint64(r.ValidationGasUsed)
+		frameList := w.List()
+		for _, frame := range r.FrameStatuses {
+			frameStatus := w.List()
+			w.WriteString(frame.Frame)
+			w.WriteUint64(frame.Status)
+			w.ListEnd(frameStatus)
+		}
+		w.ListEnd(frameList)
+		w.ListEnd(rip7560List)
+	}
 	w.ListEnd(outerList)
 	return w.Flush()
 }
@@ -292,6 +355,10 @@ func (r *ReceiptForStorage) DecodeRLP(s *rlp.Stream) error {
 	r.CumulativeGasUsed = stored.CumulativeGasUsed
 	r.Logs = stored.Logs
 	r.Bloom = CreateBloom(Receipts{(*Receipt)(r)})
+	if stored.Rip7560 != nil {
+		r.ValidationGasUsed = stored.Rip7560.ValidationGasUsed
+		r.FrameStatuses = stored.Rip7560.FrameStatuses
+	}
 
 	return nil
 }
@@ -310,9 +377,9 @@ func (rs Receipts) EncodeIndex(i int, w *bytes.Buffer) {
 		rlp.Encode(w, data)
 		return
 	}
-	w.WriteByte(r.Type)
+	w.WriteByte(r.envelopeType())
 	switch r.Type {
-	case AccessListTxType, DynamicFeeTxType, BlobTxType:
+	case AccessListTxType, DynamicFeeTxType, BlobTxType, Rip7560Type, SetCodeTxType:
 		rlp.Encode(w, data)
 	default:
 		// For unsupported types, write nothing. Since this is for
@@ -332,7 +399,7 @@ func (rs Receipts) DeriveFields(config *params.ChainConfig, hash common.Hash, nu
 	}
 	for i := 0; i < len(rs); i++ {
 		// The transaction type and hash can be retrieved from the transaction itself
-		rs[i].Type = txs[i].Type()
+		rs[i].SetTxType(txs[i])
 		rs[i].TxHash = txs[i].Hash()
 		rs[i].EffectiveGasPrice = txs[i].inner.effectiveGasPrice(new(big.Int), baseFee)
 
@@ -356,6 +423,18 @@ func (rs Receipts) DeriveFields(config *params.ChainConfig, hash common.Hash, nu
 		} else {
 			rs[i].ContractAddress = common.Address{}
 		}
+		// The paymaster and the account deployed by AA transactions are set in
+		// the transaction itself
+		rs[i].PaymasterAddress, rs[i].DeployedContractAddress = nil, nil
+		if txs[i].Type() == Rip7560Type {
+			aatx := txs[i].Rip7560TransactionData()
+			if aatx.Paymaster != nil && *aatx.Paymaster != (common.Address{}) {
+				rs[i].PaymasterAddress = copyAddressPtr(aatx.Paymaster)
+			}
+			if aatx.Deployer != nil {
+				rs[i].DeployedContractAddress = copyAddressPtr(aatx.Sender)
+			}
+		}
 
 		// The used gas can be calculated based on previous r
 		if i == 0 {
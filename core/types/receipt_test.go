@@ -491,6 +491,115 @@ func TestReceiptUnmarshalBinary(t *testing.T) {
 	}
 }
 
+// Tests that RIP-7560 receipts are encoded like the other typed receipts, so
+// that they are covered by the receipt root of the block.
+func TestRip7560ReceiptEncoding(t *testing.T) {
+	receipt := *eip1559Receipt
+	receipt.Type = Rip7560Type
+	receipt.Bloom = CreateBloom(Receipts{&receipt})
+
+	have, err := receipt.MarshalBinary()
+	if err != nil {
+		t.Fatalf("marshal binary error: %v", err)
+	}
+	buf := new(bytes.Buffer)
+	Receipts{&receipt}.EncodeIndex(0, buf)
+	if !bytes.Equal(have, buf.Bytes()) {
+		t.Errorf("BinaryMarshal and EncodeIndex mismatch, got %x want %x", have, buf.Bytes())
+	}
+	eip1559 := receipt
+	eip1559.Type = DynamicFeeTxType
+	eip1559Have, _ := eip1559.MarshalBinary()
+	if want := append([]byte{Rip7560Type}, eip1559Have[1:]...); !bytes.Equal(have, want) {
+		t.Errorf("encoded RLP mismatch, got %x want %x", have, want)
+	}
+	got := new(Receipt)
+	if err := got.UnmarshalBinary(have); err != nil {
+		t.Fatalf("unmarshal binary error: %v", err)
+	}
+	if !reflect.DeepEqual(got, &receipt) {
+		t.Errorf("receipt unmarshalled from binary mismatch, got %v want %v", got, &receipt)
+	}
+}
+
+// Tests that the fields of RIP-7560 receipts which can't be derived from the
+// transaction are kept in storage, and that the other ones are derived.
+func TestRip7560ReceiptStorage(t *testing.T) {
+	var (
+		sender    = common.Address{0x01}
+		paymaster = common.Address{0x02}
+		deployer  = common.Address{0x03}
+		tx        = NewTx(&Rip7560AccountAbstractionTx{
+			ChainID:   big.NewInt(1),
+			NonceKey:  new(big.Int),
+			GasTipCap: new(big.Int),
+			GasFeeCap: new(big.Int),
+			Sender:    &sender,
+			Paymaster: &paymaster,
+			Deployer:  &deployer,
+		})
+		receipt = &Receipt{
+			Type:              Rip7560Type,
+			Status:            ReceiptStatusFailed,
+			CumulativeGasUsed: 100,
+			Logs:              []*Log{},
+			ValidationGasUsed: 40,
+			FrameStatuses: []ReceiptFrameStatus{
+				{Frame: "deployer", Status: ReceiptStatusSuccessful},
+				{Frame: "account", Status: ReceiptStatusSuccessful},
+				{Frame: "paymaster", Status: ReceiptStatusSuccessful},
+				{Frame: "execution", Status: ReceiptStatusSuccessful},
+				{Frame: "postOp", Status: ReceiptStatusFailed},
+			},
+		}
+	)
+	enc, err := rlp.EncodeToBytes((*ReceiptForStorage)(receipt))
+	if err != nil {
+		t.Fatalf("failed to encode receipt: %v", err)
+	}
+	dec := new(ReceiptForStorage)
+	if err := rlp.DecodeBytes(enc, dec); err != nil {
+		t.Fatalf("failed to decode receipt: %v", err)
+	}
+	if dec.ValidationGasUsed != receipt.ValidationGasUsed {
+		t.Errorf("validation gas mismatch: have %d, want %d", dec.ValidationGasUsed, receipt.ValidationGasUsed)
+	}
+	if !reflect.DeepEqual(dec.FrameStatuses, receipt.FrameStatuses) {
+		t.Errorf("frame status mismatch: have %v, want %v", dec.FrameStatuses, receipt.FrameStatuses)
+	}
+	// Receipts stored before the RIP-7560 fields are still decoded
+	legacy, _ := rlp.EncodeToBytes(&storedReceiptRLP{receiptStatusFailedRLP, 100, []*Log{}, nil})
+	if err := rlp.DecodeBytes(legacy, new(ReceiptForStorage)); err != nil {
+		t.Fatalf("failed to decode receipt without RIP-7560 fields: %v", err)
+	}
+	receipts := Receipts{(*Receipt)(dec)}
+	if err := receipts.DeriveFields(params.TestChainConfig, common.Hash{}, 1, 0, new(big.Int), nil, Transactions{tx}); err != nil {
+		t.Fatalf("DeriveFields(...) = %v, want <nil>", err)
+	}
+	if have := receipts[0].PaymasterAddress; have == nil || *have != paymaster {
+		t.Errorf("paymaster mismatch: have %v, want %v", have, paymaster)
+	}
+	if have := receipts[0].DeployedContractAddress; have == nil || *have != sender {
+		t.Errorf("deployed contract mismatch: have %v, want %v", have, sender)
+	}
+	if receipts[0].ContractAddress != (common.Address{}) {
+		t.Errorf("contract address set: %v", receipts[0].ContractAddress)
+	}
+	// The fields survive a JSON round trip
+	b, err := json.Marshal(receipts[0])
+	if err != nil {
+		t.Fatalf("error marshaling receipt to json: %v", err)
+	}
+	var have Receipt
+	if err := json.Unmarshal(b, &have); err != nil {
+		t.Fatalf("error unmarshalling receipt from json: %v", err)
+	}
+	if have.ValidationGasUsed != receipt.ValidationGasUsed || !reflect.DeepEqual(have.FrameStatuses, receipt.FrameStatuses) ||
+		*have.PaymasterAddress != paymaster || *have.DeployedContractAddress != sender {
+		t.Errorf("receipt unmarshalled from json mismatch: %s", b)
+	}
+}
+
 func clearComputedFieldsOnReceipts(receipts []*Receipt) []*Receipt {
 	r := make([]*Receipt, len(receipts))
 	for i, receipt := range receipts {
@@ -0,0 +1,151 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ErrValidationRulesViolation is returned if the simulated validation of a
+// RIP-7560 transaction breaks the ERC-7562 validation rules.
+var ErrValidationRulesViolation = errors.New("validation rules violation")
+
+// ValidationFrame describes a top level call of the validation phase of a
+// RIP-7560 transaction.
+type ValidationFrame struct {
+	Name     string         `json:"name"`
+	Target   common.Address `json:"target"`
+	GasLimit hexutil.Uint64 `json:"gasLimit"`
+	GasUsed  hexutil.Uint64 `json:"gasUsed"`
+	Reverted bool           `json:"reverted"`
+}
+
+// DeploymentEstimate splits the validation gas of a transaction deploying its
+// account between the one-off deployment and the validation paid again by every
+// following transaction of the account. The prediction assumes the validation
+// of the account doesn't depend on the state warmed up by its deployment.
+type DeploymentEstimate struct {
+	DeploymentGas hexutil.Uint64 `json:"deploymentGas"` // Gas of the deployer frame and of its calldata
+	NextTxGas     hexutil.Uint64 `json:"nextTxGas"`     // Predicted validation gas of the next transaction
+}
+
+// WitnessAccount is the state of an account read by the validation phase of a
+// RIP-7560 transaction, as it was before the validation. Storage holds the slots
+// accessed by the validation frames, read or written.
+type WitnessAccount struct {
+	Exists   bool                        `json:"exists"`
+	Balance  *hexutil.Big                `json:"balance"`
+	Nonce    hexutil.Uint64              `json:"nonce"`
+	CodeHash common.Hash                 `json:"codeHash"`
+	Storage  map[common.Hash]common.Hash `json:"storage"`
+}
+
+// ValidationReport is the outcome of simulating the validation phase of a
+// RIP-7560 transaction. It is shared by the transaction pool, the RPC API and
+// the miner so that all of them judge transactions the same way.
+type ValidationReport struct {
+	TxHash         common.Hash                        `json:"txHash"`
+	Frames         []*ValidationFrame                 `json:"frames"`
+	GasUsed        hexutil.Uint64                     `json:"gasUsed"`
+	Reads          map[common.Address][]common.Hash   `json:"reads"`
+	Writes         map[common.Address][]common.Hash   `json:"writes"`
+	Witness        map[common.Address]*WitnessAccount `json:"witness"`
+	Violations     []string                           `json:"violations"`
+	ViolationRules []string                           `json:"violationRules"` // Rule broken by each of the violations
+	Rules          []string                           `json:"rules"`
+	ValidAfter     hexutil.Uint64                     `json:"validAfter"`
+	ValidUntil     hexutil.Uint64                     `json:"validUntil"`
+	Deployment     *DeploymentEstimate                `json:"deployment,omitempty"`
+	Error          string                             `json:"error,omitempty"` // Validation failure, if any
+
+	err error // Validation failure of a local simulation, keeping its kind
+}
+
+// SetErr records the validation failure of the transaction.
+func (r *ValidationReport) SetErr(err error) {
+	r.err = err
+	r.Error = ""
+	if err != nil {
+		r.Error = err.Error()
+	}
+}
+
+// Err returns the reason the transaction has to be rejected, either a failed
+// validation or a violation of the validation rules. The failures of reports
+// decoded from JSON only keep their message.
+func (r *ValidationReport) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.Error != "" {
+		return errors.New(r.Error)
+	}
+	if len(r.Violations) > 0 {
+		violations := make([]string, len(r.Violations))
+		for i, violation := range r.Violations {
+			violations[i] = violation
+			if i < len(r.ViolationRules) {
+				violations[i] = fmt.Sprintf("[%s] %s", r.ViolationRules[i], violation)
+			}
+		}
+		return fmt.Errorf("%w: %s", ErrValidationRulesViolation, strings.Join(violations, ", "))
+	}
+	return nil
+}
+
+// Rip7560FrameGas is the gas used by the frames of a RIP-7560 transaction,
+// grouped by the gas limit of the transaction covering them.
+type Rip7560FrameGas struct {
+	ValidationGas          hexutil.Uint64 `json:"validationGas"`          // Intrinsic gas, nonce manager, deployer and account validation frames
+	PaymasterValidationGas hexutil.Uint64 `json:"paymasterValidationGas"` // Paymaster validation frame
+	CallGas                hexutil.Uint64 `json:"callGas"`                // All execution frames
+	PostOpGas              hexutil.Uint64 `json:"postOpGas"`              // Paymaster postOp frame
+
+	// GasPenaltyPercent, GasPenalty and UsedGas are only reported by gas
+	// estimates: the share of the unused execution and postOp gas limits charged
+	// by the chain, the penalty charged for the estimated limits and the gas
+	// actually used by all the frames
+	GasPenaltyPercent *hexutil.Uint64 `json:"gasPenaltyPercent,omitempty"`
+	GasPenalty        *hexutil.Uint64 `json:"gasPenalty,omitempty"`
+	UsedGas           *hexutil.Uint64 `json:"usedGas,omitempty"`
+}
+
+// Rip7560CallFrame is a frame of a RIP-7560 transaction reported by its dry run.
+type Rip7560CallFrame struct {
+	Name     string         `json:"name"`
+	Target   common.Address `json:"target"`
+	GasLimit hexutil.Uint64 `json:"gasLimit"`
+	GasUsed  hexutil.Uint64 `json:"gasUsed"`
+	Error    string         `json:"error,omitempty"`
+}
+
+// Rip7560CallResult is the outcome of a dry run of both phases of a RIP-7560
+// transaction.
+type Rip7560CallResult struct {
+	Status     hexutil.Uint64      `json:"status"`
+	ReturnData hexutil.Bytes       `json:"returnData"` // Output of the last execution frame run
+	Logs       []*Log              `json:"logs"`
+	GasUsed    hexutil.Uint64      `json:"gasUsed"`
+	FrameGas   *Rip7560FrameGas    `json:"frameGas"`
+	Frames     []*Rip7560CallFrame `json:"frames"`
+	Error      string              `json:"error,omitempty"` // Revert of the postOp frame, if any
+}
@@ -49,7 +49,15 @@ const (
 	AccessListTxType = 0x01
 	DynamicFeeTxType = 0x02
 	BlobTxType       = 0x03
-	Rip7560Type      = 0x04
+	SetCodeTxType    = 0x04
+	Rip7560Type      = 0x05 // AA_TX_TYPE of RIP-7560
+
+	// Rip7560LegacyType is the AA_TX_TYPE early RIP-7560 devnets used before
+	// the type was assigned, colliding with SetCodeTxType. Envelopes of this
+	// type not decoding as set code transactions are decoded as RIP-7560 ones
+	// keeping their type byte, which are only valid before the type switch of
+	// the chain, see params.ChainConfig.RIP7560TypeBlock.
+	Rip7560LegacyType = 0x04
 )
 
 // Transaction is an Ethereum transaction.
@@ -120,7 +128,7 @@ func (tx *Transaction) EncodeRLP(w io.Writer) error {
 
 // encodeTyped writes the canonical encoding of a typed transaction to w.
 func (tx *Transaction) encodeTyped(w *bytes.Buffer) error {
-	w.WriteByte(tx.Type())
+	w.WriteByte(tx.envelopeType())
 	return tx.inner.encode(w)
 }
 
@@ -209,6 +217,17 @@ func (tx *Transaction) decodeTyped(b []byte) (TxData, error) {
 		inner = new(BlobTx)
 	case Rip7560Type:
 		inner = new(Rip7560AccountAbstractionTx)
+	case SetCodeTxType:
+		inner = new(SetCodeTx)
+		if err := inner.decode(b[1:]); err != nil {
+			// Early RIP-7560 devnets used the same type byte
+			legacy := &Rip7560AccountAbstractionTx{legacyType: true}
+			if legacy.decode(b[1:]) == nil {
+				return legacy, nil
+			}
+			return nil, err
+		}
+		return inner, nil
 	default:
 		return nil, ErrTxTypeNotSupported
 	}
@@ -275,6 +294,22 @@ func (tx *Transaction) Type() uint8 {
 	return tx.inner.txType()
 }
 
+// envelopeType returns the type byte the transaction is encoded and hashed with,
+// which is Rip7560LegacyType for the RIP-7560 transactions decoded from it.
+func (tx *Transaction) envelopeType() uint8 {
+	if tx.Rip7560LegacyType() {
+		return Rip7560LegacyType
+	}
+	return tx.Type()
+}
+
+// Rip7560LegacyType reports whether the transaction is a RIP-7560 one using the
+// type byte of early devnets, see Rip7560LegacyType.
+func (tx *Transaction) Rip7560LegacyType() bool {
+	aatx, ok := tx.inner.(*Rip7560AccountAbstractionTx)
+	return ok && aatx.legacyType
+}
+
 // ChainId returns the EIP155 chain ID of the transaction. The return value will always be
 // non-nil. For legacy transactions which are not replay-protected, the return value is
 // zero.
@@ -313,7 +348,12 @@ func (tx *Transaction) To() *common.Address {
 }
 
 // Cost returns (gas * gasPrice) + (blobGas * blobGasPrice) + value.
+// For RIP-7560 transactions it returns the fee cap multiplied by the total gas
+// limit of all frames, which is the amount charged upfront from the gas payer.
 func (tx *Transaction) Cost() *big.Int {
+	if aatx, ok := tx.inner.(*Rip7560AccountAbstractionTx); ok {
+		return aatx.maxCost()
+	}
 	total := new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(tx.Gas()))
 	if tx.Type() == BlobTxType {
 		total.Add(total, new(big.Int).Mul(tx.BlobGasFeeCap(), new(big.Int).SetUint64(tx.BlobGas())))
@@ -322,6 +362,26 @@ func (tx *Transaction) Cost() *big.Int {
 	return total
 }
 
+// SenderCost returns the part of Cost charged to the sender of the transaction.
+// The gas of RIP-7560 transactions sponsored by a paymaster is charged to the
+// paymaster instead, so their sender cost is zero.
+func (tx *Transaction) SenderCost() *big.Int {
+	if aatx, ok := tx.inner.(*Rip7560AccountAbstractionTx); ok && aatx.IsSponsored() {
+		return new(big.Int)
+	}
+	return tx.Cost()
+}
+
+// PayerCost returns the paymaster of a sponsored RIP-7560 transaction together
+// with the maximum amount charged to it. For all other transactions the payer
+// is nil and the cost zero, as everything is charged to the sender.
+func (tx *Transaction) PayerCost() (*common.Address, *big.Int) {
+	if aatx, ok := tx.inner.(*Rip7560AccountAbstractionTx); ok && aatx.IsSponsored() {
+		return copyAddressPtr(aatx.Paymaster), aatx.maxCost()
+	}
+	return nil, new(big.Int)
+}
+
 // RawSignatureValues returns the V, R, S signature values of the transaction.
 // The return values should not be modified by the caller.
 // The return values may be nil or zero, if the transaction is unsigned.
@@ -411,6 +471,15 @@ func (tx *Transaction) BlobHashes() []common.Hash {
 	return nil
 }
 
+// SetCodeAuthorizations returns the authorizations list of the transaction.
+func (tx *Transaction) SetCodeAuthorizations() []SetCodeAuthorization {
+	setcodetx, ok := tx.inner.(*SetCodeTx)
+	if !ok {
+		return nil
+	}
+	return setcodetx.AuthList
+}
+
 // BlobTxSidecar returns the sidecar of a blob transaction, nil otherwise.
 func (tx *Transaction) BlobTxSidecar() *BlobTxSidecar {
 	if blobtx, ok := tx.inner.(*BlobTx); ok {
@@ -498,7 +567,7 @@ func (tx *Transaction) Hash() common.Hash {
 	if tx.Type() == LegacyTxType {
 		h = rlpHash(tx.inner)
 	} else {
-		h = prefixedRlpHash(tx.Type(), tx.inner)
+		h = prefixedRlpHash(tx.envelopeType(), tx.inner)
 	}
 	tx.hash.Store(&h)
 	return h
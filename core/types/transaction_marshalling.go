@@ -53,6 +53,24 @@ type txJSON struct {
 	Commitments []kzg4844.Commitment `json:"commitments,omitempty"`
 	Proofs      []kzg4844.Proof      `json:"proofs,omitempty"`
 
+	// EIP-7702 set code transaction fields:
+	AuthorizationList []SetCodeAuthorization `json:"authorizationList,omitempty"`
+
+	// RIP-7560 transaction fields:
+	Sender                        *common.Address `json:"sender,omitempty"`
+	AuthorizationData             *hexutil.Bytes  `json:"authorizationData,omitempty"`
+	ExecutionData                 *hexutil.Bytes  `json:"executionData,omitempty"`
+	ExecutionCalls                []hexutil.Bytes `json:"executionCalls,omitempty"`
+	Paymaster                     *common.Address `json:"paymaster,omitempty"`
+	PaymasterData                 *hexutil.Bytes  `json:"paymasterData,omitempty"`
+	Deployer                      *common.Address `json:"deployer,omitempty"`
+	DeployerData                  *hexutil.Bytes  `json:"deployerData,omitempty"`
+	BuilderFee                    *hexutil.Big    `json:"builderFee,omitempty"`
+	VerificationGasLimit          *hexutil.Uint64 `json:"verificationGasLimit,omitempty"`
+	PaymasterVerificationGasLimit *hexutil.Uint64 `json:"paymasterVerificationGasLimit,omitempty"`
+	PaymasterPostOpGasLimit       *hexutil.Uint64 `json:"paymasterPostOpGasLimit,omitempty"`
+	NonceKey                      *hexutil.Big    `json:"nonceKey,omitempty"`
+
 	// Only used for encoding:
 	Hash common.Hash `json:"hash"`
 }
@@ -153,6 +171,46 @@ func (tx *Transaction) MarshalJSON() ([]byte, error) {
 			enc.Commitments = itx.Sidecar.Commitments
 			enc.Proofs = itx.Sidecar.Proofs
 		}
+
+	case *SetCodeTx:
+		enc.ChainID = (*hexutil.Big)(itx.ChainID.ToBig())
+		enc.Nonce = (*hexutil.Uint64)(&itx.Nonce)
+		enc.To = tx.To()
+		enc.Gas = (*hexutil.Uint64)(&itx.Gas)
+		enc.MaxFeePerGas = (*hexutil.Big)(itx.GasFeeCap.ToBig())
+		enc.MaxPriorityFeePerGas = (*hexutil.Big)(itx.GasTipCap.ToBig())
+		enc.Value = (*hexutil.Big)(itx.Value.ToBig())
+		enc.Input = (*hexutil.Bytes)(&itx.Data)
+		enc.AccessList = &itx.AccessList
+		enc.AuthorizationList = itx.AuthList
+		enc.V = (*hexutil.Big)(itx.V.ToBig())
+		enc.R = (*hexutil.Big)(itx.R.ToBig())
+		enc.S = (*hexutil.Big)(itx.S.ToBig())
+		yparity := itx.V.Uint64()
+		enc.YParity = (*hexutil.Uint64)(&yparity)
+
+	case *Rip7560AccountAbstractionTx:
+		enc.ChainID = (*hexutil.Big)(itx.ChainID)
+		enc.Nonce = (*hexutil.Uint64)(&itx.Nonce)
+		enc.Gas = (*hexutil.Uint64)(&itx.Gas)
+		enc.MaxFeePerGas = (*hexutil.Big)(itx.GasFeeCap)
+		enc.MaxPriorityFeePerGas = (*hexutil.Big)(itx.GasTipCap)
+		enc.AccessList = &itx.AccessList
+		enc.Sender = itx.Sender
+		enc.AuthorizationData = (*hexutil.Bytes)(&itx.AuthorizationData)
+		enc.ExecutionData = (*hexutil.Bytes)(&itx.ExecutionData)
+		for _, call := range itx.ExecutionCalls {
+			enc.ExecutionCalls = append(enc.ExecutionCalls, call)
+		}
+		enc.Paymaster = itx.Paymaster
+		enc.PaymasterData = (*hexutil.Bytes)(&itx.PaymasterData)
+		enc.Deployer = itx.Deployer
+		enc.DeployerData = (*hexutil.Bytes)(&itx.DeployerData)
+		enc.BuilderFee = (*hexutil.Big)(itx.BuilderFee)
+		enc.VerificationGasLimit = (*hexutil.Uint64)(&itx.ValidationGasLimit)
+		enc.PaymasterVerificationGasLimit = (*hexutil.Uint64)(&itx.PaymasterValidationGasLimit)
+		enc.PaymasterPostOpGasLimit = (*hexutil.Uint64)(&itx.PostOpGas)
+		enc.NonceKey = (*hexutil.Big)(itx.NonceKey)
 	}
 	return json.Marshal(&enc)
 }
@@ -409,6 +467,157 @@ func (tx *Transaction) UnmarshalJSON(input []byte) error {
 			}
 		}
 
+	case SetCodeTxType:
+		var itx SetCodeTx
+		inner = &itx
+		if dec.ChainID == nil {
+			return errors.New("missing required field 'chainId' in transaction")
+		}
+		var overflow bool
+		itx.ChainID, overflow = uint256.FromBig(dec.ChainID.ToInt())
+		if overflow {
+			return errors.New("'chainId' value overflows uint256")
+		}
+		if dec.Nonce == nil {
+			return errors.New("missing required field 'nonce' in transaction")
+		}
+		itx.Nonce = uint64(*dec.Nonce)
+		if dec.To == nil {
+			return errors.New("missing required field 'to' in transaction")
+		}
+		itx.To = *dec.To
+		if dec.Gas == nil {
+			return errors.New("missing required field 'gas' for txdata")
+		}
+		itx.Gas = uint64(*dec.Gas)
+		if dec.MaxPriorityFeePerGas == nil {
+			return errors.New("missing required field 'maxPriorityFeePerGas' for txdata")
+		}
+		itx.GasTipCap = uint256.MustFromBig((*big.Int)(dec.MaxPriorityFeePerGas))
+		if dec.MaxFeePerGas == nil {
+			return errors.New("missing required field 'maxFeePerGas' for txdata")
+		}
+		itx.GasFeeCap = uint256.MustFromBig((*big.Int)(dec.MaxFeePerGas))
+		if dec.Value == nil {
+			return errors.New("missing required field 'value' in transaction")
+		}
+		itx.Value = uint256.MustFromBig((*big.Int)(dec.Value))
+		if dec.Input == nil {
+			return errors.New("missing required field 'input' in transaction")
+		}
+		itx.Data = *dec.Input
+		if dec.AccessList != nil {
+			itx.AccessList = *dec.AccessList
+		}
+		if dec.AuthorizationList == nil {
+			return errors.New("missing required field 'authorizationList' in transaction")
+		}
+		itx.AuthList = dec.AuthorizationList
+
+		// signature R
+		if dec.R == nil {
+			return errors.New("missing required field 'r' in transaction")
+		}
+		itx.R, overflow = uint256.FromBig((*big.Int)(dec.R))
+		if overflow {
+			return errors.New("'r' value overflows uint256")
+		}
+		// signature S
+		if dec.S == nil {
+			return errors.New("missing required field 's' in transaction")
+		}
+		itx.S, overflow = uint256.FromBig((*big.Int)(dec.S))
+		if overflow {
+			return errors.New("'s' value overflows uint256")
+		}
+		// signature V
+		vbig, err := dec.yParityValue()
+		if err != nil {
+			return err
+		}
+		itx.V, overflow = uint256.FromBig(vbig)
+		if overflow {
+			return errors.New("'v' value overflows uint256")
+		}
+		if itx.V.Sign() != 0 || itx.R.Sign() != 0 || itx.S.Sign() != 0 {
+			if err := sanityCheckSignature(vbig, itx.R.ToBig(), itx.S.ToBig(), false); err != nil {
+				return err
+			}
+		}
+
+	case Rip7560Type:
+		var itx Rip7560AccountAbstractionTx
+		inner = &itx
+		if dec.ChainID == nil {
+			return errors.New("missing required field 'chainId' in transaction")
+		}
+		itx.ChainID = (*big.Int)(dec.ChainID)
+		if dec.Nonce == nil {
+			return errors.New("missing required field 'nonce' in transaction")
+		}
+		itx.Nonce = uint64(*dec.Nonce)
+		if dec.Gas == nil {
+			return errors.New("missing required field 'gas' for txdata")
+		}
+		itx.Gas = uint64(*dec.Gas)
+		if dec.MaxPriorityFeePerGas == nil {
+			return errors.New("missing required field 'maxPriorityFeePerGas' for txdata")
+		}
+		itx.GasTipCap = (*big.Int)(dec.MaxPriorityFeePerGas)
+		if dec.MaxFeePerGas == nil {
+			return errors.New("missing required field 'maxFeePerGas' for txdata")
+		}
+		itx.GasFeeCap = (*big.Int)(dec.MaxFeePerGas)
+		if dec.AccessList != nil {
+			itx.AccessList = *dec.AccessList
+		}
+		if dec.Sender == nil {
+			return errors.New("missing required field 'sender' in transaction")
+		}
+		itx.Sender = dec.Sender
+		if dec.VerificationGasLimit == nil {
+			return errors.New("missing required field 'verificationGasLimit' in transaction")
+		}
+		itx.ValidationGasLimit = uint64(*dec.VerificationGasLimit)
+
+		// The remaining fields are optional, the RPC representation leaves
+		// out the empty ones
+		if dec.AuthorizationData != nil {
+			itx.AuthorizationData = *dec.AuthorizationData
+		}
+		if dec.ExecutionData != nil {
+			itx.ExecutionData = *dec.ExecutionData
+		}
+		for _, call := range dec.ExecutionCalls {
+			itx.ExecutionCalls = append(itx.ExecutionCalls, call)
+		}
+		if dec.Paymaster != nil && *dec.Paymaster != (common.Address{}) {
+			itx.Paymaster = dec.Paymaster
+		}
+		if dec.PaymasterData != nil {
+			itx.PaymasterData = *dec.PaymasterData
+		}
+		if dec.Deployer != nil && *dec.Deployer != (common.Address{}) {
+			itx.Deployer = dec.Deployer
+		}
+		if dec.DeployerData != nil {
+			itx.DeployerData = *dec.DeployerData
+		}
+		itx.BuilderFee = new(big.Int)
+		if dec.BuilderFee != nil {
+			itx.BuilderFee = (*big.Int)(dec.BuilderFee)
+		}
+		if dec.PaymasterVerificationGasLimit != nil {
+			itx.PaymasterValidationGasLimit = uint64(*dec.PaymasterVerificationGasLimit)
+		}
+		if dec.PaymasterPostOpGasLimit != nil {
+			itx.PostOpGas = uint64(*dec.PaymasterPostOpGasLimit)
+		}
+		itx.NonceKey = new(big.Int)
+		if dec.NonceKey != nil {
+			itx.NonceKey = (*big.Int)(dec.NonceKey)
+		}
+
 	default:
 		return ErrTxTypeNotSupported
 	}
@@ -5,15 +5,18 @@ import (
 	"math/big"
 )
 
-type rip7560Signer struct{ londonSigner }
+type rip7560Signer struct{ pragueSigner }
 
+// NewRIP7560Signer returns a signer that accepts RIP-7560 transactions, along
+// with all the transaction types accepted by the Prague signer. Whether the set
+// code transactions are valid depends on the activation of Prague.
 func NewRIP7560Signer(chainId *big.Int) Signer {
-	return rip7560Signer{londonSigner{eip2930Signer{NewEIP155Signer(chainId)}}}
+	return rip7560Signer{pragueSigner{cancunSigner{londonSigner{eip2930Signer{NewEIP155Signer(chainId)}}}}}
 }
 
 func (s rip7560Signer) Sender(tx *Transaction) (common.Address, error) {
 	if tx.Type() != Rip7560Type {
-		return s.londonSigner.Sender(tx)
+		return s.pragueSigner.Sender(tx)
 	}
 	return [20]byte{}, nil
 }
@@ -22,30 +25,34 @@ func (s rip7560Signer) Sender(tx *Transaction) (common.Address, error) {
 // It does not uniquely identify the transaction.
 func (s rip7560Signer) Hash(tx *Transaction) common.Hash {
 	if tx.Type() != Rip7560Type {
-		return s.londonSigner.Hash(tx)
+		return s.pragueSigner.Hash(tx)
 	}
 	aatx := tx.Rip7560TransactionData()
-	return prefixedRlpHash(
-		tx.Type(),
-		[]interface{}{
-			s.chainId,
-			aatx.Nonce,
-			aatx.NonceKey,
-			aatx.Sender,
-			aatx.Deployer,
-			aatx.DeployerData,
-			aatx.Paymaster,
-			aatx.PaymasterData,
-			aatx.ExecutionData,
-			aatx.BuilderFee,
-			tx.GasTipCap(),
-			tx.GasFeeCap(),
-			aatx.ValidationGasLimit,
-			aatx.PaymasterValidationGasLimit,
-			aatx.PostOpGas,
-			tx.Gas(),
-			tx.AccessList(),
+	fields := []interface{}{
+		s.chainId,
+		aatx.Nonce,
+		aatx.NonceKey,
+		aatx.Sender,
+		aatx.Deployer,
+		aatx.DeployerData,
+		aatx.Paymaster,
+		aatx.PaymasterData,
+		aatx.ExecutionData,
+		aatx.BuilderFee,
+		tx.GasTipCap(),
+		tx.GasFeeCap(),
+		aatx.ValidationGasLimit,
+		aatx.PaymasterValidationGasLimit,
+		aatx.PostOpGas,
+		tx.Gas(),
+		tx.AccessList(),
 
-			// no AuthorizationData here - this is hashing "for signing"
-		})
+		// no AuthorizationData here - this is hashing "for signing"
+	}
+	// Batched execution calls are only hashed if present, so that the signing
+	// hash of single call transactions is unchanged.
+	if len(aatx.ExecutionCalls) != 0 {
+		fields = append(fields, aatx.ExecutionCalls)
+	}
+	return prefixedRlpHash(tx.envelopeType(), fields)
 }
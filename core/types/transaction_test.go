@@ -30,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/holiman/uint256"
 )
 
 // The values in those tests are from the Transaction Tests
@@ -543,3 +544,49 @@ func TestYParityJSONUnmarshalling(t *testing.T) {
 		}
 	}
 }
+
+// Tests that set code transactions are encoded with the type byte assigned by
+// EIP-7702, and that their payloads aren't taken for RIP-7560 ones.
+func TestSetCodeTxType(t *testing.T) {
+	tx := NewTx(&SetCodeTx{
+		ChainID:   uint256.NewInt(1),
+		GasTipCap: uint256.NewInt(1),
+		GasFeeCap: uint256.NewInt(1),
+		Gas:       21000,
+		To:        testAddr,
+		Value:     uint256.NewInt(1),
+		AuthList:  []SetCodeAuthorization{{ChainID: *uint256.NewInt(1), Address: testAddr, V: 1, R: *uint256.NewInt(1), S: *uint256.NewInt(1)}},
+		V:         new(uint256.Int),
+		R:         uint256.NewInt(1),
+		S:         uint256.NewInt(1),
+	})
+	enc, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enc[0] != 0x04 {
+		t.Fatalf("set code transaction type byte mismatch: have %#x, want 0x04", enc[0])
+	}
+	dec := new(Transaction)
+	if err := dec.UnmarshalBinary(enc); err != nil {
+		t.Fatalf("failed to decode set code transaction: %v", err)
+	}
+	if dec.Type() != SetCodeTxType || dec.Hash() != tx.Hash() {
+		t.Fatalf("decoded set code transaction mismatch: type %d, hash %x", dec.Type(), dec.Hash())
+	}
+	enc[0] = Rip7560Type
+	if err := new(Transaction).UnmarshalBinary(enc); err == nil {
+		t.Fatal("set code transaction decoded with the RIP-7560 type")
+	}
+	js, err := tx.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(js, []byte(`"type":"0x4"`)) {
+		t.Fatalf("unexpected set code transaction JSON: %s", js)
+	}
+	js = bytes.Replace(js, []byte(`"type":"0x4"`), []byte(`"type":"0x5"`), 1)
+	if err := new(Transaction).UnmarshalJSON(js); err == nil {
+		t.Fatal("set code transaction JSON decoded with the RIP-7560 type")
+	}
+}
@@ -27,6 +27,9 @@ import (
 )
 
 // Rip7560AccountAbstractionTx represents an RIP-7560 transaction.
+// Rip7560AccountAbstractionTx is the data of a RIP-7560 transaction. Its RLP
+// payload, following the type byte, is the list of the fields in order, with
+// the execution calls appended only if present.
 type Rip7560AccountAbstractionTx struct {
 	// overlapping fields
 	ChainID    *big.Int
@@ -51,35 +54,56 @@ type Rip7560AccountAbstractionTx struct {
 
 	// RIP-7712 two-dimensional nonce (optional), 192 bits
 	NonceKey *big.Int
+
+	// ExecutionCalls are the calls made to the sender in the execution phase,
+	// one frame each. They replace ExecutionData if present.
+	ExecutionCalls [][]byte `rlp:"optional"`
+
+	legacyType bool // Decoded from Rip7560LegacyType, which it is encoded with
 }
 
 // copy creates a deep copy of the transaction data and initializes all fields.
+//
+// Zero paymaster and deployer addresses are replaced by nil, which is how they
+// are represented after an RLP round trip. This keeps the hash and size cached
+// on a locally created transaction consistent with its decoded counterpart.
 func (tx *Rip7560AccountAbstractionTx) copy() TxData {
 	cpy := &Rip7560AccountAbstractionTx{
-		//To:            copyAddressPtr(tx.To),
-		ExecutionData: common.CopyBytes(tx.ExecutionData),
-		Nonce:         tx.Nonce,
-		NonceKey:      new(big.Int),
-		Gas:           tx.Gas,
+		Nonce: tx.Nonce,
+		Gas:   tx.Gas,
 		// These are copied below.
 		AccessList: make(AccessList, len(tx.AccessList)),
-		//Value:      new(big.Int),
-		ChainID:   new(big.Int),
-		GasTipCap: new(big.Int),
-		GasFeeCap: new(big.Int),
+		ChainID:    new(big.Int),
+		GasTipCap:  new(big.Int),
+		GasFeeCap:  new(big.Int),
 
 		Sender:                      copyAddressPtr(tx.Sender),
 		AuthorizationData:           common.CopyBytes(tx.AuthorizationData),
-		Paymaster:                   copyAddressPtr(tx.Paymaster),
+		ExecutionData:               common.CopyBytes(tx.ExecutionData),
+		Paymaster:                   copyNonZeroAddressPtr(tx.Paymaster),
 		PaymasterData:               common.CopyBytes(tx.PaymasterData),
-		Deployer:                    copyAddressPtr(tx.Deployer),
+		Deployer:                    copyNonZeroAddressPtr(tx.Deployer),
 		DeployerData:                common.CopyBytes(tx.DeployerData),
 		BuilderFee:                  new(big.Int),
 		ValidationGasLimit:          tx.ValidationGasLimit,
 		PaymasterValidationGasLimit: tx.PaymasterValidationGasLimit,
 		PostOpGas:                   tx.PostOpGas,
+		NonceKey:                    new(big.Int),
+
+		legacyType: tx.legacyType,
+	}
+	if tx.ExecutionCalls != nil {
+		cpy.ExecutionCalls = make([][]byte, len(tx.ExecutionCalls))
+		for i, call := range tx.ExecutionCalls {
+			cpy.ExecutionCalls[i] = common.CopyBytes(call)
+		}
+	}
+	for i, tuple := range tx.AccessList {
+		cpy.AccessList[i] = AccessTuple{
+			Address:     tuple.Address,
+			StorageKeys: append([]common.Hash(nil), tuple.StorageKeys...),
+		}
 	}
-	copy(cpy.AccessList, tx.AccessList)
 	if tx.ChainID != nil {
 		cpy.ChainID.Set(tx.ChainID)
 	}
@@ -98,6 +122,16 @@ func (tx *Rip7560AccountAbstractionTx) copy() TxData {
 	return cpy
 }
 
+// copyNonZeroAddressPtr copies an address pointer, mapping the zero address
+// to nil.
+func copyNonZeroAddressPtr(a *common.Address) *common.Address {
+	if a == nil || *a == (common.Address{}) {
+		return nil
+	}
+	cpy := *a
+	return &cpy
+}
+
 // accessors for innerTx.
 func (tx *Rip7560AccountAbstractionTx) txType() byte           { return Rip7560Type }
 func (tx *Rip7560AccountAbstractionTx) chainID() *big.Int      { return tx.ChainID }
@@ -112,12 +146,18 @@ func (tx *Rip7560AccountAbstractionTx) nonce() uint64          { return tx.Nonce
 func (tx *Rip7560AccountAbstractionTx) to() *common.Address    { return nil }
 
 func (tx *Rip7560AccountAbstractionTx) GasPayer() *common.Address {
-	if tx.Paymaster != nil && tx.Paymaster.Cmp(common.Address{}) != 0 {
+	if tx.IsSponsored() {
 		return tx.Paymaster
 	}
 	return tx.Sender
 }
 
+// IsSponsored returns whether the gas of the transaction is paid by a paymaster,
+// a zero paymaster address standing for no paymaster.
+func (tx *Rip7560AccountAbstractionTx) IsSponsored() bool {
+	return tx.Paymaster != nil && tx.Paymaster.Cmp(common.Address{}) != 0
+}
+
 func SumGas(vals ...uint64) (uint64, error) {
 	var sum uint64
 	for _, val := range vals {
@@ -151,12 +191,31 @@ func (tx *Rip7560AccountAbstractionTx) PreTransactionGasCost() (uint64, error) {
 }
 
 func (tx *Rip7560AccountAbstractionTx) callDataGasCost() (uint64, error) {
-	return SumGas(
+	costs := []uint64{
 		callDataCost(tx.AuthorizationData),
 		callDataCost(tx.DeployerData),
 		callDataCost(tx.ExecutionData),
 		callDataCost(tx.PaymasterData),
-	)
+	}
+	for _, call := range tx.ExecutionCalls {
+		costs = append(costs, callDataCost(call))
+	}
+	return SumGas(costs...)
+}
+
+// DeployerDataGasCost returns the calldata gas charged for the deployer data,
+// which is only paid by the transaction deploying the account.
+func (tx *Rip7560AccountAbstractionTx) DeployerDataGasCost() uint64 {
+	return callDataCost(tx.DeployerData)
+}
+
+// ExecutionFrames returns the calldata of the execution frames of the
+// transaction, in execution order.
+func (tx *Rip7560AccountAbstractionTx) ExecutionFrames() [][]byte {
+	if len(tx.ExecutionCalls) != 0 {
+		return tx.ExecutionCalls
+	}
+	return [][]byte{tx.ExecutionData}
 }
 
 // note: copied from state_transition.go 'IntrinsicGas' function
@@ -186,6 +245,16 @@ func (tx *Rip7560AccountAbstractionTx) IsRip7712Nonce() bool {
 	return tx.NonceKey != nil && tx.NonceKey.Cmp(big.NewInt(0)) == 1
 }
 
+// maxCost returns the maximum amount the gas payer is charged upfront, i.e. the
+// fee cap multiplied by the total gas limit of all frames.
+func (tx *Rip7560AccountAbstractionTx) maxCost() *big.Int {
+	gas := new(big.Int).SetUint64(params.Rip7560TxGas)
+	for _, limit := range []uint64{tx.Gas, tx.ValidationGasLimit, tx.PaymasterValidationGasLimit, tx.PostOpGas} {
+		gas.Add(gas, new(big.Int).SetUint64(limit))
+	}
+	return gas.Mul(gas, tx.GasFeeCap)
+}
+
 func (tx *Rip7560AccountAbstractionTx) EffectiveGasPrice(baseFee *big.Int) *big.Int {
 	return tx.effectiveGasPrice(new(big.Int), baseFee)
 }
@@ -211,15 +280,7 @@ func (tx *Rip7560AccountAbstractionTx) setSignatureValues(chainID, v, r, s *big.
 
 // encode the subtype byte and the payload-bearing bytes of the RIP-7560 transaction
 func (tx *Rip7560AccountAbstractionTx) encode(b *bytes.Buffer) error {
-	zeroAddress := common.Address{}
-	txCopy := tx.copy().(*Rip7560AccountAbstractionTx)
-	if txCopy.Paymaster != nil && zeroAddress.Cmp(*txCopy.Paymaster) == 0 {
-		txCopy.Paymaster = nil
-	}
-	if txCopy.Deployer != nil && zeroAddress.Cmp(*txCopy.Deployer) == 0 {
-		txCopy.Deployer = nil
-	}
-	return rlp.Encode(b, txCopy)
+	return rlp.Encode(b, tx)
 }
 
 // decode the payload-bearing bytes of the encoded RIP-7560 transaction payload
@@ -0,0 +1,376 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func newTestRip7560Tx(paymaster, deployer *common.Address) *Transaction {
+	return NewTx(&Rip7560AccountAbstractionTx{
+		ChainID:                     big.NewInt(1337),
+		Nonce:                       1,
+		GasTipCap:                   big.NewInt(2),
+		GasFeeCap:                   big.NewInt(10),
+		Gas:                         100_000,
+		AccessList:                  AccessList{{Address: common.Address{0x01}, StorageKeys: []common.Hash{{0x02}}}},
+		Sender:                      &common.Address{0xaa},
+		AuthorizationData:           []byte{0x01},
+		ExecutionData:               []byte{0x02},
+		Paymaster:                   paymaster,
+		Deployer:                    deployer,
+		ValidationGasLimit:          50_000,
+		PaymasterValidationGasLimit: 20_000,
+		PostOpGas:                   10_000,
+	})
+}
+
+// Tests that the cached hash and size of a locally created RIP-7560 transaction
+// match the ones of the same transaction after an encoding round trip, also if
+// the optional addresses were explicitly set to zero.
+func TestRip7560HashSizeRoundTrip(t *testing.T) {
+	for _, tx := range []*Transaction{
+		newTestRip7560Tx(nil, nil),
+		newTestRip7560Tx(&common.Address{}, &common.Address{}),
+		newTestRip7560Tx(&common.Address{0xbb}, &common.Address{0xcc}),
+	} {
+		enc, err := tx.MarshalBinary()
+		if err != nil {
+			t.Fatalf("failed to encode: %v", err)
+		}
+		dec := new(Transaction)
+		if err := dec.UnmarshalBinary(enc); err != nil {
+			t.Fatalf("failed to decode: %v", err)
+		}
+		if tx.Hash() != dec.Hash() {
+			t.Errorf("hash mismatch: local %x, decoded %x", tx.Hash(), dec.Hash())
+		}
+		if tx.Size() != dec.Size() || tx.Size() != uint64(len(enc)) {
+			t.Errorf("size mismatch: local %d, decoded %d, encoded %d", tx.Size(), dec.Size(), len(enc))
+		}
+	}
+}
+
+// Tests that RIP-7560 transactions round trip through both their assigned type
+// byte and the legacy one of the early devnets, the latter keeping the type byte
+// of the original encoding for the transaction and its receipt.
+func TestRip7560LegacyType(t *testing.T) {
+	tx := newTestRip7560Tx(&common.Address{0xbb}, nil)
+	enc, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	if enc[0] != Rip7560Type {
+		t.Fatalf("type byte mismatch: have %#x, want %#x", enc[0], Rip7560Type)
+	}
+	dec := new(Transaction)
+	if err := dec.UnmarshalBinary(enc); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if dec.Rip7560LegacyType() || dec.Hash() != tx.Hash() {
+		t.Fatalf("decoded transaction mismatch: legacy %v, hash %x", dec.Rip7560LegacyType(), dec.Hash())
+	}
+
+	legacyEnc := append([]byte{Rip7560LegacyType}, enc[1:]...)
+	legacy := new(Transaction)
+	if err := legacy.UnmarshalBinary(legacyEnc); err != nil {
+		t.Fatalf("failed to decode legacy type: %v", err)
+	}
+	if legacy.Type() != Rip7560Type || !legacy.Rip7560LegacyType() {
+		t.Fatalf("legacy type mismatch: type %d, legacy %v", legacy.Type(), legacy.Rip7560LegacyType())
+	}
+	if legacy.Hash() == tx.Hash() {
+		t.Fatal("legacy type transaction hashed as the assigned type")
+	}
+	if reenc, err := legacy.MarshalBinary(); err != nil || !bytes.Equal(reenc, legacyEnc) {
+		t.Fatalf("legacy type binary round trip mismatch: %x, %v", reenc, err)
+	}
+	rlpEnc, err := rlp.EncodeToBytes(legacy)
+	if err != nil {
+		t.Fatalf("failed to rlp encode legacy type: %v", err)
+	}
+	rlpDec := new(Transaction)
+	if err := rlp.DecodeBytes(rlpEnc, rlpDec); err != nil {
+		t.Fatalf("failed to rlp decode legacy type: %v", err)
+	}
+	if !rlpDec.Rip7560LegacyType() || rlpDec.Hash() != legacy.Hash() {
+		t.Fatalf("legacy type rlp round trip mismatch: legacy %v, hash %x", rlpDec.Rip7560LegacyType(), rlpDec.Hash())
+	}
+
+	receipts := make(Receipts, 2)
+	for i, tx := range []*Transaction{tx, legacy} {
+		receipts[i] = &Receipt{Status: ReceiptStatusSuccessful, Logs: []*Log{}}
+		receipts[i].SetTxType(tx)
+	}
+	for i, want := range []byte{Rip7560Type, Rip7560LegacyType} {
+		var buf bytes.Buffer
+		receipts.EncodeIndex(i, &buf)
+		if buf.Bytes()[0] != want {
+			t.Errorf("receipt %d type byte mismatch: have %#x, want %#x", i, buf.Bytes()[0], want)
+		}
+	}
+}
+
+// Tests that RIP-7560 transactions round trip through their JSON encoding,
+// keeping all the fields covered by the hash.
+func TestRip7560JSONRoundTrip(t *testing.T) {
+	calls := newTestRip7560Tx(nil, nil).Rip7560TransactionData()
+	calls.ExecutionData, calls.ExecutionCalls = nil, [][]byte{{0x01}, {0x02, 0x03}}
+	calls.NonceKey = big.NewInt(7)
+	calls.BuilderFee = big.NewInt(3)
+
+	for i, tx := range []*Transaction{
+		newTestRip7560Tx(nil, nil),
+		newTestRip7560Tx(&common.Address{}, &common.Address{}),
+		newTestRip7560Tx(&common.Address{0xbb}, &common.Address{0xcc}),
+		NewTx(calls),
+	} {
+		enc, err := json.Marshal(tx)
+		if err != nil {
+			t.Fatalf("test %d: failed to encode: %v", i, err)
+		}
+		dec := new(Transaction)
+		if err := json.Unmarshal(enc, dec); err != nil {
+			t.Fatalf("test %d: failed to decode: %v", i, err)
+		}
+		if tx.Hash() != dec.Hash() {
+			t.Errorf("test %d: hash mismatch: have %x, want %x", i, dec.Hash(), tx.Hash())
+		}
+		if !reflect.DeepEqual(tx.Rip7560TransactionData().ExecutionFrames(), dec.Rip7560TransactionData().ExecutionFrames()) {
+			t.Errorf("test %d: execution frames mismatch", i)
+		}
+	}
+	// The sender and the validation gas limit are always required
+	for _, field := range []string{"sender", "verificationGasLimit"} {
+		var fields map[string]interface{}
+		enc, _ := json.Marshal(newTestRip7560Tx(nil, nil))
+		json.Unmarshal(enc, &fields)
+		delete(fields, field)
+		enc, _ = json.Marshal(fields)
+		if err := json.Unmarshal(enc, new(Transaction)); err == nil {
+			t.Errorf("missing %s accepted", field)
+		}
+	}
+}
+
+// Tests that copying RIP-7560 transaction data doesn't share mutable state.
+func TestRip7560Copy(t *testing.T) {
+	orig := newTestRip7560Tx(&common.Address{0xbb}, nil).Rip7560TransactionData()
+	cpy := orig.copy().(*Rip7560AccountAbstractionTx)
+
+	cpy.GasFeeCap.SetUint64(1)
+	cpy.Paymaster[0] = 0xff
+	cpy.ExecutionData[0] = 0xff
+	cpy.AccessList[0].StorageKeys[0] = common.Hash{0xff}
+
+	if orig.GasFeeCap.Uint64() != 10 {
+		t.Error("fee cap shared with copy")
+	}
+	if orig.Paymaster[0] != 0xbb {
+		t.Error("paymaster shared with copy")
+	}
+	if orig.ExecutionData[0] != 0x02 {
+		t.Error("execution data shared with copy")
+	}
+	if orig.AccessList[0].StorageKeys[0] != (common.Hash{0x02}) {
+		t.Error("access list shared with copy")
+	}
+}
+
+// Tests that batched execution calls survive an encoding round trip and are
+// covered by the signing hash, while leaving single call transactions intact.
+func TestRip7560ExecutionCalls(t *testing.T) {
+	single := newTestRip7560Tx(nil, nil)
+
+	data := single.Rip7560TransactionData().copy().(*Rip7560AccountAbstractionTx)
+	data.ExecutionData = nil
+	data.ExecutionCalls = [][]byte{{0x02}, {0x03, 0x04}}
+	batched := NewTx(data)
+
+	enc, err := batched.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	dec := new(Transaction)
+	if err := dec.UnmarshalBinary(enc); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if dec.Hash() != batched.Hash() {
+		t.Errorf("hash mismatch: local %x, decoded %x", batched.Hash(), dec.Hash())
+	}
+	if frames := dec.Rip7560TransactionData().ExecutionFrames(); len(frames) != 2 || frames[1][1] != 0x04 {
+		t.Errorf("unexpected execution frames: %x", frames)
+	}
+	if frames := single.Rip7560TransactionData().ExecutionFrames(); len(frames) != 1 || frames[0][0] != 0x02 {
+		t.Errorf("unexpected single execution frame: %x", frames)
+	}
+	signer := NewRIP7560Signer(big.NewInt(1337))
+	if signer.Hash(single) == signer.Hash(batched) {
+		t.Error("execution calls not covered by the signing hash")
+	}
+	data.ExecutionCalls = nil
+	if signer.Hash(NewTx(data)) == signer.Hash(single) {
+		t.Error("execution data not covered by the signing hash")
+	}
+}
+
+func TestRip7560CostAndTip(t *testing.T) {
+	tx := newTestRip7560Tx(nil, nil)
+
+	gas := params.Rip7560TxGas + 100_000 + 50_000 + 20_000 + 10_000
+	if have, want := tx.Cost(), big.NewInt(int64(gas*10)); have.Cmp(want) != 0 {
+		t.Errorf("cost mismatch: have %v, want %v", have, want)
+	}
+	tip, err := tx.EffectiveGasTip(big.NewInt(9))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tip.Uint64() != 1 {
+		t.Errorf("tip mismatch: have %v, want 1", tip)
+	}
+	if _, err := tx.EffectiveGasTip(big.NewInt(11)); err != ErrGasFeeCapTooLow {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrGasFeeCapTooLow)
+	}
+}
+
+// Tests that the gas of sponsored transactions is charged to the paymaster
+// rather than the sender.
+func TestRip7560SenderPayerCost(t *testing.T) {
+	tx := newTestRip7560Tx(nil, nil)
+	if have, want := tx.SenderCost(), tx.Cost(); have.Cmp(want) != 0 {
+		t.Errorf("unsponsored sender cost mismatch: have %v, want %v", have, want)
+	}
+	if payer, cost := tx.PayerCost(); payer != nil || cost.Sign() != 0 {
+		t.Errorf("unsponsored payer mismatch: have %v %v, want nil 0", payer, cost)
+	}
+
+	paymaster := common.Address{0xbb}
+	tx = newTestRip7560Tx(&paymaster, nil)
+	if have := tx.SenderCost(); have.Sign() != 0 {
+		t.Errorf("sponsored sender cost mismatch: have %v, want 0", have)
+	}
+	payer, cost := tx.PayerCost()
+	if payer == nil || *payer != paymaster {
+		t.Errorf("sponsored payer mismatch: have %v, want %v", payer, paymaster)
+	}
+	if cost.Cmp(tx.Cost()) != 0 {
+		t.Errorf("sponsored payer cost mismatch: have %v, want %v", cost, tx.Cost())
+	}
+}
+
+// FuzzRip7560Decode checks that any RIP-7560 transaction accepted by the
+// decoder is in its canonical encoding, so that its hash, size and signing hash
+// survive gossiping it as raw bytes.
+func FuzzRip7560Decode(f *testing.F) {
+	calls := newTestRip7560Tx(nil, nil).Rip7560TransactionData().copy().(*Rip7560AccountAbstractionTx)
+	calls.NonceKey = big.NewInt(7)
+	calls.ExecutionCalls = [][]byte{{0x03}, {}}
+	for _, tx := range []*Transaction{
+		newTestRip7560Tx(nil, nil),
+		newTestRip7560Tx(&common.Address{0xbb}, &common.Address{0xcc}),
+		NewTx(calls),
+	} {
+		enc, err := tx.MarshalBinary()
+		if err != nil {
+			f.Fatalf("failed to encode seed: %v", err)
+		}
+		f.Add(enc)
+	}
+	f.Fuzz(func(t *testing.T, input []byte) {
+		tx := new(Transaction)
+		if err := tx.UnmarshalBinary(input); err != nil || tx.Type() != Rip7560Type {
+			return
+		}
+		enc, err := tx.MarshalBinary()
+		if err != nil {
+			t.Fatalf("failed to re-encode: %v", err)
+		}
+		if !bytes.Equal(enc, input) {
+			t.Fatalf("non-canonical encoding accepted\ninput:  %x\noutput: %x", input, enc)
+		}
+		dec := new(Transaction)
+		if err := dec.UnmarshalBinary(enc); err != nil {
+			t.Fatalf("failed to decode re-encoding: %v", err)
+		}
+		if tx.Hash() != dec.Hash() || tx.Hash() != crypto.Keccak256Hash(input) {
+			t.Fatalf("hash mismatch: have %x, re-decoded %x, want %x", tx.Hash(), dec.Hash(), crypto.Keccak256Hash(input))
+		}
+		if tx.Size() != uint64(len(input)) {
+			t.Fatalf("size mismatch: have %d, want %d", tx.Size(), len(input))
+		}
+		signer := NewRIP7560Signer(tx.ChainId())
+		if signer.Hash(tx) != signer.Hash(dec) {
+			t.Fatalf("signing hash mismatch: have %x, re-decoded %x", signer.Hash(tx), signer.Hash(dec))
+		}
+	})
+}
+
+// FuzzRip7560RoundTrip checks that locally created RIP-7560 transactions keep
+// their hash and signing hash through an encoding round trip.
+func FuzzRip7560RoundTrip(f *testing.F) {
+	f.Add(uint64(1), []byte{0x07}, []byte{0x01}, []byte{0x02}, []byte{}, uint64(100_000), true)
+	f.Add(uint64(0), []byte{}, []byte{}, []byte{}, []byte{0xbb}, uint64(0), false)
+	f.Fuzz(func(t *testing.T, nonce uint64, key, auth, data, paymasterData []byte, gas uint64, sponsored bool) {
+		if len(key) > 24 {
+			key = key[:24]
+		}
+		aatx := &Rip7560AccountAbstractionTx{
+			ChainID:            big.NewInt(1337),
+			Nonce:              nonce,
+			NonceKey:           new(big.Int).SetBytes(key),
+			GasTipCap:          new(big.Int).SetUint64(gas / 2),
+			GasFeeCap:          new(big.Int).SetUint64(gas),
+			Gas:                gas,
+			Sender:             &common.Address{0xaa},
+			AuthorizationData:  auth,
+			ExecutionData:      data,
+			ValidationGasLimit: gas,
+		}
+		if sponsored {
+			aatx.Paymaster, aatx.PaymasterData = &common.Address{0xbb}, paymasterData
+			aatx.PaymasterValidationGasLimit, aatx.PostOpGas = gas, gas
+		}
+		tx := NewTx(aatx)
+		enc, err := tx.MarshalBinary()
+		if err != nil {
+			t.Fatalf("failed to encode: %v", err)
+		}
+		dec := new(Transaction)
+		if err := dec.UnmarshalBinary(enc); err != nil {
+			t.Fatalf("failed to decode: %v", err)
+		}
+		if tx.Hash() != dec.Hash() {
+			t.Fatalf("hash mismatch: local %x, decoded %x", tx.Hash(), dec.Hash())
+		}
+		signer := NewRIP7560Signer(aatx.ChainID)
+		if signer.Hash(tx) != signer.Hash(dec) {
+			t.Fatalf("signing hash mismatch: local %x, decoded %x", signer.Hash(tx), signer.Hash(dec))
+		}
+		if have := dec.Rip7560TransactionData(); !bytes.Equal(have.AuthorizationData, auth) || !bytes.Equal(have.ExecutionData, data) || have.NonceKey.Cmp(aatx.NonceKey) != 0 {
+			t.Fatalf("field mismatch: have %+v, want %+v", have, aatx)
+		}
+	})
+}
@@ -33,8 +33,26 @@ type Config struct {
 	NoBaseFee               bool  // Forces the EIP-1559 baseFee to 0 (needed for 0 price calls)
 	EnablePreimageRecording bool  // Enables recording of SHA3/keccak preimages
 	ExtraEips               []int // Additional EIPS that are to be enabled
+
+	// Interpreter optionally replaces the built-in bytecode interpreter, e.g. to
+	// differentially test an alternative EVM implementation. It is invoked once
+	// per EVM instance.
+	Interpreter InterpreterFactory
 }
 
+// Interpreter executes contract code on behalf of the EVM. The EVM itself takes
+// care of value transfers, precompiles, snapshots and the call depth, so an
+// implementation only needs to run the code of the given contract, calling back
+// into the EVM for nested calls and creations.
+//
+// The same error semantics as for EVMInterpreter.Run apply.
+type Interpreter interface {
+	Run(contract *Contract, input []byte, readOnly bool) ([]byte, error)
+}
+
+// InterpreterFactory creates the interpreter used by an EVM instance.
+type InterpreterFactory func(evm *EVM) Interpreter
+
 // ScopeContext contains the things that are per-call, such as stack and memory,
 // but not transients like pc and gas
 type ScopeContext struct {
@@ -102,6 +120,8 @@ func NewEVMInterpreter(evm *EVM) *EVMInterpreter {
 	case evm.chainRules.IsVerkle:
 		// TODO replace with proper instruction set when fork is specified
 		table = &verkleInstructionSet
+	case evm.chainRules.IsPrague:
+		table = &pragueInstructionSet
 	case evm.chainRules.IsCancun:
 		table = &cancunInstructionSet
 	case evm.chainRules.IsShanghai:
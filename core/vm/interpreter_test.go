@@ -17,6 +17,7 @@
 package vm
 
 import (
+	"math/big"
 	"testing"
 	"time"
 
@@ -75,3 +76,60 @@ func TestLoopInterrupt(t *testing.T) {
 		}
 	}
 }
+
+// forwardingInterpreter is a toy alternative interpreter which ignores the code
+// and instead forwards every call to a fixed target, which returns its depth.
+type forwardingInterpreter struct {
+	evm    *EVM
+	target common.Address
+	depths []int
+}
+
+func (in *forwardingInterpreter) Run(contract *Contract, input []byte, readOnly bool) ([]byte, error) {
+	in.depths = append(in.depths, in.evm.Depth())
+	if contract.Address() == in.target {
+		return common.LeftPadBytes([]byte{byte(in.evm.Depth())}, 32), nil
+	}
+	ret, gas, err := in.evm.StaticCall(contract, in.target, input, contract.Gas)
+	contract.Gas = gas
+	return ret, err
+}
+
+// Tests that an interpreter configured through the VM config is used for all
+// frames, including nested ones, and that the EVM tracks the call depth for it.
+func TestCustomInterpreter(t *testing.T) {
+	var (
+		caller = common.Address{0x0a}
+		callee = common.Address{0x0b}
+		vmctx  = BlockContext{
+			CanTransfer: func(StateDB, common.Address, *uint256.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *uint256.Int) {},
+			BlockNumber: big.NewInt(1),
+		}
+	)
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetCode(caller, []byte{byte(STOP)})
+	statedb.SetCode(callee, []byte{byte(STOP)})
+
+	var custom *forwardingInterpreter
+	config := Config{
+		Interpreter: func(evm *EVM) Interpreter {
+			custom = &forwardingInterpreter{evm: evm, target: callee}
+			return custom
+		},
+	}
+	evm := NewEVM(vmctx, TxContext{}, statedb, params.AllEthashProtocolChanges, config)
+	ret, _, err := evm.Call(AccountRef(common.Address{}), caller, nil, 100000, new(uint256.Int))
+	if err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if new(uint256.Int).SetBytes(ret).Uint64() != 2 {
+		t.Fatalf("unexpected return data: %x", ret)
+	}
+	if len(custom.depths) != 2 || custom.depths[0] != 1 || custom.depths[1] != 2 {
+		t.Fatalf("unexpected frame depths: %v", custom.depths)
+	}
+	if in, ok := evm.CodeInterpreter().(*externalInterpreter); !ok || in.inner != custom {
+		t.Fatalf("plugged interpreter not executing the code: %T", evm.CodeInterpreter())
+	}
+}
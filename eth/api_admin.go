@@ -25,7 +25,9 @@ import (
 	"strings"
 
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool/rip7560pool"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/rpcusage"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
@@ -79,6 +81,17 @@ func (api *AdminAPI) ExportChain(file string, first *uint64, last *uint64) (bool
 	return true, nil
 }
 
+// RpcUsage returns the usage of the simulation heavy aa_ and debug_ methods by
+// the API keys of the callers. If reset is set, the usage is cleared after
+// being returned.
+func (api *AdminAPI) RpcUsage(reset *bool) map[string]rpcusage.Usage {
+	usage := rpcusage.DefaultTracker.Usage()
+	if reset != nil && *reset {
+		rpcusage.DefaultTracker.Reset()
+	}
+	return usage
+}
+
 func hasAllBlocks(chain *core.BlockChain, bs []*types.Block) bool {
 	for _, b := range bs {
 		if !chain.HasBlock(b.Hash(), b.NumberU64()) {
@@ -141,3 +154,29 @@ func (api *AdminAPI) ImportChain(file string) (bool, error) {
 	}
 	return true, nil
 }
+
+// ReloadAAPolicy re-reads the operator policy of the RIP-7560 transaction pool
+// (paymaster allow and ban lists, banned opcode mode and reputation thresholds)
+// without restarting the node.
+func (api *AdminAPI) ReloadAAPolicy() (bool, error) {
+	if err := api.eth.ReloadRip7560Policy(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// AAReputation returns the reputation of the paymasters and deployers known to
+// the RIP-7560 transaction pool.
+func (api *AdminAPI) AAReputation() ([]rip7560pool.ReputationEntry, error) {
+	return api.eth.Rip7560Reputation()
+}
+
+// SetAAReputation overrides the reputation of the given paymasters and deployers
+// in the RIP-7560 transaction pool. Entries with a status pin it, while entries
+// without one let it be derived from their counters again.
+func (api *AdminAPI) SetAAReputation(entries []rip7560pool.ReputationEntry) (bool, error) {
+	if err := api.eth.SetRip7560Reputation(entries); err != nil {
+		return false, err
+	}
+	return true, nil
+}
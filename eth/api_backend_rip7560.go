@@ -3,13 +3,71 @@ package eth
 import (
 	"context"
 	"errors"
+	"math/big"
+
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
 )
 
+// Rip7560Capabilities reports whether the node accepts RIP-7560 transactions
+// and bundles, which requires the RIP-7560 pool to be enabled.
+func (b *EthAPIBackend) Rip7560Capabilities() ethapi.Rip7560Capabilities {
+	enabled := b.eth.rip7560Pool != nil
+	return ethapi.Rip7560Capabilities{
+		Submission:       enabled,
+		BundleSubmission: enabled && b.rip7560AcceptPush,
+	}
+}
+
+// Rip7560ReplacementFee returns the minimal fees of a transaction replacing the
+// given RIP-7560 transaction in the pool.
+func (b *EthAPIBackend) Rip7560ReplacementFee(hash common.Hash) (*big.Int, *big.Int, error) {
+	if b.eth.rip7560Pool == nil {
+		return nil, nil, errRip7560PoolDisabled
+	}
+	return b.eth.rip7560Pool.ReplacementFee(hash)
+}
+
+// Rip7560ViolationStats returns the number of transactions submitted to the
+// RIP-7560 pool that broke each of the validation rules.
+func (b *EthAPIBackend) Rip7560ViolationStats() (map[string]uint64, error) {
+	if b.eth.rip7560Pool == nil {
+		return nil, errRip7560PoolDisabled
+	}
+	return b.eth.rip7560Pool.ViolationStats(), nil
+}
+
+// Rip7560ValidationBacklog returns the number of transactions admitted to the
+// RIP-7560 pool without simulating their validation.
+func (b *EthAPIBackend) Rip7560ValidationBacklog() (int, error) {
+	if b.eth.rip7560Pool == nil {
+		return 0, errRip7560PoolDisabled
+	}
+	return b.eth.rip7560Pool.ValidationBacklog(), nil
+}
+
+// SubscribeRip7560DroppedEvent subscribes to the removals of individually
+// submitted transactions from the RIP-7560 pool. Nothing is ever sent if the
+// pool is disabled.
+func (b *EthAPIBackend) SubscribeRip7560DroppedEvent(ch chan<- core.Rip7560TxDroppedEvent) event.Subscription {
+	if b.eth.rip7560Pool == nil {
+		return event.NewSubscription(func(quit <-chan struct{}) error {
+			<-quit
+			return nil
+		})
+	}
+	return b.eth.rip7560Pool.SubscribeDropped(ch)
+}
+
 func (b *EthAPIBackend) SubmitRip7560Bundle(bundle *types.ExternallyReceivedBundle) error {
+	if b.eth.rip7560Pool == nil {
+		return errRip7560PoolDisabled
+	}
 	if !b.rip7560AcceptPush {
-		return errors.New("illegal call to eth_sendRip7560TransactionsBundle: Config.Eth.Rip7560AcceptPush is not set")
+		return errors.New("illegal call to eth_sendRip7560TransactionsBundle: Config.Eth.Rip7560.AcceptPush is not set")
 	}
 	return b.eth.txPool.SubmitRip7560Bundle(bundle)
 }
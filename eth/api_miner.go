@@ -56,3 +56,12 @@ func (api *MinerAPI) SetGasLimit(gasLimit hexutil.Uint64) bool {
 	api.e.Miner().SetGasCeil(uint64(gasLimit))
 	return true
 }
+
+// SetRip7560GasShare sets the percentage of the block gas limit that is reserved
+// for RIP-7560 bundles during mining.
+func (api *MinerAPI) SetRip7560GasShare(share hexutil.Uint64) (bool, error) {
+	if err := api.e.Miner().SetRip7560GasShare(uint64(share)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
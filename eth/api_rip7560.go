@@ -0,0 +1,50 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"github.com/ethereum/go-ethereum/core/txpool/rip7560pool"
+)
+
+// Rip7560API provides an API to debug the RIP-7560 transaction pool, mirroring
+// the debug methods of the ERC-4337 bundlers.
+type Rip7560API struct {
+	eth *Ethereum
+}
+
+// NewRip7560API creates a new Rip7560API instance.
+func NewRip7560API(eth *Ethereum) *Rip7560API {
+	return &Rip7560API{eth}
+}
+
+// GetReputation returns the reputation of the paymasters and deployers known to
+// the RIP-7560 transaction pool, ordered by address.
+func (api *Rip7560API) GetReputation() ([]rip7560pool.ReputationEntry, error) {
+	return api.eth.Rip7560Reputation()
+}
+
+// SetReputation overrides the reputation of the given paymasters and deployers
+// in the RIP-7560 transaction pool, like admin_setAAReputation. Entries with a
+// status pin it, while entries without one let it be derived from their counters
+// again. As it modifies the pool, the namespace should only be exposed where the
+// admin one is.
+func (api *Rip7560API) SetReputation(entries []rip7560pool.ReputationEntry) (bool, error) {
+	if err := api.eth.SetRip7560Reputation(entries); err != nil {
+		return false, err
+	}
+	return true, nil
+}
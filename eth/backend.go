@@ -68,7 +68,10 @@ type Ethereum struct {
 	config *ethconfig.Config
 
 	// Handlers
-	txPool *txpool.TxPool
+	txPool      *txpool.TxPool
+	rip7560Pool *rip7560pool.Rip7560BundlerPool // RIP-7560 subpool (nil if disabled)
+
+	rip7560PolicyLoader func() (rip7560pool.Config, error) // Source of the reloaded RIP-7560 pool policy
 
 	blockchain         *core.BlockChain
 	handler            *handler
@@ -196,6 +199,10 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 			Preimages:           config.Preimages,
 			StateHistory:        config.StateHistory,
 			StateScheme:         scheme,
+
+			NoUncles:                 config.NoUncles,
+			StrictTxTypes:            config.StrictTxTypes,
+			Rip7560ValidationWorkers: config.Rip7560.ValidationWorkers,
 		}
 	)
 	if config.VMTrace != "" {
@@ -237,14 +244,28 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	}
 	legacyPool := legacypool.New(config.TxPool, eth.blockchain)
 
-	rip7560PoolConfig := rip7560pool.Config{
-		MaxBundleGas:  config.Rip7560MaxBundleGas,
-		MaxBundleSize: config.Rip7560MaxBundleSize,
-		PullUrls:      config.Rip7560PullUrls,
-	}
-	rip7560 := rip7560pool.New(rip7560PoolConfig, eth.blockchain, config.Miner.Etherbase)
+	subpools := []txpool.SubPool{legacyPool, blobPool}
+
+	var trustedTxPool trustedTxPool
+	if config.Rip7560Pool.Enabled {
+		rip7560PoolConfig := config.Rip7560Pool
+		rip7560PoolConfig.MaxBundleGas = config.Rip7560.MaxBundleGas
+		rip7560PoolConfig.MaxBundleSize = config.Rip7560.MaxBundleSize
+		rip7560PoolConfig.PullUrls = config.Rip7560.PullUrls
+		if rip7560PoolConfig.ReputationFile != "" {
+			rip7560PoolConfig.ReputationFile = stack.ResolvePath(rip7560PoolConfig.ReputationFile)
+		}
+		rip7560 := rip7560pool.New(rip7560PoolConfig, eth.blockchain, config.Miner.Etherbase)
+		subpools = append(subpools, rip7560)
+		eth.rip7560Pool = rip7560
 
-	eth.txPool, err = txpool.New(config.TxPool.PriceLimit, eth.blockchain, []txpool.SubPool{legacyPool, blobPool, rip7560})
+		if config.Rip7560.TrustedPeerLane {
+			trustedTxPool = rip7560
+		}
+	} else {
+		log.Info("RIP-7560 transaction pool disabled")
+	}
+	eth.txPool, err = txpool.New(config.TxPool.PriceLimit, eth.blockchain, subpools)
 	if err != nil {
 		return nil, err
 	}
@@ -260,6 +281,8 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		BloomCache:     uint64(cacheLimit),
 		EventMux:       eth.eventMux,
 		RequiredBlocks: config.RequiredBlocks,
+		TrustedTxPool:  trustedTxPool,
+		SnapPriority:   eth.rip7560SyncPriority(),
 	}); err != nil {
 		return nil, err
 	}
@@ -267,7 +290,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	eth.miner = miner.New(eth, config.Miner, eth.engine)
 	eth.miner.SetExtra(makeExtraData(config.Miner.ExtraData))
 
-	eth.APIBackend = &EthAPIBackend{config.Rip7560AcceptPush, stack.Config().ExtRPCEnabled(), stack.Config().AllowUnprotectedTxs, eth, nil}
+	eth.APIBackend = &EthAPIBackend{config.Rip7560.AcceptPush, stack.Config().ExtRPCEnabled(), stack.Config().AllowUnprotectedTxs, eth, nil}
 	if eth.APIBackend.allowUnprotectedTxs {
 		log.Info("Unprotected transactions allowed")
 	}
@@ -344,6 +367,9 @@ func (s *Ethereum) APIs() []rpc.API {
 		}, {
 			Namespace: "net",
 			Service:   s.netRPCService,
+		}, {
+			Namespace: "rip7560",
+			Service:   NewRip7560API(s),
 		},
 	}...)
 }
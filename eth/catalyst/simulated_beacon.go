@@ -31,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/crypto/kzg4844"
 	"github.com/ethereum/go-ethereum/eth"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/miner"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -292,6 +293,64 @@ func (c *SimulatedBeacon) Fork(parentHash common.Hash) error {
 	return c.eth.BlockChain().SetHead(parent.NumberU64())
 }
 
+// BuildBranch builds a block on top of the given parent containing exactly the
+// provided transactions and imports it without changing the canonical head.
+// Together with SetHead it allows tests to construct competing branches and to
+// exercise transaction pool behavior across reorgs.
+func (c *SimulatedBeacon) BuildBranch(parentHash common.Hash, txs types.Transactions) (common.Hash, error) {
+	parent := c.eth.BlockChain().GetHeaderByHash(parentHash)
+	if parent == nil {
+		return common.Hash{}, errors.New("parent not found")
+	}
+	c.feeRecipientLock.Lock()
+	feeRecipient := c.feeRecipient
+	c.feeRecipientLock.Unlock()
+
+	var random [32]byte
+	rand.Read(random[:])
+	args := &miner.BuildPayloadArgs{
+		Parent:       parentHash,
+		Timestamp:    parent.Time + 1,
+		FeeRecipient: feeRecipient,
+		Random:       random,
+	}
+	config := c.eth.BlockChain().Config()
+	if config.IsShanghai(parent.Number, args.Timestamp) {
+		args.Withdrawals = types.Withdrawals{}
+	}
+	if config.IsCancun(parent.Number, args.Timestamp) {
+		args.BeaconRoot = &common.Hash{}
+	}
+	block, err := c.eth.Miner().BuildBlock(args, txs)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := c.eth.BlockChain().InsertBlockWithoutSetHead(block); err != nil {
+		return common.Hash{}, err
+	}
+	return block.Hash(), nil
+}
+
+// SetHead makes the block with the given hash the canonical head, reorging the
+// chain if it is on a side branch. The transaction pools are reset accordingly,
+// reinjecting transactions from the dropped blocks.
+func (c *SimulatedBeacon) SetHead(hash common.Hash) error {
+	header := c.eth.BlockChain().GetHeaderByHash(hash)
+	if header == nil {
+		return errors.New("block not found")
+	}
+	finalizedHash := c.finalizedBlockHash(header.Number.Uint64())
+	if finalizedHash == nil {
+		return errors.New("finalized block not found")
+	}
+	c.setCurrentState(hash, *finalizedHash)
+	if _, err := c.engineAPI.ForkchoiceUpdatedV2(c.curForkchoiceState, nil); err != nil {
+		return err
+	}
+	c.lastBlockTime = header.Time
+	return nil
+}
+
 // AdjustTime creates a new block with an adjusted timestamp.
 func (c *SimulatedBeacon) AdjustTime(adjustment time.Duration) error {
 	if len(c.eth.TxPool().Pending(txpool.PendingFilter{})) != 0 {
@@ -18,9 +18,11 @@ package catalyst
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
@@ -59,3 +61,22 @@ func (a *api) AddWithdrawal(ctx context.Context, withdrawal *types.Withdrawal) e
 func (a *api) SetFeeRecipient(ctx context.Context, feeRecipient common.Address) {
 	a.sim.setFeeRecipient(feeRecipient)
 }
+
+// BuildBranch builds a block containing exactly the given transactions on top
+// of the parent block and imports it without making it canonical.
+func (a *api) BuildBranch(ctx context.Context, parent common.Hash, txs []hexutil.Bytes) (common.Hash, error) {
+	decoded := make(types.Transactions, len(txs))
+	for i, enc := range txs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(enc); err != nil {
+			return common.Hash{}, fmt.Errorf("invalid transaction %d: %v", i, err)
+		}
+		decoded[i] = tx
+	}
+	return a.sim.BuildBranch(parent, decoded)
+}
+
+// SetHead makes the given block the canonical head, reorging if necessary.
+func (a *api) SetHead(ctx context.Context, hash common.Hash) error {
+	return a.sim.SetHead(hash)
+}
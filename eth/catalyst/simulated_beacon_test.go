@@ -35,7 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 )
 
-func startSimulatedBeaconEthService(t *testing.T, genesis *core.Genesis) (*node.Node, *eth.Ethereum, *SimulatedBeacon) {
+func startSimulatedBeaconEthService(t *testing.T, genesis *core.Genesis, period uint64) (*node.Node, *eth.Ethereum, *SimulatedBeacon) {
 	t.Helper()
 
 	n, err := node.New(&node.Config{
@@ -55,7 +55,7 @@ func startSimulatedBeaconEthService(t *testing.T, genesis *core.Genesis) (*node.
 		t.Fatal("can't create eth service:", err)
 	}
 
-	simBeacon, err := NewSimulatedBeacon(1, ethservice)
+	simBeacon, err := NewSimulatedBeacon(period, ethservice)
 	if err != nil {
 		t.Fatal("can't create simulated beacon:", err)
 	}
@@ -87,7 +87,7 @@ func TestSimulatedBeaconSendWithdrawals(t *testing.T) {
 	// short period (1 second) for testing purposes
 	var gasLimit uint64 = 10_000_000
 	genesis := core.DeveloperGenesisBlock(gasLimit, &testAddr)
-	node, ethService, mock := startSimulatedBeaconEthService(t, genesis)
+	node, ethService, mock := startSimulatedBeaconEthService(t, genesis, 1)
 	_ = mock
 	defer node.Close()
 
@@ -140,3 +140,53 @@ func TestSimulatedBeaconSendWithdrawals(t *testing.T) {
 		}
 	}
 }
+
+// Tests that a transaction included in a branch which is later reorged out is
+// returned to the transaction pool.
+func TestSimulatedBeaconReorgReinjects(t *testing.T) {
+	var (
+		testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		testAddr   = crypto.PubkeyToAddress(testKey.PublicKey)
+	)
+	genesis := core.DeveloperGenesisBlock(10_000_000, &testAddr)
+	node, ethService, mock := startSimulatedBeaconEthService(t, genesis, 0)
+	defer node.Close()
+
+	signer := types.LatestSigner(ethService.BlockChain().Config())
+	tx, err := types.SignTx(types.NewTransaction(0, common.Address{}, big.NewInt(1000), params.TxGas, big.NewInt(2*params.InitialBaseFee), nil), signer, testKey)
+	if err != nil {
+		t.Fatalf("error signing transaction: %v", err)
+	}
+	root := ethService.BlockChain().CurrentBlock().Hash()
+
+	// Build a branch including the transaction and make it canonical.
+	a1, err := mock.BuildBranch(root, types.Transactions{tx})
+	if err != nil {
+		t.Fatalf("failed to build branch A: %v", err)
+	}
+	if err := mock.SetHead(a1); err != nil {
+		t.Fatalf("failed to set head to branch A: %v", err)
+	}
+	if head := ethService.BlockChain().CurrentBlock().Hash(); head != a1 {
+		t.Fatalf("head mismatch: have %x, want %x", head, a1)
+	}
+	// Build a longer competing branch without the transaction and reorg to it.
+	b1, err := mock.BuildBranch(root, types.Transactions{})
+	if err != nil {
+		t.Fatalf("failed to build branch B: %v", err)
+	}
+	b2, err := mock.BuildBranch(b1, types.Transactions{})
+	if err != nil {
+		t.Fatalf("failed to extend branch B: %v", err)
+	}
+	if err := mock.SetHead(b2); err != nil {
+		t.Fatalf("failed to set head to branch B: %v", err)
+	}
+	if head := ethService.BlockChain().CurrentBlock().Hash(); head != b2 {
+		t.Fatalf("head mismatch: have %x, want %x", head, b2)
+	}
+	ethService.TxPool().Sync()
+	if !ethService.TxPool().Has(tx.Hash()) {
+		t.Fatal("reorged out transaction not reinjected into the pool")
+	}
+}
@@ -29,8 +29,10 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
+	"github.com/ethereum/go-ethereum/core/txpool/rip7560pool"
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/eth/gasprice"
+	"github.com/ethereum/go-ethereum/eth/rip7560bundler"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/miner"
 	"github.com/ethereum/go-ethereum/params"
@@ -63,6 +65,8 @@ var Defaults = Config{
 	Miner:              miner.DefaultConfig,
 	TxPool:             legacypool.DefaultConfig,
 	BlobPool:           blobpool.DefaultConfig,
+	Rip7560Pool:        rip7560pool.DefaultConfig,
+	Rip7560Bundler:     rip7560bundler.DefaultConfig,
 	RPCGasCap:          50000000,
 	RPCEVMTimeout:      5 * time.Second,
 	GPO:                FullNodeGPO,
@@ -132,8 +136,12 @@ type Config struct {
 	Miner miner.Config
 
 	// Transaction pool options
-	TxPool   legacypool.Config
-	BlobPool blobpool.Config
+	TxPool      legacypool.Config
+	BlobPool    blobpool.Config
+	Rip7560Pool rip7560pool.Config
+
+	// In-process RIP-7560 bundler options
+	Rip7560Bundler rip7560bundler.Config
 
 	// Gas Price Oracle options
 	GPO gasprice.Config
@@ -141,6 +149,13 @@ type Config struct {
 	// Enables tracking of SHA3 preimages in the VM
 	EnablePreimageRecording bool
 
+	// Enables post-merge only block processing, rejecting blocks with uncles
+	NoUncles bool
+
+	// Enables strict block processing, rejecting blocks with transaction types
+	// not active at the block
+	StrictTxTypes bool `toml:",omitempty"`
+
 	// Enables VM tracing
 	VMTrace           string
 	VMTraceJsonConfig string
@@ -164,17 +179,32 @@ type Config struct {
 	// OverrideVerkle (TODO: remove after the fork)
 	OverrideVerkle *uint64 `toml:",omitempty"`
 
-	// Rip7560MaxBundleGas is the maximum amount of gas that can be used by an RIP-7560 bundle
-	Rip7560MaxBundleGas *uint64 `toml:",omitempty"`
+	// RIP-7560 bundle and block processing options
+	Rip7560 Rip7560Config
+}
+
+// Rip7560Config are the RIP-7560 options of the node not specific to its
+// transaction pool or to its in-process bundler.
+type Rip7560Config struct {
+	// MaxBundleGas is the maximum amount of gas that can be used by an RIP-7560 bundle
+	MaxBundleGas *uint64 `toml:",omitempty"`
+
+	// MaxBundleSize is the maximum number of transactions an RIP-7560 bundle can contain
+	MaxBundleSize *uint64 `toml:",omitempty"`
+
+	// PullUrls provides a list of bundlers the node will ask for new bundles for each block
+	PullUrls []string
 
-	// Rip7560MaxBundleSize is the maximum number of transactions an RIP-7560 bundle can contain
-	Rip7560MaxBundleSize *uint64 `toml:",omitempty"`
+	// AcceptPush when set to "true" the node will accept incoming 'eth_sendRip7560TransactionsBundle'
+	AcceptPush bool `toml:",omitempty"`
 
-	// Rip7560PullUrls provides a list of bundlers the node will ask for new bundles for each block
-	Rip7560PullUrls []string
+	// TrustedPeerLane when set, RIP-7560 transactions relayed by trusted peers
+	// are admitted without simulating their validation phase right away
+	TrustedPeerLane bool `toml:",omitempty"`
 
-	// Rip7560AcceptPush when set to "true" the node will accept incoming 'eth_sendRip7560TransactionsBundle'
-	Rip7560AcceptPush bool `toml:",omitempty"`
+	// Number of RIP-7560 validation phases run concurrently when processing
+	// blocks, zero or one validating the transactions one after the other
+	ValidationWorkers int `toml:",omitempty"`
 }
 
 // CreateConsensusEngine creates a consensus engine for the given chain config.
@@ -9,8 +9,10 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
+	"github.com/ethereum/go-ethereum/core/txpool/rip7560pool"
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/eth/gasprice"
+	"github.com/ethereum/go-ethereum/eth/rip7560bundler"
 	"github.com/ethereum/go-ethereum/miner"
 )
 
@@ -48,8 +50,12 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		Miner                   miner.Config
 		TxPool                  legacypool.Config
 		BlobPool                blobpool.Config
+		Rip7560Pool             rip7560pool.Config
+		Rip7560Bundler          rip7560bundler.Config
 		GPO                     gasprice.Config
 		EnablePreimageRecording bool
+		NoUncles                bool
+		StrictTxTypes           bool `toml:",omitempty"`
 		VMTrace                 string
 		VMTraceJsonConfig       string
 		DocRoot                 string `toml:"-"`
@@ -58,10 +64,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		RPCTxFeeCap             float64
 		OverrideCancun          *uint64 `toml:",omitempty"`
 		OverrideVerkle          *uint64 `toml:",omitempty"`
-		Rip7560MaxBundleGas     *uint64 `toml:",omitempty"`
-		Rip7560MaxBundleSize    *uint64 `toml:",omitempty"`
-		Rip7560PullUrls         []string
-		Rip7560AcceptPush       bool `toml:",omitempty"`
+		Rip7560                 Rip7560Config
 	}
 	var enc Config
 	enc.Genesis = c.Genesis
@@ -95,8 +98,12 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.Miner = c.Miner
 	enc.TxPool = c.TxPool
 	enc.BlobPool = c.BlobPool
+	enc.Rip7560Pool = c.Rip7560Pool
+	enc.Rip7560Bundler = c.Rip7560Bundler
 	enc.GPO = c.GPO
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
+	enc.NoUncles = c.NoUncles
+	enc.StrictTxTypes = c.StrictTxTypes
 	enc.VMTrace = c.VMTrace
 	enc.VMTraceJsonConfig = c.VMTraceJsonConfig
 	enc.DocRoot = c.DocRoot
@@ -105,10 +112,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.RPCTxFeeCap = c.RPCTxFeeCap
 	enc.OverrideCancun = c.OverrideCancun
 	enc.OverrideVerkle = c.OverrideVerkle
-	enc.Rip7560MaxBundleGas = c.Rip7560MaxBundleGas
-	enc.Rip7560MaxBundleSize = c.Rip7560MaxBundleSize
-	enc.Rip7560PullUrls = c.Rip7560PullUrls
-	enc.Rip7560AcceptPush = c.Rip7560AcceptPush
+	enc.Rip7560 = c.Rip7560
 	return &enc, nil
 }
 
@@ -146,8 +150,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		Miner                   *miner.Config
 		TxPool                  *legacypool.Config
 		BlobPool                *blobpool.Config
+		Rip7560Pool             *rip7560pool.Config
+		Rip7560Bundler          *rip7560bundler.Config
 		GPO                     *gasprice.Config
 		EnablePreimageRecording *bool
+		NoUncles                *bool
+		StrictTxTypes           *bool `toml:",omitempty"`
 		VMTrace                 *string
 		VMTraceJsonConfig       *string
 		DocRoot                 *string `toml:"-"`
@@ -156,10 +164,7 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		RPCTxFeeCap             *float64
 		OverrideCancun          *uint64 `toml:",omitempty"`
 		OverrideVerkle          *uint64 `toml:",omitempty"`
-		Rip7560MaxBundleGas     *uint64 `toml:",omitempty"`
-		Rip7560MaxBundleSize    *uint64 `toml:",omitempty"`
-		Rip7560PullUrls         []string
-		Rip7560AcceptPush       *bool `toml:",omitempty"`
+		Rip7560                 *Rip7560Config
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -258,12 +263,24 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.BlobPool != nil {
 		c.BlobPool = *dec.BlobPool
 	}
+	if dec.Rip7560Pool != nil {
+		c.Rip7560Pool = *dec.Rip7560Pool
+	}
+	if dec.Rip7560Bundler != nil {
+		c.Rip7560Bundler = *dec.Rip7560Bundler
+	}
 	if dec.GPO != nil {
 		c.GPO = *dec.GPO
 	}
 	if dec.EnablePreimageRecording != nil {
 		c.EnablePreimageRecording = *dec.EnablePreimageRecording
 	}
+	if dec.NoUncles != nil {
+		c.NoUncles = *dec.NoUncles
+	}
+	if dec.StrictTxTypes != nil {
+		c.StrictTxTypes = *dec.StrictTxTypes
+	}
 	if dec.VMTrace != nil {
 		c.VMTrace = *dec.VMTrace
 	}
@@ -288,17 +305,8 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.OverrideVerkle != nil {
 		c.OverrideVerkle = dec.OverrideVerkle
 	}
-	if dec.Rip7560MaxBundleGas != nil {
-		c.Rip7560MaxBundleGas = dec.Rip7560MaxBundleGas
-	}
-	if dec.Rip7560MaxBundleSize != nil {
-		c.Rip7560MaxBundleSize = dec.Rip7560MaxBundleSize
-	}
-	if dec.Rip7560PullUrls != nil {
-		c.Rip7560PullUrls = dec.Rip7560PullUrls
-	}
-	if dec.Rip7560AcceptPush != nil {
-		c.Rip7560AcceptPush = *dec.Rip7560AcceptPush
+	if dec.Rip7560 != nil {
+		c.Rip7560 = *dec.Rip7560
 	}
 	return nil
 }
@@ -0,0 +1,174 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasestimator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// rip7560Limit is one of the gas limits of a RIP-7560 transaction, estimated
+// independently of the others.
+type rip7560Limit struct {
+	limit func(tx *types.Rip7560AccountAbstractionTx) *uint64 // Field of the limit in the transaction
+	used  func(gas *types.Rip7560FrameGas) *hexutil.Uint64    // Gas used by the frames covered by the limit
+}
+
+var rip7560Limits = []rip7560Limit{
+	{
+		limit: func(tx *types.Rip7560AccountAbstractionTx) *uint64 { return &tx.ValidationGasLimit },
+		used:  func(gas *types.Rip7560FrameGas) *hexutil.Uint64 { return &gas.ValidationGas },
+	},
+	{
+		limit: func(tx *types.Rip7560AccountAbstractionTx) *uint64 { return &tx.PaymasterValidationGasLimit },
+		used:  func(gas *types.Rip7560FrameGas) *hexutil.Uint64 { return &gas.PaymasterValidationGas },
+	},
+	{
+		limit: func(tx *types.Rip7560AccountAbstractionTx) *uint64 { return &tx.Gas },
+		used:  func(gas *types.Rip7560FrameGas) *hexutil.Uint64 { return &gas.CallGas },
+	},
+	{
+		limit: func(tx *types.Rip7560AccountAbstractionTx) *uint64 { return &tx.PostOpGas },
+		used:  func(gas *types.Rip7560FrameGas) *hexutil.Uint64 { return &gas.PostOpGas },
+	},
+}
+
+// EstimateRip7560 returns the lowest gas limits of the frames of a RIP-7560
+// transaction that allow both of its phases to run successfully with the
+// provided context options. Every limit is binary searched independently, with
+// the other ones set to the limits of the given transaction, which are the upper
+// bounds of the search. The fees of the transaction are expected to be zero, so
+// that the estimate doesn't depend on the balance of the gas payer. The estimate
+// also reports the penalty the chain charges for the execution and postOp gas
+// the estimated limits leave unused.
+//
+// It returns an error, along with the revert reason of the failed execution
+// frame if any, if the transaction fails at its given limits.
+func EstimateRip7560(ctx context.Context, tx *types.Transaction, opts *Options) (*types.Rip7560FrameGas, []byte, error) {
+	if tx.Type() != types.Rip7560Type {
+		return nil, nil, errors.New("not a RIP-7560 transaction")
+	}
+	aatx := tx.Rip7560TransactionData()
+
+	// Run the transaction unconstrained first, if this fails there is no limit
+	// to search for
+	gas, revert, err := executeRip7560(ctx, aatx, opts)
+	if err != nil {
+		return nil, revert, err
+	}
+	estimate := new(types.Rip7560FrameGas)
+	for _, l := range rip7560Limits {
+		hi := *l.limit(aatx)
+		if hi == 0 {
+			continue // frame not present in the transaction
+		}
+		// The unconstrained gas usage lower-bounds the limit, while the usage
+		// increased by the call stipend and the 63/64 rule is a good first guess
+		var (
+			used       = uint64(*l.used(gas))
+			lo         uint64
+			optimistic = (used + params.CallStipend) * 64 / 63
+		)
+		if used > 0 {
+			lo = used - 1
+		}
+		// run reports whether the transaction succeeds with the given limit,
+		// only failing if the estimation was interrupted
+		run := func(limit uint64) (bool, error) {
+			cpy := *aatx
+			*l.limit(&cpy) = limit
+			if _, _, err := executeRip7560(ctx, &cpy, opts); err != nil {
+				return false, ctx.Err()
+			}
+			return true, nil
+		}
+		if optimistic < hi {
+			ok, err := run(optimistic)
+			if err != nil {
+				return nil, nil, err
+			}
+			if ok {
+				hi = optimistic
+			} else {
+				lo = optimistic
+			}
+		}
+		for lo+1 < hi {
+			if opts.ErrorRatio > 0 && float64(hi-lo)/float64(hi) < opts.ErrorRatio {
+				break
+			}
+			mid := (hi + lo) / 2
+			if lo > 0 && mid > lo*2 {
+				mid = lo * 2
+			}
+			ok, err := run(mid)
+			if err != nil {
+				return nil, nil, err
+			}
+			if ok {
+				hi = mid
+			} else {
+				lo = mid
+			}
+		}
+		*l.used(estimate) = hexutil.Uint64(hi)
+	}
+	// Report the penalty for the gas left unused by the estimated limits, so
+	// that callers can weigh it against the risk of running out of gas
+	var (
+		percent = opts.Config.Rip7560GasPenaltyPercent()
+		penalty = (uint64(estimate.CallGas-gas.CallGas) + uint64(estimate.PostOpGas-gas.PostOpGas)) * percent / 100
+		used    = gas.ValidationGas + gas.PaymasterValidationGas + gas.CallGas + gas.PostOpGas
+	)
+	estimate.GasPenaltyPercent, estimate.GasPenalty = (*hexutil.Uint64)(&percent), (*hexutil.Uint64)(&penalty)
+	estimate.UsedGas = &used
+	return estimate, nil, nil
+}
+
+// executeRip7560 runs both phases of a RIP-7560 transaction, returning the gas
+// used by its frames. A failed execution frame is reported as an error, along
+// with its revert reason.
+func executeRip7560(ctx context.Context, aatx *types.Rip7560AccountAbstractionTx, opts *Options) (*types.Rip7560FrameGas, []byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	var execution *core.ExecutionResult
+	tracer := &tracing.Hooks{
+		OnAAFrameEnd: func(frame string, output []byte, gasUsed uint64, err error) {
+			if err != nil && strings.HasPrefix(frame, "execution") {
+				execution = &core.ExecutionResult{Err: err, ReturnData: output}
+			}
+		},
+	}
+	gas, _, err := core.SimulateRip7560Transaction(opts.Config, opts.Chain, opts.Header, opts.State.Copy(), types.NewTx(aatx), vm.Config{Tracer: tracer, NoBaseFee: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	if execution != nil {
+		return nil, execution.Revert(), fmt.Errorf("execution frame failed: %w", execution.Err)
+	}
+	return gas, nil, nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasestimator
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the limits of the frames are searched independently, finding the
+// lowest ones the frames succeed with even if above their gas usage.
+func TestEstimateRip7560(t *testing.T) {
+	var (
+		sender = common.Address{0xaa}
+		header = &types.Header{Number: new(big.Int), Difficulty: new(big.Int), GasLimit: 30_000_000, BaseFee: new(big.Int)}
+	)
+	// The account reverts unless more than 65536 gas is left, and accepts the
+	// transaction by returning the validation data otherwise
+	code := []byte{byte(vm.GAS), byte(vm.PUSH3), 0x01, 0x00, 0x00, byte(vm.LT), byte(vm.PUSH1), 14, byte(vm.JUMPI),
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.REVERT), byte(vm.JUMPDEST), byte(vm.PUSH32)}
+	code = append(code, core.PackValidationData(core.AcceptAccountMethodSig, 0, 0)...)
+	code = append(code, byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN))
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetCode(sender, code)
+	opts := &Options{Config: params.AllDevChainProtocolChanges, Header: header, State: statedb}
+
+	aatx := &types.Rip7560AccountAbstractionTx{
+		ChainID:            params.AllDevChainProtocolChanges.ChainID,
+		NonceKey:           new(big.Int),
+		GasTipCap:          new(big.Int),
+		GasFeeCap:          new(big.Int),
+		Gas:                1_000_000,
+		Sender:             &sender,
+		ExecutionData:      []byte{0x01},
+		ValidationGasLimit: 1_000_000,
+	}
+	gas, _, err := EstimateRip7560(context.Background(), types.NewTx(aatx), opts)
+	if err != nil {
+		t.Fatalf("failed to estimate gas: %v", err)
+	}
+	if gas.ValidationGas <= 65536 || gas.CallGas <= 65536 {
+		t.Fatalf("estimates below the required gas: %+v", gas)
+	}
+	if gas.PaymasterValidationGas != 0 || gas.PostOpGas != 0 {
+		t.Fatalf("estimates for missing frames: %+v", gas)
+	}
+	// The penalty is charged for the call gas left unused by the estimate
+	used, _, err := executeRip7560(context.Background(), aatx, opts)
+	if err != nil {
+		t.Fatalf("failed to execute transaction: %v", err)
+	}
+	if gas.GasPenaltyPercent == nil || uint64(*gas.GasPenaltyPercent) != params.Rip7560GasPenaltyPercent {
+		t.Errorf("penalty percent mismatch: have %v, want %d", gas.GasPenaltyPercent, params.Rip7560GasPenaltyPercent)
+	}
+	if want := uint64(gas.CallGas-used.CallGas) * params.Rip7560GasPenaltyPercent / 100; gas.GasPenalty == nil || uint64(*gas.GasPenalty) != want {
+		t.Errorf("penalty mismatch: have %v, want %d", gas.GasPenalty, want)
+	}
+	if want := used.ValidationGas + used.CallGas; gas.UsedGas == nil || *gas.UsedGas != want {
+		t.Errorf("used gas mismatch: have %v, want %d", gas.UsedGas, want)
+	}
+	// The estimates are the lowest limits the transaction succeeds with
+	estimated := *aatx
+	estimated.ValidationGasLimit, estimated.Gas = uint64(gas.ValidationGas), uint64(gas.CallGas)
+	if _, _, err := executeRip7560(context.Background(), &estimated, opts); err != nil {
+		t.Fatalf("transaction failed with the estimates: %v", err)
+	}
+	lower := estimated
+	lower.ValidationGasLimit--
+	if _, _, err := executeRip7560(context.Background(), &lower, opts); err == nil {
+		t.Error("transaction succeeded below the validation gas estimate")
+	}
+	lower = estimated
+	lower.Gas--
+	if _, _, err := executeRip7560(context.Background(), &lower, opts); err == nil {
+		t.Error("transaction succeeded below the call gas estimate")
+	}
+}
@@ -61,7 +61,7 @@ func TestFeeHistory(t *testing.T) {
 		backend := newTestBackend(t, big.NewInt(16), big.NewInt(28), c.pending)
 		oracle := NewOracle(backend, config)
 
-		first, reward, baseFee, ratio, blobBaseFee, blobRatio, err := oracle.FeeHistory(context.Background(), c.count, c.last, c.percent)
+		first, reward, baseFee, ratio, blobBaseFee, blobRatio, rip7560Ratio, err := oracle.FeeHistory(context.Background(), c.count, c.last, c.percent)
 		backend.teardown()
 		expReward := c.expCount
 		if len(c.percent) == 0 {
@@ -87,6 +87,14 @@ func TestFeeHistory(t *testing.T) {
 		if len(blobRatio) != c.expCount {
 			t.Fatalf("Test case %d: blobGasUsedRatio array length mismatch, want %d, got %d", i, c.expCount, len(blobRatio))
 		}
+		if len(rip7560Ratio) != expReward {
+			t.Fatalf("Test case %d: rip7560GasUsedRatio array length mismatch, want %d, got %d", i, expReward, len(rip7560Ratio))
+		}
+		for _, r := range rip7560Ratio {
+			if r != 0 {
+				t.Fatalf("Test case %d: unexpected rip7560GasUsedRatio %v without RIP-7560 transactions", i, r)
+			}
+		}
 		if len(blobBaseFee) != len(baseFee) {
 			t.Fatalf("Test case %d: blobBaseFee array length mismatch, want %d, got %d", i, len(baseFee), len(blobBaseFee))
 		}
@@ -81,6 +81,14 @@ type txPool interface {
 	SubscribeTransactions(ch chan<- core.NewTxsEvent, reorgs bool) event.Subscription
 }
 
+// trustedTxPool defines the methods needed to accept transactions relayed by
+// trusted peers with reduced validation.
+type trustedTxPool interface {
+	// AddTrusted adds the given transactions to the pool, skipping the expensive
+	// parts of their validation.
+	AddTrusted(txs []*types.Transaction) []error
+}
+
 // handlerConfig is the collection of initialization parameters to create a full
 // node network handler.
 type handlerConfig struct {
@@ -93,6 +101,8 @@ type handlerConfig struct {
 	BloomCache     uint64                 // Megabytes to alloc for snap sync bloom
 	EventMux       *event.TypeMux         // Legacy event mux, deprecate for `feed`
 	RequiredBlocks map[uint64]common.Hash // Hard coded map of required block hashes for sync challenges
+	TrustedTxPool  trustedTxPool          // Pool accepting RIP-7560 transactions from trusted peers (nil if disabled)
+	SnapPriority   []common.Address       // Accounts whose state is retrieved first during snap sync
 }
 
 type handler struct {
@@ -108,6 +118,8 @@ type handler struct {
 	chain    *core.BlockChain
 	maxPeers int
 
+	trustedTxPool trustedTxPool
+
 	downloader *downloader.Downloader
 	txFetcher  *fetcher.TxFetcher
 	peers      *peerSet
@@ -143,6 +155,7 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		chain:          config.Chain,
 		peers:          newPeerSet(),
 		requiredBlocks: config.RequiredBlocks,
+		trustedTxPool:  config.TrustedTxPool,
 		quitSync:       make(chan struct{}),
 		handlerDoneCh:  make(chan struct{}),
 		handlerStartCh: make(chan struct{}),
@@ -181,6 +194,7 @@ func newHandler(config *handlerConfig) (*handler, error) {
 	}
 	// Construct the downloader (long sync)
 	h.downloader = downloader.New(config.Database, h.eventMux, h.chain, h.removePeer, h.enableSyncedFeatures)
+	h.downloader.SnapSyncer.Prioritize(config.SnapPriority)
 
 	fetchTx := func(peer string, hashes []common.Hash) error {
 		p := h.peers.peer(peer)
@@ -281,7 +295,7 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 	peer.Log().Debug("Ethereum peer connected", "name", peer.Name())
 
 	// Register the peer locally
-	if err := h.peers.registerPeer(peer, snap); err != nil {
+	if err := h.peers.registerPeer(peer, snap, h.rip7560Peer(peer.ForkID())); err != nil {
 		peer.Log().Error("Ethereum peer registration failed", "err", err)
 		return err
 	}
@@ -388,6 +402,21 @@ func (h *handler) removePeer(id string) {
 	}
 }
 
+// rip7560Peer reports whether a peer advertising the given fork ID passed the
+// activation of RIP-7560, and accepts the AA transactions gossiped to it. The
+// fork ID is only exchanged in the handshake, so peers still syncing up to the
+// fork don't get AA transactions until they reconnect.
+func (h *handler) rip7560Peer(id forkid.ID) bool {
+	config := h.chain.Config()
+	switch {
+	case config.RIP7560Block != nil:
+		return forkid.Passed(config, h.chain.Genesis(), id, config.RIP7560Block.Uint64(), 0)
+	case config.RIP7560Time != nil:
+		return forkid.Passed(config, h.chain.Genesis(), id, math.MaxUint64, *config.RIP7560Time)
+	}
+	return false
+}
+
 // unregisterPeer removes a peer from the downloader, fetchers and main peer set.
 func (h *handler) unregisterPeer(id string) {
 	// Create a custom logger to avoid printing the entire id
@@ -456,19 +485,22 @@ func (h *handler) Stop() {
 }
 
 // BroadcastTransactions will propagate a batch of transactions
-// - To a square root of all peers for non-blob transactions
+// - To a square root of all peers for non-blob, non-RIP-7560 transactions
 // - And, separately, as announcements to all peers which are not known to
 // already have the given transaction.
+//
+// RIP-7560 transactions are only announced to the peers which passed the fork.
 func (h *handler) BroadcastTransactions(txs types.Transactions) {
 	var (
 		blobTxs  int // Number of blob transactions to announce only
+		aaTxs    int // Number of RIP-7560 transactions to announce only
 		largeTxs int // Number of large transactions to announce only
 
 		directCount int // Number of transactions sent directly to peers (duplicates included)
 		annCount    int // Number of transactions announced across all peers (duplicates included)
 
-		txset = make(map[*ethPeer][]common.Hash) // Set peer->hash to transfer directly
-		annos = make(map[*ethPeer][]common.Hash) // Set peer->hash to announce
+		txset = make(map[*ethPeer][]*types.Transaction) // Set peer->tx to transfer directly
+		annos = make(map[*ethPeer][]*types.Transaction) // Set peer->tx to announce
 	)
 	// Broadcast transactions to a batch of peers not knowing about it
 	direct := big.NewInt(int64(math.Sqrt(float64(h.peers.len())))) // Approximate number of peers to broadcast to
@@ -487,6 +519,8 @@ func (h *handler) BroadcastTransactions(txs types.Transactions) {
 		switch {
 		case tx.Type() == types.BlobTxType:
 			blobTxs++
+		case tx.Type() == types.Rip7560Type:
+			aaTxs++
 		case tx.Size() > txMaxBroadcastSize:
 			largeTxs++
 		default:
@@ -500,6 +534,9 @@ func (h *handler) BroadcastTransactions(txs types.Transactions) {
 		// enode ID together with the transaction sender and broadcast if
 		// `sha(self, peer, sender) mod peers < sqrt(peers)`.
 		for _, peer := range h.peers.peersWithoutTransaction(tx.Hash()) {
+			if tx.Type() == types.Rip7560Type && !peer.rip7560 {
+				continue
+			}
 			var broadcast bool
 			if maybeDirect {
 				hasher.Reset()
@@ -515,21 +552,21 @@ func (h *handler) BroadcastTransactions(txs types.Transactions) {
 				}
 			}
 			if broadcast {
-				txset[peer] = append(txset[peer], tx.Hash())
+				txset[peer] = append(txset[peer], tx)
 			} else {
-				annos[peer] = append(annos[peer], tx.Hash())
+				annos[peer] = append(annos[peer], tx)
 			}
 		}
 	}
-	for peer, hashes := range txset {
-		directCount += len(hashes)
-		peer.AsyncSendTransactions(hashes)
+	for peer, txs := range txset {
+		directCount += len(txs)
+		peer.AsyncSendTransactions(txs)
 	}
-	for peer, hashes := range annos {
-		annCount += len(hashes)
-		peer.AsyncSendPooledTransactionHashes(hashes)
+	for peer, txs := range annos {
+		annCount += len(txs)
+		peer.AsyncSendPooledTransactionHashes(txs)
 	}
-	log.Debug("Distributed transactions", "plaintxs", len(txs)-blobTxs-largeTxs, "blobtxs", blobTxs, "largetxs", largeTxs,
+	log.Debug("Distributed transactions", "plaintxs", len(txs)-blobTxs-aaTxs-largeTxs, "blobtxs", blobTxs, "aatxs", aaTxs, "largetxs", largeTxs,
 		"bcastpeers", len(txset), "bcastcount", directCount, "annpeers", len(annos), "anncount", annCount)
 }
 
@@ -65,13 +65,42 @@ func (h *ethHandler) Handle(peer *eth.Peer, packet eth.Packet) error {
 			if tx.Type() == types.BlobTxType {
 				return errors.New("disallowed broadcast blob transaction")
 			}
+			if tx.Type() == types.Rip7560Type {
+				return errors.New("disallowed broadcast RIP-7560 transaction")
+			}
 		}
-		return h.txFetcher.Enqueue(peer.ID(), *packet, false)
+		return h.txFetcher.Enqueue(peer.ID(), h.addTrustedTxs(peer, *packet), false)
 
 	case *eth.PooledTransactionsResponse:
-		return h.txFetcher.Enqueue(peer.ID(), *packet, true)
+		return h.txFetcher.Enqueue(peer.ID(), h.addTrustedTxs(peer, *packet), true)
 
 	default:
 		return fmt.Errorf("unexpected eth packet type: %T", packet)
 	}
 }
+
+// addTrustedTxs adds the RIP-7560 transactions relayed by a trusted peer to the
+// pool right away, bypassing the fetcher and the simulation of their validation
+// phase. The remaining transactions are returned for regular processing.
+func (h *ethHandler) addTrustedTxs(peer *eth.Peer, txs []*types.Transaction) []*types.Transaction {
+	if h.trustedTxPool == nil || !peer.Peer.Trusted() {
+		return txs
+	}
+	var trusted, others []*types.Transaction
+	for _, tx := range txs {
+		if tx.Type() == types.Rip7560Type {
+			trusted = append(trusted, tx)
+		} else {
+			others = append(others, tx)
+		}
+	}
+	if len(trusted) == 0 {
+		return txs
+	}
+	for i, err := range h.trustedTxPool.AddTrusted(trusted) {
+		if err != nil {
+			peer.Log().Trace("Failed to add trusted RIP-7560 transaction", "hash", trusted[i].Hash(), "err", err)
+		}
+	}
+	return others
+}
@@ -438,3 +438,76 @@ func testTransactionPropagation(t *testing.T, protocol uint) {
 		}
 	}
 }
+
+// Tests that RIP-7560 transactions are only announced, and only to the peers
+// which passed the RIP-7560 fork when connecting.
+func TestRip7560TransactionPropagation(t *testing.T) {
+	t.Parallel()
+
+	source := newTestHandler()
+	defer source.close()
+
+	var (
+		sender = common.Address{0xaa}
+		aaTx   = types.NewTx(&types.Rip7560AccountAbstractionTx{ChainID: big.NewInt(1), NonceKey: new(big.Int), Gas: 100000, Sender: &sender})
+		tx, _  = types.SignTx(types.NewTransaction(0, common.Address{}, big.NewInt(0), 100000, big.NewInt(0), nil), types.HomesteadSigner{}, testKey)
+
+		anns   = make([]chan []common.Hash, 2)
+		bcasts = make([]chan []*types.Transaction, 2)
+	)
+	for i, rip7560 := range []bool{true, false} {
+		sourcePipe, sinkPipe := p2p.MsgPipe()
+		defer sourcePipe.Close()
+		defer sinkPipe.Close()
+
+		sourcePeer := eth.NewPeer(eth.ETH68, p2p.NewPeerPipe(enode.ID{byte(i + 1)}, "", nil, sourcePipe), sourcePipe, source.txpool)
+		sinkPeer := eth.NewPeer(eth.ETH68, p2p.NewPeerPipe(enode.ID{0}, "", nil, sinkPipe), sinkPipe, newTestTxPool())
+		defer sourcePeer.Close()
+		defer sinkPeer.Close()
+
+		if err := source.handler.peers.registerPeer(sourcePeer, nil, rip7560); err != nil {
+			t.Fatalf("peer %d: failed to register: %v", i, err)
+		}
+		backend := new(testEthHandler)
+
+		anns[i] = make(chan []common.Hash, 16)
+		annSub := backend.txAnnounces.Subscribe(anns[i])
+		defer annSub.Unsubscribe()
+
+		bcasts[i] = make(chan []*types.Transaction, 16)
+		bcastSub := backend.txBroadcasts.Subscribe(bcasts[i])
+		defer bcastSub.Unsubscribe()
+
+		go eth.Handle(backend, sinkPeer)
+	}
+	source.txpool.Add([]*types.Transaction{tx, aaTx}, false, false)
+
+	// Both peers get the regular transaction, only the first one the AA one
+	for i := range bcasts {
+		select {
+		case txs := <-bcasts[i]:
+			if len(txs) != 1 || txs[0].Hash() != tx.Hash() {
+				t.Errorf("peer %d: unexpected broadcast of %d transactions", i, len(txs))
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("peer %d: transaction broadcast timed out", i)
+		}
+	}
+	select {
+	case hashes := <-anns[0]:
+		if len(hashes) != 1 || hashes[0] != aaTx.Hash() {
+			t.Errorf("announcement mismatch: have %x, want [%x]", hashes, aaTx.Hash())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("RIP-7560 transaction announcement timed out")
+	}
+	select {
+	case hashes := <-anns[1]:
+		t.Errorf("RIP-7560 transaction announced to peer before the fork: %x", hashes)
+	case <-time.After(100 * time.Millisecond):
+	}
+	// RIP-7560 transactions must not be broadcast directly to us either
+	if err := (*ethHandler)(source.handler).Handle(nil, &eth.TransactionsPacket{aaTx}); err == nil {
+		t.Error("direct broadcast of RIP-7560 transaction accepted")
+	}
+}
@@ -31,6 +31,7 @@ type ethPeerInfo struct {
 type ethPeer struct {
 	*eth.Peer
 	snapExt *snapPeer // Satellite `snap` connection
+	rip7560 bool      // Whether the peer passed the RIP-7560 fork at handshake
 }
 
 // info gathers and returns some `eth` protocol metadata known about a peer.
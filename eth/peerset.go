@@ -142,8 +142,9 @@ func (ps *peerSet) waitSnapExtension(peer *eth.Peer) (*snap.Peer, error) {
 }
 
 // registerPeer injects a new `eth` peer into the working set, or returns an error
-// if the peer is already known.
-func (ps *peerSet) registerPeer(peer *eth.Peer, ext *snap.Peer) error {
+// if the peer is already known. RIP-7560 transactions are only propagated to the
+// peers registered with rip7560 set.
+func (ps *peerSet) registerPeer(peer *eth.Peer, ext *snap.Peer, rip7560 bool) error {
 	// Start tracking the new peer
 	ps.lock.Lock()
 	defer ps.lock.Unlock()
@@ -156,7 +157,8 @@ func (ps *peerSet) registerPeer(peer *eth.Peer, ext *snap.Peer) error {
 		return errPeerAlreadyRegistered
 	}
 	eth := &ethPeer{
-		Peer: peer,
+		Peer:    peer,
+		rip7560: rip7560,
 	}
 	if ext != nil {
 		eth.snapExt = &snapPeer{ext}
@@ -20,6 +20,7 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/forkid"
 	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
@@ -36,6 +37,20 @@ func (e enrEntry) ENRKey() string {
 	return "eth"
 }
 
+// aaEntry is the ENR entry which advertises the RIP-7560 rules of the chain, so
+// that nodes and tooling can find peers accepting the same AA transactions.
+type aaEntry struct {
+	RulesHash [4]byte // RIP-7560 rules checksum, see core.Rip7560RulesHash
+
+	// Ignore additional fields (for forward compatibility).
+	Rest []rlp.RawValue `rlp:"tail"`
+}
+
+// ENRKey implements enr.Entry.
+func (e aaEntry) ENRKey() string {
+	return "aa"
+}
+
 // StartENRUpdater starts the `eth` ENR updater loop, which listens for chain
 // head events and updates the requested node record whenever a fork is passed.
 func StartENRUpdater(chain *core.BlockChain, ln *enode.LocalNode) {
@@ -64,3 +79,13 @@ func currentENREntry(chain *core.BlockChain) *enrEntry {
 		ForkID: forkid.NewID(chain.Config(), chain.Genesis(), head.Number.Uint64(), head.Time),
 	}
 }
+
+// currentENREntries constructs the ENR entries of the `eth` protocol, including
+// the `aa` one if RIP-7560 is scheduled on the chain.
+func currentENREntries(chain *core.BlockChain) []enr.Entry {
+	entries := []enr.Entry{currentENREntry(chain)}
+	if config := chain.Config(); config.RIP7560Block != nil || config.RIP7560Time != nil {
+		entries = append(entries, &aaEntry{RulesHash: core.Rip7560RulesHash(config)})
+	}
+	return entries
+}
@@ -366,8 +366,8 @@ func handleNewPooledTransactionHashes(backend Backend, msg Decoder, peer *Peer)
 		return fmt.Errorf("%w: message %v: invalid len of fields: %v %v %v", errDecode, msg, len(ann.Hashes), len(ann.Types), len(ann.Sizes))
 	}
 	// Schedule all the unknown hashes for retrieval
-	for _, hash := range ann.Hashes {
-		peer.markTransaction(hash)
+	for i, hash := range ann.Hashes {
+		peer.markTransaction(hash, ann.Types[i])
 	}
 	return backend.Handle(peer, ann)
 }
@@ -378,16 +378,17 @@ func handleGetPooledTransactions(backend Backend, msg Decoder, peer *Peer) error
 	if err := msg.Decode(&query); err != nil {
 		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 	}
-	hashes, txs := answerGetPooledTransactions(backend, query.GetPooledTransactionsRequest)
-	return peer.ReplyPooledTransactionsRLP(query.RequestId, hashes, txs)
+	hashes, txTypes, txs := answerGetPooledTransactions(backend, query.GetPooledTransactionsRequest)
+	return peer.ReplyPooledTransactionsRLP(query.RequestId, hashes, txTypes, txs)
 }
 
-func answerGetPooledTransactions(backend Backend, query GetPooledTransactionsRequest) ([]common.Hash, []rlp.RawValue) {
+func answerGetPooledTransactions(backend Backend, query GetPooledTransactionsRequest) ([]common.Hash, []byte, []rlp.RawValue) {
 	// Gather transactions until the fetch or network limits is reached
 	var (
-		bytes  int
-		hashes []common.Hash
-		txs    []rlp.RawValue
+		bytes   int
+		hashes  []common.Hash
+		txTypes []byte
+		txs     []rlp.RawValue
 	)
 	for _, hash := range query {
 		if bytes >= softResponseLimit {
@@ -403,11 +404,12 @@ func answerGetPooledTransactions(backend Backend, query GetPooledTransactionsReq
 			log.Error("Failed to encode transaction", "err", err)
 		} else {
 			hashes = append(hashes, hash)
+			txTypes = append(txTypes, tx.Type())
 			txs = append(txs, encoded)
 			bytes += len(encoded)
 		}
 	}
-	return hashes, txs
+	return hashes, txTypes, txs
 }
 
 func handleTransactions(backend Backend, msg Decoder, peer *Peer) error {
@@ -425,7 +427,7 @@ func handleTransactions(backend Backend, msg Decoder, peer *Peer) error {
 		if tx == nil {
 			return fmt.Errorf("%w: transaction %d is nil", errDecode, i)
 		}
-		peer.markTransaction(tx.Hash())
+		peer.markTransaction(tx.Hash(), tx.Type())
 	}
 	return backend.Handle(peer, &txs)
 }
@@ -445,7 +447,7 @@ func handlePooledTransactions(backend Backend, msg Decoder, peer *Peer) error {
 		if tx == nil {
 			return fmt.Errorf("%w: transaction %d is nil", errDecode, i)
 		}
-		peer.markTransaction(tx.Hash())
+		peer.markTransaction(tx.Hash(), tx.Type())
 	}
 	requestTracker.Fulfil(peer.id, peer.version, PooledTransactionsMsg, txs.RequestId)
 
@@ -23,6 +23,7 @@ import (
 
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/forkid"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -33,6 +34,10 @@ const (
 	// before starting to randomly evict them.
 	maxKnownTxs = 32768
 
+	// maxKnownAATxs is the maximum RIP-7560 transaction hashes to keep in their
+	// own known list, so bursts of these large transactions can't evict others.
+	maxKnownAATxs = 8192
+
 	// maxQueuedTxs is the maximum number of transactions to queue up before dropping
 	// older broadcasts.
 	maxQueuedTxs = 4096
@@ -50,11 +55,13 @@ type Peer struct {
 	rw        p2p.MsgReadWriter // Input/output streams for snap
 	version   uint              // Protocol version negotiated
 
-	head common.Hash // Latest advertised head block hash
-	td   *big.Int    // Latest advertised head block total difficulty
+	head   common.Hash // Latest advertised head block hash
+	td     *big.Int    // Latest advertised head block total difficulty
+	forkID forkid.ID   // Fork ID advertised in the handshake
 
 	txpool      TxPool             // Transaction pool used by the broadcasters for liveness checks
 	knownTxs    *knownCache        // Set of transaction hashes known to be known by this peer
+	knownAATxs  *knownCache        // Set of RIP-7560 transaction hashes known to be known by this peer
 	txBroadcast chan []common.Hash // Channel used to queue transaction propagation requests
 	txAnnounce  chan []common.Hash // Channel used to queue transaction announcement requests
 
@@ -75,6 +82,7 @@ func NewPeer(version uint, p *p2p.Peer, rw p2p.MsgReadWriter, txpool TxPool) *Pe
 		rw:          rw,
 		version:     version,
 		knownTxs:    newKnownCache(maxKnownTxs),
+		knownAATxs:  newKnownCache(maxKnownAATxs),
 		txBroadcast: make(chan []common.Hash),
 		txAnnounce:  make(chan []common.Hash),
 		reqDispatch: make(chan *request),
@@ -126,16 +134,37 @@ func (p *Peer) SetHead(hash common.Hash, td *big.Int) {
 	p.td.Set(td)
 }
 
+// ForkID retrieves the fork ID the peer advertised in the handshake.
+func (p *Peer) ForkID() forkid.ID {
+	return p.forkID
+}
+
 // KnownTransaction returns whether peer is known to already have a transaction.
 func (p *Peer) KnownTransaction(hash common.Hash) bool {
-	return p.knownTxs.Contains(hash)
+	return p.knownTxs.Contains(hash) || p.knownAATxs.Contains(hash)
+}
+
+// knownCache returns the set tracking the known transactions of the given type.
+func (p *Peer) knownCache(typ byte) *knownCache {
+	if typ == types.Rip7560Type {
+		return p.knownAATxs
+	}
+	return p.knownTxs
 }
 
 // markTransaction marks a transaction as known for the peer, ensuring that it
 // will never be propagated to this particular peer.
-func (p *Peer) markTransaction(hash common.Hash) {
+func (p *Peer) markTransaction(hash common.Hash, typ byte) {
 	// If we reached the memory allowance, drop a previously known transaction hash
-	p.knownTxs.Add(hash)
+	p.knownCache(typ).Add(hash)
+}
+
+// markTransactions marks transactions as known for the peer, each in the set
+// tracking its type.
+func (p *Peer) markTransactions(txs []*types.Transaction) {
+	for _, tx := range txs {
+		p.markTransaction(tx.Hash(), tx.Type())
+	}
 }
 
 // SendTransactions sends transactions to the peer and includes the hashes
@@ -150,7 +179,7 @@ func (p *Peer) markTransaction(hash common.Hash) {
 func (p *Peer) SendTransactions(txs types.Transactions) error {
 	// Mark all the transactions as known, but ensure we don't overflow our limits
 	for _, tx := range txs {
-		p.knownTxs.Add(tx.Hash())
+		p.markTransaction(tx.Hash(), tx.Type())
 	}
 	return p2p.Send(p.rw, TransactionsMsg, txs)
 }
@@ -158,11 +187,15 @@ func (p *Peer) SendTransactions(txs types.Transactions) error {
 // AsyncSendTransactions queues a list of transactions (by hash) to eventually
 // propagate to a remote peer. The number of pending sends are capped (new ones
 // will force old sends to be dropped)
-func (p *Peer) AsyncSendTransactions(hashes []common.Hash) {
+func (p *Peer) AsyncSendTransactions(txs []*types.Transaction) {
+	hashes := make([]common.Hash, len(txs))
+	for i, tx := range txs {
+		hashes[i] = tx.Hash()
+	}
 	select {
 	case p.txBroadcast <- hashes:
 		// Mark all the transactions as known, but ensure we don't overflow our limits
-		p.knownTxs.Add(hashes...)
+		p.markTransactions(txs)
 	case <-p.term:
 		p.Log().Debug("Dropping transaction propagation", "count", len(hashes))
 	}
@@ -177,27 +210,35 @@ func (p *Peer) AsyncSendTransactions(hashes []common.Hash) {
 // not be managed directly.
 func (p *Peer) sendPooledTransactionHashes(hashes []common.Hash, types []byte, sizes []uint32) error {
 	// Mark all the transactions as known, but ensure we don't overflow our limits
-	p.knownTxs.Add(hashes...)
+	for i, hash := range hashes {
+		p.markTransaction(hash, types[i])
+	}
 	return p2p.Send(p.rw, NewPooledTransactionHashesMsg, NewPooledTransactionHashesPacket{Types: types, Sizes: sizes, Hashes: hashes})
 }
 
 // AsyncSendPooledTransactionHashes queues a list of transactions hashes to eventually
 // announce to a remote peer.  The number of pending sends are capped (new ones
 // will force old sends to be dropped)
-func (p *Peer) AsyncSendPooledTransactionHashes(hashes []common.Hash) {
+func (p *Peer) AsyncSendPooledTransactionHashes(txs []*types.Transaction) {
+	hashes := make([]common.Hash, len(txs))
+	for i, tx := range txs {
+		hashes[i] = tx.Hash()
+	}
 	select {
 	case p.txAnnounce <- hashes:
 		// Mark all the transactions as known, but ensure we don't overflow our limits
-		p.knownTxs.Add(hashes...)
+		p.markTransactions(txs)
 	case <-p.term:
 		p.Log().Debug("Dropping transaction announcement", "count", len(hashes))
 	}
 }
 
 // ReplyPooledTransactionsRLP is the response to RequestTxs.
-func (p *Peer) ReplyPooledTransactionsRLP(id uint64, hashes []common.Hash, txs []rlp.RawValue) error {
+func (p *Peer) ReplyPooledTransactionsRLP(id uint64, hashes []common.Hash, txTypes []byte, txs []rlp.RawValue) error {
 	// Mark all the transactions as known, but ensure we don't overflow our limits
-	p.knownTxs.Add(hashes...)
+	for i, hash := range hashes {
+		p.markTransaction(hash, txTypes[i])
+	}
 
 	// Not packed into PooledTransactionsResponse to avoid RLP decoding
 	return p2p.Send(p.rw, PooledTransactionsMsg, &PooledTransactionsRLPPacket{
@@ -21,9 +21,11 @@ package eth
 
 import (
 	"crypto/rand"
+	"encoding/binary"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 )
@@ -88,3 +90,26 @@ func TestPeerSet(t *testing.T) {
 		t.Fatalf("bad size")
 	}
 }
+
+// Tests that RIP-7560 transactions are tracked apart from other transactions,
+// so floods of them can't evict the hashes of the others.
+func TestPeerKnownAATransactions(t *testing.T) {
+	peer := &Peer{
+		knownTxs:   newKnownCache(maxKnownTxs),
+		knownAATxs: newKnownCache(maxKnownAATxs),
+	}
+	legacy := common.Hash{0x01}
+	peer.markTransaction(legacy, types.LegacyTxType)
+
+	for i := 0; i < 2*maxKnownTxs; i++ {
+		var hash common.Hash
+		binary.BigEndian.PutUint64(hash[24:], uint64(i+2))
+		peer.markTransaction(hash, types.Rip7560Type)
+	}
+	if !peer.KnownTransaction(legacy) {
+		t.Fatal("legacy transaction evicted by RIP-7560 transactions")
+	}
+	if have := peer.knownAATxs.Cardinality(); have != maxKnownAATxs {
+		t.Fatalf("RIP-7560 known set size mismatch: have %d, want %d", have, maxKnownAATxs)
+	}
+}
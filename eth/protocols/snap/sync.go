@@ -18,12 +18,14 @@ package snap
 
 import (
 	"bytes"
+	"cmp"
 	"encoding/json"
 	"errors"
 	"fmt"
 	gomath "math"
 	"math/big"
 	"math/rand"
+	"slices"
 	"sort"
 	"sync"
 	"sync/atomic"
@@ -446,11 +448,12 @@ type Syncer struct {
 	db     ethdb.KeyValueStore // Database to store the trie nodes into (and dedup)
 	scheme string              // Node scheme used in node database
 
-	root    common.Hash    // Current state trie root being synced
-	tasks   []*accountTask // Current account task set being synced
-	snapped bool           // Flag to signal that snap phase is done
-	healer  *healTask      // Current state healing task being executed
-	update  chan struct{}  // Notification channel for possible sync progression
+	root     common.Hash    // Current state trie root being synced
+	tasks    []*accountTask // Current account task set being synced
+	priority []common.Hash  // Hashes of the accounts to retrieve before the rest of the state
+	snapped  bool           // Flag to signal that snap phase is done
+	healer   *healTask      // Current state healing task being executed
+	update   chan struct{}  // Notification channel for possible sync progression
 
 	peers    map[string]SyncPeer // Currently active peers to download from
 	peerJoin *event.Feed         // Event feed to react to peers joining
@@ -613,6 +616,7 @@ func (s *Syncer) Sync(root common.Hash, cancel chan struct{}) error {
 		trieTasks: make(map[string]common.Hash),
 		codeTasks: make(map[common.Hash]struct{}),
 	}
+	s.healer.scheduler.Prioritize(s.priority)
 	s.statelessPeers = make(map[string]struct{})
 	s.lock.Unlock()
 
@@ -1025,7 +1029,7 @@ func (s *Syncer) assignAccountTasks(success chan *accountResponse, fail chan *ac
 	sort.Sort(sort.Reverse(idlers))
 
 	// Iterate over all the tasks and try to find a pending one
-	for _, task := range s.tasks {
+	for _, task := range s.prioritizedTasks() {
 		// Skip any tasks already filling
 		if task.req != nil || task.res != nil {
 			continue
@@ -1098,6 +1102,65 @@ func (s *Syncer) assignAccountTasks(success chan *accountResponse, fail chan *ac
 	}
 }
 
+// Prioritize sets the accounts whose state is retrieved before the rest, so
+// that they become usable as soon as possible. It is applied from the next
+// sync cycle on.
+func (s *Syncer) Prioritize(accounts []common.Address) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.priority = make([]common.Hash, len(accounts))
+	for i, account := range accounts {
+		s.priority[i] = crypto.Keccak256Hash(account.Bytes())
+	}
+	slices.SortFunc(s.priority, func(a, b common.Hash) int { return a.Cmp(b) })
+}
+
+// isPriority returns whether the account with the given hash is prioritized.
+func (s *Syncer) isPriority(account common.Hash) bool {
+	_, found := slices.BinarySearchFunc(s.priority, account, func(a, b common.Hash) int { return a.Cmp(b) })
+	return found
+}
+
+// prioritizedTasks returns the account tasks, the ones covering prioritized
+// accounts first. The relative order of the tasks is otherwise retained.
+func (s *Syncer) prioritizedTasks() []*accountTask {
+	if len(s.priority) == 0 {
+		return s.tasks
+	}
+	tasks := slices.Clone(s.tasks)
+	slices.SortStableFunc(tasks, func(a, b *accountTask) int {
+		return cmp.Compare(s.covered(b), s.covered(a))
+	})
+	return tasks
+}
+
+// covered returns the number of prioritized accounts within the range of an
+// account task.
+func (s *Syncer) covered(task *accountTask) int {
+	var n int
+	for _, account := range s.priority {
+		if account.Cmp(task.Next) >= 0 && account.Cmp(task.Last) <= 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// prioritizedAccounts returns the accounts of a storage task set, prioritized
+// accounts first. The order of the rest is random, as with ranging the set.
+func prioritizedAccounts[V any](s *Syncer, accounts map[common.Hash]V) []common.Hash {
+	var first, rest []common.Hash
+	for account := range accounts {
+		if s.isPriority(account) {
+			first = append(first, account)
+		} else {
+			rest = append(rest, account)
+		}
+	}
+	return append(first, rest...)
+}
+
 // assignBytecodeTasks attempts to match idle peers to pending code retrievals.
 func (s *Syncer) assignBytecodeTasks(success chan *bytecodeResponse, fail chan *bytecodeRequest, cancel chan struct{}) {
 	s.lock.Lock()
@@ -1225,7 +1288,7 @@ func (s *Syncer) assignStorageTasks(success chan *storageResponse, fail chan *st
 	sort.Sort(sort.Reverse(idlers))
 
 	// Iterate over all the tasks and try to find a pending one
-	for _, task := range s.tasks {
+	for _, task := range s.prioritizedTasks() {
 		// Skip any tasks not in the storage retrieval phase
 		if task.res == nil {
 			continue
@@ -1276,8 +1339,8 @@ func (s *Syncer) assignStorageTasks(success chan *storageResponse, fail chan *st
 			roots    = make([]common.Hash, 0, storageSets)
 			subtask  *storageTask
 		)
-		for account, subtasks := range storageTasks {
-			for _, st := range subtasks {
+		for _, account := range prioritizedAccounts(s, storageTasks) {
+			for _, st := range storageTasks[account] {
 				// Skip any subtasks already filling
 				if st.req != nil {
 					continue
@@ -1294,7 +1357,8 @@ func (s *Syncer) assignStorageTasks(success chan *storageResponse, fail chan *st
 		}
 		if subtask == nil {
 			// No large contract required retrieval, but small ones available
-			for account, root := range task.stateTasks {
+			for _, account := range prioritizedAccounts(s, task.stateTasks) {
+				root := task.stateTasks[account]
 				delete(task.stateTasks, account)
 
 				accounts = append(accounts, account)
@@ -1975,3 +1975,34 @@ func newDbConfig(scheme string) *triedb.Config {
 	}
 	return &triedb.Config{PathDB: pathdb.Defaults}
 }
+
+// Tests that the account tasks and storage retrievals covering prioritized
+// accounts are scheduled first.
+func TestSyncPriority(t *testing.T) {
+	var (
+		account  = common.Address{0x75, 0x60}
+		hash     = crypto.Keccak256Hash(account.Bytes())
+		syncer   = NewSyncer(rawdb.NewMemoryDatabase(), rawdb.HashScheme)
+		boundary = common.BigToHash(new(big.Int).Rsh(common.MaxHash.Big(), 2))
+	)
+	syncer.tasks = []*accountTask{
+		{Next: common.Hash{}, Last: boundary},
+		{Next: incHash(boundary), Last: common.MaxHash},
+	}
+	if tasks := syncer.prioritizedTasks(); tasks[0] != syncer.tasks[0] {
+		t.Fatal("tasks reordered without priority")
+	}
+	syncer.Prioritize([]common.Address{account})
+
+	want := syncer.tasks[0]
+	if hash.Cmp(boundary) > 0 {
+		want = syncer.tasks[1]
+	}
+	if tasks := syncer.prioritizedTasks(); tasks[0] != want {
+		t.Fatalf("prioritized task not first: have %x-%x", tasks[0].Next, tasks[0].Last)
+	}
+	storage := map[common.Hash]common.Hash{{0x01}: {}, {0x02}: {}, hash: {}, {0x03}: {}}
+	if accounts := prioritizedAccounts(syncer, storage); accounts[0] != hash || len(accounts) != len(storage) {
+		t.Fatalf("prioritized storage not first: %v", accounts)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/core/txpool/rip7560pool"
+)
+
+// errRip7560PoolDisabled is returned when submitting RIP-7560 transactions or
+// reloading the pool policy on a node running without the RIP-7560 pool.
+var errRip7560PoolDisabled = errors.New("RIP-7560 transaction pool disabled")
+
+// Rip7560Pool returns the RIP-7560 transaction pool, nil if disabled.
+func (s *Ethereum) Rip7560Pool() *rip7560pool.Rip7560BundlerPool {
+	return s.rip7560Pool
+}
+
+// SetRip7560PolicyLoader sets the function producing the up to date RIP-7560
+// pool configuration whenever the pool policy is reloaded, typically by
+// re-reading the configuration file of the node.
+func (s *Ethereum) SetRip7560PolicyLoader(loader func() (rip7560pool.Config, error)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.rip7560PolicyLoader = loader
+}
+
+// ReloadRip7560Policy re-applies the operator policy of the RIP-7560 pool.
+// Without a loader, the policy is kept but the paymaster lists are re-read from
+// their files.
+func (s *Ethereum) ReloadRip7560Policy() error {
+	if s.rip7560Pool == nil {
+		return errRip7560PoolDisabled
+	}
+	s.lock.RLock()
+	loader := s.rip7560PolicyLoader
+	s.lock.RUnlock()
+
+	config := s.rip7560Pool.Policy()
+	if loader != nil {
+		var err error
+		if config, err = loader(); err != nil {
+			return err
+		}
+	}
+	return s.rip7560Pool.SetPolicy(config)
+}
+
+// Rip7560Reputation returns the reputation of the paymasters and deployers known
+// to the RIP-7560 pool.
+func (s *Ethereum) Rip7560Reputation() ([]rip7560pool.ReputationEntry, error) {
+	if s.rip7560Pool == nil {
+		return nil, errRip7560PoolDisabled
+	}
+	return s.rip7560Pool.Reputation(), nil
+}
+
+// SetRip7560Reputation overrides the reputation of the given paymasters and
+// deployers in the RIP-7560 pool.
+func (s *Ethereum) SetRip7560Reputation(entries []rip7560pool.ReputationEntry) error {
+	if s.rip7560Pool == nil {
+		return errRip7560PoolDisabled
+	}
+	return s.rip7560Pool.SetReputation(entries)
+}
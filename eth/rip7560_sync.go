@@ -0,0 +1,77 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// rip7560SyncPriorityBlocks is the number of recent local blocks searched for
+// active paymasters to prioritize during snap sync.
+const rip7560SyncPriorityBlocks = 128
+
+// rip7560SyncPriority returns the accounts whose state is needed to validate
+// RIP-7560 transactions, to be retrieved first during snap sync: the system
+// contracts, the paymasters allowed by the pool and the paymasters active in
+// the recent local blocks. Nothing is prioritized on chains without RIP-7560.
+//
+// Prioritizing only shortens the retrieval of these accounts, it doesn't admit
+// transactions any earlier: the state of the pivot block is not readable before
+// the heal completes, as its root node is committed last, so the pool keeps
+// admitting against the last synced head until then.
+func (s *Ethereum) rip7560SyncPriority() []common.Address {
+	config := s.blockchain.Config()
+	if config.RIP7560Block == nil && config.RIP7560Time == nil {
+		return nil
+	}
+	var (
+		seen     = make(map[common.Address]struct{})
+		priority []common.Address
+	)
+	add := func(addr common.Address) {
+		if _, ok := seen[addr]; !ok {
+			seen[addr] = struct{}{}
+			priority = append(priority, addr)
+		}
+	}
+	for _, addr := range []common.Address{core.AA_ENTRY_POINT, core.AA_SENDER_CREATOR, core.AA_NONCE_MANAGER, core.AA_DEPOSIT_MANAGER} {
+		add(addr)
+	}
+	if s.rip7560Pool != nil {
+		for _, paymaster := range s.rip7560Pool.AllowedPaymasters() {
+			add(paymaster)
+		}
+	}
+	head := s.blockchain.CurrentBlock()
+	for i := uint64(0); i < rip7560SyncPriorityBlocks && i <= head.Number.Uint64(); i++ {
+		block := s.blockchain.GetBlockByNumber(head.Number.Uint64() - i)
+		if block == nil {
+			break
+		}
+		for _, tx := range block.Transactions() {
+			if tx.Type() != types.Rip7560Type {
+				continue
+			}
+			if paymaster := tx.Rip7560TransactionData().Paymaster; paymaster != nil {
+				add(*paymaster)
+			}
+		}
+	}
+	return priority
+}
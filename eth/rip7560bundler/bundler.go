@@ -0,0 +1,260 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package rip7560bundler implements an in-process bundler, assembling the
+// RIP-7560 transactions of the local pool into bundles for the miner or for a
+// remote block builder.
+package rip7560bundler
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// bundlerID identifies the bundles of the in-process bundler.
+const bundlerID = "in-process"
+
+// builderTimeout is the maximum time allowed to submit a bundle to the remote
+// block builder.
+const builderTimeout = 2 * time.Second
+
+// Config are the configuration parameters of the in-process bundler.
+type Config struct {
+	Enabled  bool          // Whether the node runs the in-process bundler
+	Builder  string        // URL of the block builder receiving the bundles (empty to hand them to the local miner)
+	Recommit time.Duration // Time interval to rebuild the bundle of the next block
+}
+
+// DefaultConfig contains the default configurations for the in-process bundler.
+var DefaultConfig = Config{
+	Recommit: 2 * time.Second,
+}
+
+// BlockChain defines the minimal set of methods needed to simulate bundles on
+// top of the chain head.
+type BlockChain interface {
+	core.ChainContext
+
+	// Config retrieves the chain's fork configuration.
+	Config() *params.ChainConfig
+
+	// CurrentBlock returns the current head of the chain.
+	CurrentBlock() *types.Header
+
+	// StateAt returns a state database for a given root hash.
+	StateAt(root common.Hash) (*state.StateDB, error)
+
+	// SubscribeChainHeadEvent subscribes to new blocks being added to the chain.
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+}
+
+// Pool defines the methods of the RIP-7560 pool the bundler draws the
+// transactions from and hands the local bundles to.
+type Pool interface {
+	// LocalBundle returns the executable individually submitted transactions
+	// as a bundle for the next block.
+	LocalBundle() *types.ExternallyReceivedBundle
+
+	// ReplaceRip7560Bundle inserts the bundle, replacing the one previously
+	// submitted by the same bundler for the same block.
+	ReplaceRip7560Bundle(bundle *types.ExternallyReceivedBundle)
+}
+
+// Bundler is a node lifecycle batching the validated RIP-7560 transactions of
+// the pool into a bundle for every block. The bundle is simulated as a whole on
+// top of the chain head, dropping the transactions invalidated by the ones
+// preceding them, before being handed to the miner or to a remote builder.
+type Bundler struct {
+	config Config
+	chain  BlockChain
+	pool   Pool
+
+	client *rpc.Client // Connection to the remote block builder (nil if local)
+	last   common.Hash // Hash of the last submitted bundle
+	lastAt uint64      // Block number the last submitted bundle is valid for
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates an in-process bundler on top of the given chain and pool.
+func New(config Config, chain BlockChain, pool Pool) *Bundler {
+	if config.Recommit <= 0 {
+		log.Warn("Sanitizing invalid bundler recommit interval", "provided", config.Recommit, "updated", DefaultConfig.Recommit)
+		config.Recommit = DefaultConfig.Recommit
+	}
+	return &Bundler{
+		config: config,
+		chain:  chain,
+		pool:   pool,
+		quit:   make(chan struct{}),
+	}
+}
+
+// Start implements node.Lifecycle, connecting to the remote builder if any and
+// starting the bundling loop.
+func (b *Bundler) Start() error {
+	if b.config.Builder != "" {
+		client, err := rpc.Dial(b.config.Builder)
+		if err != nil {
+			return err
+		}
+		b.client = client
+	}
+	b.wg.Add(1)
+	go b.loop()
+
+	log.Info("Started RIP-7560 bundler", "builder", b.config.Builder, "recommit", b.config.Recommit)
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the bundling loop.
+func (b *Bundler) Stop() error {
+	close(b.quit)
+	b.wg.Wait()
+
+	if b.client != nil {
+		b.client.Close()
+	}
+	log.Info("Stopped RIP-7560 bundler")
+	return nil
+}
+
+// loop rebuilds the bundle of the next block whenever the chain head changes,
+// and periodically in between to pick up newly submitted transactions.
+func (b *Bundler) loop() {
+	defer b.wg.Done()
+
+	var (
+		heads  = make(chan core.ChainHeadEvent, 16)
+		sub    = b.chain.SubscribeChainHeadEvent(heads)
+		ticker = time.NewTicker(b.config.Recommit)
+	)
+	defer sub.Unsubscribe()
+	defer ticker.Stop()
+
+	for {
+		select {
+		case ev := <-heads:
+			b.bundle(ev.Block.Header())
+			ticker.Reset(b.config.Recommit)
+
+		case <-ticker.C:
+			b.bundle(b.chain.CurrentBlock())
+
+		case <-sub.Err():
+			return
+
+		case <-b.quit:
+			return
+		}
+	}
+}
+
+// bundle builds and submits the bundle of the block following the given head,
+// unless it's the same as the last submitted one.
+func (b *Bundler) bundle(head *types.Header) {
+	bundle := b.pool.LocalBundle()
+	if bundle == nil {
+		return
+	}
+	bundle, err := b.simulate(head, bundle)
+	if err != nil {
+		log.Warn("Failed to simulate RIP-7560 bundle", "err", err)
+		return
+	}
+	if bundle == nil || (bundle.BundleHash == b.last && bundle.ValidForBlock.Uint64() == b.lastAt) {
+		return
+	}
+	if err := b.submit(bundle); err != nil {
+		log.Warn("Failed to submit RIP-7560 bundle", "builder", b.config.Builder, "err", err)
+		return
+	}
+	b.last, b.lastAt = bundle.BundleHash, bundle.ValidForBlock.Uint64()
+	log.Debug("Submitted RIP-7560 bundle", "hash", bundle.BundleHash, "block", bundle.ValidForBlock, "txs", len(bundle.Transactions))
+}
+
+// simulate applies the transactions of the bundle one after the other on top
+// of the given head, and returns the bundle of the ones passing their validation
+// phase. Nil is returned if none of them does.
+func (b *Bundler) simulate(head *types.Header, bundle *types.ExternallyReceivedBundle) (*types.ExternallyReceivedBundle, error) {
+	statedb, err := b.chain.StateAt(head.Root)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		config = b.chain.Config()
+		header = &types.Header{
+			ParentHash: head.Hash(),
+			Number:     new(big.Int).Add(head.Number, common.Big1),
+			GasLimit:   head.GasLimit,
+			Time:       head.Time + 1,
+			Difficulty: new(big.Int),
+		}
+		gp      = new(core.GasPool).AddGas(header.GasLimit)
+		usedGas uint64
+		txs     types.Transactions
+	)
+	if config.IsLondon(header.Number) && head.BaseFee != nil {
+		header.BaseFee = eip1559.CalcBaseFee(config, head)
+	}
+	for _, tx := range bundle.Transactions {
+		snap := statedb.Snapshot()
+		if _, err := core.ApplyRip7560Transaction(config, b.chain, &header.Coinbase, gp, statedb, header, common.Hash{}, tx, len(txs), &usedGas, vm.Config{}); err != nil {
+			log.Debug("Dropping RIP-7560 transaction from bundle", "hash", tx.Hash(), "err", err)
+			statedb.RevertToSnapshot(snap)
+			continue
+		}
+		txs = append(txs, tx)
+	}
+	if len(txs) == 0 {
+		return nil, nil
+	}
+	return &types.ExternallyReceivedBundle{
+		BundlerId:     bundlerID,
+		BundleHash:    ethapi.CalculateBundleHash(txs),
+		ValidForBlock: header.Number,
+		Transactions:  txs,
+	}, nil
+}
+
+// submit hands the bundle to the remote builder if configured, or to the local
+// pool for the miner otherwise.
+func (b *Bundler) submit(bundle *types.ExternallyReceivedBundle) error {
+	if b.client == nil {
+		b.pool.ReplaceRip7560Bundle(bundle)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), builderTimeout)
+	defer cancel()
+
+	var hash common.Hash
+	return b.client.CallContext(ctx, &hash, "eth_sendRip7560TransactionsBundle", bundle.Transactions, bundle.ValidForBlock, bundle.BundlerId)
+}
@@ -0,0 +1,165 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rip7560bundler
+
+import (
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testPool is a RIP-7560 pool handing out a fixed bundle and recording the
+// bundles submitted back to it.
+type testPool struct {
+	bundle    *types.ExternallyReceivedBundle
+	submitted []*types.ExternallyReceivedBundle
+}
+
+func (p *testPool) LocalBundle() *types.ExternallyReceivedBundle { return p.bundle }
+
+func (p *testPool) ReplaceRip7560Bundle(bundle *types.ExternallyReceivedBundle) {
+	p.submitted = append(p.submitted, bundle)
+}
+
+// testBuilder is a remote block builder recording the received bundles.
+type testBuilder struct {
+	received [][]*types.Transaction
+}
+
+func (b *testBuilder) SendRip7560TransactionsBundle(txs []*types.Transaction, creationBlock *big.Int, bundlerId string) (common.Hash, error) {
+	b.received = append(b.received, txs)
+	return common.Hash{}, nil
+}
+
+var (
+	accepting = common.Address{0xaa} // Account accepting all transactions
+	reverting = common.Address{0xbb} // Account rejecting all transactions
+)
+
+// newTestChain creates a chain whose genesis holds the test accounts.
+func newTestChain(t *testing.T) *core.BlockChain {
+	code := []byte{byte(vm.PUSH32)}
+	code = append(code, core.PackValidationData(core.AcceptAccountMethodSig, 0, 0)...)
+	code = append(code, byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN))
+
+	gspec := &core.Genesis{
+		Config:  params.AllDevChainProtocolChanges,
+		BaseFee: big.NewInt(params.InitialBaseFee),
+		Alloc: types.GenesisAlloc{
+			accepting: {Code: code, Balance: big.NewInt(params.Ether)},
+			reverting: {Code: []byte{byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.REVERT)}, Balance: big.NewInt(params.Ether)},
+		},
+	}
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, beacon.New(ethash.NewFaker()), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	t.Cleanup(chain.Stop)
+	return chain
+}
+
+func newTestTx(sender common.Address, nonce uint64) *types.Transaction {
+	return types.NewTx(&types.Rip7560AccountAbstractionTx{
+		ChainID:            params.AllDevChainProtocolChanges.ChainID,
+		NonceKey:           new(big.Int),
+		Nonce:              nonce,
+		GasTipCap:          big.NewInt(1),
+		GasFeeCap:          big.NewInt(params.GWei),
+		Gas:                100_000,
+		Sender:             &sender,
+		ValidationGasLimit: 100_000,
+	})
+}
+
+// Tests that the transactions failing the joint simulation are dropped from
+// the bundle handed to the local pool, and that unchanged bundles are only
+// submitted once per block.
+func TestBundleLocal(t *testing.T) {
+	var (
+		chain = newTestChain(t)
+		first = newTestTx(accepting, 0)
+		next  = newTestTx(accepting, 1)
+		pool  = &testPool{bundle: &types.ExternallyReceivedBundle{
+			Transactions: []*types.Transaction{first, newTestTx(reverting, 0), next, newTestTx(accepting, 1)},
+		}}
+		bundler = New(DefaultConfig, chain, pool)
+		head    = chain.CurrentBlock()
+	)
+	bundler.bundle(head)
+	if len(pool.submitted) != 1 {
+		t.Fatalf("submitted bundles mismatch: have %d, want 1", len(pool.submitted))
+	}
+	bundle := pool.submitted[0]
+	if want := []*types.Transaction{first, next}; !slices.Equal(bundle.Transactions, want) {
+		t.Errorf("bundle transactions mismatch: have %d, want %d", len(bundle.Transactions), len(want))
+	}
+	if bundle.ValidForBlock.Uint64() != head.Number.Uint64()+1 {
+		t.Errorf("bundle block mismatch: have %v, want %d", bundle.ValidForBlock, head.Number.Uint64()+1)
+	}
+	if bundle.BundlerId != bundlerID {
+		t.Errorf("bundler id mismatch: have %q, want %q", bundle.BundlerId, bundlerID)
+	}
+	// Rebuilding the same bundle doesn't resubmit it
+	bundler.bundle(head)
+	if len(pool.submitted) != 1 {
+		t.Errorf("unchanged bundle resubmitted")
+	}
+	// An empty bundle is not submitted
+	pool.bundle = &types.ExternallyReceivedBundle{Transactions: []*types.Transaction{newTestTx(reverting, 0)}}
+	bundler.bundle(head)
+	if len(pool.submitted) != 1 {
+		t.Errorf("empty bundle submitted")
+	}
+}
+
+// Tests that bundles are sent to the remote builder when configured instead of
+// the local pool.
+func TestBundleBuilder(t *testing.T) {
+	var (
+		chain   = newTestChain(t)
+		tx      = newTestTx(accepting, 0)
+		pool    = &testPool{bundle: &types.ExternallyReceivedBundle{Transactions: []*types.Transaction{tx}}}
+		builder = new(testBuilder)
+		server  = rpc.NewServer()
+	)
+	if err := server.RegisterName("eth", builder); err != nil {
+		t.Fatalf("failed to register builder: %v", err)
+	}
+	defer server.Stop()
+
+	bundler := New(DefaultConfig, chain, pool)
+	bundler.client = rpc.DialInProc(server)
+	defer bundler.client.Close()
+
+	bundler.bundle(chain.CurrentBlock())
+	if len(pool.submitted) != 0 {
+		t.Errorf("bundle submitted to the local pool")
+	}
+	if len(builder.received) != 1 || len(builder.received[0]) != 1 || builder.received[0][0].Hash() != tx.Hash() {
+		t.Errorf("builder received bundles mismatch: %v", builder.received)
+	}
+}
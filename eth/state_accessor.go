@@ -237,7 +237,10 @@ func (eth *Ethereum) stateAtTransaction(ctx context.Context, block *types.Block,
 	if beaconRoot := block.BeaconRoot(); beaconRoot != nil {
 		context := core.NewEVMBlockContext(block.Header(), eth.blockchain, nil)
 		vmenv := vm.NewEVM(context, vm.TxContext{}, statedb, eth.blockchain.Config(), vm.Config{})
-		core.ProcessBeaconBlockRoot(*beaconRoot, vmenv, statedb)
+		if err := core.ProcessBeaconBlockRoot(*beaconRoot, vmenv, statedb); err != nil {
+			release()
+			return nil, vm.BlockContext{}, nil, nil, err
+		}
 	}
 	if txIndex == 0 && len(block.Transactions()) == 0 {
 		return nil, vm.BlockContext{}, statedb, release, nil
@@ -253,6 +256,13 @@ func (eth *Ethereum) stateAtTransaction(ctx context.Context, block *types.Block,
 			return tx, context, statedb, release, nil
 		}
 		// Not yet the searched for transaction, execute on top of the current state
+		if tx.Type() == types.Rip7560Type {
+			var usedGas uint64
+			if _, err := core.ApplyRip7560Transaction(eth.blockchain.Config(), eth.blockchain, nil, new(core.GasPool).AddGas(block.GasLimit()), statedb, block.Header(), block.Hash(), tx, idx, &usedGas, vm.Config{}); err != nil {
+				return nil, vm.BlockContext{}, nil, nil, fmt.Errorf("transaction %#x failed: %v", tx.Hash(), err)
+			}
+			continue
+		}
 		vmenv := vm.NewEVM(context, txContext, statedb, eth.blockchain.Config(), vm.Config{})
 		statedb.SetTxContext(tx.Hash(), idx)
 		if _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(tx.Gas())); err != nil {
@@ -17,21 +17,23 @@
 package eth
 
 import (
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 )
 
 // syncTransactions starts sending all currently pending transactions to the given peer.
 func (h *handler) syncTransactions(p *eth.Peer) {
-	var hashes []common.Hash
+	var txs []*types.Transaction
 	for _, batch := range h.txpool.Pending(txpool.PendingFilter{OnlyPlainTxs: true}) {
 		for _, tx := range batch {
-			hashes = append(hashes, tx.Hash)
+			if tx := tx.Resolve(); tx != nil {
+				txs = append(txs, tx)
+			}
 		}
 	}
-	if len(hashes) == 0 {
+	if len(txs) == 0 {
 		return
 	}
-	p.AsyncSendPooledTransactionHashes(hashes)
+	p.AsyncSendPooledTransactionHashes(txs)
 }
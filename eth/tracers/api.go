@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"runtime"
 	"sync"
@@ -38,6 +39,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/tracers/logger"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/internal/rpcusage"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -277,7 +279,7 @@ func (api *API) traceChain(start, end *types.Block, config *TraceConfig, closed
 						TxIndex:     i,
 						TxHash:      tx.Hash(),
 					}
-					res, err := api.traceTx(ctx, tx, msg, txctx, blockCtx, task.statedb, config)
+					res, err := api.traceTx(ctx, tx, msg, txctx, blockCtx, task.statedb, config, false)
 					if err != nil {
 						task.results[i] = &txTraceResult{TxHash: tx.Hash(), Error: err.Error()}
 						log.Warn("Tracing failed", "hash", tx.Hash(), "block", task.block.NumberU64(), "err", err)
@@ -380,7 +382,11 @@ func (api *API) traceChain(start, end *types.Block, config *TraceConfig, closed
 			if beaconRoot := next.BeaconRoot(); beaconRoot != nil {
 				context := core.NewEVMBlockContext(next.Header(), api.chainContext(ctx), nil)
 				vmenv := vm.NewEVM(context, vm.TxContext{}, statedb, api.backend.ChainConfig(), vm.Config{})
-				core.ProcessBeaconBlockRoot(*beaconRoot, vmenv, statedb)
+				if err := core.ProcessBeaconBlockRoot(*beaconRoot, vmenv, statedb); err != nil {
+					release()
+					failed = err
+					break
+				}
 			}
 			// Clean out any pending release functions of trace state. Note this
 			// step must be done after constructing tracing state, because the
@@ -532,7 +538,9 @@ func (api *API) IntermediateRoots(ctx context.Context, hash common.Hash, config
 	)
 	if beaconRoot := block.BeaconRoot(); beaconRoot != nil {
 		vmenv := vm.NewEVM(vmctx, vm.TxContext{}, statedb, chainConfig, vm.Config{})
-		core.ProcessBeaconBlockRoot(*beaconRoot, vmenv, statedb)
+		if err := core.ProcessBeaconBlockRoot(*beaconRoot, vmenv, statedb); err != nil {
+			return nil, err
+		}
 	}
 	for i, tx := range block.Transactions() {
 		if err := ctx.Err(); err != nil {
@@ -611,18 +619,25 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 	)
 	if beaconRoot := block.BeaconRoot(); beaconRoot != nil {
 		vmenv := vm.NewEVM(blockCtx, vm.TxContext{}, statedb, api.backend.ChainConfig(), vm.Config{})
-		core.ProcessBeaconBlockRoot(*beaconRoot, vmenv, statedb)
+		if err := core.ProcessBeaconBlockRoot(*beaconRoot, vmenv, statedb); err != nil {
+			return nil, err
+		}
 	}
 	for i, tx := range txs {
-		// Generate the next state snapshot fast without tracing
-		msg, _ := core.TransactionToMessage(tx, signer, block.BaseFee())
 		txctx := &Context{
 			BlockHash:   blockHash,
 			BlockNumber: block.Number(),
 			TxIndex:     i,
 			TxHash:      tx.Hash(),
 		}
-		res, err := api.traceTx(ctx, tx, msg, txctx, blockCtx, statedb, config)
+		var res interface{}
+		if tx.Type() == types.Rip7560Type {
+			res, err = api.traceRip7560Tx(ctx, tx, txctx, blockCtx, statedb, block.Header(), config)
+		} else {
+			// Generate the next state snapshot fast without tracing
+			msg, _ := core.TransactionToMessage(tx, signer, block.BaseFee())
+			res, err = api.traceTx(ctx, tx, msg, txctx, blockCtx, statedb, config, false)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -666,7 +681,7 @@ func (api *API) traceBlockParallel(ctx context.Context, block *types.Block, stat
 				// concurrent use.
 				// See: https://github.com/ethereum/go-ethereum/issues/29114
 				blockCtx := core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
-				res, err := api.traceTx(ctx, txs[task.index], msg, txctx, blockCtx, task.statedb, config)
+				res, err := api.traceTx(ctx, txs[task.index], msg, txctx, blockCtx, task.statedb, config, false)
 				if err != nil {
 					results[task.index] = &txTraceResult{TxHash: txs[task.index].Hash(), Error: err.Error()}
 					continue
@@ -769,7 +784,9 @@ func (api *API) standardTraceBlockToFile(ctx context.Context, block *types.Block
 	}
 	if beaconRoot := block.BeaconRoot(); beaconRoot != nil {
 		vmenv := vm.NewEVM(vmctx, vm.TxContext{}, statedb, chainConfig, vm.Config{})
-		core.ProcessBeaconBlockRoot(*beaconRoot, vmenv, statedb)
+		if err := core.ProcessBeaconBlockRoot(*beaconRoot, vmenv, statedb); err != nil {
+			return nil, err
+		}
 	}
 	for i, tx := range block.Transactions() {
 		// Prepare the transaction for un-traced execution
@@ -872,10 +889,6 @@ func (api *API) TraceTransaction(ctx context.Context, hash common.Hash, config *
 		return nil, err
 	}
 	defer release()
-	msg, err := core.TransactionToMessage(tx, types.MakeSigner(api.backend.ChainConfig(), block.Number(), block.Time()), block.BaseFee())
-	if err != nil {
-		return nil, err
-	}
 
 	txctx := &Context{
 		BlockHash:   blockHash,
@@ -883,7 +896,15 @@ func (api *API) TraceTransaction(ctx context.Context, hash common.Hash, config *
 		TxIndex:     int(index),
 		TxHash:      hash,
 	}
-	return api.traceTx(ctx, tx, msg, txctx, vmctx, statedb, config)
+	// RIP-7560 transactions are traced frame by frame
+	if tx.Type() == types.Rip7560Type {
+		return api.traceRip7560Tx(ctx, tx, txctx, vmctx, statedb, block.Header(), config)
+	}
+	msg, err := core.TransactionToMessage(tx, types.MakeSigner(api.backend.ChainConfig(), block.Number(), block.Time()), block.BaseFee())
+	if err != nil {
+		return nil, err
+	}
+	return api.traceTx(ctx, tx, msg, txctx, vmctx, statedb, config, true)
 }
 
 // TraceCall lets you trace a given eth_call. It collects the structured logs
@@ -955,13 +976,14 @@ func (api *API) TraceCall(ctx context.Context, args ethapi.TransactionArgs, bloc
 	if config != nil {
 		traceConfig = &config.TraceConfig
 	}
-	return api.traceTx(ctx, tx, msg, new(Context), vmctx, statedb, traceConfig)
+	return api.traceTx(ctx, tx, msg, new(Context), vmctx, statedb, traceConfig, true)
 }
 
 // traceTx configures a new tracer according to the provided configuration, and
 // executes the given message in the provided environment. The return value will
-// be tracer dependent.
-func (api *API) traceTx(ctx context.Context, tx *types.Transaction, message *core.Message, txctx *Context, vmctx vm.BlockContext, statedb *state.StateDB, config *TraceConfig) (interface{}, error) {
+// be tracer dependent. If stream is set, the struct logs spilled to disk are
+// streamed into the RPC response instead of being loaded into memory.
+func (api *API) traceTx(ctx context.Context, tx *types.Transaction, message *core.Message, txctx *Context, vmctx vm.BlockContext, statedb *state.StateDB, config *TraceConfig, stream bool) (interface{}, error) {
 	var (
 		tracer  *Tracer
 		err     error
@@ -972,13 +994,15 @@ func (api *API) traceTx(ctx context.Context, tx *types.Transaction, message *cor
 		config = &TraceConfig{}
 	}
 	// Default tracer is the struct logger
+	var structLogger *logger.StructLogger
 	if config.Tracer == nil {
-		logger := logger.NewStructLogger(config.Config)
-		tracer = &Tracer{
-			Hooks:     logger.Hooks(),
-			GetResult: logger.GetResult,
-			Stop:      logger.Stop,
-		}
+		tracer, structLogger = newStructLogTracer(config)
+		defer func() {
+			// Release any logs spilled to disk, unless they are streamed
+			if structLogger != nil {
+				structLogger.Reset()
+			}
+		}()
 	} else {
 		tracer, err = DefaultDirectory.New(*config.Tracer, txctx, config.TracerConfig)
 		if err != nil {
@@ -1008,13 +1032,40 @@ func (api *API) traceTx(ctx context.Context, tx *types.Transaction, message *cor
 
 	// Call Prepare to clear out the statedb access list
 	statedb.SetTxContext(txctx.TxHash, txctx.TxIndex)
-	_, err = core.ApplyTransactionWithEVM(message, api.backend.ChainConfig(), new(core.GasPool).AddGas(message.GasLimit), statedb, vmctx.BlockNumber, txctx.BlockHash, tx, &usedGas, vmenv)
+	_, _, err = core.ApplyTransactionWithEVM(message, api.backend.ChainConfig(), new(core.GasPool).AddGas(message.GasLimit), statedb, vmctx.BlockNumber, txctx.BlockHash, tx, &usedGas, vmenv)
 	if err != nil {
 		return nil, fmt.Errorf("tracing failed: %w", err)
 	}
+	rpcusage.Record(ctx, usedGas, tx.Size())
+	if stream && structLogger != nil && structLogger.Spilled() {
+		result := &structLogResult{logger: structLogger}
+		structLogger = nil // released once the result is streamed
+		return result, nil
+	}
 	return tracer.GetResult()
 }
 
+// newStructLogTracer creates the default struct logger tracer. The logs it
+// spilled to disk, if a memory limit was configured, are released by resetting
+// the returned logger.
+func newStructLogTracer(config *TraceConfig) (*Tracer, *logger.StructLogger) {
+	logger := logger.NewStructLogger(config.Config)
+	return &Tracer{
+		Hooks:     logger.Hooks(),
+		GetResult: logger.GetResult,
+		Stop:      logger.Stop,
+	}, logger
+}
+
+// structLogResult is the result of a struct logger which spilled its logs to
+// disk. It's streamed into the RPC response, deleting the spilled logs after.
+type structLogResult struct {
+	logger *logger.StructLogger
+}
+
+func (r *structLogResult) WriteJSON(w io.Writer) error { return r.logger.WriteResult(w) }
+func (r *structLogResult) Close()                      { r.logger.Reset() }
+
 // APIs return the collection of RPC services the tracer package offers.
 func APIs(backend Backend) []rpc.API {
 	// Append all the local APIs and return
@@ -1025,6 +1076,9 @@ func APIs(backend Backend) []rpc.API {
 		}, {
 			Namespace: "eth",
 			Service:   NewRip7560API(backend),
+		}, {
+			Namespace: "trace",
+			Service:   NewTraceAPI(backend),
 		},
 	}
 }
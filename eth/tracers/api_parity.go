@@ -0,0 +1,314 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// parityTracer is the native tracer producing the traces of the trace module.
+	parityTracer = "flatCallTracer"
+
+	// maxTraceFilterBlocks is the maximum number of blocks trace_filter traces
+	// in a single request.
+	maxTraceFilterBlocks = 100
+)
+
+// parityTracerConfig makes the tracer report the errors as OpenEthereum does.
+var parityTracerConfig = json.RawMessage(`{"convertParityErrors":true}`)
+
+// parityTrace is a call trace in the format of the OpenEthereum trace module.
+// The traces of a RIP-7560 transaction carry the name of the frame they belong to.
+type parityTrace struct {
+	Action              json.RawMessage `json:"action"`
+	BlockHash           *common.Hash    `json:"blockHash"`
+	BlockNumber         uint64          `json:"blockNumber"`
+	Error               string          `json:"error,omitempty"`
+	Result              json.RawMessage `json:"result,omitempty"`
+	Subtraces           int             `json:"subtraces"`
+	TraceAddress        []int           `json:"traceAddress"`
+	TransactionHash     *common.Hash    `json:"transactionHash"`
+	TransactionPosition uint64          `json:"transactionPosition"`
+	Type                string          `json:"type"`
+	Frame               string          `json:"aaFrame,omitempty"`
+}
+
+// parityCallAction is the action of the root trace of a RIP-7560 transaction.
+type parityCallAction struct {
+	CallType string          `json:"callType"`
+	From     common.Address  `json:"from"`
+	Gas      hexutil.Uint64  `json:"gas"`
+	Input    hexutil.Bytes   `json:"input"`
+	To       *common.Address `json:"to"`
+	Value    *hexutil.Big    `json:"value"`
+}
+
+// parityCallResult is the result of the root trace of a RIP-7560 transaction.
+type parityCallResult struct {
+	GasUsed hexutil.Uint64 `json:"gasUsed"`
+	Output  hexutil.Bytes  `json:"output"`
+}
+
+// parties returns the addresses the trace is sent from and to. The contract
+// created by a creation trace is the address it is sent to.
+func (t *parityTrace) parties() (from, to *common.Address) {
+	var action struct {
+		From *common.Address `json:"from"`
+		To   *common.Address `json:"to"`
+	}
+	var result struct {
+		Address *common.Address `json:"address"`
+	}
+	json.Unmarshal(t.Action, &action)
+	if len(t.Result) > 0 {
+		json.Unmarshal(t.Result, &result)
+	}
+	if action.To == nil {
+		action.To = result.Address
+	}
+	return action.From, action.To
+}
+
+// TraceFilterArgs are the criteria of trace_filter.
+type TraceFilterArgs struct {
+	FromBlock   *rpc.BlockNumber `json:"fromBlock"`
+	ToBlock     *rpc.BlockNumber `json:"toBlock"`
+	FromAddress []common.Address `json:"fromAddress"`
+	ToAddress   []common.Address `json:"toAddress"`
+	After       *uint64          `json:"after"`
+	Count       *uint64          `json:"count"`
+}
+
+// TraceAPI is the collection of tracing APIs exposed over the trace namespace,
+// in the format of the OpenEthereum trace module.
+type TraceAPI struct {
+	api *API
+}
+
+// NewTraceAPI creates a new API definition for the trace module methods.
+func NewTraceAPI(backend Backend) *TraceAPI {
+	return &TraceAPI{api: NewAPI(backend)}
+}
+
+// Block returns the call traces of all the transactions of a block.
+func (api *TraceAPI) Block(ctx context.Context, number rpc.BlockNumber) ([]*parityTrace, error) {
+	block, err := api.api.blockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return api.traceBlock(ctx, block)
+}
+
+// Transaction returns the call traces of a transaction.
+func (api *TraceAPI) Transaction(ctx context.Context, hash common.Hash) ([]*parityTrace, error) {
+	tracer := parityTracer
+	found, tx, _, _, _, err := api.api.backend.GetTransaction(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errTxNotFound
+	}
+	result, err := api.api.TraceTransaction(ctx, hash, &TraceConfig{Tracer: &tracer, TracerConfig: parityTracerConfig})
+	if err != nil {
+		return nil, err
+	}
+	return newParityTraces(tx, result)
+}
+
+// Filter returns the call traces of the given block range matching the senders
+// and recipients of the criteria. Traces match if they are sent from any of the
+// from addresses and to any of the to addresses, an empty list matching any.
+func (api *TraceAPI) Filter(ctx context.Context, args TraceFilterArgs) ([]*parityTrace, error) {
+	from, err := api.filterBlock(ctx, args.FromBlock)
+	if err != nil {
+		return nil, err
+	}
+	to, err := api.filterBlock(ctx, args.ToBlock)
+	if err != nil {
+		return nil, err
+	}
+	if from > to {
+		return nil, fmt.Errorf("invalid block range %d-%d", from, to)
+	}
+	if to-from >= maxTraceFilterBlocks {
+		return nil, fmt.Errorf("block range %d-%d exceeds limit of %d blocks", from, to, maxTraceFilterBlocks)
+	}
+	var (
+		matches = make([]*parityTrace, 0)
+		skip    uint64
+	)
+	if args.After != nil {
+		skip = *args.After
+	}
+	for number := from; number <= to; number++ {
+		block, err := api.api.blockByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil {
+			return nil, err
+		}
+		traces, err := api.traceBlock(ctx, block)
+		if err != nil {
+			return nil, err
+		}
+		for _, trace := range traces {
+			sender, recipient := trace.parties()
+			if !matchesParty(args.FromAddress, sender) || !matchesParty(args.ToAddress, recipient) {
+				continue
+			}
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if args.Count != nil && uint64(len(matches)) == *args.Count {
+				return matches, nil
+			}
+			matches = append(matches, trace)
+		}
+	}
+	return matches, nil
+}
+
+// filterBlock resolves a block of the filter criteria, defaulting to the latest.
+func (api *TraceAPI) filterBlock(ctx context.Context, number *rpc.BlockNumber) (uint64, error) {
+	if number == nil {
+		latest := rpc.LatestBlockNumber
+		number = &latest
+	}
+	header, err := api.api.backend.HeaderByNumber(ctx, *number)
+	if err != nil {
+		return 0, err
+	}
+	if header == nil {
+		return 0, fmt.Errorf("block #%d not found", *number)
+	}
+	return header.Number.Uint64(), nil
+}
+
+// matchesParty reports whether the address is in the list, an empty list
+// matching any address.
+func matchesParty(list []common.Address, addr *common.Address) bool {
+	if len(list) == 0 {
+		return true
+	}
+	return addr != nil && slices.Contains(list, *addr)
+}
+
+// traceBlock returns the call traces of all the transactions of a block. The
+// genesis block has no traces.
+func (api *TraceAPI) traceBlock(ctx context.Context, block *types.Block) ([]*parityTrace, error) {
+	if block.NumberU64() == 0 {
+		return make([]*parityTrace, 0), nil
+	}
+	tracer := parityTracer
+	results, err := api.api.traceBlock(ctx, block, &TraceConfig{Tracer: &tracer, TracerConfig: parityTracerConfig})
+	if err != nil {
+		return nil, err
+	}
+	traces := make([]*parityTrace, 0)
+	for i, tx := range block.Transactions() {
+		txTraces, err := newParityTraces(tx, results[i].Result)
+		if err != nil {
+			return nil, err
+		}
+		traces = append(traces, txTraces...)
+	}
+	return traces, nil
+}
+
+// newParityTraces converts the result of the flat call tracer for a transaction
+// into trace module traces.
+//
+// A RIP-7560 transaction is represented as a call of the entry point to the
+// sender, whose subtraces are the calls of its frames, in the order they ran.
+// Each subtrace carries the name of its frame.
+func newParityTraces(tx *types.Transaction, result interface{}) ([]*parityTrace, error) {
+	raw, ok := result.(json.RawMessage)
+	if !ok {
+		return nil, errors.New("unexpected tracer result")
+	}
+	if tx.Type() != types.Rip7560Type {
+		var traces []*parityTrace
+		if err := json.Unmarshal(raw, &traces); err != nil {
+			return nil, err
+		}
+		return traces, nil
+	}
+	var frames []rip7560FrameTrace
+	if err := json.Unmarshal(raw, &frames); err != nil {
+		return nil, err
+	}
+	var (
+		aatx    = tx.Rip7560TransactionData()
+		root    = &parityTrace{TraceAddress: []int{}, Type: "call"}
+		traces  = []*parityTrace{root}
+		gasUsed uint64
+	)
+	for _, frame := range frames {
+		var frameTraces []*parityTrace
+		if err := json.Unmarshal(frame.Result, &frameTraces); err != nil {
+			return nil, fmt.Errorf("frame %s: %w", frame.Frame, err)
+		}
+		if len(frameTraces) == 0 {
+			continue
+		}
+		var result struct {
+			GasUsed hexutil.Uint64 `json:"gasUsed"`
+		}
+		if len(frameTraces[0].Result) > 0 {
+			json.Unmarshal(frameTraces[0].Result, &result)
+		}
+		gasUsed += uint64(result.GasUsed)
+		frameTraces[0].Frame = frame.Frame
+
+		for _, trace := range frameTraces {
+			trace.TraceAddress = append([]int{root.Subtraces}, trace.TraceAddress...)
+		}
+		root.BlockHash, root.BlockNumber = frameTraces[0].BlockHash, frameTraces[0].BlockNumber
+		root.TransactionHash, root.TransactionPosition = frameTraces[0].TransactionHash, frameTraces[0].TransactionPosition
+		root.Subtraces++
+		traces = append(traces, frameTraces...)
+	}
+	gas, err := aatx.TotalGasLimit()
+	if err != nil {
+		return nil, err
+	}
+	if root.Action, err = json.Marshal(&parityCallAction{
+		CallType: "call",
+		From:     core.AA_ENTRY_POINT,
+		Gas:      hexutil.Uint64(gas),
+		Input:    hexutil.Bytes{},
+		To:       aatx.Sender,
+		Value:    new(hexutil.Big),
+	}); err != nil {
+		return nil, err
+	}
+	if root.Result, err = json.Marshal(&parityCallResult{GasUsed: hexutil.Uint64(gasUsed), Output: hexutil.Bytes{}}); err != nil {
+		return nil, err
+	}
+	return traces, nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Tests that the flat call traces of a RIP-7560 transaction are nested into a
+// call of the entry point to the sender, labelled with their frames.
+func TestParityTracesRip7560(t *testing.T) {
+	t.Parallel()
+
+	var (
+		sender    = common.Address{0xaa}
+		paymaster = common.Address{0xbb}
+	)
+	tx := types.NewTx(&types.Rip7560AccountAbstractionTx{
+		ChainID:                     big.NewInt(1),
+		NonceKey:                    new(big.Int),
+		GasTipCap:                   big.NewInt(1),
+		GasFeeCap:                   big.NewInt(1),
+		Gas:                         100_000,
+		Sender:                      &sender,
+		Paymaster:                   &paymaster,
+		ValidationGasLimit:          50_000,
+		PaymasterValidationGasLimit: 50_000,
+	})
+	frame := func(to common.Address, gasUsed string, children int) json.RawMessage {
+		trace := `{"action":{"callType":"call","from":"` + core.AA_ENTRY_POINT.Hex() + `","to":"` + to.Hex() + `"},"blockNumber":1,"result":{"gasUsed":"` + gasUsed + `"},"subtraces":1,"traceAddress":[],"transactionPosition":2,"type":"call"}`
+		for i := 0; i < children; i++ {
+			trace += `,{"action":{"callType":"staticcall","from":"` + to.Hex() + `","to":"0x0000000000000000000000000000000000000001"},"blockNumber":1,"subtraces":0,"traceAddress":[0],"transactionPosition":2,"type":"call"}`
+		}
+		return json.RawMessage("[" + trace + "]")
+	}
+	frames, _ := json.Marshal([]rip7560FrameTrace{
+		{Frame: core.FrameAccount, Result: frame(sender, "0x10", 1)},
+		{Frame: core.FramePaymaster, Result: frame(paymaster, "0x20", 0)},
+		{Frame: "execution", Result: frame(sender, "0x30", 0)},
+	})
+	traces, err := newParityTraces(tx, json.RawMessage(frames))
+	if err != nil {
+		t.Fatalf("failed to convert traces: %v", err)
+	}
+	var (
+		addresses [][]int
+		labels    []string
+	)
+	for _, trace := range traces {
+		addresses = append(addresses, trace.TraceAddress)
+		labels = append(labels, trace.Frame)
+		if trace.BlockNumber != 1 || trace.TransactionPosition != 2 {
+			t.Errorf("trace %v: position mismatch: block %d, tx %d", trace.TraceAddress, trace.BlockNumber, trace.TransactionPosition)
+		}
+	}
+	if want := [][]int{{}, {0}, {0, 0}, {1}, {2}}; !reflect.DeepEqual(addresses, want) {
+		t.Errorf("trace addresses mismatch: have %v, want %v", addresses, want)
+	}
+	if want := []string{"", core.FrameAccount, "", core.FramePaymaster, "execution"}; !reflect.DeepEqual(labels, want) {
+		t.Errorf("frame labels mismatch: have %v, want %v", labels, want)
+	}
+	root := traces[0]
+	if root.Subtraces != 3 {
+		t.Errorf("root subtraces mismatch: have %d, want 3", root.Subtraces)
+	}
+	if from, to := root.parties(); *from != core.AA_ENTRY_POINT || *to != sender {
+		t.Errorf("root call mismatch: have %v -> %v", from, to)
+	}
+	var result parityCallResult
+	if err := json.Unmarshal(root.Result, &result); err != nil || result.GasUsed != 0x60 {
+		t.Errorf("root gas used mismatch: have %d, want %d (%v)", result.GasUsed, 0x60, err)
+	}
+}
+
+// Tests the matching of the trace_filter address criteria.
+func TestParityTraceParties(t *testing.T) {
+	t.Parallel()
+
+	var (
+		a = common.Address{0x01}
+		b = common.Address{0x02}
+	)
+	create := &parityTrace{
+		Action: json.RawMessage(`{"from":"` + a.Hex() + `","init":"0x00"}`),
+		Result: json.RawMessage(`{"address":"` + b.Hex() + `"}`),
+	}
+	from, to := create.parties()
+	if *from != a || *to != b {
+		t.Fatalf("creation parties mismatch: have %v -> %v", from, to)
+	}
+	tests := []struct {
+		list  []common.Address
+		addr  *common.Address
+		match bool
+	}{
+		{nil, nil, true},
+		{nil, &a, true},
+		{[]common.Address{a}, &a, true},
+		{[]common.Address{a}, &b, false},
+		{[]common.Address{a}, nil, false},
+	}
+	for i, tt := range tests {
+		if have := matchesParty(tt.list, tt.addr); have != tt.match {
+			t.Errorf("test %d: match mismatch: have %v, want %v", i, have, tt.match)
+		}
+	}
+}
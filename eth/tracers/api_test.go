@@ -17,6 +17,7 @@
 package tracers
 
 import (
+	"bytes"
 	"context"
 	"crypto/ecdsa"
 	"encoding/json"
@@ -177,6 +178,13 @@ func (b *testBackend) StateAtTransaction(ctx context.Context, block *types.Block
 		if idx == txIndex {
 			return tx, context, statedb, release, nil
 		}
+		if tx.Type() == types.Rip7560Type {
+			var usedGas uint64
+			if _, err := core.ApplyRip7560Transaction(b.chainConfig, b.chain, nil, new(core.GasPool).AddGas(block.GasLimit()), statedb, block.Header(), block.Hash(), tx, idx, &usedGas, vm.Config{}); err != nil {
+				return nil, vm.BlockContext{}, nil, nil, fmt.Errorf("transaction %#x failed: %v", tx.Hash(), err)
+			}
+			continue
+		}
 		vmenv := vm.NewEVM(context, txContext, statedb, b.chainConfig, vm.Config{})
 		if _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(tx.Gas())); err != nil {
 			return nil, vm.BlockContext{}, nil, nil, fmt.Errorf("transaction %#x failed: %v", tx.Hash(), err)
@@ -459,6 +467,58 @@ func TestTraceTransaction(t *testing.T) {
 	}
 }
 
+// Tests that the struct logs spilled to disk are streamed into the response of
+// a single trace, only if a memory limit was configured.
+func TestTraceCallSpilled(t *testing.T) {
+	t.Parallel()
+
+	accounts := newAccounts(1)
+	contract := common.HexToAddress("0xc0de")
+	genesis := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: types.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			contract:         {Code: []byte{byte(vm.PUSH1), 1, byte(vm.PUSH1), 2, byte(vm.ADD), byte(vm.POP), byte(vm.STOP)}},
+		},
+	}
+	backend := newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {})
+	defer backend.chain.Stop()
+	api := NewAPI(backend)
+
+	trace := func(config *logger.Config) interface{} {
+		args := ethapi.TransactionArgs{From: &accounts[0].addr, To: &contract}
+		res, err := api.TraceCall(context.Background(), args, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber), &TraceCallConfig{TraceConfig: TraceConfig{Config: config}})
+		if err != nil {
+			t.Fatalf("failed to trace call: %v", err)
+		}
+		return res
+	}
+	want, ok := trace(nil).(json.RawMessage)
+	if !ok {
+		t.Fatal("logs spilled without a memory limit")
+	}
+	stream, ok := trace(&logger.Config{MemoryLimit: 1}).(*structLogResult)
+	if !ok {
+		t.Fatal("spilled logs not streamed")
+	}
+	var have bytes.Buffer
+	if err := stream.WriteJSON(&have); err != nil {
+		t.Fatalf("failed to stream result: %v", err)
+	}
+	stream.Close()
+	if stream.logger.Spilled() {
+		t.Fatal("spilled logs not released")
+	}
+	var haveRes, wantRes logger.ExecutionResult
+	if err := json.Unmarshal(have.Bytes(), &haveRes); err != nil {
+		t.Fatalf("failed to unmarshal streamed result: %v", err)
+	}
+	json.Unmarshal(want, &wantRes)
+	if len(wantRes.StructLogs) != 5 || !reflect.DeepEqual(haveRes, wantRes) {
+		t.Fatalf("streamed result mismatch: have %s, want %s", have.Bytes(), want)
+	}
+}
+
 func TestTraceBlock(t *testing.T) {
 	t.Parallel()
 
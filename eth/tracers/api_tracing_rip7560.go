@@ -2,12 +2,14 @@ package tracers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
@@ -95,29 +97,31 @@ func (api *Rip7560API) traceTx(
 ) (interface{}, error) {
 	var (
 		tracer  *Tracer
+		user    *Tracer
 		err     error
 		timeout = defaultTraceTimeout
-		//usedGas uint64
 	)
 	if config == nil {
 		config = &TraceConfig{}
 	}
-	// Default tracer is the struct logger
-	//if config.Tracer == nil {
-	//	logger := logger.NewStructLogger(config.Config)
-	//	tracer = &Tracer{
-	//		Hooks:     logger.Hooks(),
-	//		GetResult: logger.GetResult,
-	//		Stop:      logger.Stop,
-	//	}
-	//} else {
-	tracer, err = DefaultDirectory.New("rip7560Validation", txctx, config.TracerConfig)
-	//	if err != nil {
-	//		return nil, err
-	//	}
-	//}
-	vmenv := vm.NewEVM(vmctx, vm.TxContext{GasPrice: big.NewInt(0)}, statedb, api.backend.ChainConfig(), vm.Config{Tracer: tracer.Hooks, NoBaseFee: true})
-	statedb.SetLogger(tracer.Hooks)
+	// A user tracer is stacked on top of the validation rules tracer, observing
+	// the same execution of the validation frames
+	if config.Tracer != nil && *config.Tracer != rip7560ValidationTracer {
+		if tracer, err = DefaultDirectory.New(rip7560ValidationTracer, txctx, nil); err != nil {
+			return nil, err
+		}
+		if user, err = DefaultDirectory.New(*config.Tracer, txctx, config.TracerConfig); err != nil {
+			return nil, err
+		}
+	} else if tracer, err = DefaultDirectory.New(rip7560ValidationTracer, txctx, config.TracerConfig); err != nil {
+		return nil, err
+	}
+	hooks := tracer.Hooks
+	if user != nil {
+		hooks = tracing.NewMuxHooks(tracer.Hooks, user.Hooks)
+	}
+	vmenv := vm.NewEVM(vmctx, vm.TxContext{GasPrice: big.NewInt(0)}, statedb, api.backend.ChainConfig(), vm.Config{Tracer: hooks, NoBaseFee: true})
+	statedb.SetLogger(hooks)
 
 	// Define a meaningful timeout of a single transaction trace
 	if config.Timeout != nil {
@@ -130,6 +134,9 @@ func (api *Rip7560API) traceTx(
 		<-deadlineCtx.Done()
 		if errors.Is(deadlineCtx.Err(), context.DeadlineExceeded) {
 			tracer.Stop(errors.New("execution timeout"))
+			if user != nil {
+				user.Stop(errors.New("execution timeout"))
+			}
 			// Stop evm execution. Note cancellation is not necessarily immediate.
 			vmenv.Cancel()
 		}
@@ -142,13 +149,37 @@ func (api *Rip7560API) traceTx(
 
 	// TODO: this is added to allow our bundler checking the 'TraceValidation' API is supported on Geth
 	if tx.Rip7560TransactionData().Sender.Cmp(common.HexToAddress("0x0000000000000000000000000000000000000000")) == 0 {
-		result, err := tracer.GetResult()
-		return result, err
+		return rip7560ValidationResult(tracer, user)
+	}
+
+	_, err = core.ApplyRip7560ValidationPhasesWithEVM(vmenv, gp, statedb, block.Header(), tx)
+	if err != nil {
+		return nil, err
 	}
+	return rip7560ValidationResult(tracer, user)
+}
 
-	_, err = core.ApplyRip7560ValidationPhases(api.backend.ChainConfig(), api.chainContext(ctx), nil, gp, statedb, block.Header(), tx, vmenv.Config)
+// rip7560ValidationTracer is the name of the tracer collecting the details of
+// the validation frames checked against the ERC-7562 rules.
+const rip7560ValidationTracer = "rip7560Validation"
+
+// rip7560ValidationTrace is the result of tracing the validation frames with
+// a user tracer stacked on top of the validation rules tracer.
+type rip7560ValidationTrace struct {
+	Validation json.RawMessage `json:"validation"`
+	Tracer     json.RawMessage `json:"tracer"`
+}
+
+// rip7560ValidationResult returns the result of the validation rules tracer,
+// combined with the one of the user tracer if any.
+func rip7560ValidationResult(tracer, user *Tracer) (interface{}, error) {
+	validation, err := tracer.GetResult()
+	if err != nil || user == nil {
+		return validation, err
+	}
+	result, err := user.GetResult()
 	if err != nil {
 		return nil, err
 	}
-	return tracer.GetResult()
+	return &rip7560ValidationTrace{Validation: validation, Tracer: result}, nil
 }
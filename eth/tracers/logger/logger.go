@@ -17,6 +17,7 @@
 package logger
 
 import (
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -55,6 +56,7 @@ type Config struct {
 	EnableReturnData bool // enable return data capture
 	Debug            bool // print output during capture end
 	Limit            int  // maximum length of output, but zero means unlimited
+	MemoryLimit      int  // maximum bytes of logs held in memory before spilling to disk, zero means unlimited
 	// Chain overrides, can be used to execute a trace using future fork rules
 	Overrides *params.ChainConfig `json:"overrides,omitempty"`
 }
@@ -113,6 +115,8 @@ type StructLogger struct {
 
 	storage map[common.Address]Storage
 	logs    []StructLog
+	logSize int        // Estimated memory held by logs
+	spill   *spillFile // Logs moved out of memory once MemoryLimit is exceeded
 	output  []byte
 	err     error
 	usedGas uint64
@@ -146,7 +150,12 @@ func (l *StructLogger) Reset() {
 	l.storage = make(map[common.Address]Storage)
 	l.output = make([]byte, 0)
 	l.logs = l.logs[:0]
+	l.logSize = 0
 	l.err = nil
+	if l.spill != nil {
+		l.spill.close()
+		l.spill = nil
+	}
 }
 
 // OnOpcode logs a new structured log message and pushes it out to the environment
@@ -158,7 +167,7 @@ func (l *StructLogger) OnOpcode(pc uint64, opcode byte, gas, cost uint64, scope
 		return
 	}
 	// check if already accumulated the specified number of logs
-	if l.cfg.Limit != 0 && l.cfg.Limit <= len(l.logs) {
+	if l.cfg.Limit != 0 && l.cfg.Limit <= l.spill.count()+len(l.logs) {
 		return
 	}
 
@@ -213,6 +222,14 @@ func (l *StructLogger) OnOpcode(pc uint64, opcode byte, gas, cost uint64, scope
 	// create a new snapshot of the EVM.
 	log := StructLog{pc, op, gas, cost, mem, len(memory), stck, rdata, storage, depth, l.env.StateDB.GetRefund(), err}
 	l.logs = append(l.logs, log)
+	l.logSize += log.size()
+
+	// Move the logs out of memory if they grew too large
+	if l.cfg.MemoryLimit != 0 && l.logSize > l.cfg.MemoryLimit {
+		if err := l.spillLogs(); err != nil {
+			l.Stop(err)
+		}
+	}
 }
 
 // OnExit is called a call frame finishes processing.
@@ -230,11 +247,20 @@ func (l *StructLogger) OnExit(depth int, output []byte, gasUsed uint64, err erro
 	}
 }
 
+// GetResult returns the JSON encoded result of the trace. Logs spilled to disk
+// are loaded back into memory, use WriteResult to stream them instead.
 func (l *StructLogger) GetResult() (json.RawMessage, error) {
 	// Tracing aborted
 	if l.reason != nil {
 		return nil, l.reason
 	}
+	if l.spill != nil {
+		var buf bytes.Buffer
+		if err := l.WriteResult(&buf); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
 	failed := l.err != nil
 	returnData := common.CopyBytes(l.output)
 	// Return data when successful and revert reason when reverted, otherwise empty.
@@ -271,9 +297,15 @@ func (l *StructLogger) OnTxEnd(receipt *types.Receipt, err error) {
 	l.usedGas = receipt.GasUsed
 }
 
-// StructLogs returns the captured log entries.
+// StructLogs returns the captured log entries. If the logs were spilled to disk
+// because of the configured memory limit, only the ones still held in memory
+// are returned, use WriteResult to retrieve all of them.
 func (l *StructLogger) StructLogs() []StructLog { return l.logs }
 
+// Spilled reports whether some of the logs were moved to disk because of the
+// configured memory limit.
+func (l *StructLogger) Spilled() bool { return l.spill != nil }
+
 // Error returns the VM error captured by the trace.
 func (l *StructLogger) Error() error { return l.err }
 
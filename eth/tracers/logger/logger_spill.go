@@ -0,0 +1,162 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// structLogOverhead is the approximate memory held by a StructLog, not counting
+// the captured memory, stack, return data and storage.
+const structLogOverhead = 128
+
+// size returns the approximate memory held by the log entry.
+func (s *StructLog) size() int {
+	return structLogOverhead + len(s.Memory) + len(s.ReturnData) + 32*len(s.Stack) + 2*common.HashLength*len(s.Storage)
+}
+
+// spillFile is a temporary file holding the JSON encoded logs which were moved
+// out of memory, separated by commas so they can be copied into the result as is.
+type spillFile struct {
+	file *os.File
+	buf  *bufio.Writer
+	logs int
+}
+
+func newSpillFile() (*spillFile, error) {
+	file, err := os.CreateTemp("", "structlogs-*.json")
+	if err != nil {
+		return nil, err
+	}
+	return &spillFile{file: file, buf: bufio.NewWriter(file)}, nil
+}
+
+// count returns the number of spilled logs. It's safe to call on nil.
+func (s *spillFile) count() int {
+	if s == nil {
+		return 0
+	}
+	return s.logs
+}
+
+// append encodes the given logs and appends them to the file.
+func (s *spillFile) append(logs []StructLog) error {
+	for _, res := range formatLogs(logs) {
+		enc, err := json.Marshal(res)
+		if err != nil {
+			return err
+		}
+		if s.logs > 0 {
+			s.buf.WriteByte(',')
+		}
+		if _, err := s.buf.Write(enc); err != nil {
+			return err
+		}
+		s.logs++
+	}
+	return nil
+}
+
+// writeTo copies the spilled logs into w.
+func (s *spillFile) writeTo(w io.Writer) error {
+	if err := s.buf.Flush(); err != nil {
+		return err
+	}
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := io.Copy(w, s.file)
+	if _, serr := s.file.Seek(0, io.SeekEnd); err == nil {
+		err = serr
+	}
+	return err
+}
+
+// close closes and deletes the file.
+func (s *spillFile) close() {
+	s.file.Close()
+	if err := os.Remove(s.file.Name()); err != nil {
+		log.Warn("Failed to remove struct log spill file", "file", s.file.Name(), "err", err)
+	}
+}
+
+// spillLogs moves the logs held in memory into the spill file.
+func (l *StructLogger) spillLogs() error {
+	if l.spill == nil {
+		spill, err := newSpillFile()
+		if err != nil {
+			return fmt.Errorf("failed to create struct log spill file: %w", err)
+		}
+		l.spill = spill
+	}
+	if err := l.spill.append(l.logs); err != nil {
+		return fmt.Errorf("failed to spill struct logs: %w", err)
+	}
+	clear(l.logs)
+	l.logs = l.logs[:0]
+	l.logSize = 0
+	return nil
+}
+
+// WriteResult streams the JSON encoded result of the trace into w. The output is
+// identical to the one of GetResult, but the logs which were spilled to disk are
+// never loaded into memory as a whole.
+func (l *StructLogger) WriteResult(w io.Writer) error {
+	// Tracing aborted
+	if l.reason != nil {
+		return l.reason
+	}
+	failed := l.err != nil
+	// Return data when successful and revert reason when reverted, otherwise empty.
+	returnVal := fmt.Sprintf("%x", l.output)
+	if failed && l.err != vm.ErrExecutionReverted {
+		returnVal = ""
+	}
+	encVal, _ := json.Marshal(returnVal)
+	if _, err := fmt.Fprintf(w, `{"gas":%d,"failed":%t,"returnValue":%s,"structLogs":[`, l.usedGas, failed, encVal); err != nil {
+		return err
+	}
+	if l.spill != nil {
+		if err := l.spill.writeTo(w); err != nil {
+			return err
+		}
+	}
+	for i, res := range formatLogs(l.logs) {
+		if i > 0 || l.spill.count() > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		enc, err := json.Marshal(res)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(enc); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]}")
+	return err
+}
@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"errors"
 	"math/big"
+	"os"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -106,3 +107,41 @@ func TestStructLogMarshalingOmitEmpty(t *testing.T) {
 		})
 	}
 }
+
+// Tests that spilling logs to disk doesn't change the trace output.
+func TestStructLogSpill(t *testing.T) {
+	trace := func(cfg *Config) (*StructLogger, []byte) {
+		var (
+			logger   = NewStructLogger(cfg)
+			env      = vm.NewEVM(vm.BlockContext{}, vm.TxContext{}, &dummyStatedb{}, params.TestChainConfig, vm.Config{Tracer: logger.Hooks()})
+			contract = vm.NewContract(&dummyContractRef{}, &dummyContractRef{}, new(uint256.Int), 100000)
+		)
+		// store a few slots and revert with some data
+		for i := byte(0); i < 10; i++ {
+			contract.Code = append(contract.Code, byte(vm.PUSH1), i, byte(vm.PUSH1), i, byte(vm.SSTORE))
+		}
+		contract.Code = append(contract.Code, byte(vm.PUSH1), 0x20, byte(vm.PUSH1), 0x0, byte(vm.REVERT))
+
+		logger.OnTxStart(env.GetVMContext(), nil, common.Address{})
+		ret, err := env.Interpreter().Run(contract, []byte{}, false)
+		logger.OnExit(0, ret, 0, err, true)
+		res, err := logger.GetResult()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return logger, res
+	}
+	_, want := trace(&Config{EnableMemory: true})
+	logger, have := trace(&Config{EnableMemory: true, MemoryLimit: 1024})
+	if logger.spill == nil || logger.spill.count() == 0 {
+		t.Fatal("logs were not spilled")
+	}
+	if string(have) != string(want) {
+		t.Fatalf("trace mismatch:\nhave %s\nwant %s", have, want)
+	}
+	name := logger.spill.file.Name()
+	logger.Reset()
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Fatalf("spill file not removed: %v", err)
+	}
+}
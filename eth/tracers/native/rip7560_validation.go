@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/holiman/uint256"
 	"math/big"
 	"regexp"
@@ -48,6 +50,8 @@ type entryPointCall struct {
 	Opcodes               map[string]uint64                   `json:"opcodes"`
 	ExtCodeAccessInfo     map[common.Address]string           `json:"extCodeAccessInfo"`
 	ContractSize          map[common.Address]*contractSizeVal `json:"contractSize"`
+	BannedAccess          map[common.Address]string           `json:"bannedAccess"`
+	BannedOpcodes         map[string]uint64                   `json:"bannedOpcodes"`
 	OOG                   bool                                `json:"oog"`
 }
 
@@ -55,13 +59,27 @@ type entryPointCall struct {
 
 const ValidationFramesMaxCount = 3
 
+type rip7560ValidationTracerConfig struct {
+	// BannedAddresses overrides the set of system contracts validation frames
+	// are not allowed to access. It is intended for testing only.
+	BannedAddresses *[]common.Address `json:"bannedAddresses,omitempty"`
+}
+
 func newRip7560Tracer(ctx *tracers.Context, cfg json.RawMessage) (*tracers.Tracer, error) {
-	var config prestateTracerConfig
+	var config rip7560ValidationTracerConfig
 	if cfg != nil {
 		if err := json.Unmarshal(cfg, &config); err != nil {
 			return nil, err
 		}
 	}
+	banned := core.DefaultRip7560BannedAddresses
+	if config.BannedAddresses != nil {
+		banned = *config.BannedAddresses
+	}
+	bannedAddresses := make(map[common.Address]struct{}, len(banned))
+	for _, addr := range banned {
+		bannedAddresses[addr] = struct{}{}
+	}
 	allowedOpcodeRegex, err := regexp.Compile(
 		`^(DUP\d+|PUSH\d+|SWAP\d+|POP|ADD|SUB|MUL|DIV|EQ|LTE?|S?GTE?|SLT|SH[LR]|AND|OR|NOT|ISZERO)$`,
 	)
@@ -76,6 +94,8 @@ func newRip7560Tracer(ctx *tracers.Context, cfg json.RawMessage) (*tracers.Trace
 		//Deleted:      make([]map[common.Address]bool, ValidationFramesMaxCount),
 
 		allowedOpcodeRegex: allowedOpcodeRegex,
+		bannedAddresses:    bannedAddresses,
+		bannedOpcodes:      new(params.ChainConfig).Rip7560BannedOpcodes(),
 		lastThreeOpCodes:   make([]*lastThreeOpCodesItem, 0),
 		CurrentLevel:       nil,
 		lastOp:             "",
@@ -129,7 +149,9 @@ type rip7560ValidationTracer struct {
 	//Deleted      []map[common.Address]bool `json:"deleted"`
 
 	lastThreeOpCodes    []*lastThreeOpCodesItem
-	allowedOpcodeRegex  *regexp.Regexp `json:"allowedOpcodeRegex,omitempty"`
+	allowedOpcodeRegex  *regexp.Regexp
+	bannedAddresses     map[common.Address]struct{}
+	bannedOpcodes       map[string]struct{}
 	CurrentLevel        *entryPointCall
 	lastOp              string
 	CallsFromEntryPoint []*entryPointCall `json:"callsFromEntryPoint,omitempty"`
@@ -146,6 +168,7 @@ func (b *rip7560ValidationTracer) OnEnter(depth int, typ byte, from common.Addre
 	if depth == 0 {
 		b.createNewTopLevelFrame(to)
 	}
+	b.checkBannedAccess(to, vm.OpCode(typ).String())
 	b.Calls = append(b.Calls, &callsItem{
 		Type: vm.OpCode(typ).String(),
 		From: from,
@@ -171,6 +194,9 @@ func (b *rip7560ValidationTracer) OnExit(depth int, output []byte, gasUsed uint6
 
 func (b *rip7560ValidationTracer) OnTxStart(env *tracing.VMContext, tx *types.Transaction, from common.Address) {
 	b.env = env
+	if env.ChainConfig != nil {
+		b.bannedOpcodes = env.ChainConfig.Rip7560BannedOpcodes()
+	}
 	//b.rip7560TxData = tx.Rip7560TransactionData()
 }
 
@@ -181,6 +207,8 @@ func (b *rip7560ValidationTracer) createNewTopLevelFrame(addr common.Address) {
 		Opcodes:               map[string]uint64{},
 		ExtCodeAccessInfo:     map[common.Address]string{},
 		ContractSize:          map[common.Address]*contractSizeVal{},
+		BannedAccess:          map[common.Address]string{},
+		BannedOpcodes:         map[string]uint64{},
 		OOG:                   false,
 	}
 	b.CallsFromEntryPoint = append(b.CallsFromEntryPoint, b.CurrentLevel)
@@ -257,6 +285,7 @@ func (b *rip7560ValidationTracer) OnOpcode(pc uint64, op byte, gas, cost uint64,
 			n = 1
 		}
 		addr := common.BytesToAddress(StackBack(scope.StackData(), n).Bytes())
+		b.checkBannedAccess(addr, opcode)
 
 		if _, ok := b.CurrentLevel.ContractSize[addr]; !ok && !b.isAllowedPrecompile(addr) {
 			b.CurrentLevel.ContractSize[addr] = &contractSizeVal{
@@ -269,6 +298,11 @@ func (b *rip7560ValidationTracer) OnOpcode(pc uint64, op byte, gas, cost uint64,
 	// [OP-012]
 	if b.lastOp == "GAS" && !strings.Contains(opcode, "CALL") {
 		b.incrementCount(b.CurrentLevel.Opcodes, "GAS")
+		b.checkBannedOpcode("GAS")
+	}
+	// [OP-011]
+	if opcode != "GAS" {
+		b.checkBannedOpcode(opcode)
 	}
 	// ignore "unimportant" opcodes
 	if opcode != "GAS" && !b.allowedOpcodeRegex.MatchString(opcode) {
@@ -352,6 +386,25 @@ func (b *rip7560ValidationTracer) isEXTorCALL(opcode string) bool {
 		opcode == "STATICCALL"
 }
 
+// checkBannedAccess records an interaction of the current frame with one of the
+// banned system contracts. Only the first opcode used per address is kept.
+func (b *rip7560ValidationTracer) checkBannedAccess(addr common.Address, opcode string) {
+	if _, ok := b.bannedAddresses[addr]; !ok || b.CurrentLevel == nil {
+		return
+	}
+	if _, ok := b.CurrentLevel.BannedAccess[addr]; !ok {
+		b.CurrentLevel.BannedAccess[addr] = opcode
+	}
+}
+
+// checkBannedOpcode counts the uses of opcodes banned by the chain configuration
+// in the current frame.
+func (b *rip7560ValidationTracer) checkBannedOpcode(opcode string) {
+	if _, ok := b.bannedOpcodes[opcode]; ok {
+		b.incrementCount(b.CurrentLevel.BannedOpcodes, opcode)
+	}
+}
+
 // not using 'isPrecompiled' to only allow the ones defined by the ERC-7562 as stateless precompiles
 // [OP-062]
 func (b *rip7560ValidationTracer) isAllowedPrecompile(addr common.Address) bool {
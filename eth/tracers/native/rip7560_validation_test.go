@@ -0,0 +1,57 @@
+package native
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func traceBannedAccess(t *testing.T, cfg json.RawMessage, targets ...common.Address) map[common.Address]string {
+	t.Helper()
+
+	tracer, err := newRip7560Tracer(&tracers.Context{}, cfg)
+	if err != nil {
+		t.Fatalf("failed to create tracer: %v", err)
+	}
+	sender := common.Address{0xaa}
+	tracer.OnEnter(0, byte(vm.CALL), common.Address{}, sender, nil, 100000, big.NewInt(0))
+	for _, target := range targets {
+		tracer.OnEnter(1, byte(vm.STATICCALL), sender, target, nil, 1000, nil)
+	}
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to get result: %v", err)
+	}
+	var result struct {
+		CallsFromEntryPoint []*entryPointCall `json:"callsFromEntryPoint"`
+	}
+	if err := json.Unmarshal(res, &result); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	return result.CallsFromEntryPoint[0].BannedAccess
+}
+
+// Tests that calls from validation frames into system contracts are reported,
+// and that the banned set can be overridden through the tracer config.
+func TestRip7560BannedSystemContracts(t *testing.T) {
+	other := common.Address{0xbb}
+
+	banned := traceBannedAccess(t, nil, params.BeaconRootsAddress, other)
+	if len(banned) != 1 || banned[params.BeaconRootsAddress] != "STATICCALL" {
+		t.Fatalf("unexpected banned access with default config: %v", banned)
+	}
+	banned = traceBannedAccess(t, json.RawMessage(`{"bannedAddresses":[]}`), params.BeaconRootsAddress, other)
+	if len(banned) != 0 {
+		t.Fatalf("unexpected banned access with empty override: %v", banned)
+	}
+	cfg, _ := json.Marshal(rip7560ValidationTracerConfig{BannedAddresses: &[]common.Address{other}})
+	banned = traceBannedAccess(t, cfg, params.BeaconRootsAddress, other)
+	if len(banned) != 1 || banned[other] != "STATICCALL" {
+		t.Fatalf("unexpected banned access with custom override: %v", banned)
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/internal/rpcusage"
+)
+
+// rip7560FrameTrace is the result of tracing a single frame of a RIP-7560
+// transaction.
+type rip7560FrameTrace struct {
+	Frame  string          `json:"frame"`
+	Result json.RawMessage `json:"result"`
+}
+
+// rip7560FrameTracer traces every frame of a RIP-7560 transaction with its own
+// instance of a tracer. Each frame is presented to its tracer as a transaction
+// calling the frame target with the frame gas limit, so that call and state
+// tracers give a result per frame.
+type rip7560FrameTracer struct {
+	newTracer func() (*Tracer, error)
+	env       *tracing.VMContext
+
+	frame   string  // Name of the frame being traced
+	tracer  *Tracer // Tracer of the frame, nil between frames
+	started bool    // Whether the frame tracer has seen the start of the frame
+
+	frames []rip7560FrameTrace
+	err    error
+	mu     sync.Mutex // Guards tracer and reason against Stop
+	reason error
+}
+
+func newRip7560FrameTracer(newTracer func() (*Tracer, error)) *rip7560FrameTracer {
+	return &rip7560FrameTracer{newTracer: newTracer, frames: make([]rip7560FrameTrace, 0)}
+}
+
+// Hooks returns the hooks dispatching the events of the transaction to the
+// tracer of the current frame.
+func (t *rip7560FrameTracer) Hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnTxStart:       t.OnTxStart,
+		OnAAFrameStart:  t.OnAAFrameStart,
+		OnAAFrameEnd:    t.OnAAFrameEnd,
+		OnEnter:         t.OnEnter,
+		OnExit:          t.OnExit,
+		OnOpcode:        t.OnOpcode,
+		OnFault:         t.OnFault,
+		OnGasChange:     t.OnGasChange,
+		OnBalanceChange: t.OnBalanceChange,
+		OnNonceChange:   t.OnNonceChange,
+		OnCodeChange:    t.OnCodeChange,
+		OnStorageChange: t.OnStorageChange,
+		OnLog:           t.OnLog,
+	}
+}
+
+func (t *rip7560FrameTracer) OnTxStart(env *tracing.VMContext, tx *types.Transaction, from common.Address) {
+	t.env = env
+}
+
+func (t *rip7560FrameTracer) OnAAFrameStart(frame string, to common.Address, input []byte, gasLimit uint64) {
+	tracer, err := t.newTracer()
+	if err != nil {
+		if t.err == nil {
+			t.err = err
+		}
+		return
+	}
+	t.mu.Lock()
+	t.frame, t.tracer, t.started = frame, tracer, false
+	if t.reason != nil {
+		tracer.Stop(t.reason)
+	}
+	t.mu.Unlock()
+}
+
+func (t *rip7560FrameTracer) OnAAFrameEnd(frame string, output []byte, gasUsed uint64, err error) {
+	if t.tracer == nil {
+		return
+	}
+	if t.started && t.tracer.OnTxEnd != nil {
+		t.tracer.OnTxEnd(&types.Receipt{GasUsed: gasUsed}, nil)
+	}
+	result, err := t.tracer.GetResult()
+	if err != nil && t.err == nil {
+		t.err = fmt.Errorf("frame %s: %w", frame, err)
+	}
+	t.frames = append(t.frames, rip7560FrameTrace{Frame: frame, Result: result})
+
+	t.mu.Lock()
+	t.tracer = nil
+	t.mu.Unlock()
+}
+
+func (t *rip7560FrameTracer) OnEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	if t.tracer == nil {
+		return
+	}
+	// The frame starts with the call of the protocol to the frame target
+	if depth == 0 && !t.started {
+		t.started = true
+		if t.tracer.OnTxStart != nil && t.env != nil {
+			tx := types.NewTx(&types.LegacyTx{To: &to, Gas: gas, Data: input, Value: value, GasPrice: t.env.GasPrice})
+			t.tracer.OnTxStart(t.env, tx, from)
+		}
+	}
+	if t.tracer.OnEnter != nil {
+		t.tracer.OnEnter(depth, typ, from, to, input, gas, value)
+	}
+}
+
+func (t *rip7560FrameTracer) OnExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	if t.tracer != nil && t.tracer.OnExit != nil {
+		t.tracer.OnExit(depth, output, gasUsed, err, reverted)
+	}
+}
+
+func (t *rip7560FrameTracer) OnOpcode(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+	if t.tracer != nil && t.tracer.OnOpcode != nil {
+		t.tracer.OnOpcode(pc, op, gas, cost, scope, rData, depth, err)
+	}
+}
+
+func (t *rip7560FrameTracer) OnFault(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, depth int, err error) {
+	if t.tracer != nil && t.tracer.OnFault != nil {
+		t.tracer.OnFault(pc, op, gas, cost, scope, depth, err)
+	}
+}
+
+func (t *rip7560FrameTracer) OnGasChange(old, new uint64, reason tracing.GasChangeReason) {
+	if t.tracer != nil && t.tracer.OnGasChange != nil {
+		t.tracer.OnGasChange(old, new, reason)
+	}
+}
+
+func (t *rip7560FrameTracer) OnBalanceChange(addr common.Address, prev, new *big.Int, reason tracing.BalanceChangeReason) {
+	if t.tracer != nil && t.tracer.OnBalanceChange != nil {
+		t.tracer.OnBalanceChange(addr, prev, new, reason)
+	}
+}
+
+func (t *rip7560FrameTracer) OnNonceChange(addr common.Address, prev, new uint64) {
+	if t.tracer != nil && t.tracer.OnNonceChange != nil {
+		t.tracer.OnNonceChange(addr, prev, new)
+	}
+}
+
+func (t *rip7560FrameTracer) OnCodeChange(addr common.Address, prevCodeHash common.Hash, prevCode []byte, codeHash common.Hash, code []byte) {
+	if t.tracer != nil && t.tracer.OnCodeChange != nil {
+		t.tracer.OnCodeChange(addr, prevCodeHash, prevCode, codeHash, code)
+	}
+}
+
+func (t *rip7560FrameTracer) OnStorageChange(addr common.Address, slot common.Hash, prev, new common.Hash) {
+	if t.tracer != nil && t.tracer.OnStorageChange != nil {
+		t.tracer.OnStorageChange(addr, slot, prev, new)
+	}
+}
+
+func (t *rip7560FrameTracer) OnLog(log *types.Log) {
+	if t.tracer != nil && t.tracer.OnLog != nil {
+		t.tracer.OnLog(log)
+	}
+}
+
+// GetResult returns the traces of the frames, in the order they ran.
+func (t *rip7560FrameTracer) GetResult() (json.RawMessage, error) {
+	t.mu.Lock()
+	reason := t.reason
+	t.mu.Unlock()
+	if reason != nil {
+		return nil, reason
+	}
+	if t.err != nil {
+		return nil, t.err
+	}
+	return json.Marshal(t.frames)
+}
+
+// Stop terminates the tracing of the current frame and of the following ones.
+func (t *rip7560FrameTracer) Stop(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reason = err
+	if t.tracer != nil {
+		t.tracer.Stop(err)
+	}
+}
+
+// traceRip7560Tx replays a RIP-7560 transaction, returning the result of the
+// configured tracer for each of its frames.
+func (api *API) traceRip7560Tx(ctx context.Context, tx *types.Transaction, txctx *Context, vmctx vm.BlockContext, statedb *state.StateDB, header *types.Header, config *TraceConfig) (interface{}, error) {
+	var (
+		err     error
+		timeout = defaultTraceTimeout
+		usedGas uint64
+		resets  []func()
+	)
+	if config == nil {
+		config = &TraceConfig{}
+	}
+	defer func() {
+		for _, reset := range resets {
+			reset()
+		}
+	}()
+	tracer := newRip7560FrameTracer(func() (*Tracer, error) {
+		if config.Tracer == nil {
+			tracer, logger := newStructLogTracer(config)
+			resets = append(resets, logger.Reset)
+			return tracer, nil
+		}
+		return DefaultDirectory.New(*config.Tracer, txctx, config.TracerConfig)
+	})
+	hooks := tracer.Hooks()
+	vmenv := vm.NewEVM(vmctx, vm.TxContext{GasPrice: tx.GasPrice()}, statedb, api.backend.ChainConfig(), vm.Config{Tracer: hooks, NoBaseFee: true})
+	statedb.SetLogger(hooks)
+
+	// Define a meaningful timeout of a single transaction trace
+	if config.Timeout != nil {
+		if timeout, err = time.ParseDuration(*config.Timeout); err != nil {
+			return nil, err
+		}
+	}
+	deadlineCtx, cancel := context.WithTimeout(ctx, timeout)
+	go func() {
+		<-deadlineCtx.Done()
+		if errors.Is(deadlineCtx.Err(), context.DeadlineExceeded) {
+			tracer.Stop(errors.New("execution timeout"))
+			// Stop evm execution. Note cancellation is not necessarily immediate.
+			vmenv.Cancel()
+		}
+	}()
+	defer cancel()
+
+	gp := new(core.GasPool).AddGas(header.GasLimit)
+	if _, err := core.ApplyRip7560TransactionWithEVM(vmenv, gp, statedb, header, txctx.BlockHash, tx, txctx.TxIndex, &usedGas); err != nil {
+		return nil, fmt.Errorf("tracing failed: %w", err)
+	}
+	rpcusage.Record(ctx, usedGas, tx.Size())
+	return tracer.GetResult()
+}
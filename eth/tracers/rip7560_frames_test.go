@@ -0,0 +1,169 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers/logger"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// frameTestTrace is the result of the frameTest tracer, made of the call of
+// the transaction it is presented with and the calls it sees.
+type frameTestTrace struct {
+	To      common.Address `json:"to"`
+	Gas     uint64         `json:"gas"`
+	From    common.Address `json:"from"`
+	Calls   int            `json:"calls"`
+	GasUsed uint64         `json:"gasUsed"`
+}
+
+func init() {
+	DefaultDirectory.Register("frameTest", func(ctx *Context, cfg json.RawMessage) (*Tracer, error) {
+		trace := new(frameTestTrace)
+		return &Tracer{
+			Hooks: &tracing.Hooks{
+				OnTxStart: func(env *tracing.VMContext, tx *types.Transaction, from common.Address) {
+					trace.To, trace.Gas, trace.From = *tx.To(), tx.Gas(), from
+				},
+				OnTxEnd: func(receipt *types.Receipt, err error) {
+					trace.GasUsed = receipt.GasUsed
+				},
+				OnEnter: func(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+					trace.Calls++
+				},
+			},
+			GetResult: func() (json.RawMessage, error) { return json.Marshal(trace) },
+			Stop:      func(err error) {},
+		}, nil
+	}, false)
+}
+
+// rip7560AccountCode returns the code of an account accepting any transaction
+// by calling acceptAccount on the EntryPoint.
+func rip7560AccountCode() []byte {
+	sel := core.Rip7560Abi.Methods["acceptAccount"].ID
+	code := []byte{byte(vm.PUSH4), sel[0], sel[1], sel[2], sel[3], byte(vm.PUSH1), 0xe0, byte(vm.SHL), byte(vm.PUSH1), 0, byte(vm.MSTORE),
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0x44, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH20)}
+	code = append(code, core.AA_ENTRY_POINT.Bytes()...)
+	return append(code, byte(vm.GAS), byte(vm.CALL), byte(vm.STOP))
+}
+
+// Tests that tracing a RIP-7560 transaction gives a trace per frame, replaying
+// the transactions preceding it in the block.
+func TestTraceRip7560Transaction(t *testing.T) {
+	t.Parallel()
+
+	config := *params.TestChainConfig
+	config.RIP7560Block = big.NewInt(0)
+
+	accounts := newAccounts(1)
+	sender := common.Address{0xaa}
+	genesis := &core.Genesis{
+		Config: &config,
+		Alloc: types.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			sender:           {Balance: big.NewInt(params.Ether), Code: rip7560AccountCode()},
+		},
+	}
+	var target common.Hash
+	backend := newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(0, common.Address{0xbb}, big.NewInt(1), params.TxGas, b.BaseFee(), nil), types.LatestSigner(&config), accounts[0].key)
+		b.AddTx(tx)
+		for nonce := uint64(0); nonce < 2; nonce++ {
+			tx := types.NewTx(&types.Rip7560AccountAbstractionTx{
+				ChainID:            config.ChainID,
+				NonceKey:           new(big.Int),
+				Nonce:              nonce,
+				GasTipCap:          big.NewInt(1),
+				GasFeeCap:          b.BaseFee(),
+				Gas:                100_000,
+				Sender:             &sender,
+				ExecutionData:      []byte{0x01},
+				ValidationGasLimit: 100_000,
+			})
+			b.AddTx(tx)
+			target = tx.Hash()
+		}
+	})
+	defer backend.teardown()
+	api := NewAPI(backend)
+
+	tracer := "frameTest"
+	result, err := api.TraceTransaction(context.Background(), target, &TraceConfig{Tracer: &tracer})
+	if err != nil {
+		t.Fatalf("failed to trace transaction: %v", err)
+	}
+	var frames []struct {
+		Frame  string
+		Result frameTestTrace
+	}
+	if err := json.Unmarshal(result.(json.RawMessage), &frames); err != nil {
+		t.Fatalf("failed to unmarshal result: %v", err)
+	}
+	if len(frames) != 2 || frames[0].Frame != core.FrameAccount || frames[1].Frame != "execution" {
+		t.Fatalf("frames mismatch: %+v", frames)
+	}
+	for _, frame := range frames {
+		if frame.Result.To != sender || frame.Result.From != core.AA_ENTRY_POINT {
+			t.Errorf("frame %s: call mismatch: have %v -> %v", frame.Frame, frame.Result.From, frame.Result.To)
+		}
+		if frame.Result.Gas == 0 || frame.Result.GasUsed == 0 {
+			t.Errorf("frame %s: gas not traced: limit %d, used %d", frame.Frame, frame.Result.Gas, frame.Result.GasUsed)
+		}
+	}
+	// The account calls the EntryPoint in both frames
+	if have := []int{frames[0].Result.Calls, frames[1].Result.Calls}; !reflect.DeepEqual(have, []int{2, 2}) {
+		t.Errorf("calls mismatch: have %v", have)
+	}
+
+	// Tracing the block traces the frames of its RIP-7560 transactions
+	results, err := api.TraceBlockByNumber(context.Background(), 1, &TraceConfig{Tracer: &tracer})
+	if err != nil {
+		t.Fatalf("failed to trace block: %v", err)
+	}
+	if len(results) != 3 || results[2].TxHash != target || !bytes.Equal(results[2].Result.(json.RawMessage), result.(json.RawMessage)) {
+		t.Errorf("block trace mismatch: %+v", results)
+	}
+
+	// The struct logger is the default tracer of every frame
+	result, err = api.TraceTransaction(context.Background(), target, nil)
+	if err != nil {
+		t.Fatalf("failed to trace transaction: %v", err)
+	}
+	var logs []struct {
+		Frame  string
+		Result logger.ExecutionResult
+	}
+	if err := json.Unmarshal(result.(json.RawMessage), &logs); err != nil {
+		t.Fatalf("failed to unmarshal result: %v", err)
+	}
+	if len(logs) != 2 || len(logs[0].Result.StructLogs) == 0 {
+		t.Errorf("struct logs mismatch: %+v", logs)
+	}
+}
@@ -0,0 +1,321 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package aaclient provides an RPC client for the RIP-7560 account abstraction
+// APIs of geth.
+package aaclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Client is a wrapper around rpc.Client that implements the `aa` namespace.
+//
+// If you want to use the standardized Ethereum RPC functionality, use ethclient.Client instead.
+type Client struct {
+	c *rpc.Client
+}
+
+// Dial connects a client to the given URL.
+func Dial(rawurl string) (*Client, error) {
+	return DialContext(context.Background(), rawurl)
+}
+
+// DialContext connects a client to the given URL with context.
+func DialContext(ctx context.Context, rawurl string) (*Client, error) {
+	c, err := rpc.DialContext(ctx, rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return New(c), nil
+}
+
+// New creates a client that uses the given RPC client.
+func New(c *rpc.Client) *Client {
+	return &Client{c}
+}
+
+// Close closes the underlying RPC connection.
+func (ac *Client) Close() {
+	ac.c.Close()
+}
+
+// Client gets the underlying RPC client.
+func (ac *Client) Client() *rpc.Client {
+	return ac.c
+}
+
+// Config describes the RIP-7560 system contracts and fork status of a node.
+type Config struct {
+	EntryPoint         common.Address
+	SenderCreator      common.Address
+	NonceManager       common.Address
+	DepositManager     *common.Address // Set if paymasters pay for gas from their deposits
+	StakeRegistry      *common.Address
+	ActivationBlock    *big.Int
+	ActivationTime     *uint64
+	NonceManagerBlock  *big.Int
+	NonceManagerTime   *uint64
+	Active             bool
+	NonceManagerActive bool
+	AbiVersion         uint64
+	RulesVersion       uint64
+	RulesHash          [4]byte
+
+	// Submission is set if the node accepts RIP-7560 transactions, while
+	// BundleSubmission is set if it also accepts bundles pushed by bundlers.
+	// A node offering neither only serves included transactions and receipts.
+	Submission       bool
+	BundleSubmission bool
+}
+
+// Config returns the RIP-7560 configuration of the node.
+func (ac *Client) Config(ctx context.Context) (*Config, error) {
+	var result struct {
+		EntryPoint         common.Address  `json:"entryPoint"`
+		SenderCreator      common.Address  `json:"senderCreator"`
+		NonceManager       common.Address  `json:"nonceManager"`
+		DepositManager     *common.Address `json:"depositManager"`
+		StakeRegistry      *common.Address `json:"stakeRegistry"`
+		ActivationBlock    *hexutil.Big    `json:"activationBlock"`
+		ActivationTime     *hexutil.Uint64 `json:"activationTime"`
+		NonceManagerBlock  *hexutil.Big    `json:"nonceManagerActivationBlock"`
+		NonceManagerTime   *hexutil.Uint64 `json:"nonceManagerActivationTime"`
+		Active             bool            `json:"active"`
+		NonceManagerActive bool            `json:"nonceManagerActive"`
+		AbiVersion         hexutil.Uint64  `json:"abiVersion"`
+		RulesVersion       hexutil.Uint64  `json:"rulesVersion"`
+		RulesHash          hexutil.Bytes   `json:"rulesHash"`
+		Capabilities       struct {
+			Submission       bool `json:"submission"`
+			BundleSubmission bool `json:"bundleSubmission"`
+		} `json:"capabilities"`
+	}
+	if err := ac.c.CallContext(ctx, &result, "aa_getConfig"); err != nil {
+		return nil, err
+	}
+	return &Config{
+		EntryPoint:         result.EntryPoint,
+		SenderCreator:      result.SenderCreator,
+		NonceManager:       result.NonceManager,
+		DepositManager:     result.DepositManager,
+		StakeRegistry:      result.StakeRegistry,
+		ActivationBlock:    (*big.Int)(result.ActivationBlock),
+		ActivationTime:     (*uint64)(result.ActivationTime),
+		NonceManagerBlock:  (*big.Int)(result.NonceManagerBlock),
+		NonceManagerTime:   (*uint64)(result.NonceManagerTime),
+		Active:             result.Active,
+		NonceManagerActive: result.NonceManagerActive,
+		AbiVersion:         uint64(result.AbiVersion),
+		RulesVersion:       uint64(result.RulesVersion),
+		RulesHash:          [4]byte(common.RightPadBytes(result.RulesHash, 4)),
+		Submission:         result.Capabilities.Submission,
+		BundleSubmission:   result.Capabilities.BundleSubmission,
+	}, nil
+}
+
+// SigningPayload contains the hashes a RIP-7560 transaction may be authorized with.
+type SigningPayload struct {
+	SigningHash   common.Hash         `json:"signingHash"`
+	TypedData     *apitypes.TypedData `json:"typedData"`
+	TypedDataHash common.Hash         `json:"typedDataHash"`
+}
+
+// SigningPayload returns the consensus signing hash and the EIP-712 typed data
+// of the given RIP-7560 transaction.
+func (ac *Client) SigningPayload(ctx context.Context, tx *types.Transaction) (*SigningPayload, error) {
+	arg, err := toTxArg(tx)
+	if err != nil {
+		return nil, err
+	}
+	var result SigningPayload
+	if err := ac.c.CallContext(ctx, &result, "aa_getSigningPayload", arg); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ValidateTransaction simulates the validation phase of the given RIP-7560
+// transaction on top of the given block. If blockNumber is nil, the latest
+// known block is used.
+//
+// A transaction failing validation is not an error of the call itself, the
+// reason is reported by the Error field of the returned report.
+func (ac *Client) ValidateTransaction(ctx context.Context, tx *types.Transaction, blockNumber *big.Int) (*types.ValidationReport, error) {
+	arg, err := toTxArg(tx)
+	if err != nil {
+		return nil, err
+	}
+	var result types.ValidationReport
+	if err := ac.c.CallContext(ctx, &result, "aa_validateTransaction", arg, toBlockNumArg(blockNumber)); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// EstimateGas returns the lowest gas limits of the frames of the given RIP-7560
+// transaction allowing it to succeed on top of the given block. The limits set
+// in the transaction bound the search, while missing ones are replaced by the
+// gas cap of the node. If blockNumber is nil, the latest known block is used.
+func (ac *Client) EstimateGas(ctx context.Context, tx *types.Transaction, blockNumber *big.Int) (*types.Rip7560FrameGas, error) {
+	arg, err := toTxArg(tx)
+	if err != nil {
+		return nil, err
+	}
+	var result types.Rip7560FrameGas
+	if err := ac.c.CallContext(ctx, &result, "aa_estimateGas", arg, toBlockNumArg(blockNumber)); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Call runs both phases of the given RIP-7560 transaction on top of the given
+// block without committing it, returning the output of its execution, its logs
+// and the gas used by its frames. Missing gas limits are replaced by the gas cap
+// of the node. If blockNumber is nil, the latest known block is used.
+func (ac *Client) Call(ctx context.Context, tx *types.Transaction, blockNumber *big.Int) (*types.Rip7560CallResult, error) {
+	arg, err := toTxArg(tx)
+	if err != nil {
+		return nil, err
+	}
+	var result types.Rip7560CallResult
+	if err := ac.c.CallContext(ctx, &result, "aa_call", arg, toBlockNumArg(blockNumber)); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// NonceAt returns the nonce of the given sender for the given RIP-7712 nonce key
+// at the given block. A nil or zero key returns the legacy account nonce. If
+// blockNumber is nil, the latest known block is used.
+func (ac *Client) NonceAt(ctx context.Context, sender common.Address, key *big.Int, blockNumber *big.Int) (uint64, error) {
+	var result hexutil.Uint64
+	if err := ac.c.CallContext(ctx, &result, "aa_getNonce", sender, (*hexutil.Big)(key), toBlockNumArg(blockNumber)); err != nil {
+		return 0, err
+	}
+	return uint64(result), nil
+}
+
+// DepositAt returns the gas deposit of the given paymaster held by the RIP-7560
+// DepositManager at the given block. If blockNumber is nil, the latest known
+// block is used.
+func (ac *Client) DepositAt(ctx context.Context, paymaster common.Address, blockNumber *big.Int) (*big.Int, error) {
+	var result hexutil.Big
+	if err := ac.c.CallContext(ctx, &result, "aa_getDeposit", paymaster, toBlockNumArg(blockNumber)); err != nil {
+		return nil, err
+	}
+	return (*big.Int)(&result), nil
+}
+
+// ReplacementFee returns the minimal fee and tip caps of a transaction replacing
+// the given pending RIP-7560 transaction in the pool of the node.
+func (ac *Client) ReplacementFee(ctx context.Context, hash common.Hash) (feeCap *big.Int, tipCap *big.Int, err error) {
+	var result struct {
+		MaxFeePerGas         *hexutil.Big `json:"maxFeePerGas"`
+		MaxPriorityFeePerGas *hexutil.Big `json:"maxPriorityFeePerGas"`
+	}
+	if err := ac.c.CallContext(ctx, &result, "aa_getReplacementFee", hash); err != nil {
+		return nil, nil, err
+	}
+	if result.MaxFeePerGas == nil || result.MaxPriorityFeePerGas == nil {
+		return nil, nil, errors.New("missing replacement fee fields")
+	}
+	return result.MaxFeePerGas.ToInt(), result.MaxPriorityFeePerGas.ToInt(), nil
+}
+
+// SendTransaction injects a RIP-7560 transaction into the pending pool for execution.
+func (ac *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if tx.Type() != types.Rip7560Type {
+		return errors.New("not a RIP-7560 transaction")
+	}
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return ac.c.CallContext(ctx, nil, "eth_sendRawTransaction", hexutil.Encode(data))
+}
+
+func toBlockNumArg(number *big.Int) string {
+	if number == nil {
+		return "latest"
+	}
+	if number.Sign() >= 0 {
+		return hexutil.EncodeBig(number)
+	}
+	// It's negative.
+	if number.IsInt64() {
+		return rpc.BlockNumber(number.Int64()).String()
+	}
+	// It's negative and large, which is invalid.
+	return fmt.Sprintf("<invalid %d>", number)
+}
+
+// toTxArg converts a RIP-7560 transaction into the transaction arguments of
+// the `aa` namespace.
+func toTxArg(tx *types.Transaction) (map[string]interface{}, error) {
+	if tx.Type() != types.Rip7560Type {
+		return nil, errors.New("not a RIP-7560 transaction")
+	}
+	aatx := tx.Rip7560TransactionData()
+	if aatx.Sender == nil {
+		return nil, errors.New("missing sender")
+	}
+	arg := map[string]interface{}{
+		"chainId":                       (*hexutil.Big)(tx.ChainId()),
+		"sender":                        aatx.Sender,
+		"nonce":                         hexutil.Uint64(tx.Nonce()),
+		"gas":                           hexutil.Uint64(tx.Gas()),
+		"maxFeePerGas":                  (*hexutil.Big)(tx.GasFeeCap()),
+		"maxPriorityFeePerGas":          (*hexutil.Big)(tx.GasTipCap()),
+		"accessList":                    tx.AccessList(),
+		"authorizationData":             hexutil.Bytes(aatx.AuthorizationData),
+		"executionData":                 hexutil.Bytes(aatx.ExecutionData),
+		"paymasterData":                 hexutil.Bytes(aatx.PaymasterData),
+		"deployerData":                  hexutil.Bytes(aatx.DeployerData),
+		"verificationGasLimit":          hexutil.Uint64(aatx.ValidationGasLimit),
+		"paymasterVerificationGasLimit": hexutil.Uint64(aatx.PaymasterValidationGasLimit),
+		"paymasterPostOpGasLimit":       hexutil.Uint64(aatx.PostOpGas),
+	}
+	if aatx.NonceKey != nil {
+		arg["nonceKey"] = (*hexutil.Big)(aatx.NonceKey)
+	}
+	if aatx.BuilderFee != nil {
+		arg["builderFee"] = (*hexutil.Big)(aatx.BuilderFee)
+	}
+	if aatx.Paymaster != nil {
+		arg["paymaster"] = aatx.Paymaster
+	}
+	if aatx.Deployer != nil {
+		arg["deployer"] = aatx.Deployer
+	}
+	if len(aatx.ExecutionCalls) > 0 {
+		calls := make([]hexutil.Bytes, len(aatx.ExecutionCalls))
+		for i, call := range aatx.ExecutionCalls {
+			calls[i] = call
+		}
+		arg["executionCalls"] = calls
+	}
+	return arg, nil
+}
@@ -0,0 +1,243 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package aaclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/aa"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testAAService mimics the `aa` namespace, converting the received arguments
+// the same way the node does.
+type testAAService struct{}
+
+func (s *testAAService) GetConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"entryPoint":      core.AA_ENTRY_POINT,
+		"depositManager":  core.AA_DEPOSIT_MANAGER,
+		"active":          true,
+		"abiVersion":      hexutil.Uint64(aa.LatestVersion),
+		"activationBlock": (*hexutil.Big)(big.NewInt(7)),
+		"rulesHash":       hexutil.Bytes{0x01, 0x02, 0x03, 0x04},
+		"capabilities":    map[string]bool{"submission": true},
+	}
+}
+
+func (s *testAAService) ValidateTransaction(args ethapi.TransactionArgs, block *rpc.BlockNumberOrHash) *types.ValidationReport {
+	return &types.ValidationReport{
+		TxHash:         args.ToTransaction().Hash(),
+		Violations:     []string{"account frame uses banned opcode TIMESTAMP"},
+		ViolationRules: []string{"OP-011"},
+		Rules:          []string{"OP-011"},
+	}
+}
+
+func (s *testAAService) EstimateGas(args ethapi.TransactionArgs, block *rpc.BlockNumberOrHash) *types.Rip7560FrameGas {
+	return &types.Rip7560FrameGas{ValidationGas: *args.ValidationGas / 2, CallGas: *args.Gas / 2}
+}
+
+func (s *testAAService) GetNonce(sender common.Address, key *hexutil.Big, block *rpc.BlockNumberOrHash) hexutil.Uint64 {
+	if key == nil {
+		return 1
+	}
+	return hexutil.Uint64(key.ToInt().Uint64() + 1)
+}
+
+func (s *testAAService) GetDeposit(paymaster common.Address, block *rpc.BlockNumberOrHash) *hexutil.Big {
+	return (*hexutil.Big)(new(big.Int).SetBytes(paymaster[:1]))
+}
+
+func (s *testAAService) GetReplacementFee(hash common.Hash) (*ethapi.ReplacementFee, error) {
+	if hash != (common.Hash{0x01}) {
+		return nil, errors.New("transaction not found")
+	}
+	return &ethapi.ReplacementFee{
+		MaxFeePerGas:         (*hexutil.Big)(big.NewInt(110)),
+		MaxPriorityFeePerGas: (*hexutil.Big)(big.NewInt(11)),
+	}, nil
+}
+
+type testEthService struct {
+	sent *types.Transaction
+}
+
+func (s *testEthService) SendRawTransaction(input hexutil.Bytes) (common.Hash, error) {
+	s.sent = new(types.Transaction)
+	if err := s.sent.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	return s.sent.Hash(), nil
+}
+
+func newTestClient(t *testing.T) (*Client, *testEthService) {
+	t.Helper()
+
+	server := rpc.NewServer()
+	eth := new(testEthService)
+	if err := server.RegisterName("aa", new(testAAService)); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.RegisterName("eth", eth); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Stop)
+
+	client := New(rpc.DialInProc(server))
+	t.Cleanup(client.Close)
+	return client, eth
+}
+
+func newTestTx() *types.Transaction {
+	return types.NewTx(&types.Rip7560AccountAbstractionTx{
+		ChainID:            big.NewInt(1337),
+		Nonce:              3,
+		NonceKey:           big.NewInt(5),
+		GasTipCap:          big.NewInt(1),
+		GasFeeCap:          big.NewInt(2),
+		Gas:                100_000,
+		Sender:             &common.Address{0xaa},
+		AuthorizationData:  []byte{0x01},
+		Paymaster:          &common.Address{0xbb},
+		PaymasterData:      []byte{0x02},
+		BuilderFee:         big.NewInt(0),
+		ExecutionCalls:     [][]byte{{0x03}, {0x04}},
+		ValidationGasLimit: 50_000,
+
+		PaymasterValidationGasLimit: 40_000,
+		PostOpGas:                   30_000,
+	})
+}
+
+// Tests that transactions are converted into arguments the node decodes back
+// into the same transaction.
+func TestValidateTransaction(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	tx := newTestTx()
+	report, err := client.ValidateTransaction(context.Background(), tx, nil)
+	if err != nil {
+		t.Fatalf("failed to validate: %v", err)
+	}
+	if report.TxHash != tx.Hash() {
+		t.Fatalf("transaction mismatch: have %x, want %x", report.TxHash, tx.Hash())
+	}
+	err = report.Err()
+	if !errors.Is(err, types.ErrValidationRulesViolation) || !strings.Contains(err.Error(), "[OP-011] account frame uses banned opcode TIMESTAMP") {
+		t.Fatalf("report error mismatch: have %v", err)
+	}
+}
+
+func TestEstimateGas(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	tx := newTestTx()
+	gas, err := client.EstimateGas(context.Background(), tx, nil)
+	if err != nil {
+		t.Fatalf("failed to estimate gas: %v", err)
+	}
+	aatx := tx.Rip7560TransactionData()
+	if uint64(gas.ValidationGas) != aatx.ValidationGasLimit/2 || uint64(gas.CallGas) != aatx.Gas/2 {
+		t.Fatalf("estimate mismatch: %+v", gas)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	config, err := client.Config(context.Background())
+	if err != nil {
+		t.Fatalf("failed to get config: %v", err)
+	}
+	if config.EntryPoint != core.AA_ENTRY_POINT || !config.Active || config.ActivationBlock.Uint64() != 7 || config.RulesHash != [4]byte{0x01, 0x02, 0x03, 0x04} {
+		t.Fatalf("unexpected config: %+v", config)
+	}
+	if !config.Submission || config.BundleSubmission {
+		t.Fatalf("unexpected capabilities: %+v", config)
+	}
+	if config.DepositManager == nil || *config.DepositManager != core.AA_DEPOSIT_MANAGER {
+		t.Fatalf("deposit manager mismatch: have %v, want %v", config.DepositManager, core.AA_DEPOSIT_MANAGER)
+	}
+}
+
+func TestNonceAt(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	nonce, err := client.NonceAt(context.Background(), common.Address{0xaa}, big.NewInt(5), nil)
+	if err != nil {
+		t.Fatalf("failed to get nonce: %v", err)
+	}
+	if nonce != 6 {
+		t.Fatalf("nonce mismatch: have %d, want 6", nonce)
+	}
+	if nonce, _ = client.NonceAt(context.Background(), common.Address{0xaa}, nil, big.NewInt(1)); nonce != 1 {
+		t.Fatalf("legacy nonce mismatch: have %d, want 1", nonce)
+	}
+}
+
+func TestDepositAt(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	deposit, err := client.DepositAt(context.Background(), common.Address{0x2a}, nil)
+	if err != nil {
+		t.Fatalf("failed to get deposit: %v", err)
+	}
+	if deposit.Int64() != 0x2a {
+		t.Fatalf("deposit mismatch: have %v, want %d", deposit, 0x2a)
+	}
+}
+
+func TestReplacementFee(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	feeCap, tipCap, err := client.ReplacementFee(context.Background(), common.Hash{0x01})
+	if err != nil {
+		t.Fatalf("failed to get replacement fee: %v", err)
+	}
+	if feeCap.Int64() != 110 || tipCap.Int64() != 11 {
+		t.Fatalf("replacement fee mismatch: have %v/%v, want 110/11", feeCap, tipCap)
+	}
+	if _, _, err := client.ReplacementFee(context.Background(), common.Hash{0x02}); err == nil {
+		t.Fatal("replacement fee of unknown transaction returned")
+	}
+}
+
+func TestSendTransaction(t *testing.T) {
+	client, eth := newTestClient(t)
+
+	tx := newTestTx()
+	if err := client.SendTransaction(context.Background(), tx); err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+	if eth.sent == nil || eth.sent.Hash() != tx.Hash() {
+		t.Fatal("transaction not delivered")
+	}
+	legacy := types.NewTx(&types.LegacyTx{})
+	if err := client.SendTransaction(context.Background(), legacy); err == nil {
+		t.Fatal("non RIP-7560 transaction sent")
+	}
+}
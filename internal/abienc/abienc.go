@@ -0,0 +1,165 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package abienc contains allocation free encoders for the fixed ABI calls made
+// by the protocol itself, such as the RIP-7560 frame calldata.
+//
+// The encoders append to a caller provided buffer, so a buffer reused across
+// frames does not allocate once it has grown to the required size. The output
+// is identical to what the reflection based accounts/abi packer produces.
+package abienc
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// WordSize is the size of a single ABI word.
+const WordSize = 32
+
+// Selector is the 4 byte identifier of an ABI method.
+type Selector [4]byte
+
+// NewSelector computes the selector of a canonical method signature such as
+// "transfer(address,uint256)".
+func NewSelector(signature string) Selector {
+	var sel Selector
+	copy(sel[:], crypto.Keccak256([]byte(signature)))
+	return sel
+}
+
+// Pre-computed selectors of the RIP-7560 frame entry points.
+var (
+	SelectorValidateTransaction          = NewSelector("validateTransaction(uint256,bytes32,bytes)")
+	SelectorValidatePaymasterTransaction = NewSelector("validatePaymasterTransaction(uint256,bytes32,bytes)")
+	SelectorPostPaymasterTransaction     = NewSelector("postPaymasterTransaction(bool,uint256,bytes)")
+)
+
+// grow makes sure dst has room for n more bytes and returns the extended slice
+// together with the newly added, zeroed region.
+func grow(dst []byte, n int) ([]byte, []byte) {
+	l := len(dst)
+	if cap(dst)-l < n {
+		buf := make([]byte, l, 2*cap(dst)+n)
+		copy(buf, dst)
+		dst = buf
+	}
+	dst = dst[:l+n]
+	tail := dst[l:]
+	clear(tail)
+	return dst, tail
+}
+
+// AppendSelector appends a method selector to dst.
+func AppendSelector(dst []byte, sel Selector) []byte {
+	return append(dst, sel[:]...)
+}
+
+// AppendUint64 appends v as a left padded ABI word.
+func AppendUint64(dst []byte, v uint64) []byte {
+	dst, word := grow(dst, WordSize)
+	binary.BigEndian.PutUint64(word[WordSize-8:], v)
+	return dst
+}
+
+// AppendUint256 appends v as an ABI word. A nil value is encoded as zero.
+func AppendUint256(dst []byte, v *uint256.Int) []byte {
+	dst, word := grow(dst, WordSize)
+	if v != nil {
+		v.WriteToSlice(word)
+	}
+	return dst
+}
+
+// AppendBool appends v as an ABI word.
+func AppendBool(dst []byte, v bool) []byte {
+	if v {
+		return AppendUint64(dst, 1)
+	}
+	return AppendUint64(dst, 0)
+}
+
+// AppendHash appends a 32 byte value as an ABI word.
+func AppendHash(dst []byte, h common.Hash) []byte {
+	return append(dst, h[:]...)
+}
+
+// AppendAddress appends an address as a left padded ABI word.
+func AppendAddress(dst []byte, addr common.Address) []byte {
+	dst, word := grow(dst, WordSize)
+	copy(word[WordSize-common.AddressLength:], addr[:])
+	return dst
+}
+
+// AppendBytesTail appends the tail of a dynamic bytes value, i.e. its length
+// followed by the data right padded to a word boundary. The matching head
+// offset must have been written separately.
+func AppendBytesTail(dst []byte, data []byte) []byte {
+	dst = AppendUint64(dst, uint64(len(data)))
+	dst, tail := grow(dst, paddedSize(len(data)))
+	copy(tail, data)
+	return dst
+}
+
+// paddedSize rounds n up to the next multiple of the word size.
+func paddedSize(n int) int {
+	return (n + WordSize - 1) / WordSize * WordSize
+}
+
+// AppendValidateTransaction appends the calldata of the account validation
+// frame, validateTransaction(uint256 version, bytes32 txHash, bytes transaction).
+func AppendValidateTransaction(dst []byte, version uint64, txHash common.Hash, transaction []byte) []byte {
+	return appendValidation(dst, SelectorValidateTransaction, version, txHash, transaction)
+}
+
+// AppendValidatePaymasterTransaction appends the calldata of the paymaster
+// validation frame, validatePaymasterTransaction(uint256 version, bytes32 txHash,
+// bytes transaction).
+func AppendValidatePaymasterTransaction(dst []byte, version uint64, txHash common.Hash, transaction []byte) []byte {
+	return appendValidation(dst, SelectorValidatePaymasterTransaction, version, txHash, transaction)
+}
+
+func appendValidation(dst []byte, sel Selector, version uint64, txHash common.Hash, transaction []byte) []byte {
+	dst = AppendSelector(dst, sel)
+	dst = AppendUint64(dst, version)
+	dst = AppendHash(dst, txHash)
+	dst = AppendUint64(dst, 3*WordSize)
+	return AppendBytesTail(dst, transaction)
+}
+
+// AppendPostPaymasterTransaction appends the calldata of the paymaster postOp
+// frame, postPaymasterTransaction(bool success, uint256 actualGasCost, bytes context).
+func AppendPostPaymasterTransaction(dst []byte, success bool, actualGasCost *uint256.Int, context []byte) []byte {
+	dst = AppendSelector(dst, SelectorPostPaymasterTransaction)
+	dst = AppendBool(dst, success)
+	dst = AppendUint256(dst, actualGasCost)
+	dst = AppendUint64(dst, 3*WordSize)
+	return AppendBytesTail(dst, context)
+}
+
+// AppendHex appends the 0x prefixed hexadecimal encoding of src to dst.
+func AppendHex(dst []byte, src []byte) []byte {
+	const hextable = "0123456789abcdef"
+
+	dst = append(dst, '0', 'x')
+	for _, b := range src {
+		dst = append(dst, hextable[b>>4], hextable[b&0x0f])
+	}
+	return dst
+}
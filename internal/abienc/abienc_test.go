@@ -0,0 +1,97 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package abienc
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/holiman/uint256"
+)
+
+const testABI = `[
+	{"type":"function","name":"validateTransaction","inputs":[{"name":"version","type":"uint256"},{"name":"txHash","type":"bytes32"},{"name":"transaction","type":"bytes"}]},
+	{"type":"function","name":"validatePaymasterTransaction","inputs":[{"name":"version","type":"uint256"},{"name":"txHash","type":"bytes32"},{"name":"transaction","type":"bytes"}]},
+	{"type":"function","name":"postPaymasterTransaction","inputs":[{"name":"success","type":"bool"},{"name":"actualGasCost","type":"uint256"},{"name":"context","type":"bytes"}]}
+]`
+
+// Tests that the hand rolled encoders produce the same output as the reflection
+// based ABI packer.
+func TestEncodersMatchPacker(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(testABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := common.HexToHash("0xdeadbeef")
+	for _, size := range []int{0, 1, 31, 32, 33, 100} {
+		data := bytes.Repeat([]byte{0xab}, size)
+
+		want, _ := parsed.Pack("validateTransaction", big.NewInt(3), hash, data)
+		if have := AppendValidateTransaction(nil, 3, hash, data); !bytes.Equal(have, want) {
+			t.Errorf("validateTransaction size %d: have %x, want %x", size, have, want)
+		}
+		want, _ = parsed.Pack("validatePaymasterTransaction", big.NewInt(3), hash, data)
+		if have := AppendValidatePaymasterTransaction(nil, 3, hash, data); !bytes.Equal(have, want) {
+			t.Errorf("validatePaymasterTransaction size %d: have %x, want %x", size, have, want)
+		}
+		want, _ = parsed.Pack("postPaymasterTransaction", true, big.NewInt(123456), data)
+		if have := AppendPostPaymasterTransaction(nil, true, uint256.NewInt(123456), data); !bytes.Equal(have, want) {
+			t.Errorf("postPaymasterTransaction size %d: have %x, want %x", size, have, want)
+		}
+	}
+}
+
+func TestAppendHex(t *testing.T) {
+	data := []byte{0x00, 0x01, 0xab, 0xff}
+	if have, want := string(AppendHex(nil, data)), hexutil.Encode(data); have != want {
+		t.Fatalf("hex mismatch: have %s, want %s", have, want)
+	}
+}
+
+// Tests that encoding into a sufficiently large buffer does not allocate.
+func TestEncodersDoNotAllocate(t *testing.T) {
+	var (
+		buf  = make([]byte, 0, 1024)
+		hash = common.HexToHash("0xdeadbeef")
+		data = make([]byte, 200)
+		cost = uint256.NewInt(1000)
+	)
+	allocs := testing.AllocsPerRun(100, func() {
+		buf = AppendValidateTransaction(buf[:0], 0, hash, data)
+		buf = AppendPostPaymasterTransaction(buf[:0], false, cost, data)
+	})
+	if allocs != 0 {
+		t.Fatalf("encoders allocated %v times", allocs)
+	}
+}
+
+func BenchmarkAppendValidateTransaction(b *testing.B) {
+	var (
+		buf  []byte
+		hash = common.HexToHash("0xdeadbeef")
+		data = make([]byte, 500)
+	)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = AppendValidateTransaction(buf[:0], 0, hash, data)
+	}
+}
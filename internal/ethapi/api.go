@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"reflect"
 	"strings"
 	"time"
 
@@ -38,6 +39,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -97,11 +99,13 @@ type feeHistoryResult struct {
 	GasUsedRatio     []float64        `json:"gasUsedRatio"`
 	BlobBaseFee      []*hexutil.Big   `json:"baseFeePerBlobGas,omitempty"`
 	BlobGasUsedRatio []float64        `json:"blobGasUsedRatio,omitempty"`
+
+	Rip7560GasUsedRatio []float64 `json:"rip7560GasUsedRatio,omitempty"`
 }
 
 // FeeHistory returns the fee market history.
 func (s *EthereumAPI) FeeHistory(ctx context.Context, blockCount math.HexOrDecimal64, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*feeHistoryResult, error) {
-	oldest, reward, baseFee, gasUsed, blobBaseFee, blobGasUsed, err := s.b.FeeHistory(ctx, uint64(blockCount), lastBlock, rewardPercentiles)
+	oldest, reward, baseFee, gasUsed, blobBaseFee, blobGasUsed, rip7560GasUsed, err := s.b.FeeHistory(ctx, uint64(blockCount), lastBlock, rewardPercentiles)
 	if err != nil {
 		return nil, err
 	}
@@ -133,6 +137,9 @@ func (s *EthereumAPI) FeeHistory(ctx context.Context, blockCount math.HexOrDecim
 	if blobGasUsed != nil {
 		results.BlobGasUsedRatio = blobGasUsed
 	}
+	if rip7560GasUsed != nil {
+		results.Rip7560GasUsedRatio = rip7560GasUsed
+	}
 	return results, nil
 }
 
@@ -246,6 +253,105 @@ func (s *TxPoolAPI) Status() map[string]hexutil.Uint {
 	}
 }
 
+// TxPoolStats is a snapshot of the size of the transaction pool.
+type TxPoolStats struct {
+	Pending      hexutil.Uint                        `json:"pending"`
+	Queued       hexutil.Uint                        `json:"queued"`
+	PendingBytes hexutil.Uint64                      `json:"pendingBytes"`
+	QueuedBytes  hexutil.Uint64                      `json:"queuedBytes"`
+	Types        map[hexutil.Uint64]*TxPoolTypeStats `json:"types"`
+
+	// Rip7560ValidationBacklog is the number of RIP-7560 transactions admitted
+	// without simulating their validation, nil if the RIP-7560 pool is disabled.
+	Rip7560ValidationBacklog *hexutil.Uint `json:"rip7560ValidationBacklog,omitempty"`
+}
+
+// TxPoolTypeStats is the share of a transaction type in a TxPoolStats snapshot.
+type TxPoolTypeStats struct {
+	Pending hexutil.Uint   `json:"pending"`
+	Queued  hexutil.Uint   `json:"queued"`
+	Bytes   hexutil.Uint64 `json:"bytes"`
+}
+
+// newTxPoolStats counts the given pending and queued transactions.
+func newTxPoolStats(pending, queue map[common.Address][]*types.Transaction) *TxPoolStats {
+	stats := &TxPoolStats{Types: make(map[hexutil.Uint64]*TxPoolTypeStats)}
+	count := func(tx *types.Transaction, pending bool) {
+		typ := stats.Types[hexutil.Uint64(tx.Type())]
+		if typ == nil {
+			typ = new(TxPoolTypeStats)
+			stats.Types[hexutil.Uint64(tx.Type())] = typ
+		}
+		size := hexutil.Uint64(tx.Size())
+		if pending {
+			stats.Pending++
+			stats.PendingBytes += size
+			typ.Pending++
+		} else {
+			stats.Queued++
+			stats.QueuedBytes += size
+			typ.Queued++
+		}
+		typ.Bytes += size
+	}
+	for _, txs := range pending {
+		for _, tx := range txs {
+			count(tx, true)
+		}
+	}
+	for _, txs := range queue {
+		for _, tx := range txs {
+			count(tx, false)
+		}
+	}
+	return stats
+}
+
+// stats takes a snapshot of the size of the transaction pool.
+func (s *TxPoolAPI) stats() *TxPoolStats {
+	stats := newTxPoolStats(s.b.TxPoolContent())
+	if backlog, err := s.b.Rip7560ValidationBacklog(); err == nil {
+		count := hexutil.Uint(backlog)
+		stats.Rip7560ValidationBacklog = &count
+	}
+	return stats
+}
+
+// Stats creates a subscription sending a snapshot of the size of the transaction
+// pool right away, then at the given interval in milliseconds whenever it changed.
+// The interval defaults to a second and can't be lower than 100 milliseconds.
+func (s *TxPoolAPI) Stats(ctx context.Context, interval *uint64) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	period := time.Second
+	if interval != nil {
+		period = max(time.Duration(*interval)*time.Millisecond, 100*time.Millisecond)
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+
+		last := s.stats()
+		notifier.Notify(rpcSub.ID, last)
+		for {
+			select {
+			case <-ticker.C:
+				if stats := s.stats(); !reflect.DeepEqual(stats, last) {
+					notifier.Notify(rpcSub.ID, stats)
+					last = stats
+				}
+			case <-rpcSub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
 // Inspect retrieves the content of the transaction pool and flattens it into an
 // easily inspectable list.
 func (s *TxPoolAPI) Inspect() map[string]map[string]map[string]string {
@@ -655,6 +761,50 @@ func (api *BlockChainAPI) ChainId() *hexutil.Big {
 	return (*hexutil.Big)(api.b.ChainConfig().ChainID)
 }
 
+// NodeConfig describes what the node supports at the head of its chain, for
+// tooling to feature-detect it instead of parsing its version string.
+type NodeConfig struct {
+	ChainID     *hexutil.Big     `json:"chainId"`
+	Head        hexutil.Uint64   `json:"head"`
+	ActiveForks []string         `json:"activeForks"`
+	TxTypes     []hexutil.Uint64 `json:"txTypes"`
+	Rip7560     *Rip7560Rules    `json:"rip7560"`
+}
+
+// Rip7560Rules identifies the RIP-7560 rules enforced by the node.
+type Rip7560Rules struct {
+	AbiVersion   hexutil.Uint64 `json:"abiVersion"`
+	RulesVersion hexutil.Uint64 `json:"rulesVersion"`
+	RulesHash    hexutil.Bytes  `json:"rulesHash"`
+}
+
+// Config returns the forks active at the head of the chain, the transaction
+// types accepted in its blocks and, if RIP-7560 is active, the version of the
+// AA rules enforced.
+func (api *BlockChainAPI) Config() *NodeConfig {
+	var (
+		config = api.b.ChainConfig()
+		head   = api.b.CurrentHeader()
+	)
+	result := &NodeConfig{
+		ChainID:     (*hexutil.Big)(config.ChainID),
+		Head:        hexutil.Uint64(head.Number.Uint64()),
+		ActiveForks: config.ActiveForks(head.Number, head.Time),
+	}
+	for _, txType := range core.SupportedTxTypes(config, head.Number, head.Time) {
+		result.TxTypes = append(result.TxTypes, hexutil.Uint64(txType))
+	}
+	if config.IsRIP7560(head.Number, head.Time) {
+		rulesHash := core.Rip7560RulesHash(config)
+		result.Rip7560 = &Rip7560Rules{
+			AbiVersion:   hexutil.Uint64(config.Rip7560AbiVersion()),
+			RulesVersion: core.Rip7560ValidationRulesVersion,
+			RulesHash:    rulesHash[:],
+		}
+	}
+	return result
+}
+
 // BlockNumber returns the block number of the chain head.
 func (s *BlockChainAPI) BlockNumber() hexutil.Uint64 {
 	header, _ := s.b.HeaderByNumber(context.Background(), rpc.LatestBlockNumber) // latest header should always be available
@@ -1327,33 +1477,35 @@ func (s *BlockChainAPI) rpcMarshalBlock(ctx context.Context, b *types.Block, inc
 
 // RPCTransaction represents a transaction that will serialize to the RPC representation of a transaction
 type RPCTransaction struct {
-	BlockHash           *common.Hash      `json:"blockHash"`
-	BlockNumber         *hexutil.Big      `json:"blockNumber"`
-	From                common.Address    `json:"from,omitempty"`
-	Gas                 hexutil.Uint64    `json:"gas"`
-	GasPrice            *hexutil.Big      `json:"gasPrice"`
-	GasFeeCap           *hexutil.Big      `json:"maxFeePerGas,omitempty"`
-	GasTipCap           *hexutil.Big      `json:"maxPriorityFeePerGas,omitempty"`
-	MaxFeePerBlobGas    *hexutil.Big      `json:"maxFeePerBlobGas,omitempty"`
-	Hash                common.Hash       `json:"hash"`
-	Input               hexutil.Bytes     `json:"input"`
-	Nonce               hexutil.Uint64    `json:"nonce"`
-	To                  *common.Address   `json:"to,omitempty"`
-	TransactionIndex    *hexutil.Uint64   `json:"transactionIndex"`
-	Value               *hexutil.Big      `json:"value"`
-	Type                hexutil.Uint64    `json:"type"`
-	Accesses            *types.AccessList `json:"accessList,omitempty"`
-	ChainID             *hexutil.Big      `json:"chainId,omitempty"`
-	BlobVersionedHashes []common.Hash     `json:"blobVersionedHashes,omitempty"`
-	V                   *hexutil.Big      `json:"v,omitempty"`
-	R                   *hexutil.Big      `json:"r,omitempty"`
-	S                   *hexutil.Big      `json:"s,omitempty"`
-	YParity             *hexutil.Uint64   `json:"yParity,omitempty"`
+	BlockHash           *common.Hash                 `json:"blockHash"`
+	BlockNumber         *hexutil.Big                 `json:"blockNumber"`
+	From                common.Address               `json:"from,omitempty"`
+	Gas                 hexutil.Uint64               `json:"gas"`
+	GasPrice            *hexutil.Big                 `json:"gasPrice"`
+	GasFeeCap           *hexutil.Big                 `json:"maxFeePerGas,omitempty"`
+	GasTipCap           *hexutil.Big                 `json:"maxPriorityFeePerGas,omitempty"`
+	MaxFeePerBlobGas    *hexutil.Big                 `json:"maxFeePerBlobGas,omitempty"`
+	Hash                common.Hash                  `json:"hash"`
+	Input               hexutil.Bytes                `json:"input"`
+	Nonce               hexutil.Uint64               `json:"nonce"`
+	To                  *common.Address              `json:"to,omitempty"`
+	TransactionIndex    *hexutil.Uint64              `json:"transactionIndex"`
+	Value               *hexutil.Big                 `json:"value"`
+	Type                hexutil.Uint64               `json:"type"`
+	Accesses            *types.AccessList            `json:"accessList,omitempty"`
+	ChainID             *hexutil.Big                 `json:"chainId,omitempty"`
+	BlobVersionedHashes []common.Hash                `json:"blobVersionedHashes,omitempty"`
+	AuthorizationList   []types.SetCodeAuthorization `json:"authorizationList,omitempty"`
+	V                   *hexutil.Big                 `json:"v,omitempty"`
+	R                   *hexutil.Big                 `json:"r,omitempty"`
+	S                   *hexutil.Big                 `json:"s,omitempty"`
+	YParity             *hexutil.Uint64              `json:"yParity,omitempty"`
 
 	// Introduced by RIP-7560 Transaction
 	Sender                      *common.Address `json:"sender,omitempty"`
 	AuthorizationData           *hexutil.Bytes  `json:"authorizationData,omitempty"`
 	ExecutionData               *hexutil.Bytes  `json:"executionData,omitempty"`
+	ExecutionCalls              []hexutil.Bytes `json:"executionCalls,omitempty"`
 	Paymaster                   *common.Address `json:"paymaster,omitempty"`
 	PaymasterData               *hexutil.Bytes  `json:"paymasterData,omitempty"`
 	Deployer                    *common.Address `json:"deployer,omitempty"`
@@ -1374,8 +1526,10 @@ func toBytes(data []byte) *hexutil.Bytes {
 	return (*hexutil.Bytes)(&data)
 }
 
+// conditional_uint64 leaves out the gas limit of a frame that is not called,
+// unless it is set anyway, in which case it is part of the transaction hash.
 func conditional_uint64(v uint64, addr *common.Address) *hexutil.Uint64 {
-	if addr == nil {
+	if addr == nil && v == 0 {
 		return nil
 	}
 	return (*hexutil.Uint64)(&v)
@@ -1444,11 +1598,15 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 		result.R = nil
 		result.V = nil
 		result.To = nil
+		result.From = *rip7560Tx.Sender
 		result.NonceKey = (*hexutil.Big)(rip7560Tx.NonceKey)
 		result.Input = make(hexutil.Bytes, 0)
 		result.Sender = rip7560Tx.Sender
 		result.AuthorizationData = toBytes(rip7560Tx.AuthorizationData)
 		result.ExecutionData = toBytes(rip7560Tx.ExecutionData)
+		for _, call := range rip7560Tx.ExecutionCalls {
+			result.ExecutionCalls = append(result.ExecutionCalls, call)
+		}
 		result.Gas = hexutil.Uint64(tx.Gas())
 		result.Paymaster = rip7560Tx.Paymaster
 		result.PaymasterData = toBytes(rip7560Tx.PaymasterData)
@@ -1458,6 +1616,8 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 		result.ValidationGas = (*hexutil.Uint64)(&rip7560Tx.ValidationGasLimit)
 		result.PaymasterValidationGasLimit = conditional_uint64(rip7560Tx.PaymasterValidationGasLimit, rip7560Tx.Paymaster)
 		result.PostOpGas = conditional_uint64(rip7560Tx.PostOpGas, rip7560Tx.Paymaster)
+		al := tx.AccessList()
+		result.Accesses = &al
 
 		//shared fields with DynamicFeeTxType
 		result.ChainID = (*hexutil.Big)(tx.ChainId())
@@ -1487,6 +1647,22 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 		}
 		result.MaxFeePerBlobGas = (*hexutil.Big)(tx.BlobGasFeeCap())
 		result.BlobVersionedHashes = tx.BlobHashes()
+
+	case types.SetCodeTxType:
+		al := tx.AccessList()
+		yparity := hexutil.Uint64(v.Sign())
+		result.Accesses = &al
+		result.ChainID = (*hexutil.Big)(tx.ChainId())
+		result.YParity = &yparity
+		result.GasFeeCap = (*hexutil.Big)(tx.GasFeeCap())
+		result.GasTipCap = (*hexutil.Big)(tx.GasTipCap())
+		// if the transaction has been mined, compute the effective gas price
+		if baseFee != nil && blockHash != (common.Hash{}) {
+			result.GasPrice = (*hexutil.Big)(effectiveGasPrice(tx, baseFee))
+		} else {
+			result.GasPrice = (*hexutil.Big)(tx.GasFeeCap())
+		}
+		result.AuthorizationList = tx.SetCodeAuthorizations()
 	}
 	return result
 }
@@ -1804,6 +1980,12 @@ func marshalReceipt(receipt *types.Receipt, blockHash common.Hash, blockNumber u
 		fields["blobGasUsed"] = hexutil.Uint64(receipt.BlobGasUsed)
 		fields["blobGasPrice"] = (*hexutil.Big)(receipt.BlobGasPrice)
 	}
+	if tx.Type() == types.Rip7560Type {
+		fields["validationGasUsed"] = hexutil.Uint64(receipt.ValidationGasUsed)
+		fields["paymasterAddress"] = receipt.PaymasterAddress
+		fields["deployedContractAddress"] = receipt.DeployedContractAddress
+		fields["frameStatuses"] = receipt.FrameStatuses
+	}
 
 	// If the ContractAddress is 20 0x0 bytes, assume it is not a contract creation
 	if receipt.ContractAddress != (common.Address{}) {
@@ -2074,12 +2256,13 @@ func (s *TransactionAPI) Resend(ctx context.Context, sendArgs TransactionArgs, g
 // DebugAPI is the collection of Ethereum APIs exposed over the debugging
 // namespace.
 type DebugAPI struct {
-	b Backend
+	b        Backend
+	sessions *simulationSessions
 }
 
 // NewDebugAPI creates a new instance of DebugAPI.
 func NewDebugAPI(b Backend) *DebugAPI {
-	return &DebugAPI{b: b}
+	return &DebugAPI{b: b, sessions: newSimulationSessions()}
 }
 
 // GetRawHeader retrieves the RLP encoding for a single header.
@@ -2163,6 +2346,51 @@ func (s *DebugAPI) GetRawTransaction(ctx context.Context, hash common.Hash) (hex
 	return tx.MarshalBinary()
 }
 
+// Rip7560FrameLog is a log of a RIP-7560 transaction, along with the name of the
+// frame which emitted it.
+type Rip7560FrameLog struct {
+	Frame string     `json:"frame"`
+	Log   *types.Log `json:"log"`
+}
+
+// GetRip7560FrameLogs returns the logs of an included RIP-7560 transaction along
+// with the frame which emitted each of them. If a frame name is given, only the
+// logs of that frame are returned.
+//
+// The frames are node-local metadata, only known for blocks which were processed
+// by this node.
+func (api *DebugAPI) GetRip7560FrameLogs(ctx context.Context, hash common.Hash, frame *string) ([]*Rip7560FrameLog, error) {
+	found, tx, blockHash, _, index, err := api.b.GetTransaction(ctx, hash)
+	if err != nil {
+		return nil, NewTxIndexingError() // transaction is not fully indexed
+	}
+	if !found {
+		return nil, nil // transaction is not existent or reachable
+	}
+	if tx.Type() != types.Rip7560Type {
+		return nil, errors.New("not a RIP-7560 transaction")
+	}
+	receipts, err := api.b.GetReceipts(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(receipts)) <= index {
+		return nil, nil
+	}
+	logs := receipts[index].Logs
+	frames := rawdb.ReadRip7560LogFrames(api.b.ChainDb(), blockHash, hash)
+	if len(frames) != len(logs) {
+		return nil, fmt.Errorf("log frames of transaction %x are not available", hash)
+	}
+	result := make([]*Rip7560FrameLog, 0, len(logs))
+	for i, l := range logs {
+		if frame == nil || *frame == frames[i] {
+			result = append(result, &Rip7560FrameLog{Frame: frames[i], Log: l})
+		}
+	}
+	return result, nil
+}
+
 // PrintBlock retrieves a block and returns its pretty printed form.
 func (api *DebugAPI) PrintBlock(ctx context.Context, number uint64) (string, error) {
 	block, _ := api.b.BlockByNumber(ctx, rpc.BlockNumber(number))
@@ -44,6 +44,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/beacon"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/aa"
 	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
@@ -469,8 +470,8 @@ func (b testBackend) SyncProgress() ethereum.SyncProgress { return ethereum.Sync
 func (b testBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
 	return big.NewInt(0), nil
 }
-func (b testBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []*big.Int, []float64, []*big.Int, []float64, error) {
-	return nil, nil, nil, nil, nil, nil, nil
+func (b testBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []*big.Int, []float64, []*big.Int, []float64, []float64, error) {
+	return nil, nil, nil, nil, nil, nil, nil, nil
 }
 func (b testBackend) BlobBaseFee(ctx context.Context) *big.Int { return new(big.Int) }
 func (b testBackend) ChainDb() ethdb.Database                  { return b.db }
@@ -621,6 +622,33 @@ func (b testBackend) BloomStatus() (uint64, uint64) { panic("implement me") }
 func (b testBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
 	panic("implement me")
 }
+func (b testBackend) Rip7560Capabilities() Rip7560Capabilities {
+	return Rip7560Capabilities{}
+}
+func (b testBackend) Rip7560ReplacementFee(hash common.Hash) (*big.Int, *big.Int, error) {
+	panic("implement me")
+}
+func (b testBackend) Rip7560ViolationStats() (map[string]uint64, error) {
+	panic("implement me")
+}
+func (b testBackend) Rip7560ValidationBacklog() (int, error) {
+	panic("implement me")
+}
+func (b testBackend) SubscribeRip7560DroppedEvent(ch chan<- core.Rip7560TxDroppedEvent) event.Subscription {
+	panic("implement me")
+}
+func (b testBackend) SubmitRip7560Bundle(bundle *types.ExternallyReceivedBundle) error {
+	panic("implement me")
+}
+func (b testBackend) GetRip7560BundleStatus(ctx context.Context, hash common.Hash) (*types.BundleReceipt, error) {
+	panic("implement me")
+}
+func (b testBackend) GetRip7560TransactionDebugInfo(common.Hash) (map[string]interface{}, error) {
+	panic("implement me")
+}
+func (b testBackend) SetRip7560TransactionDebugInfo(infos []*types.Rip7560TransactionDebugInfo) {
+	panic("implement me")
+}
 
 func TestEstimateGas(t *testing.T) {
 	t.Parallel()
@@ -2053,3 +2081,295 @@ func testRPCResponseWithFile(t *testing.T, testid int, result interface{}, rpc s
 	}
 	require.JSONEqf(t, string(want), string(data), "test %d: json not match, want: %s, have: %s", testid, string(want), string(data))
 }
+
+func TestAccountAbstractionGetConfig(t *testing.T) {
+	t.Parallel()
+
+	config := *params.TestChainConfig
+	config.RIP7560Block = big.NewInt(2)
+	config.RIP7712Block = big.NewInt(5)
+	var (
+		genesis = &core.Genesis{Config: &config, Alloc: types.GenesisAlloc{}}
+		backend = newTestBackend(t, 3, genesis, beacon.New(ethash.NewFaker()), func(i int, b *core.BlockGen) {})
+		api     = NewAccountAbstractionAPI(backend)
+	)
+	have := api.GetConfig(context.Background())
+	rulesHash := core.Rip7560RulesHash(&config)
+	want := &AccountAbstractionConfig{
+		EntryPoint:         core.AA_ENTRY_POINT,
+		SenderCreator:      core.AA_SENDER_CREATOR,
+		NonceManager:       core.AA_NONCE_MANAGER,
+		ActivationBlock:    (*hexutil.Big)(big.NewInt(2)),
+		NonceManagerBlock:  (*hexutil.Big)(big.NewInt(5)),
+		Active:             true,
+		NonceManagerActive: false,
+		AbiVersion:         hexutil.Uint64(aa.Version0),
+		RulesVersion:       core.Rip7560ValidationRulesVersion,
+		RulesHash:          rulesHash[:],
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Fatalf("config mismatch: have %+v, want %+v", have, want)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	t.Parallel()
+
+	config := *params.TestChainConfig
+	config.RIP7560Block = big.NewInt(2)
+	config.RIP7712Block = big.NewInt(5)
+	var (
+		genesis = &core.Genesis{Config: &config, Alloc: types.GenesisAlloc{}}
+		backend = newTestBackend(t, 3, genesis, beacon.New(ethash.NewFaker()), func(i int, b *core.BlockGen) {})
+		api     = NewBlockChainAPI(backend)
+	)
+	have := api.Config()
+	rulesHash := core.Rip7560RulesHash(&config)
+	want := &NodeConfig{
+		ChainID: (*hexutil.Big)(config.ChainID),
+		Head:    3,
+		ActiveForks: []string{"homestead", "eip150", "eip155", "eip158", "byzantium", "constantinople", "petersburg",
+			"istanbul", "muirGlacier", "berlin", "london", "arrowGlacier", "grayGlacier", "rip7560"},
+		TxTypes: []hexutil.Uint64{types.LegacyTxType, types.AccessListTxType, types.DynamicFeeTxType, types.Rip7560Type},
+		Rip7560: &Rip7560Rules{
+			AbiVersion:   hexutil.Uint64(aa.Version0),
+			RulesVersion: core.Rip7560ValidationRulesVersion,
+			RulesHash:    rulesHash[:],
+		},
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Fatalf("config mismatch: have %+v, want %+v", have, want)
+	}
+}
+
+func TestAccountAbstractionGetAccountInfo(t *testing.T) {
+	t.Parallel()
+
+	config := *params.TestChainConfig
+	config.RIP7560Block = big.NewInt(0)
+	config.RIP7712Block = big.NewInt(0)
+
+	frameABI, _ := aa.Lookup(aa.Version0)
+	selector := frameABI.ABI.Methods["validateTransaction"].ID
+	// Answers supportsInterface for ERC-165 and validateTransaction
+	erc165Code := append(append([]byte{
+		byte(vm.PUSH1), 4, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 0xe0, byte(vm.SHR),
+		byte(vm.DUP1), byte(vm.PUSH4), 0x01, 0xff, 0xc9, 0xa7, byte(vm.EQ),
+		byte(vm.SWAP1), byte(vm.PUSH4)}, selector...),
+		byte(vm.EQ), byte(vm.OR),
+		byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+	)
+	var (
+		eoa      = common.Address{0x01}
+		erc165   = common.Address{0x02}
+		selected = common.Address{0x03}
+		plain    = common.Address{0x04}
+		genesis  = &core.Genesis{Config: &config, Alloc: types.GenesisAlloc{
+			eoa:                               {Balance: big.NewInt(1), Nonce: 3},
+			erc165:                            {Code: erc165Code},
+			selected:                          {Code: append(append([]byte{byte(vm.PUSH4)}, selector...), byte(vm.POP), byte(vm.STOP))},
+			plain:                             {Code: []byte{byte(vm.STOP)}},
+			params.Rip7712NonceManagerAddress: {Nonce: 1, Code: params.Rip7712NonceManagerCode},
+		}}
+		backend = newTestBackend(t, 1, genesis, beacon.New(ethash.NewFaker()), func(i int, b *core.BlockGen) {})
+		api     = NewAccountAbstractionAPI(backend)
+	)
+	tests := []struct {
+		addr      common.Address
+		hasCode   bool
+		detection string
+		nonce     uint64
+	}{
+		{eoa, false, "", 3},
+		{erc165, true, AccountDetectionERC165, 0},
+		{selected, true, AccountDetectionBytecode, 0},
+		{plain, true, "", 0},
+	}
+	for i, tt := range tests {
+		info, err := api.GetAccountInfo(context.Background(), tt.addr, []*hexutil.Big{(*hexutil.Big)(big.NewInt(1))}, nil)
+		if err != nil {
+			t.Fatalf("test %d: failed to get account info: %v", i, err)
+		}
+		if info.HasCode != tt.hasCode {
+			t.Errorf("test %d: code mismatch: have %t, want %t", i, info.HasCode, tt.hasCode)
+		}
+		if info.Detection != tt.detection || info.SupportsValidation != (tt.detection != "") {
+			t.Errorf("test %d: detection mismatch: have %q (%t), want %q", i, info.Detection, info.SupportsValidation, tt.detection)
+		}
+		if len(info.Nonces) != 2 || uint64(info.Nonces[0].Nonce) != tt.nonce || info.Nonces[1].Key.ToInt().Cmp(common.Big1) != 0 || info.Nonces[1].Nonce != 0 {
+			t.Errorf("test %d: nonces mismatch: have %+v", i, info.Nonces)
+		}
+	}
+}
+
+func TestInclusionTracker(t *testing.T) {
+	t.Parallel()
+
+	var (
+		tracker = &inclusionTracker{hash: common.Hash{0x01}}
+		blockA  = common.Hash{0xaa}
+		blockB  = common.Hash{0xbb}
+	)
+	tests := []struct {
+		found  bool
+		block  common.Hash
+		number uint64
+		status string // Empty if no status change is expected
+	}{
+		{false, common.Hash{}, 0, ""},
+		{true, blockA, 1, TxStatusIncluded},
+		{true, blockA, 1, ""},
+		{false, common.Hash{}, 0, TxStatusReorged},
+		{false, common.Hash{}, 0, ""},
+		{true, blockB, 2, TxStatusReincluded},
+		{true, blockA, 1, TxStatusReincluded},
+	}
+	for i, tt := range tests {
+		ev := tracker.update(tt.found, tt.block, tt.number)
+		if tt.status == "" {
+			if ev != nil {
+				t.Fatalf("test %d: unexpected status %q", i, ev.Status)
+			}
+			continue
+		}
+		if ev == nil || ev.Status != tt.status {
+			t.Fatalf("test %d: status mismatch: have %v, want %q", i, ev, tt.status)
+		}
+		if tt.status != TxStatusReorged && (*ev.BlockHash != tt.block || uint64(*ev.BlockNumber) != tt.number) {
+			t.Fatalf("test %d: block mismatch: have %x/%d, want %x/%d", i, *ev.BlockHash, *ev.BlockNumber, tt.block, tt.number)
+		}
+	}
+}
+
+func TestTxPoolStats(t *testing.T) {
+	t.Parallel()
+
+	var (
+		legacy  = types.NewTx(&types.LegacyTx{Nonce: 0, Gas: 21000})
+		dynamic = types.NewTx(&types.DynamicFeeTx{Nonce: 1, Gas: 21000, Data: make([]byte, 100)})
+		aa      = types.NewTx(&types.Rip7560AccountAbstractionTx{NonceKey: new(big.Int), Gas: 21000})
+	)
+	pending := map[common.Address][]*types.Transaction{
+		{0x01}: {legacy, dynamic},
+		{0x02}: {aa},
+	}
+	queued := map[common.Address][]*types.Transaction{
+		{0x01}: {types.NewTx(&types.DynamicFeeTx{Nonce: 3, Gas: 21000})},
+	}
+	stats := newTxPoolStats(pending, queued)
+	if stats.Pending != 3 || stats.Queued != 1 {
+		t.Fatalf("counts mismatch: have %d/%d, want 3/1", stats.Pending, stats.Queued)
+	}
+	if want := legacy.Size() + dynamic.Size() + aa.Size(); uint64(stats.PendingBytes) != want {
+		t.Errorf("pending bytes mismatch: have %d, want %d", stats.PendingBytes, want)
+	}
+	if want := queued[common.Address{0x01}][0].Size(); uint64(stats.QueuedBytes) != want {
+		t.Errorf("queued bytes mismatch: have %d, want %d", stats.QueuedBytes, want)
+	}
+	if len(stats.Types) != 3 {
+		t.Fatalf("type count mismatch: have %d, want 3", len(stats.Types))
+	}
+	if typ := stats.Types[types.DynamicFeeTxType]; typ.Pending != 1 || typ.Queued != 1 || uint64(typ.Bytes) != dynamic.Size()+queued[common.Address{0x01}][0].Size() {
+		t.Errorf("dynamic fee stats mismatch: have %+v", typ)
+	}
+	if typ := stats.Types[types.Rip7560Type]; typ.Pending != 1 || typ.Queued != 0 || uint64(typ.Bytes) != aa.Size() {
+		t.Errorf("RIP-7560 stats mismatch: have %+v", typ)
+	}
+	if stats.Rip7560ValidationBacklog != nil {
+		t.Errorf("unexpected validation backlog %d", *stats.Rip7560ValidationBacklog)
+	}
+}
+
+func TestTransactionConditions(t *testing.T) {
+	t.Parallel()
+
+	var (
+		addr   = common.Address{0xaa}
+		slot   = common.Hash{0x01}
+		value  = common.Hash{0x02}
+		header = &types.Header{Number: big.NewInt(10), Time: 100}
+	)
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetState(addr, slot, value)
+	statedb.IntermediateRoot(false)
+	storageRoot := statedb.GetStorageRoot(addr)
+
+	tests := []struct {
+		conditions string
+		err        bool
+	}{
+		{`{}`, false},
+		{`{"blockNumberMin": "0xb", "blockNumberMax": "0xb"}`, false},
+		{`{"blockNumberMin": "0xc"}`, true},
+		{`{"blockNumberMax": "0xa"}`, true},
+		{`{"timestampMin": "0x64", "timestampMax": "0x64"}`, false},
+		{`{"timestampMin": "0x65"}`, true},
+		{`{"timestampMax": "0x63"}`, true},
+		{fmt.Sprintf(`{"knownAccounts": {"%v": "%v"}}`, addr, storageRoot), false},
+		{fmt.Sprintf(`{"knownAccounts": {"%v": "%v"}}`, addr, common.Hash{}), true},
+		{fmt.Sprintf(`{"knownAccounts": {"%v": {"%v": "%v"}}}`, addr, slot, value), false},
+		{fmt.Sprintf(`{"knownAccounts": {"%v": {"%v": "%v"}}}`, addr, slot, common.Hash{}), true},
+	}
+	for i, tt := range tests {
+		var conditions TransactionConditions
+		if err := json.Unmarshal([]byte(tt.conditions), &conditions); err != nil {
+			t.Fatalf("test %d: failed to decode conditions: %v", i, err)
+		}
+		err := conditions.check(header, statedb)
+		if tt.err != (err != nil) {
+			t.Fatalf("test %d: error mismatch: have %v, want error %t", i, err, tt.err)
+		}
+		if err != nil {
+			var cerr *conditionError
+			if !errors.As(err, &cerr) || cerr.ErrorCode() != -32003 {
+				t.Fatalf("test %d: unexpected error type %T", i, err)
+			}
+		}
+	}
+}
+
+// Tests that RIP-7560 transactions returned over RPC hold all the fields needed
+// to decode them back.
+func TestRip7560Transaction_RoundTripRpcJSON(t *testing.T) {
+	t.Parallel()
+
+	config := params.AllDevChainProtocolChanges
+	for i, paymaster := range []*common.Address{nil, {0xbb}} {
+		tx := types.NewTx(&types.Rip7560AccountAbstractionTx{
+			ChainID:                     config.ChainID,
+			Nonce:                       1,
+			GasTipCap:                   big.NewInt(2),
+			GasFeeCap:                   big.NewInt(10),
+			Gas:                         100_000,
+			AccessList:                  types.AccessList{{Address: common.Address{0x01}, StorageKeys: []common.Hash{{0x02}}}},
+			Sender:                      &common.Address{0xaa},
+			AuthorizationData:           []byte{0x01},
+			ExecutionData:               []byte{0x02},
+			Paymaster:                   paymaster,
+			PaymasterData:               []byte{0x03},
+			Deployer:                    &common.Address{0xcc},
+			DeployerData:                []byte{0x04},
+			BuilderFee:                  big.NewInt(5),
+			ValidationGasLimit:          50_000,
+			PaymasterValidationGasLimit: 20_000,
+			PostOpGas:                   10_000,
+			NonceKey:                    big.NewInt(6),
+		})
+		data, err := json.Marshal(newRPCTransaction(tx, common.Hash{}, 0, 0, 0, nil, config))
+		if err != nil {
+			t.Fatalf("test %d: marshalling failed: %v", i, err)
+		}
+		var dec types.Transaction
+		if err := dec.UnmarshalJSON(data); err != nil {
+			t.Fatalf("test %d: unmarshal failed: %v", i, err)
+		}
+		if want, have := tx.Hash(), dec.Hash(); want != have {
+			t.Fatalf("test %d: tx changed, want %x have %x", i, want, have)
+		}
+		var fields map[string]interface{}
+		json.Unmarshal(data, &fields)
+		if fields["from"] != "0xaa00000000000000000000000000000000000000" {
+			t.Errorf("test %d: from mismatch: have %v, want the sender", i, fields["from"])
+		}
+	}
+}
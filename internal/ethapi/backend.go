@@ -44,7 +44,7 @@ type Backend interface {
 	SyncProgress() ethereum.SyncProgress
 
 	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
-	FeeHistory(ctx context.Context, blockCount uint64, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []*big.Int, []float64, []*big.Int, []float64, error)
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []*big.Int, []float64, []*big.Int, []float64, []float64, error)
 	BlobBaseFee(ctx context.Context) *big.Int
 	ChainDb() ethdb.Database
 	AccountManager() *accounts.Manager
@@ -100,6 +100,11 @@ type Backend interface {
 
 	// RIP-7560 specific functions
 
+	Rip7560Capabilities() Rip7560Capabilities
+	Rip7560ReplacementFee(hash common.Hash) (feeCap *big.Int, tipCap *big.Int, err error)
+	Rip7560ViolationStats() (map[string]uint64, error)
+	Rip7560ValidationBacklog() (int, error)
+	SubscribeRip7560DroppedEvent(ch chan<- core.Rip7560TxDroppedEvent) event.Subscription
 	SubmitRip7560Bundle(bundle *types.ExternallyReceivedBundle) error
 	GetRip7560BundleStatus(ctx context.Context, hash common.Hash) (*types.BundleReceipt, error)
 
@@ -133,6 +138,9 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 		}, {
 			Namespace: "personal",
 			Service:   NewPersonalAccountAPI(apiBackend, nonceLock),
+		}, {
+			Namespace: "aa",
+			Service:   NewAccountAbstractionAPI(apiBackend),
 		},
 	}
 }
@@ -76,3 +76,14 @@ func (e *TxIndexingError) ErrorCode() int {
 
 // ErrorData returns the hex encoded revert reason.
 func (e *TxIndexingError) ErrorData() interface{} { return "transaction indexing is in progress" }
+
+// conditionError is an API error that indicates the conditions of a conditional
+// transaction submission are not met.
+type conditionError struct{ msg string }
+
+// Error implement error interface, returning the error message.
+func (e *conditionError) Error() string { return e.msg }
+
+// ErrorCode returns the JSON error code of unmet submission conditions, as used
+// by eth_sendRawTransactionConditional.
+func (e *conditionError) ErrorCode() int { return -32003 }
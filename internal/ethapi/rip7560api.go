@@ -1,11 +1,23 @@
 package ethapi
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/aa"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/gasestimator"
+	"github.com/ethereum/go-ethereum/internal/rpcusage"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 	"golang.org/x/crypto/sha3"
 	"math/big"
 )
@@ -18,6 +30,9 @@ func (s *TransactionAPI) SendRip7560TransactionsBundle(ctx context.Context, args
 	for i := 0; i < len(args); i++ {
 		txs[i] = args[i].ToTransaction()
 	}
+	if err := checkRip7560BundleFunds(ctx, s.b, txs); err != nil {
+		return common.Hash{}, err
+	}
 	bundle := &types.ExternallyReceivedBundle{
 		BundlerId:     bundlerId,
 		ValidForBlock: creationBlock,
@@ -41,6 +56,29 @@ func (s *TransactionAPI) GetRip7560TransactionDebugInfo(hash common.Hash) (map[s
 	return s.b.GetRip7560TransactionDebugInfo(hash)
 }
 
+// checkRip7560BundleFunds ensures that the account charged for the gas of every
+// transaction in the bundle, i.e. the sender or the sponsoring paymaster, can
+// cover its maximum cost at the latest state, from its balance or its deposit.
+func checkRip7560BundleFunds(ctx context.Context, b Backend, txs []*types.Transaction) error {
+	state, _, err := b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return err
+	}
+	for i, tx := range txs {
+		payer, cost := tx.PayerCost()
+		if payer == nil {
+			payer, cost = tx.Rip7560TransactionData().Sender, tx.SenderCost()
+		}
+		if payer == nil {
+			return fmt.Errorf("transaction %d: missing sender", i)
+		}
+		if funds := core.Rip7560GasFunds(b.ChainConfig(), state, tx.Rip7560TransactionData()).ToBig(); funds.Cmp(cost) < 0 {
+			return fmt.Errorf("transaction %d: %w: address %v have %v want %v", i, core.ErrInsufficientFunds, payer.Hex(), funds, cost)
+		}
+	}
+	return nil
+}
+
 // CalculateBundleHash
 // TODO: If this code is indeed necessary, keep it in utils; better - remove altogether.
 func CalculateBundleHash(txs []*types.Transaction) common.Hash {
@@ -50,10 +88,7 @@ func CalculateBundleHash(txs []*types.Transaction) common.Hash {
 		appendedTxIds = append(appendedTxIds, txHash[:]...)
 	}
 
-	bundleHash := rlpHash(appendedTxIds)
-	println("calculateBundleHash")
-	println(bundleHash.String())
-	return bundleHash
+	return rlpHash(appendedTxIds)
 }
 
 func rlpHash(x interface{}) (h common.Hash) {
@@ -67,3 +102,752 @@ func rlpHash(x interface{}) (h common.Hash) {
 func SubmitRip7560Bundle(ctx context.Context, b Backend, bundle *types.ExternallyReceivedBundle) error {
 	return b.SubmitRip7560Bundle(bundle)
 }
+
+// AccountAbstractionAPI provides an API to access RIP-7560 specific information.
+type AccountAbstractionAPI struct {
+	b Backend
+}
+
+// NewAccountAbstractionAPI creates a new RIP-7560 API instance.
+func NewAccountAbstractionAPI(b Backend) *AccountAbstractionAPI {
+	return &AccountAbstractionAPI{b}
+}
+
+// AccountAbstractionConfig describes the canonical RIP-7560 system contract
+// addresses and fork status of the node.
+type AccountAbstractionConfig struct {
+	EntryPoint         common.Address  `json:"entryPoint"`
+	SenderCreator      common.Address  `json:"senderCreator"`
+	NonceManager       common.Address  `json:"nonceManager"`
+	DepositManager     *common.Address `json:"depositManager"`
+	StakeRegistry      *common.Address `json:"stakeRegistry"`
+	ActivationBlock    *hexutil.Big    `json:"activationBlock"`
+	ActivationTime     *hexutil.Uint64 `json:"activationTime"`
+	NonceManagerBlock  *hexutil.Big    `json:"nonceManagerActivationBlock"`
+	NonceManagerTime   *hexutil.Uint64 `json:"nonceManagerActivationTime"`
+	Active             bool            `json:"active"`
+	NonceManagerActive bool            `json:"nonceManagerActive"`
+	AbiVersion         hexutil.Uint64  `json:"abiVersion"`
+	RulesVersion       hexutil.Uint64  `json:"rulesVersion"`
+	RulesHash          hexutil.Bytes   `json:"rulesHash"`
+
+	Capabilities Rip7560Capabilities `json:"capabilities"`
+}
+
+// Rip7560Capabilities tells which RIP-7560 services a node offers. A node
+// without a RIP-7560 pool still imports blocks with RIP-7560 transactions and
+// serves them and their receipts, but rejects new submissions.
+type Rip7560Capabilities struct {
+	Submission       bool `json:"submission"`       // RIP-7560 transactions accepted by eth_sendRawTransaction
+	BundleSubmission bool `json:"bundleSubmission"` // Bundles accepted by eth_sendRip7560TransactionsBundle
+}
+
+// GetConfig returns the RIP-7560 system contract addresses, the activation
+// status at the current head and the version of the enforced rules.
+//
+// The rules hash is a checksum of the AA rules of the chain, also advertised in
+// the ENR of the node, which differs between incompatible networks.
+//
+// The deposit manager is only reported if paymasters pay for gas from their
+// deposits on this chain. The stake registry is reported as null since this
+// client doesn't deploy one.
+// The capabilities tell tooling whether the node accepts RIP-7560 transactions
+// or only serves the ones already included in the chain.
+func (api *AccountAbstractionAPI) GetConfig(ctx context.Context) *AccountAbstractionConfig {
+	var (
+		config = api.b.ChainConfig()
+		head   = api.b.CurrentHeader()
+	)
+	rulesHash := core.Rip7560RulesHash(config)
+	var depositManager *common.Address
+	if config.Rip7560PaymasterDeposits() {
+		depositManager = &core.AA_DEPOSIT_MANAGER
+	}
+	return &AccountAbstractionConfig{
+		EntryPoint:         core.AA_ENTRY_POINT,
+		SenderCreator:      core.AA_SENDER_CREATOR,
+		NonceManager:       core.AA_NONCE_MANAGER,
+		DepositManager:     depositManager,
+		ActivationBlock:    (*hexutil.Big)(config.RIP7560Block),
+		ActivationTime:     (*hexutil.Uint64)(config.RIP7560Time),
+		NonceManagerBlock:  (*hexutil.Big)(config.RIP7712Block),
+		NonceManagerTime:   (*hexutil.Uint64)(config.RIP7712Time),
+		Active:             config.IsRIP7560(head.Number, head.Time),
+		NonceManagerActive: config.IsRIP7712(head.Number, head.Time),
+		AbiVersion:         hexutil.Uint64(config.Rip7560AbiVersion()),
+		RulesVersion:       core.Rip7560ValidationRulesVersion,
+		RulesHash:          rulesHash[:],
+		Capabilities:       api.b.Rip7560Capabilities(),
+	}
+}
+
+// SigningPayload contains the hashes a RIP-7560 transaction may be authorized
+// with. The signing hash is the consensus one, while the typed data is provided
+// for wallets signing through EIP-712 flows and is only meaningful if the account
+// validates it.
+type SigningPayload struct {
+	SigningHash   common.Hash         `json:"signingHash"`
+	TypedData     *apitypes.TypedData `json:"typedData"`
+	TypedDataHash common.Hash         `json:"typedDataHash"`
+}
+
+// GetSigningPayload returns the hashes to be signed to authorize the given
+// RIP-7560 transaction.
+func (api *AccountAbstractionAPI) GetSigningPayload(ctx context.Context, args TransactionArgs) (*SigningPayload, error) {
+	if args.Sender == nil {
+		return nil, errors.New("missing sender")
+	}
+	var (
+		chainID = api.b.ChainConfig().ChainID
+		tx      = args.ToTransaction()
+	)
+	typedData, err := apitypes.Rip7560TypedData(tx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	typedDataHash, err := apitypes.Rip7560TypedDataHash(tx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	return &SigningPayload{
+		SigningHash:   types.NewRIP7560Signer(chainID).Hash(tx),
+		TypedData:     typedData,
+		TypedDataHash: typedDataHash,
+	}, nil
+}
+
+// ReplacementFee holds the minimal fees of a transaction replacing a pending
+// RIP-7560 transaction.
+type ReplacementFee struct {
+	MaxFeePerGas         *hexutil.Big `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big `json:"maxPriorityFeePerGas"`
+}
+
+// GetReplacementFee returns the minimal fees a transaction needs to replace the
+// given RIP-7560 transaction in the pool. Both fees are bumped by the price bump
+// of the pool, and the fee cap covers the tip on top of the next base fee.
+func (api *AccountAbstractionAPI) GetReplacementFee(ctx context.Context, hash common.Hash) (*ReplacementFee, error) {
+	feeCap, tipCap, err := api.b.Rip7560ReplacementFee(hash)
+	if err != nil {
+		return nil, err
+	}
+	return &ReplacementFee{
+		MaxFeePerGas:         (*hexutil.Big)(feeCap),
+		MaxPriorityFeePerGas: (*hexutil.Big)(tipCap),
+	}, nil
+}
+
+// GetViolationStats returns the number of transactions submitted to the pool
+// that broke each of the ERC-7562 validation rules, by rule identifier. The
+// counts include the transactions accepted despite their violations, and are
+// kept since the node started.
+func (api *AccountAbstractionAPI) GetViolationStats(ctx context.Context) (map[string]hexutil.Uint64, error) {
+	stats, err := api.b.Rip7560ViolationStats()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]hexutil.Uint64, len(stats))
+	for rule, count := range stats {
+		result[rule] = hexutil.Uint64(count)
+	}
+	return result, nil
+}
+
+// ValidateTransaction simulates the validation phase of the given RIP-7560
+// transaction on top of the given block, defaulting to the latest one. The
+// report is the same the transaction pool and the miner judge transactions by.
+// For transactions deploying their account, it also tells the gas spent on the
+// deployment and predicts the validation gas of the next transactions. The
+// witness of the report holds the accounts and slots read by the validation
+// with their values, so bundlers can tell when to validate it again.
+func (api *AccountAbstractionAPI) ValidateTransaction(ctx context.Context, args TransactionArgs, blockNrOrHash *rpc.BlockNumberOrHash) (*types.ValidationReport, error) {
+	if args.Sender == nil {
+		return nil, errors.New("missing sender")
+	}
+	bNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if blockNrOrHash != nil {
+		bNrOrHash = *blockNrOrHash
+	}
+	state, header, err := api.b.StateAndHeaderByNumberOrHash(ctx, bNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	tx := args.ToTransaction()
+	report := core.SimulateRip7560Validation(api.b.ChainConfig(), NewChainContext(ctx, api.b), header, state, tx)
+	rpcusage.Record(ctx, uint64(report.GasUsed), tx.Size())
+	return report, nil
+}
+
+// GetNonce returns the nonce of the given sender for the given RIP-7712 nonce
+// key at the given block, defaulting to the latest one. The zero key returns
+// the legacy account nonce.
+func (api *AccountAbstractionAPI) GetNonce(ctx context.Context, sender common.Address, key *hexutil.Big, blockNrOrHash *rpc.BlockNumberOrHash) (hexutil.Uint64, error) {
+	bNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if blockNrOrHash != nil {
+		bNrOrHash = *blockNrOrHash
+	}
+	state, header, err := api.b.StateAndHeaderByNumberOrHash(ctx, bNrOrHash)
+	if state == nil || err != nil {
+		return 0, err
+	}
+	if key == nil || key.ToInt().Sign() == 0 {
+		return hexutil.Uint64(state.GetNonce(sender)), state.Error()
+	}
+	var (
+		blockCtx = core.NewEVMBlockContext(header, NewChainContext(ctx, api.b), nil)
+		evm      = vm.NewEVM(blockCtx, vm.TxContext{GasPrice: new(big.Int)}, state, api.b.ChainConfig(), vm.Config{NoBaseFee: true})
+	)
+	nonce, err := core.GetRip7712Nonce(evm, sender, key.ToInt())
+	return hexutil.Uint64(nonce), err
+}
+
+// GetDeposit returns the gas deposit of the given paymaster held by the
+// DepositManager at the given block, defaulting to the latest one. Deposits are
+// only charged if paymasters pay for gas from them on this chain, see GetConfig.
+func (api *AccountAbstractionAPI) GetDeposit(ctx context.Context, paymaster common.Address, blockNrOrHash *rpc.BlockNumberOrHash) (*hexutil.Big, error) {
+	bNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if blockNrOrHash != nil {
+		bNrOrHash = *blockNrOrHash
+	}
+	state, _, err := api.b.StateAndHeaderByNumberOrHash(ctx, bNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	deposit := core.GetRip7560Deposit(state, paymaster)
+	return (*hexutil.Big)(deposit.ToBig()), state.Error()
+}
+
+// Methods by which GetAccountInfo detects the support of RIP-7560 validation.
+const (
+	AccountDetectionERC165   = "erc165"   // The account implements supportsInterface for the validation interface
+	AccountDetectionBytecode = "bytecode" // The code of the account pushes the validateTransaction selector
+)
+
+// erc165Gas is the gas limit of supportsInterface calls, as set by ERC-165.
+const erc165Gas = 30000
+
+// erc165ID is the ERC-165 interface identifier of supportsInterface itself.
+var erc165ID = [4]byte{0x01, 0xff, 0xc9, 0xa7}
+
+// AccountNonce is the nonce of an account for a nonce key.
+type AccountNonce struct {
+	Key   *hexutil.Big   `json:"key"`
+	Nonce hexutil.Uint64 `json:"nonce"`
+}
+
+// AccountInfo tells whether an address is a smart account able to send RIP-7560
+// transactions, along with its nonces.
+type AccountInfo struct {
+	HasCode            bool           `json:"hasCode"`
+	CodeHash           common.Hash    `json:"codeHash"`
+	SupportsValidation bool           `json:"supportsValidation"`
+	Detection          string         `json:"detection,omitempty"`
+	Nonces             []AccountNonce `json:"nonces"`
+}
+
+// GetAccountInfo reports whether the given address has code and whether it
+// exposes the RIP-7560 validation entry point, at the given block, defaulting
+// to the latest one. Accounts are first asked through ERC-165 for the interface
+// of validateTransaction, and otherwise their code is searched for a push of its
+// selector. The latter is only a heuristic, as the selector may be matched by
+// the fallback of the account or pushed for another purpose.
+//
+// The nonces are reported for the legacy key and the given RIP-7712 nonce keys,
+// the latter requiring the nonce manager to be active.
+func (api *AccountAbstractionAPI) GetAccountInfo(ctx context.Context, address common.Address, keys []*hexutil.Big, blockNrOrHash *rpc.BlockNumberOrHash) (*AccountInfo, error) {
+	bNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if blockNrOrHash != nil {
+		bNrOrHash = *blockNrOrHash
+	}
+	state, header, err := api.b.StateAndHeaderByNumberOrHash(ctx, bNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	var (
+		config   = api.b.ChainConfig()
+		blockCtx = core.NewEVMBlockContext(header, NewChainContext(ctx, api.b), nil)
+		evm      = vm.NewEVM(blockCtx, vm.TxContext{GasPrice: new(big.Int)}, state, config, vm.Config{NoBaseFee: true})
+		code     = state.GetCode(address)
+		info     = &AccountInfo{
+			HasCode:  len(code) > 0,
+			CodeHash: state.GetCodeHash(address),
+			Nonces:   []AccountNonce{{Key: new(hexutil.Big), Nonce: hexutil.Uint64(state.GetNonce(address))}},
+		}
+	)
+	if info.HasCode {
+		frameABI, err := aa.Lookup(config.Rip7560AbiVersion())
+		if err != nil {
+			return nil, err
+		}
+		var selector [4]byte
+		copy(selector[:], frameABI.ABI.Methods["validateTransaction"].ID)
+
+		switch {
+		case supportsInterface(evm, address, selector):
+			info.SupportsValidation, info.Detection = true, AccountDetectionERC165
+		case bytes.Contains(code, append([]byte{byte(vm.PUSH4)}, selector[:]...)):
+			info.SupportsValidation, info.Detection = true, AccountDetectionBytecode
+		}
+	}
+	for _, key := range keys {
+		if key == nil || key.ToInt().Sign() == 0 {
+			continue
+		}
+		if !config.IsRIP7712(header.Number, header.Time) {
+			return nil, errors.New("RIP-7712 nonce is disabled")
+		}
+		nonce, err := core.GetRip7712Nonce(evm, address, key.ToInt())
+		if err != nil {
+			return nil, err
+		}
+		info.Nonces = append(info.Nonces, AccountNonce{Key: key, Nonce: hexutil.Uint64(nonce)})
+	}
+	return info, state.Error()
+}
+
+// supportsInterface reports whether the contract implements the given interface
+// as detected by ERC-165: it has to claim support of ERC-165 itself and of the
+// interface, while denying the invalid 0xffffffff identifier.
+func supportsInterface(evm *vm.EVM, addr common.Address, id [4]byte) bool {
+	query := func(id [4]byte) (bool, bool) {
+		// The selector of supportsInterface(bytes4) is the ERC-165 identifier
+		input := append(erc165ID[:], common.RightPadBytes(id[:], 32)...)
+		ret, _, err := evm.StaticCall(vm.AccountRef(common.Address{}), addr, input, erc165Gas)
+		if err != nil || len(ret) < 32 {
+			return false, false
+		}
+		return new(big.Int).SetBytes(ret[:32]).Cmp(common.Big1) == 0, true
+	}
+	if ok, valid := query(erc165ID); !ok || !valid {
+		return false
+	}
+	if ok, valid := query([4]byte{0xff, 0xff, 0xff, 0xff}); ok || !valid {
+		return false
+	}
+	ok, _ := query(id)
+	return ok
+}
+
+// Statuses of a RIP-7560 transaction reported by the transaction status
+// subscription.
+const (
+	TxStatusIncluded   = "included"   // Included in a canonical block
+	TxStatusReorged    = "reorged"    // The including block was reorged out
+	TxStatusReincluded = "reincluded" // Included again after being reorged out
+	TxStatusDropped    = "dropped"    // Removed from the pool without being included
+)
+
+// TransactionStatusEvent is a change of the status of a RIP-7560 transaction.
+type TransactionStatusEvent struct {
+	TxHash      common.Hash     `json:"transactionHash"`
+	Status      string          `json:"status"`
+	BlockHash   *common.Hash    `json:"blockHash,omitempty"`
+	BlockNumber *hexutil.Uint64 `json:"blockNumber,omitempty"`
+	Reason      string          `json:"reason,omitempty"`
+}
+
+// inclusionTracker follows the position of a transaction in the canonical chain
+// across reorgs.
+type inclusionTracker struct {
+	hash    common.Hash
+	block   common.Hash // Hash of the block including the transaction, zero if not included
+	number  uint64      // Number of the block including the transaction
+	reorged bool        // Whether the transaction was included and reorged out before
+}
+
+// update records the current canonical location of the transaction, returning
+// the status change, if any.
+func (t *inclusionTracker) update(found bool, block common.Hash, number uint64) *TransactionStatusEvent {
+	switch {
+	case found && block != t.block:
+		status := TxStatusIncluded
+		if t.reorged || t.block != (common.Hash{}) {
+			status = TxStatusReincluded
+		}
+		t.block, t.number = block, number
+		return &TransactionStatusEvent{TxHash: t.hash, Status: status, BlockHash: &block, BlockNumber: (*hexutil.Uint64)(&number)}
+
+	case !found && t.block != (common.Hash{}):
+		block, number := t.block, t.number
+		t.block, t.number, t.reorged = common.Hash{}, 0, true
+		return &TransactionStatusEvent{TxHash: t.hash, Status: TxStatusReorged, BlockHash: &block, BlockNumber: (*hexutil.Uint64)(&number)}
+	}
+	return nil
+}
+
+// TransactionStatus creates a subscription notifying whenever the given RIP-7560
+// transaction is included in the canonical chain, reorged out of it, included
+// again or dropped from the transaction pool. A transaction already included at
+// the time of subscribing is reported right away.
+func (api *AccountAbstractionAPI) TransactionStatus(ctx context.Context, hash common.Hash) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		var (
+			heads   = make(chan core.ChainHeadEvent, 16)
+			drops   = make(chan core.Rip7560TxDroppedEvent, 16)
+			headSub = api.b.SubscribeChainHeadEvent(heads)
+			dropSub = api.b.SubscribeRip7560DroppedEvent(drops)
+			tracker = &inclusionTracker{hash: hash}
+		)
+		defer headSub.Unsubscribe()
+		defer dropSub.Unsubscribe()
+
+		// check reports the changes of the inclusion status, returning false if
+		// the transaction index is not ready yet
+		check := func() bool {
+			found, _, block, number, _, err := api.b.GetTransaction(context.Background(), hash)
+			if err != nil {
+				return false
+			}
+			if ev := tracker.update(found, block, number); ev != nil {
+				notifier.Notify(rpcSub.ID, ev)
+			}
+			return true
+		}
+		// Transactions leave the pool when their nonce is used, which is not a
+		// drop if they are the ones included. Drops are thus only reported once
+		// the index confirms the transaction is not in the chain.
+		var dropReason *string
+		checkDrop := func() {
+			if dropReason == nil || !check() {
+				return
+			}
+			if tracker.block == (common.Hash{}) {
+				notifier.Notify(rpcSub.ID, &TransactionStatusEvent{TxHash: hash, Status: TxStatusDropped, Reason: *dropReason})
+			}
+			dropReason = nil
+		}
+		check()
+		for {
+			select {
+			case <-heads:
+				if dropReason != nil {
+					checkDrop()
+				} else {
+					check()
+				}
+			case ev := <-drops:
+				if ev.Tx.Hash() != hash {
+					continue
+				}
+				dropReason = &ev.Reason
+				checkDrop()
+			case <-rpcSub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+// KnownAccount is the expected storage of an account, given either by its
+// storage root or by the values of some of its slots.
+type KnownAccount struct {
+	StorageRoot  *common.Hash
+	StorageSlots map[common.Hash]common.Hash
+}
+
+// UnmarshalJSON decodes a known account from either a storage root hash or an
+// object of slot values.
+func (a *KnownAccount) UnmarshalJSON(input []byte) error {
+	var root common.Hash
+	if err := json.Unmarshal(input, &root); err == nil {
+		a.StorageRoot, a.StorageSlots = &root, nil
+		return nil
+	}
+	var slots map[common.Hash]common.Hash
+	if err := json.Unmarshal(input, &slots); err != nil {
+		return errors.New("known account must be a storage root or an object of storage slots")
+	}
+	a.StorageRoot, a.StorageSlots = nil, slots
+	return nil
+}
+
+// TransactionConditions are the conditions a RIP-7560 transaction is submitted
+// under, in the style of eth_sendRawTransactionConditional. They are checked
+// against the latest block when the transaction is submitted only.
+type TransactionConditions struct {
+	BlockNumberMin *hexutil.Big                     `json:"blockNumberMin,omitempty"`
+	BlockNumberMax *hexutil.Big                     `json:"blockNumberMax,omitempty"`
+	TimestampMin   *hexutil.Uint64                  `json:"timestampMin,omitempty"`
+	TimestampMax   *hexutil.Uint64                  `json:"timestampMax,omitempty"`
+	KnownAccounts  map[common.Address]*KnownAccount `json:"knownAccounts,omitempty"`
+}
+
+// check returns an error if the conditions are not met by the next block on
+// top of the given header and state.
+func (c *TransactionConditions) check(header *types.Header, state *state.StateDB) error {
+	next := new(big.Int).Add(header.Number, common.Big1)
+	if c.BlockNumberMin != nil && next.Cmp(c.BlockNumberMin.ToInt()) < 0 {
+		return &conditionError{fmt.Sprintf("block number %v below minimum %v", next, c.BlockNumberMin.ToInt())}
+	}
+	if c.BlockNumberMax != nil && next.Cmp(c.BlockNumberMax.ToInt()) > 0 {
+		return &conditionError{fmt.Sprintf("block number %v above maximum %v", next, c.BlockNumberMax.ToInt())}
+	}
+	if c.TimestampMin != nil && header.Time < uint64(*c.TimestampMin) {
+		return &conditionError{fmt.Sprintf("timestamp %d below minimum %d", header.Time, *c.TimestampMin)}
+	}
+	if c.TimestampMax != nil && header.Time > uint64(*c.TimestampMax) {
+		return &conditionError{fmt.Sprintf("timestamp %d above maximum %d", header.Time, *c.TimestampMax)}
+	}
+	for addr, account := range c.KnownAccounts {
+		if account.StorageRoot != nil {
+			if root := state.GetStorageRoot(addr); root != *account.StorageRoot {
+				return &conditionError{fmt.Sprintf("storage root of %v mismatch: have %v, want %v", addr, root, *account.StorageRoot)}
+			}
+			continue
+		}
+		for slot, want := range account.StorageSlots {
+			if have := state.GetState(addr, slot); have != want {
+				return &conditionError{fmt.Sprintf("storage slot %v of %v mismatch: have %v, want %v", slot, addr, have, want)}
+			}
+		}
+	}
+	return nil
+}
+
+// SendTransaction submits a signed RIP-7560 transaction given as a transaction
+// object to the transaction pool, provided that the optional conditions are met
+// by the latest block.
+func (api *AccountAbstractionAPI) SendTransaction(ctx context.Context, args TransactionArgs, conditions *TransactionConditions) (common.Hash, error) {
+	if args.Sender == nil {
+		return common.Hash{}, errors.New("missing sender")
+	}
+	// All the fields are covered by the signature, only the empty ones can be
+	// filled in
+	switch {
+	case args.Nonce == nil:
+		return common.Hash{}, errors.New("missing nonce")
+	case args.Gas == nil:
+		return common.Hash{}, errors.New("missing gas")
+	case args.MaxFeePerGas == nil || args.MaxPriorityFeePerGas == nil:
+		return common.Hash{}, errors.New("missing maxFeePerGas or maxPriorityFeePerGas")
+	}
+	if err := args.set7560Defaults(ctx, api.b); err != nil {
+		return common.Hash{}, err
+	}
+	if err := args.setChainID(api.b.ChainConfig().ChainID); err != nil {
+		return common.Hash{}, err
+	}
+	if args.AuthorizationData == nil {
+		args.AuthorizationData = new(hexutil.Bytes)
+	}
+	if args.ExecutionData == nil {
+		args.ExecutionData = new(hexutil.Bytes)
+	}
+	if conditions != nil {
+		state, header, err := api.b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+		if state == nil || err != nil {
+			return common.Hash{}, err
+		}
+		if err := conditions.check(header, state); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	return SubmitTransaction(ctx, api.b, args.ToTransaction())
+}
+
+// GetTransactionReceipt returns the receipt of an included RIP-7560 transaction.
+// On top of the fields of eth_getTransactionReceipt, it holds the sender, the
+// paymaster, the deployer, the nonce and the execution status of the
+// transaction.
+func (api *AccountAbstractionAPI) GetTransactionReceipt(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
+	found, tx, blockHash, blockNumber, index, err := api.b.GetTransaction(ctx, hash)
+	if err != nil {
+		return nil, NewTxIndexingError()
+	}
+	if !found {
+		return nil, nil
+	}
+	if tx.Type() != types.Rip7560Type {
+		return nil, errors.New("not a RIP-7560 transaction")
+	}
+	header, err := api.b.HeaderByHash(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := api.b.GetReceipts(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(receipts)) <= index {
+		return nil, nil
+	}
+	var (
+		receipt = receipts[index]
+		signer  = types.MakeSigner(api.b.ChainConfig(), header.Number, header.Time)
+		fields  = marshalReceipt(receipt, blockHash, blockNumber, signer, tx, int(index))
+		aatx    = tx.Rip7560TransactionData()
+	)
+	fields["from"] = aatx.Sender
+	fields["sender"] = aatx.Sender
+	fields["paymaster"] = aatx.Paymaster
+	fields["deployer"] = aatx.Deployer
+	fields["nonceKey"] = (*hexutil.Big)(aatx.NonceKey)
+	fields["nonce"] = hexutil.Uint64(aatx.Nonce)
+
+	event := core.Rip7560Abi.Events["RIP7560TransactionEvent"]
+	for _, log := range receipt.Logs {
+		if log.Address != core.AA_ENTRY_POINT || len(log.Topics) == 0 || log.Topics[0] != event.ID {
+			continue
+		}
+		values, err := event.Inputs.NonIndexed().Unpack(log.Data)
+		if err != nil || len(values) != 3 {
+			break
+		}
+		if status, ok := values[2].(*big.Int); ok {
+			fields["executionStatus"] = (*hexutil.Big)(status)
+		}
+		break
+	}
+	return fields, nil
+}
+
+// EstimateGas returns the lowest gas limits of the frames of a RIP-7560
+// transaction allowing both of its phases to succeed on top of the given block,
+// defaulting to the latest one. Every limit is binary searched independently.
+// Missing gas limits are replaced by the RPC gas cap, and the fees are ignored.
+func (api *AccountAbstractionAPI) EstimateGas(ctx context.Context, args TransactionArgs, blockNrOrHash *rpc.BlockNumberOrHash) (*types.Rip7560FrameGas, error) {
+	if args.Sender == nil {
+		return nil, errors.New("missing sender")
+	}
+	bNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if blockNrOrHash != nil {
+		bNrOrHash = *blockNrOrHash
+	}
+	state, header, err := api.b.StateAndHeaderByNumberOrHash(ctx, bNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	if err := args.set7560Defaults(ctx, api.b); err != nil {
+		return nil, err
+	}
+	if err := args.setChainID(api.b.ChainConfig().ChainID); err != nil {
+		return nil, err
+	}
+	if args.Nonce == nil {
+		nonce, err := api.GetNonce(ctx, *args.Sender, args.NonceKey, &bNrOrHash)
+		if err != nil {
+			return nil, err
+		}
+		args.Nonce = &nonce
+	}
+	if args.AuthorizationData == nil {
+		args.AuthorizationData = new(hexutil.Bytes)
+	}
+	if args.ExecutionData == nil {
+		args.ExecutionData = new(hexutil.Bytes)
+	}
+	gasCap := hexutil.Uint64(api.b.RPCGasCap())
+	if gasCap == 0 {
+		gasCap = hexutil.Uint64(header.GasLimit)
+	}
+	for _, limit := range []**hexutil.Uint64{&args.Gas, &args.ValidationGas} {
+		if *limit == nil {
+			*limit = &gasCap
+		}
+	}
+	if *args.Paymaster != (common.Address{}) {
+		for _, limit := range []**hexutil.Uint64{&args.PaymasterGas, &args.PostOpGas} {
+			if *limit == nil {
+				*limit = &gasCap
+			}
+		}
+	}
+	// Gas is not charged, so that the estimate doesn't depend on the balance
+	// of the payer
+	args.MaxFeePerGas, args.MaxPriorityFeePerGas = new(hexutil.Big), new(hexutil.Big)
+
+	tx := args.ToTransaction()
+	opts := &gasestimator.Options{
+		Config:     api.b.ChainConfig(),
+		Chain:      NewChainContext(ctx, api.b),
+		Header:     header,
+		State:      state,
+		ErrorRatio: estimateGasErrorRatio,
+	}
+	gas, revert, err := gasestimator.EstimateRip7560(ctx, tx, opts)
+	if err != nil {
+		if len(revert) > 0 {
+			return nil, newRevertError(revert)
+		}
+		return nil, err
+	}
+	rpcusage.Record(ctx, uint64(*gas.UsedGas), tx.Size())
+	return gas, nil
+}
+
+// Call runs both phases of a RIP-7560 transaction on top of the given block,
+// defaulting to the latest one, without committing its changes. It returns the
+// output of the execution, its logs and the gas used by every frame, failing
+// if the transaction doesn't pass validation. Missing gas limits are replaced
+// by the RPC gas cap, and the transaction isn't charged unless its fees are set.
+func (api *AccountAbstractionAPI) Call(ctx context.Context, args TransactionArgs, blockNrOrHash *rpc.BlockNumberOrHash, overrides *StateOverride) (*types.Rip7560CallResult, error) {
+	if args.Sender == nil {
+		return nil, errors.New("missing sender")
+	}
+	bNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if blockNrOrHash != nil {
+		bNrOrHash = *blockNrOrHash
+	}
+	state, header, err := api.b.StateAndHeaderByNumberOrHash(ctx, bNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	if err := overrides.Apply(state); err != nil {
+		return nil, err
+	}
+	if err := args.set7560Defaults(ctx, api.b); err != nil {
+		return nil, err
+	}
+	if err := args.setChainID(api.b.ChainConfig().ChainID); err != nil {
+		return nil, err
+	}
+	if args.Nonce == nil {
+		nonce, err := api.GetNonce(ctx, *args.Sender, args.NonceKey, &bNrOrHash)
+		if err != nil {
+			return nil, err
+		}
+		args.Nonce = &nonce
+	}
+	if args.AuthorizationData == nil {
+		args.AuthorizationData = new(hexutil.Bytes)
+	}
+	if args.ExecutionData == nil {
+		args.ExecutionData = new(hexutil.Bytes)
+	}
+	gasCap := hexutil.Uint64(api.b.RPCGasCap())
+	if gasCap == 0 {
+		gasCap = hexutil.Uint64(header.GasLimit)
+	}
+	for _, limit := range []**hexutil.Uint64{&args.Gas, &args.ValidationGas} {
+		if *limit == nil {
+			*limit = &gasCap
+		}
+	}
+	if *args.Paymaster != (common.Address{}) {
+		for _, limit := range []**hexutil.Uint64{&args.PaymasterGas, &args.PostOpGas} {
+			if *limit == nil {
+				*limit = &gasCap
+			}
+		}
+	}
+	if args.MaxFeePerGas == nil && args.MaxPriorityFeePerGas == nil {
+		args.MaxFeePerGas, args.MaxPriorityFeePerGas = new(hexutil.Big), new(hexutil.Big)
+	}
+	if args.MaxFeePerGas == nil || args.MaxPriorityFeePerGas == nil {
+		return nil, errors.New("both maxFeePerGas and maxPriorityFeePerGas must be set")
+	}
+	tx := args.ToTransaction()
+	result, err := core.CallRip7560Transaction(api.b.ChainConfig(), NewChainContext(ctx, api.b), header, state, tx, vm.Config{NoBaseFee: true})
+	if err != nil {
+		return nil, err
+	}
+	rpcusage.Record(ctx, uint64(result.GasUsed), tx.Size())
+	return result, nil
+}
@@ -0,0 +1,173 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/rpcusage"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// maxSimulationSessions is the maximum number of simulation sessions open
+	// at the same time.
+	maxSimulationSessions = 16
+
+	// simulationSessionTTL is the time a simulation session is kept without
+	// being used.
+	simulationSessionTTL = 10 * time.Minute
+)
+
+var (
+	errSessionNotFound = errors.New("simulation session not found")
+	errTooManySessions = errors.New("too many simulation sessions")
+)
+
+// simulationSession is a state overlaid with overrides once, on which many
+// calls and validations are simulated.
+type simulationSession struct {
+	state    *state.StateDB // Overridden state, copied for every simulation
+	header   *types.Header
+	lastUsed time.Time
+}
+
+// simulationSessions tracks the open simulation sessions by identifier.
+type simulationSessions struct {
+	sessions map[rpc.ID]*simulationSession
+	mu       sync.Mutex
+}
+
+func newSimulationSessions() *simulationSessions {
+	return &simulationSessions{sessions: make(map[rpc.ID]*simulationSession)}
+}
+
+// open adds a session on the given state, closing the expired ones first.
+func (s *simulationSessions) open(state *state.StateDB, header *types.Header) (rpc.ID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(time.Now())
+	if len(s.sessions) >= maxSimulationSessions {
+		return "", errTooManySessions
+	}
+	id := rpc.NewID()
+	s.sessions[id] = &simulationSession{state: state, header: header, lastUsed: time.Now()}
+	return id, nil
+}
+
+// get returns a copy of the state of a session, along with its header.
+func (s *simulationSessions) get(id rpc.ID) (*state.StateDB, *types.Header, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.expire(now)
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, nil, errSessionNotFound
+	}
+	session.lastUsed = now
+	return session.state.Copy(), session.header, nil
+}
+
+// close removes a session, reporting whether it was open.
+func (s *simulationSessions) close(id rpc.ID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.sessions[id]
+	delete(s.sessions, id)
+	return ok
+}
+
+// expire removes the sessions unused for longer than their time to live. The
+// lock must be held.
+func (s *simulationSessions) expire(now time.Time) {
+	for id, session := range s.sessions {
+		if now.Sub(session.lastUsed) > simulationSessionTTL {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+// CreateSimulationSession applies the given state overrides to the state of the
+// given block, defaulting to the latest one, and keeps the result for the calls
+// and validations simulated in the session. The overrides are sent once, instead
+// of with every request. Sessions are closed after ten minutes without use.
+func (api *DebugAPI) CreateSimulationSession(ctx context.Context, blockNrOrHash *rpc.BlockNumberOrHash, overrides *StateOverride) (rpc.ID, error) {
+	bNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if blockNrOrHash != nil {
+		bNrOrHash = *blockNrOrHash
+	}
+	state, header, err := api.b.StateAndHeaderByNumberOrHash(ctx, bNrOrHash)
+	if state == nil || err != nil {
+		return "", err
+	}
+	if err := overrides.Apply(state); err != nil {
+		return "", err
+	}
+	return api.sessions.open(state, header)
+}
+
+// SimulationSessionCall executes a call on the state of a simulation session,
+// like eth_call. The state changes of the call are discarded, the additional
+// overrides only apply to the call.
+func (api *DebugAPI) SimulationSessionCall(ctx context.Context, id rpc.ID, args TransactionArgs, overrides *StateOverride, blockOverrides *BlockOverrides) (hexutil.Bytes, error) {
+	state, header, err := api.sessions.get(id)
+	if err != nil {
+		return nil, err
+	}
+	result, err := doCall(ctx, api.b, args, state, header, overrides, blockOverrides, api.b.RPCEVMTimeout(), api.b.RPCGasCap())
+	if err != nil {
+		return nil, err
+	}
+	// If the result contains a revert reason, try to unpack and return it.
+	if len(result.Revert()) > 0 {
+		return nil, newRevertError(result.Revert())
+	}
+	return result.Return(), result.Err
+}
+
+// SimulationSessionValidate simulates the validation phase of a RIP-7560
+// transaction on the state of a simulation session, like aa_validateTransaction.
+func (api *DebugAPI) SimulationSessionValidate(ctx context.Context, id rpc.ID, args TransactionArgs) (*types.ValidationReport, error) {
+	if args.Sender == nil {
+		return nil, errors.New("missing sender")
+	}
+	state, header, err := api.sessions.get(id)
+	if err != nil {
+		return nil, err
+	}
+	tx := args.ToTransaction()
+	report := core.SimulateRip7560Validation(api.b.ChainConfig(), NewChainContext(ctx, api.b), header, state, tx)
+	rpcusage.Record(ctx, uint64(report.GasUsed), tx.Size())
+	return report, nil
+}
+
+// CloseSimulationSession closes a simulation session, reporting whether it was
+// open.
+func (api *DebugAPI) CloseSimulationSession(id rpc.ID) bool {
+	return api.sessions.close(id)
+}
@@ -0,0 +1,105 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the calls of a simulation session run on the overrides given once
+// when creating the session, without leaking into each other.
+func TestSimulationSession(t *testing.T) {
+	t.Parallel()
+
+	var (
+		accounts = newAccounts(1)
+		genesis  = &core.Genesis{
+			Config: params.MergedTestChainConfig,
+			Alloc: types.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			},
+		}
+		contract = common.Address{0xcc}
+		// Returns the first storage slot and then overwrites it
+		code = hexutil.Bytes{
+			byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.PUSH1), 0, byte(vm.MSTORE),
+			byte(vm.PUSH1), 0xff, byte(vm.PUSH1), 0, byte(vm.SSTORE),
+			byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+		}
+		slot = func(v byte) *map[common.Hash]common.Hash {
+			return &map[common.Hash]common.Hash{{}: common.BytesToHash([]byte{v})}
+		}
+	)
+	api := NewDebugAPI(newTestBackend(t, 1, genesis, beacon.New(ethash.NewFaker()), func(i int, b *core.BlockGen) {
+		b.SetPoS()
+	}))
+	ctx := context.Background()
+	id, err := api.CreateSimulationSession(ctx, nil, &StateOverride{contract: {Code: &code, StateDiff: slot(0x2a)}})
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	call := func(overrides *StateOverride) (byte, error) {
+		ret, err := api.SimulationSessionCall(ctx, id, TransactionArgs{From: &accounts[0].addr, To: &contract}, overrides, nil)
+		if err != nil {
+			return 0, err
+		}
+		return ret[len(ret)-1], nil
+	}
+	// Every call sees the session state, whatever the previous calls wrote
+	for i := 0; i < 2; i++ {
+		if have, err := call(nil); err != nil || have != 0x2a {
+			t.Fatalf("call %d: result mismatch: have %#x, %v, want 0x2a", i, have, err)
+		}
+	}
+	// Call overrides apply on top of the session ones, to the call only
+	if have, err := call(&StateOverride{contract: {StateDiff: slot(0x01)}}); err != nil || have != 0x01 {
+		t.Fatalf("overridden call result mismatch: have %#x, %v, want 0x01", have, err)
+	}
+	if have, err := call(nil); err != nil || have != 0x2a {
+		t.Fatalf("result mismatch after overridden call: have %#x, %v, want 0x2a", have, err)
+	}
+	if !api.CloseSimulationSession(id) {
+		t.Fatal("open session not closed")
+	}
+	if api.CloseSimulationSession(id) {
+		t.Fatal("closed session closed again")
+	}
+	if _, err := call(nil); !errors.Is(err, errSessionNotFound) {
+		t.Fatalf("error mismatch: have %v, want %v", err, errSessionNotFound)
+	}
+	// The number of open sessions is capped
+	for i := 0; i < maxSimulationSessions; i++ {
+		if _, err := api.CreateSimulationSession(ctx, nil, nil); err != nil {
+			t.Fatalf("failed to create session %d: %v", i, err)
+		}
+	}
+	if _, err := api.CreateSimulationSession(ctx, nil, nil); !errors.Is(err, errTooManySessions) {
+		t.Fatalf("error mismatch: have %v, want %v", err, errTooManySessions)
+	}
+}
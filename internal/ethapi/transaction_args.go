@@ -75,10 +75,14 @@ type TransactionArgs struct {
 	// This configures whether blobs are allowed to be passed.
 	blobSidecarAllowed bool
 
+	// For SetCodeTxType
+	AuthorizationList []types.SetCodeAuthorization `json:"authorizationList"`
+
 	// Introduced by RIP-7560 Transaction
 	Sender            *common.Address `json:"sender"`
 	AuthorizationData *hexutil.Bytes  `json:"authorizationData,omitempty"`
 	ExecutionData     *hexutil.Bytes  `json:"executionData,omitempty"`
+	ExecutionCalls    []hexutil.Bytes `json:"executionCalls,omitempty"`
 	Paymaster         *common.Address `json:"paymaster,omitempty"`
 	PaymasterData     *hexutil.Bytes  `json:"paymasterData,omitempty"`
 	Deployer          *common.Address `json:"deployer,omitempty"`
@@ -145,11 +149,22 @@ func (args *TransactionArgs) setDefaults(ctx context.Context, b Backend, skipGas
 		return fmt.Errorf(`too many blobs in transaction (have=%d, max=%d)`, len(args.BlobHashes), maxBlobsPerTransaction)
 	}
 
+	// SetCodeTx fields
+	if args.AuthorizationList != nil && len(args.AuthorizationList) == 0 {
+		return errors.New(`need at least 1 authorization for a set code transaction`)
+	}
+	if args.AuthorizationList != nil && args.BlobHashes != nil {
+		return errors.New(`set code transaction can't carry blobs`)
+	}
+
 	// create check
 	if args.To == nil {
 		if args.BlobHashes != nil {
 			return errors.New(`missing "to" in blob transaction`)
 		}
+		if args.AuthorizationList != nil {
+			return errors.New(`missing "to" in set code transaction`)
+		}
 		if len(args.data()) == 0 {
 			return errors.New(`contract creation without any data provided`)
 		}
@@ -177,6 +192,7 @@ func (args *TransactionArgs) setDefaults(ctx context.Context, b Backend, skipGas
 				AccessList:           args.AccessList,
 				BlobFeeCap:           args.BlobFeeCap,
 				BlobHashes:           args.BlobHashes,
+				AuthorizationList:    args.AuthorizationList,
 			}
 			latestBlockNr := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
 			estimated, err := DoEstimateGas(ctx, b, callArgs, latestBlockNr, nil, b.RPCGasCap())
@@ -188,9 +204,12 @@ func (args *TransactionArgs) setDefaults(ctx context.Context, b Backend, skipGas
 		}
 	}
 
-	// If chain id is provided, ensure it matches the local chain id. Otherwise, set the local
-	// chain id as the default.
-	want := b.ChainConfig().ChainID
+	return args.setChainID(b.ChainConfig().ChainID)
+}
+
+// setChainID ensures that the chain id matches the local chain id if provided.
+// Otherwise, it sets the local chain id as the default.
+func (args *TransactionArgs) setChainID(want *big.Int) error {
 	if args.ChainID != nil {
 		if have := (*big.Int)(args.ChainID); have.Cmp(want) != 0 {
 			return fmt.Errorf("chainId does not match node's (have=%v, want=%v)", have, want)
@@ -207,15 +226,16 @@ func (args *TransactionArgs) set7560Defaults(ctx context.Context, b Backend) err
 		return nil
 	}
 	if args.Paymaster == nil {
-		log.Error("set7560Defaults setting default paymaster fields")
 		args.Paymaster = &common.Address{}
 		args.PaymasterData = &hexutil.Bytes{}
 	}
 	if args.Deployer == nil {
-		log.Error("set7560Defaults setting default deployer fields")
 		args.Deployer = &common.Address{}
 		args.DeployerData = &hexutil.Bytes{}
 	}
+	if args.ExecutionData == nil && len(args.ExecutionCalls) != 0 {
+		args.ExecutionData = &hexutil.Bytes{}
+	}
 	return nil
 
 }
@@ -234,6 +254,10 @@ func (args *TransactionArgs) setFeeDefaults(ctx context.Context, b Backend) erro
 	if args.GasPrice != nil && (args.MaxFeePerGas != nil || args.MaxPriorityFeePerGas != nil) {
 		return errors.New("both gasPrice and (maxFeePerGas or maxPriorityFeePerGas) specified")
 	}
+	// Set code transactions only support the EIP-1559 fee parameters.
+	if args.GasPrice != nil && args.AuthorizationList != nil {
+		return errors.New("gasPrice is not supported by set code transactions, use maxFeePerGas and maxPriorityFeePerGas")
+	}
 	// If the tx has completely specified a fee mechanism, no default is needed.
 	// This allows users who are not yet synced past London to get defaults for
 	// other tx values. See https://github.com/ethereum/go-ethereum/pull/23274
@@ -270,6 +294,9 @@ func (args *TransactionArgs) setFeeDefaults(ctx context.Context, b Backend) erro
 		if args.MaxFeePerGas != nil || args.MaxPriorityFeePerGas != nil {
 			return errors.New("maxFeePerGas and maxPriorityFeePerGas are not valid before London is active")
 		}
+		if args.AuthorizationList != nil {
+			return errors.New("set code transactions are not valid before London is active")
+		}
 		// London not active, set gas price.
 		price, err := b.SuggestGasTipCap(ctx)
 		if err != nil {
@@ -502,6 +529,8 @@ func (args *TransactionArgs) ToMessage(baseFee *big.Int) *core.Message {
 		BlobGasFeeCap:     (*big.Int)(args.BlobFeeCap),
 		BlobHashes:        args.BlobHashes,
 		SkipAccountChecks: true,
+
+		SetCodeAuthorizations: args.AuthorizationList,
 	}
 }
 
@@ -545,6 +574,9 @@ func (args *TransactionArgs) ToTransaction() *types.Transaction {
 			PaymasterValidationGasLimit: toUint64(args.PaymasterGas),
 			PostOpGas:                   toUint64(args.PostOpGas),
 		}
+		for _, call := range args.ExecutionCalls {
+			aatx.ExecutionCalls = append(aatx.ExecutionCalls, call)
+		}
 
 		zeroAddress := common.Address{}
 		if aatx.Paymaster != nil && zeroAddress.Cmp(*aatx.Paymaster) == 0 {
@@ -555,8 +587,23 @@ func (args *TransactionArgs) ToTransaction() *types.Transaction {
 		}
 
 		data = &aatx
-		hash := types.NewTx(data).Hash()
-		log.Error("RIP-7560 transaction created", "sender", aatx.Sender.Hex(), "hash", hash)
+	case args.AuthorizationList != nil:
+		al := types.AccessList{}
+		if args.AccessList != nil {
+			al = *args.AccessList
+		}
+		data = &types.SetCodeTx{
+			To:         *args.To,
+			ChainID:    uint256.MustFromBig((*big.Int)(args.ChainID)),
+			Nonce:      uint64(*args.Nonce),
+			Gas:        uint64(*args.Gas),
+			GasFeeCap:  uint256.MustFromBig((*big.Int)(args.MaxFeePerGas)),
+			GasTipCap:  uint256.MustFromBig((*big.Int)(args.MaxPriorityFeePerGas)),
+			Value:      uint256.MustFromBig((*big.Int)(args.Value)),
+			Data:       args.data(),
+			AccessList: al,
+			AuthList:   args.AuthorizationList,
+		}
 	case args.BlobHashes != nil:
 		al := types.AccessList{}
 		if args.AccessList != nil {
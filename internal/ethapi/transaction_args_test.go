@@ -228,6 +228,28 @@ func TestSetFeeDefaults(t *testing.T) {
 			&TransactionArgs{BlobHashes: []common.Hash{}, BlobFeeCap: (*hexutil.Big)(big.NewInt(4)), MaxFeePerGas: maxFee, MaxPriorityFeePerGas: fortytwo},
 			nil,
 		},
+		// EIP-7702
+		{
+			"set gas price for set code transaction",
+			"london",
+			&TransactionArgs{GasPrice: fortytwo, AuthorizationList: []types.SetCodeAuthorization{}},
+			nil,
+			errors.New("gasPrice is not supported by set code transactions, use maxFeePerGas and maxPriorityFeePerGas"),
+		},
+		{
+			"set code transaction pre-London",
+			"legacy",
+			&TransactionArgs{AuthorizationList: []types.SetCodeAuthorization{}},
+			nil,
+			errors.New("set code transactions are not valid before London is active"),
+		},
+		{
+			"fill dynamic fees for set code transaction",
+			"london",
+			&TransactionArgs{AuthorizationList: []types.SetCodeAuthorization{}},
+			&TransactionArgs{AuthorizationList: []types.SetCodeAuthorization{}, MaxFeePerGas: maxFee, MaxPriorityFeePerGas: fortytwo},
+			nil,
+		},
 	}
 
 	ctx := context.Background()
@@ -321,8 +343,8 @@ func (b *backendMock) ChainConfig() *params.ChainConfig { return b.config }
 
 // Other methods needed to implement Backend interface.
 func (b *backendMock) SyncProgress() ethereum.SyncProgress { return ethereum.SyncProgress{} }
-func (b *backendMock) FeeHistory(ctx context.Context, blockCount uint64, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []*big.Int, []float64, []*big.Int, []float64, error) {
-	return nil, nil, nil, nil, nil, nil, nil
+func (b *backendMock) FeeHistory(ctx context.Context, blockCount uint64, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []*big.Int, []float64, []*big.Int, []float64, []float64, error) {
+	return nil, nil, nil, nil, nil, nil, nil, nil
 }
 func (b *backendMock) ChainDb() ethdb.Database           { return nil }
 func (b *backendMock) AccountManager() *accounts.Manager { return nil }
@@ -403,3 +425,27 @@ func (b *backendMock) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent)
 }
 
 func (b *backendMock) Engine() consensus.Engine { return nil }
+
+func (b *backendMock) Rip7560Capabilities() Rip7560Capabilities {
+	return Rip7560Capabilities{}
+}
+func (b *backendMock) Rip7560ReplacementFee(hash common.Hash) (*big.Int, *big.Int, error) {
+	return nil, nil, nil
+}
+func (b *backendMock) Rip7560ViolationStats() (map[string]uint64, error) {
+	return nil, nil
+}
+func (b *backendMock) Rip7560ValidationBacklog() (int, error) {
+	return 0, nil
+}
+func (b *backendMock) SubscribeRip7560DroppedEvent(ch chan<- core.Rip7560TxDroppedEvent) event.Subscription {
+	return nil
+}
+func (b *backendMock) SubmitRip7560Bundle(bundle *types.ExternallyReceivedBundle) error { return nil }
+func (b *backendMock) GetRip7560BundleStatus(ctx context.Context, hash common.Hash) (*types.BundleReceipt, error) {
+	return nil, nil
+}
+func (b *backendMock) GetRip7560TransactionDebugInfo(common.Hash) (map[string]interface{}, error) {
+	return nil, nil
+}
+func (b *backendMock) SetRip7560TransactionDebugInfo(infos []*types.Rip7560TransactionDebugInfo) {}
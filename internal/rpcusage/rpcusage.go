@@ -0,0 +1,104 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package rpcusage accounts the simulation heavy RPC calls by the API key of the
+// caller, so that shared nodes can attribute their cost to tenants.
+package rpcusage
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxKeys is the maximum number of API keys tracked, bounding the memory used
+// by callers making up keys. The usage of further keys is not accounted.
+const maxKeys = 1024
+
+// Usage is the accounted usage of a single API key.
+type Usage struct {
+	Calls        hexutil.Uint64 `json:"calls"`        // Number of simulations run
+	SimulatedGas hexutil.Uint64 `json:"simulatedGas"` // Gas used by the simulations
+	Bytes        hexutil.Uint64 `json:"bytes"`        // Size of the simulated transactions
+}
+
+// Tracker accounts the usage of API keys.
+type Tracker struct {
+	keys  map[string]*Usage
+	limit int
+	mu    sync.Mutex
+}
+
+// NewTracker creates a tracker accounting up to the given number of API keys.
+func NewTracker(limit int) *Tracker {
+	return &Tracker{keys: make(map[string]*Usage), limit: limit}
+}
+
+// Record accounts a simulation to the API key of the caller. Calls without an
+// API key are not accounted.
+func (t *Tracker) Record(ctx context.Context, gas uint64, size uint64) {
+	key := rpc.PeerInfoFromContext(ctx).HTTP.APIKey
+	if key == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	usage := t.keys[key]
+	if usage == nil {
+		if len(t.keys) >= t.limit {
+			log.Debug("Too many API keys, usage not accounted", "limit", t.limit)
+			return
+		}
+		usage = new(Usage)
+		t.keys[key] = usage
+	}
+	usage.Calls++
+	usage.SimulatedGas += hexutil.Uint64(gas)
+	usage.Bytes += hexutil.Uint64(size)
+}
+
+// Usage returns the usage of all tracked API keys.
+func (t *Tracker) Usage() map[string]Usage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	usage := make(map[string]Usage, len(t.keys))
+	for key, u := range t.keys {
+		usage[key] = *u
+	}
+	return usage
+}
+
+// Reset forgets the usage of all API keys.
+func (t *Tracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.keys = make(map[string]*Usage)
+}
+
+// DefaultTracker is the tracker shared by all the RPC APIs of the node.
+var DefaultTracker = NewTracker(maxKeys)
+
+// Record accounts a simulation to the API key of the caller in the default
+// tracker.
+func Record(ctx context.Context, gas uint64, size uint64) {
+	DefaultTracker.Record(ctx, gas, size)
+}
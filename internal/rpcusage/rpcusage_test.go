@@ -0,0 +1,80 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpcusage
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testService runs a simulation of the given gas and size when called.
+type testService struct {
+	tracker *Tracker
+}
+
+func (s *testService) Simulate(ctx context.Context, gas uint64, size uint64) {
+	s.tracker.Record(ctx, gas, size)
+}
+
+func TestTrackerRecord(t *testing.T) {
+	var (
+		tracker = NewTracker(2)
+		server  = rpc.NewServer()
+	)
+	if err := server.RegisterName("test", &testService{tracker}); err != nil {
+		t.Fatal(err)
+	}
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	call := func(key string, gas uint64, size uint64) {
+		client, err := rpc.Dial(httpsrv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+		if key != "" {
+			client.SetHeader("X-Api-Key", key)
+		}
+		if err := client.Call(nil, "test_simulate", gas, size); err != nil {
+			t.Fatal(err)
+		}
+	}
+	call("alice", 100, 10)
+	call("alice", 50, 5)
+	call("bob", 1, 1)
+	call("", 1000, 1000)  // not accounted without a key
+	call("carol", 10, 10) // not accounted above the limit
+
+	usage := tracker.Usage()
+	if len(usage) != 2 {
+		t.Fatalf("tracked keys mismatch: have %d, want 2", len(usage))
+	}
+	if have, want := usage["alice"], (Usage{Calls: 2, SimulatedGas: 150, Bytes: 15}); have != want {
+		t.Fatalf("usage mismatch: have %+v, want %+v", have, want)
+	}
+	if have, want := usage["bob"], (Usage{Calls: 1, SimulatedGas: 1, Bytes: 1}); have != want {
+		t.Fatalf("usage mismatch: have %+v, want %+v", have, want)
+	}
+	tracker.Reset()
+	if usage := tracker.Usage(); len(usage) != 0 {
+		t.Fatalf("usage not reset: %v", usage)
+	}
+}
@@ -31,6 +31,9 @@ var Modules = map[string]string{
 	"les":      LESJs,
 	"vflux":    VfluxJs,
 	"dev":      DevJs,
+	"aa":       AAJs,
+	"trace":    TraceJs,
+	"rip7560":  Rip7560Js,
 }
 
 const CliqueJs = `
@@ -158,6 +161,25 @@ web3._extend({
 			call: 'admin_sleepBlocks',
 			params: 2
 		}),
+		new web3._extend.Method({
+			name: 'reloadAAPolicy',
+			call: 'admin_reloadAAPolicy'
+		}),
+		new web3._extend.Method({
+			name: 'aaReputation',
+			call: 'admin_aaReputation'
+		}),
+		new web3._extend.Method({
+			name: 'setAAReputation',
+			call: 'admin_setAAReputation',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'rpcUsage',
+			call: 'admin_rpcUsage',
+			params: 1,
+			inputFormatter: [null]
+		}),
 		new web3._extend.Method({
 			name: 'startHTTP',
 			call: 'admin_startHTTP',
@@ -244,6 +266,35 @@ web3._extend({
 			call: 'debug_getRawTransaction',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'getRip7560FrameLogs',
+			call: 'debug_getRip7560FrameLogs',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+		new web3._extend.Method({
+			name: 'createSimulationSession',
+			call: 'debug_createSimulationSession',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'simulationSessionCall',
+			call: 'debug_simulationSessionCall',
+			params: 4,
+			inputFormatter: [null, web3._extend.formatters.inputCallFormatter, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'simulationSessionValidate',
+			call: 'debug_simulationSessionValidate',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+		new web3._extend.Method({
+			name: 'closeSimulationSession',
+			call: 'debug_closeSimulationSession',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'setHead',
 			call: 'debug_setHead',
@@ -515,6 +566,11 @@ web3._extend({
 			call: 'eth_chainId',
 			params: 0
 		}),
+		new web3._extend.Method({
+			name: 'config',
+			call: 'eth_config',
+			params: 0
+		}),
 		new web3._extend.Method({
 			name: 'sign',
 			call: 'eth_sign',
@@ -666,6 +722,12 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.utils.fromDecimal]
 		}),
+		new web3._extend.Method({
+			name: 'setRip7560GasShare',
+			call: 'miner_setRip7560GasShare',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		}),
 	],
 	properties: []
 });
@@ -885,6 +947,135 @@ web3._extend({
 			call: 'dev_setFeeRecipient',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'buildBranch',
+			call: 'dev_buildBranch',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'setHead',
+			call: 'dev_setHead',
+			params: 1
+		}),
 	],
 });
 `
+
+const AAJs = `
+web3._extend({
+	property: 'aa',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'getConfig',
+			call: 'aa_getConfig',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getSigningPayload',
+			call: 'aa_getSigningPayload',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'validateTransaction',
+			call: 'aa_validateTransaction',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getAccountInfo',
+			call: 'aa_getAccountInfo',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getNonce',
+			call: 'aa_getNonce',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getDeposit',
+			call: 'aa_getDeposit',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getReplacementFee',
+			call: 'aa_getReplacementFee',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getViolationStats',
+			call: 'aa_getViolationStats',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'sendTransaction',
+			call: 'aa_sendTransaction',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+		new web3._extend.Method({
+			name: 'getTransactionReceipt',
+			call: 'aa_getTransactionReceipt',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'estimateGas',
+			call: 'aa_estimateGas',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'call',
+			call: 'aa_call',
+			params: 3,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
+	],
+});
+`
+
+const TraceJs = `
+web3._extend({
+	property: 'trace',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'block',
+			call: 'trace_block',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'transaction',
+			call: 'trace_transaction',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'filter',
+			call: 'trace_filter',
+			params: 1
+		}),
+	]
+});
+`
+
+const Rip7560Js = `
+web3._extend({
+	property: 'rip7560',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'getReputation',
+			call: 'rip7560_getReputation'
+		}),
+		new web3._extend.Method({
+			name: 'setReputation',
+			call: 'rip7560_setReputation',
+			params: 1
+		}),
+	]
+});
+`
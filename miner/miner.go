@@ -48,6 +48,8 @@ type Config struct {
 	GasCeil             uint64         // Target gas ceiling for mined blocks.
 	GasPrice            *big.Int       // Minimum gas price for mining a transaction
 	Recommit            time.Duration  // The time interval for miner to re-create mining work.
+
+	Rip7560GasShare uint64 `toml:",omitempty"` // Percentage of the block gas limit reserved for RIP-7560 bundles
 }
 
 // DefaultConfig contains default settings for miner.
@@ -117,6 +119,18 @@ func (miner *Miner) SetGasCeil(ceil uint64) {
 	miner.confMu.Unlock()
 }
 
+// SetRip7560GasShare sets the percentage of each block's gas limit that is
+// reserved for RIP-7560 bundles and cannot be consumed by other transactions.
+func (miner *Miner) SetRip7560GasShare(share uint64) error {
+	if share > 100 {
+		return fmt.Errorf("invalid RIP-7560 gas share %d%%, must be at most 100%%", share)
+	}
+	miner.confMu.Lock()
+	miner.config.Rip7560GasShare = share
+	miner.confMu.Unlock()
+	return nil
+}
+
 // SetGasTip sets the minimum gas tip for inclusion.
 func (miner *Miner) SetGasTip(tip *big.Int) error {
 	miner.confMu.Lock()
@@ -130,6 +144,30 @@ func (miner *Miner) BuildPayload(args *BuildPayloadArgs) (*Payload, error) {
 	return miner.buildPayload(args)
 }
 
+// BuildBlock builds a block on top of the given parent containing exactly the
+// provided transactions, in order, ignoring the content of the transaction pool.
+// It is meant for tests and development tooling that need full control over
+// competing branches.
+func (miner *Miner) BuildBlock(args *BuildPayloadArgs, txs types.Transactions) (*types.Block, error) {
+	if txs == nil {
+		txs = types.Transactions{}
+	}
+	result := miner.generateWork(&generateParams{
+		timestamp:   args.Timestamp,
+		forceTime:   true,
+		parentHash:  args.Parent,
+		coinbase:    args.FeeRecipient,
+		random:      args.Random,
+		withdrawals: args.Withdrawals,
+		beaconRoot:  args.BeaconRoot,
+		txs:         txs,
+	})
+	if result.err != nil {
+		return nil, result.err
+	}
+	return result.block, nil
+}
+
 // getPending retrieves the pending block based on the current head block.
 // The result might be nil if pending generation is failed.
 func (miner *Miner) getPending() *newPayloadResult {
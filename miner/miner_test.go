@@ -20,6 +20,7 @@ package miner
 import (
 	"math/big"
 	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -27,6 +28,7 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -36,6 +38,7 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/holiman/uint256"
 )
 
 type mockBackend struct {
@@ -93,6 +96,198 @@ func (bc *testBlockChain) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent)
 	return bc.chainHeadFeed.Subscribe(ch)
 }
 
+func (bc *testBlockChain) GetReceiptsByHash(hash common.Hash) types.Receipts {
+	return nil
+}
+
+func TestRip7560ReservedGas(t *testing.T) {
+	tests := []struct {
+		gasLimit, share, gasUsed, pending, want uint64
+	}{
+		{gasLimit: 30_000_000, share: 0, gasUsed: 0, pending: 5_000_000, want: 0},
+		{gasLimit: 30_000_000, share: 10, gasUsed: 0, pending: 5_000_000, want: 3_000_000},
+		{gasLimit: 30_000_000, share: 10, gasUsed: 1_000_000, pending: 5_000_000, want: 2_000_000},
+		{gasLimit: 30_000_000, share: 10, gasUsed: 3_000_000, pending: 5_000_000, want: 0},
+		{gasLimit: 30_000_000, share: 10, gasUsed: 5_000_000, pending: 5_000_000, want: 0},
+		{gasLimit: 30_000_000, share: 100, gasUsed: 0, pending: 50_000_000, want: 30_000_000},
+		{gasLimit: 30_000_000, share: 10, gasUsed: 0, pending: 1_000_000, want: 1_000_000},
+		{gasLimit: 30_000_000, share: 10, gasUsed: 0, pending: 0, want: 0},
+	}
+	for i, tt := range tests {
+		if have := rip7560ReservedGas(tt.gasLimit, tt.share, tt.gasUsed, tt.pending); have != tt.want {
+			t.Errorf("test %d: reserved gas mismatch: have %d, want %d", i, have, tt.want)
+		}
+	}
+}
+
+// Tests that RIP-7560 transactions are only committed if the gas left in the
+// block covers their total gas limit, and that committing stops when the block
+// building is interrupted.
+func TestCommitRip7560Bundle(t *testing.T) {
+	sel := core.Rip7560Abi.Methods["acceptAccount"].ID
+	account := []byte{byte(vm.PUSH4), sel[0], sel[1], sel[2], sel[3], byte(vm.PUSH1), 0xe0, byte(vm.SHL), byte(vm.PUSH1), 0, byte(vm.MSTORE),
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0x44, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH20)}
+	account = append(account, core.AA_ENTRY_POINT.Bytes()...)
+	account = append(account, byte(vm.GAS), byte(vm.CALL), byte(vm.STOP))
+
+	var (
+		miner   = createMiner(t)
+		senders = []common.Address{{0xa1}, {0xa2}}
+		txs     types.Transactions
+	)
+	for _, sender := range senders {
+		txs = append(txs, types.NewTx(&types.Rip7560AccountAbstractionTx{
+			ChainID:            miner.chainConfig.ChainID,
+			NonceKey:           new(big.Int),
+			GasTipCap:          big.NewInt(1),
+			GasFeeCap:          big.NewInt(1),
+			Gas:                50_000,
+			ValidationGasLimit: 100_000,
+			PostOpGas:          20_000,
+			Sender:             &sender,
+		}))
+	}
+	commit := func(gas uint64, signal int32) (*environment, error) {
+		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		for _, sender := range senders {
+			statedb.SetCode(sender, account)
+			statedb.SetBalance(sender, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+		}
+		env := &environment{
+			state:   statedb,
+			gasPool: new(core.GasPool).AddGas(gas),
+			header:  &types.Header{Number: big.NewInt(1), Difficulty: new(big.Int), GasLimit: 30_000_000, BaseFee: new(big.Int)},
+		}
+		interrupt := new(atomic.Int32)
+		interrupt.Store(signal)
+		return env, miner.commitRip7560TransactionsBundle(env, &types.ExternallyReceivedBundle{Transactions: txs}, interrupt)
+	}
+	// The second transaction is included only if the gas left after the first
+	// one covers its worst case, even if it uses less, otherwise buying its gas
+	// fails its validation
+	worstCase, _ := txs[0].Rip7560TransactionData().TotalGasLimit()
+	env, err := commit(worstCase, commitInterruptNone)
+	if err != nil || len(env.receipts) != 1 {
+		t.Fatalf("failed to commit first transaction: %v", err)
+	}
+	used := env.receipts[0].GasUsed
+	if used >= worstCase {
+		t.Fatalf("transaction used its whole gas limit %d", worstCase)
+	}
+	tests := []struct {
+		gas       uint64
+		interrupt int32
+		included  int
+		err       error
+	}{
+		{used + worstCase, commitInterruptNone, 2, nil},
+		{used + worstCase - 1, commitInterruptNone, 1, nil},
+		{worstCase - 1, commitInterruptNone, 0, nil},
+		{used + worstCase, commitInterruptTimeout, 0, errBlockInterruptedByTimeout},
+	}
+	for i, tt := range tests {
+		env, err := commit(tt.gas, tt.interrupt)
+		if err != tt.err {
+			t.Fatalf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+		if len(env.txs) != tt.included {
+			t.Fatalf("test %d: included transactions mismatch: have %d, want %d", i, len(env.txs), tt.included)
+		}
+		if env.header.GasUsed+env.gasPool.Gas() != tt.gas {
+			t.Fatalf("test %d: gas pool mismatch: have %d left after using %d, want %d in total", i, env.gasPool.Gas(), env.header.GasUsed, tt.gas)
+		}
+		if tt.err != nil {
+			continue
+		}
+		for _, tx := range txs[tt.included:] {
+			info := miner.chain.GetRip7560TransactionDebugInfo(tx.Hash())
+			if info == nil || info.RevertEntityName != "block gas limit" {
+				t.Fatalf("test %d: transaction %x not failed on the block gas limit: %+v", i, tx.Hash(), info)
+			}
+		}
+	}
+	// Chains requiring ordered bundles get the bundle committed sorted
+	miner.chainConfig.Rip7560 = &params.Rip7560Config{OrderedBundles: true}
+	txs = types.Transactions{txs[1], txs[0]}
+	env, err = commit(2*worstCase, commitInterruptNone)
+	if err != nil || len(env.txs) != 2 {
+		t.Fatalf("failed to commit unordered bundle: %v", err)
+	}
+	if err := core.VerifyRip7560BundleOrder(env.txs); err != nil {
+		t.Fatalf("bundle committed out of order: %v", err)
+	}
+}
+
+// Tests that a RIP-7560 transaction whose validation accesses storage written
+// by a transaction included before it in the bundle is validated again, and
+// dropped if it breaks the validation rules on the updated state.
+func TestCommitRip7560BundleInvalidation(t *testing.T) {
+	sel := core.Rip7560Abi.Methods["acceptAccount"].ID
+	accept := []byte{byte(vm.PUSH4), sel[0], sel[1], sel[2], sel[3], byte(vm.PUSH1), 0xe0, byte(vm.SHL), byte(vm.PUSH1), 0, byte(vm.MSTORE),
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0x44, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH20)}
+	accept = append(accept, core.AA_ENTRY_POINT.Bytes()...)
+	accept = append(accept, byte(vm.GAS), byte(vm.CALL), byte(vm.STOP))
+
+	var (
+		miner  = createMiner(t)
+		writer = common.Address{0xa1}
+		victim = common.Address{0xa2}
+	)
+	// The writer calls the victim with two bytes in its execution frame, making
+	// the victim set its first storage slot
+	writerCode := []byte{byte(vm.CALLDATASIZE), byte(vm.PUSH1), 1, byte(vm.EQ), byte(vm.ISZERO), byte(vm.PUSH1), 43, byte(vm.JUMPI),
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 2, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH20)}
+	writerCode = append(writerCode, victim.Bytes()...)
+	writerCode = append(writerCode, byte(vm.GAS), byte(vm.CALL), byte(vm.POP), byte(vm.STOP), byte(vm.JUMPDEST))
+	writerCode = append(writerCode, accept...)
+
+	// The validation of the victim uses a banned opcode once the slot is set
+	victimCode := []byte{byte(vm.CALLDATASIZE), byte(vm.PUSH1), 2, byte(vm.EQ), byte(vm.ISZERO), byte(vm.PUSH1), 14, byte(vm.JUMPI),
+		byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP), byte(vm.JUMPDEST),
+		byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.ISZERO), byte(vm.PUSH1), 24, byte(vm.JUMPI), byte(vm.ORIGIN), byte(vm.POP), byte(vm.JUMPDEST)}
+	victimCode = append(victimCode, accept...)
+
+	tx := func(sender common.Address) *types.Transaction {
+		return types.NewTx(&types.Rip7560AccountAbstractionTx{
+			ChainID:            miner.chainConfig.ChainID,
+			NonceKey:           new(big.Int),
+			GasTipCap:          big.NewInt(1),
+			GasFeeCap:          big.NewInt(1),
+			Gas:                100_000,
+			ValidationGasLimit: 100_000,
+			ExecutionData:      []byte{0x01},
+			Sender:             &sender,
+		})
+	}
+	commit := func(txs types.Transactions) *environment {
+		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.SetCode(writer, writerCode)
+		statedb.SetCode(victim, victimCode)
+		for _, sender := range []common.Address{writer, victim} {
+			statedb.SetBalance(sender, uint256.NewInt(params.Ether), tracing.BalanceChangeUnspecified)
+		}
+		env := &environment{
+			state:   statedb,
+			gasPool: new(core.GasPool).AddGas(30_000_000),
+			header:  &types.Header{Number: big.NewInt(1), Difficulty: new(big.Int), GasLimit: 30_000_000, BaseFee: new(big.Int)},
+		}
+		if err := miner.commitRip7560TransactionsBundle(env, &types.ExternallyReceivedBundle{Transactions: txs}, nil); err != nil {
+			t.Fatalf("failed to commit bundle: %v", err)
+		}
+		return env
+	}
+	if env := commit(types.Transactions{tx(victim), tx(writer)}); len(env.txs) != 2 {
+		t.Fatalf("independent transactions not included: have %d, want 2", len(env.txs))
+	}
+	env := commit(types.Transactions{tx(writer), tx(victim)})
+	if len(env.txs) != 1 || env.txs[0].Hash() != tx(writer).Hash() {
+		t.Fatalf("invalidated transaction included: have %d transactions", len(env.txs))
+	}
+	if value := env.state.GetState(victim, common.Hash{}); value != common.BigToHash(common.Big1) {
+		t.Fatalf("writer execution not applied: slot %x", value)
+	}
+}
+
 func TestBuildPendingBlocks(t *testing.T) {
 	miner := createMiner(t)
 	var wg sync.WaitGroup
@@ -113,6 +308,7 @@ func minerTestGenesisBlock(period uint64, gasLimit uint64, faucet common.Address
 		Period: period,
 		Epoch:  config.Clique.Epoch,
 	}
+	config.RIP7560Block = big.NewInt(0)
 
 	// Assemble and return the genesis with the precompiles and faucet pre-funded
 	return &core.Genesis{
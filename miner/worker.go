@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"slices"
 	"sync/atomic"
 	"time"
 
@@ -28,6 +29,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
@@ -77,14 +79,15 @@ type newPayloadResult struct {
 
 // generateParams wraps various settings for generating sealing task.
 type generateParams struct {
-	timestamp   uint64            // The timestamp for sealing task
-	forceTime   bool              // Flag whether the given timestamp is immutable or not
-	parentHash  common.Hash       // Parent block hash, empty means the latest chain head
-	coinbase    common.Address    // The fee recipient address for including transaction
-	random      common.Hash       // The randomness generated by beacon chain, empty before the merge
-	withdrawals types.Withdrawals // List of withdrawals to include in block (shanghai field)
-	beaconRoot  *common.Hash      // The beacon root (cancun field).
-	noTxs       bool              // Flag whether an empty block without any transaction is expected
+	timestamp   uint64             // The timestamp for sealing task
+	forceTime   bool               // Flag whether the given timestamp is immutable or not
+	parentHash  common.Hash        // Parent block hash, empty means the latest chain head
+	coinbase    common.Address     // The fee recipient address for including transaction
+	random      common.Hash        // The randomness generated by beacon chain, empty before the merge
+	withdrawals types.Withdrawals  // List of withdrawals to include in block (shanghai field)
+	beaconRoot  *common.Hash       // The beacon root (cancun field).
+	noTxs       bool               // Flag whether an empty block without any transaction is expected
+	txs         types.Transactions // Exact transactions to include instead of the pool content (tests only)
 }
 
 // generateWork generates a sealing block based on the given parameters.
@@ -93,7 +96,11 @@ func (miner *Miner) generateWork(params *generateParams) *newPayloadResult {
 	if err != nil {
 		return &newPayloadResult{err: err}
 	}
-	if !params.noTxs {
+	if params.txs != nil {
+		if err := miner.commitExactTransactions(work, params.txs); err != nil {
+			return &newPayloadResult{err: err}
+		}
+	} else if !params.noTxs {
 		interrupt := new(atomic.Int32)
 		timer := time.AfterFunc(miner.config.Recommit, func() {
 			interrupt.Store(commitInterruptTimeout)
@@ -198,7 +205,10 @@ func (miner *Miner) prepareWork(genParams *generateParams) (*environment, error)
 	if header.ParentBeaconRoot != nil {
 		context := core.NewEVMBlockContext(header, miner.chain, nil)
 		vmenv := vm.NewEVM(context, vm.TxContext{}, env.state, miner.chainConfig, vm.Config{})
-		core.ProcessBeaconBlockRoot(*header.ParentBeaconRoot, vmenv, env.state)
+		if err := core.ProcessBeaconBlockRoot(*header.ParentBeaconRoot, vmenv, env.state); err != nil {
+			log.Error("Failed to apply beacon root", "err", err)
+			return nil, err
+		}
 	}
 	return env, nil
 }
@@ -265,7 +275,7 @@ func (miner *Miner) applyTransaction(env *environment, tx *types.Transaction) (*
 		snap = env.state.Snapshot()
 		gp   = env.gasPool.Gas()
 	)
-	receipt, err := core.ApplyTransaction(miner.chainConfig, miner.chain, &env.coinbase, env.gasPool, env.state, env.header, tx, &env.header.GasUsed, vm.Config{})
+	receipt, _, err := core.ApplyTransaction(miner.chainConfig, miner.chain, &env.coinbase, env.gasPool, env.state, env.header, tx, &env.header.GasUsed, vm.Config{})
 	if err != nil {
 		env.state.RevertToSnapshot(snap)
 		env.gasPool.SetGas(gp)
@@ -373,7 +383,7 @@ func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *tran
 	return nil
 }
 
-func (miner *Miner) commitRip7560TransactionsBundle(env *environment, txs *types.ExternallyReceivedBundle, _ *atomic.Int32) error {
+func (miner *Miner) commitRip7560TransactionsBundle(env *environment, txs *types.ExternallyReceivedBundle, interrupt *atomic.Int32) error {
 
 	// todo: copied over to fix crash, probably should do it once
 	gasLimit := env.header.GasLimit
@@ -381,14 +391,186 @@ func (miner *Miner) commitRip7560TransactionsBundle(env *environment, txs *types
 		env.gasPool = new(core.GasPool).AddGas(gasLimit)
 	}
 
-	validatedTxs, receipts, validationFailureInfos, _, err := core.HandleRip7560Transactions(txs.Transactions, 0, env.state, &env.coinbase, env.header, env.gasPool, miner.chainConfig, miner.chain, vm.Config{}, true, &env.header.GasUsed)
-	miner.chain.SetRip7560TransactionDebugInfo(validationFailureInfos)
-	if err != nil {
-		return err
+	// Respect the optional consensus caps on RIP-7560 transactions, taking into
+	// account the ones already included in the block.
+	var (
+		bundleTxs = txs.Transactions
+		gasPool   = env.gasPool
+		aaTxs     uint64
+		aaGas     uint64
+	)
+	// Chains requiring ordered bundles only accept the bundle sorted
+	if miner.chainConfig.Rip7560OrderedBundles() {
+		bundleTxs = slices.Clone(bundleTxs)
+		core.SortRip7560Bundle(bundleTxs)
+	}
+	for _, receipt := range env.receipts {
+		if receipt.Type == types.Rip7560Type {
+			aaTxs++
+			aaGas += receipt.GasUsed
+		}
+	}
+	if limit := miner.chainConfig.Rip7560MaxTxsPerBlock(); limit > 0 {
+		if aaTxs >= limit {
+			return nil
+		}
+		if left := limit - aaTxs; uint64(len(bundleTxs)) > left {
+			bundleTxs = bundleTxs[:left]
+		}
+	}
+	if limit := miner.chainConfig.Rip7560MaxGasPerBlock(); limit > 0 {
+		if aaGas >= limit {
+			return nil
+		}
+		gasPool = new(core.GasPool).AddGas(min(env.gasPool.Gas(), limit-aaGas))
+	}
+	bundleTxs, reports := miner.dropRip7560Violations(env, bundleTxs)
+
+	// Commit the transactions one by one, so that building can stop at the
+	// deadline between any two of them. Buying the gas of a transaction takes
+	// its worst case from the pool, i.e. all its frames including the postOp one
+	// using their whole gas limits, so the frames of an included transaction can
+	// never push the block over its gas limit.
+	var (
+		available  = gasPool.Gas()
+		debugInfos []*types.Rip7560TransactionDebugInfo
+		skipped    = make(map[common.Address]struct{})
+		written    = make(rip7560StorageSet)
+		stopErr    error
+	)
+	for i, tx := range bundleTxs {
+		if interrupt != nil {
+			if signal := interrupt.Load(); signal != commitInterruptNone {
+				stopErr = signalToErr(signal)
+				break
+			}
+		}
+		aatx := tx.Rip7560TransactionData()
+		if _, ok := skipped[*aatx.Sender]; ok {
+			continue
+		}
+		// The validation was simulated before the bundle, so it has to be checked
+		// again if the transactions included since wrote the storage it accesses
+		if written.overlaps(reports[i]) {
+			report := core.SimulateRip7560Validation(miner.chainConfig, miner.chain, env.header, env.state.Copy(), tx)
+			if err := report.Err(); err != nil {
+				log.Debug("Dropping RIP-7560 transaction invalidated by the bundle", "hash", tx.Hash(), "err", err)
+				skipped[*aatx.Sender] = struct{}{}
+				continue
+			}
+		}
+		env.state.SetLogger(written.hooks())
+		validatedTxs, receipts, validationFailureInfos, _, err := core.HandleRip7560Transactions(types.Transactions{tx}, env.tcount, env.state, &env.coinbase, env.header, gasPool, miner.chainConfig, miner.chain, vm.Config{}, true, &env.header.GasUsed)
+		env.state.SetLogger(nil)
+		debugInfos = append(debugInfos, validationFailureInfos...)
+		if err != nil {
+			miner.chain.SetRip7560TransactionDebugInfo(debugInfos)
+			return err
+		}
+		if len(validatedTxs) == 0 {
+			// The validation failed on top of the transactions included so far,
+			// later transactions of the sender depend on this one
+			for _, info := range validationFailureInfos {
+				miner.txpool.ReportRip7560Failure(tx, info.RevertEntityName)
+			}
+			skipped[*aatx.Sender] = struct{}{}
+			continue
+		}
+		env.txs = append(env.txs, validatedTxs...)
+		env.receipts = append(env.receipts, receipts...)
+		env.tcount += len(validatedTxs)
+	}
+	miner.chain.SetRip7560TransactionDebugInfo(debugInfos)
+	if gasPool != env.gasPool {
+		if err := env.gasPool.SubGas(available - gasPool.Gas()); err != nil {
+			return err
+		}
+	}
+	return stopErr
+}
+
+// dropRip7560Violations filters out the transactions whose validation breaks
+// the validation rules, judged by the same report the transaction pool uses.
+// Transactions are simulated against the state before the bundle, so failing
+// validations are left to the actual validation, which is authoritative. The
+// reports of the kept transactions are returned along with them.
+func (miner *Miner) dropRip7560Violations(env *environment, txs types.Transactions) (types.Transactions, []*types.ValidationReport) {
+	var (
+		kept    = make(types.Transactions, 0, len(txs))
+		reports = make([]*types.ValidationReport, 0, len(txs))
+	)
+	for _, tx := range txs {
+		report := core.SimulateRip7560Validation(miner.chainConfig, miner.chain, env.header, env.state.Copy(), tx)
+		if err := report.Err(); errors.Is(err, core.ErrValidationRulesViolation) {
+			log.Debug("Dropping RIP-7560 transaction breaking the validation rules", "hash", tx.Hash(), "err", err)
+			continue
+		}
+		kept = append(kept, tx)
+		reports = append(reports, report)
+	}
+	return kept, reports
+}
+
+// rip7560StorageSet is the set of storage slots written by the RIP-7560
+// transactions included in a block.
+type rip7560StorageSet map[common.Address]map[common.Hash]struct{}
+
+// hooks returns the state hooks adding the written slots to the set. Writes
+// reverted later on are kept, erring on the side of checking again.
+func (set rip7560StorageSet) hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnStorageChange: func(addr common.Address, slot common.Hash, prev, new common.Hash) {
+			if set[addr] == nil {
+				set[addr] = make(map[common.Hash]struct{})
+			}
+			set[addr][slot] = struct{}{}
+		},
+	}
+}
+
+// overlaps reports whether the validation of the report accessed any of the
+// slots of the set.
+func (set rip7560StorageSet) overlaps(report *types.ValidationReport) bool {
+	for _, accesses := range []map[common.Address][]common.Hash{report.Reads, report.Writes} {
+		for addr, slots := range accesses {
+			written := set[addr]
+			if written == nil {
+				continue
+			}
+			for _, slot := range slots {
+				if _, ok := written[slot]; ok {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// commitExactTransactions applies the given transactions in order, failing if
+// any of them cannot be included. RIP-7560 transactions are committed as single
+// transaction bundles.
+func (miner *Miner) commitExactTransactions(env *environment, txs types.Transactions) error {
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	}
+	for i, tx := range txs {
+		if tx.Type() == types.Rip7560Type {
+			included := env.tcount
+			bundle := &types.ExternallyReceivedBundle{Transactions: types.Transactions{tx}}
+			if err := miner.commitRip7560TransactionsBundle(env, bundle, nil); err != nil {
+				return fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
+			if env.tcount == included {
+				return fmt.Errorf("could not apply tx %d [%v]: validation failed", i, tx.Hash().Hex())
+			}
+			continue
+		}
+		env.state.SetTxContext(tx.Hash(), env.tcount)
+		if err := miner.commitTransaction(env, tx); err != nil {
+			return fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+		}
 	}
-	env.txs = append(env.txs, validatedTxs...)
-	env.receipts = append(env.receipts, receipts...)
-	env.tcount += len(validatedTxs)
 	return nil
 }
 
@@ -398,6 +580,7 @@ func (miner *Miner) commitRip7560TransactionsBundle(env *environment, txs *types
 func (miner *Miner) fillTransactions(interrupt *atomic.Int32, env *environment) error {
 	miner.confMu.RLock()
 	tip := miner.config.GasPrice
+	aaShare := miner.config.Rip7560GasShare
 	miner.confMu.RUnlock()
 
 	// Retrieve the pending transactions pre-filtered by the 1559/4844 dynamic fees
@@ -431,32 +614,87 @@ func (miner *Miner) fillTransactions(interrupt *atomic.Int32, env *environment)
 		}
 	}
 
-	pendingBundle, err := miner.txpool.PendingRip7560Bundle()
-	if pendingBundle != nil {
-		if err = miner.commitRip7560TransactionsBundle(env, pendingBundle, interrupt); err != nil {
-			log.Error(err.Error())
+	// Fill the block with all available pending transactions.
+	commitPlainTxs := func() error {
+		if len(localPlainTxs) > 0 || len(localBlobTxs) > 0 {
+			plainTxs := newTransactionsByPriceAndNonce(env.signer, localPlainTxs, env.header.BaseFee)
+			blobTxs := newTransactionsByPriceAndNonce(env.signer, localBlobTxs, env.header.BaseFee)
+
+			if err := miner.commitTransactions(env, plainTxs, blobTxs, interrupt); err != nil {
+				return err
+			}
+		}
+		if len(remotePlainTxs) > 0 || len(remoteBlobTxs) > 0 {
+			plainTxs := newTransactionsByPriceAndNonce(env.signer, remotePlainTxs, env.header.BaseFee)
+			blobTxs := newTransactionsByPriceAndNonce(env.signer, remoteBlobTxs, env.header.BaseFee)
+
+			if err := miner.commitTransactions(env, plainTxs, blobTxs, interrupt); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	pendingBundle, _ := miner.txpool.PendingRip7560Bundle()
+	commitBundle := func() error {
+		if pendingBundle == nil {
+			return nil
+		}
+		if err := miner.commitRip7560TransactionsBundle(env, pendingBundle, interrupt); err != nil {
+			if !errors.Is(err, errBlockInterruptedByTimeout) {
+				log.Error("Failed to commit RIP-7560 bundle", "err", err)
+			}
 			return err
 		}
+		return nil
 	}
-
-	// Fill the block with all available pending transactions.
-	if len(localPlainTxs) > 0 || len(localBlobTxs) > 0 {
-		plainTxs := newTransactionsByPriceAndNonce(env.signer, localPlainTxs, env.header.BaseFee)
-		blobTxs := newTransactionsByPriceAndNonce(env.signer, localBlobTxs, env.header.BaseFee)
-
-		if err := miner.commitTransactions(env, plainTxs, blobTxs, interrupt); err != nil {
+	// Without a reserved share the bundle simply goes first
+	if aaShare == 0 {
+		if err := commitBundle(); err != nil {
 			return err
 		}
+		return commitPlainTxs()
+	}
+	// Withhold the total gas limit of the pending RIP-7560 transactions, up to
+	// the reserved share, while filling the block with the other transactions,
+	// and release it for the bundle afterwards along with whatever they left.
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
 	}
-	if len(remotePlainTxs) > 0 || len(remoteBlobTxs) > 0 {
-		plainTxs := newTransactionsByPriceAndNonce(env.signer, remotePlainTxs, env.header.BaseFee)
-		blobTxs := newTransactionsByPriceAndNonce(env.signer, remoteBlobTxs, env.header.BaseFee)
+	reserved := rip7560ReservedGas(env.header.GasLimit, aaShare, env.header.GasUsed, rip7560BundleGas(pendingBundle))
+	reserved = min(reserved, env.gasPool.Gas())
+	env.gasPool.SubGas(reserved)
+	err := commitPlainTxs()
+	env.gasPool.AddGas(reserved)
+	if err != nil {
+		return err
+	}
+	return commitBundle()
+}
 
-		if err := miner.commitTransactions(env, plainTxs, blobTxs, interrupt); err != nil {
-			return err
+// rip7560ReservedGas returns the amount of gas that has to be kept free for
+// RIP-7560 bundles, given the configured percentage share of the gas limit, the
+// gas already used by the block and the gas the pending bundle may use at most.
+func rip7560ReservedGas(gasLimit uint64, share uint64, gasUsed uint64, pending uint64) uint64 {
+	reserved := gasLimit / 100 * share
+	if reserved <= gasUsed {
+		return 0
+	}
+	return min(reserved-gasUsed, pending)
+}
+
+// rip7560BundleGas returns the total gas limit of the transactions of the
+// bundle, i.e. the gas they use at most.
+func rip7560BundleGas(bundle *types.ExternallyReceivedBundle) uint64 {
+	if bundle == nil {
+		return 0
+	}
+	var total uint64
+	for _, tx := range bundle.Transactions {
+		if gas, err := tx.Rip7560TransactionData().TotalGasLimit(); err == nil {
+			total += gas
 		}
 	}
-	return nil
+	return total
 }
 
 // totalFees computes total consumed miner fees in Wei. Block transactions and receipts have to have the same order.
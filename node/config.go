@@ -102,6 +102,14 @@ type Config struct {
 	// relative), then that specific path is enforced. An empty path disables IPC.
 	IPCPath string
 
+	// IPCModules is a list of API modules to expose via the IPC endpoint. If the
+	// list is empty, all the registered modules are exposed.
+	IPCModules []string `toml:",omitempty"`
+
+	// IPCSockets are additional IPC endpoints, each exposing its own set of API
+	// modules, e.g. the aa and debug modules to a local bundler process.
+	IPCSockets []IPCSocket `toml:",omitempty"`
+
 	// HTTPHost is the host interface on which to start the HTTP RPC server. If this
 	// field is empty, no HTTP API endpoint will be started.
 	HTTPHost string
@@ -213,29 +221,51 @@ type Config struct {
 	DBEngine string `toml:",omitempty"`
 }
 
+// IPCSocket is the configuration of an additional IPC endpoint.
+type IPCSocket struct {
+	// Path is the location of the endpoint, resolved the same way as IPCPath.
+	Path string
+
+	// Modules is the list of API modules to expose via the endpoint.
+	Modules []string
+}
+
 // IPCEndpoint resolves an IPC endpoint based on a configured value, taking into
 // account the set data folders as well as the designated platform we're currently
 // running on.
 func (c *Config) IPCEndpoint() string {
+	return c.resolveIPCPath(c.IPCPath)
+}
+
+// IPCSocketEndpoints resolves the endpoints of the additional IPC sockets.
+func (c *Config) IPCSocketEndpoints() []string {
+	endpoints := make([]string, len(c.IPCSockets))
+	for i, socket := range c.IPCSockets {
+		endpoints[i] = c.resolveIPCPath(socket.Path)
+	}
+	return endpoints
+}
+
+func (c *Config) resolveIPCPath(path string) string {
 	// Short circuit if IPC has not been enabled
-	if c.IPCPath == "" {
+	if path == "" {
 		return ""
 	}
 	// On windows we can only use plain top-level pipes
 	if runtime.GOOS == "windows" {
-		if strings.HasPrefix(c.IPCPath, `\\.\pipe\`) {
-			return c.IPCPath
+		if strings.HasPrefix(path, `\\.\pipe\`) {
+			return path
 		}
-		return `\\.\pipe\` + c.IPCPath
+		return `\\.\pipe\` + path
 	}
 	// Resolve names into the data directory full paths otherwise
-	if filepath.Base(c.IPCPath) == c.IPCPath {
+	if filepath.Base(path) == path {
 		if c.DataDir == "" {
-			return filepath.Join(os.TempDir(), c.IPCPath)
+			return filepath.Join(os.TempDir(), path)
 		}
-		return filepath.Join(c.DataDir, c.IPCPath)
+		return filepath.Join(c.DataDir, path)
 	}
-	return c.IPCPath
+	return path
 }
 
 // NodeDB returns the path to the discovery node database.
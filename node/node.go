@@ -57,14 +57,15 @@ type Node struct {
 	state         int           // Tracks state of node lifecycle
 
 	lock          sync.Mutex
-	lifecycles    []Lifecycle // All registered backends, services, and auxiliary services that have a lifecycle
-	rpcAPIs       []rpc.API   // List of APIs currently provided by the node
-	http          *httpServer //
-	ws            *httpServer //
-	httpAuth      *httpServer //
-	wsAuth        *httpServer //
-	ipc           *ipcServer  // Stores information about the ipc http server
-	inprocHandler *rpc.Server // In-process RPC request handler to process the API requests
+	lifecycles    []Lifecycle  // All registered backends, services, and auxiliary services that have a lifecycle
+	rpcAPIs       []rpc.API    // List of APIs currently provided by the node
+	http          *httpServer  //
+	ws            *httpServer  //
+	httpAuth      *httpServer  //
+	wsAuth        *httpServer  //
+	ipc           *ipcServer   // Stores information about the ipc http server
+	ipcSockets    []*ipcServer // Additional ipc servers exposing their own modules
+	inprocHandler *rpc.Server  // In-process RPC request handler to process the API requests
 
 	databases map[*closeTrackingDB]struct{} // All open databases
 }
@@ -154,7 +155,15 @@ func New(conf *Config) (*Node, error) {
 	node.httpAuth = newHTTPServer(node.log, conf.HTTPTimeouts)
 	node.ws = newHTTPServer(node.log, rpc.DefaultHTTPTimeouts)
 	node.wsAuth = newHTTPServer(node.log, rpc.DefaultHTTPTimeouts)
-	node.ipc = newIPCServer(node.log, conf.IPCEndpoint())
+	node.ipc = newIPCServer(node.log, conf.IPCEndpoint(), conf.IPCModules)
+	endpoints := map[string]bool{node.ipc.endpoint: true}
+	for i, endpoint := range conf.IPCSocketEndpoints() {
+		if endpoint == "" || endpoints[endpoint] {
+			return nil, fmt.Errorf("invalid or duplicate IPC socket path %q", conf.IPCSockets[i].Path)
+		}
+		endpoints[endpoint] = true
+		node.ipcSockets = append(node.ipcSockets, newIPCServer(node.log, endpoint, conf.IPCSockets[i].Modules))
+	}
 
 	return node, nil
 }
@@ -397,6 +406,11 @@ func (n *Node) startRPC() error {
 			return err
 		}
 	}
+	for _, ipc := range n.ipcSockets {
+		if err := ipc.start(apis); err != nil {
+			return err
+		}
+	}
 	var (
 		servers           []*httpServer
 		openAPIs, allAPIs = n.getAPIs()
@@ -532,6 +546,9 @@ func (n *Node) stopRPC() {
 	n.httpAuth.stop()
 	n.wsAuth.stop()
 	n.ipc.stop()
+	for _, ipc := range n.ipcSockets {
+		ipc.stop()
+	}
 	n.stopInProc()
 }
 
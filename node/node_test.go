@@ -622,3 +622,53 @@ func containsAPI(stackAPIs []rpc.API, api rpc.API) bool {
 	}
 	return false
 }
+
+// Tests that the IPC endpoints expose their own sets of modules.
+func TestIPCSockets(t *testing.T) {
+	conf := testNodeConfig()
+	conf.DataDir = t.TempDir()
+	conf.IPCPath = "main.ipc"
+	conf.IPCModules = []string{"web3"}
+	conf.IPCSockets = []IPCSocket{{Path: "bundler.ipc", Modules: []string{"admin", "debug"}}}
+
+	node, err := New(conf)
+	if err != nil {
+		t.Fatalf("could not create node: %v", err)
+	}
+	defer node.Close()
+	if err := node.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+	modules := func(endpoint string) []string {
+		client, err := rpc.Dial(endpoint)
+		if err != nil {
+			t.Fatalf("could not dial %s: %v", endpoint, err)
+		}
+		defer client.Close()
+
+		supported, err := client.SupportedModules()
+		if err != nil {
+			t.Fatalf("could not query modules of %s: %v", endpoint, err)
+		}
+		var names []string
+		for name := range supported {
+			if name != rpc.MetadataApi {
+				names = append(names, name)
+			}
+		}
+		slices.Sort(names)
+		return names
+	}
+	if have, want := modules(node.IPCEndpoint()), []string{"web3"}; !slices.Equal(have, want) {
+		t.Errorf("main socket modules mismatch: have %v, want %v", have, want)
+	}
+	if have, want := modules(conf.IPCSocketEndpoints()[0]), []string{"admin", "debug"}; !slices.Equal(have, want) {
+		t.Errorf("additional socket modules mismatch: have %v, want %v", have, want)
+	}
+
+	// Sockets colliding with the main one are rejected
+	conf.IPCSockets = []IPCSocket{{Path: "main.ipc", Modules: []string{"admin"}}}
+	if _, err := New(conf); err == nil {
+		t.Errorf("duplicate socket path accepted")
+	}
+}
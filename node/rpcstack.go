@@ -24,6 +24,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -33,6 +34,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/klauspost/compress/zstd"
 	"github.com/rs/cors"
 )
 
@@ -401,7 +403,7 @@ func NewHTTPHandlerStack(srv http.Handler, cors []string, vhosts []string, jwtSe
 	if len(jwtSecret) != 0 {
 		handler = newJWTHandler(jwtSecret, handler)
 	}
-	return newGzipHandler(handler)
+	return newCompressionHandler(handler)
 }
 
 // NewWSHandlerStack returns a wrapped ws-related handler.
@@ -472,17 +474,43 @@ func (h *virtualHostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	http.Error(w, "invalid host specified", http.StatusForbidden)
 }
 
-var gzPool = sync.Pool{
-	New: func() interface{} {
-		w := gzip.NewWriter(io.Discard)
-		return w
-	},
+var (
+	gzPool = sync.Pool{
+		New: func() interface{} {
+			w := gzip.NewWriter(io.Discard)
+			return w
+		},
+	}
+	zstdPool = sync.Pool{
+		New: func() interface{} {
+			w, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderConcurrency(1))
+			return w
+		},
+	}
+)
+
+// compressWriter is the stream encoder of a response content encoding.
+type compressWriter interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// compressionEncodings are the supported content encodings, in order of preference.
+var compressionEncodings = []struct {
+	name string
+	pool *sync.Pool
+}{
+	{"zstd", &zstdPool},
+	{"gzip", &gzPool},
 }
 
-type gzipResponseWriter struct {
+type compressResponseWriter struct {
 	resp http.ResponseWriter
 
-	gz            *gzip.Writer
+	encoding      string     // content encoding negotiated with the client
+	pool          *sync.Pool // pool of the encoders of the content encoding
+	enc           compressWriter
 	contentLength uint64 // total length of the uncompressed response
 	written       uint64 // amount of written bytes from the uncompressed response
 	hasLength     bool   // true if uncompressed response had Content-Length
@@ -491,7 +519,7 @@ type gzipResponseWriter struct {
 
 // init runs just before response headers are written. Among other things, this function
 // also decides whether compression will be applied at all.
-func (w *gzipResponseWriter) init() {
+func (w *compressResponseWriter) init() {
 	if w.inited {
 		return
 	}
@@ -509,7 +537,7 @@ func (w *gzipResponseWriter) init() {
 	// Setting Transfer-Encoding to "identity" explicitly disables compression. net/http
 	// also recognizes this header value and uses it to disable "chunked" transfer
 	// encoding, trimming the header from the response. This means downstream handlers can
-	// set this without harm, even if they aren't wrapped by newGzipHandler.
+	// set this without harm, even if they aren't wrapped by newCompressionHandler.
 	//
 	// In go-ethereum, we use this signal to disable compression for certain error
 	// responses which are flushed out close to the write deadline of the response. For
@@ -517,84 +545,110 @@ func (w *gzipResponseWriter) init() {
 	// they require additional output that may not get written in time.
 	passthrough := hdr.Get("transfer-encoding") == "identity"
 	if !passthrough {
-		w.gz = gzPool.Get().(*gzip.Writer)
-		w.gz.Reset(w.resp)
+		w.enc = w.pool.Get().(compressWriter)
+		w.enc.Reset(w.resp)
 		hdr.Del("content-length")
-		hdr.Set("content-encoding", "gzip")
+		hdr.Set("content-encoding", w.encoding)
 	}
 }
 
-func (w *gzipResponseWriter) Header() http.Header {
+func (w *compressResponseWriter) Header() http.Header {
 	return w.resp.Header()
 }
 
-func (w *gzipResponseWriter) WriteHeader(status int) {
+func (w *compressResponseWriter) WriteHeader(status int) {
 	w.init()
 	w.resp.WriteHeader(status)
 }
 
-func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+func (w *compressResponseWriter) Write(b []byte) (int, error) {
 	w.init()
 
-	if w.gz == nil {
+	if w.enc == nil {
 		// Compression is disabled.
 		return w.resp.Write(b)
 	}
 
-	n, err := w.gz.Write(b)
+	n, err := w.enc.Write(b)
 	w.written += uint64(n)
 	if w.hasLength && w.written >= w.contentLength {
 		// The HTTP handler has finished writing the entire uncompressed response. Close
-		// the gzip stream to ensure the footer will be seen by the client in case the
-		// response is flushed after this call to write.
-		err = w.gz.Close()
+		// the compressed stream to ensure the footer will be seen by the client in case
+		// the response is flushed after this call to write.
+		err = w.enc.Close()
 	}
 	return n, err
 }
 
-func (w *gzipResponseWriter) Flush() {
-	if w.gz != nil {
-		w.gz.Flush()
+func (w *compressResponseWriter) Flush() {
+	if w.enc != nil {
+		w.enc.Flush()
 	}
 	if f, ok := w.resp.(http.Flusher); ok {
 		f.Flush()
 	}
 }
 
-func (w *gzipResponseWriter) close() {
-	if w.gz == nil {
+func (w *compressResponseWriter) close() {
+	if w.enc == nil {
 		return
 	}
-	w.gz.Close()
-	gzPool.Put(w.gz)
-	w.gz = nil
+	w.enc.Close()
+	w.pool.Put(w.enc)
+	w.enc = nil
 }
 
-func newGzipHandler(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
-			next.ServeHTTP(w, r)
-			return
+// acceptsEncoding reports whether the given Accept-Encoding header value accepts
+// the content encoding, i.e. lists it without a zero quality value.
+func acceptsEncoding(accept string, encoding string) bool {
+	for _, entry := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(entry, ";")
+		if !strings.EqualFold(strings.TrimSpace(name), encoding) {
+			continue
+		}
+		for _, param := range strings.Split(params, ";") {
+			if key, value, ok := strings.Cut(strings.TrimSpace(param), "="); ok && key == "q" {
+				if q, err := strconv.ParseFloat(value, 64); err == nil && q == 0 {
+					return false
+				}
+			}
 		}
+		return true
+	}
+	return false
+}
 
-		wrapper := &gzipResponseWriter{resp: w}
-		defer wrapper.close()
+// newCompressionHandler compresses the responses with the preferred content
+// encoding accepted by the client.
+func newCompressionHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept := r.Header.Get("Accept-Encoding")
+		for _, encoding := range compressionEncodings {
+			if !acceptsEncoding(accept, encoding.name) {
+				continue
+			}
+			wrapper := &compressResponseWriter{resp: w, encoding: encoding.name, pool: encoding.pool}
+			defer wrapper.close()
 
-		next.ServeHTTP(wrapper, r)
+			next.ServeHTTP(wrapper, r)
+			return
+		}
+		next.ServeHTTP(w, r)
 	})
 }
 
 type ipcServer struct {
 	log      log.Logger
 	endpoint string
+	modules  []string // modules exposed on the endpoint, all of them if empty
 
 	mu       sync.Mutex
 	listener net.Listener
 	srv      *rpc.Server
 }
 
-func newIPCServer(log log.Logger, endpoint string) *ipcServer {
-	return &ipcServer{log: log, endpoint: endpoint}
+func newIPCServer(log log.Logger, endpoint string, modules []string) *ipcServer {
+	return &ipcServer{log: log, endpoint: endpoint, modules: modules}
 }
 
 // start starts the httpServer's http.Server
@@ -605,6 +659,12 @@ func (is *ipcServer) start(apis []rpc.API) error {
 	if is.listener != nil {
 		return nil // already running
 	}
+	if len(is.modules) > 0 {
+		if bad, available := checkModuleAvailability(is.modules, apis); len(bad) > 0 {
+			is.log.Error("Unavailable modules in IPC API list", "url", is.endpoint, "unavailable", bad, "available", available)
+		}
+		apis = filterModules(apis, is.modules)
+	}
 	listener, srv, err := rpc.StartIPCEndpoint(is.endpoint, apis)
 	if err != nil {
 		is.log.Warn("IPC opening failed", "url", is.endpoint, "error", err)
@@ -629,6 +689,17 @@ func (is *ipcServer) stop() error {
 	return err
 }
 
+// filterModules returns the APIs of the given modules.
+func filterModules(apis []rpc.API, modules []string) []rpc.API {
+	var filtered []rpc.API
+	for _, api := range apis {
+		if slices.Contains(modules, api.Namespace) {
+			filtered = append(filtered, api)
+		}
+	}
+	return filtered
+}
+
 // RegisterApis checks the given modules' availability, generates an allowlist based on the allowed modules,
 // and then registers all of the APIs exposed by the services.
 func RegisterApis(apis []rpc.API, modules []string, srv *rpc.Server) error {
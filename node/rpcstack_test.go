@@ -18,6 +18,7 @@ package node
 
 import (
 	"bytes"
+	"compress/gzip"
 	"fmt"
 	"io"
 	"net/http"
@@ -33,6 +34,7 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/gorilla/websocket"
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -524,7 +526,7 @@ func TestGzipHandler(t *testing.T) {
 	for _, test := range tests {
 		test := test
 		t.Run(test.name, func(t *testing.T) {
-			srv := httptest.NewServer(newGzipHandler(test.handler))
+			srv := httptest.NewServer(newCompressionHandler(test.handler))
 			defer srv.Close()
 
 			resp, err := http.Get(srv.URL)
@@ -557,6 +559,59 @@ func TestGzipHandler(t *testing.T) {
 	}
 }
 
+func TestZstdHandler(t *testing.T) {
+	srv := httptest.NewServer(newCompressionHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("response"))
+	})))
+	defer srv.Close()
+
+	tests := []struct {
+		accept   string
+		encoding string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"zstd", "zstd"},
+		{"gzip, deflate, zstd", "zstd"},
+		{"gzip, zstd;q=0", "gzip"},
+		{"br, ZSTD;q=0.5", "zstd"},
+	}
+	for _, test := range tests {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		if test.accept != "" {
+			req.Header.Set("Accept-Encoding", test.accept)
+		}
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body io.Reader = resp.Body
+		switch encoding := resp.Header.Get("content-encoding"); {
+		case encoding != test.encoding:
+			t.Errorf("accept %q: content encoding mismatch: have %q, want %q", test.accept, encoding, test.encoding)
+		case encoding == "gzip":
+			body, err = gzip.NewReader(resp.Body)
+		case encoding == "zstd":
+			var dec *zstd.Decoder
+			if dec, err = zstd.NewReader(resp.Body); err == nil {
+				defer dec.Close()
+				body = dec
+			}
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("accept %q: failed to read response: %v", test.accept, err)
+		}
+		if string(content) != "response" {
+			t.Errorf("accept %q: wrong response content %q", test.accept, content)
+		}
+	}
+}
+
 func TestHTTPWriteTimeout(t *testing.T) {
 	const (
 		timeoutRes = `{"jsonrpc":"2.0","id":1,"error":{"code":-32002,"message":"request timed out"}}`
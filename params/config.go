@@ -17,8 +17,10 @@
 package params
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/big"
+	"slices"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/params/forks"
@@ -175,6 +177,7 @@ var (
 		EIP158Block:                   big.NewInt(0),
 		RIP7560Block:                  big.NewInt(0),
 		RIP7712Block:                  big.NewInt(0),
+		RIP7560ContextGasBlock:        big.NewInt(0),
 		ByzantiumBlock:                big.NewInt(0),
 		ConstantinopleBlock:           big.NewInt(0),
 		PetersburgBlock:               big.NewInt(0),
@@ -341,6 +344,27 @@ type ChainConfig struct {
 	RIP7560Block *big.Int `json:"rip7560block,omitempty"` // RIP7560 HF block
 	RIP7712Block *big.Int `json:"rip7712block,omitempty"` // RIP7712 HF block
 
+	// RIP7560TypeBlock switches the RIP-7560 transactions of the early devnets
+	// from the legacy 0x04 type byte to the assigned AA_TX_TYPE. Before it both
+	// are accepted, chains without it only ever accepted the assigned one.
+	RIP7560TypeBlock *big.Int `json:"rip7560TypeBlock,omitempty"` // RIP-7560 type switch block (nil = no legacy type)
+
+	// RIP7560ContextGasBlock starts charging paymasters for the context passed
+	// to their postOp frame, priced as calldata. Chains without it never charge.
+	RIP7560ContextGasBlock *big.Int `json:"rip7560ContextGasBlock,omitempty"` // RIP-7560 context gas block (nil = no charge)
+
+	// Post-merge AA chains may schedule the RIP forks by timestamp instead, in
+	// which case activation doesn't depend on the block number or difficulty.
+	RIP7560Time *uint64 `json:"rip7560Time,omitempty"` // RIP7560 switch time (nil = no fork, 0 = already activated)
+	RIP7712Time *uint64 `json:"rip7712Time,omitempty"` // RIP7712 switch time (nil = no fork, 0 = already activated)
+
+	// Rip7560 holds optional consensus-level limits on RIP-7560 transactions.
+	Rip7560 *Rip7560Config `json:"rip7560,omitempty"`
+
+	// BeaconRoots optionally overrides the parameters of the EIP-4788 system
+	// call, allowing devnets to deploy the beacon roots contract elsewhere.
+	BeaconRoots *BeaconRootsConfig `json:"beaconRoots,omitempty"`
+
 	ByzantiumBlock      *big.Int `json:"byzantiumBlock,omitempty"`      // Byzantium switch block (nil = no fork, 0 = already on byzantium)
 	ConstantinopleBlock *big.Int `json:"constantinopleBlock,omitempty"` // Constantinople switch block (nil = no fork, 0 = already activated)
 	PetersburgBlock     *big.Int `json:"petersburgBlock,omitempty"`     // Petersburg switch block (nil = same as Constantinople)
@@ -394,6 +418,224 @@ func (c CliqueConfig) String() string {
 	return fmt.Sprintf("clique(period: %d, epoch: %d)", c.Period, c.Epoch)
 }
 
+// Rip7560Config contains optional limits on RIP-7560 transactions, acting as a
+// safety valve while the cost of validation is being characterized. A zero value
+// disables the corresponding per-block limit, or selects the default one for the
+// per-transaction limits.
+//
+// The limits may be selected by the name of one of the Rip7560Presets, the
+// explicitly configured fields overriding the ones of the preset.
+type Rip7560Config struct {
+	Preset string `json:"preset,omitempty"` // Name of the preset the limits were expanded from
+
+	MaxTxsPerBlock          uint64 `json:"maxTxsPerBlock,omitempty"`          // Maximum number of RIP-7560 transactions in a block
+	MaxGasPerBlock          uint64 `json:"maxGasPerBlock,omitempty"`          // Maximum gas used by all RIP-7560 transactions in a block
+	MaxPaymasterContextSize uint64 `json:"maxPaymasterContextSize,omitempty"` // Maximum size of the context returned by a paymaster
+	OrderedBundles          bool   `json:"orderedBundles,omitempty"`          // Whether RIP-7560 bundles must be sorted by sender, nonce key and nonce
+	PaymasterDeposits       bool   `json:"paymasterDeposits,omitempty"`       // Whether paymasters pay for gas from their DepositManager deposit instead of their balance
+	AbiVersion              uint64 `json:"abiVersion,omitempty"`              // Version of the account and paymaster interfaces called by the frames
+
+	// GasPenaltyPercent is the share of the unused execution and postOp gas
+	// limits charged to transactions, defaulting to Rip7560GasPenaltyPercent
+	// if unset. It can be explicitly set to zero to disable the penalty.
+	GasPenaltyPercent *uint64 `json:"gasPenaltyPercent,omitempty"`
+
+	// BannedOpcodeExemptions lists the ERC-7562 banned opcodes validation frames
+	// are allowed to use on this chain. It is intended for research networks and
+	// must be empty on the public networks.
+	BannedOpcodeExemptions []string `json:"bannedOpcodeExemptions,omitempty"`
+}
+
+// Rip7560Presets are the named RIP-7560 parameter sets of the AA test networks,
+// selected in the chain config with `"rip7560": {"preset": "<name>"}`.
+var Rip7560Presets = map[string]Rip7560Config{
+	// devnet-v1 caps the share of blocks taken by RIP-7560 transactions
+	"devnet-v1": {
+		MaxTxsPerBlock:          256,
+		MaxGasPerBlock:          15_000_000,
+		MaxPaymasterContextSize: Rip7560MaxPaymasterContextSize,
+	},
+	// research lifts the block limits and allows validation frames to read the
+	// block environment, for experimenting with validation rules
+	"research": {
+		MaxPaymasterContextSize: 4 * Rip7560MaxPaymasterContextSize,
+		BannedOpcodeExemptions:  []string{"TIMESTAMP", "NUMBER", "COINBASE", "DIFFICULTY"},
+	},
+}
+
+// UnmarshalJSON implements json.Unmarshaler, expanding the preset the config
+// refers to, if any.
+func (c *Rip7560Config) UnmarshalJSON(input []byte) error {
+	type rip7560Config Rip7560Config
+	var dec rip7560Config
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	if dec.Preset != "" {
+		preset, ok := Rip7560Presets[dec.Preset]
+		if !ok {
+			return fmt.Errorf("unknown rip7560 preset %q", dec.Preset)
+		}
+		if dec.MaxTxsPerBlock == 0 {
+			dec.MaxTxsPerBlock = preset.MaxTxsPerBlock
+		}
+		if dec.MaxGasPerBlock == 0 {
+			dec.MaxGasPerBlock = preset.MaxGasPerBlock
+		}
+		if dec.MaxPaymasterContextSize == 0 {
+			dec.MaxPaymasterContextSize = preset.MaxPaymasterContextSize
+		}
+		if dec.BannedOpcodeExemptions == nil {
+			dec.BannedOpcodeExemptions = slices.Clone(preset.BannedOpcodeExemptions)
+		}
+	}
+	*c = Rip7560Config(dec)
+	return nil
+}
+
+// String implements the stringer interface, returning the limit details.
+func (c Rip7560Config) String() string {
+	limits := fmt.Sprintf("maxTxsPerBlock: %d, maxGasPerBlock: %d, maxPaymasterContextSize: %d, orderedBundles: %t, paymasterDeposits: %t, abiVersion: %d, bannedOpcodeExemptions: %v", c.MaxTxsPerBlock, c.MaxGasPerBlock, c.MaxPaymasterContextSize, c.OrderedBundles, c.PaymasterDeposits, c.AbiVersion, c.BannedOpcodeExemptions)
+	if c.GasPenaltyPercent != nil {
+		limits += fmt.Sprintf(", gasPenaltyPercent: %d", *c.GasPenaltyPercent)
+	}
+	if c.Preset != "" {
+		return fmt.Sprintf("rip7560(preset: %s, %s)", c.Preset, limits)
+	}
+	return fmt.Sprintf("rip7560(%s)", limits)
+}
+
+// BeaconRootsConfig contains overrides for the EIP-4788 beacon block root system
+// call. Zero values fall back to the mainnet parameters.
+type BeaconRootsConfig struct {
+	Address  common.Address `json:"address,omitempty"`  // Address of the beacon roots contract
+	Caller   common.Address `json:"caller,omitempty"`   // Address the system call is sent from
+	GasLimit uint64         `json:"gasLimit,omitempty"` // Gas available to the system call
+}
+
+// BeaconRootsAddress returns the address of the EIP-4788 beacon roots contract.
+func (c *ChainConfig) BeaconRootsAddress() common.Address {
+	if c.BeaconRoots != nil && c.BeaconRoots.Address != (common.Address{}) {
+		return c.BeaconRoots.Address
+	}
+	return BeaconRootsAddress
+}
+
+// BeaconRootsCaller returns the address the EIP-4788 system call is sent from.
+func (c *ChainConfig) BeaconRootsCaller() common.Address {
+	if c.BeaconRoots != nil && c.BeaconRoots.Caller != (common.Address{}) {
+		return c.BeaconRoots.Caller
+	}
+	return SystemAddress
+}
+
+// BeaconRootsGasLimit returns the gas available to the EIP-4788 system call.
+func (c *ChainConfig) BeaconRootsGasLimit() uint64 {
+	if c.BeaconRoots != nil && c.BeaconRoots.GasLimit != 0 {
+		return c.BeaconRoots.GasLimit
+	}
+	return BeaconRootsGasLimit
+}
+
+// Rip7560MaxTxsPerBlock returns the maximum number of RIP-7560 transactions
+// allowed in a block, or 0 if unlimited.
+func (c *ChainConfig) Rip7560MaxTxsPerBlock() uint64 {
+	if c.Rip7560 == nil {
+		return 0
+	}
+	return c.Rip7560.MaxTxsPerBlock
+}
+
+// Rip7560MaxGasPerBlock returns the maximum gas RIP-7560 transactions may use
+// in a block, or 0 if unlimited.
+func (c *ChainConfig) Rip7560MaxGasPerBlock() uint64 {
+	if c.Rip7560 == nil {
+		return 0
+	}
+	return c.Rip7560.MaxGasPerBlock
+}
+
+// Rip7560OrderedBundles returns whether the consecutive RIP-7560 transactions
+// of a block must be sorted by sender, nonce key and nonce.
+func (c *ChainConfig) Rip7560OrderedBundles() bool {
+	return c.Rip7560 != nil && c.Rip7560.OrderedBundles
+}
+
+// Rip7560PaymasterDeposits returns whether the paymasters of RIP-7560
+// transactions pay for gas from their deposit held by the DepositManager system
+// contract instead of their balance.
+func (c *ChainConfig) Rip7560PaymasterDeposits() bool {
+	return c.Rip7560 != nil && c.Rip7560.PaymasterDeposits
+}
+
+// Rip7560AbiVersion returns the version of the interfaces RIP-7560 accounts and
+// paymasters are called with.
+func (c *ChainConfig) Rip7560AbiVersion() uint64 {
+	if c.Rip7560 == nil {
+		return 0
+	}
+	return c.Rip7560.AbiVersion
+}
+
+// Rip7560GasPenaltyPercent returns the share of the unused execution and postOp
+// gas limits of RIP-7560 transactions charged as a penalty, in percent.
+func (c *ChainConfig) Rip7560GasPenaltyPercent() uint64 {
+	if c.Rip7560 == nil || c.Rip7560.GasPenaltyPercent == nil {
+		return Rip7560GasPenaltyPercent
+	}
+	return *c.Rip7560.GasPenaltyPercent
+}
+
+// Rip7560MaxPaymasterContextSize returns the maximum size of the context a
+// paymaster may pass from its validation frame to its postOp frame.
+func (c *ChainConfig) Rip7560MaxPaymasterContextSize() uint64 {
+	if c.Rip7560 == nil || c.Rip7560.MaxPaymasterContextSize == 0 {
+		return Rip7560MaxPaymasterContextSize
+	}
+	return c.Rip7560.MaxPaymasterContextSize
+}
+
+// Rip7560BannedOpcodes returns the set of opcodes RIP-7560 validation frames
+// must not use on this chain.
+func (c *ChainConfig) Rip7560BannedOpcodes() map[string]struct{} {
+	banned := make(map[string]struct{}, len(Rip7560BannedOpcodes))
+	for _, op := range Rip7560BannedOpcodes {
+		banned[op] = struct{}{}
+	}
+	if c.Rip7560 != nil {
+		for _, op := range c.Rip7560.BannedOpcodeExemptions {
+			delete(banned, op)
+		}
+	}
+	return banned
+}
+
+// CheckRip7560Config checks that the RIP-7560 parameters are valid, only
+// exempting known banned opcodes and charging at most the unused gas, and that
+// the ones of the public networks are not modified.
+func (c *ChainConfig) CheckRip7560Config() error {
+	if c.Rip7560 == nil {
+		return nil
+	}
+	if c.Rip7560.GasPenaltyPercent != nil && *c.Rip7560.GasPenaltyPercent > 100 {
+		return fmt.Errorf("invalid rip7560 gas penalty %d%%, above 100%%", *c.Rip7560.GasPenaltyPercent)
+	}
+	if len(c.Rip7560.BannedOpcodeExemptions) == 0 {
+		return nil
+	}
+	for _, op := range c.Rip7560.BannedOpcodeExemptions {
+		if !slices.Contains(Rip7560BannedOpcodes, op) {
+			return fmt.Errorf("invalid rip7560 banned opcode exemption %q", op)
+		}
+	}
+	for _, preset := range []*ChainConfig{MainnetChainConfig, SepoliaChainConfig, HoleskyChainConfig} {
+		if c.ChainID != nil && c.ChainID.Cmp(preset.ChainID) == 0 {
+			return fmt.Errorf("rip7560 banned opcode exemptions not allowed on chain %v", c.ChainID)
+		}
+	}
+	return nil
+}
+
 // Description returns a human-readable description of ChainConfig.
 func (c *ChainConfig) Description() string {
 	var banner string
@@ -591,14 +833,65 @@ func (c *ChainConfig) IsEIP4762(num *big.Int, time uint64) bool {
 	return c.IsVerkle(num, time)
 }
 
-// IsRIP7560 returns whether num is either equal to the RIP7560 fork block or greater.
-func (c *ChainConfig) IsRIP7560(num *big.Int) bool {
-	return isBlockForked(c.RIP7560Block, num)
+// IsRIP7560 returns whether RIP7560 has been activated at the given block,
+// either by block number or by timestamp.
+func (c *ChainConfig) IsRIP7560(num *big.Int, time uint64) bool {
+	return isBlockForked(c.RIP7560Block, num) || isTimestampForked(c.RIP7560Time, time)
 }
 
-// IsRIP7712 returns whether RIP7712 has been activated at given block.
-func (c *ChainConfig) IsRIP7712(num *big.Int) bool {
-	return isBlockForked(c.RIP7712Block, num)
+// IsRIP7712 returns whether RIP7712 has been activated at the given block,
+// either by block number or by timestamp.
+func (c *ChainConfig) IsRIP7712(num *big.Int, time uint64) bool {
+	return isBlockForked(c.RIP7712Block, num) || isTimestampForked(c.RIP7712Time, time)
+}
+
+// IsRIP7560LegacyType returns whether RIP-7560 transactions may still use the
+// legacy type byte of the early devnets at the given block.
+func (c *ChainConfig) IsRIP7560LegacyType(num *big.Int) bool {
+	return c.RIP7560TypeBlock != nil && !isBlockForked(c.RIP7560TypeBlock, num)
+}
+
+// IsRIP7560ContextGas returns whether paymasters are charged for their context
+// at the given block.
+func (c *ChainConfig) IsRIP7560ContextGas(num *big.Int) bool {
+	return isBlockForked(c.RIP7560ContextGasBlock, num)
+}
+
+// ActiveForks returns the names of the forks active at the given block, in
+// activation order. The merge is left out, as it isn't scheduled by block.
+func (c *ChainConfig) ActiveForks(num *big.Int, time uint64) []string {
+	forks := []struct {
+		name   string
+		active bool
+	}{
+		{"homestead", c.IsHomestead(num)},
+		{"daoFork", c.IsDAOFork(num)},
+		{"eip150", c.IsEIP150(num)},
+		{"eip155", c.IsEIP155(num)},
+		{"eip158", c.IsEIP158(num)},
+		{"byzantium", c.IsByzantium(num)},
+		{"constantinople", c.IsConstantinople(num)},
+		{"petersburg", c.IsPetersburg(num)},
+		{"istanbul", c.IsIstanbul(num)},
+		{"muirGlacier", c.IsMuirGlacier(num)},
+		{"berlin", c.IsBerlin(num)},
+		{"london", c.IsLondon(num)},
+		{"arrowGlacier", c.IsArrowGlacier(num)},
+		{"grayGlacier", c.IsGrayGlacier(num)},
+		{"shanghai", c.IsShanghai(num, time)},
+		{"cancun", c.IsCancun(num, time)},
+		{"prague", c.IsPrague(num, time)},
+		{"verkle", c.IsVerkle(num, time)},
+		{"rip7560", c.IsRIP7560(num, time)},
+		{"rip7712", c.IsRIP7712(num, time)},
+	}
+	var active []string
+	for _, fork := range forks {
+		if fork.active {
+			active = append(active, fork.name)
+		}
+	}
+	return active
 }
 
 // CheckCompatible checks whether scheduled fork transitions have been imported
@@ -750,6 +1043,18 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, headNumber *big.Int,
 	if isForkBlockIncompatible(c.MergeNetsplitBlock, newcfg.MergeNetsplitBlock, headNumber) {
 		return newBlockCompatError("Merge netsplit fork block", c.MergeNetsplitBlock, newcfg.MergeNetsplitBlock)
 	}
+	if isForkBlockIncompatible(c.RIP7560Block, newcfg.RIP7560Block, headNumber) {
+		return newBlockCompatError("RIP7560 fork block", c.RIP7560Block, newcfg.RIP7560Block)
+	}
+	if isForkBlockIncompatible(c.RIP7712Block, newcfg.RIP7712Block, headNumber) {
+		return newBlockCompatError("RIP7712 fork block", c.RIP7712Block, newcfg.RIP7712Block)
+	}
+	if isForkBlockIncompatible(c.RIP7560TypeBlock, newcfg.RIP7560TypeBlock, headNumber) {
+		return newBlockCompatError("RIP7560 type switch block", c.RIP7560TypeBlock, newcfg.RIP7560TypeBlock)
+	}
+	if isForkBlockIncompatible(c.RIP7560ContextGasBlock, newcfg.RIP7560ContextGasBlock, headNumber) {
+		return newBlockCompatError("RIP7560 context gas block", c.RIP7560ContextGasBlock, newcfg.RIP7560ContextGasBlock)
+	}
 	if isForkTimestampIncompatible(c.ShanghaiTime, newcfg.ShanghaiTime, headTimestamp) {
 		return newTimestampCompatError("Shanghai fork timestamp", c.ShanghaiTime, newcfg.ShanghaiTime)
 	}
@@ -762,6 +1067,12 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, headNumber *big.Int,
 	if isForkTimestampIncompatible(c.VerkleTime, newcfg.VerkleTime, headTimestamp) {
 		return newTimestampCompatError("Verkle fork timestamp", c.VerkleTime, newcfg.VerkleTime)
 	}
+	if isForkTimestampIncompatible(c.RIP7560Time, newcfg.RIP7560Time, headTimestamp) {
+		return newTimestampCompatError("RIP7560 fork timestamp", c.RIP7560Time, newcfg.RIP7560Time)
+	}
+	if isForkTimestampIncompatible(c.RIP7712Time, newcfg.RIP7712Time, headTimestamp) {
+		return newTimestampCompatError("RIP7712 fork timestamp", c.RIP7712Time, newcfg.RIP7712Time)
+	}
 	return nil
 }
 
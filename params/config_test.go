@@ -17,6 +17,7 @@
 package params
 
 import (
+	"encoding/json"
 	"math/big"
 	"reflect"
 	"testing"
@@ -93,6 +94,17 @@ func TestCheckCompatible(t *testing.T) {
 				RewindToBlock: 30,
 			},
 		},
+		{
+			stored:    &ChainConfig{RIP7560Block: big.NewInt(10)},
+			new:       &ChainConfig{RIP7560Block: big.NewInt(20)},
+			headBlock: 15,
+			wantErr: &ConfigCompatError{
+				What:          "RIP7560 fork block",
+				StoredBlock:   big.NewInt(10),
+				NewBlock:      big.NewInt(20),
+				RewindToBlock: 9,
+			},
+		},
 		{
 			stored:        &ChainConfig{ShanghaiTime: newUint64(10)},
 			new:           &ChainConfig{ShanghaiTime: newUint64(20)},
@@ -155,3 +167,126 @@ func TestTimestampCompatError(t *testing.T) {
 	require.Equal(t, newTimestampCompatError(errWhat, newUint64(0), newUint64(1681338455)).Error(),
 		"mismatching Shanghai fork timestamp in database (have timestamp 0, want timestamp 1681338455, rewindto timestamp 0)")
 }
+
+// Tests that the RIP forks can be scheduled by timestamp alone, without any
+// dependency on the block number.
+func TestRIP7560TimestampActivation(t *testing.T) {
+	c := &ChainConfig{RIP7560Time: newUint64(500), RIP7712Time: newUint64(1000)}
+	if c.IsRIP7560(big.NewInt(1_000_000), 0) {
+		t.Fatal("RIP7560 active before its timestamp")
+	}
+	if !c.IsRIP7560(nil, 500) {
+		t.Fatal("RIP7560 not active at its timestamp")
+	}
+	if c.IsRIP7712(nil, 500) {
+		t.Fatal("RIP7712 active before its timestamp")
+	}
+	// Rescheduling an already activated fork must be rejected
+	newcfg := &ChainConfig{RIP7560Time: newUint64(600), RIP7712Time: newUint64(1000)}
+	if err := c.CheckCompatible(newcfg, 0, 550); err == nil || err.RewindToTime != 499 {
+		t.Fatalf("expected timestamp compat error, got %v", err)
+	}
+	if err := c.CheckCompatible(newcfg, 0, 450); err != nil {
+		t.Fatalf("unexpected compat error: %v", err)
+	}
+}
+
+// Tests that the active forks are listed in activation order, including the
+// ones scheduled by timestamp.
+func TestActiveForks(t *testing.T) {
+	c := &ChainConfig{
+		HomesteadBlock: big.NewInt(0),
+		BerlinBlock:    big.NewInt(10),
+		LondonBlock:    big.NewInt(10),
+		ShanghaiTime:   newUint64(500),
+		RIP7560Time:    newUint64(500),
+	}
+	if have, want := c.ActiveForks(big.NewInt(5), 0), []string{"homestead"}; !reflect.DeepEqual(have, want) {
+		t.Errorf("forks mismatch: have %v, want %v", have, want)
+	}
+	if have, want := c.ActiveForks(big.NewInt(10), 500), []string{"homestead", "berlin", "london", "shanghai", "rip7560"}; !reflect.DeepEqual(have, want) {
+		t.Errorf("forks mismatch: have %v, want %v", have, want)
+	}
+}
+
+// Tests that banned opcode exemptions only accept known banned opcodes and are
+// rejected on the public networks.
+func TestRip7560BannedOpcodeExemptions(t *testing.T) {
+	c := &ChainConfig{ChainID: big.NewInt(1337), Rip7560: &Rip7560Config{BannedOpcodeExemptions: []string{"TIMESTAMP"}}}
+	if err := c.CheckRip7560Config(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	banned := c.Rip7560BannedOpcodes()
+	if _, ok := banned["TIMESTAMP"]; ok {
+		t.Fatal("exempted opcode still banned")
+	}
+	if _, ok := banned["NUMBER"]; !ok {
+		t.Fatal("non-exempted opcode not banned")
+	}
+	c.Rip7560.BannedOpcodeExemptions = []string{"SLOAD"}
+	if err := c.CheckRip7560Config(); err == nil {
+		t.Fatal("expected error for exempting an unbanned opcode")
+	}
+	c.ChainID = MainnetChainConfig.ChainID
+	c.Rip7560.BannedOpcodeExemptions = []string{"TIMESTAMP"}
+	if err := c.CheckRip7560Config(); err == nil {
+		t.Fatal("expected error for exemptions on mainnet")
+	}
+}
+
+// Tests that RIP-7560 presets are expanded when decoding the chain config, with
+// the explicit fields overriding the ones of the preset.
+func TestRip7560Presets(t *testing.T) {
+	var c ChainConfig
+	if err := json.Unmarshal([]byte(`{"chainId": 1337, "rip7560": {"preset": "devnet-v1", "maxTxsPerBlock": 10}}`), &c); err != nil {
+		t.Fatalf("failed to decode config: %v", err)
+	}
+	want := Rip7560Presets["devnet-v1"]
+	want.Preset, want.MaxTxsPerBlock = "devnet-v1", 10
+	if !reflect.DeepEqual(*c.Rip7560, want) {
+		t.Fatalf("config mismatch: have %v, want %v", c.Rip7560, want)
+	}
+	// The expanded config round-trips
+	blob, err := json.Marshal(&c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dec ChainConfig
+	if err := json.Unmarshal(blob, &dec); err != nil {
+		t.Fatalf("failed to decode encoded config: %v", err)
+	}
+	if !reflect.DeepEqual(dec.Rip7560, c.Rip7560) {
+		t.Fatalf("round-trip mismatch: have %v, want %v", dec.Rip7560, c.Rip7560)
+	}
+	for name := range Rip7560Presets {
+		c := &ChainConfig{ChainID: big.NewInt(1337)}
+		if err := json.Unmarshal([]byte(`{"preset": "`+name+`"}`), &c.Rip7560); err != nil {
+			t.Fatalf("%s: failed to decode preset: %v", name, err)
+		}
+		if err := c.CheckRip7560Config(); err != nil {
+			t.Errorf("%s: invalid preset: %v", name, err)
+		}
+	}
+	if err := json.Unmarshal([]byte(`{"rip7560": {"preset": "unknown"}}`), &c); err == nil {
+		t.Fatal("unknown preset accepted")
+	}
+}
+
+// Tests that the RIP-7560 gas penalty defaults when unset, can be disabled, and
+// is rejected above the unused gas.
+func TestRip7560GasPenaltyPercent(t *testing.T) {
+	c := &ChainConfig{ChainID: big.NewInt(1337)}
+	if have := c.Rip7560GasPenaltyPercent(); have != Rip7560GasPenaltyPercent {
+		t.Errorf("default penalty mismatch: have %d, want %d", have, Rip7560GasPenaltyPercent)
+	}
+	if err := json.Unmarshal([]byte(`{"gasPenaltyPercent": 0}`), &c.Rip7560); err != nil {
+		t.Fatalf("failed to decode config: %v", err)
+	}
+	if have := c.Rip7560GasPenaltyPercent(); have != 0 {
+		t.Errorf("disabled penalty mismatch: have %d, want 0", have)
+	}
+	c.Rip7560.GasPenaltyPercent = newUint64(101)
+	if err := c.CheckRip7560Config(); err == nil {
+		t.Fatal("penalty above 100% accepted")
+	}
+}
@@ -91,10 +91,11 @@ const (
 	SelfdestructRefundGas uint64 = 24000 // Refunded following a selfdestruct operation.
 	MemoryGas             uint64 = 3     // Times the address of the (highest referenced byte in memory + 1). NOTE: referencing happens on read, write and in instructions such as RETURN and CALL.
 
-	TxDataNonZeroGasFrontier  uint64 = 68   // Per byte of data attached to a transaction that is not equal to zero. NOTE: Not payable on data of calls between transactions.
-	TxDataNonZeroGasEIP2028   uint64 = 16   // Per byte of non zero data attached to a transaction after EIP 2028 (part in Istanbul)
-	TxAccessListAddressGas    uint64 = 2400 // Per address specified in EIP 2930 access list
-	TxAccessListStorageKeyGas uint64 = 1900 // Per storage key specified in EIP 2930 access list
+	TxDataNonZeroGasFrontier  uint64 = 68    // Per byte of data attached to a transaction that is not equal to zero. NOTE: Not payable on data of calls between transactions.
+	TxDataNonZeroGasEIP2028   uint64 = 16    // Per byte of non zero data attached to a transaction after EIP 2028 (part in Istanbul)
+	TxAccessListAddressGas    uint64 = 2400  // Per address specified in EIP 2930 access list
+	TxAccessListStorageKeyGas uint64 = 1900  // Per storage key specified in EIP 2930 access list
+	TxAuthTupleGas            uint64 = 12500 // Per auth tuple code specified in EIP-7702
 
 	// These have been changed during the course of the chain
 	CallGasFrontier              uint64 = 40  // Once per CALL operation & message call transaction.
@@ -192,6 +193,57 @@ var (
 	// BeaconRootsCode is the code where historical beacon roots are stored as per EIP-4788
 	BeaconRootsCode = common.FromHex("3373fffffffffffffffffffffffffffffffffffffffe14604d57602036146024575f5ffd5b5f35801560495762001fff810690815414603c575f5ffd5b62001fff01545f5260205ff35b5f5ffd5b62001fff42064281555f359062001fff015500")
 
+	// Rip7560EntryPointAddress is the address RIP-7560 frames are called from,
+	// and the verifying contract of the EIP-712 domain of RIP-7560 transactions
+	Rip7560EntryPointAddress = common.HexToAddress("0x0000000000000000000000000000000000007560")
+
+	// Rip7712NonceManagerAddress is the address of the RIP-7712 NonceManager
+	// system contract tracking the two-dimensional nonces of RIP-7560 accounts
+	Rip7712NonceManagerAddress = common.HexToAddress("0x63f63e798f5F6A934Acf0a3FD1C01f3Fac851fF0")
+
+	// Rip7712NonceManagerCode is the code of the RIP-7712 NonceManager. Its calldata
+	// is packed as sender (20 bytes), nonce key (24 bytes) and, only if called by
+	// the RIP-7560 EntryPoint, nonce (8 bytes). Called by the EntryPoint, it reverts
+	// unless the nonce is the next one of the key, and increments it. Called by
+	// anyone else, it returns the next nonce with the key in its upper 192 bits.
+	// Nonces are stored in a mapping(address => mapping(uint192 => uint256)) at
+	// slot 0.
+	Rip7712NonceManagerCode = common.FromHex("60003560601c600052604060002060205260143560401c80600052604060002080543373000000000000000000000000000000000000756014604b5790509060401b1760005260206000f35b602c3560c01c811415605e576001019055005b600080fd")
+
+	// Rip7560DepositManagerAddress is the address of the RIP-7560 DepositManager
+	// system contract holding the gas deposits of paymasters
+	Rip7560DepositManagerAddress = common.HexToAddress("0x7560d3905173aa0000000000000000000000d3b0")
+
+	// Rip7560DepositManagerCode is the code of the RIP-7560 DepositManager. Calls
+	// with value credit it to the deposit of the 20 bytes address in calldata, or
+	// of the caller if there is none. Calls without value and with 20 bytes of
+	// calldata return the deposit of that address, and calls with 52 bytes of
+	// calldata withdraw the amount in the last 32 bytes from the deposit of the
+	// caller to the address in the first 20 bytes. Deposits are stored at the
+	// slot of the address they belong to.
+	Rip7560DepositManagerCode = common.FromHex("3460185736601414603657366034146046575b60006000fd5b333615602e5736601414156012575060003560601c5b805434019055005b60003560601c5460005260206000f35b6014353354818110601257819003335560006000600060008460003560601c5af11560125700")
+
 	// SystemAddress is where the system-transaction is sent from as per EIP-4788
 	SystemAddress = common.HexToAddress("0xfffffffffffffffffffffffffffffffffffffffe")
 )
+
+// BeaconRootsGasLimit is the gas available to the EIP-4788 system call.
+const BeaconRootsGasLimit uint64 = 30_000_000
+
+// Rip7560MaxPaymasterContextSize is the default maximum size of the context a
+// RIP-7560 paymaster may pass from its validation frame to its postOp frame.
+const Rip7560MaxPaymasterContextSize uint64 = 65536
+
+// Rip7560GasPenaltyPercent is the default share of the unused execution and
+// postOp gas limits of a RIP-7560 transaction charged to discourage reserving
+// more gas than needed.
+const Rip7560GasPenaltyPercent uint64 = 10
+
+// Rip7560BannedOpcodes are the opcodes RIP-7560 validation frames must not use
+// as their results may change between validation and inclusion [ERC-7562]. GAS
+// is only banned if not immediately followed by one of the CALL opcodes.
+var Rip7560BannedOpcodes = []string{
+	"GASPRICE", "GASLIMIT", "DIFFICULTY", "TIMESTAMP", "BASEFEE", "BLOCKHASH",
+	"NUMBER", "SELFBALANCE", "BALANCE", "ORIGIN", "GAS", "CREATE", "COINBASE",
+	"SELFDESTRUCT", "BLOBHASH", "BLOBBASEFEE", "INVALID",
+}
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math/rand"
 	"net"
 	"net/http"
@@ -740,6 +741,81 @@ func TestClientHTTP(t *testing.T) {
 	}
 }
 
+// streamService returns results which are streamed into the response.
+type streamService struct{ closed chan string }
+
+type streamResult struct {
+	str    string
+	closed chan string
+}
+
+func (r *streamResult) WriteJSON(w io.Writer) error {
+	enc, err := json.Marshal(r.str)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(enc)
+	return err
+}
+
+func (r *streamResult) Close() { r.closed <- r.str }
+
+func (s *streamService) Echo(str string) *streamResult {
+	return &streamResult{str: str, closed: s.closed}
+}
+
+// This test checks that streaming results are written into the responses of
+// all transports and batches, and released afterwards.
+func TestClientStreamingResult(t *testing.T) {
+	service := &streamService{closed: make(chan string, 2)}
+	server := NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("stream", service); err != nil {
+		t.Fatal(err)
+	}
+	check := func(t *testing.T, client *Client) {
+		var result string
+		if err := client.Call(&result, "stream_echo", "single"); err != nil {
+			t.Fatal(err)
+		}
+		if result != "single" {
+			t.Fatalf("result mismatch: have %q, want %q", result, "single")
+		}
+		if closed := <-service.closed; closed != "single" {
+			t.Fatalf("closed result mismatch: have %q, want %q", closed, "single")
+		}
+		batch := []BatchElem{
+			{Method: "stream_echo", Args: []interface{}{"a"}, Result: new(string)},
+			{Method: "stream_echo", Args: []interface{}{"b"}, Result: new(string)},
+		}
+		if err := client.BatchCall(batch); err != nil {
+			t.Fatal(err)
+		}
+		for i, want := range []string{"a", "b"} {
+			if batch[i].Error != nil {
+				t.Fatalf("batch element %d failed: %v", i, batch[i].Error)
+			}
+			if have := *batch[i].Result.(*string); have != want {
+				t.Fatalf("batch result %d mismatch: have %q, want %q", i, have, want)
+			}
+			<-service.closed
+		}
+	}
+	t.Run("inproc", func(t *testing.T) {
+		client := DialInProc(server)
+		defer client.Close()
+		check(t, client)
+	})
+	for _, transport := range []string{"http", "ws"} {
+		t.Run(transport, func(t *testing.T) {
+			client, hs := httpTestClient(server, transport, nil)
+			defer hs.Close()
+			defer client.Close()
+			check(t, client)
+		})
+	}
+}
+
 func TestClientReconnect(t *testing.T) {
 	startServer := func(addr string) (*Server, net.Listener) {
 		srv := newTestServer()
@@ -225,6 +225,9 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 				break
 			}
 			resp := h.handleCallMsg(cp, msg)
+			if resp != nil {
+				resp.bufferResult() // batches are sent as a whole
+			}
 			callBuffer.pushResponse(resp)
 			if resp != nil && h.batchResponseMaxSize != 0 {
 				responseBytes += len(resp.Result)
@@ -302,6 +305,7 @@ func (h *handler) handleNonBatchCall(cp *callProc, msg *jsonrpcMessage) {
 		responded.Do(func() {
 			h.conn.writeJSON(cp.ctx, answer, false)
 		})
+		answer.discardResult() // the timeout was answered instead
 	}
 	for _, n := range cp.notifiers {
 		n.activate()
@@ -180,6 +180,7 @@ func TestHTTPPeerInfo(t *testing.T) {
 	}
 	c.SetHeader("user-agent", "ua-testing")
 	c.SetHeader("origin", "origin.example.com")
+	c.SetHeader("x-api-key", "tenant")
 
 	// Request peer information.
 	var info PeerInfo
@@ -202,6 +203,9 @@ func TestHTTPPeerInfo(t *testing.T) {
 	if info.HTTP.Origin != "origin.example.com" {
 		t.Errorf("wrong HTTP.Origin %q", info.HTTP.UserAgent)
 	}
+	if info.HTTP.APIKey != "tenant" {
+		t.Errorf("wrong HTTP.APIKey %q", info.HTTP.APIKey)
+	}
 }
 
 func TestNewContextWithHeaders(t *testing.T) {
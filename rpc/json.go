@@ -17,6 +17,7 @@
 package rpc
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -66,6 +67,8 @@ type jsonrpcMessage struct {
 	Params  json.RawMessage `json:"params,omitempty"`
 	Error   *jsonError      `json:"error,omitempty"`
 	Result  json.RawMessage `json:"result,omitempty"`
+
+	stream StreamingResult // Result written into the connection when sending the response
 }
 
 func (msg *jsonrpcMessage) isNotification() bool {
@@ -113,6 +116,9 @@ func (msg *jsonrpcMessage) errorResponse(err error) *jsonrpcMessage {
 }
 
 func (msg *jsonrpcMessage) response(result interface{}) *jsonrpcMessage {
+	if stream, ok := result.(StreamingResult); ok {
+		return &jsonrpcMessage{Version: vsn, ID: msg.ID, stream: stream}
+	}
 	enc, err := json.Marshal(result)
 	if err != nil {
 		return msg.errorResponse(&internalServerError{errcodeMarshalError, err.Error()})
@@ -120,6 +126,30 @@ func (msg *jsonrpcMessage) response(result interface{}) *jsonrpcMessage {
 	return &jsonrpcMessage{Version: vsn, ID: msg.ID, Result: enc}
 }
 
+// bufferResult encodes a streaming result into the Result field, for the
+// transports and batches which cannot stream it.
+func (msg *jsonrpcMessage) bufferResult() {
+	if msg.stream == nil {
+		return
+	}
+	defer msg.discardResult()
+
+	var buf bytes.Buffer
+	if err := msg.stream.WriteJSON(&buf); err != nil {
+		msg.Error = &jsonError{Code: errcodeMarshalError, Message: err.Error()}
+		return
+	}
+	msg.Result = buf.Bytes()
+}
+
+// discardResult releases the streaming result of a response which is not sent.
+func (msg *jsonrpcMessage) discardResult() {
+	if msg.stream != nil {
+		msg.stream.Close()
+		msg.stream = nil
+	}
+}
+
 func errorMessage(err error) *jsonrpcMessage {
 	msg := &jsonrpcMessage{Version: vsn, ID: null, Error: &jsonError{
 		Code:    errcodeDefault,
@@ -185,6 +215,7 @@ type jsonCodec struct {
 	encMu   sync.Mutex       // guards the encoder
 	encode  encodeFunc       // encoder to allow multiple transports
 	conn    deadlineCloser
+	writer  io.Writer // underlying stream, if streaming results is supported
 }
 
 type encodeFunc = func(v interface{}, isErrorResponse bool) error
@@ -217,7 +248,9 @@ func NewCodec(conn Conn) ServerCodec {
 	encode := func(v interface{}, isErrorResponse bool) error {
 		return enc.Encode(v)
 	}
-	return NewFuncCodec(conn, encode, dec.Decode)
+	codec := NewFuncCodec(conn, encode, dec.Decode).(*jsonCodec)
+	codec.writer = conn
+	return codec
 }
 
 func (c *jsonCodec) peerInfo() PeerInfo {
@@ -256,9 +289,46 @@ func (c *jsonCodec) writeJSON(ctx context.Context, v interface{}, isErrorRespons
 		deadline = time.Now().Add(defaultWriteTimeout)
 	}
 	c.conn.SetWriteDeadline(deadline)
+
+	switch v := v.(type) {
+	case *jsonrpcMessage:
+		if v.stream != nil && c.writer != nil {
+			return c.writeStream(v)
+		}
+		v.bufferResult()
+	case []*jsonrpcMessage:
+		for _, msg := range v {
+			msg.bufferResult()
+		}
+	}
 	return c.encode(v, isErrorResponse)
 }
 
+// writeStream writes a response whose result is streamed into the connection
+// instead of being encoded in memory. A failure halfway leaves a truncated
+// message behind, so the connection is closed.
+func (c *jsonCodec) writeStream(msg *jsonrpcMessage) error {
+	defer msg.discardResult()
+
+	head, err := json.Marshal(&jsonrpcMessage{Version: msg.Version, ID: msg.ID})
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(c.writer)
+	w.Write(head[:len(head)-1])
+	w.WriteString(`,"result":`)
+	if err := msg.stream.WriteJSON(w); err != nil {
+		c.close()
+		return err
+	}
+	w.WriteString("}\n")
+	if err := w.Flush(); err != nil {
+		c.close()
+		return err
+	}
+	return nil
+}
+
 func (c *jsonCodec) close() {
 	c.closer.Do(func() {
 		close(c.closeCh)
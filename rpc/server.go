@@ -218,6 +218,7 @@ type PeerInfo struct {
 		UserAgent string
 		Origin    string
 		Host      string
+		APIKey    string // Value of the X-Api-Key header, identifying the tenant of shared nodes
 	}
 }
 
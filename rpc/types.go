@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"strings"
 
@@ -48,6 +49,15 @@ type ServerCodec interface {
 	jsonWriter
 }
 
+// StreamingResult can be returned by methods whose result is too large to be
+// encoded in memory. Its JSON encoding is written straight into the connection
+// when sending the response, or buffered on transports and in batches which
+// cannot stream it. Close is called once the result is written or discarded.
+type StreamingResult interface {
+	WriteJSON(w io.Writer) error
+	Close()
+}
+
 // jsonWriter can write JSON messages to its underlying connection.
 // Implementations must be safe for concurrent use.
 type jsonWriter interface {
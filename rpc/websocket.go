@@ -19,7 +19,9 @@ package rpc
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
@@ -49,10 +51,11 @@ var wsBufferPool = new(sync.Pool)
 // To allow connections with any origin, pass "*".
 func (s *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 	var upgrader = websocket.Upgrader{
-		ReadBufferSize:  wsReadBuffer,
-		WriteBufferSize: wsWriteBuffer,
-		WriteBufferPool: wsBufferPool,
-		CheckOrigin:     wsHandshakeValidator(allowedOrigins),
+		ReadBufferSize:    wsReadBuffer,
+		WriteBufferSize:   wsWriteBuffer,
+		WriteBufferPool:   wsBufferPool,
+		CheckOrigin:       wsHandshakeValidator(allowedOrigins),
+		EnableCompression: true, // permessage-deflate, if requested by the client
 	}
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		conn, err := upgrader.Upgrade(w, r, nil)
@@ -225,10 +228,11 @@ func newClientTransportWS(endpoint string, cfg *clientConfig) (reconnectFunc, er
 	dialer := cfg.wsDialer
 	if dialer == nil {
 		dialer = &websocket.Dialer{
-			ReadBufferSize:  wsReadBuffer,
-			WriteBufferSize: wsWriteBuffer,
-			WriteBufferPool: wsBufferPool,
-			Proxy:           http.ProxyFromEnvironment,
+			ReadBufferSize:    wsReadBuffer,
+			WriteBufferSize:   wsWriteBuffer,
+			WriteBufferPool:   wsBufferPool,
+			Proxy:             http.ProxyFromEnvironment,
+			EnableCompression: true,
 		}
 	}
 
@@ -297,7 +301,7 @@ func newWebsocketCodec(conn *websocket.Conn, host string, req http.Header, readL
 		return conn.WriteJSON(v)
 	}
 	wc := &websocketCodec{
-		jsonCodec:    NewFuncCodec(conn, encode, conn.ReadJSON).(*jsonCodec),
+		jsonCodec:    NewFuncCodec(conn, encode, wsReadJSON(conn, readLimit)).(*jsonCodec),
 		conn:         conn,
 		pingReset:    make(chan struct{}, 1),
 		pongReceived: make(chan struct{}),
@@ -310,6 +314,7 @@ func newWebsocketCodec(conn *websocket.Conn, host string, req http.Header, readL
 	wc.info.HTTP.Host = host
 	wc.info.HTTP.Origin = req.Get("Origin")
 	wc.info.HTTP.UserAgent = req.Get("User-Agent")
+	wc.info.HTTP.APIKey = req.Get("X-Api-Key")
 	// Start pinger.
 	conn.SetPongHandler(func(appData string) error {
 		select {
@@ -323,6 +328,52 @@ func newWebsocketCodec(conn *websocket.Conn, host string, req http.Header, readL
 	return wc
 }
 
+// wsReadJSON returns a decoder of the messages of the connection. The read limit
+// of the connection applies to the frames as sent, so the decoder limits the size
+// of the messages after decompression as well.
+func wsReadJSON(conn *websocket.Conn, readLimit int64) func(v interface{}) error {
+	return func(v interface{}) error {
+		_, r, err := conn.NextReader()
+		if err != nil {
+			return err
+		}
+		if readLimit > 0 {
+			r = &wsLimitReader{r: r, left: readLimit}
+		}
+		err = json.NewDecoder(r).Decode(v)
+		if err == io.EOF {
+			// One value is expected in the message.
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+}
+
+// wsLimitReader fails with websocket.ErrReadLimit when reading more than the
+// given number of bytes.
+type wsLimitReader struct {
+	r    io.Reader
+	left int64
+}
+
+func (l *wsLimitReader) Read(p []byte) (int, error) {
+	if l.left <= 0 {
+		// Check if the message continues beyond the limit.
+		var probe [1]byte
+		n, err := l.r.Read(probe[:])
+		if n > 0 {
+			return 0, websocket.ErrReadLimit
+		}
+		return 0, err
+	}
+	if int64(len(p)) > l.left {
+		p = p[:l.left]
+	}
+	n, err := l.r.Read(p)
+	l.left -= int64(n)
+	return n, err
+}
+
 func (wc *websocketCodec) close() {
 	wc.jsonCodec.close()
 	wc.wg.Wait()
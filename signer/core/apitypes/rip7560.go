@@ -0,0 +1,126 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package apitypes
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Rip7560PrimaryType is the EIP-712 primary type of RIP-7560 transactions.
+const Rip7560PrimaryType = "Rip7560Transaction"
+
+// rip7560Types are the EIP-712 types describing a RIP-7560 transaction.
+var rip7560Types = Types{
+	"EIP712Domain": {
+		{Name: "name", Type: "string"},
+		{Name: "version", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+		{Name: "verifyingContract", Type: "address"},
+	},
+	Rip7560PrimaryType: {
+		{Name: "sender", Type: "address"},
+		{Name: "nonceKey", Type: "uint256"},
+		{Name: "nonce", Type: "uint256"},
+		{Name: "deployer", Type: "address"},
+		{Name: "deployerData", Type: "bytes"},
+		{Name: "paymaster", Type: "address"},
+		{Name: "paymasterData", Type: "bytes"},
+		{Name: "executionData", Type: "bytes"},
+		{Name: "builderFee", Type: "uint256"},
+		{Name: "maxPriorityFeePerGas", Type: "uint256"},
+		{Name: "maxFeePerGas", Type: "uint256"},
+		{Name: "validationGasLimit", Type: "uint256"},
+		{Name: "paymasterValidationGasLimit", Type: "uint256"},
+		{Name: "postOpGasLimit", Type: "uint256"},
+		{Name: "callGasLimit", Type: "uint256"},
+	},
+}
+
+// Rip7560TypedData returns the EIP-712 representation of a RIP-7560 transaction,
+// for wallets which can only sign through typed data flows.
+//
+// The hash of the typed data is unrelated to the consensus signing hash of the
+// transaction. It only authorizes the transaction if the validation code of the
+// account verifies it. The access list is not part of the typed data.
+func Rip7560TypedData(tx *types.Transaction, chainID *big.Int) (*TypedData, error) {
+	if tx.Type() != types.Rip7560Type {
+		return nil, errors.New("not a RIP-7560 transaction")
+	}
+	aatx := tx.Rip7560TransactionData()
+	if aatx.Sender == nil {
+		return nil, errors.New("missing sender")
+	}
+	address := func(addr *common.Address) string {
+		if addr == nil {
+			return common.Address{}.Hex()
+		}
+		return addr.Hex()
+	}
+	integer := func(v *big.Int) string {
+		if v == nil {
+			return "0"
+		}
+		return v.String()
+	}
+	return &TypedData{
+		Types:       rip7560Types,
+		PrimaryType: Rip7560PrimaryType,
+		Domain: TypedDataDomain{
+			Name:              "RIP7560",
+			Version:           "1",
+			ChainId:           (*math.HexOrDecimal256)(new(big.Int).Set(chainID)),
+			VerifyingContract: params.Rip7560EntryPointAddress.Hex(),
+		},
+		Message: TypedDataMessage{
+			"sender":                      aatx.Sender.Hex(),
+			"nonceKey":                    integer(aatx.NonceKey),
+			"nonce":                       new(big.Int).SetUint64(aatx.Nonce).String(),
+			"deployer":                    address(aatx.Deployer),
+			"deployerData":                hexutil.Encode(aatx.DeployerData),
+			"paymaster":                   address(aatx.Paymaster),
+			"paymasterData":               hexutil.Encode(aatx.PaymasterData),
+			"executionData":               hexutil.Encode(aatx.ExecutionData),
+			"builderFee":                  integer(aatx.BuilderFee),
+			"maxPriorityFeePerGas":        integer(aatx.GasTipCap),
+			"maxFeePerGas":                integer(aatx.GasFeeCap),
+			"validationGasLimit":          new(big.Int).SetUint64(aatx.ValidationGasLimit).String(),
+			"paymasterValidationGasLimit": new(big.Int).SetUint64(aatx.PaymasterValidationGasLimit).String(),
+			"postOpGasLimit":              new(big.Int).SetUint64(aatx.PostOpGas).String(),
+			"callGasLimit":                new(big.Int).SetUint64(aatx.Gas).String(),
+		},
+	}, nil
+}
+
+// Rip7560TypedDataHash returns the EIP-712 hash of a RIP-7560 transaction.
+func Rip7560TypedDataHash(tx *types.Transaction, chainID *big.Int) (common.Hash, error) {
+	typedData, err := Rip7560TypedData(tx, chainID)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	hash, _, err := TypedDataAndHash(*typedData)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(hash), nil
+}
@@ -19,6 +19,7 @@ package apitypes
 import (
 	"crypto/sha256"
 	"encoding/json"
+	"math/big"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -141,3 +142,56 @@ func TestBlobTxs(t *testing.T) {
 	}
 	t.Logf("tx %v", string(data))
 }
+
+// Tests that the typed data of a RIP-7560 transaction survives a JSON round trip,
+// as done when passing it to a wallet, and commits to the transaction fields.
+func TestRip7560TypedData(t *testing.T) {
+	var (
+		chainID   = big.NewInt(1337)
+		paymaster = common.Address{0xbb}
+	)
+	newTx := func(nonce uint64) *types.Transaction {
+		return types.NewTx(&types.Rip7560AccountAbstractionTx{
+			Nonce:         nonce,
+			GasTipCap:     big.NewInt(1),
+			GasFeeCap:     big.NewInt(2),
+			Gas:           100_000,
+			Sender:        &common.Address{0xaa},
+			ExecutionData: []byte{0x01, 0x02},
+			Paymaster:     &paymaster,
+		})
+	}
+	typedData, err := Rip7560TypedData(newTx(1), chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, _, err := TypedDataAndHash(*typedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := json.Marshal(typedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dec TypedData
+	if err := json.Unmarshal(enc, &dec); err != nil {
+		t.Fatal(err)
+	}
+	decHash, _, err := TypedDataAndHash(dec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if common.BytesToHash(hash) != common.BytesToHash(decHash) {
+		t.Fatalf("hash changed after round trip: %x != %x", hash, decHash)
+	}
+	other, err := Rip7560TypedDataHash(newTx(2), chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == common.BytesToHash(hash) {
+		t.Fatal("typed data hash doesn't commit to the nonce")
+	}
+	if _, err := Rip7560TypedData(types.NewTransaction(0, common.Address{}, nil, 0, nil, nil), chainID); err == nil {
+		t.Fatal("expected error for non RIP-7560 transaction")
+	}
+}
@@ -44,6 +44,7 @@ var (
 	executionSpecBlockchainTestDir = filepath.Join(".", "spec-tests", "fixtures", "blockchain_tests")
 	executionSpecStateTestDir      = filepath.Join(".", "spec-tests", "fixtures", "state_tests")
 	benchmarksDir                  = filepath.Join(".", "evm-benchmarks", "benchmarks")
+	rip7560StateTestDir            = filepath.Join(".", "rip7560", "testdata", "StateTests")
 )
 
 func readJSON(reader io.Reader, value interface{}) error {
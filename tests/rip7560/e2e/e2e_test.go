@@ -0,0 +1,127 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package e2e
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	sender    = common.HexToAddress("0x1111111111222222222233333333334444444444")
+	paymaster = common.HexToAddress("0x5555555555666666666677777777778888888888")
+	deployer  = common.HexToAddress("0x9999999999aaaaaaaaaabbbbbbbbbbcccccccccc")
+	ether     = big.NewInt(params.Ether)
+)
+
+// Tests that a transaction paid by its sender is executed, and charged to the
+// sender.
+func TestSelfPaid(t *testing.T) {
+	s := NewScenario(t).WithAccount(sender, AccountCode(), ether).Start()
+
+	tx := s.Tx(&types.Rip7560AccountAbstractionTx{Sender: &sender, ExecutionData: []byte{0x01}})
+	s.Send(tx).Commit().
+		ExpectStatus(tx, types.ReceiptStatusSuccessful).
+		ExpectStorage(sender, common.Hash{}, common.Hash{0x01}).
+		ExpectNonce(sender, nil, 1).
+		ExpectCharged(sender, tx)
+}
+
+// Tests that a sponsored transaction is charged to its paymaster, letting
+// senders without any balance transact.
+func TestSponsored(t *testing.T) {
+	s := NewScenario(t).
+		WithAccount(sender, AccountCode(), new(big.Int)).
+		WithAccount(paymaster, PaymasterCode(), ether).
+		Start()
+
+	tx := s.Tx(&types.Rip7560AccountAbstractionTx{Sender: &sender, Paymaster: &paymaster, ExecutionData: []byte{0x02}})
+	s.Send(tx).Commit().
+		ExpectStatus(tx, types.ReceiptStatusSuccessful).
+		ExpectStorage(sender, common.Hash{}, common.Hash{0x02}).
+		ExpectCharged(paymaster, tx)
+}
+
+// Tests that the deployer frame deploys the sender before its validation.
+func TestDeployment(t *testing.T) {
+	account := DeployedAddress(deployer, AccountCode())
+	s := NewScenario(t).
+		WithAccount(deployer, DeployerCode(AccountCode()), new(big.Int)).
+		WithAccount(account, nil, ether).
+		Start()
+
+	s.ExpectCode(account, false)
+	tx := s.Tx(&types.Rip7560AccountAbstractionTx{Sender: &account, Deployer: &deployer, ExecutionData: []byte{0x03}, ValidationGasLimit: 500_000})
+	s.Send(tx).Commit().
+		ExpectStatus(tx, types.ReceiptStatusSuccessful).
+		ExpectCode(account, true).
+		ExpectStorage(account, common.Hash{}, common.Hash{0x03})
+}
+
+// Tests that transactions rejected by their account are refused by the pool and
+// never included.
+func TestRejected(t *testing.T) {
+	s := NewScenario(t).WithAccount(sender, RejectingAccountCode(), ether).Start()
+
+	tx := s.Tx(&types.Rip7560AccountAbstractionTx{Sender: &sender, ExecutionData: []byte{0x04}})
+	if err := s.SendRejected(tx); !strings.Contains(err.Error(), "signature") {
+		t.Errorf("unexpected rejection: %v", err)
+	}
+	s.Commit().
+		ExpectNotIncluded(tx).
+		ExpectNonce(sender, nil, 0)
+}
+
+// Tests that consecutive transactions of a sender are included in order, over
+// multiple blocks.
+func TestSequence(t *testing.T) {
+	s := NewScenario(t).WithAccount(sender, AccountCode(), ether).Start()
+
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		tx := s.Tx(&types.Rip7560AccountAbstractionTx{Sender: &sender, Nonce: nonce, ExecutionData: []byte{byte(0x10 + nonce)}})
+		s.Send(tx).Commit().
+			ExpectStatus(tx, types.ReceiptStatusSuccessful).
+			ExpectStorage(sender, common.Hash{}, common.Hash{byte(0x10 + nonce)})
+	}
+	s.ExpectNonce(sender, nil, 3)
+}
+
+// Tests that a dry run over aa_call runs both phases without committing them.
+func TestCall(t *testing.T) {
+	s := NewScenario(t).WithAccount(sender, AccountCode(), ether).Start()
+
+	tx := s.Tx(&types.Rip7560AccountAbstractionTx{Sender: &sender, ExecutionData: []byte{0x05}})
+	result, err := s.AAClient().Call(context.Background(), tx, nil)
+	if err != nil {
+		t.Fatalf("failed to call transaction: %v", err)
+	}
+	if result.Status != hexutil.Uint64(types.ReceiptStatusSuccessful) {
+		t.Errorf("status mismatch: have %d, want %d", result.Status, types.ReceiptStatusSuccessful)
+	}
+	if len(result.Frames) != 2 || result.Frames[1].GasUsed == 0 {
+		t.Errorf("frames mismatch: %+v", result.Frames)
+	}
+	s.ExpectStorage(sender, common.Hash{}, common.Hash{}).
+		ExpectNonce(sender, nil, 0)
+}
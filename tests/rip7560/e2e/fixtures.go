@@ -0,0 +1,84 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package e2e
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// AccountCode returns the code of a smart account accepting all transactions.
+// Every call stores the first word of its calldata in slot 0, so that the last
+// execution frame run can be checked.
+func AccountCode() []byte {
+	code := []byte{byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 0, byte(vm.SSTORE)}
+	return append(code, returnWords(core.PackValidationData(core.AcceptAccountMethodSig, 0, 0))...)
+}
+
+// RejectingAccountCode returns the code of a smart account rejecting all
+// transactions with a signature failure.
+func RejectingAccountCode() []byte {
+	return returnWords(core.PackValidationData(core.SigFailAccountMethodSig, 0, 0))
+}
+
+// PaymasterCode returns the code of a paymaster sponsoring all transactions,
+// with an empty context.
+func PaymasterCode() []byte {
+	ret := core.PackValidationData(core.AcceptPaymasterMethodSig, 0, 0)
+	ret = append(ret, common.LeftPadBytes([]byte{64}, 32)...)
+	return returnWords(append(ret, make([]byte, 32)...))
+}
+
+// DeployerCode returns the code of a deployer creating an account with the given
+// code on every call, with CREATE2 and a zero salt.
+func DeployerCode(code []byte) []byte {
+	initcode := deployerInitcode(code)
+	deployer := []byte{
+		byte(vm.PUSH2), byte(len(initcode) >> 8), byte(len(initcode)), byte(vm.PUSH1), 20, byte(vm.PUSH1), 0, byte(vm.CODECOPY),
+		byte(vm.PUSH1), 0, byte(vm.PUSH2), byte(len(initcode) >> 8), byte(len(initcode)), byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.CREATE2),
+		byte(vm.POP), byte(vm.STOP),
+	}
+	return append(deployer, initcode...)
+}
+
+// DeployedAddress returns the address of the account created with the given
+// code by the deployer of DeployerCode.
+func DeployedAddress(deployer common.Address, code []byte) common.Address {
+	return crypto.CreateAddress2(deployer, [32]byte{}, crypto.Keccak256(deployerInitcode(code)))
+}
+
+// deployerInitcode returns the init code returning the given code.
+func deployerInitcode(code []byte) []byte {
+	initcode := []byte{
+		byte(vm.PUSH2), byte(len(code) >> 8), byte(len(code)), byte(vm.DUP1), byte(vm.PUSH1), 12, byte(vm.PUSH1), 0, byte(vm.CODECOPY),
+		byte(vm.PUSH1), 0, byte(vm.RETURN),
+	}
+	return append(initcode, code...)
+}
+
+// returnWords returns the code returning the given data, a multiple of words.
+func returnWords(data []byte) []byte {
+	var code []byte
+	for i := 0; i < len(data); i += 32 {
+		code = append(code, byte(vm.PUSH32))
+		code = append(code, data[i:i+32]...)
+		code = append(code, byte(vm.PUSH1), byte(i), byte(vm.MSTORE))
+	}
+	return append(code, byte(vm.PUSH1), byte(len(data)), byte(vm.PUSH1), 0, byte(vm.RETURN))
+}
@@ -0,0 +1,286 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package e2e runs RIP-7560 scenarios end to end: against an in-process node
+// sealing blocks with a simulated beacon client, with the transactions submitted
+// and the results inspected over RPC.
+package e2e
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/catalyst"
+	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethclient/aaclient"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Scenario is an end-to-end RIP-7560 test. It is built by declaring the genesis
+// state with the With methods, after which Start launches the node, and the
+// transactions are submitted and sealed with Send and Commit. The Expect methods
+// assert the outcome over RPC, failing the test on mismatches.
+type Scenario struct {
+	t      *testing.T
+	alloc  types.GenesisAlloc
+	config *params.ChainConfig
+
+	beacon   *catalyst.SimulatedBeacon
+	client   *ethclient.Client
+	aaclient *aaclient.Client
+}
+
+// NewScenario creates a scenario on the developer chain, which has RIP-7560
+// active from genesis.
+func NewScenario(t *testing.T) *Scenario {
+	t.Helper()
+	return &Scenario{
+		t:      t,
+		alloc:  make(types.GenesisAlloc),
+		config: params.AllDevChainProtocolChanges,
+	}
+}
+
+// WithAccount adds an account with the given code and balance to the genesis.
+func (s *Scenario) WithAccount(addr common.Address, code []byte, balance *big.Int) *Scenario {
+	s.alloc[addr] = types.Account{Code: code, Balance: balance}
+	return s
+}
+
+// WithStorage sets a storage slot of an account added to the genesis.
+func (s *Scenario) WithStorage(addr common.Address, slot, value common.Hash) *Scenario {
+	account := s.alloc[addr]
+	if account.Storage == nil {
+		account.Storage = make(map[common.Hash]common.Hash)
+	}
+	account.Storage[slot] = value
+	s.alloc[addr] = account
+	return s
+}
+
+// WithConfig replaces the chain config of the developer chain.
+func (s *Scenario) WithConfig(config *params.ChainConfig) *Scenario {
+	s.config = config
+	return s
+}
+
+// Start launches the node with the RIP-7560 pool enabled and the genesis state
+// of the scenario. The node is stopped when the test ends.
+func (s *Scenario) Start() *Scenario {
+	s.t.Helper()
+
+	stack, err := node.New(&node.Config{P2P: p2p.Config{NoDiscovery: true}})
+	if err != nil {
+		s.t.Fatalf("failed to create node: %v", err)
+	}
+	s.t.Cleanup(func() { stack.Close() })
+
+	config := ethconfig.Defaults
+	config.Genesis = &core.Genesis{
+		Config:   s.config,
+		GasLimit: ethconfig.Defaults.Miner.GasCeil,
+		Alloc:    s.alloc,
+	}
+	config.SyncMode = downloader.FullSync
+	config.TxPool.NoLocals = true
+	config.Rip7560Pool.Enabled = true
+
+	backend, err := eth.New(stack, &config)
+	if err != nil {
+		s.t.Fatalf("failed to create backend: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		s.t.Fatalf("failed to start node: %v", err)
+	}
+	beacon, err := catalyst.NewSimulatedBeacon(0, backend)
+	if err != nil {
+		s.t.Fatalf("failed to create simulated beacon: %v", err)
+	}
+	if err := beacon.Fork(backend.BlockChain().GetCanonicalHash(0)); err != nil {
+		s.t.Fatalf("failed to reset simulated beacon: %v", err)
+	}
+	s.t.Cleanup(func() { beacon.Stop() })
+
+	rpcClient := stack.Attach()
+	s.beacon = beacon
+	s.client, s.aaclient = ethclient.NewClient(rpcClient), aaclient.New(rpcClient)
+	return s
+}
+
+// Tx completes a RIP-7560 transaction with the chain ID of the scenario and the
+// fees and gas limits most scenarios need, for the fields left unset.
+func (s *Scenario) Tx(aatx *types.Rip7560AccountAbstractionTx) *types.Transaction {
+	if aatx.ChainID == nil {
+		aatx.ChainID = s.config.ChainID
+	}
+	if aatx.NonceKey == nil {
+		aatx.NonceKey = new(big.Int)
+	}
+	if aatx.GasFeeCap == nil {
+		aatx.GasFeeCap = big.NewInt(10 * params.GWei)
+	}
+	if aatx.GasTipCap == nil {
+		aatx.GasTipCap = big.NewInt(params.GWei)
+	}
+	if aatx.Gas == 0 {
+		aatx.Gas = 100_000
+	}
+	if aatx.ValidationGasLimit == 0 {
+		aatx.ValidationGasLimit = 100_000
+	}
+	if aatx.Paymaster != nil && aatx.PaymasterValidationGasLimit == 0 {
+		aatx.PaymasterValidationGasLimit = 100_000
+	}
+	return types.NewTx(aatx)
+}
+
+// Send submits transactions over eth_sendRawTransaction, failing the test if
+// any is rejected.
+func (s *Scenario) Send(txs ...*types.Transaction) *Scenario {
+	s.t.Helper()
+	for i, tx := range txs {
+		if err := s.aaclient.SendTransaction(context.Background(), tx); err != nil {
+			s.t.Fatalf("tx %d: failed to send transaction: %v", i, err)
+		}
+	}
+	return s
+}
+
+// SendRejected submits a transaction over eth_sendRawTransaction, failing the
+// test unless it is rejected.
+func (s *Scenario) SendRejected(tx *types.Transaction) error {
+	s.t.Helper()
+	err := s.aaclient.SendTransaction(context.Background(), tx)
+	if err == nil {
+		s.t.Fatalf("transaction %x accepted", tx.Hash())
+	}
+	return err
+}
+
+// Commit seals a block with the pending transactions.
+func (s *Scenario) Commit() *Scenario {
+	s.beacon.Commit()
+	return s
+}
+
+// Client returns the RPC client of the node, for the assertions not covered by
+// the Expect methods.
+func (s *Scenario) Client() *ethclient.Client {
+	return s.client
+}
+
+// AAClient returns the client of the aa namespace of the node.
+func (s *Scenario) AAClient() *aaclient.Client {
+	return s.aaclient
+}
+
+// Receipt returns the receipt of an included transaction, failing the test if
+// it is missing.
+func (s *Scenario) Receipt(tx *types.Transaction) *types.Receipt {
+	s.t.Helper()
+	receipt, err := s.client.TransactionReceipt(context.Background(), tx.Hash())
+	if err != nil {
+		s.t.Fatalf("failed to get receipt of %x: %v", tx.Hash(), err)
+	}
+	return receipt
+}
+
+// ExpectStatus asserts that a transaction was included with the given status.
+func (s *Scenario) ExpectStatus(tx *types.Transaction, status uint64) *Scenario {
+	s.t.Helper()
+	if receipt := s.Receipt(tx); receipt.Status != status {
+		s.t.Errorf("status mismatch of %x: have %d, want %d", tx.Hash(), receipt.Status, status)
+	}
+	return s
+}
+
+// ExpectNotIncluded asserts that a transaction has no receipt.
+func (s *Scenario) ExpectNotIncluded(tx *types.Transaction) *Scenario {
+	s.t.Helper()
+	if receipt, err := s.client.TransactionReceipt(context.Background(), tx.Hash()); err == nil {
+		s.t.Errorf("transaction %x included in block %d", tx.Hash(), receipt.BlockNumber)
+	}
+	return s
+}
+
+// ExpectCode asserts that an account has code, deployed or not.
+func (s *Scenario) ExpectCode(addr common.Address, deployed bool) *Scenario {
+	s.t.Helper()
+	code, err := s.client.CodeAt(context.Background(), addr, nil)
+	if err != nil {
+		s.t.Fatalf("failed to get code of %v: %v", addr, err)
+	}
+	if (len(code) > 0) != deployed {
+		s.t.Errorf("code mismatch of %v: have %d bytes, want deployed %v", addr, len(code), deployed)
+	}
+	return s
+}
+
+// ExpectStorage asserts the value of a storage slot of an account.
+func (s *Scenario) ExpectStorage(addr common.Address, slot, value common.Hash) *Scenario {
+	s.t.Helper()
+	have, err := s.client.StorageAt(context.Background(), addr, slot, nil)
+	if err != nil {
+		s.t.Fatalf("failed to get storage of %v: %v", addr, err)
+	}
+	if common.BytesToHash(have) != value {
+		s.t.Errorf("storage mismatch of %v slot %x: have %x, want %x", addr, slot, have, value)
+	}
+	return s
+}
+
+// ExpectNonce asserts the nonce of a sender under a RIP-7712 nonce key, the
+// zero key being the legacy nonce.
+func (s *Scenario) ExpectNonce(sender common.Address, key *big.Int, nonce uint64) *Scenario {
+	s.t.Helper()
+	have, err := s.aaclient.NonceAt(context.Background(), sender, key, nil)
+	if err != nil {
+		s.t.Fatalf("failed to get nonce of %v: %v", sender, err)
+	}
+	if have != nonce {
+		s.t.Errorf("nonce mismatch of %v: have %d, want %d", sender, have, nonce)
+	}
+	return s
+}
+
+// ExpectCharged asserts that an account paid the gas of a transaction at its
+// effective gas price, compared to its balance before the block.
+func (s *Scenario) ExpectCharged(addr common.Address, tx *types.Transaction) *Scenario {
+	s.t.Helper()
+	receipt := s.Receipt(tx)
+	before, err := s.client.BalanceAt(context.Background(), addr, new(big.Int).Sub(receipt.BlockNumber, common.Big1))
+	if err != nil {
+		s.t.Fatalf("failed to get balance of %v: %v", addr, err)
+	}
+	after, err := s.client.BalanceAt(context.Background(), addr, receipt.BlockNumber)
+	if err != nil {
+		s.t.Fatalf("failed to get balance of %v: %v", addr, err)
+	}
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice)
+	if charged := new(big.Int).Sub(before, after); charged.Cmp(cost) != 0 {
+		s.t.Errorf("charge mismatch of %v: have %v, want %v", addr, charged, cost)
+	}
+	return s
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
 	"math/big"
 	"testing"
 )
@@ -72,8 +73,8 @@ func TestPaymasterValidationFailure_contextTooLarge(t *testing.T) {
 		//vm.PUSH1, 1, vm.PUSH0, vm.RETURN,
 		copyToMemory(core.PackValidationData(core.AcceptPaymasterMethodSig, 0, 0), 0),
 		copyToMemory(asBytes32(64), 32),
-		copyToMemory(asBytes32(core.PaymasterMaxContextSize+1), 64),
-		push(core.PaymasterMaxContextSize+96+1), vm.PUSH0, vm.RETURN)
+		copyToMemory(asBytes32(int(params.Rip7560MaxPaymasterContextSize)+1), 64),
+		push(int(params.Rip7560MaxPaymasterContextSize)+96+1), vm.PUSH0, vm.RETURN)
 
 	handleTransaction(newTestContextBuilder(t).withCode(DEFAULT_SENDER, createAccountCode(), 0).
 		withCode(DEFAULT_PAYMASTER.String(), pmCode, DEFAULT_BALANCE), types.Rip7560AccountAbstractionTx{
@@ -81,7 +82,7 @@ func TestPaymasterValidationFailure_contextTooLarge(t *testing.T) {
 		PaymasterValidationGasLimit: 1000000000,
 		GasFeeCap:                   big.NewInt(1000000000),
 		Paymaster:                   &DEFAULT_PAYMASTER,
-	}, "paymaster return data: context too large")
+	}, "paymaster context too large")
 }
 
 func TestPaymasterValidationFailure_validAfter(t *testing.T) {
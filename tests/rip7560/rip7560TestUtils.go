@@ -201,3 +201,12 @@ func createCode(items ...interface{}) []byte {
 func asBytes32(a int) []byte {
 	return common.LeftPadBytes(big.NewInt(int64(a)).Bytes(), 32)
 }
+
+// paymasterReturnValue returns the ABI encoding of the validation data and the
+// context returned by a paymaster validation frame.
+func paymasterReturnValue(magic, validUntil, validAfter uint64, context []byte) []byte {
+	ret := core.PackValidationData(magic, validUntil, validAfter)
+	ret = append(ret, asBytes32(64)...)
+	ret = append(ret, asBytes32(len(context))...)
+	return append(ret, common.RightPadBytes(context, (len(context)+31)/32*32)...)
+}
@@ -102,6 +102,15 @@ func TestExecutionSpecState(t *testing.T) {
 	})
 }
 
+// TestRip7560State runs the state tests of RIP-7560 transactions, which are not
+// part of the reference tests.
+func TestRip7560State(t *testing.T) {
+	st := new(testMatcher)
+	st.walk(t, rip7560StateTestDir, func(t *testing.T, name string, test *StateTest) {
+		execStateTest(t, st, test)
+	})
+}
+
 func execStateTest(t *testing.T, st *testMatcher, test *StateTest) {
 	for _, subtest := range test.Subtests() {
 		subtest := subtest
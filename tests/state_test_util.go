@@ -123,6 +123,8 @@ type stTransaction struct {
 	Sender               *common.Address     `json:"sender"`
 	BlobVersionedHashes  []common.Hash       `json:"blobVersionedHashes,omitempty"`
 	BlobGasFeeCap        *big.Int            `json:"maxFeePerBlobGas,omitempty"`
+	AuthorizationList    []*stAuthorization  `json:"authorizationList,omitempty"`
+	Rip7560              *stRip7560          `json:"rip7560,omitempty"`
 }
 
 type stTransactionMarshaling struct {
@@ -135,6 +137,44 @@ type stTransactionMarshaling struct {
 	BlobGasFeeCap        *math.HexOrDecimal256
 }
 
+//go:generate go run github.com/fjl/gencodec -type stAuthorization -field-override stAuthorizationMarshaling -out gen_stauthorization.go
+
+// Authorization is an authorization from an account to deploy code at its address.
+type stAuthorization struct {
+	ChainID *big.Int       `json:"chainId" gencodec:"required"`
+	Address common.Address `json:"address" gencodec:"required"`
+	Nonce   uint64         `json:"nonce" gencodec:"required"`
+	V       uint8          `json:"v" gencodec:"required"`
+	R       *big.Int       `json:"r" gencodec:"required"`
+	S       *big.Int       `json:"s" gencodec:"required"`
+}
+
+// field type overrides for gencodec
+type stAuthorizationMarshaling struct {
+	ChainID *math.HexOrDecimal256
+	Nonce   math.HexOrDecimal64
+	V       math.HexOrDecimal64
+	R       *math.HexOrDecimal256
+	S       *math.HexOrDecimal256
+}
+
+// stRip7560 holds the fields specific to RIP-7560 transactions. If present, the
+// test transaction is a RIP-7560 one sent by its 'sender' account, calling it
+// with the 'data' of the post state, and with the 'gasLimit' of the post state
+// as the gas limit of its execution frame. Its value must be zero.
+type stRip7560 struct {
+	NonceKey                    *math.HexOrDecimal256 `json:"nonceKey,omitempty"`
+	AuthorizationData           hexutil.Bytes         `json:"authorizationData,omitempty"`
+	Paymaster                   *common.Address       `json:"paymaster,omitempty"`
+	PaymasterData               hexutil.Bytes         `json:"paymasterData,omitempty"`
+	Deployer                    *common.Address       `json:"deployer,omitempty"`
+	DeployerData                hexutil.Bytes         `json:"deployerData,omitempty"`
+	BuilderFee                  *math.HexOrDecimal256 `json:"builderFee,omitempty"`
+	ValidationGasLimit          math.HexOrDecimal64   `json:"validationGasLimit"`
+	PaymasterValidationGasLimit math.HexOrDecimal64   `json:"paymasterValidationGasLimit,omitempty"`
+	PostOpGasLimit              math.HexOrDecimal64   `json:"postOpGasLimit,omitempty"`
+}
+
 // GetChainConfig takes a fork definition and returns a chain config.
 // The fork definition can be
 // - a plain forkname, e.g. `Byzantium`,
@@ -248,6 +288,10 @@ func (t *StateTest) RunNoVerify(subtest StateSubtest, vmconfig vm.Config, snapsh
 		}
 	}
 	post := t.json.Post[subtest.Fork][subtest.Index]
+	if t.json.Tx.Rip7560 != nil {
+		root, err = t.runRip7560(st, block, config, vmconfig, post, baseFee)
+		return st, root, err
+	}
 	msg, err := t.json.Tx.toMessage(post, baseFee)
 	if err != nil {
 		return st, common.Hash{}, err
@@ -278,22 +322,7 @@ func (t *StateTest) RunNoVerify(subtest StateSubtest, vmconfig vm.Config, snapsh
 
 	// Prepare the EVM.
 	txContext := core.NewEVMTxContext(msg)
-	context := core.NewEVMBlockContext(block.Header(), nil, &t.json.Env.Coinbase)
-	context.GetHash = vmTestBlockHash
-	context.BaseFee = baseFee
-	context.Random = nil
-	if t.json.Env.Difficulty != nil {
-		context.Difficulty = new(big.Int).Set(t.json.Env.Difficulty)
-	}
-	if config.IsLondon(new(big.Int)) && t.json.Env.Random != nil {
-		rnd := common.BigToHash(t.json.Env.Random)
-		context.Random = &rnd
-		context.Difficulty = big.NewInt(0)
-	}
-	if config.IsCancun(new(big.Int), block.Time()) && t.json.Env.ExcessBlobGas != nil {
-		context.BlobBaseFee = eip4844.CalcBlobFee(*t.json.Env.ExcessBlobGas)
-	}
-	evm := vm.NewEVM(context, txContext, st.StateDB, config, vmconfig)
+	evm := vm.NewEVM(t.blockContext(block, config, baseFee), txContext, st.StateDB, config, vmconfig)
 
 	if tracer := vmconfig.Tracer; tracer != nil && tracer.OnTxStart != nil {
 		tracer.OnTxStart(evm.GetVMContext(), nil, msg.From)
@@ -323,6 +352,56 @@ func (t *StateTest) RunNoVerify(subtest StateSubtest, vmconfig vm.Config, snapsh
 	return st, root, err
 }
 
+// runRip7560 applies the RIP-7560 transaction of the test, running both its
+// validation and execution phases, and returns the post-state root.
+func (t *StateTest) runRip7560(st StateTestState, block *types.Block, config *params.ChainConfig, vmconfig vm.Config, post stPostState, baseFee *big.Int) (root common.Hash, err error) {
+	tx, err := t.json.Tx.toRip7560Transaction(post, config.ChainID)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	header := block.Header()
+	header.BaseFee = baseFee
+	evm := vm.NewEVM(t.blockContext(block, config, baseFee), vm.TxContext{}, st.StateDB, config, vmconfig)
+
+	// The transaction start is reported by the validation phase itself
+	if tracer := vmconfig.Tracer; tracer != nil && tracer.OnTxEnd != nil {
+		defer func() {
+			tracer.OnTxEnd(nil, err)
+		}()
+	}
+	snapshot := st.StateDB.Snapshot()
+	gaspool := new(core.GasPool)
+	gaspool.AddGas(block.GasLimit())
+	var usedGas uint64
+	if _, err = core.ApplyRip7560TransactionWithEVM(evm, gaspool, st.StateDB, header, block.Hash(), tx, 0, &usedGas); err != nil {
+		st.StateDB.RevertToSnapshot(snapshot)
+	}
+	st.StateDB.AddBalance(block.Coinbase(), new(uint256.Int), tracing.BalanceChangeUnspecified)
+
+	root, _ = st.StateDB.Commit(block.NumberU64(), config.IsEIP158(block.Number()))
+	return root, err
+}
+
+// blockContext returns the block context of the test environment.
+func (t *StateTest) blockContext(block *types.Block, config *params.ChainConfig, baseFee *big.Int) vm.BlockContext {
+	context := core.NewEVMBlockContext(block.Header(), nil, &t.json.Env.Coinbase)
+	context.GetHash = vmTestBlockHash
+	context.BaseFee = baseFee
+	context.Random = nil
+	if t.json.Env.Difficulty != nil {
+		context.Difficulty = new(big.Int).Set(t.json.Env.Difficulty)
+	}
+	if config.IsLondon(new(big.Int)) && t.json.Env.Random != nil {
+		rnd := common.BigToHash(t.json.Env.Random)
+		context.Random = &rnd
+		context.Difficulty = big.NewInt(0)
+	}
+	if config.IsCancun(new(big.Int), block.Time()) && t.json.Env.ExcessBlobGas != nil {
+		context.BlobBaseFee = eip4844.CalcBlobFee(*t.json.Env.ExcessBlobGas)
+	}
+	return context
+}
+
 func (t *StateTest) gasLimit(subtest StateSubtest) uint64 {
 	return t.json.Tx.GasLimit[t.json.Post[subtest.Fork][subtest.Index].Indexes.Gas]
 }
@@ -397,6 +476,21 @@ func (tx *stTransaction) toMessage(ps stPostState, baseFee *big.Int) (*core.Mess
 	if tx.AccessLists != nil && tx.AccessLists[ps.Indexes.Data] != nil {
 		accessList = *tx.AccessLists[ps.Indexes.Data]
 	}
+	var authList []types.SetCodeAuthorization
+	if tx.AuthorizationList != nil {
+		authList = make([]types.SetCodeAuthorization, len(tx.AuthorizationList))
+		for i, auth := range tx.AuthorizationList {
+			authList[i] = types.SetCodeAuthorization{
+				ChainID: *uint256.MustFromBig(auth.ChainID),
+				Address: auth.Address,
+				Nonce:   auth.Nonce,
+				V:       auth.V,
+				R:       *uint256.MustFromBig(auth.R),
+				S:       *uint256.MustFromBig(auth.S),
+			}
+		}
+	}
+
 	// If baseFee provided, set gasPrice to effectiveGasPrice.
 	gasPrice := tx.GasPrice
 	if baseFee != nil {
@@ -417,22 +511,66 @@ func (tx *stTransaction) toMessage(ps stPostState, baseFee *big.Int) (*core.Mess
 	}
 
 	msg := &core.Message{
-		From:          from,
-		To:            to,
-		Nonce:         tx.Nonce,
-		Value:         value,
-		GasLimit:      gasLimit,
-		GasPrice:      gasPrice,
-		GasFeeCap:     tx.MaxFeePerGas,
-		GasTipCap:     tx.MaxPriorityFeePerGas,
-		Data:          data,
-		AccessList:    accessList,
-		BlobHashes:    tx.BlobVersionedHashes,
-		BlobGasFeeCap: tx.BlobGasFeeCap,
+		From:                  from,
+		To:                    to,
+		Nonce:                 tx.Nonce,
+		Value:                 value,
+		GasLimit:              gasLimit,
+		GasPrice:              gasPrice,
+		GasFeeCap:             tx.MaxFeePerGas,
+		GasTipCap:             tx.MaxPriorityFeePerGas,
+		Data:                  data,
+		AccessList:            accessList,
+		BlobHashes:            tx.BlobVersionedHashes,
+		BlobGasFeeCap:         tx.BlobGasFeeCap,
+		SetCodeAuthorizations: authList,
 	}
 	return msg, nil
 }
 
+func (tx *stTransaction) toRip7560Transaction(ps stPostState, chainID *big.Int) (*types.Transaction, error) {
+	if tx.Sender == nil {
+		return nil, errors.New("rip7560 transaction without sender")
+	}
+	// The fees and the execution of the transaction are shared with the other
+	// transaction types. The fee caps are defaulted like the dynamic fee ones.
+	msg, err := tx.toMessage(ps, new(big.Int))
+	if err != nil {
+		return nil, err
+	}
+	if msg.Value.Sign() != 0 {
+		return nil, fmt.Errorf("rip7560 transaction with value %v", msg.Value)
+	}
+	aa := tx.Rip7560
+	aatx := &types.Rip7560AccountAbstractionTx{
+		ChainID:                     chainID,
+		Nonce:                       msg.Nonce,
+		GasTipCap:                   msg.GasTipCap,
+		GasFeeCap:                   msg.GasFeeCap,
+		Gas:                         msg.GasLimit,
+		AccessList:                  msg.AccessList,
+		Sender:                      tx.Sender,
+		AuthorizationData:           aa.AuthorizationData,
+		ExecutionData:               msg.Data,
+		Paymaster:                   aa.Paymaster,
+		PaymasterData:               aa.PaymasterData,
+		Deployer:                    aa.Deployer,
+		DeployerData:                aa.DeployerData,
+		BuilderFee:                  (*big.Int)(aa.BuilderFee),
+		ValidationGasLimit:          uint64(aa.ValidationGasLimit),
+		PaymasterValidationGasLimit: uint64(aa.PaymasterValidationGasLimit),
+		PostOpGas:                   uint64(aa.PostOpGasLimit),
+		NonceKey:                    (*big.Int)(aa.NonceKey),
+	}
+	if aatx.NonceKey == nil {
+		aatx.NonceKey = new(big.Int)
+	}
+	if aatx.BuilderFee == nil {
+		aatx.BuilderFee = new(big.Int)
+	}
+	return types.NewTx(aatx), nil
+}
+
 func rlpHash(x interface{}) (h common.Hash) {
 	hw := sha3.NewLegacyKeccak256()
 	rlp.Encode(hw, x)
@@ -17,6 +17,7 @@
 package trie
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"sync"
@@ -264,6 +265,8 @@ type Sync struct {
 	nodeReqs map[string]*nodeRequest      // Pending requests pertaining to a trie node path
 	codeReqs map[common.Hash]*codeRequest // Pending requests pertaining to a code hash
 	queue    *prque.Prque[int64, any]     // Priority queue with the pending requests
+	urgent   *prque.Prque[int64, any]     // Priority queue with the requests of prioritized accounts
+	priority [][]byte                     // Paths of the accounts retrieved before the rest of the state
 	fetches  map[int]int                  // Number of active fetches per trie node depth
 }
 
@@ -276,6 +279,7 @@ func NewSync(root common.Hash, database ethdb.KeyValueReader, callback LeafCallb
 		nodeReqs: make(map[string]*nodeRequest),
 		codeReqs: make(map[common.Hash]*codeRequest),
 		queue:    prque.New[int64, any](nil), // Ugh, can contain both string and hash, whyyy
+		urgent:   prque.New[int64, any](nil),
 		fetches:  make(map[int]int),
 	}
 	ts.AddSubTrie(root, nil, common.Hash{}, nil, callback)
@@ -355,41 +359,68 @@ func (s *Sync) AddCodeEntry(hash common.Hash, path []byte, parent common.Hash, p
 // Missing retrieves the known missing nodes from the trie for retrieval. To aid
 // both eth/6x style fast sync and snap/1x style state sync, the paths of trie
 // nodes are returned too, as well as separate hash list for codes.
+//
+// The requests of prioritized accounts are returned before any other.
 func (s *Sync) Missing(max int) ([]string, []common.Hash, []common.Hash) {
 	var (
 		nodePaths  []string
 		nodeHashes []common.Hash
 		codeHashes []common.Hash
 	)
-	for !s.queue.Empty() && (max == 0 || len(nodeHashes)+len(codeHashes) < max) {
-		// Retrieve the next item in line
-		item, prio := s.queue.Peek()
-
-		// If we have too many already-pending tasks for this depth, throttle
-		depth := int(prio >> 56)
-		if s.fetches[depth] > maxFetchesPerDepth {
-			break
-		}
-		// Item is allowed to be scheduled, add it to the task list
-		s.queue.Pop()
-		s.fetches[depth]++
-
-		switch item := item.(type) {
-		case common.Hash:
-			codeHashes = append(codeHashes, item)
-		case string:
-			req, ok := s.nodeReqs[item]
-			if !ok {
-				log.Error("Missing node request", "path", item)
-				continue // System very wrong, shouldn't happen
+	for _, queue := range []*prque.Prque[int64, any]{s.urgent, s.queue} {
+		for !queue.Empty() && (max == 0 || len(nodeHashes)+len(codeHashes) < max) {
+			// Retrieve the next item in line
+			item, prio := queue.Peek()
+
+			// If we have too many already-pending tasks for this depth, throttle
+			depth := int(prio >> 56)
+			if s.fetches[depth] > maxFetchesPerDepth {
+				break
+			}
+			// Item is allowed to be scheduled, add it to the task list
+			queue.Pop()
+			s.fetches[depth]++
+
+			switch item := item.(type) {
+			case common.Hash:
+				codeHashes = append(codeHashes, item)
+			case string:
+				req, ok := s.nodeReqs[item]
+				if !ok {
+					log.Error("Missing node request", "path", item)
+					continue // System very wrong, shouldn't happen
+				}
+				nodePaths = append(nodePaths, item)
+				nodeHashes = append(nodeHashes, req.hash)
 			}
-			nodePaths = append(nodePaths, item)
-			nodeHashes = append(nodeHashes, req.hash)
 		}
 	}
 	return nodePaths, nodeHashes, codeHashes
 }
 
+// Prioritize sets the accounts (by the hash of their address) whose trie nodes,
+// storage and code are to be retrieved before the rest of the state. It only
+// affects requests scheduled afterwards, so it should be called right after
+// creating the scheduler.
+func (s *Sync) Prioritize(accounts []common.Hash) {
+	s.priority = make([][]byte, len(accounts))
+	for i, account := range accounts {
+		s.priority[i] = keybytesToHex(account.Bytes())[:2*common.HashLength]
+	}
+}
+
+// queueFor returns the queue to schedule the request of the given path into,
+// which is the urgent one if the path leads to a prioritized account, or is
+// within its storage trie.
+func (s *Sync) queueFor(path []byte) *prque.Prque[int64, any] {
+	for _, account := range s.priority {
+		if bytes.HasPrefix(account, path) || bytes.HasPrefix(path, account) {
+			return s.urgent
+		}
+	}
+	return s.queue
+}
+
 // ProcessCode injects the received data for requested item. Note it can
 // happen that the single response commits two pending requests(e.g.
 // there are two requests one for code and one for node but the hash
@@ -512,7 +543,7 @@ func (s *Sync) scheduleNodeRequest(req *nodeRequest) {
 	for i := 0; i < 14 && i < len(req.path); i++ {
 		prio |= int64(15-req.path[i]) << (52 - i*4) // 15-nibble => lexicographic order
 	}
-	s.queue.Push(string(req.path), prio)
+	s.queueFor(req.path).Push(string(req.path), prio)
 }
 
 // scheduleCodeRequest inserts a new state retrieval request into the fetch queue. If there
@@ -532,7 +563,7 @@ func (s *Sync) scheduleCodeRequest(req *codeRequest) {
 	for i := 0; i < 14 && i < len(req.path); i++ {
 		prio |= int64(15-req.path[i]) << (52 - i*4) // 15-nibble => lexicographic order
 	}
-	s.queue.Push(req.hash, prio)
+	s.queueFor(req.path).Push(req.hash, prio)
 }
 
 // children retrieves all the missing children of a state trie entry for future
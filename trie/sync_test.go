@@ -1000,3 +1000,64 @@ func testSyncAbort(t *testing.T, scheme string) {
 	syncWith(t, rootC, destDisk, srcTrieDB)
 	checkTrieContents(t, destDisk, scheme, srcTrie.Hash().Bytes(), stateC, true)
 }
+
+// Tests that the trie nodes leading to prioritized accounts are retrieved
+// before any other.
+func TestSyncPriority(t *testing.T) {
+	testSyncPriority(t, rawdb.HashScheme)
+	testSyncPriority(t, rawdb.PathScheme)
+}
+
+func testSyncPriority(t *testing.T, scheme string) {
+	_, srcDb, srcTrie, srcData := makeTestTrie(scheme)
+
+	var (
+		account = crypto.Keccak256Hash(common.LeftPadBytes([]byte{7, 42}, 32))
+		target  = keybytesToHex(account.Bytes())[:2*common.HashLength]
+		diskdb  = rawdb.NewMemoryDatabase()
+		sched   = NewSync(srcTrie.Hash(), diskdb, nil, srcDb.Scheme())
+		reqs    []string
+	)
+	sched.Prioritize([]common.Hash{account})
+
+	reader, err := srcDb.Reader(srcTrie.Hash())
+	if err != nil {
+		t.Fatalf("State is not available %x", srcTrie.Hash())
+	}
+	for {
+		paths, nodes, _ := sched.Missing(1)
+		if len(paths) == 0 {
+			break
+		}
+		reqs = append(reqs, paths[0])
+
+		owner, inner := ResolvePath([]byte(paths[0]))
+		data, err := reader.Node(owner, inner, nodes[0])
+		if err != nil {
+			t.Fatalf("failed to retrieve node data for %x: %v", nodes[0], err)
+		}
+		if err := sched.ProcessNode(NodeSyncResult{paths[0], data}); err != nil {
+			t.Fatalf("failed to process result %v", err)
+		}
+		batch := diskdb.NewBatch()
+		if err := sched.Commit(batch); err != nil {
+			t.Fatalf("failed to commit data: %v", err)
+		}
+		batch.Write()
+	}
+	checkTrieContents(t, diskdb, srcDb.Scheme(), srcTrie.Hash().Bytes(), srcData, false)
+
+	// The nodes on the path to the prioritized account have to come first
+	var urgent int
+	for urgent < len(reqs) && bytes.HasPrefix(target, []byte(reqs[urgent])) {
+		urgent++
+	}
+	if urgent < 2 {
+		t.Fatalf("prioritized path not retrieved first: %v", reqs[:2])
+	}
+	for _, path := range reqs[urgent:] {
+		if bytes.HasPrefix(target, []byte(path)) {
+			t.Fatalf("prioritized node %x retrieved late", path)
+		}
+	}
+}